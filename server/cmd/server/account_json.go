@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+const accountJSONMaxBytes = 64 << 10
+
+// PasswordFormResponse is the JSON answer of the invite password and reset
+// confirmation forms. Field names the offending input on validation errors.
+type PasswordFormResponse struct {
+	OK       bool   `json:"ok"`
+	Redirect string `json:"redirect,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Field    string `json:"field,omitempty"`
+}
+
+// requestHasJSONBody reports whether the request body is application/json.
+func requestHasJSONBody(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && strings.EqualFold(mediaType, "application/json")
+}
+
+// wantsPasswordFormJSON reports whether a password form should be answered
+// with PasswordFormResponse instead of a rendered page.
+func wantsPasswordFormJSON(r *http.Request) bool {
+	return requestHasJSONBody(r) || prefersJSONResponse(r)
+}
+
+// readPasswordForm returns the submitted password and confirmation from a JSON
+// body or a regular form. JSON clients may omit confirm_password, in which
+// case the password counts as confirmed.
+func readPasswordForm(w http.ResponseWriter, r *http.Request) (string, string, error) {
+	if requestHasJSONBody(r) {
+		var body struct {
+			Password        string  `json:"password"`
+			ConfirmPassword *string `json:"confirm_password"`
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, accountJSONMaxBytes)).Decode(&body); err != nil {
+			return "", "", err
+		}
+		password := strings.TrimSpace(body.Password)
+		if body.ConfirmPassword == nil {
+			return password, password, nil
+		}
+		return password, strings.TrimSpace(*body.ConfirmPassword), nil
+	}
+	if err := r.ParseForm(); err != nil {
+		return "", "", err
+	}
+	return strings.TrimSpace(r.FormValue("password")), strings.TrimSpace(r.FormValue("confirm_password")), nil
+}
+
+// passwordFormError checks the submitted pair and returns the message and the
+// field it applies to, or empty strings when the password is acceptable.
+func passwordFormError(password, confirmPassword string) (string, string) {
+	if password != confirmPassword {
+		return "passwords do not match", "confirm_password"
+	}
+	if err := validatePassword(password); err != nil {
+		return err.Error(), "password"
+	}
+	return "", ""
+}
+
+func writePasswordFormJSON(w http.ResponseWriter, status int, response PasswordFormResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(response)
+}
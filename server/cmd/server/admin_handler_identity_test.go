@@ -2318,7 +2318,7 @@ func TestHandleOrgAdminUsersSetRolesWithIdentity(t *testing.T) {
 	}
 
 	targetID := "user-2"
-	req := httptest.NewRequest(http.MethodPost, "/my/organization/users", strings.NewReader("intent=set_roles&userId="+targetID+"&roles=approver&roles=org-admin"))
+	req := httptest.NewRequest(http.MethodPost, "/my/organization/users", strings.NewReader("intent=set_roles&userId="+targetID+"&roles=approver&roles=org-admin&rolesVersion="+identityRolesVersion(users[1], 0)))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	req.AddCookie(&http.Cookie{Name: "attesta_session", Value: "session-1"})
 	rec := httptest.NewRecorder()
@@ -2336,7 +2336,7 @@ func TestHandleOrgAdminUsersSetRolesWithIdentity(t *testing.T) {
 	}
 
 	selfID := "user-1"
-	selfReq := httptest.NewRequest(http.MethodPost, "/my/organization/users", strings.NewReader("intent=set_roles&userId="+selfID+"&roles=approver"))
+	selfReq := httptest.NewRequest(http.MethodPost, "/my/organization/users", strings.NewReader("intent=set_roles&userId="+selfID+"&roles=approver&rolesVersion="+identityRolesVersion(users[0], 0)))
 	selfReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	selfReq.AddCookie(&http.Cookie{Name: "attesta_session", Value: "session-1"})
 	selfRec := httptest.NewRecorder()
@@ -2629,6 +2629,7 @@ func TestHandleOrgAdminUsersDeleteUserWithIdentity(t *testing.T) {
 	now := time.Now().UTC()
 	deletedMemberships := []string{}
 	updatedUsers := map[string][]string{}
+	revokedSessions := []string{}
 	server := &Server{
 		authorizer: fakeAuthorizer{},
 		store:      NewMemoryStore(),
@@ -2656,6 +2657,10 @@ func TestHandleOrgAdminUsersDeleteUserWithIdentity(t *testing.T) {
 				updatedUsers[userID] = append([]string(nil), labels...)
 				return IdentityUser{ID: userID, Labels: labels}, nil
 			},
+			deleteUserSessionsFunc: func(ctx context.Context, userID string) error {
+				revokedSessions = append(revokedSessions, userID)
+				return nil
+			},
 			getOrganizationBySlugFunc: func(ctx context.Context, slug string) (*IdentityOrg, error) {
 				org := IdentityOrg{ID: "team-1", Slug: "acme", Name: "Acme Org"}
 				return &org, nil
@@ -2687,14 +2692,17 @@ func TestHandleOrgAdminUsersDeleteUserWithIdentity(t *testing.T) {
 	if labels := updatedUsers["user-2"]; len(labels) != 1 || labels[0] != "custom:keep" {
 		t.Fatalf("updated user labels = %#v", updatedUsers)
 	}
+	if len(revokedSessions) != 1 || revokedSessions[0] != "user-2" {
+		t.Fatalf("revoked sessions = %#v, want user-2", revokedSessions)
+	}
 }
 
 func TestIdentityOrgAdminHelpers(t *testing.T) {
-	if got := inviteRedirectURL(httptest.NewRequest(http.MethodGet, "/my/organization/users", nil)); !strings.Contains(got, "/invite/accept") {
+	if got := (&Server{}).inviteRedirectURL(httptest.NewRequest(http.MethodGet, "/my/organization/users", nil)); !strings.Contains(got, "/invite/accept") {
 		t.Fatalf("invite redirect = %q", got)
 	}
 	t.Setenv("APPWRITE_INVITE_REDIRECT_URL", "https://app.example/invite/accept")
-	if got := inviteRedirectURL(httptest.NewRequest(http.MethodGet, "/my/organization/users", nil)); got != "https://app.example/invite/accept" {
+	if got := (&Server{}).inviteRedirectURL(httptest.NewRequest(http.MethodGet, "/my/organization/users", nil)); got != "https://app.example/invite/accept" {
 		t.Fatalf("configured invite redirect = %q", got)
 	}
 	if _, err := sessionSecretFromRequest(httptest.NewRequest(http.MethodGet, "/", nil)); err == nil {
@@ -2944,7 +2952,7 @@ func TestHandleOrgAdminUsersIdentityBranchErrors(t *testing.T) {
 		}
 		server := &Server{
 			authorizer: fakeAuthorizer{}, store: NewMemoryStore(), identity: fake, tmpl: testTemplates(), enforceAuth: true, now: func() time.Time { return now }}
-		req := httptest.NewRequest(http.MethodPost, "/my/organization/users", strings.NewReader("intent=set_roles&userId=user-2&roles=approver&roles=org-admin"))
+		req := httptest.NewRequest(http.MethodPost, "/my/organization/users", strings.NewReader("intent=set_roles&userId=user-2&roles=approver&roles=org-admin&rolesVersion="+identityRolesVersion(IdentityUser{Labels: []string{encodeIdentityRoleLabel("approver")}}, 0)))
 		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 		req.AddCookie(&http.Cookie{Name: "attesta_session", Value: "session-1"})
 		rec := httptest.NewRecorder()
@@ -3085,6 +3093,41 @@ func TestHandleOrgAdminUsersIdentityBranchErrors(t *testing.T) {
 		}
 	})
 
+	t.Run("update org saves public branding only when it changes", func(t *testing.T) {
+		fake := baseIdentity()
+		fake.getOrganizationBySlugFunc = func(ctx context.Context, slug string) (*IdentityOrg, error) {
+			org := IdentityOrg{ID: "team-1", Slug: "acme", Name: "Acme Org"}
+			return &org, nil
+		}
+		fake.updateOrganizationFunc = func(ctx context.Context, sessionSecret, currentSlug, name, logoFileID string, roles []IdentityRole) (IdentityOrg, error) {
+			return IdentityOrg{ID: "team-1", Slug: "acme", Name: name}, nil
+		}
+		var saved []bool
+		fake.updateOrganizationPublicBrandingFunc = func(ctx context.Context, sessionSecret, orgSlug string, public bool) (IdentityOrg, error) {
+			if sessionSecret != "session-1" || orgSlug != "acme" {
+				t.Fatalf("update public branding session=%q org=%q", sessionSecret, orgSlug)
+			}
+			saved = append(saved, public)
+			return IdentityOrg{ID: "team-1", Slug: orgSlug, PublicBranding: public}, nil
+		}
+		server := &Server{
+			authorizer: fakeAuthorizer{}, store: NewMemoryStore(), identity: fake, tmpl: testTemplates(), enforceAuth: true, now: func() time.Time { return now }}
+		post := func(form string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPost, "/my/organization/users", strings.NewReader("intent=update_org&name=Acme+Org&"+form))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.AddCookie(&http.Cookie{Name: "attesta_session", Value: "session-1"})
+			rec := httptest.NewRecorder()
+			server.handleOrgAdminUsers(rec, req)
+			return rec
+		}
+		if rec := post("public_branding=false"); rec.Code != http.StatusSeeOther || len(saved) != 0 {
+			t.Fatalf("unchanged branding status=%d saved=%v", rec.Code, saved)
+		}
+		if rec := post("public_branding=false&public_branding=true"); rec.Code != http.StatusSeeOther || len(saved) != 1 || !saved[0] {
+			t.Fatalf("enabled branding status=%d saved=%v", rec.Code, saved)
+		}
+	})
+
 	t.Run("delete user handles label cleanup failure", func(t *testing.T) {
 		fake := baseIdentity()
 		fake.listOrganizationMembershipsFunc = func(ctx context.Context, orgSlug string) ([]IdentityMembership, error) {
@@ -3237,7 +3280,7 @@ func TestHandleOrgAdminUsersIdentityBranchErrors(t *testing.T) {
 		}
 		server := &Server{
 			authorizer: fakeAuthorizer{}, store: NewMemoryStore(), identity: fake, tmpl: testTemplates(), enforceAuth: true, now: func() time.Time { return now }}
-		req := httptest.NewRequest(http.MethodPost, "/my/organization/users", strings.NewReader("intent=set_roles&userId=user-2&roles=missing"))
+		req := httptest.NewRequest(http.MethodPost, "/my/organization/users", strings.NewReader("intent=set_roles&userId=user-2&roles=missing&rolesVersion="+identityRolesVersion(IdentityUser{Labels: []string{encodeIdentityRoleLabel("approver")}}, 0)))
 		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 		req.AddCookie(&http.Cookie{Name: "attesta_session", Value: "session-1"})
 		rec := httptest.NewRecorder()
@@ -3247,6 +3290,65 @@ func TestHandleOrgAdminUsersIdentityBranchErrors(t *testing.T) {
 		}
 	})
 
+	t.Run("set roles rejects stale roles version", func(t *testing.T) {
+		fake := baseIdentity()
+		fake.listOrganizationUsersFunc = func(ctx context.Context, orgSlug string) ([]IdentityUser, error) {
+			return []IdentityUser{{ID: "user-2", Email: "member@example.com", OrgSlug: "acme", Labels: []string{encodeIdentityRoleLabel("approver"), encodeIdentityRoleLabel("qa-reviewer")}, Status: "active"}}, nil
+		}
+		updated := false
+		fake.updateUserLabelsFunc = func(ctx context.Context, userID string, labels []string) (IdentityUser, error) {
+			updated = true
+			return IdentityUser{}, nil
+		}
+		server := &Server{
+			authorizer: fakeAuthorizer{}, store: NewMemoryStore(), identity: fake, tmpl: testTemplates(), enforceAuth: true, now: func() time.Time { return now }}
+		stale := identityRolesVersion(IdentityUser{Labels: []string{encodeIdentityRoleLabel("approver")}}, 0)
+		req := httptest.NewRequest(http.MethodPost, "/my/organization/users", strings.NewReader("intent=set_roles&userId=user-2&roles=approver&rolesVersion="+stale))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(&http.Cookie{Name: "attesta_session", Value: "session-1"})
+		rec := httptest.NewRecorder()
+		server.handleOrgAdminUsers(rec, req)
+		if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "were changed by someone else") {
+			t.Fatalf("stale roles version response = %d %q", rec.Code, rec.Body.String())
+		}
+		if updated {
+			t.Fatal("expected stale role update not to reach the identity store")
+		}
+	})
+
+	t.Run("set roles lets one of two updates from the same page through", func(t *testing.T) {
+		fake := baseIdentity()
+		member := IdentityUser{ID: "user-2", Email: "member@example.com", OrgSlug: "acme", Labels: []string{encodeIdentityRoleLabel("approver")}, Status: "active"}
+		fake.listOrganizationUsersFunc = func(ctx context.Context, orgSlug string) ([]IdentityUser, error) {
+			return []IdentityUser{member}, nil
+		}
+		updates := 0
+		fake.updateUserLabelsFunc = func(ctx context.Context, userID string, labels []string) (IdentityUser, error) {
+			updates++
+			return IdentityUser{}, nil
+		}
+		server := &Server{
+			authorizer: fakeAuthorizer{}, store: NewMemoryStore(), identity: fake, tmpl: testTemplates(), enforceAuth: true, now: func() time.Time { return now }}
+		version := identityRolesVersion(member, 0)
+		post := func() *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPost, "/my/organization/users", strings.NewReader("intent=set_roles&userId=user-2&roles=approver&rolesVersion="+version))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.AddCookie(&http.Cookie{Name: "attesta_session", Value: "session-1"})
+			rec := httptest.NewRecorder()
+			server.handleOrgAdminUsers(rec, req)
+			return rec
+		}
+		if rec := post(); rec.Code != http.StatusSeeOther {
+			t.Fatalf("first update response = %d %q", rec.Code, rec.Body.String())
+		}
+		if rec := post(); rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "were changed by someone else") {
+			t.Fatalf("second update response = %d %q", rec.Code, rec.Body.String())
+		}
+		if updates != 1 {
+			t.Fatalf("label updates = %d, want 1", updates)
+		}
+	})
+
 	t.Run("delete user handles load user failure", func(t *testing.T) {
 		fake := baseIdentity()
 		fake.listOrganizationMembershipsFunc = func(ctx context.Context, orgSlug string) ([]IdentityMembership, error) {
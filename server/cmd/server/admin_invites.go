@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// orgInviteTTL is how long an unconfirmed membership counts as pending.
+const orgInviteTTL = 7 * 24 * time.Hour
+
+// membershipInviteStatus reports "accepted", "pending" or "expired" for
+// membership and when its invite expires (zero without an invite time).
+func membershipInviteStatus(membership IdentityMembership, now time.Time) (string, time.Time) {
+	expiresAt := time.Time{}
+	if !membership.InvitedAt.IsZero() {
+		expiresAt = membership.InvitedAt.Add(orgInviteTTL)
+	}
+	if membership.Confirmed {
+		return "accepted", expiresAt
+	}
+	if !expiresAt.IsZero() && expiresAt.Before(now) {
+		return "expired", expiresAt
+	}
+	return "pending", expiresAt
+}
+
+// AdminInvite is one open invite in GET /admin/invites.
+type AdminInvite struct {
+	OrgSlug   string     `json:"org_slug"`
+	OrgName   string     `json:"org_name"`
+	Email     string     `json:"email"`
+	Roles     []string   `json:"roles"`
+	Status    string     `json:"status"`
+	InvitedAt *time.Time `json:"invited_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type AdminInvitesResponse struct {
+	Invites []AdminInvite `json:"invites"`
+}
+
+// AdminInviteFilter narrows listAllInvites. Status is "", "pending" or
+// "expired"; OrgSlug is "" for every organization.
+type AdminInviteFilter struct {
+	Status  string
+	OrgSlug string
+}
+
+// listAllInvites collects the unconfirmed memberships of every organization,
+// newest invite first.
+func listAllInvites(ctx context.Context, identity IdentityStore, filter AdminInviteFilter, now time.Time) ([]AdminInvite, error) {
+	orgs, err := identity.ListOrganizations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	invites := []AdminInvite{}
+	for _, org := range orgs {
+		if filter.OrgSlug != "" && org.Slug != filter.OrgSlug {
+			continue
+		}
+		memberships, err := identity.ListOrganizationMemberships(ctx, org.Slug)
+		if err != nil {
+			return nil, fmt.Errorf("list memberships of %s: %w", org.Slug, err)
+		}
+		for _, membership := range memberships {
+			status, expiresAt := membershipInviteStatus(membership, now)
+			if status == "accepted" || (filter.Status != "" && status != filter.Status) {
+				continue
+			}
+			roles := append([]string{}, membership.RoleSlugs...)
+			if membership.IsOrgAdmin {
+				roles = canonifyRoleSlugs(append(roles, "org-admin"))
+			}
+			invite := AdminInvite{
+				OrgSlug: org.Slug,
+				OrgName: org.Name,
+				Email:   membership.Email,
+				Roles:   roles,
+				Status:  status,
+			}
+			if !membership.InvitedAt.IsZero() {
+				invitedAt := membership.InvitedAt
+				invite.InvitedAt, invite.ExpiresAt = &invitedAt, &expiresAt
+			}
+			invites = append(invites, invite)
+		}
+	}
+	sort.SliceStable(invites, func(i, j int) bool {
+		if invites[i].InvitedAt == nil || invites[j].InvitedAt == nil {
+			return invites[i].InvitedAt != nil
+		}
+		return invites[i].InvitedAt.After(*invites[j].InvitedAt)
+	})
+	return invites, nil
+}
+
+// handleAdminInvites serves GET /admin/invites[?status=pending|expired][&org=slug]
+// to platform admins.
+func (s *Server) handleAdminInvites(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if _, ok := s.requirePlatformAdmin(w, r); !ok {
+		return
+	}
+	if s.identity == nil {
+		http.Error(w, "identity unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	filter := AdminInviteFilter{
+		Status:  strings.ToLower(strings.TrimSpace(r.URL.Query().Get("status"))),
+		OrgSlug: strings.TrimSpace(r.URL.Query().Get("org")),
+	}
+	if filter.Status != "" && filter.Status != "pending" && filter.Status != "expired" {
+		http.Error(w, "status must be pending or expired", http.StatusBadRequest)
+		return
+	}
+	invites, err := listAllInvites(r.Context(), s.identity, filter, s.nowUTC())
+	if err != nil {
+		logAndHTTPError(w, r, http.StatusBadGateway, "identity unavailable", err, "list invites for platform admin")
+		return
+	}
+	writeJSON(w, AdminInvitesResponse{Invites: invites})
+}
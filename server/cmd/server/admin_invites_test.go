@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandleAdminInvitesListsOpenInvitesAcrossOrgs(t *testing.T) {
+	t.Setenv("ADMIN_EMAIL", "admin@example.com")
+	t.Setenv("ADMIN_PASSWORD", "change-me")
+
+	now := time.Date(2026, 5, 20, 12, 0, 0, 0, time.UTC)
+	server := &Server{
+		authorizer: fakeAuthorizer{},
+		identity: &fakeIdentityStore{
+			listOrganizationsFunc: func(ctx context.Context) ([]IdentityOrg, error) {
+				return []IdentityOrg{{ID: "team-1", Slug: "acme", Name: "Acme"}, {ID: "team-2", Slug: "globex", Name: "Globex"}}, nil
+			},
+			listOrganizationMembershipsFunc: func(ctx context.Context, orgSlug string) ([]IdentityMembership, error) {
+				switch orgSlug {
+				case "acme":
+					return []IdentityMembership{
+						{Email: "member@acme.test", Confirmed: true, InvitedAt: now.Add(-time.Hour)},
+						{Email: "old@acme.test", RoleSlugs: []string{"dep1"}, InvitedAt: now.Add(-8 * 24 * time.Hour)},
+						{Email: "new@acme.test", RoleSlugs: []string{"dep2"}, IsOrgAdmin: true, InvitedAt: now.Add(-time.Hour)},
+					}, nil
+				default:
+					return []IdentityMembership{{Email: "ops@globex.test", RoleSlugs: []string{"ops"}, InvitedAt: now.Add(-2 * time.Hour)}}, nil
+				}
+			},
+		},
+		tmpl:        testTemplates(),
+		enforceAuth: true,
+		now:         func() time.Time { return now },
+	}
+	list := func(query string) []AdminInvite {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/admin/invites"+query, nil)
+		req.AddCookie(&http.Cookie{Name: "attesta_session", Value: platformAdminSessionValue()})
+		rec := httptest.NewRecorder()
+		server.handleAdminInvites(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%q status = %d body = %s", query, rec.Code, rec.Body.String())
+		}
+		var response AdminInvitesResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("decode invites: %v", err)
+		}
+		return response.Invites
+	}
+	emails := func(invites []AdminInvite) []string {
+		out := []string{}
+		for _, invite := range invites {
+			out = append(out, invite.Email)
+		}
+		return out
+	}
+
+	invites := list("")
+	if got := emails(invites); len(got) != 3 || got[0] != "new@acme.test" || got[1] != "ops@globex.test" || got[2] != "old@acme.test" {
+		t.Fatalf("invites = %v", got)
+	}
+	if invites[0].OrgName != "Acme" || invites[0].Status != "pending" || len(invites[0].Roles) != 2 || invites[0].Roles[1] != "org-admin" {
+		t.Fatalf("first invite = %#v", invites[0])
+	}
+	if invites[2].Status != "expired" || !invites[2].ExpiresAt.Equal(now.Add(-24*time.Hour)) {
+		t.Fatalf("expired invite = %#v", invites[2])
+	}
+	if got := emails(list("?status=expired")); len(got) != 1 || got[0] != "old@acme.test" {
+		t.Fatalf("expired filter = %v", got)
+	}
+	if got := emails(list("?status=pending&org=globex")); len(got) != 1 || got[0] != "ops@globex.test" {
+		t.Fatalf("org filter = %v", got)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/invites?status=accepted", nil)
+	req.AddCookie(&http.Cookie{Name: "attesta_session", Value: platformAdminSessionValue()})
+	rec := httptest.NewRecorder()
+	server.handleAdminInvites(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("accepted status filter = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	server.authorizer = fakeAuthorizer{accessDecide: func(*AccountUser, string, string, map[string]interface{}, string) (bool, error) { return false, nil }}
+	req = httptest.NewRequest(http.MethodGet, "/admin/invites", nil)
+	req.AddCookie(&http.Cookie{Name: "attesta_session", Value: platformAdminSessionValue()})
+	rec = httptest.NewRecorder()
+	server.handleAdminInvites(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("denied status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const amendmentReasonMaxRunes = 1000
+
+// currentStepData returns the value a completed substep currently shows: the
+// latest amendment when there is one, otherwise the original submission.
+func currentStepData(step ProcessStep) map[string]interface{} {
+	if n := len(step.Amendments); n > 0 {
+		return step.Amendments[n-1].Data
+	}
+	return step.Data
+}
+
+func currentStepDigest(step ProcessStep) string {
+	if n := len(step.Amendments); n > 0 {
+		return step.Amendments[n-1].Digest
+	}
+	return digestPayload(step.Data)
+}
+
+// stepDataHistory lists the original submission followed by every amendment.
+func stepDataHistory(step ProcessStep) []map[string]interface{} {
+	history := []map[string]interface{}{step.Data}
+	for _, amendment := range step.Amendments {
+		history = append(history, amendment.Data)
+	}
+	return history
+}
+
+func notarizedAmendments(step ProcessStep) []NotarizedAmendment {
+	if len(step.Amendments) == 0 {
+		return nil
+	}
+	amendments := make([]NotarizedAmendment, 0, len(step.Amendments))
+	for _, amendment := range step.Amendments {
+		entry := NotarizedAmendment{
+			Payload:        amendment.Data,
+			Digest:         amendment.Digest,
+			PreviousDigest: amendment.PreviousDigest,
+			Reason:         amendment.Reason,
+			AmendedAt:      rfc3339UTC(amendment.AmendedAt),
+		}
+		if amendment.AmendedBy != nil {
+			entry.AmendedBy = amendment.AmendedBy.ID
+			entry.AmendedRole = amendment.AmendedBy.Role
+		}
+		amendments = append(amendments, entry)
+	}
+	return amendments
+}
+
+func (s *Server) handleAmendSubstep(w http.ResponseWriter, r *http.Request, processID, substepID string) {
+	user, _, ok := s.requireAuthenticatedPost(w, r)
+	if !ok {
+		return
+	}
+	workflowKey, cfg, selected := s.selectedWorkflowOrRedirectHome(w, r)
+	if !selected {
+		return
+	}
+	actor := actorForSubstepUser(user, workflowKey)
+
+	ctx := r.Context()
+	process, err := s.loadProcess(ctx, processID)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			logRequestError(r, err, "failed to load process %s for substep %s amendment", processID, substepID)
+		}
+		s.renderActionErrorForRequest(w, r, http.StatusNotFound, "Process not found.", process, actor)
+		return
+	}
+	if !s.processBelongsToWorkflow(process, workflowKey) {
+		s.renderActionErrorForRequest(w, r, http.StatusNotFound, "Process not found.", process, actor)
+		return
+	}
+	if !processAcceptsAmendments(process) {
+		s.renderActionErrorForRequest(w, r, http.StatusConflict, "Stream is already ended.", process, actor)
+		return
+	}
+	substep, step, err := findSubstep(cfg.Workflow, substepID)
+	if err != nil {
+		s.renderActionErrorForRequest(w, r, http.StatusNotFound, "Substep not found.", process, actor)
+		return
+	}
+	if progress, ok := process.Progress[substepID]; !ok || progress.State != "done" {
+		s.renderActionErrorForRequest(w, r, http.StatusConflict, "Only completed substeps can be amended.", process, actor)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, completionFormMaxBytes())
+	if err := r.ParseForm(); err != nil {
+		if isRequestTooLarge(err) {
+			s.renderActionErrorForRequest(w, r, http.StatusRequestEntityTooLarge, "File too large.", process, actor)
+			return
+		}
+		s.renderActionErrorForRequest(w, r, http.StatusBadRequest, "Invalid form.", process, actor)
+		return
+	}
+	reason := strings.TrimSpace(r.FormValue("reason"))
+	if reason == "" {
+		s.renderActionErrorForRequest(w, r, http.StatusBadRequest, "Amendment reason is required.", process, actor)
+		return
+	}
+	if len([]rune(reason)) > amendmentReasonMaxRunes {
+		s.renderActionErrorForRequest(w, r, http.StatusBadRequest, "Reason is too long.", process, actor)
+		return
+	}
+
+	activeRole := strings.TrimSpace(r.FormValue("activeRole"))
+	if activeRole == "" && len(actor.RoleSlugs) == 1 {
+		activeRole = actor.RoleSlugs[0]
+	}
+	allowedRoles := substepRoles(substep)
+	if !s.enforceAuth && activeRole == "" && len(allowedRoles) > 0 {
+		activeRole = allowedRoles[0]
+		actor.RoleSlugs = append([]string(nil), allowedRoles...)
+	}
+	if activeRole == "" || !containsRole(actor.RoleSlugs, activeRole) || !containsRole(allowedRoles, activeRole) {
+		s.renderActionErrorForRequest(w, r, http.StatusForbidden, "Not authorized for this action.", process, actor)
+		return
+	}
+	actor.Role = activeRole
+	if !substepAssignedTo(substep, actor.ID) {
+		s.renderActionErrorForRequest(w, r, http.StatusForbidden, "This step is assigned to someone else.", process, actor)
+		return
+	}
+
+	if s.authorizer == nil {
+		s.renderActionErrorForRequest(w, r, http.StatusBadGateway, "Cerbos check failed.", process, actor)
+		return
+	}
+	allowed, err := s.authorizer.CanComplete(ctx, actor, processID, workflowKey, substep, step.Order, step.OrganizationSlug, true)
+	if err != nil {
+		logRequestError(r, err, "cerbos check failed for process %s substep %s amendment", processID, substepID)
+		status, message := authorizerErrorResponse(err)
+		s.renderActionErrorForRequest(w, r, status, message, process, actor)
+		return
+	}
+	if !allowed {
+		s.renderActionErrorForRequest(w, r, http.StatusForbidden, "Not authorized for this action.", process, actor)
+		return
+	}
+	if isAcknowledgeSubstep(substep) {
+		s.renderActionErrorForRequest(w, r, http.StatusConflict, "Acknowledgements cannot be amended.", process, actor)
+		return
+	}
+	if isSignatureSubstep(substep) {
+		s.renderActionErrorForRequest(w, r, http.StatusConflict, "Signatures cannot be amended.", process, actor)
+		return
+	}
+
+	override := process.Overrides[strings.TrimSpace(substepID)]
+	effective := effectiveSubstep(substep, &override)
+	if strings.TrimSpace(override.SubstepID) == "" {
+		effective = substep
+	}
+	now := s.nowUTC()
+	uploads := &savedAttachments{}
+	r = r.WithContext(withSavedAttachments(r.Context(), uploads))
+	payload, err := s.parseCompletionPayload(r, process.ID, effective, now)
+	if err != nil {
+		s.discardSavedAttachments(r, uploads)
+		switch {
+		case errors.Is(err, ErrAttachmentTooLarge):
+			s.renderActionErrorForRequest(w, r, http.StatusRequestEntityTooLarge, "File too large.", process, actor)
+		case errors.Is(err, errAttachmentRejected):
+			logRequestError(r, err, "rejected upload for process %s substep %s", processID, substepID)
+			s.renderActionErrorForRequest(w, r, http.StatusUnprocessableEntity, "File rejected by virus scan.", process, actor)
+		case errors.Is(err, errAttachmentScanFailed):
+			logRequestError(r, err, "failed to scan upload for process %s substep %s", processID, substepID)
+			s.renderActionErrorForRequest(w, r, http.StatusBadGateway, "File scan failed.", process, actor)
+		case errors.Is(err, errInvalidForm):
+			s.renderActionErrorForRequest(w, r, http.StatusBadRequest, "Invalid form.", process, actor)
+		case errors.Is(err, errPayloadTooComplex):
+			s.renderActionErrorForRequest(w, r, http.StatusUnprocessableEntity, payloadTooComplexMessage, process, actor)
+		default:
+			s.renderActionErrorForRequest(w, r, http.StatusBadRequest, err.Error(), process, actor)
+		}
+		return
+	}
+
+	process, err = s.processService().AmendSubstep(ctx, AmendSubstepCmd{
+		Process:     process,
+		WorkflowKey: workflowKey,
+		SubstepID:   substepID,
+		Actor:       actor,
+		Payload:     payload,
+		Reason:      reason,
+		Now:         now,
+	})
+	if err != nil {
+		if payloadNotPersisted(err) {
+			s.discardSavedAttachments(r, uploads)
+		}
+		switch {
+		case errors.Is(err, ErrIllegalStatusTransition):
+			s.renderActionErrorForRequest(w, r, http.StatusConflict, "Stream is already ended.", process, actor)
+		case errors.Is(err, ErrNotarization):
+			logRequestError(r, err, "failed to notarize amendment for process %s substep %s", processID, substepID)
+			s.renderActionErrorForRequest(w, r, http.StatusInternalServerError, "Failed to notarize payload.", process, actor)
+		default:
+			logRequestError(r, err, "failed to amend process %s substep %s", processID, substepID)
+			s.renderActionErrorForRequest(w, r, http.StatusInternalServerError, "Failed to update process.", process, actor)
+		}
+		return
+	}
+
+	s.sse.Broadcast("process:"+workflowKey+":"+processID, "process-updated")
+	nextReq := cloneRequestWithSelectedSubstep(r, substepID)
+	if isProcessContentTargetRequest(r) || isHTMXRequest(r) {
+		s.renderProcessContent(w, nextReq, process, actor, "")
+		return
+	}
+	s.renderDepartmentProcessPage(w, nextReq, process, actor, "")
+}
+
+func amendedAtDisplay(step ProcessStep) (string, string) {
+	n := len(step.Amendments)
+	if n == 0 || step.Amendments[n-1].AmendedAt.IsZero() {
+		return "", ""
+	}
+	amendedAt := step.Amendments[n-1].AmendedAt
+	return humanReadableTraceabilityTime(amendedAt), rfc3339UTC(amendedAt)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestHandleAmendSubstep(t *testing.T) {
+	store := NewMemoryStore()
+	server, processID, fixedNow := newServerForCompleteTests(t, store, fakeAuthorizer{})
+	post := func(substepID, action string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/process/"+processID+"/substep/"+substepID+"/"+action, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		if action == "amend" {
+			server.handleAmendSubstep(rec, req, processID, substepID)
+		} else {
+			server.handleCompleteSubstep(rec, req, processID, substepID)
+		}
+		return rec
+	}
+
+	if rec := post("1.1", "amend", url.Values{"value": {`{"status":"fixed"}`}, "reason": {"typo"}}); rec.Code != http.StatusConflict {
+		t.Fatalf("amend before completion status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if rec := post("1.1", "complete", url.Values{"value": {`{"status":"ok"}`}}); rec.Code != http.StatusOK {
+		t.Fatalf("complete status = %d", rec.Code)
+	}
+	if rec := post("1.1", "amend", url.Values{"value": {`{"status":"fixed"}`}}); rec.Code != http.StatusBadRequest {
+		t.Fatalf("amend without reason status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	id, _ := primitive.ObjectIDFromHex(processID)
+	before, _ := store.SnapshotProcess(id)
+	before.Progress = normalizeProgressKeys(before.Progress)
+	beforeRoot := buildNotarizedExport(testFormataRuntimeConfig().Workflow, &before).Merkle.Root
+
+	if rec := post("1.1", "amend", url.Values{"value": {`{"status":"fixed"}`}, "reason": {"typo"}}); rec.Code != http.StatusOK {
+		t.Fatalf("amend status = %d body=%q", rec.Code, rec.Body.String())
+	}
+
+	after, _ := store.SnapshotProcess(id)
+	after.Progress = normalizeProgressKeys(after.Progress)
+	step := after.Progress["1.1"]
+	if step.Data["status"] != "ok" {
+		t.Fatalf("expected original submission to be preserved, got %#v", step.Data)
+	}
+	if len(step.Amendments) != 1 {
+		t.Fatalf("amendments = %#v, want one", step.Amendments)
+	}
+	amendment := step.Amendments[0]
+	originalDigest := digestPayload(step.Data)
+	if amendment.PreviousDigest != originalDigest || amendment.Reason != "typo" || !amendment.AmendedAt.Equal(fixedNow) || amendment.Data["status"] != "fixed" {
+		t.Fatalf("unexpected amendment %#v", amendment)
+	}
+	if got := currentStepData(step)["status"]; got != "fixed" {
+		t.Fatalf("current value = %v, want fixed", got)
+	}
+
+	notarizations := store.Notarizations()
+	if len(notarizations) != 2 {
+		t.Fatalf("notarizations = %d, want 2", len(notarizations))
+	}
+	if last := notarizations[1]; last.AmendsDigest != originalDigest || last.FakeNotary.Digest != amendment.Digest {
+		t.Fatalf("unexpected amendment notarization %#v", last)
+	}
+
+	export := buildNotarizedExport(testFormataRuntimeConfig().Workflow, &after)
+	entry := export.Steps[0].Substeps[0]
+	if entry.Digest != originalDigest || len(entry.Amendments) != 1 || entry.Amendments[0].PreviousDigest != originalDigest {
+		t.Fatalf("unexpected export entry %#v", entry)
+	}
+	if export.Merkle.Root == beforeRoot {
+		t.Fatal("expected the Merkle root to cover the amendment chain")
+	}
+
+	if err := store.UpdateProcessTermination(t.Context(), id, "workflow", ProcessTermination{Reason: "cancelled", EndedAt: fixedNow}); err != nil {
+		t.Fatalf("UpdateProcessTermination: %v", err)
+	}
+	if rec := post("1.1", "amend", url.Values{"value": {`{"status":"late"}`}, "reason": {"after the end"}}); rec.Code != http.StatusConflict {
+		t.Fatalf("amend after termination status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if terminated, _ := store.SnapshotProcess(id); len(normalizeProgressKeys(terminated.Progress)["1.1"].Amendments) != 1 {
+		t.Fatal("expected no amendment on a terminated process")
+	}
+}
+
+func TestAmendSubstepChainsDigests(t *testing.T) {
+	store := NewMemoryStore()
+	id := store.SeedProcess(Process{Progress: map[string]ProcessStep{"1_1": {State: "done", Data: map[string]interface{}{"v": "a"}}}})
+	service := &ProcessService{store: store}
+	process, err := service.reloadProcess(t.Context(), id)
+	if err != nil {
+		t.Fatalf("reloadProcess: %v", err)
+	}
+	for _, value := range []string{"b", "c"} {
+		process, err = service.AmendSubstep(t.Context(), AmendSubstepCmd{
+			Process:   process,
+			SubstepID: "1.1",
+			Payload:   map[string]interface{}{"v": value},
+			Reason:    "fix",
+		})
+		if err != nil {
+			t.Fatalf("AmendSubstep(%s): %v", value, err)
+		}
+	}
+	amendments := process.Progress["1.1"].Amendments
+	if len(amendments) != 2 || amendments[1].PreviousDigest != amendments[0].Digest {
+		t.Fatalf("expected chained amendments, got %#v", amendments)
+	}
+	if _, err := service.AmendSubstep(t.Context(), AmendSubstepCmd{Process: process, SubstepID: "1.2"}); err != ErrSubstepNotDone {
+		t.Fatalf("AmendSubstep on pending substep error = %v, want %v", err, ErrSubstepNotDone)
+	}
+
+	// A termination landing after the caller read the process is caught by
+	// the store.
+	if err := store.UpdateProcessTermination(t.Context(), id, "", ProcessTermination{Reason: "cancelled"}); err != nil {
+		t.Fatalf("UpdateProcessTermination: %v", err)
+	}
+	if _, err := service.AmendSubstep(t.Context(), AmendSubstepCmd{Process: process, SubstepID: "1.1", Payload: map[string]interface{}{"v": "d"}, Reason: "fix"}); !errors.Is(err, ErrIllegalStatusTransition) {
+		t.Fatalf("AmendSubstep on terminated process error = %v, want %v", err, ErrIllegalStatusTransition)
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	workflowAnalyticsDefaultLimit = 200
+	workflowAnalyticsMaxLimit     = 1000
+)
+
+type WorkflowAnalyticsResponse struct {
+	WorkflowKey  string                     `json:"workflow_key"`
+	ProcessCount int                        `json:"process_count"`
+	Substeps     []SubstepDurationAnalytics `json:"substeps"`
+}
+
+type SubstepDurationAnalytics struct {
+	SubstepID      string                  `json:"substep_id"`
+	Title          string                  `json:"title"`
+	Samples        int                     `json:"samples"`
+	AverageSeconds float64                 `json:"average_seconds"`
+	Roles          []RoleDurationAnalytics `json:"roles"`
+}
+
+type RoleDurationAnalytics struct {
+	Role           string  `json:"role"`
+	Samples        int     `json:"samples"`
+	AverageSeconds float64 `json:"average_seconds"`
+}
+
+type durationTotal struct {
+	samples int
+	seconds int64
+}
+
+func (t *durationTotal) add(seconds int64) {
+	t.samples++
+	t.seconds += seconds
+}
+
+func (t durationTotal) average() float64 {
+	if t.samples == 0 {
+		return 0
+	}
+	return float64(t.seconds) / float64(t.samples)
+}
+
+// buildWorkflowAnalytics averages recorded substep durations, overall and per
+// completing role. Substeps completed before durations were tracked are
+// ignored rather than counted as zero.
+func buildWorkflowAnalytics(workflowKey string, def WorkflowDef, processes []Process) WorkflowAnalyticsResponse {
+	response := WorkflowAnalyticsResponse{
+		WorkflowKey:  workflowKey,
+		ProcessCount: len(processes),
+		Substeps:     []SubstepDurationAnalytics{},
+	}
+	for _, sub := range orderedSubsteps(def) {
+		total := durationTotal{}
+		byRole := map[string]*durationTotal{}
+		for idx := range processes {
+			progress, ok := processes[idx].Progress[sub.SubstepID]
+			if !ok || progress.State != "done" || progress.DurationSeconds == nil {
+				continue
+			}
+			total.add(*progress.DurationSeconds)
+			role := ""
+			if progress.DoneBy != nil {
+				role = strings.TrimSpace(progress.DoneBy.Role)
+			}
+			if byRole[role] == nil {
+				byRole[role] = &durationTotal{}
+			}
+			byRole[role].add(*progress.DurationSeconds)
+		}
+		entry := SubstepDurationAnalytics{
+			SubstepID:      sub.SubstepID,
+			Title:          sub.Title,
+			Samples:        total.samples,
+			AverageSeconds: total.average(),
+			Roles:          make([]RoleDurationAnalytics, 0, len(byRole)),
+		}
+		for role, roleTotal := range byRole {
+			entry.Roles = append(entry.Roles, RoleDurationAnalytics{
+				Role:           role,
+				Samples:        roleTotal.samples,
+				AverageSeconds: roleTotal.average(),
+			})
+		}
+		sort.Slice(entry.Roles, func(i, j int) bool { return entry.Roles[i].Role < entry.Roles[j].Role })
+		response.Substeps = append(response.Substeps, entry)
+	}
+	return response
+}
+
+// handleWorkflowAnalytics serves GET /my/streams/:key/analytics?limit=N over
+// the N most recent processes of the stream.
+func (s *Server) handleWorkflowAnalytics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, _, ok := s.requireAuthenticatedPost(w, r)
+	if !ok {
+		return
+	}
+	workflowKey, cfg, err := s.selectedWorkflowUnvalidated(r)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if s.store == nil {
+		http.Error(w, "store not configured", http.StatusInternalServerError)
+		return
+	}
+	limit := workflowAnalyticsDefaultLimit
+	if raw := strings.TrimSpace(r.URL.Query().Get("limit")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > workflowAnalyticsMaxLimit {
+			http.Error(w, "limit must be between 1 and 1000", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	processes, err := s.store.ListRecentProcessesByWorkflow(r.Context(), workflowKey, int64(limit))
+	if err != nil {
+		logAndHTTPError(w, r, http.StatusInternalServerError, "failed to load analytics", err, "failed to list processes for workflow %s analytics", workflowKey)
+		return
+	}
+	processes = s.visibleProcesses(user, cfg, processes)
+	for idx := range processes {
+		processes[idx].Progress = normalizeProgressKeys(processes[idx].Progress)
+	}
+	writeJSON(w, buildWorkflowAnalytics(workflowKey, cfg.Workflow, processes))
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCompleteSubstepRecordsDurationSinceAvailable(t *testing.T) {
+	cfg := testRuntimeConfig()
+	createdAt := time.Date(2026, 2, 2, 9, 0, 0, 0, time.UTC)
+	firstDone := createdAt.Add(30 * time.Minute)
+	now := firstDone.Add(90 * time.Second)
+	store := NewMemoryStore()
+	svc := &ProcessService{store: store, now: func() time.Time { return now }}
+	processID := store.SeedProcess(Process{
+		ID:        primitive.NewObjectID(),
+		CreatedAt: createdAt,
+		Status:    "active",
+		Progress: map[string]ProcessStep{
+			"1_1": {State: "done", DoneAt: &firstDone},
+			"1_2": {State: "pending"},
+		},
+	})
+	process, err := store.LoadProcessByID(t.Context(), processID)
+	if err != nil {
+		t.Fatalf("LoadProcessByID: %v", err)
+	}
+	process.Progress = normalizeProgressKeys(process.Progress)
+	if got := substepAvailableAt(cfg.Workflow, process, "1.1"); !got.Equal(createdAt) {
+		t.Fatalf("first substep available at %s, want creation time", got)
+	}
+
+	updated, err := svc.CompleteSubstep(t.Context(), CompleteSubstepCmd{
+		Process:     process,
+		WorkflowKey: "workflow",
+		SubstepID:   "1.2",
+		Substep:     cfg.Workflow.Steps[0].Substep[1],
+		Actor:       Actor{ID: "user-1", Role: "dep1"},
+		Payload:     map[string]interface{}{"note": "ok"},
+		Config:      cfg,
+		Now:         now,
+	})
+	if err != nil {
+		t.Fatalf("CompleteSubstep: %v", err)
+	}
+	step := updated.Progress["1.2"]
+	if step.DurationSeconds == nil || *step.DurationSeconds != 90 {
+		t.Fatalf("duration = %v, want 90", step.DurationSeconds)
+	}
+	export := buildNotarizedExport(cfg.Workflow, updated)
+	if got := export.Steps[0].Substeps[1].DurationSeconds; got == nil || *got != 90 {
+		t.Fatalf("notarized duration = %v, want 90", got)
+	}
+}
+
+func TestHandleWorkflowAnalyticsAveragesByRole(t *testing.T) {
+	store := NewMemoryStore()
+	duration := func(seconds int64) *int64 { return &seconds }
+	for _, progress := range []map[string]ProcessStep{
+		{"1_1": {State: "done", DoneBy: &Actor{Role: "dep1"}, DurationSeconds: duration(60)}},
+		{"1_1": {State: "done", DoneBy: &Actor{Role: "dep1"}, DurationSeconds: duration(120)}},
+		{"1_1": {State: "done", DoneBy: &Actor{Role: "qa"}, DurationSeconds: duration(300)}},
+		{"1_1": {State: "done", DoneBy: &Actor{Role: "dep1"}}},
+	} {
+		store.SeedProcess(Process{ID: primitive.NewObjectID(), WorkflowKey: "workflow", CreatedAt: time.Now().UTC(), Progress: progress})
+	}
+	server := &Server{store: store}
+	analytics := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/analytics"+query, nil)
+		req = req.WithContext(context.WithValue(req.Context(), workflowContextKey{}, workflowContextValue{
+			Key: "workflow",
+			Cfg: testRuntimeConfig(),
+		}))
+		rec := httptest.NewRecorder()
+		server.handleWorkflowAnalytics(rec, req)
+		return rec
+	}
+
+	rec := analytics("")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	var response WorkflowAnalyticsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode analytics: %v", err)
+	}
+	if response.ProcessCount != 4 || len(response.Substeps) != 7 {
+		t.Fatalf("unexpected analytics shape %#v", response)
+	}
+	first := response.Substeps[0]
+	if first.SubstepID != "1.1" || first.Samples != 3 || first.AverageSeconds != 160 {
+		t.Fatalf("unexpected substep totals %#v", first)
+	}
+	want := []RoleDurationAnalytics{{Role: "dep1", Samples: 2, AverageSeconds: 90}, {Role: "qa", Samples: 1, AverageSeconds: 300}}
+	if len(first.Roles) != 2 || first.Roles[0] != want[0] || first.Roles[1] != want[1] {
+		t.Fatalf("roles = %#v, want %#v", first.Roles, want)
+	}
+
+	if rec := analytics("?limit=0"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid limit status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
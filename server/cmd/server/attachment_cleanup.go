@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type savedAttachmentsKey struct{}
+
+// savedAttachments records the attachments stored while handling a single
+// completion or amendment, so they can be removed again when the request
+// fails before the payload referencing them is persisted.
+type savedAttachments struct {
+	mu  sync.Mutex
+	ids []primitive.ObjectID
+}
+
+func withSavedAttachments(ctx context.Context, saved *savedAttachments) context.Context {
+	return context.WithValue(ctx, savedAttachmentsKey{}, saved)
+}
+
+// recordSavedAttachment notes id on the recorder carried by ctx, if any.
+func recordSavedAttachment(ctx context.Context, id primitive.ObjectID) {
+	saved, ok := ctx.Value(savedAttachmentsKey{}).(*savedAttachments)
+	if !ok || saved == nil {
+		return
+	}
+	saved.mu.Lock()
+	saved.ids = append(saved.ids, id)
+	saved.mu.Unlock()
+}
+
+// payloadNotPersisted reports whether a ProcessService error left no stored
+// payload: it happened before the progress or amendment write, or the store
+// rolled that write back. Otherwise (a notarization failure without
+// transactions, a reload failure) the stored payload still points at the
+// attachments.
+func payloadNotPersisted(err error) bool {
+	return errors.Is(err, ErrProgressUpdate) || errors.Is(err, ErrIllegalStatusTransition) || errors.Is(err, ErrSubstepNotDone) || errors.Is(err, ErrTransactionRolledBack) || errors.Is(err, ErrDPPInputMissing)
+}
+
+// discardSavedAttachments deletes every attachment recorded for the request.
+// It runs detached from the request context so a cancelled client still gets
+// its uploads cleaned up; failures are logged and otherwise ignored.
+func (s *Server) discardSavedAttachments(r *http.Request, saved *savedAttachments) {
+	if saved == nil {
+		return
+	}
+	saved.mu.Lock()
+	ids := saved.ids
+	saved.ids = nil
+	saved.mu.Unlock()
+	ctx := context.WithoutCancel(r.Context())
+	for _, id := range ids {
+		if err := s.store.DeleteAttachment(ctx, id); err != nil {
+			logRequestError(r, err, "failed to delete orphaned attachment %s", id.Hex())
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	// errAttachmentRejected marks an upload the scanner flagged; completions
+	// fail with 422 and the file is not stored.
+	errAttachmentRejected = errors.New("attachment rejected by scanner")
+	// errAttachmentScanFailed means the scanner could not give a verdict.
+	errAttachmentScanFailed = errors.New("attachment scan failed")
+)
+
+// AttachmentScanner inspects an upload before it is stored. clean is false
+// when the content must be rejected; detail names what was found.
+type AttachmentScanner interface {
+	Scan(ctx context.Context, content io.Reader) (clean bool, detail string, err error)
+}
+
+type noopAttachmentScanner struct{}
+
+func (noopAttachmentScanner) Scan(context.Context, io.Reader) (bool, string, error) {
+	return true, "", nil
+}
+
+// attachmentScannerFromEnv selects the scanner from ATTACHMENT_SCANNER:
+// empty or "none" disables scanning, "clamav" streams uploads to clamd at
+// CLAMAV_ADDR (default localhost:3310).
+func attachmentScannerFromEnv() (AttachmentScanner, error) {
+	switch kind := strings.ToLower(strings.TrimSpace(os.Getenv("ATTACHMENT_SCANNER"))); kind {
+	case "", "none":
+		return noopAttachmentScanner{}, nil
+	case "clamav":
+		timeout := intEnvOr("CLAMAV_TIMEOUT_SECONDS", 30)
+		if timeout <= 0 {
+			timeout = 30
+		}
+		return &clamdScanner{
+			addr:    envOr("CLAMAV_ADDR", "localhost:3310"),
+			timeout: time.Duration(timeout) * time.Second,
+		}, nil
+	default:
+		return nil, fmt.Errorf("ATTACHMENT_SCANNER %q is not supported (use none or clamav)", kind)
+	}
+}
+
+func (s *Server) attachmentScanner() AttachmentScanner {
+	if s == nil || s.scanner == nil {
+		return noopAttachmentScanner{}
+	}
+	return s.scanner
+}
+
+// clamdChunkBytes is the INSTREAM chunk size; uploads are sent in chunks of
+// this size rather than in one piece.
+const clamdChunkBytes = 32 * 1024
+
+// clamdScanner talks to clamd's INSTREAM command over TCP.
+type clamdScanner struct {
+	addr    string
+	timeout time.Duration
+}
+
+func (c *clamdScanner) Scan(ctx context.Context, content io.Reader) (bool, string, error) {
+	dialer := net.Dialer{Timeout: c.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return false, "", err
+	}
+	defer conn.Close()
+	deadline := time.Now().Add(c.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	_ = conn.SetDeadline(deadline)
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return false, "", err
+	}
+	buf := make([]byte, clamdChunkBytes)
+	size := make([]byte, 4)
+	for {
+		n, readErr := content.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err := conn.Write(size); err != nil {
+				return false, "", err
+			}
+			if _, err := conn.Write(buf[:n]); err != nil {
+				return false, "", err
+			}
+		}
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			return false, "", readErr
+		}
+	}
+	binary.BigEndian.PutUint32(size, 0)
+	if _, err := conn.Write(size); err != nil {
+		return false, "", err
+	}
+
+	reply, err := io.ReadAll(conn)
+	if err != nil && len(reply) == 0 {
+		return false, "", err
+	}
+	return parseClamdReply(string(reply))
+}
+
+// parseClamdReply reads "stream: OK", "stream: <signature> FOUND" or
+// "<message> ERROR".
+func parseClamdReply(reply string) (bool, string, error) {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	reply = strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+	switch {
+	case reply == "OK":
+		return true, "", nil
+	case strings.HasSuffix(reply, " FOUND"):
+		return false, strings.TrimSpace(strings.TrimSuffix(reply, " FOUND")), nil
+	case strings.HasSuffix(reply, " ERROR"):
+		return false, "", fmt.Errorf("clamd: %s", strings.TrimSpace(strings.TrimSuffix(reply, " ERROR")))
+	default:
+		return false, "", fmt.Errorf("clamd: unexpected reply %q", reply)
+	}
+}
+
+// scanAttachment runs the configured scanner over data and maps its verdict
+// to errAttachmentRejected / errAttachmentScanFailed.
+func (s *Server) scanAttachment(ctx context.Context, filename string, data []byte) error {
+	clean, detail, err := s.attachmentScanner().Scan(ctx, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", errAttachmentScanFailed, filename, err)
+	}
+	if !clean {
+		return fmt.Errorf("%w: %s: %s", errAttachmentRejected, filename, detail)
+	}
+	return nil
+}
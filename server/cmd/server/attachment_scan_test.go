@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeAttachmentScanner struct {
+	clean   bool
+	detail  string
+	err     error
+	scanned [][]byte
+}
+
+func (f *fakeAttachmentScanner) Scan(_ context.Context, content io.Reader) (bool, string, error) {
+	data, _ := io.ReadAll(content)
+	f.scanned = append(f.scanned, data)
+	return f.clean, f.detail, f.err
+}
+
+func TestParseClamdReply(t *testing.T) {
+	tests := []struct {
+		reply     string
+		wantClean bool
+		detail    string
+		wantErr   bool
+	}{
+		{reply: "stream: OK\x00", wantClean: true},
+		{reply: "stream: Eicar-Test-Signature FOUND\x00", detail: "Eicar-Test-Signature"},
+		{reply: "INSTREAM size limit exceeded. ERROR\x00", wantErr: true},
+		{reply: "garbage", wantErr: true},
+	}
+	for _, tc := range tests {
+		clean, detail, err := parseClamdReply(tc.reply)
+		if clean != tc.wantClean || detail != tc.detail || (err != nil) != tc.wantErr {
+			t.Fatalf("parseClamdReply(%q) = %v, %q, %v", tc.reply, clean, detail, err)
+		}
+	}
+}
+
+func TestClamdScannerStreamsChunks(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("listen: %v", err)
+	}
+	defer listener.Close()
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		command := make([]byte, len("zINSTREAM\x00"))
+		if _, err := io.ReadFull(conn, command); err != nil {
+			return
+		}
+		var body bytes.Buffer
+		size := make([]byte, 4)
+		for {
+			if _, err := io.ReadFull(conn, size); err != nil {
+				return
+			}
+			n := binary.BigEndian.Uint32(size)
+			if n == 0 {
+				break
+			}
+			if _, err := io.CopyN(&body, conn, int64(n)); err != nil {
+				return
+			}
+		}
+		received <- body.Bytes()
+		reply := "stream: OK\x00"
+		if bytes.Contains(body.Bytes(), []byte("EICAR")) {
+			reply = "stream: Eicar-Test-Signature FOUND\x00"
+		}
+		_, _ = conn.Write([]byte(reply))
+	}()
+
+	scanner := &clamdScanner{addr: listener.Addr().String(), timeout: 5 * time.Second}
+	content := append(bytes.Repeat([]byte("a"), clamdChunkBytes+10), []byte("EICAR")...)
+	clean, detail, err := scanner.Scan(context.Background(), bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if clean || detail != "Eicar-Test-Signature" {
+		t.Fatalf("Scan = %v, %q, want infected", clean, detail)
+	}
+	if got := <-received; !bytes.Equal(got, content) {
+		t.Fatalf("clamd received %d bytes, want %d", len(got), len(content))
+	}
+}
+
+func TestAttachmentScannerFromEnv(t *testing.T) {
+	t.Setenv("ATTACHMENT_SCANNER", "")
+	if scanner, err := attachmentScannerFromEnv(); err != nil || scanner != (noopAttachmentScanner{}) {
+		t.Fatalf("default scanner = %#v, %v", scanner, err)
+	}
+	t.Setenv("ATTACHMENT_SCANNER", "clamav")
+	t.Setenv("CLAMAV_ADDR", "clamd:3310")
+	scanner, err := attachmentScannerFromEnv()
+	if err != nil {
+		t.Fatalf("clamav scanner: %v", err)
+	}
+	if clamd, ok := scanner.(*clamdScanner); !ok || clamd.addr != "clamd:3310" || clamd.timeout != 30*time.Second {
+		t.Fatalf("unexpected clamav scanner %#v", scanner)
+	}
+	t.Setenv("ATTACHMENT_SCANNER", "sophos")
+	if _, err := attachmentScannerFromEnv(); err == nil {
+		t.Fatal("expected unsupported scanner error")
+	}
+}
+
+func TestHandleCompleteSubstepRejectsInfectedUpload(t *testing.T) {
+	body := "value=" + url.QueryEscape(`{"status":"ok","evidence":"data:text/plain;base64,aGVsbG8="}`)
+	tests := []struct {
+		name     string
+		scanner  *fakeAttachmentScanner
+		wantCode int
+	}{
+		{name: "infected", scanner: &fakeAttachmentScanner{detail: "Eicar-Test-Signature"}, wantCode: http.StatusUnprocessableEntity},
+		{name: "scanner down", scanner: &fakeAttachmentScanner{err: errors.New("connection refused")}, wantCode: http.StatusBadGateway},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			store := NewMemoryStore()
+			server, processID, _ := newServerForCompleteTests(t, store, fakeAuthorizer{})
+			server.scanner = tc.scanner
+			req := httptest.NewRequest(http.MethodPost, "/process/"+processID+"/substep/1.1/complete", strings.NewReader(body))
+			req.Header.Set("HX-Request", "true")
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rec := httptest.NewRecorder()
+			server.handleCompleteSubstep(rec, req, processID, "1.1")
+			if rec.Code != tc.wantCode {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tc.wantCode, rec.Body.String())
+			}
+			if len(tc.scanner.scanned) != 1 || string(tc.scanner.scanned[0]) != "hello" {
+				t.Fatalf("scanned = %q, want decoded upload", tc.scanner.scanned)
+			}
+			if len(store.attachments) != 0 {
+				t.Fatalf("expected rejected upload not to be stored, got %d", len(store.attachments))
+			}
+			if len(store.notarizations) != 0 {
+				t.Fatalf("expected no notarization, got %d", len(store.notarizations))
+			}
+		})
+	}
+}
@@ -117,35 +117,52 @@ func TestCurrentUserPropagatesIdentityLookupError(t *testing.T) {
 }
 
 func TestRequestURLsAndCookieHelpers(t *testing.T) {
+	server := &Server{}
 	req := httptest.NewRequest(http.MethodGet, "http://attesta.local/", nil)
-	if got := requestBaseURL(req); got != "http://attesta.local" {
+	if got := server.requestBaseURL(req); got != "http://attesta.local" {
 		t.Fatalf("requestBaseURL = %q", got)
 	}
-	if got := resetRedirectURL(req); got != "http://attesta.local/reset/confirm" {
+	if got := server.resetRedirectURL(req); got != "http://attesta.local/reset/confirm" {
 		t.Fatalf("resetRedirectURL = %q", got)
 	}
-	if got := inviteRedirectURL(req); got != "http://attesta.local/invite/accept" {
+	if got := server.inviteRedirectURL(req); got != "http://attesta.local/invite/accept" {
 		t.Fatalf("inviteRedirectURL = %q", got)
 	}
 
 	t.Setenv("APPWRITE_RESET_REDIRECT_URL", "https://app.example/reset/confirm")
-	if got := resetRedirectURL(req); got != "https://app.example/reset/confirm" {
+	if got := server.resetRedirectURL(req); got != "https://app.example/reset/confirm" {
 		t.Fatalf("configured reset redirect = %q", got)
 	}
 	t.Setenv("APPWRITE_INVITE_REDIRECT_URL", "https://app.example/invite/accept")
-	if got := inviteRedirectURL(req); got != "https://app.example/invite/accept" {
+	if got := server.inviteRedirectURL(req); got != "https://app.example/invite/accept" {
 		t.Fatalf("configured invite redirect = %q", got)
 	}
 
+	server.trustedProxies = testTrustedProxies(t, "192.0.2.0/24")
 	secureReq := httptest.NewRequest(http.MethodGet, "http://attesta.local/", nil)
 	secureReq.Header.Set("X-Forwarded-Proto", "https")
-	if got := requestBaseURL(secureReq); got != "https://attesta.local" {
+	if got := server.requestBaseURL(secureReq); got != "https://attesta.local" {
 		t.Fatalf("secure requestBaseURL = %q", got)
 	}
+	secureReq.Header.Set("X-Forwarded-Host", "attesta.example.com")
+	if got := server.requestBaseURL(secureReq); got != "https://attesta.example.com" {
+		t.Fatalf("forwarded host requestBaseURL = %q", got)
+	}
+	forwardedReq := httptest.NewRequest(http.MethodGet, "http://attesta.local/", nil)
+	forwardedReq.Header.Set("Forwarded", `proto=https;host=attesta.example.com`)
+	if got := server.requestBaseURL(forwardedReq); got != "https://attesta.example.com" {
+		t.Fatalf("Forwarded requestBaseURL = %q", got)
+	}
+	spoofedReq := httptest.NewRequest(http.MethodGet, "http://attesta.local/", nil)
+	spoofedReq.RemoteAddr = "203.0.113.9:5000"
+	spoofedReq.Header.Set("X-Forwarded-Host", "evil.example")
+	if got := server.requestBaseURL(spoofedReq); got != "http://attesta.local" {
+		t.Fatalf("untrusted forwarded host requestBaseURL = %q", got)
+	}
 
 	hostlessReq := httptest.NewRequest(http.MethodGet, "/", nil)
 	hostlessReq.Host = ""
-	if got := requestBaseURL(hostlessReq); got != "http://localhost:3000" {
+	if got := server.requestBaseURL(hostlessReq); got != "http://localhost:3000" {
 		t.Fatalf("hostless requestBaseURL = %q", got)
 	}
 	noticeReq := httptest.NewRequest(http.MethodGet, "http://attesta.local/login?notice=password_reset_success", nil)
@@ -160,6 +177,37 @@ func TestRequestURLsAndCookieHelpers(t *testing.T) {
 	}
 }
 
+func TestSafeNextPathRejectsOffsiteRedirects(t *testing.T) {
+	cases := map[string]string{
+		"/my/streams/workflow/instance/123": "/my/streams/workflow/instance/123",
+		"//evil.com":                        "/my",
+		"/\\evil.com":                       "/my",
+		"/\t/evil.com":                      "/my",
+		"https://evil.com":                  "/my",
+		"":                                  "/my",
+	}
+	server := &Server{}
+	for next, want := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/login?next="+url.QueryEscape(next), nil)
+		if got := server.safeNextPath(req, "/my"); got != want {
+			t.Fatalf("safeNextPath(%q) = %q, want %q", next, got, want)
+		}
+	}
+
+	t.Setenv("LOGIN_REDIRECT_ALLOWED_PREFIXES", "/my/streams/, /org")
+	server.loginRedirectPrefixes = splitCSVEnv("LOGIN_REDIRECT_ALLOWED_PREFIXES")
+	for next, want := range map[string]string{
+		"/my/streams/workflow/instance/123": "/my/streams/workflow/instance/123",
+		"/org/users":                        "/org/users",
+		"/admin/orgs":                       "/my",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/login?next="+url.QueryEscape(next), nil)
+		if got := server.safeNextPath(req, "/my"); got != want {
+			t.Fatalf("with allowlist safeNextPath(%q) = %q, want %q", next, got, want)
+		}
+	}
+}
+
 func TestSessionSecretFromRequest(t *testing.T) {
 	if _, err := sessionSecretFromRequest(httptest.NewRequest(http.MethodGet, "/", nil)); err == nil {
 		t.Fatal("expected missing cookie error")
@@ -397,25 +445,79 @@ func TestSessionTTLDaysAndNewSessionID(t *testing.T) {
 
 func TestCookieAndPlatformAdminHelpers(t *testing.T) {
 	t.Run("should secure cookie from env forwarded proto and tls", func(t *testing.T) {
+		server := &Server{}
 		t.Setenv("COOKIE_SECURE", "true")
 		req := httptest.NewRequest(http.MethodGet, "http://attesta.local/", nil)
-		if !shouldSecureCookie(req) {
+		if !server.shouldSecureCookie(req) {
 			t.Fatal("expected secure cookie from env")
 		}
 
 		t.Setenv("COOKIE_SECURE", "false")
 		req = httptest.NewRequest(http.MethodGet, "http://attesta.local/", nil)
 		req.Header.Set("X-Forwarded-Proto", "https")
-		if !shouldSecureCookie(req) {
+		if server.shouldSecureCookie(req) {
+			t.Fatal("expected forwarded proto from an untrusted peer to be ignored")
+		}
+		server.trustedProxies = testTrustedProxies(t, "192.0.2.0/24")
+		if !server.shouldSecureCookie(req) {
 			t.Fatal("expected secure cookie from forwarded proto")
 		}
 
 		req = httptest.NewRequest(http.MethodGet, "https://attesta.local/", nil)
-		if !shouldSecureCookie(req) {
+		if !server.shouldSecureCookie(req) {
 			t.Fatal("expected secure cookie from tls request")
 		}
 	})
 
+	t.Run("cookie policy from samesite and domain env", func(t *testing.T) {
+		t.Setenv("COOKIE_SECURE", "")
+		t.Setenv("COOKIE_SAMESITE", "")
+		t.Setenv("COOKIE_DOMAIN", "")
+		if err := validateCookieConfig(); err != nil {
+			t.Fatalf("validateCookieConfig(default) = %v", err)
+		}
+		req := httptest.NewRequest(http.MethodGet, "http://attesta.local/", nil)
+		rec := httptest.NewRecorder()
+		server := &Server{}
+		if err := server.writeSessionCookie(rec, req, IdentitySession{Secret: "secret"}); err != nil {
+			t.Fatalf("writeSessionCookie: %v", err)
+		}
+		cookie := rec.Result().Cookies()[0]
+		if cookie.SameSite != http.SameSiteLaxMode || cookie.Domain != "" || cookie.Secure {
+			t.Fatalf("default cookie = %#v", cookie)
+		}
+
+		t.Setenv("COOKIE_SAMESITE", "Strict")
+		t.Setenv("COOKIE_DOMAIN", "example.com")
+		rec = httptest.NewRecorder()
+		server.clearCookie(rec, req, "attesta_session")
+		cookie = rec.Result().Cookies()[0]
+		if cookie.SameSite != http.SameSiteStrictMode || cookie.Domain != "example.com" || cookie.MaxAge >= 0 {
+			t.Fatalf("strict cleared cookie = %#v", cookie)
+		}
+
+		t.Setenv("COOKIE_SAMESITE", "none")
+		if err := validateCookieConfig(); err == nil || !strings.Contains(err.Error(), "COOKIE_SECURE") {
+			t.Fatalf("validateCookieConfig(none without secure) = %v", err)
+		}
+		rec = httptest.NewRecorder()
+		if err := server.writeSessionCookie(rec, req, IdentitySession{Secret: "secret"}); err != nil {
+			t.Fatalf("writeSessionCookie: %v", err)
+		}
+		if cookie = rec.Result().Cookies()[0]; cookie.SameSite != http.SameSiteNoneMode || !cookie.Secure {
+			t.Fatalf("none cookie = %#v, want forced Secure", cookie)
+		}
+		t.Setenv("COOKIE_SECURE", "true")
+		if err := validateCookieConfig(); err != nil {
+			t.Fatalf("validateCookieConfig(none with secure) = %v", err)
+		}
+
+		t.Setenv("COOKIE_SAMESITE", "sometimes")
+		if err := validateCookieConfig(); err == nil {
+			t.Fatal("expected unsupported COOKIE_SAMESITE error")
+		}
+	})
+
 	t.Run("platform admin helpers require credentials", func(t *testing.T) {
 		t.Setenv("ADMIN_EMAIL", "")
 		t.Setenv("ADMIN_PASSWORD", "")
@@ -124,6 +124,26 @@ func TestHandleInviteAcceptBranches(t *testing.T) {
 		}
 	})
 
+	t.Run("invite already used", func(t *testing.T) {
+		server := &Server{
+			identity: &fakeIdentityStore{
+				acceptInviteFunc: func(ctx context.Context, teamID, membershipID, userID, secret string) (IdentitySession, error) {
+					return IdentitySession{}, ErrInviteAlreadyUsed
+				},
+			},
+			now: time.Now,
+		}
+		req := httptest.NewRequest(http.MethodGet, "/invite/accept?teamId=acme&membershipId=membership-1&userId=user-1&secret=secret-1", nil)
+		rec := httptest.NewRecorder()
+		server.handleInvite(rec, req)
+		if rec.Code != http.StatusConflict {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusConflict)
+		}
+		if cookies := rec.Result().Cookies(); len(cookies) != 0 {
+			t.Fatalf("cookies = %#v, want none", cookies)
+		}
+	})
+
 	t.Run("write session cookie failure", func(t *testing.T) {
 		server := &Server{
 			identity: &fakeIdentityStore{
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"html/template"
 	"net/http"
@@ -122,6 +123,93 @@ func TestHandleInvitePasswordUpdatesCurrentPassword(t *testing.T) {
 	}
 }
 
+func TestHandleInvitePasswordAcceptsJSON(t *testing.T) {
+	now := time.Date(2026, 2, 27, 10, 0, 0, 0, time.UTC)
+	var updatedPassword string
+	server := &Server{
+		identity: &fakeIdentityStore{
+			getSessionFunc: func(ctx context.Context, sessionSecret string) (IdentitySession, error) {
+				return fakeIdentitySession(sessionSecret, "user-1", now.Add(24*time.Hour)), nil
+			},
+			getCurrentUserFunc: func(ctx context.Context, sessionSecret string) (IdentityUser, error) {
+				return IdentityUser{ID: "user-1", Email: "invitee@example.com", OrgSlug: "acme", PasswordSet: false}, nil
+			},
+			updateCurrentPasswordFunc: func(ctx context.Context, sessionSecret, password string) error {
+				updatedPassword = password
+				return nil
+			},
+		},
+		tmpl:        invitePasswordTemplates(),
+		now:         func() time.Time { return now },
+		enforceAuth: true,
+	}
+
+	post := func(body string) (*httptest.ResponseRecorder, PasswordFormResponse) {
+		req := httptest.NewRequest(http.MethodPost, "/invite/password", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.AddCookie(&http.Cookie{Name: "attesta_session", Value: "invite-session"})
+		rec := httptest.NewRecorder()
+		server.handleInvite(rec, req)
+		var response PasswordFormResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("decode response: %v (%s)", err, rec.Body.String())
+		}
+		return rec, response
+	}
+
+	rec, response := post(`{"password":"short"}`)
+	if rec.Code != http.StatusBadRequest || response.OK || response.Field != "password" || response.Error == "" {
+		t.Fatalf("short password = %d %#v", rec.Code, response)
+	}
+	rec, response = post(`{"password":"this-is-strong-enough","confirm_password":"something-else!"}`)
+	if rec.Code != http.StatusBadRequest || response.Field != "confirm_password" {
+		t.Fatalf("mismatch = %d %#v", rec.Code, response)
+	}
+	if updatedPassword != "" {
+		t.Fatalf("expected no update on validation errors, got %q", updatedPassword)
+	}
+	rec, response = post(`{"password":"this-is-strong-enough"}`)
+	if rec.Code != http.StatusOK || !response.OK || response.Redirect != appHomePath {
+		t.Fatalf("success = %d %#v", rec.Code, response)
+	}
+	if updatedPassword != "this-is-strong-enough" {
+		t.Fatalf("updated password = %q", updatedPassword)
+	}
+}
+
+func TestHandleInvitePasswordShowsOrganizationBrand(t *testing.T) {
+	now := time.Date(2026, 2, 27, 10, 0, 0, 0, time.UTC)
+	server := &Server{
+		identity: &fakeIdentityStore{
+			getSessionFunc: func(ctx context.Context, sessionSecret string) (IdentitySession, error) {
+				return fakeIdentitySession(sessionSecret, "user-1", now.Add(24*time.Hour)), nil
+			},
+			getCurrentUserFunc: func(ctx context.Context, sessionSecret string) (IdentityUser, error) {
+				return IdentityUser{ID: "user-1", Email: "invitee@example.com", OrgSlug: "acme", PasswordSet: false}, nil
+			},
+			getOrganizationBySlugFunc: func(ctx context.Context, slug string) (*IdentityOrg, error) {
+				return &IdentityOrg{Slug: slug, Name: "Acme Foods"}, nil
+			},
+		},
+		tmpl: template.Must(template.New("invite-brand").Parse(`
+{{define "invite.html"}}{{if .Brand}}{{.Brand.Name}}|{{.Brand.LogoURL}}{{end}}{{end}}
+`)),
+		now:         func() time.Time { return now },
+		enforceAuth: true,
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/invite/password", nil)
+	req.AddCookie(&http.Cookie{Name: "attesta_session", Value: "invite-session"})
+	rec := httptest.NewRecorder()
+	server.handleInvite(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got := strings.TrimSpace(rec.Body.String()); got != "Acme Foods|" {
+		t.Fatalf("body = %q, want brand without logo", got)
+	}
+}
+
 func TestHandleInvitePasswordBranches(t *testing.T) {
 	now := time.Date(2026, 2, 27, 10, 0, 0, 0, time.UTC)
 	baseServer := func(identity *fakeIdentityStore) *Server {
@@ -297,6 +385,44 @@ func TestHandleResetConfirmCompletesRecovery(t *testing.T) {
 	}
 }
 
+func TestHandleResetConfirmAcceptsJSON(t *testing.T) {
+	var completedPassword string
+	server := &Server{
+		identity: &fakeIdentityStore{
+			completeRecoveryFunc: func(ctx context.Context, userID, secret, password string) error {
+				completedPassword = password
+				return nil
+			},
+		},
+		tmpl: resetTemplates(),
+		now:  time.Now,
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/reset/confirm?userId=user-1&secret=secret-1", strings.NewReader(`{"password":"this-is-strong-enough","confirm_password":"this-is-strong-enough"}`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	rec := httptest.NewRecorder()
+	server.handleResetSet(rec, req)
+
+	var response PasswordFormResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode response: %v (%s)", err, rec.Body.String())
+	}
+	if rec.Code != http.StatusOK || !response.OK || response.Redirect != "/login?notice=password_reset_success" {
+		t.Fatalf("response = %d %#v", rec.Code, response)
+	}
+	if completedPassword != "this-is-strong-enough" {
+		t.Fatalf("completed password = %q", completedPassword)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/reset/confirm?userId=user-1&secret=secret-1", strings.NewReader(`{"password":`))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	server.handleResetSet(rec, req)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Header().Get("Content-Type"), "application/json") {
+		t.Fatalf("malformed body = %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+}
+
 func TestHandleResetSetBranches(t *testing.T) {
 	t.Run("confirm get renders form", func(t *testing.T) {
 		server := &Server{identity: &fakeIdentityStore{}, tmpl: resetTemplates(), now: time.Now}
@@ -176,6 +176,44 @@ func TestHandleLogoutClearsSession(t *testing.T) {
 	}
 }
 
+func TestCurrentUserRejectsRevokedUserSession(t *testing.T) {
+	now := time.Now().UTC()
+	for _, status := range []string{"deleted", "disabled"} {
+		t.Run(status, func(t *testing.T) {
+			var deletedSecret string
+			server := &Server{
+				identity: &fakeIdentityStore{
+					getSessionFunc: func(ctx context.Context, sessionSecret string) (IdentitySession, error) {
+						return fakeIdentitySession(sessionSecret, "user-2", now.Add(time.Hour)), nil
+					},
+					getCurrentUserFunc: func(ctx context.Context, sessionSecret string) (IdentityUser, error) {
+						return IdentityUser{ID: "user-2", Email: "member@example.com", OrgSlug: "acme", Status: status}, nil
+					},
+					deleteSessionFunc: func(ctx context.Context, sessionSecret string) error {
+						deletedSecret = sessionSecret
+						return nil
+					},
+				},
+				enforceAuth: true,
+				now:         func() time.Time { return now },
+			}
+			req := httptest.NewRequest(http.MethodPost, "/my/organization/users", nil)
+			req.AddCookie(&http.Cookie{Name: "attesta_session", Value: "session-2"})
+
+			if _, _, err := server.currentUser(req); !errors.Is(err, ErrIdentityUnauthorized) {
+				t.Fatalf("currentUser error = %v, want %v", err, ErrIdentityUnauthorized)
+			}
+			if deletedSecret != "session-2" {
+				t.Fatalf("deleted secret = %q, want session-2", deletedSecret)
+			}
+			rec := httptest.NewRecorder()
+			if _, _, ok := server.requireAuthenticatedPost(rec, req); ok || rec.Code != http.StatusUnauthorized {
+				t.Fatalf("requireAuthenticatedPost ok = %v status = %d", ok, rec.Code)
+			}
+		})
+	}
+}
+
 func TestHandleLogoutSkipsIdentityDeleteForPlatformAdminSession(t *testing.T) {
 	t.Setenv("ADMIN_EMAIL", "admin@example.com")
 	t.Setenv("ADMIN_PASSWORD", "change-me")
@@ -260,6 +298,51 @@ func TestHandleLoginPageShowsNoticeConfirmation(t *testing.T) {
 	}
 }
 
+func TestHandleLoginPageBrandsOrganizationHint(t *testing.T) {
+	tmpl := parseTestTemplates(t)
+	server := &Server{
+		tmpl: tmpl,
+		identity: &fakeIdentityStore{
+			getOrganizationBySlugFunc: func(ctx context.Context, slug string) (*IdentityOrg, error) {
+				switch slug {
+				case "acme":
+					return &IdentityOrg{Slug: "acme", Name: "Acme Foods", LogoFileID: "logo-1", PublicBranding: true}, nil
+				case "private":
+					return &IdentityOrg{Slug: "private", Name: "Private Labs", LogoFileID: "logo-2"}, nil
+				}
+				return nil, ErrIdentityNotFound
+			},
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/login?org=acme", nil)
+	rec := httptest.NewRecorder()
+	server.handleLogin(rec, req)
+	body := rec.Body.String()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	for _, want := range []string{"Acme Foods", `src="/organization/logo/acme"`, `name="org" value="acme"`} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected branded login page to contain %q, got %q", want, body)
+		}
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/login?org=unknown", nil)
+	rec = httptest.NewRecorder()
+	server.handleLogin(rec, req)
+	if strings.Contains(rec.Body.String(), "login-brand") {
+		t.Fatalf("expected generic login page for unknown org, got %q", rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/login?org=private", nil)
+	rec = httptest.NewRecorder()
+	server.handleLogin(rec, req)
+	if body := rec.Body.String(); strings.Contains(body, "login-brand") || strings.Contains(body, "Private Labs") {
+		t.Fatalf("expected generic login page for org without public branding, got %q", body)
+	}
+}
+
 func TestHandleLoginRedirectsAuthenticatedUserToHome(t *testing.T) {
 	now := time.Date(2026, 2, 26, 15, 0, 0, 0, time.UTC)
 	server := &Server{
@@ -388,7 +471,7 @@ func TestHandleLogoutBranches(t *testing.T) {
 	})
 
 	t.Run("secure cookie follows forwarded proto", func(t *testing.T) {
-		server := &Server{identity: &fakeIdentityStore{}, now: time.Now}
+		server := &Server{identity: &fakeIdentityStore{}, now: time.Now, trustedProxies: testTrustedProxies(t, "192.0.2.0/24")}
 		req := httptest.NewRequest(http.MethodPost, "/logout", nil)
 		req.Header.Set("X-Forwarded-Proto", "https")
 		rec := httptest.NewRecorder()
@@ -114,6 +114,13 @@ func principalForAccountUser(user *AccountUser) (string, []string, map[string]in
 }
 
 func (a *CerbosAuthorizer) CanComplete(ctx context.Context, actor Actor, processID string, workflowKey string, sub WorkflowSub, stepOrder int, stepOrgSlug string, sequenceOK bool) (bool, error) {
+	principal, resourceAttr := completeCheckInput(actor, processID, workflowKey, sub, stepOrder, stepOrgSlug, sequenceOK)
+	return a.checkResourceAction(ctx, principal, "substep", sub.SubstepID, resourceAttr, "complete")
+}
+
+// completeCheckInput builds the principal and substep resource attributes
+// CanComplete sends to Cerbos.
+func completeCheckInput(actor Actor, processID string, workflowKey string, sub WorkflowSub, stepOrder int, stepOrgSlug string, sequenceOK bool) (map[string]interface{}, map[string]interface{}) {
 	rolesAllowed := append([]string(nil), sub.Roles...)
 	if len(rolesAllowed) == 0 && strings.TrimSpace(sub.Role) != "" {
 		rolesAllowed = []string{strings.TrimSpace(sub.Role)}
@@ -121,31 +128,27 @@ func (a *CerbosAuthorizer) CanComplete(ctx context.Context, actor Actor, process
 	if len(actor.RoleSlugs) == 0 && strings.TrimSpace(actor.Role) != "" {
 		actor.RoleSlugs = []string{strings.TrimSpace(actor.Role)}
 	}
-	return a.checkResourceAction(ctx,
-		map[string]interface{}{
-			"id":    actor.ID,
-			"roles": []string{"authenticated"},
-			"attr": map[string]interface{}{
-				"orgSlug":     strings.TrimSpace(actor.OrgSlug),
-				"roleSlugs":   actor.RoleSlugs,
-				"activeRole":  strings.TrimSpace(actor.Role),
-				"workflowKey": strings.TrimSpace(actor.WorkflowKey),
-			},
+	principal := map[string]interface{}{
+		"id":    actor.ID,
+		"roles": []string{"authenticated"},
+		"attr": map[string]interface{}{
+			"orgSlug":     strings.TrimSpace(actor.OrgSlug),
+			"roleSlugs":   actor.RoleSlugs,
+			"activeRole":  strings.TrimSpace(actor.Role),
+			"workflowKey": strings.TrimSpace(actor.WorkflowKey),
 		},
-		"substep",
-		sub.SubstepID,
-		map[string]interface{}{
-			"orgSlug":      strings.TrimSpace(stepOrgSlug),
-			"rolesAllowed": rolesAllowed,
-			"stepOrder":    stepOrder,
-			"substepOrder": sub.Order,
-			"substepId":    sub.SubstepID,
-			"processId":    processID,
-			"workflowKey":  strings.TrimSpace(workflowKey),
-			"sequenceOk":   sequenceOK,
-		},
-		"complete",
-	)
+	}
+	resourceAttr := map[string]interface{}{
+		"orgSlug":      strings.TrimSpace(stepOrgSlug),
+		"rolesAllowed": rolesAllowed,
+		"stepOrder":    stepOrder,
+		"substepOrder": sub.Order,
+		"substepId":    sub.SubstepID,
+		"processId":    processID,
+		"workflowKey":  strings.TrimSpace(workflowKey),
+		"sequenceOk":   sequenceOK,
+	}
+	return principal, resourceAttr
 }
 
 func (a *CerbosAuthorizer) CanDeleteStream(ctx context.Context, user *AccountUser, workflowKey string, createdByUserID string, hasProcesses bool) (bool, error) {
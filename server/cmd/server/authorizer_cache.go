@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errAuthorizerUnavailable is returned while the circuit breaker is open.
+// Callers fail closed and answer 503 instead of waiting on Cerbos.
+var errAuthorizerUnavailable = errors.New("authorizer unavailable: circuit open")
+
+const authorizerUnavailableMessage = "Authorization service unavailable, try again shortly."
+
+type ResilientAuthorizerConfig struct {
+	CacheTTL         time.Duration
+	Timeout          time.Duration
+	FailureThreshold int
+	Cooldown         time.Duration
+}
+
+func resilientAuthorizerConfigFromEnv() ResilientAuthorizerConfig {
+	return ResilientAuthorizerConfig{
+		CacheTTL:         time.Duration(intEnvOr("CERBOS_CACHE_TTL_SECONDS", 5)) * time.Second,
+		Timeout:          time.Duration(intEnvOr("CERBOS_TIMEOUT_MS", 2000)) * time.Millisecond,
+		FailureThreshold: intEnvOr("CERBOS_BREAKER_FAILURES", 5),
+		Cooldown:         time.Duration(intEnvOr("CERBOS_BREAKER_COOLDOWN_SECONDS", 30)) * time.Second,
+	}
+}
+
+// ResilientAuthorizer wraps another Authorizer with a per-call timeout, a
+// short-lived cache of CanComplete decisions and a consecutive-failure
+// circuit breaker. Errors are never cached.
+type ResilientAuthorizer struct {
+	next Authorizer
+	cfg  ResilientAuthorizerConfig
+	now  func() time.Time
+
+	mu        sync.Mutex
+	decisions map[string]cachedDecision
+	failures  int
+	openUntil time.Time
+}
+
+type cachedDecision struct {
+	allowed   bool
+	expiresAt time.Time
+}
+
+func NewResilientAuthorizer(next Authorizer, cfg ResilientAuthorizerConfig, now func() time.Time) *ResilientAuthorizer {
+	if now == nil {
+		now = time.Now
+	}
+	return &ResilientAuthorizer{
+		next:      next,
+		cfg:       cfg,
+		now:       now,
+		decisions: map[string]cachedDecision{},
+	}
+}
+
+func (a *ResilientAuthorizer) CanComplete(ctx context.Context, actor Actor, processID string, workflowKey string, sub WorkflowSub, stepOrder int, stepOrgSlug string, sequenceOK bool) (bool, error) {
+	key := completeDecisionKey(actor, workflowKey, sub, stepOrder, stepOrgSlug, sequenceOK)
+	if allowed, ok := a.cachedDecision(key); ok {
+		return allowed, nil
+	}
+	allowed, err := a.call(ctx, func(ctx context.Context) (bool, error) {
+		return a.next.CanComplete(ctx, actor, processID, workflowKey, sub, stepOrder, stepOrgSlug, sequenceOK)
+	})
+	if err == nil {
+		a.storeDecision(key, allowed)
+	}
+	return allowed, err
+}
+
+func (a *ResilientAuthorizer) CanDeleteStream(ctx context.Context, user *AccountUser, workflowKey string, createdByUserID string, hasProcesses bool) (bool, error) {
+	return a.call(ctx, func(ctx context.Context) (bool, error) {
+		return a.next.CanDeleteStream(ctx, user, workflowKey, createdByUserID, hasProcesses)
+	})
+}
+
+func (a *ResilientAuthorizer) CanAccess(ctx context.Context, user *AccountUser, resourceKind, resourceID string, resourceAttr map[string]interface{}, action string) (bool, error) {
+	return a.call(ctx, func(ctx context.Context) (bool, error) {
+		return a.next.CanAccess(ctx, user, resourceKind, resourceID, resourceAttr, action)
+	})
+}
+
+func (a *ResilientAuthorizer) call(ctx context.Context, check func(context.Context) (bool, error)) (bool, error) {
+	if a.breakerOpen() {
+		return false, errAuthorizerUnavailable
+	}
+	callCtx := ctx
+	if a.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, a.cfg.Timeout)
+		defer cancel()
+	}
+	allowed, err := check(callCtx)
+	// A request the client abandoned says nothing about Cerbos health.
+	if ctx.Err() == nil {
+		a.recordResult(err)
+	}
+	if err != nil {
+		return false, err
+	}
+	return allowed, nil
+}
+
+func (a *ResilientAuthorizer) breakerOpen() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.now().Before(a.openUntil)
+}
+
+func (a *ResilientAuthorizer) recordResult(err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err == nil {
+		a.failures = 0
+		return
+	}
+	a.failures++
+	if a.cfg.FailureThreshold > 0 && a.failures >= a.cfg.FailureThreshold {
+		a.openUntil = a.now().Add(a.cfg.Cooldown)
+		a.failures = 0
+	}
+}
+
+func (a *ResilientAuthorizer) cachedDecision(key string) (bool, bool) {
+	if a.cfg.CacheTTL <= 0 {
+		return false, false
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	decision, ok := a.decisions[key]
+	if !ok {
+		return false, false
+	}
+	if !a.now().Before(decision.expiresAt) {
+		delete(a.decisions, key)
+		return false, false
+	}
+	return decision.allowed, true
+}
+
+func (a *ResilientAuthorizer) storeDecision(key string, allowed bool) {
+	if a.cfg.CacheTTL <= 0 {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := a.now()
+	for existing, decision := range a.decisions {
+		if !now.Before(decision.expiresAt) {
+			delete(a.decisions, existing)
+		}
+	}
+	a.decisions[key] = cachedDecision{allowed: allowed, expiresAt: now.Add(a.cfg.CacheTTL)}
+}
+
+// completeDecisionKey covers every attribute the substep policy reads. The
+// process ID and actor ID are sent to Cerbos but not evaluated, so decisions
+// are shared across processes and users with the same roles.
+func completeDecisionKey(actor Actor, workflowKey string, sub WorkflowSub, stepOrder int, stepOrgSlug string, sequenceOK bool) string {
+	roleSlugs := append([]string(nil), actor.RoleSlugs...)
+	if len(roleSlugs) == 0 && strings.TrimSpace(actor.Role) != "" {
+		roleSlugs = []string{strings.TrimSpace(actor.Role)}
+	}
+	sort.Strings(roleSlugs)
+	rolesAllowed := append([]string(nil), sub.Roles...)
+	if len(rolesAllowed) == 0 && strings.TrimSpace(sub.Role) != "" {
+		rolesAllowed = []string{strings.TrimSpace(sub.Role)}
+	}
+	sort.Strings(rolesAllowed)
+	return strings.Join([]string{
+		strings.TrimSpace(actor.OrgSlug),
+		strings.TrimSpace(actor.WorkflowKey),
+		strings.TrimSpace(actor.Role),
+		strings.Join(roleSlugs, ","),
+		strings.TrimSpace(workflowKey),
+		sub.SubstepID,
+		fmt.Sprintf("%d.%d", stepOrder, sub.Order),
+		strings.TrimSpace(stepOrgSlug),
+		strings.Join(rolesAllowed, ","),
+		fmt.Sprintf("%t", sequenceOK),
+	}, "|")
+}
+
+// authorizerErrorResponse maps a failed authorizer call to the status and
+// message shown to the user.
+func authorizerErrorResponse(err error) (int, string) {
+	if errors.Is(err, errAuthorizerUnavailable) {
+		return http.StatusServiceUnavailable, authorizerUnavailableMessage
+	}
+	return http.StatusBadGateway, "Cerbos check failed."
+}
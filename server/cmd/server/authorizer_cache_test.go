@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// blockingAuthorizer waits for the caller's context, like a hung Cerbos.
+type blockingAuthorizer struct {
+	fakeAuthorizer
+	calls int
+}
+
+func (b *blockingAuthorizer) CanComplete(ctx context.Context, actor Actor, processID string, workflowKey string, sub WorkflowSub, stepOrder int, stepOrgSlug string, sequenceOK bool) (bool, error) {
+	b.calls++
+	<-ctx.Done()
+	return false, ctx.Err()
+}
+
+func TestResilientAuthorizerCachesCompleteDecisions(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	calls := 0
+	authorizer := NewResilientAuthorizer(fakeAuthorizer{
+		decide: func(Actor, string, string, WorkflowSub, int, string, bool) (bool, error) {
+			calls++
+			return true, nil
+		},
+	}, ResilientAuthorizerConfig{CacheTTL: 5 * time.Second}, func() time.Time { return now })
+	actor := Actor{ID: "u1", Role: "dep1", RoleSlugs: []string{"dep1"}, WorkflowKey: "workflow"}
+	sub := WorkflowSub{SubstepID: "1.1", Order: 1, Roles: []string{"dep1"}}
+
+	for _, processID := range []string{"p1", "p2"} {
+		if allowed, err := authorizer.CanComplete(t.Context(), actor, processID, "workflow", sub, 1, "", true); err != nil || !allowed {
+			t.Fatalf("CanComplete(%s) = %v, %v", processID, allowed, err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("expected one upstream call for identical decisions, got %d", calls)
+	}
+	if _, err := authorizer.CanComplete(t.Context(), actor, "p1", "workflow", sub, 1, "", false); err != nil || calls != 2 {
+		t.Fatalf("expected sequenceOK to be part of the key, calls = %d err = %v", calls, err)
+	}
+	now = now.Add(6 * time.Second)
+	if _, err := authorizer.CanComplete(t.Context(), actor, "p1", "workflow", sub, 1, "", true); err != nil || calls != 3 {
+		t.Fatalf("expected expired decision to be refreshed, calls = %d err = %v", calls, err)
+	}
+}
+
+func TestResilientAuthorizerOpensCircuitAfterTimeouts(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	upstream := &blockingAuthorizer{}
+	authorizer := NewResilientAuthorizer(upstream, ResilientAuthorizerConfig{
+		CacheTTL:         time.Minute,
+		Timeout:          10 * time.Millisecond,
+		FailureThreshold: 2,
+		Cooldown:         30 * time.Second,
+	}, func() time.Time { return now })
+	actor := Actor{ID: "u1", Role: "dep1", RoleSlugs: []string{"dep1"}}
+	sub := WorkflowSub{SubstepID: "1.1", Order: 1, Roles: []string{"dep1"}}
+
+	for i := 0; i < 2; i++ {
+		if _, err := authorizer.CanComplete(t.Context(), actor, "p1", "workflow", sub, 1, "", true); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("call %d error = %v, want deadline exceeded", i, err)
+		}
+	}
+	allowed, err := authorizer.CanComplete(t.Context(), actor, "p1", "workflow", sub, 1, "", true)
+	if allowed || !errors.Is(err, errAuthorizerUnavailable) {
+		t.Fatalf("expected open circuit to deny, got %v, %v", allowed, err)
+	}
+	if upstream.calls != 2 {
+		t.Fatalf("expected open circuit to skip upstream, calls = %d", upstream.calls)
+	}
+
+	now = now.Add(31 * time.Second)
+	if _, err := authorizer.CanComplete(t.Context(), actor, "p1", "workflow", sub, 1, "", true); !errors.Is(err, context.DeadlineExceeded) || upstream.calls != 3 {
+		t.Fatalf("expected a probe after cooldown, calls = %d err = %v", upstream.calls, err)
+	}
+}
+
+func TestHandleCompleteSubstepOpenCircuitReturns503(t *testing.T) {
+	store := NewMemoryStore()
+	authorizer := NewResilientAuthorizer(&blockingAuthorizer{}, ResilientAuthorizerConfig{
+		Timeout:          time.Millisecond,
+		FailureThreshold: 1,
+		Cooldown:         time.Minute,
+	}, nil)
+	server, processID, _ := newServerForCompleteTests(t, store, authorizer)
+
+	complete := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/process/"+processID+"/substep/1.1/complete", strings.NewReader("value=%7B%22status%22%3A%22ok%22%7D"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("HX-Request", "true")
+		rr := httptest.NewRecorder()
+		server.handleCompleteSubstep(rr, req, processID, "1.1")
+		return rr
+	}
+	if rr := complete(); rr.Code != http.StatusBadGateway {
+		t.Fatalf("expected first timeout to return %d, got %d", http.StatusBadGateway, rr.Code)
+	}
+	if rr := complete(); rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected open circuit to return %d, got %d", http.StatusServiceUnavailable, rr.Code)
+	}
+}
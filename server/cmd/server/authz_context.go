@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"net/http"
+)
+
+// AuthzContext echoes the inputs authorizer.CanComplete gets for a substep
+// completion, without asking Cerbos, so a decision can be replayed against
+// the policies in isolation. CerbosRequest holds the principal and resource
+// exactly as the Cerbos check request carries them.
+type AuthzContext struct {
+	ProcessID     string            `json:"process_id"`
+	WorkflowKey   string            `json:"workflow_key"`
+	SubstepID     string            `json:"substep_id"`
+	StepOrder     int               `json:"step_order"`
+	StepOrgSlug   string            `json:"step_org_slug"`
+	SequenceOK    bool              `json:"sequence_ok"`
+	Actor         AuthzActor        `json:"actor"`
+	RoleMatch     bool              `json:"role_match"`
+	CerbosRequest AuthzCheckRequest `json:"cerbos_request"`
+}
+
+type AuthzActor struct {
+	ID          string   `json:"id"`
+	OrgSlug     string   `json:"org_slug"`
+	Role        string   `json:"role"`
+	RoleSlugs   []string `json:"role_slugs"`
+	WorkflowKey string   `json:"workflow_key"`
+}
+
+type AuthzCheckRequest struct {
+	Principal map[string]interface{} `json:"principal"`
+	Resource  AuthzResource          `json:"resource"`
+	Action    string                 `json:"action"`
+}
+
+type AuthzResource struct {
+	Kind string                 `json:"kind"`
+	ID   string                 `json:"id"`
+	Attr map[string]interface{} `json:"attr"`
+}
+
+// canInspectAuthorization lets org and platform admins read authz contexts.
+func (s *Server) canInspectAuthorization(ctx context.Context, user *AccountUser) (bool, error) {
+	if !s.enforceAuth {
+		return true, nil
+	}
+	allowed, err := s.canAccessPlatformAdminConsole(ctx, user)
+	if err != nil || allowed {
+		return allowed, err
+	}
+	return s.canAccessOrgAdminConsole(ctx, user)
+}
+
+// handleSubstepAuthzContext serves GET
+// /my/streams/:key/instance/:id/substep/:substepId/authz-context[?activeRole=]
+// to admins. RoleMatch false means the completion POST would refuse before
+// calling Cerbos.
+func (s *Server) handleSubstepAuthzContext(w http.ResponseWriter, r *http.Request, processID, substepID string) {
+	user, _, ok := s.requireAuthenticatedPost(w, r)
+	if !ok {
+		return
+	}
+	allowed, err := s.canInspectAuthorization(r.Context(), user)
+	if err != nil {
+		status, message := authorizerErrorResponse(err)
+		logAndHTTPError(w, r, status, message, err, "cerbos check failed for authz context of process %s", processID)
+		return
+	}
+	if !allowed {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	workflowKey, cfg, ok := s.selectedWorkflowOrRedirectHome(w, r)
+	if !ok {
+		return
+	}
+	process, err := s.loadProcess(r.Context(), processID)
+	if err != nil || !s.processBelongsToWorkflow(process, workflowKey) {
+		http.NotFound(w, r)
+		return
+	}
+	substep, step, err := findSubstep(cfg.Workflow, substepID)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	actor := completionActor(user, workflowKey)
+	roleMatch := s.applyCompletionRole(&actor, r.URL.Query().Get("activeRole"), substepRoles(substep))
+	id := process.ID.Hex()
+	sequenceOK := isSequenceOK(cfg.Workflow, process, substep.SubstepID)
+	principal, resourceAttr := completeCheckInput(actor, id, workflowKey, substep, step.Order, step.OrganizationSlug, sequenceOK)
+	writeJSON(w, AuthzContext{
+		ProcessID:   id,
+		WorkflowKey: workflowKey,
+		SubstepID:   substep.SubstepID,
+		StepOrder:   step.Order,
+		StepOrgSlug: step.OrganizationSlug,
+		SequenceOK:  sequenceOK,
+		Actor: AuthzActor{
+			ID:          actor.ID,
+			OrgSlug:     actor.OrgSlug,
+			Role:        actor.Role,
+			RoleSlugs:   append([]string{}, actor.RoleSlugs...),
+			WorkflowKey: actor.WorkflowKey,
+		},
+		RoleMatch: roleMatch,
+		CerbosRequest: AuthzCheckRequest{
+			Principal: principal,
+			Resource:  AuthzResource{Kind: "substep", ID: substep.SubstepID, Attr: resourceAttr},
+			Action:    "complete",
+		},
+	})
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestHandleSubstepAuthzContext(t *testing.T) {
+	store := NewMemoryStore()
+	decisions := 0
+	server, processID, _ := newServerForCompleteTests(t, store, fakeAuthorizer{decide: func(Actor, string, string, WorkflowSub, int, string, bool) (bool, error) {
+		decisions++
+		return true, nil
+	}})
+	authzContext := func(substepID, query string) AuthzContext {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/instance/"+processID+"/substep/"+substepID+"/authz-context"+query, nil)
+		rec := httptest.NewRecorder()
+		server.handleSubstepAuthzContext(rec, req, processID, substepID)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d body = %s", rec.Code, rec.Body.String())
+		}
+		var got AuthzContext
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatalf("decode authz context: %v", err)
+		}
+		return got
+	}
+
+	got := authzContext("1.2", "")
+	if got.SubstepID != "1.2" || got.StepOrder != 1 || got.SequenceOK || !got.RoleMatch || got.Actor.Role != "dep1" {
+		t.Fatalf("authz context = %#v", got)
+	}
+	cfg, _ := server.runtimeConfig()
+	sub, step, _ := findSubstep(cfg.Workflow, "1.2")
+	principal, attr := completeCheckInput(Actor{ID: got.Actor.ID, OrgSlug: got.Actor.OrgSlug, Role: got.Actor.Role, RoleSlugs: got.Actor.RoleSlugs, WorkflowKey: got.Actor.WorkflowKey}, processID, got.WorkflowKey, sub, step.Order, step.OrganizationSlug, false)
+	want, _ := json.Marshal(AuthzCheckRequest{Principal: principal, Resource: AuthzResource{Kind: "substep", ID: "1.2", Attr: attr}, Action: "complete"})
+	if gotRequest, _ := json.Marshal(got.CerbosRequest); !reflect.DeepEqual(gotRequest, want) {
+		t.Fatalf("cerbos request = %s, want %s", gotRequest, want)
+	}
+	if got.CerbosRequest.Resource.Attr["processId"] != processID || got.CerbosRequest.Resource.Attr["sequenceOk"] != false {
+		t.Fatalf("resource attr = %#v", got.CerbosRequest.Resource.Attr)
+	}
+
+	if got := authzContext("1.1", "?activeRole=dep2"); got.RoleMatch || !got.SequenceOK {
+		t.Fatalf("foreign role context = %#v", got)
+	}
+	if decisions != 0 {
+		t.Fatalf("authz context asked Cerbos %d times", decisions)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/instance/"+processID+"/substep/9.9/authz-context", nil)
+	rec := httptest.NewRecorder()
+	server.handleSubstepAuthzContext(rec, req, processID, "9.9")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown substep status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandleSubstepAuthzContextRequiresAdmin(t *testing.T) {
+	t.Setenv("ADMIN_EMAIL", "admin@example.com")
+	t.Setenv("ADMIN_PASSWORD", "change-me")
+	store := NewMemoryStore()
+	server, processID, _ := newServerForCompleteTests(t, store, fakeAuthorizer{accessDecide: func(*AccountUser, string, string, map[string]interface{}, string) (bool, error) {
+		return false, nil
+	}})
+	server.enforceAuth = true
+	server.identity = &fakeIdentityStore{}
+	req := httptest.NewRequest(http.MethodGet, "/instance/"+processID+"/substep/1.1/authz-context", nil)
+	req.AddCookie(&http.Cookie{Name: "attesta_session", Value: platformAdminSessionValue()})
+	rec := httptest.NewRecorder()
+	server.handleSubstepAuthzContext(rec, req, processID, "1.1")
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d body = %s", rec.Code, http.StatusForbidden, rec.Body.String())
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// canonicalValue folds v into the plain maps, slices and float64 numbers
+// that encoding/json produces when decoding, so it marshals with sorted keys
+// and a single number format whatever container types it arrived in.
+// Payloads read back from Mongo decode nested documents as primitive.D and
+// arrays as primitive.A; folding them lets a re-loaded process hash exactly
+// like the payload that was notarized. Values already in decoded form come
+// back unchanged, so digests recorded before this existed still match.
+func canonicalValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		if value == nil {
+			return value
+		}
+		out := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			out[key] = canonicalValue(item)
+		}
+		return out
+	case primitive.M:
+		return canonicalValue(map[string]interface{}(value))
+	case primitive.D:
+		out := make(map[string]interface{}, len(value))
+		for _, elem := range value {
+			out[elem.Key] = canonicalValue(elem.Value)
+		}
+		return out
+	case []interface{}:
+		if value == nil {
+			return value
+		}
+		out := make([]interface{}, len(value))
+		for idx, item := range value {
+			out[idx] = canonicalValue(item)
+		}
+		return out
+	case primitive.A:
+		return canonicalValue([]interface{}(value))
+	case []map[string]interface{}:
+		out := make([]interface{}, len(value))
+		for idx, item := range value {
+			out[idx] = canonicalValue(item)
+		}
+		return out
+	case int:
+		return float64(value)
+	case int32:
+		return float64(value)
+	case int64:
+		// Beyond 2^53 a float64 would round; keep the exact integer.
+		if value > 1<<53 || value < -(1<<53) {
+			return value
+		}
+		return float64(value)
+	case float32:
+		return float64(value)
+	case primitive.DateTime:
+		return value.Time().UTC().Format(time.RFC3339Nano)
+	case time.Time:
+		return value.UTC().Format(time.RFC3339Nano)
+	default:
+		return v
+	}
+}
+
+// canonicalPayload is canonicalValue for payload maps embedded in larger
+// hashed structures.
+func canonicalPayload(payload map[string]interface{}) map[string]interface{} {
+	if payload == nil {
+		return nil
+	}
+	return canonicalValue(payload).(map[string]interface{})
+}
+
+func canonicalAmendments(amendments []NotarizedAmendment) []NotarizedAmendment {
+	if len(amendments) == 0 {
+		return amendments
+	}
+	out := make([]NotarizedAmendment, len(amendments))
+	for idx, amendment := range amendments {
+		amendment.Payload = canonicalPayload(amendment.Payload)
+		out[idx] = amendment
+	}
+	return out
+}
@@ -560,6 +560,135 @@ func TestHandleCompleteSubstepStoreFailures(t *testing.T) {
 	}
 }
 
+func TestHandleCompleteSubstepDeletesAttachmentsOnlyWhenProgressNotSaved(t *testing.T) {
+	body := "value=" + url.QueryEscape(`{"status":"ok","evidence":"data:text/plain;base64,aGVsbG8="}`)
+	complete := func(store *MemoryStore) int {
+		t.Helper()
+		server, processID, _ := newServerForCompleteTests(t, store, fakeAuthorizer{})
+		req := httptest.NewRequest(http.MethodPost, "/process/"+processID+"/substep/1.1/complete", strings.NewReader(body))
+		req.Header.Set("HX-Request", "true")
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		server.handleCompleteSubstep(rec, req, processID, "1.1")
+		return rec.Code
+	}
+
+	failedUpdate := NewMemoryStore()
+	failedUpdate.UpdateProgressErr = assertErr("update")
+	if code := complete(failedUpdate); code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", code, http.StatusInternalServerError)
+	}
+	if len(failedUpdate.attachments) != 0 {
+		t.Fatalf("expected orphaned attachment to be deleted, got %d", len(failedUpdate.attachments))
+	}
+
+	failedNotarization := NewMemoryStore()
+	failedNotarization.InsertNotarizeErr = assertErr("notarize")
+	if code := complete(failedNotarization); code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", code, http.StatusInternalServerError)
+	}
+	if len(failedNotarization.attachments) != 0 {
+		t.Fatalf("expected attachment of rolled back progress to be deleted, got %d", len(failedNotarization.attachments))
+	}
+
+	succeeded := NewMemoryStore()
+	if code := complete(succeeded); code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", code, http.StatusOK)
+	}
+	if len(succeeded.attachments) != 1 {
+		t.Fatalf("expected attachment to be kept after completion, got %d", len(succeeded.attachments))
+	}
+}
+
+func TestSubstepAssignedTo(t *testing.T) {
+	open := WorkflowSub{SubstepID: "1.1"}
+	if !substepAssignedTo(open, "appwrite:u-1") {
+		t.Fatal("expected substep without assignees to be open")
+	}
+	assigned := WorkflowSub{SubstepID: "1.1", AssignedUserIDs: []string{" u-1 ", "appwrite:u-2"}}
+	for actorID, want := range map[string]bool{"appwrite:u-1": true, "appwrite:u-2": true, "appwrite:u-3": false, "": false} {
+		if got := substepAssignedTo(assigned, actorID); got != want {
+			t.Fatalf("substepAssignedTo(%q) = %v, want %v", actorID, got, want)
+		}
+	}
+}
+
+func TestHandleCompleteSubstepRequiresAssignedUser(t *testing.T) {
+	store := NewMemoryStore()
+	server, processID, now := newServerForCompleteTests(t, store, fakeAuthorizer{})
+	cfg := testFormataRuntimeConfig()
+	cfg.Workflow.Steps[0].Substep[0].AssignedUserIDs = []string{"u-1"}
+	server.configProvider = func() (RuntimeConfig, error) { return cfg, nil }
+	server.enforceAuth = true
+	server.identity = testIdentityForSessions(now, map[string]AccountUser{
+		"session-assignee": {IdentityUserID: "u-1", RoleSlugs: []string{"dep1"}},
+		"session-other":    {IdentityUserID: "u-2", RoleSlugs: []string{"dep1"}},
+	})
+	complete := func(session string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/process/"+processID+"/substep/1.1/complete", strings.NewReader("value=%7B%22status%22%3A%22ok%22%7D"))
+		req.Header.Set("HX-Request", "true")
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(&http.Cookie{Name: "attesta_session", Value: session})
+		rec := httptest.NewRecorder()
+		server.handleCompleteSubstep(rec, req, processID, "1.1")
+		return rec
+	}
+
+	if rec := complete("session-other"); rec.Code != http.StatusForbidden {
+		t.Fatalf("other user status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+	if rec := complete("session-assignee"); rec.Code != http.StatusOK {
+		t.Fatalf("assignee status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	id, _ := primitive.ObjectIDFromHex(processID)
+	snapshot, _ := store.SnapshotProcess(id)
+	if progress := snapshot.Progress["1_1"]; progress.State != "done" || progress.DoneBy == nil || progress.DoneBy.ID != "appwrite:u-1" {
+		t.Fatalf("expected 1.1 completed by assignee, got %#v", progress)
+	}
+}
+
+func TestBuildSubstepViewsDisablesSubstepAssignedToOthers(t *testing.T) {
+	cfg := testFormataRuntimeConfig()
+	cfg.Workflow.Steps[0].Substep[0].AssignedUserIDs = []string{"u-1"}
+	process := &Process{ID: primitive.NewObjectID(), Progress: map[string]ProcessStep{}}
+	other := buildSubstepViews(cfg.Workflow, process, "workflow", Actor{ID: "appwrite:u-2", RoleSlugs: []string{"dep1"}}, false, nil, nil)
+	if !other[0].Disabled || other[0].Reason != "Assigned to u-1" {
+		t.Fatalf("expected other user to see disabled assignment, got disabled=%v reason=%q", other[0].Disabled, other[0].Reason)
+	}
+	assignee := buildSubstepViews(cfg.Workflow, process, "workflow", Actor{ID: "appwrite:u-1", RoleSlugs: []string{"dep1"}}, false, nil, nil)
+	if assignee[0].Disabled {
+		t.Fatalf("expected assignee to be able to act, got reason %q", assignee[0].Reason)
+	}
+}
+
+func TestApplyAssigneeNamesToSubstepViewsResolvesEmails(t *testing.T) {
+	cfg := testFormataRuntimeConfig()
+	cfg.Workflow.Steps[0].Substep[0].AssignedUserIDs = []string{"u-1", "appwrite:u-3", "gone"}
+	process := &Process{ID: primitive.NewObjectID(), Progress: map[string]ProcessStep{}}
+	server := &Server{
+		identity: &fakeIdentityStore{
+			getUserByIDFunc: func(ctx context.Context, userID string) (IdentityUser, error) {
+				switch userID {
+				case "u-1":
+					return IdentityUser{ID: "u-1", Email: "one@example.com"}, nil
+				case "u-3":
+					return IdentityUser{ID: "u-3", Email: "three@example.com"}, nil
+				}
+				return IdentityUser{}, ErrIdentityNotFound
+			},
+		},
+	}
+	views := buildSubstepViews(cfg.Workflow, process, "workflow", Actor{ID: "appwrite:u-2", RoleSlugs: []string{"dep1"}}, false, nil, nil)
+	views = server.applyAssigneeNamesToSubstepViews(context.Background(), views)
+	if want := "Assigned to one@example.com, three@example.com, gone"; views[0].Reason != want {
+		t.Fatalf("reason = %q, want %q", views[0].Reason, want)
+	}
+	if views[1].Reason == views[0].Reason {
+		t.Fatalf("unassigned substep got assignment reason %q", views[1].Reason)
+	}
+}
+
 func TestHandleCompleteSubstepLogsPreciseStoreError(t *testing.T) {
 	store := NewMemoryStore()
 	store.UpdateProgressErr = assertErr("write failed: duplicate key on progress update")
@@ -768,8 +897,8 @@ func TestHandleCompleteSubstepFinalCompletionDPPIdempotent(t *testing.T) {
 	secondReq.AddCookie(&http.Cookie{Name: "demo_user", Value: "u3|dep3"})
 	secondRec := httptest.NewRecorder()
 	server.handleCompleteSubstep(secondRec, secondReq, process.ID.Hex(), "3.2")
-	if secondRec.Code != http.StatusOK {
-		t.Fatalf("second completion status = %d, want %d", secondRec.Code, http.StatusOK)
+	if secondRec.Code != http.StatusConflict {
+		t.Fatalf("second completion status = %d, want %d (done is terminal)", secondRec.Code, http.StatusConflict)
 	}
 
 	after, ok := store.SnapshotProcess(process.ID)
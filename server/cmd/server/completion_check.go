@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// CompletionExplanation says whether an actor may complete a substep right
+// now and which check decides it. Status and Reason are what the completion
+// POST would answer; every check is reported even after one fails, except
+// Cerbos, which needs a usable role.
+type CompletionExplanation struct {
+	ProcessID    string   `json:"process_id"`
+	SubstepID    string   `json:"substep_id"`
+	Allowed      bool     `json:"allowed"`
+	Status       int      `json:"status"`
+	Reason       string   `json:"reason,omitempty"`
+	ProcessOpen  bool     `json:"process_open"`
+	ActiveRole   string   `json:"active_role,omitempty"`
+	ActorRoles   []string `json:"actor_roles"`
+	AllowedRoles []string `json:"allowed_roles"`
+	RoleMatch    bool     `json:"role_match"`
+	Assigned     bool     `json:"assigned"`
+	SequenceOK   bool     `json:"sequence_ok"`
+	AlreadyDone  bool     `json:"already_done"`
+	// Cerbos is "allow", "deny", "error" or "not_checked".
+	Cerbos      string `json:"cerbos"`
+	CerbosError string `json:"cerbos_error,omitempty"`
+
+	cerbosErr error
+}
+
+// completionActor is the actor user completes substeps of workflowKey as,
+// before an active role is picked.
+func completionActor(user *AccountUser, workflowKey string) Actor {
+	actor := Actor{
+		ID:          accountActorID(user),
+		OrgSlug:     user.OrgSlug,
+		RoleSlugs:   append([]string(nil), user.RoleSlugs...),
+		WorkflowKey: workflowKey,
+	}
+	if len(user.RoleSlugs) > 0 {
+		actor.Role = user.RoleSlugs[0]
+	}
+	return actor
+}
+
+// applyCompletionRole picks the role actor completes a substep allowing
+// allowedRoles as: activeRole, else the actor's only role, else (without
+// enforced auth) the first allowed role. It sets actor.Role and reports
+// whether the actor holds the role and the substep allows it.
+func (s *Server) applyCompletionRole(actor *Actor, activeRole string, allowedRoles []string) bool {
+	activeRole = strings.TrimSpace(activeRole)
+	if activeRole == "" && len(actor.RoleSlugs) == 1 {
+		activeRole = actor.RoleSlugs[0]
+	}
+	if !s.enforceAuth && activeRole == "" && len(allowedRoles) > 0 {
+		activeRole = allowedRoles[0]
+		actor.RoleSlugs = append([]string(nil), allowedRoles...)
+	}
+	if activeRole == "" || !containsRole(actor.RoleSlugs, activeRole) || !containsRole(allowedRoles, activeRole) {
+		return false
+	}
+	actor.Role = activeRole
+	return true
+}
+
+// checkCompletion runs the role, assignment, sequence and Cerbos checks of
+// the completion POST for actor on substep, in the POST's order. activeRole
+// is the role the actor asked to complete as; actor.Role is set to the role
+// that was used.
+func (s *Server) checkCompletion(ctx context.Context, actor *Actor, activeRole, workflowKey string, cfg RuntimeConfig, process *Process, substep WorkflowSub, step WorkflowStep) CompletionExplanation {
+	substepID := substep.SubstepID
+	allowedRoles := substepRoles(substep)
+	check := CompletionExplanation{
+		ProcessID:    process.ID.Hex(),
+		SubstepID:    substepID,
+		ProcessOpen:  processAcceptsCompletions(process),
+		AllowedRoles: allowedRoles,
+		SequenceOK:   isSequenceOK(cfg.Workflow, process, substepID),
+		AlreadyDone:  process.Progress[substepID].State == "done",
+		Cerbos:       "not_checked",
+	}
+	if check.AllowedRoles == nil {
+		check.AllowedRoles = []string{}
+	}
+
+	check.RoleMatch = s.applyCompletionRole(actor, activeRole, allowedRoles)
+	check.ActorRoles = append([]string{}, actor.RoleSlugs...)
+	if check.RoleMatch {
+		check.ActiveRole = actor.Role
+	}
+	check.Assigned = substepAssignedTo(substep, actor.ID)
+
+	if check.RoleMatch && s.authorizer != nil {
+		allowed, err := s.authorizer.CanComplete(ctx, *actor, check.ProcessID, workflowKey, substep, step.Order, step.OrganizationSlug, check.SequenceOK)
+		switch {
+		case err != nil:
+			check.Cerbos = "error"
+			check.cerbosErr = err
+			_, check.CerbosError = authorizerErrorResponse(err)
+		case allowed:
+			check.Cerbos = "allow"
+		default:
+			check.Cerbos = "deny"
+		}
+	}
+
+	switch {
+	case !check.ProcessOpen:
+		check.Status, check.Reason = http.StatusConflict, "Stream is already ended."
+	case !check.RoleMatch:
+		check.Status, check.Reason = http.StatusForbidden, "Not authorized for this action."
+	case !check.Assigned:
+		check.Status, check.Reason = http.StatusForbidden, "This step is assigned to someone else."
+	case s.authorizer == nil:
+		check.Status, check.Reason = http.StatusBadGateway, "Cerbos check failed."
+	case check.cerbosErr != nil:
+		check.Status, check.Reason = authorizerErrorResponse(check.cerbosErr)
+	case !check.SequenceOK:
+		check.Status, check.Reason = http.StatusConflict, "Step is locked: complete previous steps first."
+	case check.Cerbos != "allow":
+		check.Status, check.Reason = http.StatusForbidden, "Not authorized for this action."
+	default:
+		check.Status, check.Allowed = http.StatusOK, true
+	}
+	return check
+}
+
+// handleCanCompleteSubstep serves GET
+// /my/streams/:key/instance/:id/substep/:substepId/can-complete[?activeRole=].
+func (s *Server) handleCanCompleteSubstep(w http.ResponseWriter, r *http.Request, processID, substepID string) {
+	user, _, ok := s.requireAuthenticatedPost(w, r)
+	if !ok {
+		return
+	}
+	workflowKey, cfg, ok := s.selectedWorkflowOrRedirectHome(w, r)
+	if !ok {
+		return
+	}
+	process, err := s.loadProcess(r.Context(), processID)
+	if err != nil || !s.processBelongsToWorkflow(process, workflowKey) {
+		http.NotFound(w, r)
+		return
+	}
+	substep, step, err := findSubstep(cfg.Workflow, substepID)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	actor := completionActor(user, workflowKey)
+	check := s.checkCompletion(r.Context(), &actor, r.URL.Query().Get("activeRole"), workflowKey, cfg, process, substep, step)
+	if check.cerbosErr != nil {
+		logRequestError(r, check.cerbosErr, "cerbos check failed for process %s substep %s explanation", processID, substepID)
+	}
+	writeJSON(w, check)
+}
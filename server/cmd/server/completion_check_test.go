@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandleCanCompleteSubstep(t *testing.T) {
+	store := NewMemoryStore()
+	server, processID, _ := newServerForCompleteTests(t, store, fakeAuthorizer{})
+	explain := func(substepID, query string) CompletionExplanation {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/instance/"+processID+"/substep/"+substepID+"/can-complete"+query, nil)
+		rec := httptest.NewRecorder()
+		server.handleCanCompleteSubstep(rec, req, processID, substepID)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d body = %s", rec.Code, rec.Body.String())
+		}
+		var check CompletionExplanation
+		if err := json.Unmarshal(rec.Body.Bytes(), &check); err != nil {
+			t.Fatalf("decode explanation: %v", err)
+		}
+		return check
+	}
+
+	check := explain("1.1", "")
+	if !check.Allowed || check.Status != http.StatusOK || check.ActiveRole != "dep1" || !check.RoleMatch || !check.SequenceOK || check.Cerbos != "allow" {
+		t.Fatalf("available substep = %#v", check)
+	}
+
+	check = explain("1.2", "")
+	if check.Allowed || check.SequenceOK || check.Status != http.StatusConflict || check.Reason != "Step is locked: complete previous steps first." {
+		t.Fatalf("locked substep = %#v", check)
+	}
+
+	check = explain("1.1", "?activeRole=dep2")
+	if check.Allowed || check.RoleMatch || check.Cerbos != "not_checked" || check.Status != http.StatusForbidden {
+		t.Fatalf("foreign role = %#v", check)
+	}
+
+	server.authorizer = fakeAuthorizer{decide: func(Actor, string, string, WorkflowSub, int, string, bool) (bool, error) { return false, nil }}
+	check = explain("1.1", "")
+	if check.Allowed || check.Cerbos != "deny" || check.Status != http.StatusForbidden || check.Reason != "Not authorized for this action." {
+		t.Fatalf("cerbos deny = %#v", check)
+	}
+
+	server.authorizer = fakeAuthorizer{decide: func(Actor, string, string, WorkflowSub, int, string, bool) (bool, error) {
+		return false, errors.New("cerbos down")
+	}}
+	check = explain("1.1", "")
+	if check.Allowed || check.Cerbos != "error" || check.Status != http.StatusBadGateway || check.CerbosError != "Cerbos check failed." {
+		t.Fatalf("cerbos error = %#v", check)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/instance/"+processID+"/substep/9.9/can-complete", nil)
+	rec := httptest.NewRecorder()
+	server.handleCanCompleteSubstep(rec, req, processID, "9.9")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown substep status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
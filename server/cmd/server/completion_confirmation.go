@@ -0,0 +1,20 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+)
+
+const completionConfirmationMessage = "Confirm this step before completing it: it cannot be undone."
+
+// completionConfirmed reports whether a completion request carries the
+// explicit confirmation that substeps with requireConfirmation need:
+// `confirm` set to true (the form checkbox), or `confirmation` typed as
+// CONFIRM.
+func completionConfirmed(r *http.Request) bool {
+	switch strings.ToLower(strings.TrimSpace(r.FormValue("confirm"))) {
+	case "true", "1", "on", "yes":
+		return true
+	}
+	return strings.EqualFold(strings.TrimSpace(r.FormValue("confirmation")), "confirm")
+}
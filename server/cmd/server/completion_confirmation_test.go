@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestHandleCompleteSubstepRequiresConfirmation(t *testing.T) {
+	store := NewMemoryStore()
+	server, processID, _ := newServerForCompleteTests(t, store, fakeAuthorizer{})
+	cfg := testFormataRuntimeConfig()
+	cfg.Workflow.Steps[0].Substep[0].RequireConfirmation = true
+	server.configProvider = func() (RuntimeConfig, error) { return cfg, nil }
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/process/"+processID+"/substep/1.1/complete", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rr := httptest.NewRecorder()
+		server.handleCompleteSubstep(rr, req, processID, "1.1")
+		return rr
+	}
+
+	for _, body := range []string{"value=%7B%7D", "value=%7B%7D&confirm=false", "value=%7B%7D&confirmation=yes"} {
+		rr := post(body)
+		if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), completionConfirmationMessage) {
+			t.Fatalf("%s: status = %d body = %q", body, rr.Code, rr.Body.String())
+		}
+	}
+	id, _ := primitive.ObjectIDFromHex(processID)
+	process, _ := store.SnapshotProcess(id)
+	if process.Progress["1_1"].State == "done" {
+		t.Fatal("substep must stay pending without confirmation")
+	}
+
+	if rr := post("value=%7B%7D&confirmation=Confirm"); rr.Code != http.StatusOK {
+		t.Fatalf("confirmed status = %d, want %d (%s)", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	process, _ = store.SnapshotProcess(id)
+	if process.Progress["1_1"].State != "done" {
+		t.Fatalf("expected confirmed completion, got %#v", process.Progress["1_1"])
+	}
+}
+
+func TestCompletionConfirmedAcceptsCheckboxValues(t *testing.T) {
+	for value, want := range map[string]bool{"true": true, "on": true, "1": true, "": false, "no": false} {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("confirm="+value))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if got := completionConfirmed(req); got != want {
+			t.Fatalf("completionConfirmed(confirm=%q) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestSubstepBodyTemplateRendersConfirmCheckbox(t *testing.T) {
+	tmpl := parseTestTemplates(t)
+	for _, inputType := range []string{"formata", "acknowledge", "signature"} {
+		action := withSubstepBodyMode(SubstepBodyView{
+			WorkflowKey:         "workflow",
+			ProcessID:           "process-1",
+			SubstepID:           "1.1",
+			InputType:           inputType,
+			Status:              "available",
+			FormSchema:          `{"type":"object"}`,
+			RequireConfirmation: true,
+		})
+		var out bytes.Buffer
+		if err := tmpl.ExecuteTemplate(&out, "substep_body", action); err != nil {
+			t.Fatalf("render %s substep_body template: %v", inputType, err)
+		}
+		if !strings.Contains(out.String(), `name="confirm"`) {
+			t.Fatalf("expected confirm checkbox for %s: %s", inputType, out.String())
+		}
+	}
+}
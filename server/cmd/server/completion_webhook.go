@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	completionWebhookEvent      = "process.completed"
+	completionWebhookTimeout    = 10 * time.Second
+	completionWebhookAttempts   = 3
+	completionWebhookRetryDelay = 2 * time.Second
+)
+
+// ProcessCompletedWebhook is the body POSTed to workflow.completionWebhook.
+// Export is notarized.json, signed when NOTARIZED_SIGNING_KEY is set.
+type ProcessCompletedWebhook struct {
+	Event       string                 `json:"event"`
+	WorkflowKey string                 `json:"workflow_key"`
+	ProcessID   string                 `json:"process_id"`
+	CompletedAt string                 `json:"completed_at"`
+	DPPURL      string                 `json:"dpp_url,omitempty"`
+	Export      NotarizedProcessExport `json:"export"`
+}
+
+// notifyProcessCompleted fires the workflow's completion webhook for a
+// process that finished all its substeps. Completion is detected both when
+// the last substep is submitted and whenever a closed process is viewed, so
+// the process is claimed with Store.MarkProcessCompletionNotified first: only
+// the caller that sets completedNotifiedAt sends. Delivery runs in the
+// background and is retried with backoff; when every attempt fails the claim
+// is released so the next completion check sends again. Receivers may see a
+// duplicate if a 2xx response is lost, never a missing event.
+func (p *ProcessService) notifyProcessCompleted(ctx context.Context, cfg RuntimeConfig, workflowKey string, process *Process, now time.Time) {
+	target := strings.TrimSpace(cfg.Workflow.CompletionWebhook)
+	if target == "" || process == nil || process.CompletedNotifiedAt != nil {
+		return
+	}
+	if process.Termination != nil || !isProcessComplete(cfg.Workflow, process) {
+		return
+	}
+	claimed, err := p.store.MarkProcessCompletionNotified(ctx, process.ID, now)
+	if err != nil {
+		log.Printf("failed to claim completion webhook for process %s: %v", process.ID.Hex(), err)
+		return
+	}
+	if !claimed {
+		return
+	}
+	process.CompletedNotifiedAt = &now
+
+	export := buildNotarizedExport(cfg.Workflow, process)
+	if p.signer != nil {
+		signature, err := p.signer.sign(export)
+		if err != nil {
+			log.Printf("failed to sign completion webhook export for process %s: %v", process.ID.Hex(), err)
+		} else {
+			export.Signature = &signature
+		}
+	}
+	// The process completed when its last substep did, not when this load
+	// noticed it.
+	_, completedAt, _ := processProgressStats(cfg.Workflow, process)
+	if completedAt.IsZero() {
+		completedAt = now
+	}
+	body := ProcessCompletedWebhook{
+		Event:       completionWebhookEvent,
+		WorkflowKey: workflowKey,
+		ProcessID:   process.ID.Hex(),
+		CompletedAt: completedAt.UTC().Format(time.RFC3339),
+		Export:      export,
+	}
+	if process.DPP != nil {
+		body.DPPURL = digitalLinkURL(process.DPP.GTIN, process.DPP.Lot, process.DPP.Serial)
+	}
+	client := p.webhookClient
+	if client == nil {
+		client = &http.Client{Timeout: completionWebhookTimeout}
+	}
+	delay := p.webhookRetryDelay
+	if delay <= 0 {
+		delay = completionWebhookRetryDelay
+	}
+	processID := process.ID
+	go func() {
+		var err error
+		for attempt := 1; attempt <= completionWebhookAttempts; attempt++ {
+			if err = postCompletionWebhook(client, target, body); err == nil {
+				return
+			}
+			log.Printf("completion webhook for process %s failed (attempt %d/%d): %v", body.ProcessID, attempt, completionWebhookAttempts, err)
+			if attempt < completionWebhookAttempts {
+				time.Sleep(delay)
+				delay *= 2
+			}
+		}
+		if err := p.store.ReleaseProcessCompletionNotified(context.Background(), processID, now); err != nil {
+			log.Printf("failed to release completion webhook claim for process %s: %v", body.ProcessID, err)
+		}
+	}()
+}
+
+func postCompletionWebhook(client *http.Client, target string, body ProcessCompletedWebhook) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), completionWebhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Attesta-Event", completionWebhookEvent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
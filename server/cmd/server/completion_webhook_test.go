@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCompletionWebhookFiresOncePerProcess(t *testing.T) {
+	deliveries := make(chan *http.Request, 4)
+	bodies := make(chan []byte, 4)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		deliveries <- r
+		bodies <- body
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	fixedNow := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+	cfg := RuntimeConfig{
+		Workflow: WorkflowDef{
+			CompletionWebhook: receiver.URL,
+			Steps: []WorkflowStep{{
+				StepID: "1",
+				Substep: []WorkflowSub{
+					{SubstepID: "1.1", Order: 1, Role: "dep1", InputKey: "value", InputType: "formata"},
+				},
+			}},
+		},
+		DPP: DPPConfig{Enabled: true, GTIN: "09506000134352", LotDefault: "LOT-1", SerialStrategy: "process_id_hex"},
+	}
+	store := NewMemoryStore()
+	svc := &ProcessService{store: store, now: func() time.Time { return fixedNow }, webhookClient: receiver.Client()}
+	processID := primitive.NewObjectID()
+	store.SeedProcess(Process{
+		ID:       processID,
+		Status:   "active",
+		Progress: map[string]ProcessStep{"1_1": {State: "done", DoneAt: ptrTime(fixedNow.Add(-2 * time.Hour)), Data: map[string]interface{}{"value": "ok"}}},
+	})
+
+	for i := 0; i < 3; i++ {
+		process, err := store.LoadProcessByID(context.Background(), processID)
+		if err != nil {
+			t.Fatalf("LoadProcessByID: %v", err)
+		}
+		process.Progress = normalizeProgressKeys(process.Progress)
+		svc.EnsureCompletionArtifacts(context.Background(), cfg, "workflow", process)
+	}
+
+	var req *http.Request
+	select {
+	case req = <-deliveries:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a webhook delivery")
+	}
+	if req.Method != http.MethodPost || req.Header.Get("X-Attesta-Event") != "process.completed" {
+		t.Fatalf("unexpected delivery: %s %q", req.Method, req.Header.Get("X-Attesta-Event"))
+	}
+	var payload ProcessCompletedWebhook
+	if err := json.Unmarshal(<-bodies, &payload); err != nil {
+		t.Fatalf("decode webhook body: %v", err)
+	}
+	if payload.Event != "process.completed" || payload.WorkflowKey != "workflow" || payload.ProcessID != processID.Hex() {
+		t.Fatalf("unexpected payload: %#v", payload)
+	}
+	if want := fixedNow.Add(-2 * time.Hour).Format(time.RFC3339); payload.CompletedAt != want {
+		t.Fatalf("completed_at = %q, want the last substep's doneAt %q", payload.CompletedAt, want)
+	}
+	if payload.Export.ProcessID != processID.Hex() {
+		t.Fatalf("expected export of the process, got %q", payload.Export.ProcessID)
+	}
+	if want := digitalLinkURL("09506000134352", "LOT-1", processID.Hex()); payload.DPPURL != want {
+		t.Fatalf("expected dpp url %q, got %q", want, payload.DPPURL)
+	}
+	select {
+	case <-deliveries:
+		t.Fatal("expected exactly one webhook delivery")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	stored, err := store.LoadProcessByID(context.Background(), processID)
+	if err != nil {
+		t.Fatalf("LoadProcessByID: %v", err)
+	}
+	if stored.CompletedNotifiedAt == nil || !stored.CompletedNotifiedAt.Equal(fixedNow) {
+		t.Fatalf("expected completedNotifiedAt %s, got %v", fixedNow, stored.CompletedNotifiedAt)
+	}
+}
+
+func TestCompletionWebhookRetriesAndReleasesClaimOnFailure(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+	attempts := make(chan int, 8)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			attempts <- http.StatusBadGateway
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		attempts <- http.StatusNoContent
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	now := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+	cfg := RuntimeConfig{Workflow: WorkflowDef{
+		CompletionWebhook: receiver.URL,
+		Steps: []WorkflowStep{{
+			StepID:  "1",
+			Substep: []WorkflowSub{{SubstepID: "1.1", Order: 1, Role: "dep1", InputKey: "value", InputType: "formata"}},
+		}},
+	}}
+	store := NewMemoryStore()
+	svc := &ProcessService{store: store, now: func() time.Time { return now }, webhookClient: receiver.Client(), webhookRetryDelay: time.Millisecond}
+	processID := primitive.NewObjectID()
+	store.SeedProcess(Process{ID: processID, Status: "active", Progress: map[string]ProcessStep{"1_1": {State: "done"}}})
+	notify := func() {
+		process, err := store.LoadProcessByID(context.Background(), processID)
+		if err != nil {
+			t.Fatalf("LoadProcessByID: %v", err)
+		}
+		process.Progress = normalizeProgressKeys(process.Progress)
+		svc.notifyProcessCompleted(context.Background(), cfg, "workflow", process, now)
+	}
+	waitAttempt := func(want int) {
+		t.Helper()
+		select {
+		case got := <-attempts:
+			if got != want {
+				t.Fatalf("delivery status = %d, want %d", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected a webhook delivery attempt")
+		}
+	}
+
+	notify()
+	for i := 0; i < completionWebhookAttempts; i++ {
+		waitAttempt(http.StatusBadGateway)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		stored, _ := store.LoadProcessByID(context.Background(), processID)
+		if stored.CompletedNotifiedAt == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the claim to be released after failed deliveries")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	failing.Store(false)
+	notify()
+	waitAttempt(http.StatusNoContent)
+	select {
+	case got := <-attempts:
+		t.Fatalf("unexpected extra delivery with status %d", got)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if stored, _ := store.LoadProcessByID(context.Background(), processID); stored.CompletedNotifiedAt == nil {
+		t.Fatal("expected completedNotifiedAt after a successful delivery")
+	}
+}
+
+func TestCompletionWebhookSkipsWorkflowsWithoutURL(t *testing.T) {
+	store := NewMemoryStore()
+	svc := &ProcessService{store: store}
+	processID := primitive.NewObjectID()
+	store.SeedProcess(Process{ID: processID, Status: "active", Progress: map[string]ProcessStep{"1_1": {State: "done"}}})
+	cfg := RuntimeConfig{Workflow: WorkflowDef{Steps: []WorkflowStep{{
+		StepID:  "1",
+		Substep: []WorkflowSub{{SubstepID: "1.1", Order: 1}},
+	}}}}
+	process := &Process{ID: processID, Progress: map[string]ProcessStep{"1.1": {State: "done"}}}
+
+	svc.notifyProcessCompleted(context.Background(), cfg, "workflow", process, time.Now())
+
+	stored, _ := store.LoadProcessByID(context.Background(), processID)
+	if stored.CompletedNotifiedAt != nil {
+		t.Fatalf("expected no completion marker without a webhook, got %v", stored.CompletedNotifiedAt)
+	}
+}
+
+func TestMemoryStoreMarkProcessCompletionNotifiedClaimsOnce(t *testing.T) {
+	store := NewMemoryStore()
+	processID := primitive.NewObjectID()
+	store.SeedProcess(Process{ID: processID})
+	now := time.Now().UTC()
+
+	claimed, err := store.MarkProcessCompletionNotified(context.Background(), processID, now)
+	if err != nil || !claimed {
+		t.Fatalf("expected first claim to win, got %v %v", claimed, err)
+	}
+	claimed, err = store.MarkProcessCompletionNotified(context.Background(), processID, now.Add(time.Minute))
+	if err != nil || claimed {
+		t.Fatalf("expected second claim to lose, got %v %v", claimed, err)
+	}
+	if _, err := store.MarkProcessCompletionNotified(context.Background(), primitive.NewObjectID(), now); err == nil {
+		t.Fatal("expected error for unknown process")
+	}
+}
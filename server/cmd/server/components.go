@@ -1,6 +1,7 @@
 package main
 
 import (
+	"html/template"
 	"strings"
 	"time"
 )
@@ -10,6 +11,7 @@ type StreamCardView struct {
 	Key               string
 	Name              string
 	Description       string
+	DescriptionHTML   template.HTML
 	Counts            WorkflowProcessCounts
 	HasUserTurn       bool
 	CanClone          bool
@@ -30,6 +32,7 @@ type WorkflowProcessCounts struct {
 // StreamInstanceCard is the view model for templates/components/stream_instance_card.html.
 type StreamInstanceCard struct {
 	ID              string
+	Code            string
 	Name            string
 	Status          string
 	StatusLabel     string
@@ -37,6 +40,8 @@ type StreamInstanceCard struct {
 	CreatedAt       string
 	CreatedAtISO    string
 	CreatedAtTime   time.Time
+	UpdatedAtTime   time.Time
+	CreatedBy       string
 	DoneSubsteps    int
 	TotalSubsteps   int
 	Percent         int
@@ -62,6 +67,8 @@ type SubstepRoleOption struct {
 type SubstepKV struct {
 	Key   string
 	Value string
+	// URL makes Value a link; set for inputType=url answers.
+	URL string
 }
 
 // SubstepAttachmentView is a file attachment on a substep body.
@@ -119,6 +126,8 @@ type SubstepBodyView struct {
 	DoneAtISO      string
 	DoneBy         string
 	DoneRole       string
+	AmendedAt      string
+	AmendedAtISO   string
 	Values         []SubstepKV
 	Attachments    []SubstepAttachmentView
 	Disabled       bool
@@ -131,6 +140,32 @@ type SubstepBodyView struct {
 	OverrideReason string
 	HasOverride    bool
 	Digest         string
+	// ReworkReason and friends describe the latest rejection of a substep
+	// that is waiting to be redone.
+	ReworkReason string
+	ReworkAt     string
+	ReworkAtISO  string
+	ReworkBy     string
+	// RejectURL is set on completed substeps the viewer may send back;
+	// RejectRoles are the roles they can do it with.
+	RejectURL   string
+	RejectRoles []SubstepRoleOption
+	// HasDraft marks a form pre-filled from the viewer's saved draft.
+	HasDraft bool
+	// Help, Placeholder and FieldHelp come from the substep config and are
+	// shown with the form only.
+	Help        string
+	Placeholder string
+	FieldHelp   []SubstepFieldHelp
+	// RequireConfirmation adds the confirm checkbox the server insists on.
+	RequireConfirmation bool
+	// Options are the checkboxes of a multiselect substep.
+	Options []SubstepOption
+	// AllowedHosts lists the hosts a url substep accepts links from.
+	AllowedHosts []string
+	// Assignees are the assignedUserIds named in an "Assigned to" Reason,
+	// resolved to emails by applyAssigneeNamesToSubstepViews.
+	Assignees []string
 }
 
 func resolveSubstepBodyMode(v SubstepBodyView) SubstepBodyMode {
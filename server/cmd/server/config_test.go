@@ -461,6 +461,63 @@ roles:
 	}
 }
 
+func TestParseRuntimeConfigDataSubstepDependencies(t *testing.T) {
+	config := func(dependsOn11, dependsOn12 string) []byte {
+		return []byte(`
+workflow:
+  name: "Workflow"
+  steps:
+    - id: "1"
+      title: "Step 1"
+      order: 1
+      substeps:
+        - id: "1.1"
+          title: "First"
+          order: 1
+          roles: ["dep1"]
+          inputKey: "value"
+          inputType: "formata"
+          schema: {type: object}
+          dependsOn: ` + dependsOn11 + `
+        - id: "1.2"
+          title: "Second"
+          order: 2
+          roles: ["dep1"]
+          inputKey: "value"
+          inputType: "formata"
+          schema: {type: object}
+          dependsOn: ` + dependsOn12 + `
+`)
+	}
+
+	cfg, err := parseRuntimeConfigData("deps.yaml", config("[]", `[" 1.1 ", "1.1"]`))
+	if err != nil {
+		t.Fatalf("parseRuntimeConfigData(valid): %v", err)
+	}
+	if got := cfg.Workflow.Steps[0].Substep[1].DependsOn; len(got) != 1 || got[0] != "1.1" {
+		t.Fatalf("dependsOn = %#v, want [1.1]", got)
+	}
+
+	for name, tc := range map[string]struct {
+		dependsOn11 string
+		dependsOn12 string
+		want        string
+	}{
+		"unknown": {dependsOn11: "[]", dependsOn12: `["9.9"]`, want: `unknown substep "9.9"`},
+		"self":    {dependsOn11: `["1.1"]`, dependsOn12: "[]", want: "cannot depend on itself"},
+		"cycle":   {dependsOn11: `["1.2"]`, dependsOn12: `["1.1"]`, want: "dependency cycle 1.1 -> 1.2 -> 1.1"},
+		// 1.2 has no dependsOn, so it implicitly waits for 1.1.
+		"implicit order cycle": {dependsOn11: `["1.2"]`, dependsOn12: "[]", want: "dependency cycle 1.1 -> 1.2 -> 1.1"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseRuntimeConfigData("deps.yaml", config(tc.dependsOn11, tc.dependsOn12))
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
 func TestWorkflowCatalogRejectsInvalidFile(t *testing.T) {
 	tempDir := t.TempDir()
 	writeWorkflowConfig(t, filepath.Join(tempDir, "workflow.yaml"), "Main workflow", "string")
@@ -815,6 +872,34 @@ func TestWorkflowCatalogRejectsEnabledDPPWithInvalidGTIN(t *testing.T) {
 	}
 }
 
+func TestWorkflowCatalogRejectsEnabledDPPWithInvalidCheckDigit(t *testing.T) {
+	tempDir := t.TempDir()
+	writeWorkflowConfigWithDPP(t, filepath.Join(tempDir, "workflow.yaml"), "  enabled: true\n  gtin: \"09506000134353\"\n")
+
+	server := &Server{configDir: tempDir}
+	_, err := server.workflowCatalog()
+	if err == nil {
+		t.Fatal("expected dpp.gtin check digit error")
+	}
+	if !strings.Contains(err.Error(), "dpp.gtin has an invalid check digit") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestWorkflowCatalogComputesDPPCheckDigit(t *testing.T) {
+	tempDir := t.TempDir()
+	writeWorkflowConfigWithDPP(t, filepath.Join(tempDir, "workflow.yaml"), "  enabled: true\n  gtin: \"1061414100041\"\n  gtinComputeCheckDigit: true\n")
+
+	server := &Server{configDir: tempDir}
+	catalog, err := server.workflowCatalog()
+	if err != nil {
+		t.Fatalf("workflowCatalog(): %v", err)
+	}
+	if got := catalog["workflow"].DPP.GTIN; got != "10614141000415" {
+		t.Fatalf("dpp.gtin = %q, want %q", got, "10614141000415")
+	}
+}
+
 func TestWorkflowCatalogNormalizesEnabledDPPDefaults(t *testing.T) {
 	tempDir := t.TempDir()
 	writeWorkflowConfigWithDPP(t, filepath.Join(tempDir, "workflow.yaml"), "  enabled: true\n  gtin: \"9506000134352\"\n")
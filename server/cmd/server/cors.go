@@ -0,0 +1,136 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// corsPolicy describes which cross-origin callers may use the JSON API. The
+// zero value (no allowed origins) disables CORS entirely so deployments stay
+// same-origin unless CORS_ALLOWED_ORIGINS is set.
+type corsPolicy struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+	IncludeHTML      bool
+	MaxAgeSeconds    int
+}
+
+func corsPolicyFromEnv() (corsPolicy, error) {
+	policy := corsPolicy{
+		AllowedOrigins:   splitCSVEnv("CORS_ALLOWED_ORIGINS"),
+		AllowedMethods:   splitCSVEnv("CORS_ALLOWED_METHODS"),
+		AllowedHeaders:   splitCSVEnv("CORS_ALLOWED_HEADERS"),
+		AllowCredentials: boolEnvOr("CORS_ALLOW_CREDENTIALS", false),
+		IncludeHTML:      boolEnvOr("CORS_INCLUDE_HTML", false),
+		MaxAgeSeconds:    intEnvOr("CORS_MAX_AGE_SECONDS", 600),
+	}
+	if len(policy.AllowedMethods) == 0 {
+		policy.AllowedMethods = []string{http.MethodGet, http.MethodHead, http.MethodOptions}
+	}
+	if len(policy.AllowedHeaders) == 0 {
+		policy.AllowedHeaders = []string{"Accept", "Content-Type", "If-None-Match"}
+	}
+	if policy.AllowCredentials && containsRole(policy.AllowedOrigins, "*") {
+		return corsPolicy{}, errors.New("CORS_ALLOWED_ORIGINS=* cannot be combined with CORS_ALLOW_CREDENTIALS=true")
+	}
+	return policy, nil
+}
+
+func splitCSVEnv(key string) []string {
+	var values []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
+func (p corsPolicy) enabled() bool {
+	return len(p.AllowedOrigins) > 0
+}
+
+func (p corsPolicy) allowsOrigin(origin string) bool {
+	for _, allowed := range p.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// appliesTo reports whether r is a read of a JSON route: a GET or HEAD, or
+// the preflight for one. State-changing JSON routes stay same-origin. HTML
+// pages and the cookie based auth flows are only covered when IncludeHTML is
+// set.
+func (p corsPolicy) appliesTo(r *http.Request) bool {
+	if p.IncludeHTML {
+		return true
+	}
+	if !corsReadRequest(r) {
+		return false
+	}
+	path := r.URL.Path
+	switch {
+	case strings.HasPrefix(path, "/api/"), strings.HasSuffix(path, ".json"):
+		return true
+	case strings.HasPrefix(path, "/my/streams/") && (strings.HasSuffix(path, "/processes") || strings.HasSuffix(path, "/processes/search") || strings.HasSuffix(path, "/analytics")):
+		return true
+	case strings.HasPrefix(path, "/01/"):
+		return r.Method == http.MethodOptions || prefersJSONResponse(r)
+	default:
+		return false
+	}
+}
+
+func corsReadRequest(r *http.Request) bool {
+	method := r.Method
+	if method == http.MethodOptions {
+		method = strings.ToUpper(strings.TrimSpace(r.Header.Get("Access-Control-Request-Method")))
+	}
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+// withCORS wraps next with the CORS policy. Preflight requests for covered
+// routes are answered here; everything else passes through unchanged.
+func withCORS(policy corsPolicy, next http.Handler) http.Handler {
+	if !policy.enabled() {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := strings.TrimSpace(r.Header.Get("Origin"))
+		if origin == "" || !policy.appliesTo(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+		if !policy.allowsOrigin(origin) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if policy.AllowCredentials || !containsRole(policy.AllowedOrigins, "*") {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		} else {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		}
+		if policy.AllowCredentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(policy.AllowedMethods, ", "))
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(policy.AllowedHeaders, ", "))
+			if policy.MaxAgeSeconds > 0 {
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(policy.MaxAgeSeconds))
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Access-Control-Expose-Headers", "ETag, Retry-After")
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithCORS(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	policy := corsPolicy{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowedMethods:   []string{http.MethodGet, http.MethodOptions},
+		AllowedHeaders:   []string{"Accept", "If-None-Match"},
+		AllowCredentials: true,
+		MaxAgeSeconds:    600,
+	}
+	serve := func(handler http.Handler, method, path, origin string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		for key, value := range header {
+			req.Header.Set(key, value)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		rec := serve(withCORS(corsPolicy{}, next), http.MethodGet, "/my/streams/workflow/instance/1/notarized.json", "https://app.example.com", nil)
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" || rec.Code != http.StatusTeapot {
+			t.Fatalf("status = %d allow-origin = %q, want passthrough without CORS", rec.Code, got)
+		}
+	})
+
+	t.Run("json route allowed origin", func(t *testing.T) {
+		rec := serve(withCORS(policy, next), http.MethodGet, "/my/streams/workflow/instance/1/notarized.json", "https://app.example.com", nil)
+		if rec.Code != http.StatusTeapot {
+			t.Fatalf("status = %d, want passthrough", rec.Code)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+			t.Fatalf("allow-origin = %q", got)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+			t.Fatalf("allow-credentials = %q, want true", got)
+		}
+		if got := rec.Header().Get("Access-Control-Expose-Headers"); got != "ETag, Retry-After" {
+			t.Fatalf("expose-headers = %q", got)
+		}
+	})
+
+	t.Run("preflight", func(t *testing.T) {
+		rec := serve(withCORS(policy, next), http.MethodOptions, "/api/catalog", "https://app.example.com", map[string]string{"Access-Control-Request-Method": http.MethodGet})
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusNoContent)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Methods"); got != "GET, OPTIONS" {
+			t.Fatalf("allow-methods = %q", got)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Headers"); got != "Accept, If-None-Match" {
+			t.Fatalf("allow-headers = %q", got)
+		}
+		if got := rec.Header().Get("Access-Control-Max-Age"); got != "600" {
+			t.Fatalf("max-age = %q", got)
+		}
+	})
+
+	t.Run("state-changing requests excluded", func(t *testing.T) {
+		rec := serve(withCORS(policy, next), http.MethodPost, "/my/streams/workflow/instance/1/dpp/regenerate", "https://app.example.com", nil)
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" || rec.Code != http.StatusTeapot {
+			t.Fatalf("status = %d allow-origin = %q, want passthrough without CORS", rec.Code, got)
+		}
+		rec = serve(withCORS(policy, next), http.MethodOptions, "/api/catalog", "https://app.example.com", map[string]string{"Access-Control-Request-Method": http.MethodPost})
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" || rec.Code != http.StatusTeapot {
+			t.Fatalf("POST preflight status = %d allow-origin = %q, want passthrough without CORS", rec.Code, got)
+		}
+	})
+
+	t.Run("unknown origin", func(t *testing.T) {
+		rec := serve(withCORS(policy, next), http.MethodOptions, "/api/catalog", "https://evil.example", map[string]string{"Access-Control-Request-Method": http.MethodGet})
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" || rec.Code != http.StatusTeapot {
+			t.Fatalf("status = %d allow-origin = %q, want passthrough without CORS", rec.Code, got)
+		}
+	})
+
+	t.Run("html routes excluded unless enabled", func(t *testing.T) {
+		rec := serve(withCORS(policy, next), http.MethodGet, "/login", "https://app.example.com", nil)
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+			t.Fatalf("allow-origin = %q, want none for HTML route", got)
+		}
+		rec = serve(withCORS(policy, next), http.MethodGet, "/01/09506000134352/10/LOT/21/SER", "https://app.example.com", nil)
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+			t.Fatalf("allow-origin = %q, want none for DPP HTML", got)
+		}
+		rec = serve(withCORS(policy, next), http.MethodGet, "/01/09506000134352/10/LOT/21/SER", "https://app.example.com", map[string]string{"Accept": "application/json"})
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got == "" {
+			t.Fatal("expected CORS for DPP JSON")
+		}
+		htmlPolicy := policy
+		htmlPolicy.IncludeHTML = true
+		rec = serve(withCORS(htmlPolicy, next), http.MethodGet, "/login", "https://app.example.com", nil)
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got == "" {
+			t.Fatal("expected CORS for HTML route when IncludeHTML is set")
+		}
+	})
+}
+
+func TestCORSPolicyFromEnv(t *testing.T) {
+	t.Setenv("CORS_ALLOWED_ORIGINS", " https://a.example , https://b.example ")
+	t.Setenv("CORS_ALLOWED_METHODS", "")
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "true")
+	policy, err := corsPolicyFromEnv()
+	if err != nil {
+		t.Fatalf("corsPolicyFromEnv: %v", err)
+	}
+	if len(policy.AllowedOrigins) != 2 || policy.AllowedOrigins[1] != "https://b.example" {
+		t.Fatalf("origins = %#v", policy.AllowedOrigins)
+	}
+	if len(policy.AllowedMethods) != 3 || !policy.AllowCredentials {
+		t.Fatalf("unexpected policy %#v", policy)
+	}
+
+	t.Setenv("CORS_ALLOWED_ORIGINS", "*")
+	if _, err := corsPolicyFromEnv(); err == nil {
+		t.Fatal("expected wildcard origin with credentials to be rejected")
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+)
+
+// AggregatedDashboardResponse merges the stream dashboards of every workflow
+// the caller holds a role in. Each item carries its workflow key and name.
+type AggregatedDashboardResponse struct {
+	Workflows       []string          `json:"workflows"`
+	TodoActions     []TodoAction      `json:"todo_actions"`
+	TodoTotal       int               `json:"todo_total"`
+	ActiveProcesses []ProcessListItem `json:"active_processes"`
+	ActiveTotal     int               `json:"active_total"`
+	Truncated       bool              `json:"truncated"`
+	// NoRoles is set, with Message, when the caller has no role at all, so
+	// clients can tell onboarding apart from an empty dashboard.
+	NoRoles bool   `json:"no_roles,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// userHasWorkflowRole reports whether any of the user's roles is required by a
+// substep of cfg. Without enforced auth every workflow counts.
+func (s *Server) userHasWorkflowRole(user *AccountUser, cfg RuntimeConfig) bool {
+	if !s.enforceAuth {
+		return true
+	}
+	if user == nil {
+		return false
+	}
+	for _, sub := range orderedSubsteps(cfg.Workflow) {
+		for _, role := range substepRoles(sub) {
+			if containsRole(user.RoleSlugs, role) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// handleAggregatedDashboard serves GET /my/dashboard: todo actions and active
+// instances across workflows. Todos are ordered by how long they have been
+// waiting, oldest first; active instances by last activity, newest first.
+// Both lists are capped by s.dashboardListLimit() after merging.
+func (s *Server) handleAggregatedDashboard(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, _, ok := s.requireAuthenticatedPost(w, r)
+	if !ok {
+		return
+	}
+	if s.userHasNoRoles(user) {
+		writeJSON(w, AggregatedDashboardResponse{
+			Workflows:       []string{},
+			TodoActions:     []TodoAction{},
+			ActiveProcesses: []ProcessListItem{},
+			NoRoles:         true,
+			Message:         noRolesMessage,
+		})
+		return
+	}
+	catalog, err := s.workflowCatalog()
+	if err != nil {
+		logAndHTTPError(w, r, http.StatusInternalServerError, "failed to load workflows", err, "failed to load workflow catalog for dashboard")
+		return
+	}
+	response := AggregatedDashboardResponse{
+		Workflows:       []string{},
+		TodoActions:     []TodoAction{},
+		ActiveProcesses: []ProcessListItem{},
+	}
+	for _, key := range sortedWorkflowKeys(catalog) {
+		cfg := catalog[key]
+		if !s.userHasWorkflowRole(user, cfg) {
+			continue
+		}
+		dashboard, err := s.streamDashboardForUser(r.Context(), user, key, cfg, 0)
+		if err != nil {
+			logAndHTTPError(w, r, http.StatusInternalServerError, "failed to list processes", err, "failed to list processes for workflow %s", key)
+			return
+		}
+		response.Workflows = append(response.Workflows, key)
+		for _, todo := range dashboard.TodoActions {
+			todo.WorkflowKey = key
+			todo.WorkflowName = cfg.Workflow.Name
+			response.TodoActions = append(response.TodoActions, todo)
+		}
+		for _, item := range dashboard.ActiveProcesses {
+			item.WorkflowKey = key
+			item.WorkflowName = cfg.Workflow.Name
+			response.ActiveProcesses = append(response.ActiveProcesses, item)
+		}
+	}
+	// RFC 3339 UTC strings sort chronologically; todos without a start time
+	// go last.
+	sort.SliceStable(response.TodoActions, func(i, j int) bool {
+		left, right := response.TodoActions[i].AvailableAt, response.TodoActions[j].AvailableAt
+		if left == "" || right == "" {
+			return left != "" && right == ""
+		}
+		return left < right
+	})
+	sort.SliceStable(response.ActiveProcesses, func(i, j int) bool {
+		return response.ActiveProcesses[i].UpdatedAt > response.ActiveProcesses[j].UpdatedAt
+	})
+	response.TodoTotal = len(response.TodoActions)
+	response.ActiveTotal = len(response.ActiveProcesses)
+	if limit := s.dashboardListLimit(); limit > 0 {
+		if len(response.TodoActions) > limit {
+			response.TodoActions = response.TodoActions[:limit]
+		}
+		if len(response.ActiveProcesses) > limit {
+			response.ActiveProcesses = response.ActiveProcesses[:limit]
+		}
+	}
+	response.Truncated = response.TodoTotal > len(response.TodoActions) || response.ActiveTotal > len(response.ActiveProcesses)
+	writeJSON(w, response)
+}
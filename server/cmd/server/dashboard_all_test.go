@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestHandleAggregatedDashboardMergesWorkflowsWithUserRoles(t *testing.T) {
+	now := time.Date(2026, 2, 4, 11, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	writeTwoSubstepWorkflowConfig(t, filepath.Join(dir, "alpha.yaml"), "Alpha")
+	writeTwoSubstepWorkflowConfig(t, filepath.Join(dir, "beta.yaml"), "Beta")
+	writeTwoSubstepWorkflowConfig(t, filepath.Join(dir, "gamma.yaml"), "Gamma")
+	gammaPath := filepath.Join(dir, "gamma.yaml")
+	data, err := os.ReadFile(gammaPath)
+	if err != nil {
+		t.Fatalf("read gamma: %v", err)
+	}
+	if err := os.WriteFile(gammaPath, []byte(strings.ReplaceAll(string(data), "dep1", "dep9")), 0o644); err != nil {
+		t.Fatalf("write gamma: %v", err)
+	}
+
+	store := NewMemoryStore()
+	alpha := store.SeedProcess(Process{WorkflowKey: "alpha", Name: "A", CreatedAt: now.Add(-time.Hour), Status: processStatusActive, Progress: map[string]ProcessStep{}})
+	beta := store.SeedProcess(Process{WorkflowKey: "beta", Name: "B", CreatedAt: now.Add(-3 * time.Hour), Status: processStatusActive, Progress: map[string]ProcessStep{}})
+	store.SeedProcess(Process{WorkflowKey: "gamma", CreatedAt: now.Add(-5 * time.Hour), Status: processStatusActive, Progress: map[string]ProcessStep{}})
+
+	user := AccountUser{ID: primitive.NewObjectID(), IdentityUserID: "user-1", Email: "user@example.com", Status: "active", OrgSlug: "org1", RoleSlugs: []string{"dep1"}}
+	server := &Server{
+		store:       store,
+		configDir:   dir,
+		authorizer:  fakeAuthorizer{},
+		identity:    testIdentityForSessions(now, map[string]AccountUser{"session-user": user}),
+		enforceAuth: true,
+		now:         func() time.Time { return now },
+	}
+	req := httptest.NewRequest(http.MethodGet, "/my/dashboard", nil)
+	req.AddCookie(&http.Cookie{Name: "attesta_session", Value: "session-user"})
+	rec := httptest.NewRecorder()
+	server.handleMyRoutes(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var response AggregatedDashboardResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if strings.Join(response.Workflows, ",") != "alpha,beta" {
+		t.Fatalf("workflows = %v, want alpha,beta", response.Workflows)
+	}
+	if response.TodoTotal != 2 || len(response.TodoActions) != 2 {
+		t.Fatalf("todos = %#v", response.TodoActions)
+	}
+	first, second := response.TodoActions[0], response.TodoActions[1]
+	if first.ProcessID != beta.Hex() || first.WorkflowKey != "beta" || first.WorkflowName != "Beta" || first.AvailableAt != "2026-02-04T08:00:00Z" {
+		t.Fatalf("first todo = %#v, want oldest (beta)", first)
+	}
+	if second.ProcessID != alpha.Hex() || second.WorkflowKey != "alpha" || second.URL != streamInstancePath("alpha", alpha.Hex()) {
+		t.Fatalf("second todo = %#v", second)
+	}
+	if response.ActiveTotal != 2 || response.ActiveProcesses[0].ProcessID != alpha.Hex() || response.ActiveProcesses[0].WorkflowName != "Alpha" {
+		t.Fatalf("active = %#v", response.ActiveProcesses)
+	}
+}
+
+func TestHandleAggregatedDashboardCapsMergedLists(t *testing.T) {
+	now := time.Date(2026, 2, 4, 11, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	writeTwoSubstepWorkflowConfig(t, filepath.Join(dir, "alpha.yaml"), "Alpha")
+	writeTwoSubstepWorkflowConfig(t, filepath.Join(dir, "beta.yaml"), "Beta")
+	store := NewMemoryStore()
+	store.SeedProcess(Process{WorkflowKey: "alpha", CreatedAt: now, Status: processStatusActive, Progress: map[string]ProcessStep{}})
+	store.SeedProcess(Process{WorkflowKey: "beta", CreatedAt: now, Status: processStatusActive, Progress: map[string]ProcessStep{}})
+
+	user := AccountUser{ID: primitive.NewObjectID(), IdentityUserID: "user-1", Email: "user@example.com", Status: "active", OrgSlug: "org1", RoleSlugs: []string{"dep1"}}
+	server := &Server{
+		store:          store,
+		configDir:      dir,
+		authorizer:     fakeAuthorizer{},
+		identity:       testIdentityForSessions(now, map[string]AccountUser{"session-user": user}),
+		enforceAuth:    true,
+		dashboardLimit: 1,
+		now:            func() time.Time { return now },
+	}
+	req := httptest.NewRequest(http.MethodGet, "/my/dashboard", nil)
+	req.AddCookie(&http.Cookie{Name: "attesta_session", Value: "session-user"})
+	rec := httptest.NewRecorder()
+	server.handleAggregatedDashboard(rec, req)
+
+	var response AggregatedDashboardResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode response: %v (%s)", err, rec.Body.String())
+	}
+	if !response.Truncated || len(response.TodoActions) != 1 || response.TodoTotal != 2 || len(response.ActiveProcesses) != 1 || response.ActiveTotal != 2 {
+		t.Fatalf("unexpected capped dashboard %#v", response)
+	}
+}
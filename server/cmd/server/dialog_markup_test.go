@@ -20,6 +20,7 @@ func TestStreamPageNewInstanceDialogMarkup(t *testing.T) {
 		Sort:          "time_desc",
 		FilterOptions: testHomeFilterOptions(),
 		ProcessGroups: testHomeActiveProcessGroups(nil, "all", "time_desc", 1),
+		CanStart:      true,
 	}
 	if err := tmpl.ExecuteTemplate(&out, "home_body", view); err != nil {
 		t.Fatalf("render home_body: %v", err)
@@ -203,3 +204,33 @@ func TestStreamPreviewDialogPageScopedMarkup(t *testing.T) {
 		t.Fatal("expected stream preview card sizing class removed from markup")
 	}
 }
+
+func TestStreamPageHidesNewInstanceWithoutCanStart(t *testing.T) {
+	tmpl := parseTestTemplates(t)
+
+	var out bytes.Buffer
+	view := HomeView{
+		PageBase: PageBase{
+			WorkflowKey:  "workflow",
+			WorkflowPath: "/my/streams/workflow",
+			WorkflowName: "Demo workflow",
+		},
+		StatusFilter:  "all",
+		Sort:          "time_desc",
+		FilterOptions: testHomeFilterOptions(),
+		ProcessGroups: testHomeActiveProcessGroups(nil, "all", "time_desc", 1),
+	}
+	if err := tmpl.ExecuteTemplate(&out, "home_body", view); err != nil {
+		t.Fatalf("render home_body: %v", err)
+	}
+	body := out.String()
+
+	for _, unwanted := range []string{`id="new-instance-dialog"`, "New instance", "/instance/start"} {
+		if strings.Contains(body, unwanted) {
+			t.Fatalf("expected %q to be hidden without CanStart, got:\n%s", unwanted, body)
+		}
+	}
+	if !strings.Contains(body, "View preview") {
+		t.Fatal("expected preview action to remain visible")
+	}
+}
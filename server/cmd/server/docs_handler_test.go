@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -27,11 +28,11 @@ func TestHandleDocsRoutes(t *testing.T) {
 			wantLocation: "/docs/",
 		},
 		{
-			name:             "swagger ui html",
+			name:             "docs html",
 			path:             "/docs/",
 			wantStatus:       http.StatusOK,
 			wantContentType:  "text/html; charset=utf-8",
-			wantBodyContains: "SwaggerUIBundle",
+			wantBodyContains: "/docs/openapi3.json",
 		},
 		{
 			name:             "openapi json",
@@ -98,21 +99,23 @@ func TestOpenAPIDocCandidates(t *testing.T) {
 }
 
 func TestOpenAPIRequestOriginUsesForwardedHeaders(t *testing.T) {
+	server := &Server{trustedProxies: testTrustedProxies(t, "192.0.2.0/24")}
 	req := httptest.NewRequest(http.MethodGet, "http://internal:3000/docs/openapi3.json", nil)
 	req.Header.Set("X-Forwarded-Proto", "https")
 	req.Header.Set("X-Forwarded-Host", "attesta.example.com")
 
-	if got := openAPIRequestOrigin(req); got != "https://attesta.example.com" {
+	if got := server.openAPIRequestOrigin(req); got != "https://attesta.example.com" {
 		t.Fatalf("origin = %q, want %q", got, "https://attesta.example.com")
 	}
 }
 
 func TestOpenAPIRequestOriginFallbacks(t *testing.T) {
+	server := &Server{trustedProxies: testTrustedProxies(t, "192.0.2.0/24")}
 	t.Run("forwarded header", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodGet, "http://internal:3000/docs/openapi3.json", nil)
 		req.Header.Set("Forwarded", `for=192.0.2.1;proto=https;host="attesta.example.com"`)
 
-		if got := openAPIRequestOrigin(req); got != "https://attesta.example.com" {
+		if got := server.openAPIRequestOrigin(req); got != "https://attesta.example.com" {
 			t.Fatalf("origin = %q, want %q", got, "https://attesta.example.com")
 		}
 	})
@@ -120,7 +123,7 @@ func TestOpenAPIRequestOriginFallbacks(t *testing.T) {
 	t.Run("request host", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodGet, "http://localhost:3001/docs/openapi3.json", nil)
 
-		if got := openAPIRequestOrigin(req); got != "http://localhost:3001" {
+		if got := server.openAPIRequestOrigin(req); got != "http://localhost:3001" {
 			t.Fatalf("origin = %q, want %q", got, "http://localhost:3001")
 		}
 	})
@@ -130,7 +133,7 @@ func TestOpenAPIRequestOriginFallbacks(t *testing.T) {
 		req.Header.Set("X-Forwarded-Proto", "ftp")
 		req.Header.Set("X-Forwarded-Host", "attesta.example.com")
 
-		if got := openAPIRequestOrigin(req); got != "https://attesta.example.com" {
+		if got := server.openAPIRequestOrigin(req); got != "https://attesta.example.com" {
 			t.Fatalf("origin = %q, want %q", got, "https://attesta.example.com")
 		}
 	})
@@ -213,7 +216,7 @@ func TestRewriteOpenAPIServersErrors(t *testing.T) {
 }
 
 func TestServeOpenAPIFileRewritesServerToRequestOrigin(t *testing.T) {
-	server := &Server{}
+	server := &Server{trustedProxies: testTrustedProxies(t, "192.0.2.0/24")}
 	req := httptest.NewRequest(http.MethodGet, "http://internal:3000/docs/openapi3.json", nil)
 	req.Header.Set("X-Forwarded-Proto", "https")
 	req.Header.Set("X-Forwarded-Host", "attesta.example.com")
@@ -251,3 +254,51 @@ func TestServeOpenAPIFileNotFound(t *testing.T) {
 		t.Fatalf("expected missing OpenAPI error body, got %q", rec.Body.String())
 	}
 }
+
+func TestSwaggerUIPageUsesLocalAssets(t *testing.T) {
+	render := func(server *Server) string {
+		rec := httptest.NewRecorder()
+		server.handleDocs(rec, httptest.NewRequest(http.MethodGet, "/docs/", nil))
+		return rec.Body.String()
+	}
+
+	staticDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(staticDir, "swagger-ui"), 0o755); err != nil {
+		t.Fatalf("mkdir swagger-ui: %v", err)
+	}
+	for _, name := range []string{"swagger-ui.css", "swagger-ui-bundle.js"} {
+		if err := os.WriteFile(filepath.Join(staticDir, "swagger-ui", name), []byte("/* vendored */"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	body := render(&Server{staticDir: staticDir})
+	if strings.Contains(body, "unpkg.com") {
+		t.Fatalf("expected no CDN references, got %s", body)
+	}
+	for _, want := range []string{`href="/static/swagger-ui/swagger-ui.css"`, `src="/static/swagger-ui/swagger-ui-bundle.js"`, `href="/static/attesta-favicon.svg"`, "<title>Attesta API Docs</title>"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %s in docs page, got %s", want, body)
+		}
+	}
+
+	body = render(&Server{docsTitle: "Acme <API>", docsFaviconURL: "https://cdn.example.com/icon.svg", viteDevServer: "http://localhost:5173"})
+	for _, want := range []string{"<title>Acme &lt;API&gt;</title>", `href="https://cdn.example.com/icon.svg"`, `src="http://localhost:5173/swagger-ui/swagger-ui-bundle.js"`} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %s in configured docs page, got %s", want, body)
+		}
+	}
+}
+
+func TestDocsPageFallsBackToSpecLinksWithoutSwaggerUIAssets(t *testing.T) {
+	rec := httptest.NewRecorder()
+	(&Server{staticDir: t.TempDir()}).handleDocs(rec, httptest.NewRequest(http.MethodGet, "/docs/", nil))
+	body := rec.Body.String()
+	if rec.Code != http.StatusOK || strings.Contains(body, "swagger-ui-bundle.js") {
+		t.Fatalf("expected fallback page without Swagger UI, got %d %s", rec.Code, body)
+	}
+	for _, want := range []string{`href="/docs/openapi3.json"`, `href="/docs/openapi3.yaml"`, "task web:vendor-swagger-ui"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %s in fallback docs page, got %s", want, body)
+		}
+	}
+}
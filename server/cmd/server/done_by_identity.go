@@ -51,3 +51,38 @@ func (s *Server) lookupUserIdentityByActorID(ctx context.Context, actorID string
 	cache[id] = userIdentityView{}
 	return userIdentityView{}, false
 }
+
+// legacyProcessCreator is recorded as Process.CreatedBy when auth is not
+// enforced (tests and processes created before authentication).
+const legacyProcessCreator = "demo"
+
+// processCreatedBy returns the recorded initiator actor ID, hiding the legacy
+// placeholder used before real authentication.
+func processCreatedBy(process *Process) string {
+	if process == nil {
+		return ""
+	}
+	createdBy := strings.TrimSpace(process.CreatedBy)
+	if createdBy == legacyProcessCreator {
+		return ""
+	}
+	return createdBy
+}
+
+// createdByDisplay resolves the process initiator for display with the same
+// visibility rules as done-by: email for viewers in a participating
+// organization, the stable actor ID otherwise.
+func (s *Server) createdByDisplay(ctx context.Context, def WorkflowDef, viewer Actor, createdBy string, cache map[string]userIdentityView) string {
+	createdBy = processCreatedBy(&Process{CreatedBy: createdBy})
+	if createdBy == "" {
+		return ""
+	}
+	identity, ok := s.lookupUserIdentityByActorID(ctx, createdBy, cache)
+	if !ok {
+		return createdBy
+	}
+	if viewerCanSeeDoneByEmail(def, viewer) && strings.TrimSpace(identity.email) != "" {
+		return identity.email
+	}
+	return firstNonEmpty(identity.fallbackID, createdBy)
+}
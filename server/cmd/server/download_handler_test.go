@@ -290,3 +290,45 @@ func TestHandleDownloadProcessAttachmentDefaultsContentType(t *testing.T) {
 		t.Fatalf("content-type = %q, want application/octet-stream", got)
 	}
 }
+
+func TestHandleDownloadProcessBlobBySHA256(t *testing.T) {
+	store := NewMemoryStore()
+	processID := primitive.NewObjectID()
+	otherProcessID := primitive.NewObjectID()
+	save := func(owner primitive.ObjectID, content string) Attachment {
+		attachment, err := store.SaveAttachment(t.Context(), AttachmentUpload{
+			ProcessID:   owner,
+			SubstepID:   "1.3",
+			Filename:    "evidence.txt",
+			ContentType: "text/plain",
+			MaxBytes:    1024,
+		}, strings.NewReader(content))
+		if err != nil {
+			t.Fatalf("save attachment: %v", err)
+		}
+		return attachment
+	}
+	attachment := save(processID, "blob-content")
+	foreign := save(otherProcessID, "foreign-content")
+	store.SeedProcess(Process{ID: processID, CreatedAt: time.Now().UTC(), Status: "active"})
+	server := &Server{
+		store:          store,
+		tmpl:           testTemplates(),
+		configProvider: func() (RuntimeConfig, error) { return testRuntimeConfig(), nil },
+	}
+	get := func(sha string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		server.handleProcessRoutes(rr, httptest.NewRequest(http.MethodGet, "/instance/"+processID.Hex()+"/blob/"+sha, nil))
+		return rr
+	}
+
+	rr := get(strings.ToUpper(attachment.SHA256))
+	if rr.Code != http.StatusOK || rr.Body.String() != "blob-content" {
+		t.Fatalf("blob status = %d body = %q", rr.Code, rr.Body.String())
+	}
+	for _, sha := range []string{foreign.SHA256, strings.Repeat("0", 64), "not-a-hash"} {
+		if rr := get(sha); rr.Code != http.StatusNotFound {
+			t.Fatalf("blob %q status = %d, want %d", sha, rr.Code, http.StatusNotFound)
+		}
+	}
+}
@@ -37,13 +37,16 @@ func buildProcessDPP(def WorkflowDef, cfg DPPConfig, process *Process, generated
 	}
 
 	lot := dppFirstStringValue(def, process, cfg.LotInputKey)
-	if lot == "" {
+	if lot == "" && !cfg.RequireLot {
 		lot = cfg.LotDefault
 	}
 	serial := ""
 	if cfg.SerialInputKey != "" {
 		serial = dppFirstStringValue(def, process, cfg.SerialInputKey)
 	}
+	if serial == "" && cfg.RequireSerialInput {
+		return ProcessDPP{}, errors.New("missing dpp serial value")
+	}
 	if serial == "" {
 		derivedSerial, err := dppSerialFromStrategy(cfg.SerialStrategy, process.ID)
 		if err != nil {
@@ -57,11 +60,15 @@ func buildProcessDPP(def WorkflowDef, cfg DPPConfig, process *Process, generated
 	if serial == "" {
 		return ProcessDPP{}, errors.New("missing dpp serial value")
 	}
+	product := resolveDPPProductInfo(def, cfg, process)
 	return ProcessDPP{
-		GTIN:        cfg.GTIN,
-		Lot:         lot,
-		Serial:      serial,
-		GeneratedAt: generatedAt,
+		GTIN:               cfg.GTIN,
+		Lot:                lot,
+		Serial:             serial,
+		ProductName:        product.ProductName,
+		ProductDescription: product.ProductDescription,
+		OwnerName:          product.OwnerName,
+		GeneratedAt:        generatedAt,
 	}, nil
 }
 
@@ -247,6 +254,8 @@ func buildDPPTraceabilitySubstep(ctx timelineSubstepBuildContext) TimelineSubste
 	reason := ""
 	doneAtHuman := ""
 	doneAtISO := ""
+	amendedAt := ""
+	amendedAtISO := ""
 	doneBy := ""
 	palette := meta.Palette
 	var values []SubstepKV
@@ -286,9 +295,10 @@ func buildDPPTraceabilitySubstep(ctx timelineSubstepBuildContext) TimelineSubste
 				palette = selectedMeta.Palette
 			}
 		}
-		digest = digestPayload(progress.Data)
+		digest = currentStepDigest(progress)
 		values = dppTraceValues(sub, progress)
-		attachments = buildSubstepAttachments(ctx.workflowKey, process, progress.Data)
+		attachments = buildSubstepAttachments(ctx.workflowKey, process, currentStepData(progress))
+		amendedAt, amendedAtISO = amendedAtDisplay(progress)
 	case processStatusTerminated:
 		reason = "Stream ended early"
 		detailMessage = state.terminationReason
@@ -298,6 +308,10 @@ func buildDPPTraceabilitySubstep(ctx timelineSubstepBuildContext) TimelineSubste
 	case "skipped":
 		reason = "Stream ended early"
 		detailMessage = "Step not completed because the stream was ended before this."
+		if state.hidden[sub.SubstepID] {
+			reason = substepHiddenReason
+			detailMessage = substepHiddenDetail
+		}
 	}
 
 	processID := ""
@@ -329,7 +343,10 @@ func buildDPPTraceabilitySubstep(ctx timelineSubstepBuildContext) TimelineSubste
 		HasOverride:    hasOverride,
 		OverrideReason: overrideReason,
 		Digest:         digest,
+		AmendedAt:      amendedAt,
+		AmendedAtISO:   amendedAtISO,
 	})
+	applyReworkNotice(&body, process)
 	return TimelineSubstep{
 		SubstepID: sub.SubstepID,
 		Title:     sub.Title,
@@ -356,14 +373,14 @@ func rfc3339UTC(value time.Time) string {
 }
 
 func dppTraceValues(sub WorkflowSub, progress ProcessStep) []SubstepKV {
-	data := progress.Data
+	data := currentStepData(progress)
 	if len(data) == 0 {
 		return nil
 	}
 
 	flattened := make([]SubstepKV, 0)
 	if raw, ok := processStepDataValue(progress, sub); ok {
-		flattened = append(flattened, flattenDisplayValues("", raw)...)
+		flattened = append(flattened, substepDisplayValues(sub, raw)...)
 	}
 	if len(flattened) == 0 {
 		keys := make([]string, 0, len(data))
@@ -72,6 +72,46 @@ func TestHandleDigitalLinkDPPJSON(t *testing.T) {
 	}
 }
 
+func TestHandleDigitalLinkDPPSignsExport(t *testing.T) {
+	tempDir := t.TempDir()
+	writeWorkflowConfig(t, tempDir+"/workflow.yaml", "Demo workflow", "string")
+
+	store := NewMemoryStore()
+	process := seedDPPProcess(store)
+	server := &Server{
+		store:     store,
+		tmpl:      testTemplates(),
+		configDir: tempDir,
+	}
+	link := digitalLinkURL(process.DPP.GTIN, process.DPP.Lot, process.DPP.Serial)
+	get := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("Accept", "application/json")
+		rr := httptest.NewRecorder()
+		server.handleDigitalLinkDPP(rr, req)
+		return rr
+	}
+
+	if rr := get(link + "/notarized.json.sig"); rr.Code != http.StatusNotFound {
+		t.Fatalf("signature without key status = %d, want %d", rr.Code, http.StatusNotFound)
+	}
+
+	server.exportSigner = &exportSigner{key: []byte("secret"), keyID: "dpp"}
+	var payload struct {
+		Export NotarizedProcessExport `json:"export"`
+	}
+	if err := json.Unmarshal(get(link).Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode response JSON: %v", err)
+	}
+	if payload.Export.Signature == nil || payload.Export.Signature.KeyID != "dpp" {
+		t.Fatalf("expected signed export, got %#v", payload.Export.Signature)
+	}
+	rr := get(link + "/notarized.json.sig")
+	if rr.Code != http.StatusOK || strings.TrimSpace(rr.Body.String()) != payload.Export.Signature.Value {
+		t.Fatalf("signature status = %d body = %q, want %q", rr.Code, rr.Body.String(), payload.Export.Signature.Value)
+	}
+}
+
 func TestHandleDigitalLinkDPPNotFound(t *testing.T) {
 	server := &Server{
 		store: NewMemoryStore(),
@@ -639,3 +679,93 @@ func writeFileWorkflowConfig(t *testing.T, path string) {
 		t.Fatalf("write temp config %s: %v", path, err)
 	}
 }
+
+func TestHandleRegenerateProcessDPP(t *testing.T) {
+	t.Setenv("ADMIN_EMAIL", "admin@example.com")
+	t.Setenv("ADMIN_PASSWORD", "change-me")
+	now := time.Date(2026, 4, 1, 8, 0, 0, 0, time.UTC)
+	cfg := testRuntimeConfig()
+	cfg.DPP = DPPConfig{Enabled: true, GTIN: "09506000134369", LotDefault: "LOT-NEW", SerialStrategy: "process_id_hex"}
+	seed := func(store *MemoryStore, done bool) Process {
+		process := processWithDone("1.1", "1.2", "1.3", "2.1", "2.2", "3.1", "3.2")
+		process.ID = primitive.NewObjectID()
+		process.WorkflowKey = "workflow"
+		process.Status = "done"
+		process.DPP = &ProcessDPP{GTIN: "09506000134352", Lot: "LOT-OLD", Serial: process.ID.Hex()}
+		if !done {
+			delete(process.Progress, "3.2")
+			process.Status = "active"
+		}
+		store.SeedProcess(*process)
+		return *process
+	}
+	newRequest := func(server *Server, processID, session string) *httptest.ResponseRecorder {
+		server.identity = testIdentityForSessions(now, map[string]AccountUser{"session-user": {IdentityUserID: "u-1", OrgSlug: "acme"}})
+		req := httptest.NewRequest(http.MethodPost, "/instance/"+processID+"/dpp/regenerate", nil)
+		req.AddCookie(&http.Cookie{Name: "attesta_session", Value: session})
+		req = req.WithContext(context.WithValue(req.Context(), workflowContextKey{}, workflowContextValue{Key: "workflow", Cfg: cfg}))
+		rr := httptest.NewRecorder()
+		server.handleProcessRoutes(rr, req)
+		return rr
+	}
+	admin := platformAdminSessionValue()
+
+	t.Run("regenerates digital link", func(t *testing.T) {
+		store := NewMemoryStore()
+		process := seed(store, true)
+		server := &Server{store: store, authorizer: fakeAuthorizer{}, enforceAuth: true, now: func() time.Time { return now }}
+		rr := newRequest(server, process.ID.Hex(), admin)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("status = %d body = %q, want 200", rr.Code, rr.Body.String())
+		}
+		var payload ProcessDPPResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &payload); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		wantLink := digitalLinkURL("09506000134369", "LOT-NEW", process.ID.Hex())
+		if payload.DigitalLink != wantLink || payload.GeneratedAt != "2026-04-01T08:00:00Z" {
+			t.Fatalf("unexpected payload %#v", payload)
+		}
+		stored, _ := store.LoadProcessByID(t.Context(), process.ID)
+		if stored.DPP == nil || stored.DPP.GTIN != "09506000134369" || stored.DPP.Lot != "LOT-NEW" {
+			t.Fatalf("expected stored dpp to be overwritten, got %#v", stored.DPP)
+		}
+	})
+
+	t.Run("rejects non admin", func(t *testing.T) {
+		store := NewMemoryStore()
+		process := seed(store, true)
+		server := &Server{store: store, authorizer: fakeAuthorizer{}, enforceAuth: true, now: func() time.Time { return now }}
+		if rr := newRequest(server, process.ID.Hex(), "session-user"); rr.Code != http.StatusForbidden {
+			t.Fatalf("status = %d, want %d", rr.Code, http.StatusForbidden)
+		}
+	})
+
+	t.Run("rejects incomplete process", func(t *testing.T) {
+		store := NewMemoryStore()
+		process := seed(store, false)
+		server := &Server{store: store, authorizer: fakeAuthorizer{}, enforceAuth: true, now: func() time.Time { return now }}
+		if rr := newRequest(server, process.ID.Hex(), admin); rr.Code != http.StatusConflict {
+			t.Fatalf("status = %d, want %d", rr.Code, http.StatusConflict)
+		}
+	})
+
+	t.Run("rejects colliding digital link", func(t *testing.T) {
+		store := NewMemoryStore()
+		process := seed(store, true)
+		store.SeedProcess(Process{
+			ID:          primitive.NewObjectID(),
+			WorkflowKey: "workflow",
+			DPP:         &ProcessDPP{GTIN: "09506000134369", Lot: "LOT-NEW", Serial: process.ID.Hex()},
+		})
+		server := &Server{store: store, authorizer: fakeAuthorizer{}, enforceAuth: true, now: func() time.Time { return now }}
+		rr := newRequest(server, process.ID.Hex(), admin)
+		if rr.Code != http.StatusConflict || !strings.Contains(rr.Body.String(), "another process") {
+			t.Fatalf("status = %d body = %q, want 409 collision", rr.Code, rr.Body.String())
+		}
+		stored, _ := store.LoadProcessByID(t.Context(), process.ID)
+		if stored.DPP.GTIN != "09506000134352" {
+			t.Fatalf("expected original dpp to be kept, got %#v", stored.DPP)
+		}
+	})
+}
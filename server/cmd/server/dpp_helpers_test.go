@@ -1,6 +1,7 @@
 package main
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -27,6 +28,49 @@ func TestParseDigitalLinkPathValidAndInvalid(t *testing.T) {
 	}
 }
 
+func TestNormalizeGTINCheckDigit(t *testing.T) {
+	valid := map[string]string{
+		"09506000134352": "09506000134352",
+		"9506000134352":  "09506000134352",
+		"4006381333931":  "04006381333931",
+		"012345678905":   "00012345678905",
+		"96385074":       "00000096385074",
+		"10614141000415": "10614141000415",
+	}
+	for raw, want := range valid {
+		got, err := normalizeGTIN(raw)
+		if err != nil {
+			t.Fatalf("normalizeGTIN(%q): %v", raw, err)
+		}
+		if got != want {
+			t.Fatalf("normalizeGTIN(%q) = %q, want %q", raw, got, want)
+		}
+	}
+	for _, raw := range []string{"09506000134353", "4006381333932", "012345678901", "96385075", "10614141000410"} {
+		if _, err := normalizeGTIN(raw); err == nil || !strings.Contains(err.Error(), "invalid check digit") {
+			t.Fatalf("normalizeGTIN(%q) err = %v, want check digit error", raw, err)
+		}
+	}
+	if _, _, _, err := parseDigitalLinkPath("/01/09506000134353/10/LOT-001/21/SERIAL-001"); err == nil {
+		t.Fatal("expected digital link with invalid check digit to be rejected")
+	}
+}
+
+func TestAppendGTINCheckDigit(t *testing.T) {
+	got, err := appendGTINCheckDigit("1061414100041")
+	if err != nil {
+		t.Fatalf("appendGTINCheckDigit(): %v", err)
+	}
+	if got != "10614141000415" {
+		t.Fatalf("appendGTINCheckDigit() = %q, want %q", got, "10614141000415")
+	}
+	for _, raw := range []string{"09506000134352", "950600013435", "95060001343x5"} {
+		if _, err := appendGTINCheckDigit(raw); err == nil {
+			t.Fatalf("appendGTINCheckDigit(%q) expected error", raw)
+		}
+	}
+}
+
 func TestParseDigitalLinkAttachmentPath(t *testing.T) {
 	gtin, lot, serial, attachmentID, ok, err := parseDigitalLinkAttachmentPath("/01/09506000134352/10/LOT-001/21/SERIAL-001/attachment/file%201/file")
 	if err != nil {
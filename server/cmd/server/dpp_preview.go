@@ -0,0 +1,116 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DPPPreviewResponse is the digital link a process would get if its DPP were
+// generated now. Missing lists the configured inputs no completed substep has
+// provided yet; when Ready is still true the link falls back to lotDefault or
+// serialStrategy for them and changes once they are completed.
+type DPPPreviewResponse struct {
+	ProcessID   string                   `json:"process_id"`
+	Ready       bool                     `json:"ready"`
+	Generated   bool                     `json:"generated"`
+	GTIN        string                   `json:"gtin"`
+	Lot         string                   `json:"lot,omitempty"`
+	Serial      string                   `json:"serial,omitempty"`
+	DigitalLink string                   `json:"digital_link,omitempty"`
+	Error       string                   `json:"error,omitempty"`
+	Missing     []DPPPreviewMissingInput `json:"missing,omitempty"`
+}
+
+// DPPPreviewMissingInput names a dpp input key and the substeps, not yet
+// done, whose payload can carry it.
+type DPPPreviewMissingInput struct {
+	Field      string   `json:"field"`
+	InputKey   string   `json:"input_key"`
+	SubstepIDs []string `json:"substep_ids"`
+}
+
+// dppInputSubsteps lists the substeps, in workflow order, whose payload can
+// hold key: a formata schema property or the substep's own input key.
+func dppInputSubsteps(def WorkflowDef, key string) []WorkflowSub {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return nil
+	}
+	var substeps []WorkflowSub
+	for _, substep := range orderedSubsteps(def) {
+		properties, _ := substep.Schema["properties"].(map[string]interface{})
+		if _, ok := properties[key]; ok || key == strings.TrimSpace(substep.InputKey) || key == substepDataKey(substep) {
+			substeps = append(substeps, substep)
+		}
+	}
+	return substeps
+}
+
+// dppMissingInput reports key as missing when no completed substep carries a
+// value for it; ok is false when it is already provided or not configured.
+func dppMissingInput(def WorkflowDef, process *Process, field, key string) (DPPPreviewMissingInput, bool) {
+	key = strings.TrimSpace(key)
+	if key == "" || dppFirstStringValue(def, process, key) != "" {
+		return DPPPreviewMissingInput{}, false
+	}
+	missing := DPPPreviewMissingInput{Field: field, InputKey: key, SubstepIDs: []string{}}
+	for _, substep := range dppInputSubsteps(def, key) {
+		if progress, ok := process.Progress[substep.SubstepID]; ok && progress.State == "done" {
+			continue
+		}
+		missing.SubstepIDs = append(missing.SubstepIDs, substep.SubstepID)
+	}
+	return missing, true
+}
+
+// buildDPPPreview runs buildProcessDPP against the current progress without
+// storing anything. A process whose DPP was already generated reports it as
+// stored.
+func buildDPPPreview(def WorkflowDef, cfg DPPConfig, process *Process) DPPPreviewResponse {
+	preview := DPPPreviewResponse{ProcessID: process.ID.Hex(), GTIN: cfg.GTIN}
+	if process.DPP != nil {
+		preview.Ready = true
+		preview.Generated = true
+		preview.GTIN = process.DPP.GTIN
+		preview.Lot = process.DPP.Lot
+		preview.Serial = process.DPP.Serial
+		preview.DigitalLink = digitalLinkURL(process.DPP.GTIN, process.DPP.Lot, process.DPP.Serial)
+		return preview
+	}
+	if missing, ok := dppMissingInput(def, process, "lot", cfg.LotInputKey); ok {
+		preview.Missing = append(preview.Missing, missing)
+	}
+	if missing, ok := dppMissingInput(def, process, "serial", cfg.SerialInputKey); ok {
+		preview.Missing = append(preview.Missing, missing)
+	}
+	dpp, err := buildProcessDPP(def, cfg, process, time.Time{})
+	if err != nil {
+		preview.Error = err.Error()
+		return preview
+	}
+	preview.Ready = true
+	preview.Lot = dpp.Lot
+	preview.Serial = dpp.Serial
+	preview.DigitalLink = digitalLinkURL(dpp.GTIN, dpp.Lot, dpp.Serial)
+	return preview
+}
+
+// handleDPPPreview serves GET .../instance/:id/dpp/preview.json so partners
+// can pre-register the digital link before the process completes.
+func (s *Server) handleDPPPreview(w http.ResponseWriter, r *http.Request, processID string) {
+	workflowKey, cfg, ok := s.selectedWorkflowOrRedirectHome(w, r)
+	if !ok {
+		return
+	}
+	if !cfg.DPP.Enabled {
+		http.NotFound(w, r)
+		return
+	}
+	process, err := s.loadProcess(r.Context(), processID)
+	if err != nil || !s.processBelongsToWorkflow(process, workflowKey) {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, buildDPPPreview(cfg.Workflow, cfg.DPP, process))
+}
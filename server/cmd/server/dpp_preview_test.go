@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHandleDPPPreview(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	cfg := RuntimeConfig{
+		Workflow: WorkflowDef{Steps: []WorkflowStep{{
+			StepID: "1",
+			Substep: []WorkflowSub{
+				{SubstepID: "1.1", Order: 1, Role: "dep1", InputType: "formata", Schema: map[string]interface{}{
+					"type":       "object",
+					"properties": map[string]interface{}{"batchId": map[string]interface{}{"type": "string"}},
+				}},
+				{SubstepID: "1.2", Order: 2, Role: "dep1", InputKey: "note", InputType: "formata", Schema: map[string]interface{}{"type": "object"}},
+			},
+		}}},
+		DPP: DPPConfig{Enabled: true, GTIN: "09506000134352", LotInputKey: "batchId", SerialStrategy: "process_id_hex"},
+	}
+	store := NewMemoryStore()
+	processID := store.SeedProcess(Process{WorkflowKey: "workflow", CreatedAt: now, Status: "active", Progress: map[string]ProcessStep{
+		"1_1": {State: "pending"},
+		"1_2": {State: "pending"},
+	}})
+	server := &Server{store: store, now: func() time.Time { return now }}
+	preview := func() DPPPreviewResponse {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/instance/"+processID.Hex()+"/dpp/preview.json", nil)
+		req = req.WithContext(context.WithValue(req.Context(), workflowContextKey{}, workflowContextValue{Key: "workflow", Cfg: cfg}))
+		rec := httptest.NewRecorder()
+		server.handleDPPPreview(rec, req, processID.Hex())
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d body = %s", rec.Code, rec.Body.String())
+		}
+		var response DPPPreviewResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("decode preview: %v", err)
+		}
+		return response
+	}
+
+	got := preview()
+	wantMissing := []DPPPreviewMissingInput{{Field: "lot", InputKey: "batchId", SubstepIDs: []string{"1.1"}}}
+	if got.Ready || got.DigitalLink != "" || got.Error != "missing dpp lot value" || !reflect.DeepEqual(got.Missing, wantMissing) {
+		t.Fatalf("pending preview = %#v", got)
+	}
+
+	description := ""
+	if err := store.UpdateProcessProgress(context.Background(), processID, "workflow", "1.1", ProcessStep{
+		State:       "done",
+		Description: &description,
+		Data:        map[string]interface{}{"batchId": "B-7"},
+	}); err != nil {
+		t.Fatalf("UpdateProcessProgress: %v", err)
+	}
+	got = preview()
+	if !got.Ready || got.Generated || got.Lot != "B-7" || got.Serial != processID.Hex() || len(got.Missing) != 0 {
+		t.Fatalf("ready preview = %#v", got)
+	}
+	if got.DigitalLink != digitalLinkURL("09506000134352", "B-7", processID.Hex()) {
+		t.Fatalf("digital link = %q", got.DigitalLink)
+	}
+	if snapshot, _ := store.SnapshotProcess(processID); snapshot.DPP != nil {
+		t.Fatalf("preview stored a dpp: %#v", snapshot.DPP)
+	}
+
+	if err := store.UpdateProcessDPP(context.Background(), processID, "workflow", ProcessDPP{GTIN: "09506000134352", Lot: "B-6", Serial: "S-1", GeneratedAt: now}); err != nil {
+		t.Fatalf("UpdateProcessDPP: %v", err)
+	}
+	if got = preview(); !got.Generated || got.Lot != "B-6" || got.Serial != "S-1" {
+		t.Fatalf("generated preview = %#v", got)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DPPProductInfo is the product and owner text a passport shows. In
+// multi-product workflows it comes from process data, see
+// resolveDPPProductInfo.
+type DPPProductInfo struct {
+	ProductName        string `json:"product_name,omitempty"`
+	ProductDescription string `json:"product_description,omitempty"`
+	OwnerName          string `json:"owner_name,omitempty"`
+}
+
+// resolveDPPProductInfo reads each *InputKey from the first completed substep
+// that provides it and falls back to the static dpp value otherwise.
+func resolveDPPProductInfo(def WorkflowDef, cfg DPPConfig, process *Process) DPPProductInfo {
+	resolve := func(key, fallback string) string {
+		if value := dppFirstStringValue(def, process, key); value != "" {
+			return value
+		}
+		return fallback
+	}
+	return DPPProductInfo{
+		ProductName:        resolve(cfg.ProductNameInputKey, cfg.ProductName),
+		ProductDescription: resolve(cfg.ProductDescriptionInputKey, cfg.ProductDescription),
+		OwnerName:          resolve(cfg.OwnerNameInputKey, cfg.OwnerName),
+	}
+}
+
+// processDPPProductInfo prefers the values stored with the DPP and resolves
+// them from the process for passports generated before they were stored.
+func processDPPProductInfo(def WorkflowDef, cfg DPPConfig, process *Process) DPPProductInfo {
+	if process != nil && process.DPP != nil {
+		stored := DPPProductInfo{
+			ProductName:        process.DPP.ProductName,
+			ProductDescription: process.DPP.ProductDescription,
+			OwnerName:          process.DPP.OwnerName,
+		}
+		if stored != (DPPProductInfo{}) {
+			return stored
+		}
+	}
+	return resolveDPPProductInfo(def, cfg, process)
+}
+
+// validateDPPProductInputKeys rejects product/owner input keys that no
+// substep of the workflow can provide.
+func validateDPPProductInputKeys(def WorkflowDef, cfg DPPConfig) error {
+	keys := []struct{ field, key string }{
+		{"productNameInputKey", cfg.ProductNameInputKey},
+		{"productDescriptionInputKey", cfg.ProductDescriptionInputKey},
+		{"ownerNameInputKey", cfg.OwnerNameInputKey},
+	}
+	for _, item := range keys {
+		if strings.TrimSpace(item.key) == "" {
+			continue
+		}
+		if len(dppInputSubsteps(def, item.key)) == 0 {
+			return fmt.Errorf("dpp.%s %q is not provided by any substep", item.field, item.key)
+		}
+	}
+	return nil
+}
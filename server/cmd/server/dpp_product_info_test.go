@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestBuildProcessDPPResolvesProductInfoFromData(t *testing.T) {
+	def := testRuntimeConfig().Workflow
+	description := ""
+	process := &Process{
+		ID: primitive.NewObjectID(),
+		Progress: map[string]ProcessStep{
+			"1.1": {State: "done", Description: &description, Data: map[string]interface{}{"value": "Olive oil 1L"}},
+			"1.2": {State: "done", Description: &description, Data: map[string]interface{}{"note": "LOT-1"}},
+		},
+	}
+	cfg := DPPConfig{
+		Enabled:             true,
+		GTIN:                "09506000134352",
+		LotInputKey:         "note",
+		SerialStrategy:      "process_id_hex",
+		ProductName:         "Generic product",
+		ProductNameInputKey: "value",
+		ProductDescription:  "Cold pressed",
+		OwnerName:           "Acme",
+		OwnerNameInputKey:   "missing",
+	}
+
+	dpp, err := buildProcessDPP(def, cfg, process, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("buildProcessDPP: %v", err)
+	}
+	if dpp.ProductName != "Olive oil 1L" || dpp.ProductDescription != "Cold pressed" || dpp.OwnerName != "Acme" {
+		t.Fatalf("product info = %#v", dpp)
+	}
+
+	process.Progress["1.1"] = ProcessStep{State: "pending"}
+	if got := resolveDPPProductInfo(def, cfg, process); got.ProductName != "Generic product" {
+		t.Fatalf("fallback product name = %q", got.ProductName)
+	}
+
+	process.DPP = &ProcessDPP{ProductName: "Stored name"}
+	if got := processDPPProductInfo(def, cfg, process); got != (DPPProductInfo{ProductName: "Stored name"}) {
+		t.Fatalf("stored product info = %#v", got)
+	}
+	process.DPP = &ProcessDPP{GTIN: cfg.GTIN}
+	if got := processDPPProductInfo(def, cfg, process); got.ProductName != "Generic product" || got.OwnerName != "Acme" {
+		t.Fatalf("legacy dpp product info = %#v", got)
+	}
+}
+
+func TestValidateWorkflowConfigRejectsUnknownDPPProductInputKey(t *testing.T) {
+	cfg := testRuntimeConfig()
+	cfg.DPP = DPPConfig{Enabled: true, GTIN: "09506000134352", ProductNameInputKey: " note "}
+	if err := validateWorkflowConfig(&cfg); err != nil {
+		t.Fatalf("validateWorkflowConfig: %v", err)
+	}
+	if cfg.DPP.ProductNameInputKey != "note" {
+		t.Fatalf("productNameInputKey = %q, want trimmed", cfg.DPP.ProductNameInputKey)
+	}
+
+	cfg = testRuntimeConfig()
+	cfg.DPP = DPPConfig{Enabled: true, GTIN: "09506000134352", OwnerNameInputKey: "producer"}
+	err := validateWorkflowConfig(&cfg)
+	if err == nil || !strings.Contains(err.Error(), "dpp.ownerNameInputKey") {
+		t.Fatalf("expected ownerNameInputKey error, got %v", err)
+	}
+}
+
+func TestHandleDigitalLinkDPPShowsProductInfo(t *testing.T) {
+	tempDir := t.TempDir()
+	writeWorkflowConfig(t, tempDir+"/workflow.yaml", "Demo workflow", "string")
+
+	store := NewMemoryStore()
+	process := seedDPPProcess(store)
+	process.DPP.ProductName = "Olive oil 1L"
+	process.DPP.OwnerName = "Acme Farms"
+	store.SeedProcess(process)
+	server := &Server{
+		store:     store,
+		tmpl:      parseTestTemplates(t),
+		configDir: tempDir,
+	}
+	link := digitalLinkURL(process.DPP.GTIN, process.DPP.Lot, process.DPP.Serial)
+
+	rr := httptest.NewRecorder()
+	server.handleDigitalLinkDPP(rr, httptest.NewRequest(http.MethodGet, link, nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	body := rr.Body.String()
+	if !strings.Contains(body, `<dd class="dpp-product-name">Olive oil 1L</dd>`) || !strings.Contains(body, `<dd class="dpp-product-owner">Acme Farms</dd>`) {
+		t.Fatalf("expected product and owner on the DPP page, got %q", body)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, link, nil)
+	req.Header.Set("Accept", "application/json")
+	rr = httptest.NewRecorder()
+	server.handleDigitalLinkDPP(rr, req)
+	var payload struct {
+		Product DPPProductInfo `json:"product"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode response JSON: %v", err)
+	}
+	if payload.Product != (DPPProductInfo{ProductName: "Olive oil 1L", OwnerName: "Acme Farms"}) {
+		t.Fatalf("json product = %#v", payload.Product)
+	}
+}
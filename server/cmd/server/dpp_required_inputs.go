@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrDPPInputMissing rejects the completion that would finish a process
+// while an input marked required by dpp.requireLot or
+// dpp.requireSerialInput is still missing.
+var ErrDPPInputMissing = errors.New("process: required dpp input missing")
+
+// missingRequiredDPPInputs names the required dpp inputs that no completed
+// substep of process provides.
+func missingRequiredDPPInputs(def WorkflowDef, cfg DPPConfig, process *Process) []string {
+	if !cfg.Enabled {
+		return nil
+	}
+	var missing []string
+	if cfg.RequireLot && dppFirstStringValue(def, process, cfg.LotInputKey) == "" {
+		missing = append(missing, fmt.Sprintf("lot (%s)", cfg.LotInputKey))
+	}
+	if cfg.RequireSerialInput && dppFirstStringValue(def, process, cfg.SerialInputKey) == "" {
+		missing = append(missing, fmt.Sprintf("serial (%s)", cfg.SerialInputKey))
+	}
+	return missing
+}
+
+// checkRequiredDPPInputs runs before a completion is stored. It applies
+// progress to a copy of process and fails only when that completion would
+// finish the process without the required dpp inputs, so earlier substeps
+// can still be completed in any allowed order.
+func checkRequiredDPPInputs(cfg RuntimeConfig, process *Process, substepID string, progress ProcessStep) error {
+	if process == nil || process.DPP != nil || !cfg.DPP.Enabled || (!cfg.DPP.RequireLot && !cfg.DPP.RequireSerialInput) {
+		return nil
+	}
+	projected := *process
+	projected.Progress = make(map[string]ProcessStep, len(process.Progress)+1)
+	for id, entry := range process.Progress {
+		projected.Progress[id] = entry
+	}
+	projected.Progress[substepID] = progress
+	if !isProcessComplete(cfg.Workflow, &projected) {
+		return nil
+	}
+	if missing := missingRequiredDPPInputs(cfg.Workflow, cfg.DPP, &projected); len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrDPPInputMissing, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// validateDPPRequiredInputKeys rejects a dpp.requireLot or
+// dpp.requireSerialInput whose input key no substep provides, or is provided
+// by a substep that visibleWhen can hide. Either would make the last
+// completion of every process fail with ErrDPPInputMissing.
+func validateDPPRequiredInputKeys(def WorkflowDef, cfg DPPConfig) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	required := []struct {
+		enabled    bool
+		field, key string
+	}{
+		{cfg.RequireLot, "lotInputKey", cfg.LotInputKey},
+		{cfg.RequireSerialInput, "serialInputKey", cfg.SerialInputKey},
+	}
+	for _, item := range required {
+		if !item.enabled {
+			continue
+		}
+		providers := dppInputSubsteps(def, item.key)
+		if len(providers) == 0 {
+			return fmt.Errorf("dpp.%s %q is required but not provided by any substep", item.field, item.key)
+		}
+		for _, substep := range providers {
+			if strings.TrimSpace(substep.VisibleWhen) != "" {
+				return fmt.Errorf("dpp.%s %q is required but substep %s providing it has visibleWhen", item.field, item.key, substep.SubstepID)
+			}
+		}
+	}
+	return nil
+}
+
+// dppInputMissingMessage is the user-facing text for an ErrDPPInputMissing.
+func dppInputMissingMessage(err error) string {
+	missing := strings.TrimPrefix(err.Error(), ErrDPPInputMissing.Error()+": ")
+	return "This step would finish the stream, but the Digital Product Passport still needs " + missing + "."
+}
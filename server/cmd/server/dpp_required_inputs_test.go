@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func requiredLotRuntimeConfig() RuntimeConfig {
+	schema := map[string]interface{}{"type": "object"}
+	return RuntimeConfig{
+		Workflow: WorkflowDef{
+			Name: "Lots",
+			Steps: []WorkflowStep{{
+				StepID: "1",
+				Title:  "Intake",
+				Order:  1,
+				Substep: []WorkflowSub{
+					{SubstepID: "1.1", Title: "Batch", Order: 1, Role: "dep1", InputKey: "batchId", InputType: "formata", Schema: schema},
+					{SubstepID: "1.2", Title: "Check", Order: 2, Role: "dep1", InputKey: "note", InputType: "formata", Schema: schema},
+				},
+			}},
+		},
+		DPP: DPPConfig{Enabled: true, GTIN: "09506000134352", LotInputKey: "batchId", LotDefault: "defaultProduct", SerialStrategy: "process_id_hex", RequireLot: true},
+	}
+}
+
+func TestCompleteSubstepRejectsFinishWithoutRequiredLot(t *testing.T) {
+	cfg := requiredLotRuntimeConfig()
+	now := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	svc := &ProcessService{store: store, now: func() time.Time { return now }}
+	processID := store.SeedProcess(Process{ID: primitive.NewObjectID(), WorkflowKey: "workflow", CreatedAt: now, Status: "active", Progress: map[string]ProcessStep{
+		"1_1": {State: "pending"},
+		"1_2": {State: "pending"},
+	}})
+	complete := func(substep WorkflowSub, payload map[string]interface{}) (*Process, error) {
+		t.Helper()
+		process, err := store.LoadProcessByID(t.Context(), processID)
+		if err != nil {
+			t.Fatalf("LoadProcessByID: %v", err)
+		}
+		process.Progress = normalizeProgressKeys(process.Progress)
+		return svc.CompleteSubstep(t.Context(), CompleteSubstepCmd{
+			Process:     process,
+			WorkflowKey: "workflow",
+			SubstepID:   substep.SubstepID,
+			Substep:     substep,
+			Actor:       Actor{ID: "user-1", Role: "dep1"},
+			Payload:     payload,
+			Config:      cfg,
+			Now:         now,
+		})
+	}
+
+	if _, err := complete(cfg.Workflow.Steps[0].Substep[0], map[string]interface{}{"batchId": ""}); err != nil {
+		t.Fatalf("first substep should not finish the process: %v", err)
+	}
+	_, err := complete(cfg.Workflow.Steps[0].Substep[1], map[string]interface{}{"note": "ok"})
+	if !errors.Is(err, ErrDPPInputMissing) || !payloadNotPersisted(err) {
+		t.Fatalf("expected ErrDPPInputMissing, got %v", err)
+	}
+	if got := dppInputMissingMessage(err); !strings.Contains(got, "lot (batchId)") {
+		t.Fatalf("message = %q", got)
+	}
+	snapshot, _ := store.SnapshotProcess(processID)
+	if snapshot.Progress["1_2"].State == "done" || snapshot.DPP != nil || snapshot.Status == processStatusDone {
+		t.Fatalf("rejected completion was stored: %#v", snapshot)
+	}
+	if notarizations, _ := store.ListNotarizations(t.Context(), processID); len(notarizations) != 1 {
+		t.Fatalf("notarizations = %d, want only the first substep", len(notarizations))
+	}
+
+	cfg.DPP.RequireLot = false
+	updated, err := complete(cfg.Workflow.Steps[0].Substep[1], map[string]interface{}{"note": "ok"})
+	if err != nil {
+		t.Fatalf("CompleteSubstep without requireLot: %v", err)
+	}
+	if updated.DPP == nil || updated.DPP.Lot != "defaultProduct" {
+		t.Fatalf("dpp = %#v, want lotDefault placeholder", updated.DPP)
+	}
+}
+
+func TestBuildProcessDPPRequiredInputsSkipPlaceholders(t *testing.T) {
+	cfg := requiredLotRuntimeConfig()
+	process := &Process{ID: primitive.NewObjectID(), Progress: map[string]ProcessStep{}}
+	if _, err := buildProcessDPP(cfg.Workflow, cfg.DPP, process, time.Time{}); err == nil || err.Error() != "missing dpp lot value" {
+		t.Fatalf("expected missing lot error, got %v", err)
+	}
+
+	description := ""
+	process.Progress["1.1"] = ProcessStep{State: "done", Description: &description, Data: map[string]interface{}{"batchId": "B-9"}}
+	cfg.DPP.SerialInputKey = "note"
+	cfg.DPP.RequireSerialInput = true
+	if _, err := buildProcessDPP(cfg.Workflow, cfg.DPP, process, time.Time{}); err == nil || err.Error() != "missing dpp serial value" {
+		t.Fatalf("expected missing serial error, got %v", err)
+	}
+	if missing := missingRequiredDPPInputs(cfg.Workflow, cfg.DPP, process); len(missing) != 1 || missing[0] != "serial (note)" {
+		t.Fatalf("missing = %v", missing)
+	}
+}
+
+func TestNormalizeDPPConfigRequireSerialInputNeedsKey(t *testing.T) {
+	cfg := DPPConfig{Enabled: true, GTIN: "09506000134352", RequireSerialInput: true}
+	if err := normalizeDPPConfig(&cfg); err == nil || !strings.Contains(err.Error(), "serialInputKey") {
+		t.Fatalf("expected serialInputKey error, got %v", err)
+	}
+	cfg.SerialInputKey = " serial "
+	if err := normalizeDPPConfig(&cfg); err != nil {
+		t.Fatalf("normalizeDPPConfig: %v", err)
+	}
+}
+
+func TestValidateDPPRequiredInputKeys(t *testing.T) {
+	cfg := requiredLotRuntimeConfig()
+	if err := validateDPPRequiredInputKeys(cfg.Workflow, cfg.DPP); err != nil {
+		t.Fatalf("valid config: %v", err)
+	}
+
+	misspelled := cfg.DPP
+	misspelled.LotInputKey = "batchID"
+	if err := validateDPPRequiredInputKeys(cfg.Workflow, misspelled); err == nil || !strings.Contains(err.Error(), `dpp.lotInputKey "batchID" is required but not provided`) {
+		t.Fatalf("misspelled lot key error = %v", err)
+	}
+
+	serial := cfg.DPP
+	serial.RequireLot = false
+	serial.RequireSerialInput = true
+	serial.SerialInputKey = "serial"
+	if err := validateDPPRequiredInputKeys(cfg.Workflow, serial); err == nil || !strings.Contains(err.Error(), `dpp.serialInputKey "serial"`) {
+		t.Fatalf("missing serial provider error = %v", err)
+	}
+
+	hidden := requiredLotRuntimeConfig()
+	hidden.Workflow.Steps[0].Substep[0].VisibleWhen = "note == yes"
+	if err := validateDPPRequiredInputKeys(hidden.Workflow, hidden.DPP); err == nil || !strings.Contains(err.Error(), "substep 1.1 providing it has visibleWhen") {
+		t.Fatalf("hideable provider error = %v", err)
+	}
+
+	disabled := misspelled
+	disabled.Enabled = false
+	if err := validateDPPRequiredInputKeys(cfg.Workflow, disabled); err != nil {
+		t.Fatalf("disabled dpp: %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var emailDomainEntryPattern = regexp.MustCompile(`^(\*\.)?[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)+$`)
+
+// normalizeEmailDomains lower-cases and de-duplicates an email domain
+// allowlist. An entry is either "example.com", which matches that domain
+// only, or "*.example.com", which matches any of its subdomains. A leading
+// "@" is tolerated.
+func normalizeEmailDomains(entries []string) ([]string, error) {
+	seen := map[string]bool{}
+	domains := []string{}
+	for _, entry := range entries {
+		entry = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(entry)), "@")
+		if entry == "" || seen[entry] {
+			continue
+		}
+		if !emailDomainEntryPattern.MatchString(entry) {
+			return nil, fmt.Errorf("invalid email domain %q", entry)
+		}
+		seen[entry] = true
+		domains = append(domains, entry)
+	}
+	return domains, nil
+}
+
+// globalAllowedEmailDomains reads ALLOWED_EMAIL_DOMAINS. Invalid entries
+// are skipped rather than failing every signup.
+func globalAllowedEmailDomains() []string {
+	var domains []string
+	for _, entry := range splitCSVEnv("ALLOWED_EMAIL_DOMAINS") {
+		if normalized, err := normalizeEmailDomains([]string{entry}); err == nil {
+			domains = append(domains, normalized...)
+		}
+	}
+	return domains
+}
+
+func emailDomain(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	return email[at+1:]
+}
+
+func emailDomainAllowed(email string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	domain := emailDomain(email)
+	if domain == "" {
+		return false
+	}
+	for _, entry := range allowed {
+		if parent, ok := strings.CutPrefix(entry, "*."); ok {
+			if strings.HasSuffix(domain, "."+parent) {
+				return true
+			}
+			continue
+		}
+		if domain == entry {
+			return true
+		}
+	}
+	return false
+}
+
+// emailDomainError rejects an email outside an allowlist; its message is
+// shown to the admin or the person signing up.
+type emailDomainError struct {
+	Domain  string
+	Allowed []string
+}
+
+func (e *emailDomainError) Error() string {
+	return fmt.Sprintf("email domain %q is not allowed; use an address at %s", e.Domain, strings.Join(e.Allowed, ", "))
+}
+
+func checkEmailDomain(email string, allowed []string) error {
+	if emailDomainAllowed(email, allowed) {
+		return nil
+	}
+	return &emailDomainError{Domain: emailDomain(email), Allowed: allowed}
+}
+
+// checkInviteEmailDomain applies ALLOWED_EMAIL_DOMAINS and then the
+// organization's own allowlist to an invite into org.
+func checkInviteEmailDomain(org IdentityOrg, email string) error {
+	if err := checkEmailDomain(email, globalAllowedEmailDomains()); err != nil {
+		return err
+	}
+	return checkEmailDomain(email, org.AllowedEmailDomains)
+}
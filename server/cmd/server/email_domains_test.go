@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNormalizeEmailDomains(t *testing.T) {
+	got, err := normalizeEmailDomains([]string{" Example.COM ", "@example.com", "", "*.Corp.example.org"})
+	if err != nil {
+		t.Fatalf("normalizeEmailDomains: %v", err)
+	}
+	if strings.Join(got, ",") != "example.com,*.corp.example.org" {
+		t.Fatalf("normalizeEmailDomains = %#v", got)
+	}
+	for _, invalid := range []string{"localhost", "exa mple.com", "*.com.", "a@b.com"} {
+		if _, err := normalizeEmailDomains([]string{invalid}); err == nil {
+			t.Fatalf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestEmailDomainAllowed(t *testing.T) {
+	allowed := []string{"example.com", "*.corp.example.org"}
+	cases := map[string]bool{
+		"user@example.com":            true,
+		"User@EXAMPLE.com":            true,
+		"user@sub.example.com":        false,
+		"user@eu.corp.example.org":    true,
+		"user@corp.example.org":       false,
+		"user@notcorp.example.org":    false,
+		"user@example.com.attacker.x": false,
+		"no-at-sign":                  false,
+	}
+	for email, want := range cases {
+		if got := emailDomainAllowed(email, allowed); got != want {
+			t.Fatalf("emailDomainAllowed(%q) = %v, want %v", email, got, want)
+		}
+	}
+	if !emailDomainAllowed("anyone@anywhere.test", nil) {
+		t.Fatal("empty allowlist must allow every email")
+	}
+}
+
+func TestCheckInviteEmailDomainAppliesGlobalAndOrgLists(t *testing.T) {
+	t.Setenv("ALLOWED_EMAIL_DOMAINS", "example.com, *.example.com")
+	org := IdentityOrg{Slug: "acme", AllowedEmailDomains: []string{"eu.example.com"}}
+
+	if err := checkInviteEmailDomain(org, "user@eu.example.com"); err != nil {
+		t.Fatalf("expected eu.example.com to pass both lists, got %v", err)
+	}
+	err := checkInviteEmailDomain(org, "user@example.com")
+	var domainErr *emailDomainError
+	if !errors.As(err, &domainErr) || domainErr.Domain != "example.com" {
+		t.Fatalf("expected org list to reject example.com, got %v", err)
+	}
+	if err := checkInviteEmailDomain(IdentityOrg{}, "user@other.test"); err == nil || !strings.Contains(err.Error(), "example.com, *.example.com") {
+		t.Fatalf("expected global list to reject other.test, got %v", err)
+	}
+}
+
+func TestHandleSignupRejectsEmailOutsideAllowedDomains(t *testing.T) {
+	t.Setenv("ANYONE_CAN_CREATE_ACCOUNT", "true")
+	t.Setenv("ALLOWED_EMAIL_DOMAINS", "example.com")
+	created := false
+	server := &Server{identity: &fakeIdentityStore{
+		createAccountFunc: func(ctx context.Context, email, password, name string) (IdentityUser, error) {
+			created = true
+			return IdentityUser{}, nil
+		},
+	}, tmpl: testTemplates(), now: time.Now}
+	req := httptest.NewRequest(http.MethodPost, "/signup", strings.NewReader("email=u1%40other.test&password=secure-password"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+
+	server.handleSignup(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if created || !strings.Contains(rec.Body.String(), "is not allowed") {
+		t.Fatalf("created=%v body=%q", created, rec.Body.String())
+	}
+}
+
+func TestHandleOrgAdminUsersInviteRejectsEmailOutsideOrgDomains(t *testing.T) {
+	now := time.Now().UTC()
+	inviteCalls := 0
+	server := &Server{
+		authorizer: fakeAuthorizer{},
+		store:      NewMemoryStore(),
+		identity: &fakeIdentityStore{
+			getSessionFunc: func(ctx context.Context, sessionSecret string) (IdentitySession, error) {
+				return fakeIdentitySession(sessionSecret, "user-1", now.Add(time.Hour)), nil
+			},
+			getCurrentUserFunc: func(ctx context.Context, sessionSecret string) (IdentityUser, error) {
+				return IdentityUser{ID: "user-1", Email: "owner@acme.test", OrgSlug: "acme", OrgName: "Acme Org", Labels: []string{identityOrgAdminLabel}, IsOrgAdmin: true, Status: "active"}, nil
+			},
+			getOrganizationBySlugFunc: func(ctx context.Context, slug string) (*IdentityOrg, error) {
+				return &IdentityOrg{ID: "team-1", Slug: "acme", Name: "Acme Org", Roles: []IdentityRole{{Slug: "approver", Name: "Approver"}}, AllowedEmailDomains: []string{"acme.test"}}, nil
+			},
+			listOrganizationMembershipsFunc: func(ctx context.Context, orgSlug string) ([]IdentityMembership, error) {
+				return nil, nil
+			},
+			getUserByEmailFunc: func(ctx context.Context, email string) (IdentityUser, error) {
+				return IdentityUser{}, ErrIdentityNotFound
+			},
+			inviteOrganizationUserFunc: func(ctx context.Context, sessionSecret, orgSlug, email, redirectURL string, roleSlugs []string, isOrgAdmin bool) (IdentityMembership, error) {
+				inviteCalls++
+				return IdentityMembership{ID: "membership-1", Email: email}, nil
+			},
+			listOrganizationUsersFunc: func(ctx context.Context, orgSlug string) ([]IdentityUser, error) {
+				return nil, nil
+			},
+		},
+		tmpl:        testTemplates(),
+		enforceAuth: true,
+		now:         func() time.Time { return now },
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/my/organization/users", strings.NewReader("intent=invite&email=new%40gmail.test&roles=approver"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(&http.Cookie{Name: "attesta_session", Value: "session-1"})
+	rec := httptest.NewRecorder()
+
+	server.handleOrgAdminUsers(rec, req)
+
+	if inviteCalls != 0 {
+		t.Fatalf("invite calls = %d, want 0", inviteCalls)
+	}
+	if !strings.Contains(rec.Body.String(), "is not allowed; use an address at acme.test") {
+		t.Fatalf("expected domain error, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHandleAdminOrgsCreateOrgSetsEmailDomainsBeforeBootstrapInvite(t *testing.T) {
+	t.Setenv("ADMIN_EMAIL", "admin@example.com")
+	t.Setenv("ADMIN_PASSWORD", "change-me")
+	now := time.Now().UTC()
+	created := 0
+	var savedDomains []string
+	inviteCalls := 0
+	server := &Server{
+		authorizer: fakeAuthorizer{},
+		store:      NewMemoryStore(),
+		identity: &fakeIdentityStore{
+			createEmailPasswordSessionFunc: func(ctx context.Context, email, password string) (IdentitySession, error) {
+				return fakeIdentitySession("platform-session", "user-1", now.Add(time.Hour)), nil
+			},
+			createOrganizationFunc: func(ctx context.Context, sessionSecret, name string) (IdentityOrg, error) {
+				created++
+				return IdentityOrg{ID: "team-1", Slug: "fresh-org", Name: name}, nil
+			},
+			updateOrganizationEmailDomainsFunc: func(ctx context.Context, sessionSecret, orgSlug string, domains []string) (IdentityOrg, error) {
+				if sessionSecret != "platform-session" || orgSlug != "fresh-org" {
+					t.Fatalf("update domains session=%q org=%q", sessionSecret, orgSlug)
+				}
+				savedDomains = domains
+				return IdentityOrg{ID: "team-1", Slug: orgSlug, AllowedEmailDomains: domains}, nil
+			},
+			getOrganizationBySlugFunc: func(ctx context.Context, slug string) (*IdentityOrg, error) {
+				return nil, ErrIdentityNotFound
+			},
+			listOrganizationsFunc: func(ctx context.Context) ([]IdentityOrg, error) {
+				return nil, nil
+			},
+			listOrganizationMembershipsFunc: func(ctx context.Context, orgSlug string) ([]IdentityMembership, error) {
+				return nil, nil
+			},
+			getUserByEmailFunc: func(ctx context.Context, email string) (IdentityUser, error) {
+				return IdentityUser{}, ErrIdentityNotFound
+			},
+			inviteOrganizationUserFunc: func(ctx context.Context, sessionSecret, orgSlug, email, redirectURL string, roleSlugs []string, isOrgAdmin bool) (IdentityMembership, error) {
+				inviteCalls++
+				return IdentityMembership{ID: "membership-1", Email: email, IsOrgAdmin: true}, nil
+			},
+			deleteSessionFunc: func(ctx context.Context, sessionSecret string) error { return nil },
+		},
+		tmpl:        testTemplates(),
+		enforceAuth: true,
+		now:         func() time.Time { return now },
+	}
+	createOrg := func(form string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "http://attesta.local/admin/orgs", strings.NewReader("intent=create_org&name=Fresh+Org&"+form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(&http.Cookie{Name: "attesta_session", Value: platformAdminSessionValue()})
+		rec := httptest.NewRecorder()
+		server.handleAdminOrgs(rec, req)
+		return rec
+	}
+
+	rec := createOrg("invite_email=owner%40gmail.test&allowed_email_domains=Acme.test%2C+*.acme.test")
+	if created != 0 || savedDomains != nil {
+		t.Fatalf("mismatched invite created=%d domains=%v, want nothing created", created, savedDomains)
+	}
+	if inviteCalls != 0 || !strings.Contains(rec.Body.String(), "is not allowed; use an address at acme.test, *.acme.test") {
+		t.Fatalf("invite calls=%d status=%d body=%q", inviteCalls, rec.Code, rec.Body.String())
+	}
+
+	if rec := createOrg("invite_email=owner%40acme.test&allowed_email_domains=acme.test"); rec.Code != http.StatusSeeOther || created != 1 || strings.Join(savedDomains, ",") != "acme.test" || inviteCalls != 1 {
+		t.Fatalf("matching invite status=%d created=%d domains=%v calls=%d", rec.Code, created, savedDomains, inviteCalls)
+	}
+
+	rec = createOrg("allowed_email_domains=not+a+domain")
+	if created != 1 || !strings.Contains(rec.Body.String(), "invalid email domain &#34;not a domain&#34;") {
+		t.Fatalf("invalid domain created=%d body=%q", created, rec.Body.String())
+	}
+
+	savedDomains = nil
+	if rec := createOrg(""); rec.Code != http.StatusSeeOther || created != 2 || savedDomains != nil {
+		t.Fatalf("no domains status=%d created=%d domains=%v", rec.Code, created, savedDomains)
+	}
+
+	identity := server.identity.(*fakeIdentityStore)
+	identity.updateOrganizationEmailDomainsFunc = func(ctx context.Context, sessionSecret, orgSlug string, domains []string) (IdentityOrg, error) {
+		return IdentityOrg{}, errors.New("appwrite down")
+	}
+	var deleted []string
+	identity.deleteOrganizationAsAdminFunc = func(ctx context.Context, orgSlug string) error {
+		deleted = append(deleted, orgSlug)
+		return nil
+	}
+	if rec := createOrg("allowed_email_domains=acme.test"); !strings.Contains(rec.Body.String(), "failed to update organization") || strings.Join(deleted, ",") != "fresh-org" {
+		t.Fatalf("failed domain update deleted=%v body=%q, want the new organization rolled back", deleted, rec.Body.String())
+	}
+}
@@ -99,6 +99,39 @@ func TestHandleEventsRoleStream(t *testing.T) {
 	}
 }
 
+func TestHandleEventsSendsHeartbeatWhileIdle(t *testing.T) {
+	server := &Server{
+		sse:          newSSEHub(),
+		sseHeartbeat: 5 * time.Millisecond,
+		configProvider: func() (RuntimeConfig, error) {
+			return testRuntimeConfig(), nil
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req := httptest.NewRequest(http.MethodGet, "/events?processId=p-1", nil).WithContext(ctx)
+	rr := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		server.handleEvents(rr, req)
+		close(done)
+	}()
+
+	waitForSSESubscriber(t, server.sse, "process:workflow:p-1")
+	time.Sleep(30 * time.Millisecond)
+	cancel()
+	waitForHandlerDone(t, done)
+
+	body := rr.Body.String()
+	if !strings.HasPrefix(body, ": keepalive\n\n") || strings.Contains(body, "event:") {
+		t.Fatalf("expected only keepalive comments, got %q", body)
+	}
+	if !rr.Flushed {
+		t.Fatal("expected heartbeat to be flushed")
+	}
+}
+
 func TestHandleEventsStreamingUnsupported(t *testing.T) {
 	server := &Server{
 		sse: newSSEHub(),
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MerkleProofStep is one sibling on the path from a leaf to the root.
+// Position says which side the sibling sits on when the pair is hashed.
+type MerkleProofStep struct {
+	Hash     string `json:"hash"`
+	Position string `json:"position"`
+}
+
+// MerkleProof lets a recipient check one substep against the Merkle root
+// without the rest of the export.
+type MerkleProof struct {
+	SubstepID string            `json:"substep_id"`
+	Leaf      string            `json:"leaf"`
+	Path      []MerkleProofStep `json:"path"`
+	Root      string            `json:"root"`
+}
+
+// BundleEntry describes one file in bundle.zip.
+type BundleEntry struct {
+	Name      string `json:"name"`
+	SizeBytes int64  `json:"size_bytes"`
+	SHA256    string `json:"sha256"`
+}
+
+// BundleMissingFile names an attachment the process references but the
+// bundle could not include, so an incomplete bundle is never mistaken for a
+// complete one.
+type BundleMissingFile struct {
+	Name         string `json:"name"`
+	AttachmentID string `json:"attachment_id"`
+}
+
+// BundleManifest is written last in bundle.zip and lists every other entry
+// with its digest. It is signed like notarized.json when a key is configured.
+type BundleManifest struct {
+	ProcessID    string              `json:"process_id"`
+	MerkleRoot   string              `json:"merkle_root"`
+	Entries      []BundleEntry       `json:"entries"`
+	MissingFiles []BundleMissingFile `json:"missing_files,omitempty"`
+	Signature    *NotarizedSignature `json:"signature,omitempty"`
+}
+
+// merkleProofs derives an inclusion proof for every leaf from the levels
+// buildMerkleTree recorded. An unpaired node is hashed with itself, so its
+// sibling is its own hash.
+func merkleProofs(tree MerkleTree) []MerkleProof {
+	proofs := make([]MerkleProof, 0, len(tree.Leaves))
+	for idx, leaf := range tree.Leaves {
+		proof := MerkleProof{SubstepID: leaf.SubstepID, Leaf: leaf.Hash, Path: []MerkleProofStep{}, Root: tree.Root}
+		pos := idx
+		for _, level := range tree.Levels {
+			if len(level) < 2 {
+				break
+			}
+			sibling := pos ^ 1
+			if sibling >= len(level) {
+				sibling = pos
+			}
+			position := "right"
+			if sibling < pos {
+				position = "left"
+			}
+			proof.Path = append(proof.Path, MerkleProofStep{Hash: level[sibling], Position: position})
+			pos /= 2
+		}
+		proofs = append(proofs, proof)
+	}
+	return proofs
+}
+
+// bundleWriter writes zip entries with a fixed timestamp and records their
+// size and digest for the manifest.
+type bundleWriter struct {
+	zip      *zip.Writer
+	modified time.Time
+	entries  []BundleEntry
+}
+
+func (b *bundleWriter) create(name string) (io.Writer, func(), error) {
+	entry, err := b.zip.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: b.modified})
+	if err != nil {
+		return nil, nil, err
+	}
+	digest := sha256.New()
+	counter := &countingWriter{}
+	done := func() {
+		b.entries = append(b.entries, BundleEntry{Name: name, SizeBytes: counter.n, SHA256: hex.EncodeToString(digest.Sum(nil))})
+	}
+	return io.MultiWriter(entry, digest, counter), done, nil
+}
+
+func (b *bundleWriter) writeBytes(name string, data []byte) error {
+	entry, done, err := b.create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := entry.Write(data); err != nil {
+		return err
+	}
+	done()
+	return nil
+}
+
+func (b *bundleWriter) writeJSON(name string, value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	return b.writeBytes(name, data)
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
+
+// handleProcessBundle serves bundle.zip: the signed notarized export, the
+// Merkle tree with one proof per substep, every attachment and a manifest of
+// digests. Entry names and timestamps depend only on the process, so the same
+// process state always yields the same entries.
+func (s *Server) handleProcessBundle(w http.ResponseWriter, r *http.Request, processID string) {
+	workflowKey, cfg, ok := s.selectedWorkflowOrRedirectHome(w, r)
+	if !ok {
+		return
+	}
+	process, err := s.loadProcess(r.Context(), processID)
+	if err != nil || !s.processBelongsToWorkflow(process, workflowKey) {
+		http.NotFound(w, r)
+		return
+	}
+	export, err := s.signNotarizedExport(buildNotarizedExport(cfg.Workflow, process))
+	if err != nil {
+		logAndHTTPError(w, r, http.StatusInternalServerError, "failed to sign export", err, "failed to sign notarized export for process %s", processID)
+		return
+	}
+
+	filename := fmt.Sprintf("process-%s-bundle.zip", process.ID.Hex())
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	zipWriter := zip.NewWriter(w)
+	defer zipWriter.Close()
+	bundle := &bundleWriter{zip: zipWriter, modified: processLastActivity(process).UTC()}
+
+	if err := bundle.writeJSON("notarized.json", export); err != nil {
+		logRequestError(r, err, "failed to write bundle for process %s", processID)
+		return
+	}
+	if export.Signature != nil {
+		if err := bundle.writeBytes("notarized.json.sig", []byte(export.Signature.Value+"\n")); err != nil {
+			logRequestError(r, err, "failed to write bundle for process %s", processID)
+			return
+		}
+	}
+	if err := bundle.writeJSON("merkle.json", export.Merkle); err != nil {
+		logRequestError(r, err, "failed to write bundle for process %s", processID)
+		return
+	}
+	for _, proof := range merkleProofs(export.Merkle) {
+		name := "proofs/" + strings.ReplaceAll(proof.SubstepID, ".", "_") + ".json"
+		if err := bundle.writeJSON(name, proof); err != nil {
+			logRequestError(r, err, "failed to write bundle for process %s", processID)
+			return
+		}
+	}
+
+	files := collectProcessAttachments(cfg.Workflow, process)
+	entryNames := attachmentZipEntryNames(files)
+	var missing []BundleMissingFile
+	for idx, file := range files {
+		name := "files/" + entryNames[idx]
+		attachmentID, err := primitive.ObjectIDFromHex(file.AttachmentID)
+		var download io.ReadCloser
+		if err == nil {
+			download, err = s.store.OpenAttachmentDownload(r.Context(), attachmentID)
+		}
+		if err != nil {
+			logRequestError(r, err, "attachment %s missing from bundle for process %s", file.AttachmentID, processID)
+			missing = append(missing, BundleMissingFile{Name: name, AttachmentID: file.AttachmentID})
+			continue
+		}
+		entry, done, err := bundle.create(name)
+		if err != nil {
+			download.Close()
+			logRequestError(r, err, "failed to write bundle for process %s", processID)
+			return
+		}
+		_, copyErr := io.Copy(entry, download)
+		download.Close()
+		if copyErr != nil {
+			logRequestError(r, copyErr, "failed to stream attachment %s into bundle", file.AttachmentID)
+			return
+		}
+		done()
+	}
+
+	manifest := BundleManifest{
+		ProcessID:    process.ID.Hex(),
+		MerkleRoot:   export.Merkle.Root,
+		Entries:      bundle.entries,
+		MissingFiles: missing,
+	}
+	if s.exportSigner != nil {
+		signature, err := s.exportSigner.signValue(manifest)
+		if err != nil {
+			logRequestError(r, err, "failed to sign bundle manifest for process %s", processID)
+			return
+		}
+		manifest.Signature = &signature
+	}
+	if err := bundle.writeJSON("manifest.json", manifest); err != nil {
+		logRequestError(r, err, "failed to write bundle for process %s", processID)
+	}
+}
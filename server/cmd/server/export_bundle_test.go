@@ -0,0 +1,247 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMerkleProofsVerifyAgainstRoot(t *testing.T) {
+	for _, count := range []int{1, 2, 3, 5, 8} {
+		var leaves []MerkleLeaf
+		for i := 0; i < count; i++ {
+			sum := sha256.Sum256([]byte{byte(i)})
+			leaves = append(leaves, MerkleLeaf{SubstepID: string(rune('a' + i)), Hash: hex.EncodeToString(sum[:])})
+		}
+		tree := buildMerkleTree(leaves)
+		proofs := merkleProofs(tree)
+		if len(proofs) != count {
+			t.Fatalf("count %d: proofs = %d", count, len(proofs))
+		}
+		for _, proof := range proofs {
+			current := proof.Leaf
+			for _, step := range proof.Path {
+				pair := current + step.Hash
+				if step.Position == "left" {
+					pair = step.Hash + current
+				}
+				sum := sha256.Sum256([]byte(pair))
+				current = hex.EncodeToString(sum[:])
+			}
+			if current != tree.Root {
+				t.Fatalf("count %d: proof for %s does not reach root", count, proof.SubstepID)
+			}
+		}
+	}
+}
+
+func TestHandleProcessBundle(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Date(2026, 2, 3, 9, 0, 0, 0, time.UTC)
+	processID := primitive.NewObjectID()
+
+	attachment, err := store.SaveAttachment(context.Background(), AttachmentUpload{
+		ProcessID:   processID,
+		SubstepID:   "1.3",
+		Filename:    "alpha.txt",
+		ContentType: "text/plain",
+		MaxBytes:    1 << 20,
+		UploadedAt:  now,
+	}, bytes.NewReader([]byte("hello world")))
+	if err != nil {
+		t.Fatalf("save attachment: %v", err)
+	}
+	store.SeedProcess(Process{
+		ID:        processID,
+		CreatedAt: now,
+		Status:    "active",
+		Progress: map[string]ProcessStep{
+			"1_3": {
+				State:  "done",
+				DoneAt: ptrTime(now.Add(-5 * time.Minute)),
+				Data: map[string]interface{}{
+					"attachment": map[string]interface{}{
+						"attachmentId": attachment.ID.Hex(),
+						"filename":     attachment.Filename,
+						"contentType":  attachment.ContentType,
+						"size":         attachment.SizeBytes,
+						"sha256":       attachment.SHA256,
+					},
+				},
+			},
+		},
+	})
+
+	server := &Server{
+		store: store,
+		configProvider: func() (RuntimeConfig, error) {
+			return testRuntimeConfig(), nil
+		},
+		exportSigner: &exportSigner{key: []byte("secret"), keyID: "k1"},
+		now:          func() time.Time { return now },
+	}
+
+	fetch := func() []byte {
+		req := httptest.NewRequest(http.MethodGet, "/process/"+processID.Hex()+"/bundle.zip", nil)
+		rec := httptest.NewRecorder()
+		server.handleProcessBundle(rec, req, processID.Hex())
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+		}
+		if got := rec.Header().Get("Content-Disposition"); got != `attachment; filename="process-`+processID.Hex()+`-bundle.zip"` {
+			t.Fatalf("content-disposition = %q", got)
+		}
+		return rec.Body.Bytes()
+	}
+	body := fetch()
+	if !bytes.Equal(body, fetch()) {
+		t.Fatalf("expected identical bundles for the same process state")
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatalf("read zip: %v", err)
+	}
+	contents := map[string][]byte{}
+	var names []string
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", file.Name, err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		contents[file.Name] = data
+		names = append(names, file.Name)
+	}
+	for _, name := range []string{"notarized.json", "notarized.json.sig", "merkle.json", "proofs/1_3.json", "files/1_3-alpha.txt", "manifest.json"} {
+		if _, ok := contents[name]; !ok {
+			t.Fatalf("missing entry %s in %v", name, names)
+		}
+	}
+	if names[len(names)-1] != "manifest.json" {
+		t.Fatalf("last entry = %q, want manifest.json", names[len(names)-1])
+	}
+	if string(contents["files/1_3-alpha.txt"]) != "hello world" {
+		t.Fatalf("attachment content = %q", contents["files/1_3-alpha.txt"])
+	}
+
+	var manifest BundleManifest
+	if err := json.Unmarshal(contents["manifest.json"], &manifest); err != nil {
+		t.Fatalf("decode manifest: %v", err)
+	}
+	if manifest.ProcessID != processID.Hex() || manifest.MerkleRoot == "" {
+		t.Fatalf("unexpected manifest header: %+v", manifest)
+	}
+	if len(manifest.MissingFiles) != 0 {
+		t.Fatalf("missing files = %+v, want none", manifest.MissingFiles)
+	}
+	if len(manifest.Entries) != len(names)-1 {
+		t.Fatalf("manifest entries = %d, want %d", len(manifest.Entries), len(names)-1)
+	}
+	for _, entry := range manifest.Entries {
+		sum := sha256.Sum256(contents[entry.Name])
+		if entry.SHA256 != hex.EncodeToString(sum[:]) || entry.SizeBytes != int64(len(contents[entry.Name])) {
+			t.Fatalf("manifest entry %s does not match zip content", entry.Name)
+		}
+	}
+	if manifest.Signature == nil {
+		t.Fatalf("expected signed manifest")
+	}
+	unsigned := manifest
+	unsigned.Signature = nil
+	want, err := server.exportSigner.signValue(unsigned)
+	if err != nil {
+		t.Fatalf("sign manifest: %v", err)
+	}
+	if manifest.Signature.Value != want.Value {
+		t.Fatalf("manifest signature does not verify")
+	}
+}
+
+func TestHandleProcessBundleListsMissingAttachments(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Date(2026, 2, 3, 9, 0, 0, 0, time.UTC)
+	processID := primitive.NewObjectID()
+	lostID := primitive.NewObjectID()
+	store.SeedProcess(Process{
+		ID:        processID,
+		CreatedAt: now,
+		Status:    "active",
+		Progress: map[string]ProcessStep{
+			"1_3": {
+				State:  "done",
+				DoneAt: ptrTime(now),
+				Data: map[string]interface{}{
+					"attachment": map[string]interface{}{
+						"attachmentId": lostID.Hex(),
+						"filename":     "alpha.txt",
+					},
+				},
+			},
+		},
+	})
+	server := &Server{
+		store: store,
+		configProvider: func() (RuntimeConfig, error) {
+			return testRuntimeConfig(), nil
+		},
+		now: func() time.Time { return now },
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/process/"+processID.Hex()+"/bundle.zip", nil)
+	rec := httptest.NewRecorder()
+	server.handleProcessBundle(rec, req, processID.Hex())
+	body := rec.Body.Bytes()
+	reader, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatalf("read zip: %v", err)
+	}
+	var manifest BundleManifest
+	for _, file := range reader.File {
+		if strings.HasPrefix(file.Name, "files/") {
+			t.Fatalf("unexpected entry %s for a missing attachment", file.Name)
+		}
+		if file.Name != "manifest.json" {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("open manifest: %v", err)
+		}
+		if err := json.NewDecoder(rc).Decode(&manifest); err != nil {
+			t.Fatalf("decode manifest: %v", err)
+		}
+		rc.Close()
+	}
+	if len(manifest.MissingFiles) != 1 || manifest.MissingFiles[0].AttachmentID != lostID.Hex() || manifest.MissingFiles[0].Name != "files/1_3-alpha.txt" {
+		t.Fatalf("missing files = %+v, want the lost attachment", manifest.MissingFiles)
+	}
+}
+
+func TestHandleProcessBundleNotFound(t *testing.T) {
+	server := &Server{
+		store: NewMemoryStore(),
+		configProvider: func() (RuntimeConfig, error) {
+			return testRuntimeConfig(), nil
+		},
+	}
+	processID := primitive.NewObjectID().Hex()
+	req := httptest.NewRequest(http.MethodGet, "/process/"+processID+"/bundle.zip", nil)
+	rec := httptest.NewRecorder()
+	server.handleProcessBundle(rec, req, processID)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -251,3 +251,39 @@ func TestApplyDoneByEmailFallsBackToOpaqueActorIDWhenEmailUnavailable(t *testing
 		t.Fatalf("mapped action doneBy = %q, want opaque appwrite actor id", mappedActions[0].DoneBy)
 	}
 }
+
+func TestCreatedByDisplay(t *testing.T) {
+	def := WorkflowDef{Steps: []WorkflowStep{{StepID: "1", OrganizationSlug: "acme"}}}
+	server := &Server{identity: &fakeIdentityStore{
+		getUserByIDFunc: func(ctx context.Context, userID string) (IdentityUser, error) {
+			if userID != "u-1" {
+				return IdentityUser{}, ErrIdentityNotFound
+			}
+			return IdentityUser{ID: "u-1", Email: "ana@example.com"}, nil
+		},
+	}}
+	cache := map[string]userIdentityView{}
+
+	for name, tc := range map[string]struct {
+		viewer    Actor
+		createdBy string
+		want      string
+	}{
+		"participant sees email":   {viewer: Actor{OrgSlug: "acme"}, createdBy: "appwrite:u-1", want: "ana@example.com"},
+		"outsider sees actor id":   {viewer: Actor{OrgSlug: "other"}, createdBy: "appwrite:u-1", want: "appwrite:u-1"},
+		"unknown user keeps id":    {viewer: Actor{OrgSlug: "acme"}, createdBy: "appwrite:gone", want: "appwrite:gone"},
+		"legacy creator is hidden": {viewer: Actor{OrgSlug: "acme"}, createdBy: legacyProcessCreator, want: ""},
+	} {
+		if got := server.createdByDisplay(t.Context(), def, tc.viewer, tc.createdBy, cache); got != tc.want {
+			t.Fatalf("%s: createdByDisplay = %q, want %q", name, got, tc.want)
+		}
+	}
+
+	export := buildNotarizedExport(testRuntimeConfig().Workflow, &Process{CreatedBy: "appwrite:u-1", Progress: map[string]ProcessStep{}})
+	if export.CreatedBy != "appwrite:u-1" {
+		t.Fatalf("export created_by = %q, want appwrite:u-1", export.CreatedBy)
+	}
+	if export := buildNotarizedExport(testRuntimeConfig().Workflow, &Process{CreatedBy: legacyProcessCreator}); export.CreatedBy != "" {
+		t.Fatalf("expected legacy creator to be omitted from export, got %q", export.CreatedBy)
+	}
+}
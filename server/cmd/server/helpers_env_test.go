@@ -1,6 +1,9 @@
 package main
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestBoolEnvOrAndSessionTTLDays(t *testing.T) {
 	t.Run("bool env parsing", func(t *testing.T) {
@@ -28,6 +31,21 @@ func TestBoolEnvOrAndSessionTTLDays(t *testing.T) {
 	})
 }
 
+func TestSSEHeartbeatInterval(t *testing.T) {
+	t.Setenv("SSE_HEARTBEAT_SECONDS", "")
+	if got := sseHeartbeatInterval(); got != sseHeartbeatDefault {
+		t.Fatalf("default interval = %s", got)
+	}
+	t.Setenv("SSE_HEARTBEAT_SECONDS", "45")
+	if got := sseHeartbeatInterval(); got != 45*time.Second {
+		t.Fatalf("interval = %s, want 45s", got)
+	}
+	t.Setenv("SSE_HEARTBEAT_SECONDS", "0")
+	if got := sseHeartbeatInterval(); got != sseHeartbeatDefault {
+		t.Fatalf("non-positive interval = %s, want default", got)
+	}
+}
+
 func TestAttachmentAndCompletionFormMaxBytes(t *testing.T) {
 	t.Run("defaults", func(t *testing.T) {
 		t.Setenv("ATTACHMENT_MAX_BYTES", "")
@@ -35,6 +35,16 @@ func TestSortHomeProcessList(t *testing.T) {
 			},
 			wantIDs: []string{"a", "c", "b"},
 		},
+		{
+			name:    "activity desc with tie by recent creation",
+			sortKey: "activity_desc",
+			items: []StreamInstanceCard{
+				{ID: "a", CreatedAtTime: base.Add(2 * time.Hour), UpdatedAtTime: base.Add(2 * time.Hour)},
+				{ID: "b", CreatedAtTime: base.Add(-1 * time.Hour), UpdatedAtTime: base.Add(5 * time.Hour)},
+				{ID: "c", CreatedAtTime: base.Add(3 * time.Hour), UpdatedAtTime: base.Add(2 * time.Hour)},
+			},
+			wantIDs: []string{"b", "c", "a"},
+		},
 		{
 			name:    "progress asc with tie by recent time",
 			sortKey: "progress_asc",
@@ -102,7 +112,7 @@ func TestSortHomeProcessList(t *testing.T) {
 
 func TestRoleMetaIndexUnavailableUsesFallback(t *testing.T) {
 	got := roleMetaForOrg("org1", "dep1", (&Server{}).roleMetaIndex(context.Background()), nil)
-	if got.Palette != "fallback" {
-		t.Fatalf("palette = %q, want fallback", got.Palette)
+	if want := autoRolePalette("dep1"); got.Palette != want {
+		t.Fatalf("palette = %q, want %q", got.Palette, want)
 	}
 }
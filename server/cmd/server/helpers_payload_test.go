@@ -3,6 +3,9 @@ package main
 import (
 	"encoding/json"
 	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func TestNormalizePayload(t *testing.T) {
@@ -43,6 +46,50 @@ func TestDigestPayloadStability(t *testing.T) {
 	}
 }
 
+func TestDigestPayloadSurvivesMongoRoundTrip(t *testing.T) {
+	var payload map[string]interface{}
+	if err := json.Unmarshal([]byte(`{"z":"<last>","lot":{"weight":42,"origin":{"country":"IT","farm":"Nord"}},"tags":[{"b":1,"a":2.5},"x"],"count":3}`), &payload); err != nil {
+		t.Fatalf("unmarshal payload: %v", err)
+	}
+	original := Process{Progress: map[string]ProcessStep{"1_1": {State: "done", Data: payload}}}
+	entry := NotarizedSubstep{Status: "done", Payload: payload}
+	notarizedDigest := digestPayload(payload)
+	notarizedLeaf := hashMerkleLeaf("1.1", entry)
+
+	raw, err := bson.Marshal(original)
+	if err != nil {
+		t.Fatalf("bson marshal: %v", err)
+	}
+	var loaded Process
+	if err := bson.Unmarshal(raw, &loaded); err != nil {
+		t.Fatalf("bson unmarshal: %v", err)
+	}
+	reloaded := loaded.Progress["1_1"].Data
+	if got := digestPayload(reloaded); got != notarizedDigest {
+		t.Fatalf("digest after reload = %q, want %q", got, notarizedDigest)
+	}
+	entry.Payload = reloaded
+	if got := hashMerkleLeaf("1.1", entry); got != notarizedLeaf {
+		t.Fatalf("merkle leaf after reload = %q, want %q", got, notarizedLeaf)
+	}
+}
+
+func TestCanonicalValueKeepsDecodedPayloads(t *testing.T) {
+	payload := map[string]interface{}{"b": []interface{}{1.5, "a&b"}, "a": map[string]interface{}{"n": 10.0}}
+	plain, _ := json.Marshal(payload)
+	canonical, _ := json.Marshal(canonicalValue(payload))
+	if string(canonical) != string(plain) {
+		t.Fatalf("canonical encoding = %s, want %s so existing digests stay valid", canonical, plain)
+	}
+	if ints, _ := json.Marshal(canonicalValue(map[string]interface{}{"n": int32(10), "big": int64(1 << 60)})); string(ints) != `{"big":1152921504606846976,"n":10}` {
+		t.Fatalf("canonical numbers = %s", ints)
+	}
+	driverShaped := map[string]interface{}{"lot": primitive.D{{Key: "weight", Value: int32(42)}, {Key: "farm", Value: "Nord"}}, "tags": primitive.A{"x"}}
+	if got, want := digestPayload(driverShaped), digestPayload(map[string]interface{}{"lot": map[string]interface{}{"farm": "Nord", "weight": 42.0}, "tags": []interface{}{"x"}}); got != want {
+		t.Fatalf("digest of driver-shaped payload = %q, want %q", got, want)
+	}
+}
+
 type fixedStringer string
 
 func (s fixedStringer) String() string {
@@ -58,7 +58,7 @@ func TestLogRequests(t *testing.T) {
 		log.SetPrefix(oldPrefix)
 	})
 
-	handler := logRequests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	handler := (&Server{}).logRequests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusCreated)
 		_, _ = w.Write([]byte("ok"))
 	}))
@@ -56,3 +56,44 @@ func TestSSEHubBroadcastDoesNotBlockOnFullBuffer(t *testing.T) {
 		t.Fatal("expected broadcast to drop overflow message without blocking")
 	}
 }
+
+func TestSSEHubBroadcastManyReachesEverySubscribedKey(t *testing.T) {
+	hub := newSSEHub()
+	hub.BroadcastMany([]string{"role:w:a"}, "role-updated")
+
+	a := hub.Subscribe("role:w:a")
+	b := hub.Subscribe("role:w:b")
+	other := hub.Subscribe("role:w:c")
+	t.Cleanup(func() {
+		hub.Unsubscribe("role:w:a", a)
+		hub.Unsubscribe("role:w:b", b)
+		hub.Unsubscribe("role:w:c", other)
+	})
+
+	hub.BroadcastMany([]string{"role:w:a", "role:w:b", "role:w:empty"}, "role-updated")
+
+	for name, ch := range map[string]chan string{"a": a, "b": b} {
+		select {
+		case got := <-ch:
+			if got != "role-updated" {
+				t.Fatalf("subscriber %s got %q", name, got)
+			}
+		default:
+			t.Fatalf("expected message on subscriber %s", name)
+		}
+	}
+	select {
+	case got := <-other:
+		t.Fatalf("unlisted key got %q", got)
+	default:
+	}
+}
+
+func TestSSEHubUnsubscribeDropsEmptyKeys(t *testing.T) {
+	hub := newSSEHub()
+	ch := hub.Subscribe("role:w:a")
+	hub.Unsubscribe("role:w:a", ch)
+	if len(hub.stream) != 0 {
+		t.Fatalf("expected no tracked keys, got %v", hub.stream)
+	}
+}
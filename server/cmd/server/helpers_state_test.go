@@ -29,6 +29,37 @@ func TestComputeAvailability(t *testing.T) {
 	}
 }
 
+func TestComputeAvailabilityWithDependsOn(t *testing.T) {
+	def := testRuntimeConfig().Workflow
+	for stepIdx := range def.Steps {
+		for subIdx := range def.Steps[stepIdx].Substep {
+			sub := &def.Steps[stepIdx].Substep[subIdx]
+			switch sub.SubstepID {
+			case "2.1":
+				sub.DependsOn = []string{"1.1"}
+			case "3.1":
+				sub.DependsOn = []string{"1.2", "2.1"}
+			}
+		}
+	}
+
+	availability := computeAvailability(def, processWithDone("1.1"))
+	for id, want := range map[string]bool{"1.1": false, "1.2": true, "1.3": false, "2.1": true, "2.2": false, "3.1": false} {
+		if got := availability[id]; got != want {
+			t.Fatalf("availability[%s]=%t, want %t", id, got, want)
+		}
+	}
+	if isSequenceOK(def, processWithDone("1.1", "1.2"), "3.1") {
+		t.Fatal("expected 3.1 to wait for 2.1")
+	}
+	if !isSequenceOK(def, processWithDone("1.1", "1.2", "2.1"), "3.1") {
+		t.Fatal("expected 3.1 to be sequence-OK once its dependencies are done")
+	}
+	if isSequenceOK(def, processWithDone("1.1", "2.1"), "2.2") {
+		t.Fatal("expected 2.2 without dependsOn to keep linear order")
+	}
+}
+
 func TestSequenceAndDoneHelpers(t *testing.T) {
 	def := testRuntimeConfig().Workflow
 
@@ -45,10 +76,10 @@ func TestSequenceAndDoneHelpers(t *testing.T) {
 		t.Fatal("expected nil process to fail sequence check after first substep")
 	}
 
-	if isProcessDone(def, processWithDone("1.1")) {
+	if isProcessComplete(def, processWithDone("1.1")) {
 		t.Fatal("expected partially done process to be incomplete")
 	}
-	if !isProcessDone(def, processWithDone("1.1", "1.2", "1.3", "2.1", "2.2", "3.1", "3.2")) {
+	if !isProcessComplete(def, processWithDone("1.1", "1.2", "1.3", "2.1", "2.2", "3.1", "3.2")) {
 		t.Fatal("expected all done process to be complete")
 	}
 }
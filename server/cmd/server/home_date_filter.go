@@ -0,0 +1,115 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+	"time"
+)
+
+// homeDateRange restricts the stream home list to processes created inside
+// [From, To]. Either bound may be zero; FromValue and ToValue keep the raw
+// query values so links and the date inputs round-trip them unchanged.
+type homeDateRange struct {
+	From      time.Time
+	To        time.Time
+	FromValue string
+	ToValue   string
+}
+
+// parseHomeDateBound accepts an RFC3339 timestamp or a plain 2006-01-02 date.
+// A plain date used as the upper bound covers the whole day.
+func parseHomeDateBound(raw string, upper bool) (time.Time, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, false
+	}
+	if parsed, err := time.Parse(time.RFC3339, raw); err == nil {
+		return parsed.UTC(), true
+	}
+	parsed, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if upper {
+		parsed = parsed.Add(24*time.Hour - time.Nanosecond)
+	}
+	return parsed, true
+}
+
+// parseHomeDateRange reads ?from= and ?to=. Values that do not parse are
+// dropped rather than rejected, like the other home list parameters.
+func parseHomeDateRange(query url.Values) homeDateRange {
+	var dateRange homeDateRange
+	if from, ok := parseHomeDateBound(query.Get("from"), false); ok {
+		dateRange.From = from
+		dateRange.FromValue = strings.TrimSpace(query.Get("from"))
+	}
+	if to, ok := parseHomeDateBound(query.Get("to"), true); ok {
+		dateRange.To = to
+		dateRange.ToValue = strings.TrimSpace(query.Get("to"))
+	}
+	return dateRange
+}
+
+// homeDateInputValue trims an RFC3339 bound to the date shown by the
+// type=date inputs.
+func homeDateInputValue(raw string) string {
+	if len(raw) > len("2006-01-02") {
+		return raw[:len("2006-01-02")]
+	}
+	return raw
+}
+
+func (d homeDateRange) IsZero() bool {
+	return d.FromValue == "" && d.ToValue == ""
+}
+
+func (d homeDateRange) contains(t time.Time) bool {
+	if !d.From.IsZero() && t.Before(d.From) {
+		return false
+	}
+	if !d.To.IsZero() && t.After(d.To) {
+		return false
+	}
+	return true
+}
+
+// fields returns the range as hidden inputs for the filter and sort forms.
+func (d homeDateRange) fields() []QueryInput {
+	var fields []QueryInput
+	if d.FromValue != "" {
+		fields = append(fields, QueryInput{Name: "from", Value: d.FromValue})
+	}
+	if d.ToValue != "" {
+		fields = append(fields, QueryInput{Name: "to", Value: d.ToValue})
+	}
+	return fields
+}
+
+// withHomeDateRange adds the range to a homePaginationURL link.
+func withHomeDateRange(target string, d homeDateRange) string {
+	if d.IsZero() {
+		return target
+	}
+	path, rawQuery, _ := strings.Cut(target, "?")
+	values, _ := url.ParseQuery(rawQuery)
+	for _, field := range d.fields() {
+		values.Set(field.Name, field.Value)
+	}
+	return path + "?" + values.Encode()
+}
+
+// applyHomeDateRange carries the range into every link and form of the
+// active group so paging and sorting keep the filter.
+func applyHomeDateRange(group ProcessStatusGroup, d homeDateRange) ProcessStatusGroup {
+	if d.IsZero() {
+		return group
+	}
+	group.SortFields = append(group.SortFields, d.fields()...)
+	for idx := range group.PageLinks {
+		group.PageLinks[idx].URL = withHomeDateRange(group.PageLinks[idx].URL, d)
+	}
+	group.PreviousURL = withHomeDateRange(group.PreviousURL, d)
+	group.NextURL = withHomeDateRange(group.NextURL, d)
+	return group
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseHomeDateRange(t *testing.T) {
+	dateRange := parseHomeDateRange(url.Values{"from": {"2026-02-01"}, "to": {"2026-02-02"}})
+	if !dateRange.From.Equal(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("from = %s", dateRange.From)
+	}
+	if !dateRange.contains(time.Date(2026, 2, 2, 23, 59, 0, 0, time.UTC)) || dateRange.contains(time.Date(2026, 2, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("date-only to should cover the whole day: %#v", dateRange)
+	}
+
+	dateRange = parseHomeDateRange(url.Values{"from": {"2026-02-01T10:00:00+01:00"}, "to": {"yesterday"}})
+	if !dateRange.From.Equal(time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC)) || !dateRange.To.IsZero() || dateRange.ToValue != "" {
+		t.Fatalf("range = %#v", dateRange)
+	}
+	if got := homeDateInputValue(dateRange.FromValue); got != "2026-02-01" {
+		t.Fatalf("input value = %q", got)
+	}
+	if got := withHomeDateRange("/my/streams/workflow/?filter=done", dateRange); got != "/my/streams/workflow/?filter=done&from=2026-02-01T10%3A00%3A00%2B01%3A00" {
+		t.Fatalf("url = %q", got)
+	}
+	if got := withHomeDateRange("/my/streams/workflow/", homeDateRange{}); got != "/my/streams/workflow/" {
+		t.Fatalf("url without range = %q", got)
+	}
+}
+
+func TestBuildWorkflowHomeViewFiltersByStatusAndDate(t *testing.T) {
+	now := time.Date(2026, 2, 3, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	seed := func(createdAt time.Time, done bool) primitive.ObjectID {
+		progress := map[string]ProcessStep{}
+		if done {
+			for _, substep := range orderedSubsteps(testRuntimeConfig().Workflow) {
+				progress[substep.SubstepID] = ProcessStep{State: "done", DoneAt: ptrTime(createdAt.Add(time.Minute))}
+			}
+		}
+		return store.SeedProcess(Process{WorkflowKey: "workflow", CreatedAt: createdAt, Status: "active", Progress: progress})
+	}
+	seed(now.AddDate(0, 0, -10), true)
+	recentDone := seed(now.AddDate(0, 0, -1), true)
+	seed(now.AddDate(0, 0, -1), false)
+	seed(now, false)
+
+	server := &Server{authorizer: fakeAuthorizer{}, store: store, tmpl: testTemplates()}
+	cfg := testRuntimeConfig()
+	req := httptest.NewRequest(http.MethodGet, "/my/streams/workflow/?status=done&from=2026-02-01&to=2026-02-02&sort=time_asc", nil)
+	view := server.buildWorkflowHomeView(context.Background(), req, nil, "workflow", cfg, "")
+
+	if view.StatusFilter != processStatusDone || view.DateFrom != "2026-02-01" || view.DateTo != "2026-02-02" {
+		t.Fatalf("view filter = %q from = %q to = %q", view.StatusFilter, view.DateFrom, view.DateTo)
+	}
+	counts := map[string]int{}
+	for _, option := range view.FilterOptions {
+		counts[option.Status] = option.TotalCount
+	}
+	if counts["all"] != 2 || counts[processStatusDone] != 1 {
+		t.Fatalf("filter counts = %#v", counts)
+	}
+	group := view.ProcessGroups[0]
+	if len(group.Processes) != 1 || group.Processes[0].ID != recentDone.Hex() {
+		t.Fatalf("processes = %#v", group.Processes)
+	}
+	wantFields := []QueryInput{{Name: "filter", Value: "done"}, {Name: "from", Value: "2026-02-01"}, {Name: "to", Value: "2026-02-02"}}
+	if !reflect.DeepEqual(group.SortFields, wantFields) {
+		t.Fatalf("sort fields = %#v", group.SortFields)
+	}
+	if want := "/my/streams/workflow/?filter=done&from=2026-02-01&sort=time_asc&to=2026-02-02"; group.PageLinks[0].URL != want || group.NextURL != want {
+		t.Fatalf("page url = %q next = %q", group.PageLinks[0].URL, group.NextURL)
+	}
+	if want := "/my/streams/workflow/?from=2026-02-01&sort=time_asc&to=2026-02-02"; view.FilterOptions[0].URL != want {
+		t.Fatalf("all option url = %q", view.FilterOptions[0].URL)
+	}
+	if want := "/my/streams/workflow/?filter=done&sort=time_asc"; view.DateClearURL != want {
+		t.Fatalf("clear url = %q", view.DateClearURL)
+	}
+
+	rec := httptest.NewRecorder()
+	server.tmpl = parseTestTemplates(t)
+	server.renderStreamDashboardResults(rec, view)
+	body := rec.Body.String()
+	for _, want := range []string{`name="from" value="2026-02-01"`, `<input type="hidden" name="to" value="2026-02-02" />`, `href="` + template.HTMLEscapeString(view.FilterOptions[0].URL) + `"`} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %s in dashboard results, got %q", want, body)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"html/template"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -478,6 +479,60 @@ func TestHandleHomePickerMarksWorkflowCardsWithMyTurn(t *testing.T) {
 	}
 }
 
+func TestHandleHomeRedirectsToOnlyEnabledWorkflow(t *testing.T) {
+	tempDir := t.TempDir()
+	writeWorkflowConfig(t, filepath.Join(tempDir, "workflow.yaml"), "Main workflow", "string")
+	user := AccountUser{ID: primitive.NewObjectID(), Email: "member@example.com", OrgSlug: "org1", RoleSlugs: []string{"dep1"}, Status: "active"}
+	server := &Server{
+		authorizer:                 fakeAuthorizer{},
+		store:                      NewMemoryStore(),
+		identity:                   testIdentityForSessions(time.Now().UTC(), map[string]AccountUser{"session-home": user}),
+		tmpl:                       homePickerTemplates(),
+		configDir:                  tempDir,
+		enforceAuth:                true,
+		homeSingleWorkflowRedirect: true,
+	}
+	home := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.AddCookie(&http.Cookie{Name: "attesta_session", Value: "session-home"})
+		rec := httptest.NewRecorder()
+		server.handleHome(rec, req)
+		return rec
+	}
+
+	if rec := home("/my"); rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != streamPath("workflow") {
+		t.Fatalf("single workflow: status = %d location = %q", rec.Code, rec.Header().Get("Location"))
+	}
+	if rec := home("/my?confirmation=Stream+deleted"); rec.Code != http.StatusOK {
+		t.Fatalf("flash message: status = %d, want picker", rec.Code)
+	}
+
+	disabledPath := filepath.Join(tempDir, "retired.yaml")
+	writeWorkflowConfig(t, disabledPath, "Retired workflow", "string")
+	data, err := os.ReadFile(disabledPath)
+	if err != nil {
+		t.Fatalf("read retired: %v", err)
+	}
+	if err := os.WriteFile(disabledPath, []byte(strings.Replace(string(data), "workflow:\n", "workflow:\n  enabled: false\n", 1)), 0o644); err != nil {
+		t.Fatalf("write retired: %v", err)
+	}
+	if rec := home("/my"); rec.Code != http.StatusSeeOther {
+		t.Fatalf("one enabled of two: status = %d, want redirect", rec.Code)
+	}
+
+	writeWorkflowConfig(t, filepath.Join(tempDir, "second.yaml"), "Second workflow", "string")
+	if rec := home("/my"); rec.Code != http.StatusOK {
+		t.Fatalf("two enabled workflows: status = %d, want picker", rec.Code)
+	}
+	if err := os.Remove(filepath.Join(tempDir, "second.yaml")); err != nil {
+		t.Fatalf("remove second: %v", err)
+	}
+	server.homeSingleWorkflowRedirect = false
+	if rec := home("/my"); rec.Code != http.StatusOK {
+		t.Fatalf("redirect disabled: status = %d, want picker", rec.Code)
+	}
+}
+
 func TestHandleHomePickerRendersTurnIndicatorOnWorkflowCard(t *testing.T) {
 	tempDir := t.TempDir()
 	writeWorkflowConfig(t, filepath.Join(tempDir, "workflow.yaml"), "Main workflow", "string", "Main workflow description")
@@ -716,51 +771,64 @@ func TestHandleWorkflowHomeRendersValidationState(t *testing.T) {
 		},
 	}
 
-	server := &Server{
-		authorizer: fakeAuthorizer{},
-		store:      NewMemoryStore(),
-		tmpl:       tmpl,
-		identity: &fakeIdentityStore{
-			getSessionFunc: func(ctx context.Context, sessionSecret string) (IdentitySession, error) {
-				return IdentitySession{Secret: sessionSecret, ExpiresAt: time.Now().UTC().Add(time.Hour)}, nil
-			},
-			getCurrentUserFunc: func(ctx context.Context, sessionSecret string) (IdentityUser, error) {
-				return IdentityUser{ID: "user-1", Email: "user@example.com"}, nil
-			},
-			listOrganizationsFunc: func(ctx context.Context) ([]IdentityOrg, error) {
-				return nil, nil
+	render := func(identityUser IdentityUser) string {
+		server := &Server{
+			authorizer: fakeAuthorizer{},
+			store:      NewMemoryStore(),
+			tmpl:       tmpl,
+			identity: &fakeIdentityStore{
+				getSessionFunc: func(ctx context.Context, sessionSecret string) (IdentitySession, error) {
+					return IdentitySession{Secret: sessionSecret, ExpiresAt: time.Now().UTC().Add(time.Hour)}, nil
+				},
+				getCurrentUserFunc: func(ctx context.Context, sessionSecret string) (IdentityUser, error) {
+					return identityUser, nil
+				},
+				listOrganizationsFunc: func(ctx context.Context) ([]IdentityOrg, error) {
+					return []IdentityOrg{{Slug: "org1", Name: "Organization 1"}}, nil
+				},
 			},
-		},
-		enforceAuth: true,
-	}
+			enforceAuth: true,
+		}
 
-	req := httptest.NewRequest(http.MethodGet, "/my/streams/workflow/", nil)
-	req.AddCookie(&http.Cookie{Name: "attesta_session", Value: "session-1"})
-	req = req.WithContext(context.WithValue(req.Context(), workflowContextKey{}, workflowContextValue{
-		Key: "workflow",
-		Cfg: cfg,
-	}))
-	rec := httptest.NewRecorder()
-	server.handleWorkflowHome(rec, req)
+		req := httptest.NewRequest(http.MethodGet, "/my/streams/workflow/", nil)
+		req.AddCookie(&http.Cookie{Name: "attesta_session", Value: "session-1"})
+		req = req.WithContext(context.WithValue(req.Context(), workflowContextKey{}, workflowContextValue{
+			Key: "workflow",
+			Cfg: cfg,
+		}))
+		rec := httptest.NewRecorder()
+		server.handleWorkflowHome(rec, req)
 
-	if rec.Code != http.StatusOK {
-		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+		}
+		body := rec.Body.String()
+		if !strings.Contains(body, `class="error`) {
+			t.Fatalf("expected error banner, got %q", body)
+		}
+		if strings.Contains(body, `class="rail-layout`) || strings.Contains(body, `class="home-workflow-grid"`) {
+			t.Fatalf("did not expect stream dashboard grid when config is invalid, got %q", body)
+		}
+		if strings.Contains(body, `action="/my/streams/workflow/instance/start"`) || strings.Contains(body, "New instance") {
+			t.Fatalf("did not expect new instance controls when config is invalid, got %q", body)
+		}
+		return body
 	}
-	body := rec.Body.String()
-	if !strings.Contains(body, `class="error`) {
-		t.Fatalf("expected error banner, got %q", body)
+
+	body := render(IdentityUser{ID: "user-1", Email: "user@example.com"})
+	if !strings.Contains(body, workflowUnavailableMessage) {
+		t.Fatalf("expected generic unavailable message, got %q", body)
+	}
+	if strings.Contains(body, "Stream configuration issue") || strings.Contains(body, "missing role slug") {
+		t.Fatalf("did not expect reference details for non-admins, got %q", body)
 	}
+
+	body = render(IdentityUser{ID: "admin-1", Email: "admin@example.com", OrgSlug: "org1", IsOrgAdmin: true})
 	if !strings.Contains(body, "Stream configuration issue") {
 		t.Fatalf("expected validation heading inside error, got %q", body)
 	}
-	if !strings.Contains(body, "workflow references are invalid") {
-		t.Fatalf("expected validation error details, got %q", body)
-	}
-	if strings.Contains(body, `class="rail-layout`) || strings.Contains(body, `class="home-workflow-grid"`) {
-		t.Fatalf("did not expect stream dashboard grid when config is invalid, got %q", body)
-	}
-	if strings.Contains(body, `action="/my/streams/workflow/instance/start"`) || strings.Contains(body, "New instance") {
-		t.Fatalf("did not expect new instance controls when config is invalid, got %q", body)
+	if !strings.Contains(body, "missing role slug org1/dep1") || !strings.Contains(body, `href="/my/organization/roles"`) {
+		t.Fatalf("expected missing role with fix link, got %q", body)
 	}
 }
 
@@ -1112,6 +1180,9 @@ func TestNormalizeHomeSortKey(t *testing.T) {
 	if got := normalizeHomeSortKey("status"); got != "status" {
 		t.Fatalf("expected status, got %q", got)
 	}
+	if got := normalizeHomeSortKey("activity_desc"); got != "activity_desc" {
+		t.Fatalf("expected activity_desc, got %q", got)
+	}
 	if got := normalizeHomeSortKey("unknown"); got != "time_desc" {
 		t.Fatalf("expected time_desc for unknown, got %q", got)
 	}
@@ -39,6 +39,7 @@ func TestHomeTemplateRendersSidebarAndReadOnlyPreview(t *testing.T) {
 		StatusFilter:        "all",
 		FilterOptions:       testHomeFilterOptions(process),
 		ProcessGroups:       testHomeActiveGroup("all", process),
+		CanStart:            true,
 		Preview: StreamInstanceDetailView{
 			HideStatus: true,
 			Timeline: []TimelineStep{
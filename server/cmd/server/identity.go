@@ -8,6 +8,11 @@ import (
 
 var ErrIdentityNotFound = errors.New("identity not found")
 var ErrIdentityUnauthorized = errors.New("identity unauthorized")
+var ErrIdentityInviteAccepted = errors.New("identity invite already accepted")
+
+// ErrInviteAlreadyUsed is returned by AcceptInvite when the membership was
+// already confirmed, so a replayed or concurrent accept must not log in.
+var ErrInviteAlreadyUsed = errors.New("invite already used")
 
 // IdentityStore isolates auth and organization data from the Mongo workflow store.
 type IdentityStore interface {
@@ -19,20 +24,29 @@ type IdentityStore interface {
 	CreateEmailPasswordSession(ctx context.Context, email, password string) (IdentitySession, error)
 	CreateRecovery(ctx context.Context, email, redirectURL string) error
 	CompleteRecovery(ctx context.Context, userID, secret, password string) error
+	CreateMagicURL(ctx context.Context, email, redirectURL string) error
+	CreateTokenSession(ctx context.Context, userID, secret string) (IdentitySession, error)
 	UpdateCurrentPassword(ctx context.Context, sessionSecret, password string) error
 	GetSession(ctx context.Context, sessionSecret string) (IdentitySession, error)
 	DeleteSession(ctx context.Context, sessionSecret string) error
+	DeleteUserSessions(ctx context.Context, userID string) error
 	GetCurrentUser(ctx context.Context, sessionSecret string) (IdentityUser, error)
 	GetUserByID(ctx context.Context, userID string) (IdentityUser, error)
 	GetUserByEmail(ctx context.Context, email string) (IdentityUser, error)
 	AddOrganizationUserByIDAsAdmin(ctx context.Context, orgSlug, userID string, roleSlugs []string, isOrgAdmin bool) (IdentityMembership, error)
 	InviteOrganizationUser(ctx context.Context, sessionSecret, orgSlug, email, redirectURL string, roleSlugs []string, isOrgAdmin bool) (IdentityMembership, error)
+	ResendOrganizationInvite(ctx context.Context, sessionSecret, orgSlug, membershipID, redirectURL string) (IdentityMembership, error)
 	ListOrganizations(ctx context.Context) ([]IdentityOrg, error)
 	ListOrganizationMemberships(ctx context.Context, orgSlug string) ([]IdentityMembership, error)
 	ListOrganizationUsers(ctx context.Context, orgSlug string) ([]IdentityUser, error)
+	CountUsersByRole(ctx context.Context, orgSlug string) (map[string]int, error)
 	GetOrganizationBySlug(ctx context.Context, slug string) (*IdentityOrg, error)
 	UpdateOrganization(ctx context.Context, sessionSecret, currentSlug, name, logoFileID string, roles []IdentityRole) (IdentityOrg, error)
 	UpdateOrganizationAsAdmin(ctx context.Context, currentSlug, name, logoFileID string, roles []IdentityRole) (IdentityOrg, error)
+	UpdateRole(ctx context.Context, sessionSecret, orgSlug string, role IdentityRole) (IdentityOrg, error)
+	DeleteRole(ctx context.Context, sessionSecret, orgSlug, roleSlug string) (IdentityOrg, error)
+	UpdateOrganizationEmailDomains(ctx context.Context, sessionSecret, orgSlug string, domains []string) (IdentityOrg, error)
+	UpdateOrganizationPublicBranding(ctx context.Context, sessionSecret, orgSlug string, public bool) (IdentityOrg, error)
 	DeleteOrganizationAsAdmin(ctx context.Context, orgSlug string) error
 	UpdateOrganizationMembership(ctx context.Context, sessionSecret, orgSlug, membershipID string, roleSlugs []string, isOrgAdmin bool) (IdentityMembership, error)
 	UpdateOrganizationMembershipAsAdmin(ctx context.Context, orgSlug, membershipID string, roleSlugs []string, isOrgAdmin bool) (IdentityMembership, error)
@@ -63,6 +77,12 @@ type IdentityOrg struct {
 	Name       string
 	LogoFileID string
 	Roles      []IdentityRole
+	// AllowedEmailDomains restricts invites into the organization; see
+	// normalizeEmailDomains for the entry format. Empty allows all.
+	AllowedEmailDomains []string
+	// PublicBranding lets anonymous visitors see the name and logo, on
+	// /login?org= and through /organization/logo/.
+	PublicBranding bool
 }
 
 type IdentityRole struct {
@@ -26,10 +26,12 @@ import (
 const appwriteTeamIDMaxLen = 36
 
 type appwriteTeamPrefs struct {
-	SchemaVersion int            `json:"schemaVersion,omitempty"`
-	Slug          string         `json:"slug,omitempty"`
-	LogoFileID    string         `json:"logoFileId,omitempty"`
-	Roles         []IdentityRole `json:"roles,omitempty"`
+	SchemaVersion  int            `json:"schemaVersion,omitempty"`
+	Slug           string         `json:"slug,omitempty"`
+	LogoFileID     string         `json:"logoFileId,omitempty"`
+	Roles          []IdentityRole `json:"roles,omitempty"`
+	EmailDomains   []string       `json:"allowedEmailDomains,omitempty"`
+	PublicBranding bool           `json:"publicBranding,omitempty"`
 }
 
 type appwriteIdentity struct {
@@ -180,7 +182,7 @@ func (a *appwriteIdentity) AcceptInvite(ctx context.Context, teamID, membershipI
 		strings.TrimSpace(secret),
 	)
 	if err != nil {
-		return IdentitySession{}, normalizeIdentityError(err)
+		return IdentitySession{}, normalizeInviteAcceptError(err)
 	}
 	accountUser, err := account.New(sessionClient).Get()
 	if err != nil {
@@ -226,6 +228,35 @@ func (a *appwriteIdentity) CompleteRecovery(ctx context.Context, userID, secret,
 	return normalizeIdentityError(err)
 }
 
+// CreateMagicURL emails the existing account of email a one-time sign-in
+// link to redirectURL. Unknown emails return ErrIdentityNotFound rather than
+// letting Appwrite create an account.
+func (a *appwriteIdentity) CreateMagicURL(ctx context.Context, email, redirectURL string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	user, err := a.GetUserByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	client := account.New(a.sessionClient)
+	_, err = client.CreateMagicURLToken(user.ID, strings.TrimSpace(user.Email), client.WithCreateMagicURLTokenUrl(strings.TrimSpace(redirectURL)))
+	return normalizeIdentityError(err)
+}
+
+// CreateTokenSession exchanges the userId/secret of a sign-in link for a
+// session. Appwrite deletes the token when it does, so a link works once.
+func (a *appwriteIdentity) CreateTokenSession(ctx context.Context, userID, secret string) (IdentitySession, error) {
+	if err := ctx.Err(); err != nil {
+		return IdentitySession{}, err
+	}
+	session, err := account.New(a.adminClient).CreateSession(strings.TrimSpace(userID), strings.TrimSpace(secret))
+	if err != nil {
+		return IdentitySession{}, normalizeIdentityError(err)
+	}
+	return toIdentitySession(session, "")
+}
+
 func (a *appwriteIdentity) UpdateCurrentPassword(ctx context.Context, sessionSecret, password string) error {
 	if err := ctx.Err(); err != nil {
 		return err
@@ -268,6 +299,16 @@ func (a *appwriteIdentity) DeleteSession(ctx context.Context, sessionSecret stri
 	return normalizeIdentityError(err)
 }
 
+// DeleteUserSessions revokes every session of userID, so a removed or
+// disabled user is signed out everywhere at once.
+func (a *appwriteIdentity) DeleteUserSessions(ctx context.Context, userID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	_, err := users.New(a.adminClient).DeleteSessions(strings.TrimSpace(userID))
+	return normalizeIdentityError(err)
+}
+
 func (a *appwriteIdentity) GetCurrentUser(ctx context.Context, sessionSecret string) (IdentityUser, error) {
 	if err := ctx.Err(); err != nil {
 		return IdentityUser{}, err
@@ -365,6 +406,49 @@ func (a *appwriteIdentity) InviteOrganizationUser(ctx context.Context, sessionSe
 	return a.inviteOrganizationUserWithClient(ctx, sessionClient, orgSlug, email, redirectURL, roleSlugs, isOrgAdmin)
 }
 
+// ResendOrganizationInvite replaces a pending invite with a fresh one for the
+// same email and roles, which restarts the expiry and sends a new invite
+// email. The replacement is created first, so a failure leaves the old invite
+// in place; Appwrite reissues an unconfirmed membership in place, and only a
+// membership it did not reuse is deleted afterwards. The invited user record
+// is reused.
+func (a *appwriteIdentity) ResendOrganizationInvite(ctx context.Context, sessionSecret, orgSlug, membershipID, redirectURL string) (IdentityMembership, error) {
+	if err := ctx.Err(); err != nil {
+		return IdentityMembership{}, err
+	}
+	org, err := a.GetOrganizationBySlug(ctx, orgSlug)
+	if err != nil {
+		return IdentityMembership{}, err
+	}
+	teamID := strings.TrimSpace(org.ID)
+	current, err := teams.New(a.adminClient).GetMembership(teamID, strings.TrimSpace(membershipID))
+	if err != nil {
+		return IdentityMembership{}, normalizeIdentityError(err)
+	}
+	if current.Confirm {
+		return IdentityMembership{}, ErrIdentityInviteAccepted
+	}
+	sessionClient, err := cloneAppwriteClient(a.sessionClient, appwrite.WithSession(strings.TrimSpace(sessionSecret)))
+	if err != nil {
+		return IdentityMembership{}, err
+	}
+	membership, err := teams.New(sessionClient).CreateMembership(
+		teamID,
+		append([]string(nil), current.Roles...),
+		teams.New(sessionClient).WithCreateMembershipEmail(strings.ToLower(strings.TrimSpace(current.UserEmail))),
+		teams.New(sessionClient).WithCreateMembershipUrl(strings.TrimSpace(redirectURL)),
+	)
+	if err != nil {
+		return IdentityMembership{}, normalizeIdentityError(err)
+	}
+	if membership.Id != current.Id {
+		if _, err := teams.New(sessionClient).DeleteMembership(teamID, current.Id); err != nil {
+			return IdentityMembership{}, normalizeIdentityError(err)
+		}
+	}
+	return a.toIdentityMembership(ctx, membership, org), nil
+}
+
 func (a *appwriteIdentity) InviteOrganizationUserAsAdmin(ctx context.Context, orgSlug, email, redirectURL string, roleSlugs []string, isOrgAdmin bool) (IdentityMembership, error) {
 	if err := ctx.Err(); err != nil {
 		return IdentityMembership{}, err
@@ -447,6 +531,16 @@ func (a *appwriteIdentity) ListOrganizationUsers(ctx context.Context, orgSlug st
 	return usersForOrg, nil
 }
 
+// CountUsersByRole returns, per role slug, how many active members of orgSlug
+// hold that role.
+func (a *appwriteIdentity) CountUsersByRole(ctx context.Context, orgSlug string) (map[string]int, error) {
+	orgUsers, err := a.ListOrganizationUsers(ctx, orgSlug)
+	if err != nil {
+		return nil, err
+	}
+	return countIdentityUsersByRole(orgUsers), nil
+}
+
 func (a *appwriteIdentity) ListOrganizationMemberships(ctx context.Context, orgSlug string) ([]IdentityMembership, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
@@ -515,6 +609,103 @@ func (a *appwriteIdentity) UpdateOrganizationAsAdmin(ctx context.Context, curren
 	return a.updateOrganizationWithClient(ctx, a.adminClient, currentSlug, name, logoFileID, roles)
 }
 
+// UpdateRole rewrites the name and palette of an existing org role in place.
+// The slug is the lookup key and is never changed, so memberships and
+// workflow configs that reference it keep working.
+func (a *appwriteIdentity) UpdateRole(ctx context.Context, sessionSecret, orgSlug string, role IdentityRole) (IdentityOrg, error) {
+	if err := ctx.Err(); err != nil {
+		return IdentityOrg{}, err
+	}
+	org, err := a.GetOrganizationBySlug(ctx, orgSlug)
+	if err != nil {
+		return IdentityOrg{}, err
+	}
+	roles, ok := renameIdentityRole(org.Roles, role)
+	if !ok {
+		return IdentityOrg{}, ErrIdentityNotFound
+	}
+	sessionClient, err := cloneAppwriteClient(a.sessionClient, appwrite.WithSession(strings.TrimSpace(sessionSecret)))
+	if err != nil {
+		return IdentityOrg{}, err
+	}
+	return a.updateOrganizationWithClient(ctx, sessionClient, org.Slug, org.Name, org.LogoFileID, roles)
+}
+
+// DeleteRole removes an org role. Callers check that nothing uses it first.
+func (a *appwriteIdentity) DeleteRole(ctx context.Context, sessionSecret, orgSlug, roleSlug string) (IdentityOrg, error) {
+	if err := ctx.Err(); err != nil {
+		return IdentityOrg{}, err
+	}
+	org, err := a.GetOrganizationBySlug(ctx, orgSlug)
+	if err != nil {
+		return IdentityOrg{}, err
+	}
+	roles, ok := removeIdentityRole(org.Roles, roleSlug)
+	if !ok {
+		return IdentityOrg{}, ErrIdentityNotFound
+	}
+	sessionClient, err := cloneAppwriteClient(a.sessionClient, appwrite.WithSession(strings.TrimSpace(sessionSecret)))
+	if err != nil {
+		return IdentityOrg{}, err
+	}
+	return a.updateOrganizationWithClient(ctx, sessionClient, org.Slug, org.Name, org.LogoFileID, roles)
+}
+
+// UpdateOrganizationEmailDomains replaces the invite email domain allowlist
+// of an org. The name is rewritten unchanged with the session client so
+// only an org owner can change the allowlist.
+func (a *appwriteIdentity) UpdateOrganizationEmailDomains(ctx context.Context, sessionSecret, orgSlug string, domains []string) (IdentityOrg, error) {
+	if err := ctx.Err(); err != nil {
+		return IdentityOrg{}, err
+	}
+	org, err := a.GetOrganizationBySlug(ctx, orgSlug)
+	if err != nil {
+		return IdentityOrg{}, err
+	}
+	sessionClient, err := cloneAppwriteClient(a.sessionClient, appwrite.WithSession(strings.TrimSpace(sessionSecret)))
+	if err != nil {
+		return IdentityOrg{}, err
+	}
+	teamID := strings.TrimSpace(org.ID)
+	if _, err := teams.New(sessionClient).UpdateName(teamID, org.Name); err != nil {
+		return IdentityOrg{}, normalizeIdentityError(err)
+	}
+	updatedOrg := *org
+	updatedOrg.AllowedEmailDomains = append([]string(nil), domains...)
+	if _, err := teams.New(a.adminClient).UpdatePrefs(teamID, encodeIdentityOrgPrefs(updatedOrg)); err != nil {
+		return IdentityOrg{}, normalizeIdentityError(err)
+	}
+	return updatedOrg, nil
+}
+
+// UpdateOrganizationPublicBranding opts an org in or out of showing its name
+// and logo to visitors without a session. Like the email domain allowlist,
+// the name is rewritten unchanged with the session client first so only an
+// org owner can change it.
+func (a *appwriteIdentity) UpdateOrganizationPublicBranding(ctx context.Context, sessionSecret, orgSlug string, public bool) (IdentityOrg, error) {
+	if err := ctx.Err(); err != nil {
+		return IdentityOrg{}, err
+	}
+	org, err := a.GetOrganizationBySlug(ctx, orgSlug)
+	if err != nil {
+		return IdentityOrg{}, err
+	}
+	sessionClient, err := cloneAppwriteClient(a.sessionClient, appwrite.WithSession(strings.TrimSpace(sessionSecret)))
+	if err != nil {
+		return IdentityOrg{}, err
+	}
+	teamID := strings.TrimSpace(org.ID)
+	if _, err := teams.New(sessionClient).UpdateName(teamID, org.Name); err != nil {
+		return IdentityOrg{}, normalizeIdentityError(err)
+	}
+	updatedOrg := *org
+	updatedOrg.PublicBranding = public
+	if _, err := teams.New(a.adminClient).UpdatePrefs(teamID, encodeIdentityOrgPrefs(updatedOrg)); err != nil {
+		return IdentityOrg{}, normalizeIdentityError(err)
+	}
+	return updatedOrg, nil
+}
+
 func (a *appwriteIdentity) DeleteOrganizationAsAdmin(ctx context.Context, orgSlug string) error {
 	if err := ctx.Err(); err != nil {
 		return err
@@ -702,11 +893,13 @@ func (a *appwriteIdentity) updateOrganizationWithClient(ctx context.Context, cli
 		return IdentityOrg{}, normalizeIdentityError(err)
 	}
 	updatedOrg := IdentityOrg{
-		ID:         teamID,
-		Slug:       canonifySlug(name),
-		Name:       strings.TrimSpace(updatedTeam.Name),
-		LogoFileID: strings.TrimSpace(logoFileID),
-		Roles:      append([]IdentityRole(nil), roles...),
+		ID:                  teamID,
+		Slug:                canonifySlug(name),
+		Name:                strings.TrimSpace(updatedTeam.Name),
+		LogoFileID:          strings.TrimSpace(logoFileID),
+		Roles:               append([]IdentityRole(nil), roles...),
+		AllowedEmailDomains: append([]string(nil), org.AllowedEmailDomains...),
+		PublicBranding:      org.PublicBranding,
 	}
 	if _, err := teams.New(a.adminClient).UpdatePrefs(teamID, encodeIdentityOrgPrefs(updatedOrg)); err != nil {
 		return IdentityOrg{}, normalizeIdentityError(err)
@@ -952,6 +1145,17 @@ func parseAppwriteTime(value string) (time.Time, error) {
 	return time.Parse(time.RFC3339, value)
 }
 
+// normalizeInviteAcceptError maps the conflict Appwrite returns for an already
+// confirmed membership to ErrInviteAlreadyUsed. The membership status update is
+// conditional on Appwrite's side, so only one accept per invite can succeed.
+func normalizeInviteAcceptError(err error) error {
+	var appwriteErr *appwriteclient.AppwriteError
+	if errors.As(err, &appwriteErr) && appwriteErr.GetStatusCode() == http.StatusConflict {
+		return ErrInviteAlreadyUsed
+	}
+	return normalizeIdentityError(err)
+}
+
 func normalizeIdentityError(err error) error {
 	if err == nil {
 		return nil
@@ -855,6 +855,73 @@ func TestAppwriteIdentityCreateAccountAndRecovery(t *testing.T) {
 	}
 }
 
+func TestAppwriteIdentityMagicURL(t *testing.T) {
+	var tokenBody map[string]interface{}
+	var sessionBody map[string]interface{}
+	tokens := 0
+
+	appwriteAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/users":
+			if r.URL.Query().Get("search") != "member@example.com" {
+				_, _ = w.Write([]byte(`{"total":0,"users":[]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"total":1,"users":[{"$id":"member-1","email":"member@example.com","status":true,"labels":[]}]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/users/member-1":
+			_, _ = w.Write([]byte(`{"$id":"member-1","email":"member@example.com","status":true,"labels":[]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/users/member-1/memberships":
+			_, _ = w.Write([]byte(`{"total":0,"memberships":[]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/account/tokens/magic-url":
+			tokens++
+			if err := json.NewDecoder(r.Body).Decode(&tokenBody); err != nil {
+				t.Fatalf("decode magic url body: %v", err)
+			}
+			_, _ = w.Write([]byte(`{"$id":"token-1","userId":"member-1","secret":"","expire":"2026-03-18T11:00:00Z"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/account/sessions/token":
+			if err := json.NewDecoder(r.Body).Decode(&sessionBody); err != nil {
+				t.Fatalf("decode token session body: %v", err)
+			}
+			if sessionBody["secret"] != "secret-1" {
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte(`{"message":"Invalid token","code":401,"type":"user_invalid_token"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"$id":"session-1","userId":"member-1","expire":"2026-03-18T10:11:12Z","secret":"session-secret"}`))
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer appwriteAPI.Close()
+
+	identity := NewAppwriteIdentity(appwriteAPI.URL+"/v1", "project-1", "api-key-1", appwriteAPI.Client())
+
+	if err := identity.CreateMagicURL(context.Background(), "Member@example.com", "http://attesta.local/login/magic/confirm"); err != nil {
+		t.Fatalf("CreateMagicURL error: %v", err)
+	}
+	if tokenBody["userId"] != "member-1" || tokenBody["email"] != "member@example.com" || tokenBody["url"] != "http://attesta.local/login/magic/confirm" {
+		t.Fatalf("magic url request = %#v", tokenBody)
+	}
+	if err := identity.CreateMagicURL(context.Background(), "stranger@example.com", "http://attesta.local/login/magic/confirm"); !errors.Is(err, ErrIdentityNotFound) {
+		t.Fatalf("unknown email: expected ErrIdentityNotFound, got %v", err)
+	}
+	if tokens != 1 {
+		t.Fatalf("magic url tokens = %d, want 1", tokens)
+	}
+
+	session, err := identity.CreateTokenSession(context.Background(), "member-1", "secret-1")
+	if err != nil {
+		t.Fatalf("CreateTokenSession error: %v", err)
+	}
+	if session.Secret != "session-secret" || session.UserID != "member-1" || sessionBody["userId"] != "member-1" {
+		t.Fatalf("session = %#v request = %#v", session, sessionBody)
+	}
+	if _, err := identity.CreateTokenSession(context.Background(), "member-1", "used"); !errors.Is(err, ErrIdentityUnauthorized) {
+		t.Fatalf("used token: expected ErrIdentityUnauthorized, got %v", err)
+	}
+}
+
 func TestAppwriteIdentityGetCurrentUserHydratesMembership(t *testing.T) {
 	var accountSessionHeader string
 	var membershipsKeyHeader string
@@ -1332,6 +1399,25 @@ func TestAppwriteIdentityCreateOrganizationKeepsExistingAdminLabel(t *testing.T)
 	}
 }
 
+func TestAppwriteIdentityAcceptInviteAlreadyConfirmed(t *testing.T) {
+	appwriteAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/teams/acme/memberships/membership-1/status":
+			http.Error(w, `{"message":"Membership already confirmed","code":409,"type":"membership_already_confirmed"}`, http.StatusConflict)
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer appwriteAPI.Close()
+
+	identity := NewAppwriteIdentity(appwriteAPI.URL+"/v1", "project-1", "api-key-1", appwriteAPI.Client())
+
+	if _, err := identity.AcceptInvite(context.Background(), "acme", "membership-1", "user-1", "secret-1"); !errors.Is(err, ErrInviteAlreadyUsed) {
+		t.Fatalf("AcceptInvite error = %v, want %v", err, ErrInviteAlreadyUsed)
+	}
+}
+
 func TestAppwriteIdentityAcceptInviteSessionFailure(t *testing.T) {
 	appwriteAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -1994,3 +2080,58 @@ func TestIdentityAppwriteNilAndErrorBranches(t *testing.T) {
 		t.Fatal("expected missing secret error")
 	}
 }
+
+func TestAppwriteIdentityResendOrganizationInviteCreatesBeforeDeleting(t *testing.T) {
+	var calls []string
+	createdID := "membership-3"
+	createFails := false
+	appwriteAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/teams/acme":
+			http.NotFound(w, r)
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/teams":
+			_, _ = w.Write([]byte(`{"total":1,"teams":[{"$id":"acme-team","name":"Acme Org","prefs":{"schemaVersion":1,"slug":"acme"}}]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/teams/acme-team/memberships/membership-2":
+			_, _ = w.Write([]byte(`{"$id":"membership-2","userId":"","userEmail":"pending@example.com","teamId":"acme-team","teamName":"Acme Org","confirm":false,"roles":["member","iapprover"]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/teams/acme-team/memberships":
+			calls = append(calls, "create")
+			if createFails {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				_, _ = w.Write([]byte(`{"message":"mail unavailable","code":503,"type":"general_unknown"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"$id":"` + createdID + `","userId":"","userEmail":"pending@example.com","teamId":"acme-team","teamName":"Acme Org","confirm":false,"roles":["member","iapprover"]}`))
+		case r.Method == http.MethodDelete && r.URL.Path == "/v1/teams/acme-team/memberships/membership-2":
+			calls = append(calls, "delete")
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer appwriteAPI.Close()
+
+	identity := NewAppwriteIdentity(appwriteAPI.URL+"/v1", "project-1", "api-key-1", appwriteAPI.Client())
+	resend := func() (IdentityMembership, error) {
+		calls = nil
+		return identity.ResendOrganizationInvite(context.Background(), "session-secret", "acme", "membership-2", "https://attesta.example/invite/accept")
+	}
+
+	membership, err := resend()
+	if err != nil {
+		t.Fatalf("ResendOrganizationInvite error: %v", err)
+	}
+	if membership.ID != "membership-3" || strings.Join(calls, ",") != "create,delete" {
+		t.Fatalf("membership = %#v calls = %#v", membership, calls)
+	}
+
+	createdID = "membership-2"
+	if _, err := resend(); err != nil || strings.Join(calls, ",") != "create" {
+		t.Fatalf("reissued in place: err = %v calls = %#v, want no delete", err, calls)
+	}
+
+	createFails = true
+	if _, err := resend(); err == nil || strings.Join(calls, ",") != "create" {
+		t.Fatalf("failed create: err = %v calls = %#v, want the old invite kept", err, calls)
+	}
+}
@@ -1,6 +1,12 @@
 package main
 
-import "strings"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+)
 
 const (
 	identityOrgAdminLabel          = "attestaOrgAdmin"
@@ -41,12 +47,27 @@ func decodeIdentityRoleLabels(labels []string) []string {
 	return uniqueIdentityStrings(roleSlugs)
 }
 
+func countIdentityUsersByRole(orgUsers []IdentityUser) map[string]int {
+	counts := map[string]int{}
+	for _, user := range orgUsers {
+		if status := strings.TrimSpace(user.Status); status != "" && status != "active" {
+			continue
+		}
+		for _, slug := range decodeIdentityRoleLabels(user.Labels) {
+			counts[slug]++
+		}
+	}
+	return counts
+}
+
 func encodeIdentityOrgPrefs(org IdentityOrg) appwriteTeamPrefs {
 	return appwriteTeamPrefs{
-		SchemaVersion: identityTeamPrefsSchemaVersion,
-		Slug:          strings.TrimSpace(org.Slug),
-		LogoFileID:    strings.TrimSpace(org.LogoFileID),
-		Roles:         append([]IdentityRole(nil), org.Roles...),
+		SchemaVersion:  identityTeamPrefsSchemaVersion,
+		Slug:           strings.TrimSpace(org.Slug),
+		LogoFileID:     strings.TrimSpace(org.LogoFileID),
+		Roles:          append([]IdentityRole(nil), org.Roles...),
+		EmailDomains:   append([]string(nil), org.AllowedEmailDomains...),
+		PublicBranding: org.PublicBranding,
 	}
 }
 
@@ -56,11 +77,13 @@ func decodeIdentityOrgFromTeam(id, name string, prefs appwriteTeamPrefs) Identit
 		slug = strings.TrimSpace(id)
 	}
 	return IdentityOrg{
-		ID:         strings.TrimSpace(id),
-		Slug:       slug,
-		Name:       strings.TrimSpace(name),
-		LogoFileID: strings.TrimSpace(prefs.LogoFileID),
-		Roles:      append([]IdentityRole(nil), prefs.Roles...),
+		ID:                  strings.TrimSpace(id),
+		Slug:                slug,
+		Name:                strings.TrimSpace(name),
+		LogoFileID:          strings.TrimSpace(prefs.LogoFileID),
+		Roles:               append([]IdentityRole(nil), prefs.Roles...),
+		AllowedEmailDomains: append([]string(nil), prefs.EmailDomains...),
+		PublicBranding:      prefs.PublicBranding,
 	}
 }
 
@@ -78,6 +101,23 @@ func encodeInviteMembershipRoles(roleSlugs []string, isOrgAdmin bool) []string {
 	return roles
 }
 
+// identityRolesVersion is the role update counter of user (see
+// Store.ClaimUserRolesVersion) followed by a fingerprint of the role labels.
+// The org admin role form carries it so a role update based on a stale page
+// is refused instead of overwriting roles another admin changed in the
+// meantime; the fingerprint also catches label changes made outside the form.
+func identityRolesVersion(user IdentityUser, seq int64) string {
+	managed := make([]string, 0, len(user.Labels))
+	for _, label := range user.Labels {
+		if isManagedIdentityLabel(label) {
+			managed = append(managed, strings.TrimSpace(label))
+		}
+	}
+	sort.Strings(managed)
+	sum := sha256.Sum256([]byte(strings.Join(managed, "\n")))
+	return strconv.FormatInt(seq, 10) + ":" + hex.EncodeToString(sum[:8])
+}
+
 func isManagedIdentityLabel(label string) bool {
 	label = strings.TrimSpace(label)
 	if strings.EqualFold(label, identityOrgAdminLabel) {
@@ -97,3 +97,18 @@ func TestIdentityLabelsAvoidColon(t *testing.T) {
 		t.Fatalf("encoded role label contains ':'")
 	}
 }
+
+func TestIdentityRolesVersionTracksManagedLabelsOnly(t *testing.T) {
+	base := IdentityUser{Labels: []string{encodeIdentityRoleLabel("approver"), identityOrgAdminLabel}}
+	reordered := IdentityUser{Labels: []string{identityOrgAdminLabel, "unrelated", encodeIdentityRoleLabel("approver")}}
+	if identityRolesVersion(base, 3) != identityRolesVersion(reordered, 3) {
+		t.Fatal("expected version to ignore label order and unmanaged labels")
+	}
+	changed := IdentityUser{Labels: []string{encodeIdentityRoleLabel("approver")}}
+	if identityRolesVersion(base, 3) == identityRolesVersion(changed, 3) {
+		t.Fatal("expected version to change when roles change")
+	}
+	if identityRolesVersion(base, 3) == identityRolesVersion(base, 4) {
+		t.Fatal("expected version to change when the roles counter moves")
+	}
+}
@@ -15,19 +15,28 @@ type fakeIdentityStore struct {
 	createEmailPasswordSessionFunc          func(ctx context.Context, email, password string) (IdentitySession, error)
 	createRecoveryFunc                      func(ctx context.Context, email, redirectURL string) error
 	completeRecoveryFunc                    func(ctx context.Context, userID, secret, password string) error
+	createMagicURLFunc                      func(ctx context.Context, email, redirectURL string) error
+	createTokenSessionFunc                  func(ctx context.Context, userID, secret string) (IdentitySession, error)
 	updateCurrentPasswordFunc               func(ctx context.Context, sessionSecret, password string) error
 	getSessionFunc                          func(ctx context.Context, sessionSecret string) (IdentitySession, error)
 	deleteSessionFunc                       func(ctx context.Context, sessionSecret string) error
+	deleteUserSessionsFunc                  func(ctx context.Context, userID string) error
 	getCurrentUserFunc                      func(ctx context.Context, sessionSecret string) (IdentityUser, error)
 	getUserByIDFunc                         func(ctx context.Context, userID string) (IdentityUser, error)
 	getUserByEmailFunc                      func(ctx context.Context, email string) (IdentityUser, error)
 	addOrganizationUserByIDAsAdminFunc      func(ctx context.Context, orgSlug, userID string, roleSlugs []string, isOrgAdmin bool) (IdentityMembership, error)
 	inviteOrganizationUserFunc              func(ctx context.Context, sessionSecret, orgSlug, email, redirectURL string, roleSlugs []string, isOrgAdmin bool) (IdentityMembership, error)
+	resendOrganizationInviteFunc            func(ctx context.Context, sessionSecret, orgSlug, membershipID, redirectURL string) (IdentityMembership, error)
 	listOrganizationsFunc                   func(ctx context.Context) ([]IdentityOrg, error)
 	listOrganizationMembershipsFunc         func(ctx context.Context, orgSlug string) ([]IdentityMembership, error)
 	listOrganizationUsersFunc               func(ctx context.Context, orgSlug string) ([]IdentityUser, error)
+	countUsersByRoleFunc                    func(ctx context.Context, orgSlug string) (map[string]int, error)
 	getOrganizationBySlugFunc               func(ctx context.Context, slug string) (*IdentityOrg, error)
 	updateOrganizationFunc                  func(ctx context.Context, sessionSecret, currentSlug, name, logoFileID string, roles []IdentityRole) (IdentityOrg, error)
+	updateRoleFunc                          func(ctx context.Context, sessionSecret, orgSlug string, role IdentityRole) (IdentityOrg, error)
+	deleteRoleFunc                          func(ctx context.Context, sessionSecret, orgSlug, roleSlug string) (IdentityOrg, error)
+	updateOrganizationEmailDomainsFunc      func(ctx context.Context, sessionSecret, orgSlug string, domains []string) (IdentityOrg, error)
+	updateOrganizationPublicBrandingFunc    func(ctx context.Context, sessionSecret, orgSlug string, public bool) (IdentityOrg, error)
 	updateOrganizationAsAdminFunc           func(ctx context.Context, currentSlug, name, logoFileID string, roles []IdentityRole) (IdentityOrg, error)
 	deleteOrganizationAsAdminFunc           func(ctx context.Context, orgSlug string) error
 	updateOrganizationMembershipFunc        func(ctx context.Context, sessionSecret, orgSlug, membershipID string, roleSlugs []string, isOrgAdmin bool) (IdentityMembership, error)
@@ -96,6 +105,20 @@ func (f *fakeIdentityStore) CompleteRecovery(ctx context.Context, userID, secret
 	return nil
 }
 
+func (f *fakeIdentityStore) CreateMagicURL(ctx context.Context, email, redirectURL string) error {
+	if f.createMagicURLFunc != nil {
+		return f.createMagicURLFunc(ctx, email, redirectURL)
+	}
+	return nil
+}
+
+func (f *fakeIdentityStore) CreateTokenSession(ctx context.Context, userID, secret string) (IdentitySession, error) {
+	if f.createTokenSessionFunc != nil {
+		return f.createTokenSessionFunc(ctx, userID, secret)
+	}
+	return IdentitySession{}, ErrIdentityUnauthorized
+}
+
 func (f *fakeIdentityStore) UpdateCurrentPassword(ctx context.Context, sessionSecret, password string) error {
 	if f.updateCurrentPasswordFunc != nil {
 		return f.updateCurrentPasswordFunc(ctx, sessionSecret, password)
@@ -117,6 +140,13 @@ func (f *fakeIdentityStore) DeleteSession(ctx context.Context, sessionSecret str
 	return nil
 }
 
+func (f *fakeIdentityStore) DeleteUserSessions(ctx context.Context, userID string) error {
+	if f.deleteUserSessionsFunc != nil {
+		return f.deleteUserSessionsFunc(ctx, userID)
+	}
+	return nil
+}
+
 func (f *fakeIdentityStore) GetCurrentUser(ctx context.Context, sessionSecret string) (IdentityUser, error) {
 	if f.getCurrentUserFunc != nil {
 		return f.getCurrentUserFunc(ctx, sessionSecret)
@@ -152,6 +182,13 @@ func (f *fakeIdentityStore) InviteOrganizationUser(ctx context.Context, sessionS
 	return IdentityMembership{}, ErrIdentityUnauthorized
 }
 
+func (f *fakeIdentityStore) ResendOrganizationInvite(ctx context.Context, sessionSecret, orgSlug, membershipID, redirectURL string) (IdentityMembership, error) {
+	if f.resendOrganizationInviteFunc != nil {
+		return f.resendOrganizationInviteFunc(ctx, sessionSecret, orgSlug, membershipID, redirectURL)
+	}
+	return IdentityMembership{}, ErrIdentityUnauthorized
+}
+
 func (f *fakeIdentityStore) ListOrganizations(ctx context.Context) ([]IdentityOrg, error) {
 	if f.listOrganizationsFunc != nil {
 		return f.listOrganizationsFunc(ctx)
@@ -173,6 +210,17 @@ func (f *fakeIdentityStore) ListOrganizationUsers(ctx context.Context, orgSlug s
 	return nil, nil
 }
 
+func (f *fakeIdentityStore) CountUsersByRole(ctx context.Context, orgSlug string) (map[string]int, error) {
+	if f.countUsersByRoleFunc != nil {
+		return f.countUsersByRoleFunc(ctx, orgSlug)
+	}
+	orgUsers, err := f.ListOrganizationUsers(ctx, orgSlug)
+	if err != nil {
+		return nil, err
+	}
+	return countIdentityUsersByRole(orgUsers), nil
+}
+
 func (f *fakeIdentityStore) GetOrganizationBySlug(ctx context.Context, slug string) (*IdentityOrg, error) {
 	if f.getOrganizationBySlugFunc != nil {
 		return f.getOrganizationBySlugFunc(ctx, slug)
@@ -194,6 +242,34 @@ func (f *fakeIdentityStore) UpdateOrganizationAsAdmin(ctx context.Context, curre
 	return IdentityOrg{}, ErrIdentityUnauthorized
 }
 
+func (f *fakeIdentityStore) UpdateRole(ctx context.Context, sessionSecret, orgSlug string, role IdentityRole) (IdentityOrg, error) {
+	if f.updateRoleFunc != nil {
+		return f.updateRoleFunc(ctx, sessionSecret, orgSlug, role)
+	}
+	return IdentityOrg{}, ErrIdentityUnauthorized
+}
+
+func (f *fakeIdentityStore) DeleteRole(ctx context.Context, sessionSecret, orgSlug, roleSlug string) (IdentityOrg, error) {
+	if f.deleteRoleFunc != nil {
+		return f.deleteRoleFunc(ctx, sessionSecret, orgSlug, roleSlug)
+	}
+	return IdentityOrg{}, ErrIdentityUnauthorized
+}
+
+func (f *fakeIdentityStore) UpdateOrganizationEmailDomains(ctx context.Context, sessionSecret, orgSlug string, domains []string) (IdentityOrg, error) {
+	if f.updateOrganizationEmailDomainsFunc != nil {
+		return f.updateOrganizationEmailDomainsFunc(ctx, sessionSecret, orgSlug, domains)
+	}
+	return IdentityOrg{}, ErrIdentityUnauthorized
+}
+
+func (f *fakeIdentityStore) UpdateOrganizationPublicBranding(ctx context.Context, sessionSecret, orgSlug string, public bool) (IdentityOrg, error) {
+	if f.updateOrganizationPublicBrandingFunc != nil {
+		return f.updateOrganizationPublicBrandingFunc(ctx, sessionSecret, orgSlug, public)
+	}
+	return IdentityOrg{}, ErrIdentityUnauthorized
+}
+
 func (f *fakeIdentityStore) DeleteOrganizationAsAdmin(ctx context.Context, orgSlug string) error {
 	if f.deleteOrganizationAsAdminFunc != nil {
 		return f.deleteOrganizationAsAdminFunc(ctx, orgSlug)
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const noticeMagicLinkSent = "magic_link_sent"
+
+const magicLinkInvalidMessage = "This sign-in link is invalid or has expired. Request a new one."
+
+// magicLinkRedirectURL is where the emailed sign-in link points. Appwrite
+// appends userId and secret to it; next survives as its own parameter.
+func (s *Server) magicLinkRedirectURL(r *http.Request, next string) string {
+	target := strings.TrimSpace(os.Getenv("APPWRITE_MAGIC_URL_REDIRECT_URL"))
+	if target == "" {
+		target = s.requestBaseURL(r) + "/login/magic/confirm"
+	}
+	if next == "" || next == appHomePath {
+		return target
+	}
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return target
+	}
+	query := parsed.Query()
+	query.Set("next", next)
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
+
+func (s *Server) renderMagicLinkLoginError(w http.ResponseWriter, r *http.Request, status int, email, next, message string) {
+	w.WriteHeader(status)
+	_ = s.tmpl.ExecuteTemplate(w, "login.html", LoginView{
+		PageBase:   s.pageBase("login_body", "", ""),
+		Email:      email,
+		Next:       next,
+		Error:      message,
+		ShowSignup: anyoneCanCreateAccount(),
+	})
+}
+
+// handleMagicLink serves POST /login/magic (email a one-time sign-in link)
+// and GET /login/magic/confirm?userId=&secret= (sign in with it). Appwrite
+// issues the token, sends the mail and enforces expiry and single use.
+func (s *Server) handleMagicLink(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/login/magic" && r.Method == http.MethodPost:
+		s.handleMagicLinkRequest(w, r)
+	case r.URL.Path == "/login/magic/confirm" && r.Method == http.MethodGet:
+		s.handleMagicLinkConfirm(w, r)
+	case r.URL.Path == "/login/magic" || r.URL.Path == "/login/magic/confirm":
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) handleMagicLinkRequest(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		logAndHTTPError(w, r, http.StatusBadRequest, "invalid form", err, "failed to parse magic link form")
+		return
+	}
+	email := strings.ToLower(strings.TrimSpace(r.FormValue("email")))
+	next := s.safeNextPath(r, appHomePath)
+	if email == "" {
+		s.renderMagicLinkLoginError(w, r, http.StatusBadRequest, email, next, "Enter your email to receive a sign-in link.")
+		return
+	}
+	if s.identity == nil {
+		http.Error(w, "login unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	sent := "/login?notice=" + url.QueryEscape(noticeMagicLinkSent)
+	// The platform admin signs in with the configured credentials only.
+	if adminEmail, _, ok := platformAdminCredentials(); ok && strings.EqualFold(email, adminEmail) {
+		http.Redirect(w, r, sent, http.StatusSeeOther)
+		return
+	}
+	if err := s.identity.CreateMagicURL(r.Context(), email, s.magicLinkRedirectURL(r, next)); err != nil && !errors.Is(err, ErrIdentityNotFound) {
+		logRequestError(r, err, "failed to create magic link for %s", email)
+		s.renderMagicLinkLoginError(w, r, http.StatusBadGateway, email, next, "Unable to send a sign-in link right now. Please try again.")
+		return
+	}
+	http.Redirect(w, r, sent, http.StatusSeeOther)
+}
+
+func (s *Server) handleMagicLinkConfirm(w http.ResponseWriter, r *http.Request) {
+	if s.identity == nil {
+		http.NotFound(w, r)
+		return
+	}
+	next := s.safeNextPath(r, appHomePath)
+	userID, secret := resetConfirmParams(r)
+	if userID == "" || secret == "" {
+		s.renderMagicLinkLoginError(w, r, http.StatusBadRequest, "", next, magicLinkInvalidMessage)
+		return
+	}
+	session, err := s.identity.CreateTokenSession(r.Context(), userID, secret)
+	if isLoginCredentialError(err) {
+		s.renderMagicLinkLoginError(w, r, http.StatusUnauthorized, "", next, magicLinkInvalidMessage)
+		return
+	}
+	if err != nil {
+		logAndHTTPError(w, r, http.StatusInternalServerError, "login failed", err, "failed to create magic link session for user %s", userID)
+		return
+	}
+	if err := s.writeSessionCookie(w, r, session); err != nil {
+		logAndHTTPError(w, r, http.StatusInternalServerError, "login failed", err, "failed to write magic link session cookie for user %s", userID)
+		return
+	}
+	http.Redirect(w, r, next, http.StatusSeeOther)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandleMagicLinkRequest(t *testing.T) {
+	t.Setenv("ADMIN_EMAIL", "admin@example.com")
+	t.Setenv("ADMIN_PASSWORD", "change-me")
+	var gotEmail, gotRedirect string
+	sendErr := error(nil)
+	server := &Server{
+		tmpl: parseTestTemplates(t),
+		identity: &fakeIdentityStore{
+			createMagicURLFunc: func(ctx context.Context, email, redirectURL string) error {
+				gotEmail, gotRedirect = email, redirectURL
+				return sendErr
+			},
+		},
+	}
+	request := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "http://attesta.local/login/magic", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		server.handleMagicLink(rec, req)
+		return rec
+	}
+
+	rec := request(url.Values{"email": {" Member@Example.com "}, "next": {"/my/streams/workflow"}})
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/login?notice="+noticeMagicLinkSent {
+		t.Fatalf("status = %d location = %q", rec.Code, rec.Header().Get("Location"))
+	}
+	if gotEmail != "member@example.com" || gotRedirect != "http://attesta.local/login/magic/confirm?next=%2Fmy%2Fstreams%2Fworkflow" {
+		t.Fatalf("magic url = %q %q", gotEmail, gotRedirect)
+	}
+
+	sendErr = ErrIdentityNotFound
+	if rec := request(url.Values{"email": {"stranger@example.com"}}); rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/login?notice="+noticeMagicLinkSent {
+		t.Fatalf("unknown email status = %d location = %q", rec.Code, rec.Header().Get("Location"))
+	}
+
+	sendErr = errors.New("smtp down")
+	rec = request(url.Values{"email": {"member@example.com"}})
+	if rec.Code != http.StatusBadGateway || !strings.Contains(rec.Body.String(), "Unable to send a sign-in link right now.") {
+		t.Fatalf("send failure status = %d body = %s", rec.Code, rec.Body.String())
+	}
+
+	gotEmail = ""
+	if rec := request(url.Values{"email": {"admin@example.com"}}); rec.Code != http.StatusSeeOther || gotEmail != "" {
+		t.Fatalf("platform admin status = %d, magic url sent to %q", rec.Code, gotEmail)
+	}
+	if rec := request(url.Values{"email": {""}}); rec.Code != http.StatusBadRequest {
+		t.Fatalf("blank email status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestHandleMagicLinkConfirm(t *testing.T) {
+	used := map[string]bool{}
+	server := &Server{
+		tmpl: parseTestTemplates(t),
+		identity: &fakeIdentityStore{
+			createTokenSessionFunc: func(ctx context.Context, userID, secret string) (IdentitySession, error) {
+				if userID != "user-1" || secret != "secret-1" || used[secret] {
+					return IdentitySession{}, ErrIdentityUnauthorized
+				}
+				used[secret] = true
+				return fakeIdentitySession("magic-session", userID, time.Now().Add(time.Hour)), nil
+			},
+		},
+	}
+	confirm := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		server.handleMagicLink(rec, httptest.NewRequest(http.MethodGet, "/login/magic/confirm"+query, nil))
+		return rec
+	}
+
+	rec := confirm("?userId=user-1&secret=secret-1&next=%2Fmy%2Fstreams%2Fworkflow")
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/my/streams/workflow" {
+		t.Fatalf("status = %d location = %q", rec.Code, rec.Header().Get("Location"))
+	}
+	if cookie := rec.Result().Cookies(); len(cookie) != 1 || cookie[0].Name != "attesta_session" || cookie[0].Value != "magic-session" {
+		t.Fatalf("cookies = %#v", cookie)
+	}
+
+	rec = confirm("?userId=user-1&secret=secret-1")
+	if rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), magicLinkInvalidMessage) || len(rec.Result().Cookies()) != 0 {
+		t.Fatalf("reused link status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	if rec := confirm("?userId=user-1"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("missing secret status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestLoginTemplateOffersMagicLink(t *testing.T) {
+	tmpl := parseTestTemplates(t)
+	var out strings.Builder
+	if err := tmpl.ExecuteTemplate(&out, "login_body", LoginView{Next: "/"}); err != nil {
+		t.Fatalf("render login_body: %v", err)
+	}
+	if !strings.Contains(out.String(), `formaction="/login/magic"`) {
+		t.Fatalf("expected magic link button in login form: %s", out.String())
+	}
+}
@@ -13,13 +13,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"hash/fnv"
 	"html/template"
 	"io"
 	"log"
 	"mime"
 	"net"
 	"net/http"
+	"net/netip"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -27,7 +27,9 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -42,10 +44,34 @@ const (
 )
 
 type WorkflowDef struct {
-	ID          primitive.ObjectID `bson:"_id,omitempty" yaml:"-"`
-	Name        string             `bson:"name" yaml:"name"`
-	Description string             `bson:"description,omitempty" yaml:"description,omitempty"`
-	Steps       []WorkflowStep     `bson:"steps" yaml:"steps"`
+	ID            primitive.ObjectID `bson:"_id,omitempty" yaml:"-"`
+	Name          string             `bson:"name" yaml:"name"`
+	Description   string             `bson:"description,omitempty" yaml:"description,omitempty"`
+	StartRoles    []string           `bson:"startRoles,omitempty" yaml:"startRoles,omitempty"`
+	RetentionDays int                `bson:"retentionDays,omitempty" yaml:"retentionDays,omitempty"`
+	Enabled       *bool              `bson:"enabled,omitempty" yaml:"enabled,omitempty"`
+	// CompletionWebhook receives a process.completed POST once per process
+	// that finishes (completion_webhook.go).
+	CompletionWebhook string `bson:"completionWebhook,omitempty" yaml:"completionWebhook,omitempty"`
+	// ProcessCodePrefix enables human process codes (process_code.go).
+	ProcessCodePrefix string `bson:"processCodePrefix,omitempty" yaml:"processCodePrefix,omitempty"`
+	// DoneWhen is "all" (default) or "required", which lets optional
+	// substeps stay empty in a complete process (process_completeness.go).
+	DoneWhen string `bson:"doneWhen,omitempty" yaml:"doneWhen,omitempty"`
+	// StartFields are substeps filled on the creation form and stored as
+	// completed with the new process (process_start_fields.go).
+	StartFields []string `bson:"startFields,omitempty" yaml:"startFields,omitempty"`
+	// ViewAccess is "open" (default) or "restricted", which limits viewing a
+	// process to its participants and the workflow's roles (process_acl.go).
+	ViewAccess string         `bson:"viewAccess,omitempty" yaml:"viewAccess,omitempty"`
+	Steps      []WorkflowStep `bson:"steps" yaml:"steps"`
+}
+
+// workflowEnabled reports whether def accepts new processes and is listed on
+// the home page. Workflows are enabled unless they set `enabled: false`;
+// existing processes of a disabled workflow stay viewable.
+func workflowEnabled(def WorkflowDef) bool {
+	return def.Enabled == nil || *def.Enabled
 }
 
 type WorkflowStep struct {
@@ -66,6 +92,45 @@ type WorkflowSub struct {
 	InputType string                 `bson:"inputType" yaml:"inputType"`
 	Schema    map[string]interface{} `bson:"schema,omitempty" yaml:"schema,omitempty"`
 	UISchema  map[string]interface{} `bson:"uiSchema,omitempty" yaml:"uiSchema,omitempty"`
+	DependsOn []string               `bson:"dependsOn,omitempty" yaml:"dependsOn,omitempty"`
+	Min       *float64               `bson:"min,omitempty" yaml:"min,omitempty"`
+	Max       *float64               `bson:"max,omitempty" yaml:"max,omitempty"`
+	Step      *float64               `bson:"step,omitempty" yaml:"step,omitempty"`
+
+	// VisibleWhen hides the substep unless an earlier answer matches, e.g.
+	// `1.1.inspected == "yes"`. Hidden substeps count as skipped.
+	VisibleWhen string `bson:"visibleWhen,omitempty" yaml:"visibleWhen,omitempty"`
+
+	// AssignedUserIDs narrows a substep to named users on top of the role
+	// check. Entries are identity user IDs or actor IDs ("appwrite:<id>").
+	AssignedUserIDs []string `bson:"assignedUserIds,omitempty" yaml:"assignedUserIds,omitempty"`
+
+	// RejectRoles limits which reviewer roles may send this substep back for
+	// rework. Empty means any role of a later substep that is available.
+	RejectRoles []string `bson:"rejectRoles,omitempty" yaml:"rejectRoles,omitempty"`
+
+	// Help, Placeholder and HelpBySchema guide whoever fills the form
+	// (substep_help.go). Placeholder is an example answer; HelpBySchema
+	// holds per-field notes keyed by formata schema property.
+	Help         string            `bson:"help,omitempty" yaml:"help,omitempty"`
+	Placeholder  string            `bson:"placeholder,omitempty" yaml:"placeholder,omitempty"`
+	HelpBySchema map[string]string `bson:"helpBySchema,omitempty" yaml:"helpBySchema,omitempty"`
+
+	// RequireConfirmation makes completion need an explicit confirm field
+	// (completion_confirmation.go), for substeps that cannot be undone.
+	RequireConfirmation bool `bson:"requireConfirmation,omitempty" yaml:"requireConfirmation,omitempty"`
+
+	// Options are the choices of an inputType=multiselect substep
+	// (multiselect.go).
+	Options []SubstepOption `bson:"options,omitempty" yaml:"options,omitempty"`
+
+	// AllowedHosts restricts the links an inputType=url substep accepts
+	// to these hostnames and their subdomains (url_input.go).
+	AllowedHosts []string `bson:"allowedHosts,omitempty" yaml:"allowedHosts,omitempty"`
+
+	// Optional substeps are not needed for completion when the workflow
+	// sets doneWhen: required.
+	Optional bool `bson:"optional,omitempty" yaml:"optional,omitempty"`
 }
 
 type Process struct {
@@ -74,12 +139,26 @@ type Process struct {
 	WorkflowKey   string                     `bson:"workflowKey,omitempty"`
 	Name          string                     `bson:"name,omitempty"`
 	CreatedAt     time.Time                  `bson:"createdAt"`
+	UpdatedAt     time.Time                  `bson:"updatedAt,omitempty"`
 	CreatedBy     string                     `bson:"createdBy"`
 	Status        string                     `bson:"status"`
 	Progress      map[string]ProcessStep     `bson:"progress"`
 	Overrides     map[string]SubstepOverride `bson:"substepOverrides,omitempty"`
 	DPP           *ProcessDPP                `bson:"dpp,omitempty"`
 	Termination   *ProcessTermination        `bson:"termination,omitempty"`
+	Participants  []string                   `bson:"participants,omitempty"`
+	DeletedAt     *time.Time                 `bson:"deletedAt,omitempty"`
+	SearchText    []string                   `bson:"searchText,omitempty"`
+	Rejections    []ProcessRejection         `bson:"rejections,omitempty"`
+	// Code is the human-friendly id (e.g. PRC-2026-000123) of processes
+	// started in a workflow with processCodePrefix; see process_code.go.
+	Code string `bson:"code,omitempty"`
+	// Metadata holds operator-supplied key/values (ticket id, customer ref)
+	// outside the notarized workflow; see process_metadata.go.
+	Metadata map[string]string `bson:"metadata,omitempty"`
+	// CompletedNotifiedAt is set once the process.completed webhook has been
+	// claimed for delivery.
+	CompletedNotifiedAt *time.Time `bson:"completedNotifiedAt,omitempty"`
 }
 
 type SubstepOverride struct {
@@ -95,10 +174,13 @@ type SubstepOverride struct {
 }
 
 type ProcessDPP struct {
-	GTIN        string    `bson:"gtin"`
-	Lot         string    `bson:"lot"`
-	Serial      string    `bson:"serial"`
-	GeneratedAt time.Time `bson:"generatedAt"`
+	GTIN               string    `bson:"gtin"`
+	Lot                string    `bson:"lot"`
+	Serial             string    `bson:"serial"`
+	ProductName        string    `bson:"productName,omitempty"`
+	ProductDescription string    `bson:"productDescription,omitempty"`
+	OwnerName          string    `bson:"ownerName,omitempty"`
+	GeneratedAt        time.Time `bson:"generatedAt"`
 }
 
 type ProcessTermination struct {
@@ -114,6 +196,23 @@ type ProcessStep struct {
 	DoneAt      *time.Time             `bson:"doneAt,omitempty"`
 	DoneBy      *Actor                 `bson:"doneBy,omitempty"`
 	Data        map[string]interface{} `bson:"data,omitempty"`
+	Amendments  []ProcessAmendment     `bson:"amendments,omitempty"`
+	// DurationSeconds is how long the substep waited between becoming
+	// available and being completed; nil for completions recorded before it
+	// was tracked.
+	DurationSeconds *int64 `bson:"durationSeconds,omitempty"`
+}
+
+// ProcessAmendment is a correction notarized on top of a completed substep.
+// PreviousDigest links it to the value it supersedes, forming a chain back to
+// the original submission.
+type ProcessAmendment struct {
+	Data           map[string]interface{} `bson:"data"`
+	Digest         string                 `bson:"digest"`
+	PreviousDigest string                 `bson:"previousDigest"`
+	Reason         string                 `bson:"reason"`
+	AmendedAt      time.Time              `bson:"amendedAt"`
+	AmendedBy      *Actor                 `bson:"amendedBy,omitempty"`
 }
 
 type Actor struct {
@@ -132,6 +231,9 @@ type Notarization struct {
 	Actor      Actor                  `bson:"actor"`
 	CreatedAt  time.Time              `bson:"createdAt"`
 	FakeNotary FakeNotary             `bson:"fakeNotary"`
+	// AmendsDigest is set on amendment notarizations and references the
+	// digest of the value being superseded.
+	AmendsDigest string `bson:"amendsDigest,omitempty"`
 }
 
 type FakeNotary struct {
@@ -157,8 +259,55 @@ type Server struct {
 	viteDevServer  string
 	enforceAuth    bool
 	formataArchURL string
+	startLimiter   *rateLimiter
+	// readOnly caches the store's read-only flag; readOnlyCheckedAt is when
+	// it was last loaded (unix nanoseconds).
+	readOnly          atomic.Bool
+	readOnlyCheckedAt atomic.Int64
+	// trustedProxies are the TRUSTED_PROXIES peers whose forwarded headers
+	// are believed.
+	trustedProxies []netip.Prefix
+	// defaultWorkflow pins the workflow selected when a request does not name
+	// one (DEFAULT_WORKFLOW_KEY).
+	defaultWorkflow string
+	// docsTitle and docsFaviconURL override the /docs/ page title and icon
+	// (DOCS_TITLE, DOCS_FAVICON_URL).
+	docsTitle      string
+	docsFaviconURL string
+	// staticDir is the built web assets directory served under /static/;
+	// empty means ../web/dist.
+	staticDir string
+	// exportSigner signs notarized.json when NOTARIZED_SIGNING_KEY is set.
+	exportSigner *exportSigner
+	// scanner checks completion uploads before they are stored
+	// (ATTACHMENT_SCANNER); nil means no scanning.
+	scanner AttachmentScanner
+	// simulations holds the in-memory processes of workflow previews.
+	simulations simulationStore
+	// webhookClient delivers workflow completion webhooks; nil uses a client
+	// with completionWebhookTimeout.
+	webhookClient *http.Client
+	// sseHeartbeat is how often idle SSE streams get a keepalive comment
+	// (SSE_HEARTBEAT_SECONDS); zero uses sseHeartbeatDefault.
+	sseHeartbeat time.Duration
+	// formataMaxDepth and formataMaxElements bound completion payloads
+	// (FORMATA_PAYLOAD_MAX_DEPTH / FORMATA_PAYLOAD_MAX_ELEMENTS); zero uses
+	// the defaults in payload_limits.go.
+	formataMaxDepth    int
+	formataMaxElements int
+	// dashboardLimit caps the JSON dashboard lists (DASHBOARD_LIST_LIMIT);
+	// zero uses dashboardListLimitDefault and a negative value disables it.
+	dashboardLimit int
+	// homeSingleWorkflowRedirect sends /my straight to the only enabled
+	// workflow (HOME_SINGLE_WORKFLOW_REDIRECT).
+	homeSingleWorkflowRedirect bool
+	// loginRedirectPrefixes restricts the `next` paths safeNextPath follows
+	// (LOGIN_REDIRECT_ALLOWED_PREFIXES); empty allows any local path.
+	loginRedirectPrefixes []string
+	// roleHolderCounts caches the identity role counts behind
+	// unassignedWorkflowRoleWarnings.
+	roleHolderCounts roleHolderCountCache
 }
-
 type SSEHub struct {
 	mu     sync.Mutex
 	stream map[string]map[chan string]struct{}
@@ -181,10 +330,22 @@ type NotarizedSubstep struct {
 	DoneAt                string                 `json:"done_at,omitempty"`
 	DoneBy                string                 `json:"done_by,omitempty"`
 	DoneRole              string                 `json:"done_role,omitempty"`
+	DurationSeconds       *int64                 `json:"duration_seconds,omitempty"`
 	Payload               map[string]interface{} `json:"payload,omitempty"`
 	Digest                string                 `json:"digest,omitempty"`
 	Attachment            *NotarizedAttachment   `json:"attachment,omitempty"`
 	LocalAdaptationReason string                 `json:"local_adaptation_reason,omitempty"`
+	Amendments            []NotarizedAmendment   `json:"amendments,omitempty"`
+}
+
+type NotarizedAmendment struct {
+	Payload        map[string]interface{} `json:"payload"`
+	Digest         string                 `json:"digest"`
+	PreviousDigest string                 `json:"previous_digest"`
+	Reason         string                 `json:"reason"`
+	AmendedAt      string                 `json:"amended_at,omitempty"`
+	AmendedBy      string                 `json:"amended_by,omitempty"`
+	AmendedRole    string                 `json:"amended_role,omitempty"`
 }
 
 type NotarizedStep struct {
@@ -204,13 +365,89 @@ type MerkleTree struct {
 	Root   string       `json:"root"`
 }
 
+// MerkleRootPreview is the current Merkle root of a process and how many of
+// its substeps are done. The root changes every time a substep completes.
+type MerkleRootPreview struct {
+	Root         string `json:"root"`
+	SubstepCount int    `json:"substep_count"`
+	DoneCount    int    `json:"done_count"`
+}
+
 type NotarizedProcessExport struct {
 	ProcessID   string                       `json:"process_id"`
 	CreatedAt   string                       `json:"created_at"`
+	CreatedBy   string                       `json:"created_by,omitempty"`
 	Status      string                       `json:"status"`
 	Termination *NotarizedProcessTermination `json:"termination,omitempty"`
 	Steps       []NotarizedStep              `json:"steps"`
 	Merkle      MerkleTree                   `json:"merkle"`
+	Signature   *NotarizedSignature          `json:"signature,omitempty"`
+}
+
+type ProcessListResponse struct {
+	WorkflowKey string            `json:"workflow_key"`
+	Processes   []ProcessListItem `json:"processes"`
+}
+
+// StreamDashboardResponse is the JSON form of the stream dashboard: the
+// substeps the caller can act on now plus the active and done instances.
+// TodoActions and ActiveProcesses stop at dashboardListLimit(); the totals
+// and Truncated flag say how many were left out.
+type StreamDashboardResponse struct {
+	WorkflowKey     string            `json:"workflow_key"`
+	TodoActions     []TodoAction      `json:"todo_actions"`
+	TodoTotal       int               `json:"todo_total"`
+	ActiveProcesses []ProcessListItem `json:"active_processes"`
+	ActiveTotal     int               `json:"active_total"`
+	DoneProcesses   []ProcessListItem `json:"done_processes"`
+	Truncated       bool              `json:"truncated"`
+}
+
+// StreamDashboardCounts carries only the totals of StreamDashboardResponse,
+// for nav badges that do not need the lists.
+type StreamDashboardCounts struct {
+	Todo   int `json:"todo"`
+	Active int `json:"active"`
+	Done   int `json:"done"`
+}
+
+// TodoAction is one substep the caller can complete now. AvailableAt is
+// when its prerequisites were met; WorkflowKey/WorkflowName are only set in
+// the cross-workflow dashboard.
+type TodoAction struct {
+	ProcessID    string   `json:"process_id"`
+	ProcessName  string   `json:"process_name,omitempty"`
+	WorkflowKey  string   `json:"workflow_key,omitempty"`
+	WorkflowName string   `json:"workflow_name,omitempty"`
+	SubstepID    string   `json:"substep_id"`
+	Title        string   `json:"title"`
+	Roles        []string `json:"roles"`
+	AvailableAt  string   `json:"available_at,omitempty"`
+	URL          string   `json:"url"`
+}
+
+type ProcessDPPResponse struct {
+	ProcessID   string `json:"process_id"`
+	GTIN        string `json:"gtin"`
+	Lot         string `json:"lot"`
+	Serial      string `json:"serial"`
+	DigitalLink string `json:"digital_link"`
+	GeneratedAt string `json:"generated_at"`
+	DPPProductInfo
+}
+
+type ProcessListItem struct {
+	ProcessID    string `json:"process_id"`
+	Name         string `json:"name,omitempty"`
+	WorkflowKey  string `json:"workflow_key,omitempty"`
+	WorkflowName string `json:"workflow_name,omitempty"`
+	CreatedAt    string `json:"created_at"`
+	UpdatedAt    string `json:"updated_at"`
+	CreatedBy    string `json:"created_by,omitempty"`
+	Status       string `json:"status"`
+	URL          string `json:"url"`
+	// Metadata is Process.Metadata; it is not part of the notarized record.
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
 type NotarizedProcessTermination struct {
@@ -233,12 +470,13 @@ type User struct {
 }
 
 type RuntimeConfig struct {
-	Workflow      WorkflowDef            `yaml:"workflow"`
-	Organizations []WorkflowOrganization `yaml:"organizations"`
-	Roles         []WorkflowRole         `yaml:"roles"`
-	Departments   []Department           `yaml:"departments"`
-	Users         []User                 `yaml:"users"`
-	DPP           DPPConfig              `yaml:"dpp"`
+	Workflow      WorkflowDef                  `yaml:"workflow"`
+	Organizations []WorkflowOrganization       `yaml:"organizations"`
+	Roles         []WorkflowRole               `yaml:"roles"`
+	Departments   []Department                 `yaml:"departments"`
+	Users         []User                       `yaml:"users"`
+	DPP           DPPConfig                    `yaml:"dpp"`
+	Labels        map[string]map[string]string `yaml:"labels,omitempty"`
 }
 
 type WorkflowOrganization struct {
@@ -253,15 +491,21 @@ type WorkflowRole struct {
 }
 
 type DPPConfig struct {
-	Enabled            bool   `yaml:"enabled"`
-	GTIN               string `yaml:"gtin"`
-	LotInputKey        string `yaml:"lotInputKey"`
-	LotDefault         string `yaml:"lotDefault"`
-	SerialInputKey     string `yaml:"serialInputKey"`
-	SerialStrategy     string `yaml:"serialStrategy"`
-	ProductName        string `yaml:"productName"`
-	ProductDescription string `yaml:"productDescription"`
-	OwnerName          string `yaml:"ownerName"`
+	Enabled                    bool   `yaml:"enabled"`
+	GTIN                       string `yaml:"gtin"`
+	GTINComputeCheckDigit      bool   `yaml:"gtinComputeCheckDigit"`
+	LotInputKey                string `yaml:"lotInputKey"`
+	LotDefault                 string `yaml:"lotDefault"`
+	SerialInputKey             string `yaml:"serialInputKey"`
+	SerialStrategy             string `yaml:"serialStrategy"`
+	RequireLot                 bool   `yaml:"requireLot"`
+	RequireSerialInput         bool   `yaml:"requireSerialInput"`
+	ProductName                string `yaml:"productName"`
+	ProductNameInputKey        string `yaml:"productNameInputKey"`
+	ProductDescription         string `yaml:"productDescription"`
+	ProductDescriptionInputKey string `yaml:"productDescriptionInputKey"`
+	OwnerName                  string `yaml:"ownerName"`
+	OwnerNameInputKey          string `yaml:"ownerNameInputKey"`
 }
 
 type RoleMeta struct {
@@ -281,6 +525,7 @@ type PageBase struct {
 	ShowOrgsLink    bool
 	ShowMyOrgLink   bool
 	ShowLogout      bool
+	ReadOnly        bool
 }
 
 type PublicCatalogResponse struct {
@@ -310,6 +555,8 @@ type WorkflowPickerView struct {
 
 type HomeWorkflowPickerView struct {
 	WorkflowPickerView
+	// NoRolesNotice replaces the stream grid for users without roles.
+	NoRolesNotice string
 }
 
 type PaginationLink struct {
@@ -332,6 +579,7 @@ type ProcessStatusGroup struct {
 	EmptyMessage        string
 	PaginationAriaLabel string
 	PanelID             string
+	URL                 string
 	Sort                string
 	SortFields          []QueryInput
 	TotalCount          int
@@ -350,14 +598,27 @@ type ProcessStatusGroup struct {
 
 type HomeView struct {
 	PageBase
-	Breadcrumbs         BreadcrumbsView
-	WorkflowDescription string
-	Error               string
-	Sort                string
-	StatusFilter        string
-	FilterOptions       []ProcessStatusGroup
-	ProcessGroups       []ProcessStatusGroup
-	Preview             StreamInstanceDetailView
+	Breadcrumbs             BreadcrumbsView
+	WorkflowDescription     string
+	WorkflowDescriptionHTML template.HTML
+	Error                   string
+	Sort                    string
+	StatusFilter            string
+	DateFrom                string
+	DateTo                  string
+	DateFields              []QueryInput
+	DateClearURL            string
+	FilterOptions           []ProcessStatusGroup
+	ProcessGroups           []ProcessStatusGroup
+	Preview                 StreamInstanceDetailView
+	CanStart                bool
+	// StartURL is the creation form of a workflow with startFields; empty
+	// keeps the name-only dialog.
+	StartURL     string
+	RoleWarnings []string
+	// ConfigProblems lists broken workflow references for admins; when set
+	// it replaces Error in the configuration banner.
+	ConfigProblems []WorkflowRefProblemView
 }
 
 type LoginView struct {
@@ -367,6 +628,16 @@ type LoginView struct {
 	Error        string
 	Confirmation string
 	ShowSignup   bool
+	// Brand themes the page for the organization named by ?org=; nil keeps
+	// the generic login page.
+	Brand *OrgBrandView
+}
+
+// OrgBrandView is the organization branding shown on pre-login pages.
+type OrgBrandView struct {
+	Slug    string
+	Name    string
+	LogoURL string
 }
 
 type SignupView struct {
@@ -380,6 +651,7 @@ type InviteView struct {
 	Token string
 	Email string
 	Org   string
+	Brand *OrgBrandView
 	Roles []string
 	Error string
 }
@@ -407,26 +679,27 @@ type AboutView struct {
 
 type PlatformAdminView struct {
 	PageBase
-	Breadcrumbs              BreadcrumbsView
-	SearchQuery              string
-	CurrentPage              int
-	TotalPages               int
-	PageNumbers              []int
-	HasPreviousPage          bool
-	HasNextPage              bool
-	PreviousPage             int
-	NextPage                 int
-	MatchedOrganizations     int
-	Organizations            []PlatformAdminOrganizationRow
-	InviteLink               string
-	Confirmation             string
-	OrganizationError        string
-	OrganizationDialogAction string
-	OrganizationDialogSlug   string
-	OrganizationDialogName   string
-	InviteError              string
-	InviteDialogEmail        string
-	Error                    string
+	Breadcrumbs               BreadcrumbsView
+	SearchQuery               string
+	CurrentPage               int
+	TotalPages                int
+	PageNumbers               []int
+	HasPreviousPage           bool
+	HasNextPage               bool
+	PreviousPage              int
+	NextPage                  int
+	MatchedOrganizations      int
+	Organizations             []PlatformAdminOrganizationRow
+	InviteLink                string
+	Confirmation              string
+	OrganizationError         string
+	OrganizationDialogAction  string
+	OrganizationDialogSlug    string
+	OrganizationDialogName    string
+	OrganizationDialogDomains string
+	InviteError               string
+	InviteDialogEmail         string
+	Error                     string
 }
 
 type PlatformAdminOrganizationRow struct {
@@ -446,6 +719,7 @@ type PlatformAdminErrors struct {
 	OrgSlug      string
 	OrgName      string
 	InviteEmail  string
+	EmailDomains string
 	SearchQuery  string
 	Page         int
 }
@@ -456,6 +730,7 @@ type OrgAdminView struct {
 	ActivePanel            string
 	Organization           Organization
 	OrganizationLogoURL    string
+	AllowedEmailDomains    string
 	NeedsOrganizationSetup bool
 	OrganizationError      string
 	RoleError              string
@@ -469,11 +744,26 @@ type OrgAdminView struct {
 	RolePills              []OrgAdminRoleOption
 	RoleRows               []OrgAdminRoleRow
 	Users                  []OrgAdminUserRow
+	UserList               OrgAdminUserListView
 	Invites                []OrgAdminInviteRow
 	InviteLink             string
 	Error                  string
 }
 
+// OrgAdminUserListView carries the search and paging state of the members
+// panel. Users on OrgAdminView only holds the current page.
+type OrgAdminUserListView struct {
+	Query           string
+	MatchedCount    int
+	CurrentPage     int
+	TotalPages      int
+	PageLinks       []PaginationLink
+	HasPreviousPage bool
+	HasNextPage     bool
+	PreviousURL     string
+	NextURL         string
+}
+
 type OrgAdminErrors struct {
 	Organization string
 	Role         string
@@ -506,6 +796,9 @@ type OrgAdminUserRow struct {
 	Activated   bool
 	IsOrgAdmin  bool
 	RoleOptions []OrgAdminRoleOption
+	// RolesVersion is identityRolesVersion of the user when the page was
+	// rendered; set_roles refuses the update when it no longer matches.
+	RolesVersion string
 }
 
 type OrgAdminInviteRow struct {
@@ -533,6 +826,7 @@ func roleSlugsKey(roleSlugs []string) string {
 
 type WorkflowRefValidationError struct {
 	Messages []string
+	Problems []WorkflowRefProblem
 }
 
 func (e *WorkflowRefValidationError) Error() string {
@@ -544,36 +838,60 @@ func (e *WorkflowRefValidationError) Error() string {
 
 type ProcessPageView struct {
 	PageBase
-	Breadcrumbs  BreadcrumbsView
-	ProcessID    string
+	Breadcrumbs BreadcrumbsView
+	ProcessID   string
+	// ProcessCode is the human code shown instead of ProcessID when set.
+	ProcessCode  string
 	InstanceName string
+	CreatedBy    string
 	Status       string
 	StatusLabel  string
-	Detail       StreamInstanceDetailView
-	DPPURL       string
-	DPPGS1       string
-	Attachments  []ProcessDownloadAttachment
+	// Metadata lists Process.Metadata by key; it is hidden on shared pages.
+	// MetadataURL is where the page's metadata form posts; empty on shared
+	// and preview pages.
+	Metadata    []ProcessMetadataEntry
+	MetadataURL string
+	Detail      StreamInstanceDetailView
+	DPPURL      string
+	DPPGS1      string
+	Attachments []ProcessDownloadAttachment
+	// AttachmentTotalBytes sums Attachments; AttachmentsLarge is set when it
+	// exceeds ATTACHMENT_ZIP_WARN_BYTES so the page can warn before the zip.
+	AttachmentCount      int
+	AttachmentTotalBytes int64
+	AttachmentTotalSize  string
+	AttachmentsLarge     bool
+	// Shared marks the anonymous /share/:token rendering; SharedNotarizedURL
+	// is set when the link also exposes notarized.json.
+	Shared             bool
+	SharedNotarizedURL string
+	// Simulation marks a workflow preview (simulation.go) whose process only
+	// lives in memory.
+	Simulation bool
 }
 
 type ProcessDownloadAttachment struct {
 	SubstepID string
 	Filename  string
 	URL       string
+	SizeBytes int64
 }
 
 type DPPPageView struct {
 	PageBase
-	ProcessID    string
-	DigitalLink  string
-	GTIN         string
-	Lot          string
-	Serial       string
-	IssuedAt     string
-	Workflow     WorkflowDef
-	Traceability []TimelineStep
-	Integrity    DPPIntegrityView
-	Export       NotarizedProcessExport
-	Termination  *StreamTerminationDetailsView
+	ProcessID               string
+	DigitalLink             string
+	GTIN                    string
+	Lot                     string
+	Serial                  string
+	IssuedAt                string
+	Product                 DPPProductInfo
+	Workflow                WorkflowDef
+	WorkflowDescriptionHTML template.HTML
+	Traceability            []TimelineStep
+	Integrity               DPPIntegrityView
+	Export                  NotarizedProcessExport
+	Termination             *StreamTerminationDetailsView
 }
 
 type ProcessTerminationView struct {
@@ -643,13 +961,10 @@ var rolePaletteKeys = []string{
 }
 
 func defaultRolePaletteFromInput(raw string) string {
-	normalized := strings.ToLower(strings.Join(strings.Fields(strings.TrimSpace(raw)), " "))
-	if normalized == "" || len(rolePaletteKeys) == 0 {
-		return "red"
+	if palette := hashRolePalette(rolePaletteKeys, raw); palette != "" {
+		return palette
 	}
-	hasher := fnv.New32a()
-	_, _ = hasher.Write([]byte(normalized))
-	return rolePaletteKeys[int(hasher.Sum32()%uint32(len(rolePaletteKeys)))]
+	return "red"
 }
 
 func rolePaletteKeyFromStyle(color, fallbackName string) string {
@@ -683,6 +998,20 @@ type workflowContextValue struct {
 
 func main() {
 	ctx := context.Background()
+	if err := validateCookieConfig(); err != nil {
+		log.Fatal(err)
+	}
+	trustedProxies, err := trustedProxiesFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := loadRolePalette(); err != nil {
+		log.Fatal(err)
+	}
+	cors, err := corsPolicyFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
 	mongoURI := envOr("MONGODB_URI", "mongodb://localhost:27017")
 	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
 	if err != nil {
@@ -706,10 +1035,10 @@ func main() {
 
 	server := &Server{
 		mongo:          client,
-		store:          &MongoStore{db: db},
+		store:          &MongoStore{db: db, retry: mongoRetryConfigFromEnv()},
 		identity:       NewAppwriteIdentity(envOr("APPWRITE_ENDPOINT", "http://appwrite/v1"), strings.TrimSpace(os.Getenv("APPWRITE_PROJECT_ID")), strings.TrimSpace(os.Getenv("APPWRITE_API_KEY")), http.DefaultClient),
 		tmpl:           tmpl,
-		authorizer:     NewCerbosAuthorizer(envOr("CERBOS_URL", "http://localhost:3592"), http.DefaultClient, time.Now),
+		authorizer:     NewResilientAuthorizer(NewCerbosAuthorizer(envOr("CERBOS_URL", "http://localhost:3592"), http.DefaultClient, time.Now), resilientAuthorizerConfigFromEnv(), time.Now),
 		sse:            newSSEHub(),
 		now:            time.Now,
 		workflowDefID:  primitive.NewObjectID(),
@@ -717,14 +1046,58 @@ func main() {
 		viteDevServer:  strings.TrimRight(strings.TrimSpace(os.Getenv("VITE_DEV_SERVER")), "/"),
 		enforceAuth:    true,
 		formataArchURL: strings.TrimRight(strings.TrimSpace(os.Getenv("FORMATA_ARCH_URL")), "/"),
+		startLimiter:   newRateLimiter(processCreateLimitPerHour(), time.Hour),
+		trustedProxies: trustedProxies,
+	}
+	if boolEnvOr("READ_ONLY", false) {
+		if err := server.setReadOnly(ctx, true); err != nil {
+			log.Fatal(err)
+		}
+	}
+	server.defaultWorkflow = strings.TrimSpace(os.Getenv("DEFAULT_WORKFLOW_KEY"))
+	server.docsTitle = strings.TrimSpace(os.Getenv("DOCS_TITLE"))
+	server.docsFaviconURL = strings.TrimSpace(os.Getenv("DOCS_FAVICON_URL"))
+	server.exportSigner = exportSignerFromEnv()
+	server.process = &ProcessService{store: server.store, now: server.now, signer: server.exportSigner, webhookClient: server.webhookClient}
+	server.sseHeartbeat = sseHeartbeatInterval()
+	server.formataMaxDepth = formataPayloadMaxDepth()
+	server.formataMaxElements = formataPayloadMaxElements()
+	server.dashboardLimit = dashboardListLimitFromEnv()
+	server.homeSingleWorkflowRedirect = boolEnvOr("HOME_SINGLE_WORKFLOW_REDIRECT", true)
+	server.loginRedirectPrefixes = splitCSVEnv("LOGIN_REDIRECT_ALLOWED_PREFIXES")
+	scanner, err := attachmentScannerFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	server.scanner = scanner
+	if err := server.validateDefaultWorkflowKey(); err != nil {
+		log.Fatal(err)
 	}
-	server.process = &ProcessService{store: server.store, now: server.now}
 	if err := bootstrapFormataBuilderStreams(ctx, server.store, configDir, server.now); err != nil {
 		log.Fatal(err)
 	}
 	if err := server.bootstrapPlatformAdminIdentity(ctx); err != nil {
 		log.Fatal(err)
 	}
+	if err := server.store.BackfillProcessParticipants(ctx); err != nil {
+		log.Printf("failed to backfill process participants: %v", err)
+	}
+	if err := server.store.BackfillProcessSearchText(ctx); err != nil {
+		log.Printf("failed to backfill process search text: %v", err)
+	}
+	if err := server.store.EnsureProcessCodeIndex(ctx); err != nil {
+		log.Printf("failed to create process code index: %v", err)
+	}
+	if err := server.store.EnsureProcessEventsIndex(ctx); err != nil {
+		log.Printf("failed to create process events index: %v", err)
+	}
+	if err := server.store.EnsureShareLinkIndex(ctx); err != nil {
+		log.Printf("failed to create share link index: %v", err)
+	}
+	if err := server.store.EnsureSubstepDraftIndex(ctx); err != nil {
+		log.Printf("failed to create substep draft index: %v", err)
+	}
+	go server.runRetentionSweeper(ctx, retentionSweepConfigFromEnv())
 
 	mux := server.newMux()
 
@@ -734,7 +1107,7 @@ func main() {
 		log.Fatal(err)
 	}
 	log.Printf("server listening on %s", addr)
-	if err := http.Serve(listener, logRequests(mux)); err != nil {
+	if err := http.Serve(listener, server.logRequests(withCORS(cors, server.withReadOnlyGuard(mux)))); err != nil {
 		log.Fatal(err)
 	}
 }
@@ -788,6 +1161,18 @@ func intEnvOr(key string, fallback int) int {
 	return value
 }
 
+const sseHeartbeatDefault = 20 * time.Second
+
+// sseHeartbeatInterval reads SSE_HEARTBEAT_SECONDS; values below one second
+// fall back to sseHeartbeatDefault.
+func sseHeartbeatInterval() time.Duration {
+	seconds := intEnvOr("SSE_HEARTBEAT_SECONDS", int(sseHeartbeatDefault/time.Second))
+	if seconds <= 0 {
+		return sseHeartbeatDefault
+	}
+	return time.Duration(seconds) * time.Second
+}
+
 func sessionTTLDays() int {
 	days := intEnvOr("SESSION_TTL_DAYS", 30)
 	if days <= 0 {
@@ -796,7 +1181,7 @@ func sessionTTLDays() int {
 	return days
 }
 
-func safeNextPath(r *http.Request, fallback string) string {
+func (s *Server) safeNextPath(r *http.Request, fallback string) string {
 	next := strings.TrimSpace(r.URL.Query().Get("next"))
 	if r.Method == http.MethodPost {
 		_ = r.ParseForm()
@@ -804,20 +1189,80 @@ func safeNextPath(r *http.Request, fallback string) string {
 			next = formNext
 		}
 	}
-	if next == "" || !strings.HasPrefix(next, "/") {
+	if !isSafeNextPath(next, s.loginRedirectPrefixes) {
 		return fallback
 	}
 	return next
 }
 
-func shouldSecureCookie(r *http.Request) bool {
-	if boolEnvOr("COOKIE_SECURE", false) {
+// isSafeNextPath accepts only same-origin paths: a single leading "/", no
+// backslash and no control characters. Browsers resolve "//host" and
+// "/\host" (and "/\t/host", since they strip tabs and newlines) to another
+// host. When allowedPrefixes is non-empty, next must also start with one of
+// them.
+func isSafeNextPath(next string, allowedPrefixes []string) bool {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.Contains(next, "\\") {
+		return false
+	}
+	for _, r := range next {
+		if unicode.IsControl(r) {
+			return false
+		}
+	}
+	if len(allowedPrefixes) == 0 {
 		return true
 	}
-	if strings.EqualFold(strings.TrimSpace(r.Header.Get("X-Forwarded-Proto")), "https") {
+	for _, prefix := range allowedPrefixes {
+		if strings.HasPrefix(next, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) shouldSecureCookie(r *http.Request) bool {
+	if boolEnvOr("COOKIE_SECURE", false) {
 		return true
 	}
-	return r.TLS != nil
+	return s.requestIsHTTPS(r)
+}
+
+func cookieSameSite() (http.SameSite, error) {
+	raw := strings.ToLower(strings.TrimSpace(os.Getenv("COOKIE_SAMESITE")))
+	switch raw {
+	case "", "lax":
+		return http.SameSiteLaxMode, nil
+	case "strict":
+		return http.SameSiteStrictMode, nil
+	case "none":
+		return http.SameSiteNoneMode, nil
+	default:
+		return http.SameSiteLaxMode, fmt.Errorf("unsupported COOKIE_SAMESITE %q (allowed: lax, strict, none)", raw)
+	}
+}
+
+func cookieDomain() string {
+	return strings.TrimSpace(os.Getenv("COOKIE_DOMAIN"))
+}
+
+// validateCookieConfig rejects COOKIE_SAMESITE=none unless COOKIE_SECURE is
+// enabled, since browsers drop SameSite=None cookies that are not Secure.
+func validateCookieConfig() error {
+	sameSite, err := cookieSameSite()
+	if err != nil {
+		return err
+	}
+	if sameSite == http.SameSiteNoneMode && !boolEnvOr("COOKIE_SECURE", false) {
+		return errors.New("COOKIE_SAMESITE=none requires COOKIE_SECURE=true")
+	}
+	return nil
+}
+
+func (s *Server) applyCookiePolicy(r *http.Request, cookie *http.Cookie) {
+	sameSite, _ := cookieSameSite()
+	cookie.SameSite = sameSite
+	cookie.Domain = cookieDomain()
+	cookie.Secure = sameSite == http.SameSiteNoneMode || s.shouldSecureCookie(r)
 }
 
 const (
@@ -825,17 +1270,17 @@ const (
 	noticeResetRequestSent     = "reset_request_sent"
 )
 
-func clearCookie(w http.ResponseWriter, r *http.Request, name string) {
-	http.SetCookie(w, &http.Cookie{
+func (s *Server) clearCookie(w http.ResponseWriter, r *http.Request, name string) {
+	cookie := &http.Cookie{
 		Name:     strings.TrimSpace(name),
 		Value:    "",
 		Path:     "/",
 		Expires:  time.Unix(0, 0),
 		MaxAge:   -1,
 		HttpOnly: true,
-		SameSite: http.SameSiteLaxMode,
-		Secure:   shouldSecureCookie(r),
-	})
+	}
+	s.applyCookiePolicy(r, cookie)
+	http.SetCookie(w, cookie)
 }
 
 func requestNotice(r *http.Request) string {
@@ -849,6 +1294,8 @@ func loginNoticeMessage(code string) string {
 	switch strings.TrimSpace(code) {
 	case noticePasswordResetSuccess:
 		return "Password reset successfully. Now you can enter with your new credentials."
+	case noticeMagicLinkSent:
+		return "If the account exists, a sign-in link has been sent."
 	default:
 		return ""
 	}
@@ -1110,9 +1557,23 @@ func (s *Server) currentUser(r *http.Request) (*AccountUser, *IdentitySession, e
 	if err != nil {
 		return nil, nil, err
 	}
+	if isRevokedIdentityStatus(identityUser.Status) {
+		_ = s.identity.DeleteSession(r.Context(), session.Secret)
+		return nil, nil, ErrIdentityUnauthorized
+	}
 	return s.accountUserFromIdentity(r.Context(), identityUser), session, nil
 }
 
+// isRevokedIdentityStatus reports whether a user may no longer hold a
+// session: sessions outliving a deletion or block are dropped on next use.
+func isRevokedIdentityStatus(status string) bool {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "deleted", "disabled":
+		return true
+	}
+	return false
+}
+
 func (s *Server) requireAuthenticatedPage(w http.ResponseWriter, r *http.Request) (*AccountUser, *IdentitySession, bool) {
 	if !s.enforceAuth {
 		return &AccountUser{}, nil, true
@@ -1232,6 +1693,19 @@ func attachmentMaxBytes() int64 {
 	return value
 }
 
+func attachmentZipWarnBytes() int64 {
+	const defaultWarnBytes = int64(100 * 1024 * 1024)
+	raw := strings.TrimSpace(os.Getenv("ATTACHMENT_ZIP_WARN_BYTES"))
+	if raw == "" {
+		return defaultWarnBytes
+	}
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || value <= 0 {
+		return defaultWarnBytes
+	}
+	return value
+}
+
 func completionFormMaxBytes() int64 {
 	const overhead = int64(1 << 20)
 	maxAttachmentBytes := attachmentMaxBytes()
@@ -1254,11 +1728,11 @@ func organizationLogoMaxBytes() int64 {
 	return value
 }
 
-func logRequests(next http.Handler) http.Handler {
+func (s *Server) logRequests(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 		next.ServeHTTP(w, r)
-		log.Printf("%s %s %s", r.Method, r.URL.Path, time.Since(start))
+		log.Printf("%s %s %s %s", s.clientIP(r), r.Method, r.URL.Path, time.Since(start))
 	})
 }
 
@@ -1299,6 +1773,7 @@ func (s *Server) pageBase(body, workflowKey, workflowName string) PageBase {
 		ViteDevServer: s.viteDevServer,
 		WorkflowKey:   strings.TrimSpace(workflowKey),
 		WorkflowName:  strings.TrimSpace(workflowName),
+		ReadOnly:      s.isReadOnly(),
 	}
 	if base.WorkflowKey != "" {
 		base.WorkflowPath = streamPath(base.WorkflowKey)
@@ -1427,7 +1902,7 @@ func deriveProcessStatus(def WorkflowDef, process *Process) string {
 	if status == processStatusTerminated {
 		return processStatusTerminated
 	}
-	if status != processStatusDone && isProcessDone(def, process) {
+	if status != processStatusDone && isProcessComplete(def, process) {
 		status = processStatusDone
 	}
 	return status
@@ -1438,7 +1913,7 @@ func isProcessClosed(def WorkflowDef, process *Process) bool {
 		return false
 	}
 	status := strings.TrimSpace(process.Status)
-	return status == processStatusDone || status == processStatusTerminated || process.Termination != nil || isProcessDone(def, process)
+	return status == processStatusDone || status == processStatusTerminated || process.Termination != nil || isProcessComplete(def, process)
 }
 
 func processStatusLabel(status string) string {
@@ -1477,6 +1952,28 @@ func formataStreamCreatorID(stream FormataBuilderStream) string {
 	return strings.TrimSpace(stream.UpdatedByUserID)
 }
 
+// singleWorkflowHomeKey returns the only enabled workflow when the home
+// picker should be skipped (Server.homeSingleWorkflowRedirect).
+func (s *Server) singleWorkflowHomeKey() (string, bool) {
+	if !s.homeSingleWorkflowRedirect {
+		return "", false
+	}
+	catalog, err := s.workflowCatalog()
+	if err != nil {
+		return "", false
+	}
+	var enabled []string
+	for _, key := range sortedWorkflowKeys(catalog) {
+		if workflowEnabled(catalog[key].Workflow) {
+			enabled = append(enabled, key)
+		}
+	}
+	if len(enabled) != 1 {
+		return "", false
+	}
+	return enabled[0], true
+}
+
 func homePickerMessage(r *http.Request, key string) string {
 	if r == nil || r.URL == nil {
 		return ""
@@ -1512,13 +2009,17 @@ func (s *Server) workflowOptions(ctx context.Context, user *AccountUser) ([]Stre
 	options := make([]StreamCardView, 0, len(keys))
 	for _, key := range keys {
 		cfg := catalog[key]
+		if !workflowEnabled(cfg.Workflow) {
+			continue
+		}
 		option := StreamCardView{
-			Key:          key,
-			Name:         cfg.Workflow.Name,
-			Description:  strings.TrimSpace(cfg.Workflow.Description),
-			Counts:       WorkflowProcessCounts{},
-			EditAction:   organizationPath("formata-builder?stream=" + key),
-			DeleteAction: streamPath(key) + "/delete",
+			Key:             key,
+			Name:            cfg.Workflow.Name,
+			Description:     strings.TrimSpace(cfg.Workflow.Description),
+			DescriptionHTML: renderMarkdown(cfg.Workflow.Description),
+			Counts:          WorkflowProcessCounts{},
+			EditAction:      organizationPath("formata-builder?stream=" + key),
+			DeleteAction:    streamPath(key) + "/delete",
 		}
 		if s.store == nil {
 			options = append(options, option)
@@ -1528,6 +2029,7 @@ func (s *Server) workflowOptions(ctx context.Context, user *AccountUser) ([]Stre
 		if listErr != nil {
 			return nil, listErr
 		}
+		processes = s.visibleProcesses(user, cfg, processes)
 		option.Counts = workflowProcessCounts(cfg.Workflow, processes)
 		actor := actorFromAccountUser(user, key)
 		if len(actor.RoleSlugs) == 0 && !s.enforceAuth {
@@ -1568,6 +2070,15 @@ func (s *Server) workflowOptions(ctx context.Context, user *AccountUser) ([]Stre
 	return options, nil
 }
 
+// contextWorkflowKey is the workflow selected on ctx by the stream routes,
+// or the default workflow.
+func (s *Server) contextWorkflowKey(ctx context.Context) string {
+	if selected, ok := ctx.Value(workflowContextKey{}).(workflowContextValue); ok {
+		return selected.Key
+	}
+	return s.defaultWorkflowKey()
+}
+
 func (s *Server) selectedWorkflowUnvalidated(r *http.Request) (string, RuntimeConfig, error) {
 	if value := r.Context().Value(workflowContextKey{}); value != nil {
 		if selected, ok := value.(workflowContextValue); ok {
@@ -1601,12 +2112,12 @@ func (s *Server) selectedWorkflowOrRedirectHome(w http.ResponseWriter, r *http.R
 	var validationErr *WorkflowRefValidationError
 	if errors.As(err, &validationErr) {
 		if fallbackKey, _, fallbackErr := s.selectedWorkflowUnvalidated(r); fallbackErr == nil && strings.TrimSpace(fallbackKey) != "" {
-			redirectWorkflowHomeWithMessage(w, r, fallbackKey, validationErr.Error())
+			redirectWorkflowHomeWithMessage(w, r, fallbackKey, workflowUnavailableMessage)
 			return "", RuntimeConfig{}, false
 		}
 	}
 
-	http.Error(w, err.Error(), http.StatusInternalServerError)
+	writeWorkflowSelectionError(w, err)
 	return "", RuntimeConfig{}, false
 }
 
@@ -1621,7 +2132,10 @@ func (s *Server) validateWorkflowRefs(ctx context.Context, cfg RuntimeConfig) er
 		return nil
 	}
 
-	messages := []string{}
+	var problems []WorkflowRefProblem
+	addProblem := func(kind, orgSlug, roleSlug, message string) {
+		problems = append(problems, WorkflowRefProblem{Kind: kind, OrgSlug: orgSlug, RoleSlug: roleSlug, Message: message})
+	}
 	orgs, err := s.identity.ListOrganizations(ctx)
 	if err != nil {
 		return err
@@ -1638,7 +2152,7 @@ func (s *Server) validateWorkflowRefs(ctx context.Context, cfg RuntimeConfig) er
 		}
 		yamlOrgs[slug] = struct{}{}
 		if _, ok := orgsBySlug[slug]; !ok {
-			messages = append(messages, "missing organization slug "+slug)
+			addProblem(workflowRefMissingOrg, slug, "", "missing organization slug "+slug)
 		}
 	}
 
@@ -1667,7 +2181,7 @@ func (s *Server) validateWorkflowRefs(ctx context.Context, cfg RuntimeConfig) er
 		}
 		org, ok := orgsBySlug[orgSlug]
 		if !ok || !identityOrgHasRole(org, roleSlug) {
-			messages = append(messages, "missing role slug "+orgSlug+"/"+roleSlug)
+			addProblem(workflowRefMissingRole, orgSlug, roleSlug, "missing role slug "+orgSlug+"/"+roleSlug)
 		}
 	}
 
@@ -1675,7 +2189,7 @@ func (s *Server) validateWorkflowRefs(ctx context.Context, cfg RuntimeConfig) er
 		stepOrg := strings.TrimSpace(step.OrganizationSlug)
 		if stepOrg != "" {
 			if _, ok := yamlOrgs[stepOrg]; !ok {
-				messages = append(messages, "step "+step.StepID+" references organization not in yaml: "+stepOrg)
+				addProblem(workflowRefInvalid, "", "", "step "+step.StepID+" references organization not in yaml: "+stepOrg)
 			}
 		}
 		for _, sub := range step.Substep {
@@ -1684,7 +2198,7 @@ func (s *Server) validateWorkflowRefs(ctx context.Context, cfg RuntimeConfig) er
 				roles = []string{strings.TrimSpace(sub.Role)}
 			}
 			if len(roles) == 0 {
-				messages = append(messages, "substep "+sub.SubstepID+" has no roles")
+				addProblem(workflowRefInvalid, "", "", "substep "+sub.SubstepID+" has no roles")
 				continue
 			}
 			for _, roleSlug := range roles {
@@ -1692,21 +2206,21 @@ func (s *Server) validateWorkflowRefs(ctx context.Context, cfg RuntimeConfig) er
 				if stepOrg != "" {
 					if _, ok := yamlRolesByOrg[stepOrg][trimmedRole]; !ok {
 						if len(yamlRoleOrgs[trimmedRole]) == 0 {
-							messages = append(messages, "substep "+sub.SubstepID+" references role not in yaml: "+trimmedRole)
+							addProblem(workflowRefInvalid, "", "", "substep "+sub.SubstepID+" references role not in yaml: "+trimmedRole)
 						} else {
-							messages = append(messages, "substep "+sub.SubstepID+" role "+trimmedRole+" not in step organization "+stepOrg)
+							addProblem(workflowRefInvalid, "", "", "substep "+sub.SubstepID+" role "+trimmedRole+" not in step organization "+stepOrg)
 						}
 						continue
 					}
 					if !identityOrgHasRole(orgsBySlug[stepOrg], trimmedRole) {
-						messages = append(messages, "missing role slug "+stepOrg+"/"+trimmedRole)
+						addProblem(workflowRefMissingRole, stepOrg, trimmedRole, "missing role slug "+stepOrg+"/"+trimmedRole)
 					}
 					continue
 				}
 
 				roleOrgs := yamlRoleOrgs[trimmedRole]
 				if len(roleOrgs) == 0 {
-					messages = append(messages, "substep "+sub.SubstepID+" references role not in yaml: "+trimmedRole)
+					addProblem(workflowRefInvalid, "", "", "substep "+sub.SubstepID+" references role not in yaml: "+trimmedRole)
 					continue
 				}
 				foundRole := false
@@ -1717,16 +2231,16 @@ func (s *Server) validateWorkflowRefs(ctx context.Context, cfg RuntimeConfig) er
 					}
 				}
 				if !foundRole {
-					messages = append(messages, "missing role slug "+roleOrgs[0]+"/"+trimmedRole)
+					addProblem(workflowRefMissingRole, roleOrgs[0], trimmedRole, "missing role slug "+roleOrgs[0]+"/"+trimmedRole)
 				}
 			}
 		}
 	}
 
-	if len(messages) == 0 {
+	if len(problems) == 0 {
 		return nil
 	}
-	return &WorkflowRefValidationError{Messages: dedupeStrings(messages)}
+	return newWorkflowRefValidationError(problems)
 }
 
 func dedupeStrings(items []string) []string {
@@ -1744,7 +2258,7 @@ func dedupeStrings(items []string) []string {
 
 func normalizeHomeSortKey(value string) string {
 	switch value {
-	case "time_asc", "time_desc", "progress_asc", "progress_desc", "status":
+	case "time_asc", "time_desc", "activity_desc", "progress_asc", "progress_desc", "status":
 		return value
 	default:
 		return "time_desc"
@@ -1941,7 +2455,7 @@ func processProgressStats(def WorkflowDef, process *Process) (doneCount int, las
 			first = false
 			digest := ""
 			if progress.Data != nil {
-				digest = digestPayload(progress.Data)
+				digest = currentStepDigest(progress)
 			}
 			if len(digest) > 12 {
 				digest = digest[:12]
@@ -1959,6 +2473,13 @@ func sortHomeProcessList(items []StreamInstanceCard, sortKey string) {
 	switch sortKey {
 	case "time_asc":
 		sort.Slice(items, func(i, j int) bool { return items[i].CreatedAtTime.Before(items[j].CreatedAtTime) })
+	case "activity_desc":
+		sort.Slice(items, func(i, j int) bool {
+			if items[i].UpdatedAtTime.Equal(items[j].UpdatedAtTime) {
+				return items[i].CreatedAtTime.After(items[j].CreatedAtTime)
+			}
+			return items[i].UpdatedAtTime.After(items[j].UpdatedAtTime)
+		})
 	case "progress_asc":
 		sort.Slice(items, func(i, j int) bool {
 			if items[i].Percent == items[j].Percent {
@@ -2029,12 +2550,14 @@ func (s *Server) handlePublicHome(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	base := s.pageBase("public_home_body", "", "")
-	if user, _, err := s.currentUser(r); err == nil {
-		base = s.pageBaseForUser(user, "public_home_body", "", "")
-	}
 	view := struct {
 		PageBase
+		NoRolesNotice string
 	}{PageBase: base}
+	if user, _, err := s.currentUser(r); err == nil {
+		view.PageBase = s.pageBaseForUser(user, "public_home_body", "", "")
+		view.NoRolesNotice = s.noRolesNotice(user)
+	}
 	if err := s.tmpl.ExecuteTemplate(w, "public_home.html", view); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
@@ -2050,15 +2573,26 @@ func (s *Server) handleHome(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		return
 	}
+	showCreateStreamCard, authErr := s.canViewFormataBuilder(r.Context(), user)
+	if authErr != nil {
+		logRequestError(r, authErr, "cerbos check failed for formata builder card")
+	}
+	// Stream builders keep the picker: it holds the create and delete
+	// actions. Flash messages are shown on the picker, so they also stop
+	// the redirect. Users without roles stay too, to see why the stream
+	// would be empty.
+	noRolesNotice := s.noRolesNotice(user)
+	if !showCreateStreamCard && noRolesNotice == "" && homePickerMessage(r, "error") == "" && homePickerMessage(r, "confirmation") == "" {
+		if key, ok := s.singleWorkflowHomeKey(); ok {
+			http.Redirect(w, r, streamPath(key), http.StatusSeeOther)
+			return
+		}
+	}
 	options, err := s.workflowOptions(r.Context(), user)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	showCreateStreamCard, authErr := s.canViewFormataBuilder(r.Context(), user)
-	if authErr != nil {
-		logRequestError(r, authErr, "cerbos check failed for formata builder card")
-	}
 	view := HomeWorkflowPickerView{
 		WorkflowPickerView: WorkflowPickerView{
 			PageBase:             s.pageBaseForUser(user, "home_picker_body", "", ""),
@@ -2067,6 +2601,7 @@ func (s *Server) handleHome(w http.ResponseWriter, r *http.Request) {
 			Error:                homePickerMessage(r, "error"),
 			Confirmation:         homePickerMessage(r, "confirmation"),
 		},
+		NoRolesNotice: noRolesNotice,
 	}
 	if err := s.tmpl.ExecuteTemplate(w, "home.html", view); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -2085,6 +2620,9 @@ func (s *Server) handleMyRoutes(w http.ResponseWriter, r *http.Request) {
 	case rest == "streams" || strings.HasPrefix(rest, "streams/"):
 		s.handleStreamRoutes(w, cloneRequestWithPath(r, "/"+rest))
 		return
+	case rest == "dashboard":
+		s.handleAggregatedDashboard(w, r)
+		return
 	case rest == "organization" || strings.HasPrefix(rest, "organization/"):
 		s.handleOrganizationRoutes(w, cloneRequestWithPath(r, "/"+rest))
 		return
@@ -2100,10 +2638,28 @@ func (s *Server) handleOrganizationRoutes(w http.ResponseWriter, r *http.Request
 		s.handleOrgAdminPage(w, r)
 	case path == "/roles" || path == "/roles/":
 		s.handleOrgAdminRoles(w, r)
+	case strings.HasPrefix(path, "/roles/") && strings.HasSuffix(path, "/rename"):
+		roleSlug := strings.TrimSuffix(strings.TrimPrefix(path, "/roles/"), "/rename")
+		if roleSlug == "" || strings.Contains(roleSlug, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		s.handleOrgAdminRoleRename(w, r, roleSlug)
+	case strings.HasPrefix(path, "/roles/") && strings.HasSuffix(path, "/delete"):
+		roleSlug := strings.TrimSuffix(strings.TrimPrefix(path, "/roles/"), "/delete")
+		if roleSlug == "" || strings.Contains(roleSlug, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		s.handleOrgAdminRoleDelete(w, r, roleSlug)
 	case path == "/members" || path == "/members/":
 		s.handleOrgAdminPage(w, r)
 	case path == "/users" || path == "/users/":
 		s.handleOrgAdminUsers(w, r)
+	case path == "/users/import":
+		s.handleOrgAdminUserImport(w, r)
+	case path == "/invites/resend":
+		s.handleOrgAdminInviteResend(w, r)
 	case strings.HasPrefix(path, "/logo/"):
 		s.handleOrgAdminLogo(w, cloneRequestWithPath(r, path))
 	case path == "/formata-builder" || strings.HasPrefix(path, "/formata-builder/"):
@@ -2118,13 +2674,16 @@ func (s *Server) handleAbout(w http.ResponseWriter, r *http.Request) {
 	http.NotFound(w, r)
 }
 
-const swaggerUIPage = `<!doctype html>
+// swaggerUIPage is rendered with assets vendored under web/public/swagger-ui
+// (served from /static/) so the docs work without reaching a CDN.
+var swaggerUIPage = template.Must(template.New("swagger-ui").Parse(`<!doctype html>
 <html lang="en">
 <head>
   <meta charset="utf-8">
   <meta name="viewport" content="width=device-width, initial-scale=1">
-  <title>Attesta API Docs</title>
-  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+  <title>{{ .Title }}</title>
+  <link rel="icon" type="image/svg+xml" href="{{ .FaviconURL }}">
+  <link rel="stylesheet" href="{{ .AssetBase }}/swagger-ui.css">
   <style>
     html, body { margin: 0; padding: 0; }
     #swagger-ui { min-height: 100vh; }
@@ -2132,7 +2691,7 @@ const swaggerUIPage = `<!doctype html>
 </head>
 <body>
   <div id="swagger-ui"></div>
-  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script src="{{ .AssetBase }}/swagger-ui-bundle.js"></script>
   <script>
     window.ui = SwaggerUIBundle({
       url: '/docs/openapi3.json',
@@ -2141,7 +2700,72 @@ const swaggerUIPage = `<!doctype html>
     });
   </script>
 </body>
-</html>`
+</html>`))
+
+// openAPISpecPage stands in for Swagger UI when its vendored assets are
+// missing from the static build, so /docs/ still points at the spec.
+var openAPISpecPage = template.Must(template.New("openapi-spec").Parse(`<!doctype html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{ .Title }}</title>
+  <link rel="icon" type="image/svg+xml" href="{{ .FaviconURL }}">
+</head>
+<body>
+  <main>
+    <h1>{{ .Title }}</h1>
+    <p>The OpenAPI spec is available as <a href="/docs/openapi3.json">openapi3.json</a> and <a href="/docs/openapi3.yaml">openapi3.yaml</a>.</p>
+    <p>Swagger UI is not installed in this build; run <code>task web:vendor-swagger-ui</code> and rebuild the web assets to browse it here.</p>
+  </main>
+</body>
+</html>`))
+
+type swaggerUIPageView struct {
+	Title      string
+	FaviconURL string
+	AssetBase  string
+}
+
+func (s *Server) staticRoot() string {
+	if s != nil && s.staticDir != "" {
+		return s.staticDir
+	}
+	return "../web/dist"
+}
+
+// swaggerUIAvailable reports whether the vendored Swagger UI assets can be
+// served. The Vite dev server serves web/public directly.
+func (s *Server) swaggerUIAvailable() bool {
+	if s != nil && s.viteDevServer != "" {
+		return true
+	}
+	for _, name := range []string{"swagger-ui.css", "swagger-ui-bundle.js"} {
+		if _, err := os.Stat(filepath.Join(s.staticRoot(), "swagger-ui", name)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) swaggerUIPageView() swaggerUIPageView {
+	staticBase := "/static"
+	if s != nil && s.viteDevServer != "" {
+		staticBase = s.viteDevServer
+	}
+	view := swaggerUIPageView{
+		Title:      "Attesta API Docs",
+		FaviconURL: staticBase + "/attesta-favicon.svg",
+		AssetBase:  staticBase + "/swagger-ui",
+	}
+	if s != nil && s.docsTitle != "" {
+		view.Title = s.docsTitle
+	}
+	if s != nil && s.docsFaviconURL != "" {
+		view.FaviconURL = s.docsFaviconURL
+	}
+	return view
+}
 
 func (s *Server) handleDocs(w http.ResponseWriter, r *http.Request) {
 	switch r.URL.Path {
@@ -2150,7 +2774,13 @@ func (s *Server) handleDocs(w http.ResponseWriter, r *http.Request) {
 		return
 	case "/docs/":
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
-		_, _ = io.WriteString(w, swaggerUIPage)
+		page := swaggerUIPage
+		if !s.swaggerUIAvailable() {
+			page = openAPISpecPage
+		}
+		if err := page.Execute(w, s.swaggerUIPageView()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
 		return
 	case "/docs/openapi3.json":
 		s.serveOpenAPIFile(w, r, "openapi3.json", "application/json; charset=utf-8")
@@ -2246,17 +2876,28 @@ func (s *Server) requireCatalogAccessAPI(w http.ResponseWriter, r *http.Request)
 
 func (s *Server) newMux() *http.ServeMux {
 	mux := http.NewServeMux()
-	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("../web/dist"))))
+	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir(s.staticRoot()))))
 	mux.HandleFunc("/docs", s.handleDocs)
 	mux.HandleFunc("/docs/", s.handleDocs)
 	mux.HandleFunc("/about", s.handleAbout)
 	mux.HandleFunc("/api/catalog", s.handlePublicCatalog)
+	mux.HandleFunc(workflowDefinitionAPIPath, s.handleWorkflowDefinitionAPI)
+	mux.HandleFunc(workflowDefinitionAPIPath+"/", s.handleWorkflowDefinitionAPI)
 	mux.HandleFunc("/01/", s.handleDigitalLinkDPP)
+	mux.HandleFunc("/share/", s.handleShare)
 	mux.HandleFunc("/login", s.handleLogin)
+	mux.HandleFunc("/login/magic", s.handleMagicLink)
+	mux.HandleFunc("/login/magic/", s.handleMagicLink)
 	mux.HandleFunc("/signup", s.handleSignup)
 	mux.HandleFunc("/logout", s.handleLogout)
 	mux.HandleFunc("/admin/orgs", s.handleAdminOrgs)
+	mux.HandleFunc("/admin/invites", s.handleAdminInvites)
+	mux.HandleFunc("/admin/sequences", s.handleAdminSequences)
+	mux.HandleFunc("/admin/sequences/", s.handleAdminSequences)
 	mux.HandleFunc("/admin/orgs/", s.handleAdminOrgs)
+	mux.HandleFunc(workflowValidatePath, s.handleWorkflowValidate)
+	mux.HandleFunc(readOnlyTogglePath, s.handleReadOnlyToggle)
+	mux.HandleFunc(processWorkflowAdminPrefix, s.handleProcessWorkflowReassign)
 	mux.HandleFunc("/invite/", s.handleInvite)
 	mux.HandleFunc("/reset", s.handleResetRequest)
 	mux.HandleFunc("/reset/", s.handleResetSet)
@@ -2278,14 +2919,12 @@ func openAPIDocCandidates(filename string) []string {
 	}
 }
 
-func openAPIRequestOrigin(r *http.Request) string {
+func (s *Server) openAPIRequestOrigin(r *http.Request) string {
 	if r == nil {
 		return ""
 	}
-	proto := firstForwardedHeaderValue(r.Header.Get("X-Forwarded-Proto"))
-	if proto == "" {
-		proto = forwardedHeaderParam(r.Header.Get("Forwarded"), "proto")
-	}
+	// Forwarded headers only count when a TRUSTED_PROXIES peer set them.
+	proto, host := s.forwardedProto(r), s.forwardedHost(r)
 	if proto == "" {
 		if r.TLS != nil {
 			proto = "https"
@@ -2293,10 +2932,6 @@ func openAPIRequestOrigin(r *http.Request) string {
 			proto = "http"
 		}
 	}
-	host := firstForwardedHeaderValue(r.Header.Get("X-Forwarded-Host"))
-	if host == "" {
-		host = forwardedHeaderParam(r.Header.Get("Forwarded"), "host")
-	}
 	if host == "" {
 		host = r.Host
 	}
@@ -2380,7 +3015,7 @@ func (s *Server) serveOpenAPIFile(w http.ResponseWriter, r *http.Request, filena
 		http.Error(w, "failed to read OpenAPI spec", http.StatusInternalServerError)
 		return
 	}
-	data, err = rewriteOpenAPIServers(data, filename, openAPIRequestOrigin(r))
+	data, err = rewriteOpenAPIServers(data, filename, s.openAPIRequestOrigin(r))
 	if err != nil {
 		http.Error(w, "failed to render OpenAPI spec", http.StatusInternalServerError)
 		return
@@ -2393,15 +3028,15 @@ func (s *Server) writeSessionCookie(w http.ResponseWriter, r *http.Request, sess
 	if strings.TrimSpace(session.Secret) == "" {
 		return errors.New("session secret required")
 	}
-	http.SetCookie(w, &http.Cookie{
+	cookie := &http.Cookie{
 		Name:     "attesta_session",
 		Value:    session.Secret,
 		Path:     "/",
 		Expires:  session.ExpiresAt,
 		HttpOnly: true,
-		SameSite: http.SameSiteLaxMode,
-		Secure:   shouldSecureCookie(r),
-	})
+	}
+	s.applyCookiePolicy(r, cookie)
+	http.SetCookie(w, cookie)
 	return nil
 }
 
@@ -2416,9 +3051,10 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 		}
 		view := LoginView{
 			PageBase:     s.pageBase("login_body", "", ""),
-			Next:         safeNextPath(r, appHomePath),
+			Next:         s.safeNextPath(r, appHomePath),
 			Confirmation: loginNoticeMessage(requestNotice(r)),
 			ShowSignup:   anyoneCanCreateAccount(),
+			Brand:        s.organizationBrand(r.Context(), r.URL.Query().Get("org"), true),
 		}
 		if err := s.tmpl.ExecuteTemplate(w, "login.html", view); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -2431,7 +3067,8 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 		}
 		email := strings.ToLower(strings.TrimSpace(r.FormValue("email")))
 		password := strings.TrimSpace(r.FormValue("password"))
-		next := safeNextPath(r, appHomePath)
+		next := s.safeNextPath(r, appHomePath)
+		brand := s.organizationBrand(r.Context(), r.FormValue("org"), true)
 
 		if adminEmail, adminPassword, ok := platformAdminCredentials(); ok && strings.EqualFold(email, adminEmail) {
 			if subtle.ConstantTimeCompare([]byte(password), []byte(adminPassword)) != 1 {
@@ -2441,6 +3078,7 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 					Next:       next,
 					Error:      "Invalid email or password.",
 					ShowSignup: anyoneCanCreateAccount(),
+					Brand:      brand,
 				}
 				w.WriteHeader(http.StatusUnauthorized)
 				_ = s.tmpl.ExecuteTemplate(w, "login.html", view)
@@ -2471,6 +3109,7 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 				Next:       next,
 				Error:      "Invalid email or password.",
 				ShowSignup: anyoneCanCreateAccount(),
+				Brand:      brand,
 			}
 			w.WriteHeader(http.StatusUnauthorized)
 			_ = s.tmpl.ExecuteTemplate(w, "login.html", view)
@@ -2520,6 +3159,15 @@ func (s *Server) handleSignup(w http.ResponseWriter, r *http.Request) {
 		}
 		email := strings.ToLower(strings.TrimSpace(r.FormValue("email")))
 		password := strings.TrimSpace(r.FormValue("password"))
+		if err := checkEmailDomain(email, globalAllowedEmailDomains()); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = s.tmpl.ExecuteTemplate(w, "signup.html", SignupView{
+				PageBase: s.pageBase("signup_body", "", ""),
+				Email:    email,
+				Error:    err.Error(),
+			})
+			return
+		}
 		if err := validatePassword(password); err != nil {
 			w.WriteHeader(http.StatusBadRequest)
 			_ = s.tmpl.ExecuteTemplate(w, "signup.html", SignupView{
@@ -2570,7 +3218,7 @@ func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 	}
-	clearCookie(w, r, "attesta_session")
+	s.clearCookie(w, r, "attesta_session")
 	http.Redirect(w, r, "/login", http.StatusSeeOther)
 }
 
@@ -2617,6 +3265,10 @@ func (s *Server) handleInviteAccept(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	session, err := s.identity.AcceptInvite(r.Context(), teamID, membershipID, userID, secret)
+	if errors.Is(err, ErrInviteAlreadyUsed) {
+		logAndHTTPError(w, r, http.StatusConflict, "invite already used", err, "rejected reused invite team=%s membership=%s user=%s", teamID, membershipID, userID)
+		return
+	}
 	if err != nil {
 		logAndHTTPError(w, r, http.StatusBadRequest, "failed to accept invite", err, "failed to accept invite team=%s membership=%s user=%s", teamID, membershipID, userID)
 		return
@@ -2643,6 +3295,7 @@ func (s *Server) handleInvitePassword(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		return
 	}
+	brand := s.organizationBrand(r.Context(), user.OrgSlug, false)
 	switch r.Method {
 	case http.MethodGet:
 		view := InviteView{
@@ -2650,6 +3303,7 @@ func (s *Server) handleInvitePassword(w http.ResponseWriter, r *http.Request) {
 			Token:    "password",
 			Email:    strings.TrimSpace(user.Email),
 			Org:      strings.TrimSpace(user.OrgSlug),
+			Brand:    brand,
 			Roles:    append([]string(nil), user.RoleSlugs...),
 		}
 		if err := s.tmpl.ExecuteTemplate(w, "invite.html", view); err != nil {
@@ -2657,40 +3311,47 @@ func (s *Server) handleInvitePassword(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	case http.MethodPost:
-		if err := r.ParseForm(); err != nil {
+		jsonResponse := wantsPasswordFormJSON(r)
+		password, confirmPassword, err := readPasswordForm(w, r)
+		if err != nil {
+			if jsonResponse {
+				logRequestError(r, err, "failed to parse invite password form")
+				writePasswordFormJSON(w, http.StatusBadRequest, PasswordFormResponse{Error: "invalid form"})
+				return
+			}
 			logAndHTTPError(w, r, http.StatusBadRequest, "invalid form", err, "failed to parse invite password form")
 			return
 		}
-		password := strings.TrimSpace(r.FormValue("password"))
-		confirmPassword := strings.TrimSpace(r.FormValue("confirm_password"))
-		if password != confirmPassword {
-			w.WriteHeader(http.StatusBadRequest)
-			_ = s.tmpl.ExecuteTemplate(w, "invite.html", InviteView{
-				PageBase: s.pageBaseForUser(user, "invite_body", "", ""),
-				Token:    "password",
-				Email:    strings.TrimSpace(user.Email),
-				Org:      strings.TrimSpace(user.OrgSlug),
-				Roles:    append([]string(nil), user.RoleSlugs...),
-				Error:    "passwords do not match",
-			})
-			return
-		}
-		if err := validatePassword(password); err != nil {
+		if message, field := passwordFormError(password, confirmPassword); message != "" {
+			if jsonResponse {
+				writePasswordFormJSON(w, http.StatusBadRequest, PasswordFormResponse{Error: message, Field: field})
+				return
+			}
 			w.WriteHeader(http.StatusBadRequest)
 			_ = s.tmpl.ExecuteTemplate(w, "invite.html", InviteView{
 				PageBase: s.pageBaseForUser(user, "invite_body", "", ""),
 				Token:    "password",
 				Email:    strings.TrimSpace(user.Email),
 				Org:      strings.TrimSpace(user.OrgSlug),
+				Brand:    brand,
 				Roles:    append([]string(nil), user.RoleSlugs...),
-				Error:    err.Error(),
+				Error:    message,
 			})
 			return
 		}
 		if err := s.identity.UpdateCurrentPassword(r.Context(), session.Secret, password); err != nil {
+			if jsonResponse {
+				logRequestError(r, err, "failed to update invited user password for %s", user.Email)
+				writePasswordFormJSON(w, http.StatusInternalServerError, PasswordFormResponse{Error: "failed to update password"})
+				return
+			}
 			logAndHTTPError(w, r, http.StatusInternalServerError, "failed to update password", err, "failed to update invited user password for %s", user.Email)
 			return
 		}
+		if jsonResponse {
+			writePasswordFormJSON(w, http.StatusOK, PasswordFormResponse{OK: true, Redirect: appHomePath})
+			return
+		}
 		http.Redirect(w, r, appHomePath, http.StatusSeeOther)
 		return
 	default:
@@ -2706,30 +3367,36 @@ func resetTTLHrs() int {
 	return hours
 }
 
-func requestBaseURL(r *http.Request) string {
+// requestBaseURL is the origin the client used, for links sent by email or
+// shown to be copied: https when the request came over TLS (or COOKIE_SECURE
+// is set), and the host a trusted proxy forwarded before r.Host.
+func (s *Server) requestBaseURL(r *http.Request) string {
 	scheme := "http"
-	if shouldSecureCookie(r) {
+	if s.shouldSecureCookie(r) {
 		scheme = "https"
 	}
-	host := strings.TrimSpace(r.Host)
+	host := strings.TrimSpace(s.forwardedHost(r))
+	if host == "" {
+		host = strings.TrimSpace(r.Host)
+	}
 	if host == "" {
 		host = "localhost:3000"
 	}
 	return scheme + "://" + host
 }
 
-func resetRedirectURL(r *http.Request) string {
+func (s *Server) resetRedirectURL(r *http.Request) string {
 	if configured := strings.TrimSpace(os.Getenv("APPWRITE_RESET_REDIRECT_URL")); configured != "" {
 		return configured
 	}
-	return requestBaseURL(r) + "/reset/confirm"
+	return s.requestBaseURL(r) + "/reset/confirm"
 }
 
-func inviteRedirectURL(r *http.Request) string {
+func (s *Server) inviteRedirectURL(r *http.Request) string {
 	if configured := strings.TrimSpace(os.Getenv("APPWRITE_INVITE_REDIRECT_URL")); configured != "" {
 		return configured
 	}
-	return requestBaseURL(r) + "/invite/accept"
+	return s.requestBaseURL(r) + "/invite/accept"
 }
 
 func resetConfirmParams(r *http.Request) (string, string) {
@@ -2756,7 +3423,7 @@ func (s *Server) handleResetRequest(w http.ResponseWriter, r *http.Request) {
 		email := strings.ToLower(strings.TrimSpace(r.FormValue("email")))
 
 		if s.identity != nil {
-			if err := s.identity.CreateRecovery(r.Context(), email, resetRedirectURL(r)); err != nil {
+			if err := s.identity.CreateRecovery(r.Context(), email, s.resetRedirectURL(r)); err != nil {
 				if errors.Is(err, ErrIdentityNotFound) {
 					http.Redirect(w, r, "/reset?notice="+url.QueryEscape(noticeResetRequestSent), http.StatusSeeOther)
 					return
@@ -2809,39 +3476,47 @@ func (s *Server) handleResetConfirm(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	case http.MethodPost:
-		if err := r.ParseForm(); err != nil {
+		jsonResponse := wantsPasswordFormJSON(r)
+		password, confirmPassword, err := readPasswordForm(w, r)
+		if err != nil {
+			if jsonResponse {
+				logRequestError(r, err, "failed to parse reset confirmation form")
+				writePasswordFormJSON(w, http.StatusBadRequest, PasswordFormResponse{Error: "invalid form"})
+				return
+			}
 			logAndHTTPError(w, r, http.StatusBadRequest, "invalid form", err, "failed to parse reset confirmation form")
 			return
 		}
-		password := strings.TrimSpace(r.FormValue("password"))
-		confirmPassword := strings.TrimSpace(r.FormValue("confirm_password"))
-		if password != confirmPassword {
-			w.WriteHeader(http.StatusBadRequest)
-			_ = s.tmpl.ExecuteTemplate(w, "reset_set.html", ResetSetView{
-				PageBase:    s.pageBase("reset_set_body", "", ""),
-				Token:       "confirm?userId=" + url.QueryEscape(userID) + "&secret=" + url.QueryEscape(secret),
-				Error:       "passwords do not match",
-				Title:       "Set New Password",
-				SubmitLabel: "Update password",
-			})
-			return
-		}
-		if err := validatePassword(password); err != nil {
+		if message, field := passwordFormError(password, confirmPassword); message != "" {
+			if jsonResponse {
+				writePasswordFormJSON(w, http.StatusBadRequest, PasswordFormResponse{Error: message, Field: field})
+				return
+			}
 			w.WriteHeader(http.StatusBadRequest)
 			_ = s.tmpl.ExecuteTemplate(w, "reset_set.html", ResetSetView{
 				PageBase:    s.pageBase("reset_set_body", "", ""),
 				Token:       "confirm?userId=" + url.QueryEscape(userID) + "&secret=" + url.QueryEscape(secret),
-				Error:       err.Error(),
+				Error:       message,
 				Title:       "Set New Password",
 				SubmitLabel: "Update password",
 			})
 			return
 		}
 		if err := s.identity.CompleteRecovery(r.Context(), userID, secret, password); err != nil {
+			if jsonResponse {
+				logRequestError(r, err, "failed to complete password recovery for user %s", userID)
+				writePasswordFormJSON(w, http.StatusInternalServerError, PasswordFormResponse{Error: "failed to reset password"})
+				return
+			}
 			logAndHTTPError(w, r, http.StatusInternalServerError, "failed to reset password", err, "failed to complete password recovery for user %s", userID)
 			return
 		}
-		http.Redirect(w, r, "/login?notice="+url.QueryEscape(noticePasswordResetSuccess), http.StatusSeeOther)
+		loginURL := "/login?notice=" + url.QueryEscape(noticePasswordResetSuccess)
+		if jsonResponse {
+			writePasswordFormJSON(w, http.StatusOK, PasswordFormResponse{OK: true, Redirect: loginURL})
+			return
+		}
+		http.Redirect(w, r, loginURL, http.StatusSeeOther)
 		return
 	default:
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -2961,10 +3636,12 @@ func parseAppwriteActorID(actorID string) (string, bool) {
 
 func organizationFromIdentityOrg(org IdentityOrg) Organization {
 	return Organization{
-		ID:               stableOrgObjectID(org.Slug),
-		Slug:             strings.TrimSpace(org.Slug),
-		Name:             strings.TrimSpace(org.Name),
-		LogoAttachmentID: strings.TrimSpace(org.LogoFileID),
+		ID:                  stableOrgObjectID(org.Slug),
+		Slug:                strings.TrimSpace(org.Slug),
+		Name:                strings.TrimSpace(org.Name),
+		LogoAttachmentID:    strings.TrimSpace(org.LogoFileID),
+		AllowedEmailDomains: append([]string(nil), org.AllowedEmailDomains...),
+		PublicBranding:      org.PublicBranding,
 	}
 }
 
@@ -3157,6 +3834,7 @@ func (s *Server) platformOrganizations(ctx context.Context) []Organization {
 
 const platformAdminOrganizationsPerPage = 12
 const homeProcessesPerPage = 10
+const orgAdminUsersPerPage = 20
 
 func filterPlatformOrganizations(organizations []Organization, query string) []Organization {
 	trimmedQuery := strings.ToLower(strings.TrimSpace(query))
@@ -3351,6 +4029,9 @@ func (s *Server) inviteOrganizationAdminWithSession(ctx context.Context, session
 	case err != nil && !errors.Is(err, ErrIdentityNotFound):
 		return "", err
 	}
+	if err := checkInviteEmailDomain(org, email); err != nil {
+		return "", err
+	}
 	if _, err := s.identity.InviteOrganizationUser(ctx, sessionSecret, org.Slug, email, redirectURL, nil, true); err != nil {
 		return "", err
 	}
@@ -3387,26 +4068,27 @@ func (s *Server) platformAdminView(user *AccountUser, confirmation string, errs
 	}
 	rows := platformAdminOrganizationRows(context.Background(), pagedOrganizations, s.identity)
 	return PlatformAdminView{
-		PageBase: s.pageBaseForUser(user, "platform_admin_body", "", ""),
-		Breadcrumbs:              buildPlatformAdminBreadcrumbs(),
-		SearchQuery:              errs.SearchQuery,
-		CurrentPage:              currentPage,
-		TotalPages:               totalPages,
-		PageNumbers:              pageNumbers,
-		HasPreviousPage:          currentPage > 1,
-		HasNextPage:              currentPage < totalPages,
-		PreviousPage:             max(currentPage-1, 1),
-		NextPage:                 min(currentPage+1, totalPages),
-		MatchedOrganizations:     len(filteredOrganizations),
-		Organizations:            rows,
-		Confirmation:             strings.TrimSpace(confirmation),
-		OrganizationError:        errs.Organization,
-		OrganizationDialogAction: errs.DialogAction,
-		OrganizationDialogSlug:   errs.OrgSlug,
-		OrganizationDialogName:   errs.OrgName,
-		InviteError:              errs.Invite,
-		InviteDialogEmail:        errs.InviteEmail,
-		Error:                    firstNonEmpty(errs.Organization, errs.Invite),
+		PageBase:                  s.pageBaseForUser(user, "platform_admin_body", "", ""),
+		Breadcrumbs:               buildPlatformAdminBreadcrumbs(),
+		SearchQuery:               errs.SearchQuery,
+		CurrentPage:               currentPage,
+		TotalPages:                totalPages,
+		PageNumbers:               pageNumbers,
+		HasPreviousPage:           currentPage > 1,
+		HasNextPage:               currentPage < totalPages,
+		PreviousPage:              max(currentPage-1, 1),
+		NextPage:                  min(currentPage+1, totalPages),
+		MatchedOrganizations:      len(filteredOrganizations),
+		Organizations:             rows,
+		Confirmation:              strings.TrimSpace(confirmation),
+		OrganizationError:         errs.Organization,
+		OrganizationDialogAction:  errs.DialogAction,
+		OrganizationDialogSlug:    errs.OrgSlug,
+		OrganizationDialogName:    errs.OrgName,
+		OrganizationDialogDomains: strings.TrimSpace(errs.EmailDomains),
+		InviteError:               errs.Invite,
+		InviteDialogEmail:         errs.InviteEmail,
+		Error:                     firstNonEmpty(errs.Organization, errs.Invite),
 	}
 }
 
@@ -3487,7 +4169,7 @@ func (s *Server) handleAdminOrgs(w http.ResponseWriter, r *http.Request) {
 				s.renderPlatformAdmin(w, admin, "", PlatformAdminErrors{Invite: "organization is required", DialogAction: "invite", OrgSlug: orgSlug, InviteEmail: email, SearchQuery: searchQuery, Page: page})
 				return
 			}
-			redirectURL := inviteRedirectURL(r)
+			redirectURL := s.inviteRedirectURL(r)
 			org, err := s.identity.GetOrganizationBySlug(r.Context(), orgSlug)
 			if err != nil || org == nil {
 				if err != nil {
@@ -3509,6 +4191,11 @@ func (s *Server) handleAdminOrgs(w http.ResponseWriter, r *http.Request) {
 				s.renderPlatformAdmin(w, admin, "", PlatformAdminErrors{Invite: "email already belongs to another organization", DialogAction: "invite", OrgSlug: orgSlug, InviteEmail: email, SearchQuery: searchQuery, Page: page})
 				return
 			}
+			var domainErr *emailDomainError
+			if errors.As(err, &domainErr) {
+				s.renderPlatformAdmin(w, admin, "", PlatformAdminErrors{Invite: domainErr.Error(), DialogAction: "invite", OrgSlug: orgSlug, InviteEmail: email, SearchQuery: searchQuery, Page: page})
+				return
+			}
 			if err != nil {
 				s.logAndRenderPlatformAdminError(w, r, admin, "", PlatformAdminErrors{Invite: "failed to create invite", DialogAction: "invite", OrgSlug: orgSlug, InviteEmail: email, SearchQuery: searchQuery, Page: page}, err, "failed to create org admin invite for %s in %s", email, org.Slug)
 				return
@@ -3520,10 +4207,24 @@ func (s *Server) handleAdminOrgs(w http.ResponseWriter, r *http.Request) {
 		case "create_org":
 			name := strings.TrimSpace(r.FormValue("name"))
 			inviteEmail := strings.ToLower(strings.TrimSpace(r.FormValue("invite_email")))
+			rawEmailDomains := strings.TrimSpace(r.FormValue("allowed_email_domains"))
 			if name == "" {
-				s.renderPlatformAdmin(w, admin, "", PlatformAdminErrors{Organization: "organization name is required", DialogAction: "create", OrgName: name, InviteEmail: inviteEmail, SearchQuery: searchQuery, Page: page})
+				s.renderPlatformAdmin(w, admin, "", PlatformAdminErrors{Organization: "organization name is required", DialogAction: "create", OrgName: name, InviteEmail: inviteEmail, EmailDomains: rawEmailDomains, SearchQuery: searchQuery, Page: page})
+				return
+			}
+			emailDomains, err := normalizeEmailDomains(strings.Split(rawEmailDomains, ","))
+			if err != nil {
+				s.renderPlatformAdmin(w, admin, "", PlatformAdminErrors{Organization: err.Error(), DialogAction: "create", OrgName: name, InviteEmail: inviteEmail, EmailDomains: rawEmailDomains, SearchQuery: searchQuery, Page: page})
 				return
 			}
+			if inviteEmail != "" {
+				// Reject a bootstrap invite the new allowlist would refuse before
+				// the organization exists, not after.
+				if err := checkInviteEmailDomain(IdentityOrg{AllowedEmailDomains: emailDomains}, inviteEmail); err != nil {
+					s.renderPlatformAdmin(w, admin, "", PlatformAdminErrors{Organization: err.Error(), DialogAction: "create", OrgName: name, InviteEmail: inviteEmail, EmailDomains: rawEmailDomains, SearchQuery: searchQuery, Page: page})
+					return
+				}
+			}
 			orgSlug := canonifySlug(name)
 			if existing, err := s.identity.GetOrganizationBySlug(r.Context(), orgSlug); err == nil && existing != nil {
 				s.renderPlatformAdmin(w, admin, "", PlatformAdminErrors{Organization: "organization slug already exists", DialogAction: "create", OrgName: name, InviteEmail: inviteEmail, SearchQuery: searchQuery, Page: page})
@@ -3566,12 +4267,30 @@ func (s *Server) handleAdminOrgs(w http.ResponseWriter, r *http.Request) {
 					return
 				}
 			}
+			if len(emailDomains) > 0 {
+				if _, err := s.identity.UpdateOrganizationEmailDomains(r.Context(), platformSession.Secret, createdOrg.Slug, emailDomains); err != nil {
+					// An organization without its allowlist would accept any
+					// invite, so roll the creation back.
+					if deleteErr := s.identity.DeleteOrganizationAsAdmin(r.Context(), createdOrg.Slug); deleteErr != nil {
+						logRequestError(r, deleteErr, "failed to roll back organization %s after email domain update failed", createdOrg.Slug)
+					}
+					s.logAndRenderPlatformAdminError(w, r, admin, "", PlatformAdminErrors{Organization: "failed to update organization", DialogAction: "create", OrgName: name, InviteEmail: inviteEmail, EmailDomains: rawEmailDomains, SearchQuery: searchQuery, Page: page}, err, "failed to set email domains for organization %s", createdOrg.Slug)
+					return
+				}
+				// The bootstrap invite below is checked against the new allowlist.
+				createdOrg.AllowedEmailDomains = emailDomains
+			}
 			if inviteEmail != "" {
-				message, err := s.inviteOrganizationAdminWithSession(r.Context(), platformSession.Secret, createdOrg, inviteEmail, inviteRedirectURL(r))
+				message, err := s.inviteOrganizationAdminWithSession(r.Context(), platformSession.Secret, createdOrg, inviteEmail, s.inviteRedirectURL(r))
 				if errors.Is(err, errPlatformAdminInviteCrossOrg) {
 					s.renderPlatformAdmin(w, admin, "organization created", PlatformAdminErrors{Invite: "email already belongs to another organization", DialogAction: "invite", OrgSlug: createdOrg.Slug, InviteEmail: inviteEmail, SearchQuery: searchQuery, Page: page})
 					return
 				}
+				var domainErr *emailDomainError
+				if errors.As(err, &domainErr) {
+					s.renderPlatformAdmin(w, admin, "organization created", PlatformAdminErrors{Invite: domainErr.Error(), DialogAction: "invite", OrgSlug: createdOrg.Slug, InviteEmail: inviteEmail, SearchQuery: searchQuery, Page: page})
+					return
+				}
 				if err != nil {
 					s.logAndRenderPlatformAdminError(w, r, admin, "organization created", PlatformAdminErrors{Invite: "failed to create invite", DialogAction: "invite", OrgSlug: createdOrg.Slug, InviteEmail: inviteEmail, SearchQuery: searchQuery, Page: page}, err, "failed to create org admin invite for new organization %s", createdOrg.Slug)
 					return
@@ -3758,7 +4477,7 @@ func buildOrgAdminRoleRows(roles []Role, users []OrgAdminUserRow, invites []OrgA
 	return rows
 }
 
-func buildOrgAdminUserRowsFromIdentity(rolePills []OrgAdminRoleOption, users []IdentityUser) []OrgAdminUserRow {
+func buildOrgAdminUserRowsFromIdentity(rolePills []OrgAdminRoleOption, users []IdentityUser, rolesVersions map[string]int64) []OrgAdminUserRow {
 	orgUsers := make([]OrgAdminUserRow, 0, len(users))
 	for _, orgUser := range users {
 		if isPlatformAdminIdentityUser(orgUser) {
@@ -3783,35 +4502,88 @@ func buildOrgAdminUserRowsFromIdentity(rolePills []OrgAdminRoleOption, users []I
 			userID = strings.TrimSpace(orgUser.Email)
 		}
 		orgUsers = append(orgUsers, OrgAdminUserRow{
-			UserID:      userID,
-			Email:       orgUser.Email,
-			Status:      orgUser.Status,
-			Activated:   !strings.EqualFold(strings.TrimSpace(orgUser.Status), "pending") && !strings.EqualFold(strings.TrimSpace(orgUser.Status), "invited"),
-			IsOrgAdmin:  orgUser.IsOrgAdmin,
-			RoleOptions: roleOptions,
+			UserID:       userID,
+			Email:        orgUser.Email,
+			Status:       orgUser.Status,
+			Activated:    !strings.EqualFold(strings.TrimSpace(orgUser.Status), "pending") && !strings.EqualFold(strings.TrimSpace(orgUser.Status), "invited"),
+			IsOrgAdmin:   orgUser.IsOrgAdmin,
+			RoleOptions:  roleOptions,
+			RolesVersion: identityRolesVersion(orgUser, rolesVersions[userID]),
 		})
 	}
 	return orgUsers
 }
 
-func buildOrgAdminInviteRowsFromMemberships(memberships []IdentityMembership, now time.Time) []OrgAdminInviteRow {
-	orgInvites := make([]OrgAdminInviteRow, 0, len(memberships))
-	for _, membership := range memberships {
-		status := "accepted"
-		if !membership.Confirmed {
-			status = "pending"
-			if !membership.InvitedAt.IsZero() && membership.InvitedAt.Add(7*24*time.Hour).Before(now) {
-				status = "expired"
-			}
-		}
-		roleSlugs := append([]string(nil), membership.RoleSlugs...)
-		if membership.IsOrgAdmin {
-			roleSlugs = canonifyRoleSlugs(append(roleSlugs, "org-admin"))
-		}
-		expiresAt := time.Time{}
-		if !membership.InvitedAt.IsZero() {
-			expiresAt = membership.InvitedAt.Add(7 * 24 * time.Hour)
-		}
+func orgAdminMembersPath(query string, page int) string {
+	values := url.Values{}
+	if trimmedQuery := strings.TrimSpace(query); trimmedQuery != "" {
+		values.Set("q", trimmedQuery)
+	}
+	if page > 1 {
+		values.Set("page", strconv.Itoa(page))
+	}
+	if encoded := values.Encode(); encoded != "" {
+		return organizationPath("members") + "?" + encoded
+	}
+	return organizationPath("members")
+}
+
+func filterOrgAdminUserRows(users []OrgAdminUserRow, query string) []OrgAdminUserRow {
+	trimmedQuery := strings.ToLower(strings.TrimSpace(query))
+	if trimmedQuery == "" {
+		return users
+	}
+	filtered := make([]OrgAdminUserRow, 0, len(users))
+	for _, user := range users {
+		if strings.Contains(strings.ToLower(user.Email), trimmedQuery) {
+			filtered = append(filtered, user)
+		}
+	}
+	return filtered
+}
+
+// pageOrgAdminUserRows filters users by email substring and returns the
+// requested page of rows, clamping page to the available range.
+func pageOrgAdminUserRows(users []OrgAdminUserRow, query string, page int) ([]OrgAdminUserRow, OrgAdminUserListView) {
+	query = strings.TrimSpace(query)
+	filtered := filterOrgAdminUserRows(users, query)
+	totalPages := 1
+	if len(filtered) > 0 {
+		totalPages = (len(filtered) + orgAdminUsersPerPage - 1) / orgAdminUsersPerPage
+	}
+	currentPage := min(max(page, 1), totalPages)
+	start := min((currentPage-1)*orgAdminUsersPerPage, len(filtered))
+	end := min(start+orgAdminUsersPerPage, len(filtered))
+
+	pageLinks := make([]PaginationLink, 0, totalPages)
+	for pageNum := 1; pageNum <= totalPages; pageNum++ {
+		pageLinks = append(pageLinks, PaginationLink{
+			Page:      pageNum,
+			URL:       orgAdminMembersPath(query, pageNum),
+			IsCurrent: pageNum == currentPage,
+		})
+	}
+	return filtered[start:end], OrgAdminUserListView{
+		Query:           query,
+		MatchedCount:    len(filtered),
+		CurrentPage:     currentPage,
+		TotalPages:      totalPages,
+		PageLinks:       pageLinks,
+		HasPreviousPage: currentPage > 1,
+		HasNextPage:     currentPage < totalPages,
+		PreviousURL:     orgAdminMembersPath(query, max(currentPage-1, 1)),
+		NextURL:         orgAdminMembersPath(query, min(currentPage+1, totalPages)),
+	}
+}
+
+func buildOrgAdminInviteRowsFromMemberships(memberships []IdentityMembership, now time.Time) []OrgAdminInviteRow {
+	orgInvites := make([]OrgAdminInviteRow, 0, len(memberships))
+	for _, membership := range memberships {
+		status, expiresAt := membershipInviteStatus(membership, now)
+		roleSlugs := append([]string(nil), membership.RoleSlugs...)
+		if membership.IsOrgAdmin {
+			roleSlugs = canonifyRoleSlugs(append(roleSlugs, "org-admin"))
+		}
 		var usedAt *time.Time
 		if !membership.JoinedAt.IsZero() {
 			joinedAt := membership.JoinedAt
@@ -3847,7 +4619,14 @@ func (s *Server) loadOrgAdminState(ctx context.Context, user *AccountUser, orgSl
 	if identityUsersErr != nil {
 		return Organization{}, nil, nil, nil, identityUsersErr
 	}
-	orgUsers := buildOrgAdminUserRowsFromIdentity(rolePills, identityUsers)
+	var rolesVersions map[string]int64
+	if s.store != nil {
+		rolesVersions, err = s.store.ListUserRolesVersions(ctx)
+		if err != nil {
+			return Organization{}, nil, nil, nil, err
+		}
+	}
+	orgUsers := buildOrgAdminUserRowsFromIdentity(rolePills, identityUsers, rolesVersions)
 
 	if memberships, membershipsErr := s.identity.ListOrganizationMemberships(ctx, org.Slug); membershipsErr == nil {
 		return org, roles, orgUsers, buildOrgAdminInviteRowsFromMemberships(memberships, s.nowUTC()), nil
@@ -3895,6 +4674,8 @@ func (s *Server) renderOrgAdminWithErrors(w http.ResponseWriter, r *http.Request
 	}
 	rolePills := buildOrgAdminRolePills(roles)
 	roleRows := buildOrgAdminRoleRows(roles, orgUsers, orgInvites)
+	userQuery, userPage := platformAdminListStateFromRequest(r)
+	pagedUsers, userList := pageOrgAdminUserRows(orgUsers, userQuery, userPage)
 
 	view := OrgAdminView{
 		PageBase: s.pageBaseForUser(user, "org_admin_body", "", ""),
@@ -3902,6 +4683,7 @@ func (s *Server) renderOrgAdminWithErrors(w http.ResponseWriter, r *http.Request
 		ActivePanel:            activePanel,
 		Organization:           org,
 		OrganizationLogoURL:    organizationPath("logo/" + strings.TrimSpace(org.LogoAttachmentID)),
+		AllowedEmailDomains:    strings.Join(org.AllowedEmailDomains, ", "),
 		NeedsOrganizationSetup: false,
 		OrganizationError:      errs.Organization,
 		RoleError:              errs.Role,
@@ -3914,7 +4696,8 @@ func (s *Server) renderOrgAdminWithErrors(w http.ResponseWriter, r *http.Request
 		Roles:                  roles,
 		RolePills:              rolePills,
 		RoleRows:               roleRows,
-		Users:                  orgUsers,
+		Users:                  pagedUsers,
+		UserList:               userList,
 		Invites:                orgInvites,
 		InviteLink:             strings.TrimSpace(inviteLink),
 		Error:                  firstNonEmpty(errs.Organization, errs.Role, errs.Invite, errs.Users),
@@ -3975,20 +4758,26 @@ func (s *Server) handleOrgAdminLogo(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write(logo.Data)
 }
 
+// handleOrganizationLogo serves an organization's logo to signed-in users.
+// Orgs with PublicBranding serve it without a session too, so /login?org=
+// can show it; for every other slug anonymous visitors are sent to login
+// before learning whether the org exists.
 func (s *Server) handleOrganizationLogo(w http.ResponseWriter, r *http.Request) {
-	if _, _, ok := s.requireAuthenticatedPage(w, r); !ok {
-		return
-	}
 	if r.Method != http.MethodGet {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 	orgSlug := strings.Trim(strings.TrimPrefix(r.URL.Path, "/organization/logo/"), "/")
-	if orgSlug == "" || s.identity == nil {
-		http.NotFound(w, r)
-		return
+	var org *IdentityOrg
+	var err error
+	if orgSlug != "" && s.identity != nil {
+		org, err = s.identity.GetOrganizationBySlug(r.Context(), orgSlug)
+	}
+	if org == nil || !org.PublicBranding {
+		if _, _, ok := s.requireAuthenticatedPage(w, r); !ok {
+			return
+		}
 	}
-	org, err := s.identity.GetOrganizationBySlug(r.Context(), orgSlug)
 	if err != nil || org == nil || strings.TrimSpace(org.LogoFileID) == "" {
 		if err != nil && !errors.Is(err, ErrIdentityNotFound) {
 			logRequestError(r, err, "failed to load organization %s logo metadata", orgSlug)
@@ -4101,7 +4890,7 @@ func (s *Server) handleOrgAdminRoles(w http.ResponseWriter, r *http.Request) {
 			s.logAndRenderOrgAdminError(w, r, user, user.OrgSlug, "", OrgAdminErrors{Role: "failed to load organization users"}, membershipsErr, "failed to list organization memberships for role action in %s", user.OrgSlug)
 			return
 		}
-		roleRows := buildOrgAdminRoleRows(rolesFromIdentityOrg(*org), buildOrgAdminUserRowsFromIdentity(buildOrgAdminRolePills(rolesFromIdentityOrg(*org)), orgUsers), buildOrgAdminInviteRowsFromMemberships(memberships, s.nowUTC()))
+		roleRows := buildOrgAdminRoleRows(rolesFromIdentityOrg(*org), buildOrgAdminUserRowsFromIdentity(buildOrgAdminRolePills(rolesFromIdentityOrg(*org)), orgUsers, nil), buildOrgAdminInviteRowsFromMemberships(memberships, s.nowUTC()))
 
 		findRoleRow := func(roleSlug string) *OrgAdminRoleRow {
 			for idx := range roleRows {
@@ -4364,102 +5153,15 @@ func (s *Server) handleOrgAdminUsers(w http.ResponseWriter, r *http.Request) {
 			http.NotFound(w, r)
 			return
 		}
-		selectedRoles := requestedRoleSlugs(r.Form)
-		allowedRoles := ensureOrgAdminRoleOption(rolesFromIdentityOrg(*org))
-		allowed := make(map[string]struct{}, len(allowedRoles))
-		for _, role := range allowedRoles {
-			allowed[strings.TrimSpace(role.Slug)] = struct{}{}
-		}
-		for _, roleSlug := range selectedRoles {
-			if _, ok := allowed[strings.TrimSpace(roleSlug)]; !ok {
-				s.renderOrgAdminWithErrors(w, r, admin, admin.OrgSlug, "", OrgAdminErrors{Invite: "role not found"})
-				return
-			}
-		}
-		isOrgAdmin := containsRole(selectedRoles, "org-admin")
-		businessRoles := make([]string, 0, len(selectedRoles))
-		for _, roleSlug := range selectedRoles {
-			if containsRole([]string{roleSlug}, "org-admin") || containsRole([]string{roleSlug}, "org_admin") {
-				isOrgAdmin = true
-				continue
-			}
-			businessRoles = append(businessRoles, roleSlug)
-		}
-		memberships, err := s.identity.ListOrganizationMemberships(r.Context(), admin.OrgSlug)
-		if err != nil {
-			s.logAndRenderOrgAdminError(w, r, admin, admin.OrgSlug, "", OrgAdminErrors{Invite: "failed to create invite"}, err, "failed to list memberships for organization %s during invite", admin.OrgSlug)
-			return
-		}
-		for _, membership := range memberships {
-			if !strings.EqualFold(strings.TrimSpace(membership.Email), email) {
-				continue
-			}
-			if membership.Confirmed {
-				labels := make([]string, 0, len(businessRoles)+1)
-				for _, roleSlug := range businessRoles {
-					labels = append(labels, encodeIdentityRoleLabel(roleSlug))
-				}
-				if isOrgAdmin {
-					labels = append(labels, identityOrgAdminLabel)
-				}
-				if _, err := s.identity.UpdateUserLabels(r.Context(), membership.UserID, labels); err != nil {
-					s.logAndRenderOrgAdminError(w, r, admin, admin.OrgSlug, "", OrgAdminErrors{Invite: "failed to update user roles"}, err, "failed to update labels for invited member %s in organization %s", membership.UserID, admin.OrgSlug)
-					return
-				}
-				http.Redirect(w, r, organizationPath("members"), http.StatusSeeOther)
-				return
-			}
-			if roleSlugsKey(append(append([]string{}, membership.RoleSlugs...), func() []string {
-				if membership.IsOrgAdmin {
-					return []string{"org-admin"}
-				}
-				return nil
-			}()...)) == roleSlugsKey(selectedRoles) {
-				http.Redirect(w, r, organizationPath("members"), http.StatusSeeOther)
-				return
-			}
-			sessionSecret, err := sessionSecretFromRequest(r)
-			if err != nil {
-				logAndHTTPError(w, r, http.StatusUnauthorized, "unauthorized", err, "failed to read session secret for membership update in %s", admin.OrgSlug)
-				return
-			}
-			if _, err := s.identity.UpdateOrganizationMembership(r.Context(), sessionSecret, admin.OrgSlug, membership.ID, businessRoles, isOrgAdmin); err != nil {
-				s.logAndRenderOrgAdminError(w, r, admin, admin.OrgSlug, "", OrgAdminErrors{Invite: "failed to create invite"}, err, "failed to update membership %s in organization %s", membership.ID, admin.OrgSlug)
-				return
-			}
-			http.Redirect(w, r, organizationPath("members"), http.StatusSeeOther)
-			return
-		}
-		existingUser, err := s.identity.GetUserByEmail(r.Context(), email)
-		switch {
-		case err == nil && existingUser.OrgSlug != "" && !strings.EqualFold(strings.TrimSpace(existingUser.OrgSlug), strings.TrimSpace(admin.OrgSlug)):
-			s.renderOrgAdminWithErrors(w, r, admin, admin.OrgSlug, "", OrgAdminErrors{Invite: "email already belongs to another organization"})
-			return
-		case err == nil && strings.EqualFold(strings.TrimSpace(existingUser.OrgSlug), strings.TrimSpace(admin.OrgSlug)):
-			labels := make([]string, 0, len(businessRoles)+1)
-			for _, roleSlug := range businessRoles {
-				labels = append(labels, encodeIdentityRoleLabel(roleSlug))
-			}
-			if isOrgAdmin {
-				labels = append(labels, identityOrgAdminLabel)
-			}
-			if _, err := s.identity.UpdateUserLabels(r.Context(), existingUser.ID, labels); err != nil {
-				s.logAndRenderOrgAdminError(w, r, admin, admin.OrgSlug, "", OrgAdminErrors{Invite: "failed to update user roles"}, err, "failed to update labels for existing user %s in organization %s", existingUser.ID, admin.OrgSlug)
-				return
+		if _, inviteErr := s.inviteOrganizationMember(r, admin, org, email, requestedRoleSlugs(r.Form)); inviteErr != nil {
+			switch {
+			case inviteErr.Unauthorized:
+				logAndHTTPError(w, r, http.StatusUnauthorized, "unauthorized", inviteErr.Err, "%s", inviteErr.LogMessage)
+			case inviteErr.Err != nil:
+				s.logAndRenderOrgAdminError(w, r, admin, admin.OrgSlug, "", OrgAdminErrors{Invite: inviteErr.Message}, inviteErr.Err, "%s", inviteErr.LogMessage)
+			default:
+				s.renderOrgAdminWithErrors(w, r, admin, admin.OrgSlug, "", OrgAdminErrors{Invite: inviteErr.Message})
 			}
-			http.Redirect(w, r, organizationPath("members"), http.StatusSeeOther)
-			return
-		case err != nil && !errors.Is(err, ErrIdentityNotFound):
-			s.logAndRenderOrgAdminError(w, r, admin, admin.OrgSlug, "", OrgAdminErrors{Invite: "failed to load existing user"}, err, "failed to look up existing user %s during invite", email)
-			return
-		}
-		sessionSecret, err := sessionSecretFromRequest(r)
-		if err != nil {
-			logAndHTTPError(w, r, http.StatusUnauthorized, "unauthorized", err, "failed to read session secret for invite creation in %s", admin.OrgSlug)
-			return
-		}
-		if _, err := s.identity.InviteOrganizationUser(r.Context(), sessionSecret, admin.OrgSlug, email, inviteRedirectURL(r), businessRoles, isOrgAdmin); err != nil {
-			s.logAndRenderOrgAdminError(w, r, admin, admin.OrgSlug, "", OrgAdminErrors{Invite: "failed to create invite"}, err, "failed to create invite for %s in organization %s", email, admin.OrgSlug)
 			return
 		}
 		http.Redirect(w, r, organizationPath("members"), http.StatusSeeOther)
@@ -4484,6 +5186,18 @@ func (s *Server) handleOrgAdminUsers(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 		}
+		emailDomains := org.AllowedEmailDomains
+		if _, ok := r.Form["allowed_email_domains"]; ok {
+			emailDomains, err = normalizeEmailDomains(strings.Split(r.FormValue("allowed_email_domains"), ","))
+			if err != nil {
+				s.renderOrgAdminWithErrors(w, r, admin, admin.OrgSlug, "", OrgAdminErrors{Organization: err.Error()})
+				return
+			}
+		}
+		publicBranding := org.PublicBranding
+		if values, ok := r.Form["public_branding"]; ok {
+			publicBranding = values[len(values)-1] == "true"
+		}
 		logoUpload, logoErrMsg := s.readOrganizationLogoUpload(r)
 		if logoErrMsg != "" {
 			s.renderOrgAdminWithErrors(w, r, admin, admin.OrgSlug, "", OrgAdminErrors{Organization: logoErrMsg})
@@ -4517,6 +5231,18 @@ func (s *Server) handleOrgAdminUsers(w http.ResponseWriter, r *http.Request) {
 			s.logAndRenderOrgAdminError(w, r, admin, admin.OrgSlug, "", OrgAdminErrors{Organization: "failed to update organization"}, err, "failed to update organization %s", admin.OrgSlug)
 			return
 		}
+		if strings.Join(emailDomains, ",") != strings.Join(org.AllowedEmailDomains, ",") {
+			if _, err := s.identity.UpdateOrganizationEmailDomains(r.Context(), sessionSecret, updatedOrg.Slug, emailDomains); err != nil {
+				s.logAndRenderOrgAdminError(w, r, admin, updatedOrg.Slug, "", OrgAdminErrors{Organization: "failed to update allowed email domains"}, err, "failed to update allowed email domains of organization %s", updatedOrg.Slug)
+				return
+			}
+		}
+		if publicBranding != org.PublicBranding {
+			if _, err := s.identity.UpdateOrganizationPublicBranding(r.Context(), sessionSecret, updatedOrg.Slug, publicBranding); err != nil {
+				s.logAndRenderOrgAdminError(w, r, admin, updatedOrg.Slug, "", OrgAdminErrors{Organization: "failed to update public branding"}, err, "failed to update public branding of organization %s", updatedOrg.Slug)
+				return
+			}
+		}
 		if logoUpload != nil && previousLogoFileID != "" && previousLogoFileID != strings.TrimSpace(updatedOrg.LogoFileID) {
 			if err := s.identity.DeleteOrganizationLogo(r.Context(), previousLogoFileID); err != nil && !errors.Is(err, ErrIdentityNotFound) {
 				log.Printf("failed to delete previous organization logo %q: %v", previousLogoFileID, err)
@@ -4558,6 +5284,15 @@ func (s *Server) handleOrgAdminUsers(w http.ResponseWriter, r *http.Request) {
 			s.renderOrgAdminWithErrors(w, r, admin, admin.OrgSlug, "", OrgAdminErrors{Users: "user not found"})
 			return
 		}
+		staleRoles := "roles of " + target.Email + " were changed by someone else; review them and try again"
+		rolesVersion := strings.TrimSpace(r.FormValue("rolesVersion"))
+		seqText, _, _ := strings.Cut(rolesVersion, ":")
+		expectedSeq, seqErr := strconv.ParseInt(seqText, 10, 64)
+		if seqErr != nil || rolesVersion != identityRolesVersion(*target, expectedSeq) {
+			w.WriteHeader(http.StatusConflict)
+			s.renderOrgAdminWithErrors(w, r, admin, admin.OrgSlug, "", OrgAdminErrors{Users: staleRoles})
+			return
+		}
 		selectedRoles := requestedRoleSlugs(r.Form)
 		allowedRoles := ensureOrgAdminRoleOption(rolesFromIdentityOrg(*org))
 		allowed := make(map[string]struct{}, len(allowedRoles))
@@ -4592,11 +5327,21 @@ func (s *Server) handleOrgAdminUsers(w http.ResponseWriter, r *http.Request) {
 		if isOrgAdmin {
 			labels = append(labels, identityOrgAdminLabel)
 		}
+		if s.store != nil {
+			if err := s.store.ClaimUserRolesVersion(r.Context(), userID, expectedSeq); errors.Is(err, ErrRolesVersionConflict) {
+				w.WriteHeader(http.StatusConflict)
+				s.renderOrgAdminWithErrors(w, r, admin, admin.OrgSlug, "", OrgAdminErrors{Users: staleRoles})
+				return
+			} else if err != nil {
+				s.logAndRenderOrgAdminError(w, r, admin, admin.OrgSlug, "", OrgAdminErrors{Users: "failed to update user roles"}, err, "failed to claim roles version of user %s in organization %s", target.ID, admin.OrgSlug)
+				return
+			}
+		}
 		if _, err := s.identity.UpdateUserLabels(r.Context(), target.ID, labels); err != nil {
 			s.logAndRenderOrgAdminError(w, r, admin, admin.OrgSlug, "", OrgAdminErrors{Users: "failed to update user roles"}, err, "failed to update labels for user %s in organization %s", target.ID, admin.OrgSlug)
 			return
 		}
-		http.Redirect(w, r, organizationPath("members"), http.StatusSeeOther)
+		http.Redirect(w, r, orgAdminMembersPath(platformAdminListStateFromRequest(r)), http.StatusSeeOther)
 	case "delete_user":
 		userID := strings.TrimSpace(r.FormValue("userId"))
 		if userID == "" {
@@ -4654,8 +5399,11 @@ func (s *Server) handleOrgAdminUsers(w http.ResponseWriter, r *http.Request) {
 				s.logAndRenderOrgAdminError(w, r, admin, admin.OrgSlug, "", OrgAdminErrors{Users: "failed to delete user"}, err, "failed to clear labels for deleted user %s in organization %s", target.UserID, admin.OrgSlug)
 				return
 			}
+			if err := s.identity.DeleteUserSessions(r.Context(), target.UserID); err != nil && !errors.Is(err, ErrIdentityNotFound) {
+				logRequestError(r, err, "failed to revoke sessions for deleted user %s in organization %s", target.UserID, admin.OrgSlug)
+			}
 		}
-		http.Redirect(w, r, organizationPath("members"), http.StatusSeeOther)
+		http.Redirect(w, r, orgAdminMembersPath(platformAdminListStateFromRequest(r)), http.StatusSeeOther)
 	default:
 		s.renderOrgAdminWithErrors(w, r, admin, admin.OrgSlug, "", OrgAdminErrors{Users: "unsupported action"})
 	}
@@ -4705,7 +5453,7 @@ func (s *Server) handleStreamRoutes(w http.ResponseWriter, r *http.Request) {
 		http.NotFound(w, r)
 		return
 	}
-	scopedReq := r.WithContext(context.WithValue(r.Context(), workflowContextKey{}, workflowContextValue{
+	scopedReq := r.WithContext(context.WithValue(withLabelLocale(r.Context(), r.URL.Query().Get("locale")), workflowContextKey{}, workflowContextValue{
 		Key: workflowKey,
 		Cfg: cfg,
 	}))
@@ -4715,9 +5463,15 @@ func (s *Server) handleStreamRoutes(w http.ResponseWriter, r *http.Request) {
 	}
 	tail := "/" + strings.Join(parts[1:], "/")
 	switch {
+	case tail == "/preview":
+		s.handleStartSimulation(w, cloneRequestWithPath(scopedReq, tail))
+		return
 	case tail == "/instance/start":
 		s.handleStartProcess(w, cloneRequestWithPath(scopedReq, tail))
 		return
+	case tail == "/instance/new":
+		s.handleNewProcessForm(w, cloneRequestWithPath(scopedReq, tail))
+		return
 	case tail == "/delete":
 		s.handleDeleteWorkflow(w, cloneRequestWithPath(scopedReq, tail))
 		return
@@ -4727,6 +5481,35 @@ func (s *Server) handleStreamRoutes(w http.ResponseWriter, r *http.Request) {
 	case tail == "/events":
 		s.handleEvents(w, cloneRequestWithPath(scopedReq, tail))
 		return
+	case tail == "/processes":
+		s.handleListProcesses(w, cloneRequestWithPath(scopedReq, tail))
+		return
+	case tail == "/processes/search":
+		s.handleSearchProcesses(w, cloneRequestWithPath(scopedReq, tail))
+		return
+	case tail == "/processes/export":
+		s.handleProcessesExport(w, cloneRequestWithPath(scopedReq, tail))
+		return
+	case tail == "/analytics":
+		s.handleWorkflowAnalytics(w, cloneRequestWithPath(scopedReq, tail))
+		return
+	case tail == "/stuck":
+		s.handleStuckProcesses(w, cloneRequestWithPath(scopedReq, tail))
+		return
+	case tail == "/roles.json":
+		s.handleWorkflowRoles(w, cloneRequestWithPath(scopedReq, tail))
+		return
+	case tail == "/dashboard/counts.json":
+		s.handleStreamDashboardCounts(w, cloneRequestWithPath(scopedReq, tail))
+		return
+	case strings.HasPrefix(tail, "/roles/") && strings.HasSuffix(tail, "/substeps.json"):
+		role := strings.TrimSuffix(strings.TrimPrefix(tail, "/roles/"), "/substeps.json")
+		if strings.Contains(role, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		s.handleRoleSubsteps(w, cloneRequestWithPath(scopedReq, tail), role)
+		return
 	default:
 		http.NotFound(w, r)
 	}
@@ -4817,13 +5600,19 @@ func (s *Server) handleDeleteWorkflow(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) buildWorkflowHomeView(ctx context.Context, r *http.Request, user *AccountUser, workflowKey string, cfg RuntimeConfig, workflowError string) HomeView {
 	sortKey := normalizeHomeSortKey(strings.TrimSpace(r.URL.Query().Get("sort")))
-	statusFilter := normalizeHomeStatusFilter(r.URL.Query().Get("filter"))
+	rawFilter := r.URL.Query().Get("filter")
+	if strings.TrimSpace(rawFilter) == "" {
+		rawFilter = r.URL.Query().Get("status")
+	}
+	statusFilter := normalizeHomeStatusFilter(rawFilter)
+	dateRange := parseHomeDateRange(r.URL.Query())
 	page := parsePositiveInt(r.URL.Query().Get("page"), 1)
 	processesRaw, err := s.store.ListRecentProcessesByWorkflow(ctx, workflowKey, 0)
 	if err != nil {
 		logRequestError(r, err, "failed to list recent processes for workflow %s", workflowKey)
 		processesRaw = nil
 	}
+	processesRaw = s.visibleProcesses(user, cfg, processesRaw)
 	actor := actorFromAccountUser(user, workflowKey)
 	if len(actor.RoleSlugs) == 0 && !s.enforceAuth {
 		actor.RoleSlugs = s.roles(cfg)
@@ -4834,9 +5623,13 @@ func (s *Server) buildWorkflowHomeView(ctx context.Context, r *http.Request, use
 	roleMeta := s.roleMetaIndex(ctx)
 
 	totalSubsteps := countWorkflowSubsteps(cfg.Workflow)
+	creatorCache := map[string]userIdentityView{}
 	var processes []StreamInstanceCard
 	path := streamPath(workflowKey)
 	for _, process := range processesRaw {
+		if !dateRange.contains(process.CreatedAt) {
+			continue
+		}
 		process.Progress = normalizeProgressKeys(process.Progress)
 		status := deriveProcessStatus(cfg.Workflow, &process)
 		doneCount, lastDoneAt, lastDigest := processProgressStats(cfg.Workflow, &process)
@@ -4846,13 +5639,16 @@ func (s *Server) buildWorkflowHomeView(ctx context.Context, r *http.Request, use
 		}
 		item := StreamInstanceCard{
 			ID:                 process.ID.Hex(),
+			Code:               strings.TrimSpace(process.Code),
 			Name:               strings.TrimSpace(process.Name),
 			Status:             status,
 			StatusLabel:        processStatusLabel(status),
-			DetailHref:         streamInstancePath(workflowKey, process.ID.Hex()),
+			DetailHref:         streamInstancePath(workflowKey, processRef(&process)),
 			CreatedAt:          humanReadableTraceabilityTime(process.CreatedAt),
 			CreatedAtISO:       rfc3339UTC(process.CreatedAt),
 			CreatedAtTime:      process.CreatedAt,
+			UpdatedAtTime:      processLastActivity(&process),
+			CreatedBy:          s.createdByDisplay(ctx, cfg.Workflow, actor, process.CreatedBy, creatorCache),
 			DoneSubsteps:       doneCount,
 			TotalSubsteps:      totalSubsteps,
 			Percent:            percent,
@@ -4870,7 +5666,10 @@ func (s *Server) buildWorkflowHomeView(ctx context.Context, r *http.Request, use
 	}
 
 	filterOptions := buildHomeFilterOptions(processes)
-	activeGroup := buildHomeActiveProcessGroup(path, processes, statusFilter, sortKey, page)
+	for idx := range filterOptions {
+		filterOptions[idx].URL = withHomeDateRange(homePaginationURL(path, filterOptions[idx].Status, sortKey, 1), dateRange)
+	}
+	activeGroup := applyHomeDateRange(buildHomeActiveProcessGroup(path, processes, statusFilter, sortKey, page), dateRange)
 
 	preview := makeStreamInstanceDetailReadOnly(
 		s.buildStreamInstanceDetailView(ctx, cfg, workflowKey, buildWorkflowPreviewProcess(cfg.Workflow, workflowKey), actor, "", "", false),
@@ -4878,16 +5677,33 @@ func (s *Server) buildWorkflowHomeView(ctx context.Context, r *http.Request, use
 	)
 	preview.HideStatus = true
 
+	var roleWarnings []string
+	if workflowError == "" && user != nil && (user.IsPlatformAdmin || userIsOrgAdmin(user)) {
+		roleWarnings, err = s.unassignedWorkflowRoleWarnings(ctx, cfg)
+		if err != nil {
+			logRequestError(r, err, "failed to count role holders for workflow %s", workflowKey)
+			roleWarnings = nil
+		}
+	}
+
 	return HomeView{
-		PageBase:            s.pageBaseForUser(user, "home_body", workflowKey, cfg.Workflow.Name),
-		Breadcrumbs:         buildStreamBreadcrumbs(workflowKey, cfg.Workflow.Name),
-		WorkflowDescription: strings.TrimSpace(cfg.Workflow.Description),
-		Error:               workflowError,
-		Sort:                sortKey,
-		StatusFilter:        statusFilter,
-		FilterOptions:       filterOptions,
-		ProcessGroups:       []ProcessStatusGroup{activeGroup},
-		Preview:             preview,
+		PageBase:                s.pageBaseForUser(user, "home_body", workflowKey, cfg.Workflow.Name),
+		Breadcrumbs:             buildStreamBreadcrumbs(workflowKey, cfg.Workflow.Name),
+		WorkflowDescription:     strings.TrimSpace(cfg.Workflow.Description),
+		WorkflowDescriptionHTML: renderMarkdown(cfg.Workflow.Description),
+		Error:                   workflowError,
+		Sort:                    sortKey,
+		StatusFilter:            statusFilter,
+		DateFrom:                homeDateInputValue(dateRange.FromValue),
+		DateTo:                  homeDateInputValue(dateRange.ToValue),
+		DateFields:              dateRange.fields(),
+		DateClearURL:            homePaginationURL(path, statusFilter, sortKey, 1),
+		FilterOptions:           filterOptions,
+		ProcessGroups:           []ProcessStatusGroup{activeGroup},
+		Preview:                 preview,
+		CanStart:                s.canStartWorkflow(cfg.Workflow, user),
+		StartURL:                newProcessFormURL(workflowKey, cfg),
+		RoleWarnings:            roleWarnings,
 	}
 }
 
@@ -4904,6 +5720,10 @@ func (s *Server) renderStreamDashboardResults(w http.ResponseWriter, view HomeVi
 }
 
 func (s *Server) handleWorkflowHome(w http.ResponseWriter, r *http.Request) {
+	if prefersJSONResponse(r) {
+		s.handleWorkflowHomeJSON(w, r)
+		return
+	}
 	user, _, ok := s.requireAuthenticatedPage(w, r)
 	if !ok {
 		return
@@ -4915,18 +5735,21 @@ func (s *Server) handleWorkflowHome(w http.ResponseWriter, r *http.Request) {
 	}
 	ctx := r.Context()
 	workflowError := homePickerMessage(r, "error")
+	var refErr *WorkflowRefValidationError
 	if validationErr := s.validateWorkflowRefs(ctx, cfg); validationErr != nil {
-		var refErr *WorkflowRefValidationError
 		if !errors.As(validationErr, &refErr) {
 			http.Error(w, validationErr.Error(), http.StatusInternalServerError)
 			return
 		}
 		if workflowError == "" {
-			workflowError = refErr.Error()
+			workflowError = workflowUnavailableMessage
 		}
 	}
 
 	view := s.buildWorkflowHomeView(ctx, r, user, workflowKey, cfg, workflowError)
+	if refErr != nil && canSeeWorkflowRefProblems(user) {
+		view.ConfigProblems = workflowRefProblemViews(user, refErr.Problems)
+	}
 	if isHTMXRequest(r) {
 		s.renderStreamDashboardResults(w, view)
 		return
@@ -4934,22 +5757,275 @@ func (s *Server) handleWorkflowHome(w http.ResponseWriter, r *http.Request) {
 	s.renderStreamDashboard(w, view)
 }
 
+// handleWorkflowHomeJSON serves the stream dashboard as JSON for API clients
+// (?format=json or Accept: application/json). Terminated instances are left
+// out; they remain available from the /processes list.
+func (s *Server) handleWorkflowHomeJSON(w http.ResponseWriter, r *http.Request) {
+	user, _, ok := s.requireAuthenticatedPost(w, r)
+	if !ok {
+		return
+	}
+	workflowKey, cfg, err := s.selectedWorkflowUnvalidated(r)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	response, err := s.streamDashboardForUser(r.Context(), user, workflowKey, cfg, s.dashboardListLimit())
+	if err != nil {
+		logAndHTTPError(w, r, http.StatusInternalServerError, "failed to list processes", err, "failed to list processes for workflow %s", workflowKey)
+		return
+	}
+	writeJSON(w, response)
+}
+
+// handleStreamDashboardCounts serves GET /my/streams/:key/dashboard/counts.json:
+// the todo/active/done totals of the caller's stream dashboard without the
+// lists themselves.
+func (s *Server) handleStreamDashboardCounts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, _, ok := s.requireAuthenticatedPost(w, r)
+	if !ok {
+		return
+	}
+	workflowKey, cfg, err := s.selectedWorkflowUnvalidated(r)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	// A limit of 1 keeps the collected lists tiny; the totals ignore it.
+	dashboard, err := s.streamDashboardForUser(r.Context(), user, workflowKey, cfg, 1)
+	if err != nil {
+		logAndHTTPError(w, r, http.StatusInternalServerError, "failed to count processes", err, "failed to count processes for workflow %s", workflowKey)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, StreamDashboardCounts{
+		Todo:   dashboard.TodoTotal,
+		Active: dashboard.ActiveTotal,
+		Done:   len(dashboard.DoneProcesses),
+	})
+}
+
+// streamDashboardForUser collects the todo actions and active/done instances
+// of one workflow as seen by user. limit caps the todo and active lists
+// (0 = no cap); the totals always count everything.
+func (s *Server) streamDashboardForUser(ctx context.Context, user *AccountUser, workflowKey string, cfg RuntimeConfig, limit int) (StreamDashboardResponse, error) {
+	processes, err := s.store.ListRecentProcessesByWorkflow(ctx, workflowKey, 0)
+	if err != nil {
+		return StreamDashboardResponse{}, err
+	}
+	processes = s.visibleProcesses(user, cfg, processes)
+	actor := actorFromAccountUser(user, workflowKey)
+	if len(actor.RoleSlugs) == 0 && !s.enforceAuth {
+		actor.RoleSlugs = s.roles(cfg)
+		if len(actor.RoleSlugs) > 0 {
+			actor.Role = actor.RoleSlugs[0]
+		}
+	}
+	roleMeta := s.roleMetaIndex(ctx)
+
+	response := StreamDashboardResponse{
+		WorkflowKey:     workflowKey,
+		TodoActions:     []TodoAction{},
+		ActiveProcesses: []ProcessListItem{},
+		DoneProcesses:   []ProcessListItem{},
+	}
+	for idx := range processes {
+		process := &processes[idx]
+		process.Progress = normalizeProgressKeys(process.Progress)
+		item := processListItem(workflowKey, cfg, process)
+		switch item.Status {
+		case processStatusDone:
+			response.DoneProcesses = append(response.DoneProcesses, item)
+			continue
+		case processStatusTerminated:
+			continue
+		}
+		response.ActiveTotal++
+		if limit == 0 || len(response.ActiveProcesses) < limit {
+			response.ActiveProcesses = append(response.ActiveProcesses, item)
+		}
+		for _, action := range buildSubstepViews(cfg.Workflow, process, workflowKey, actor, false, roleMeta, cfg.Roles) {
+			if action.Status != "available" || action.Disabled {
+				continue
+			}
+			response.TodoTotal++
+			if limit > 0 && len(response.TodoActions) >= limit {
+				continue
+			}
+			roles := make([]string, 0, len(action.MatchingRoles))
+			for _, role := range action.MatchingRoles {
+				roles = append(roles, role.Slug)
+			}
+			todo := TodoAction{
+				ProcessID:   item.ProcessID,
+				ProcessName: item.Name,
+				SubstepID:   action.SubstepID,
+				Title:       action.Title,
+				Roles:       roles,
+				URL:         item.URL,
+			}
+			if availableAt := substepAvailableAt(cfg.Workflow, process, action.SubstepID); !availableAt.IsZero() {
+				todo.AvailableAt = availableAt.UTC().Format(time.RFC3339)
+			}
+			response.TodoActions = append(response.TodoActions, todo)
+		}
+	}
+	response.Truncated = response.ActiveTotal > len(response.ActiveProcesses) || response.TodoTotal > len(response.TodoActions)
+	return response, nil
+}
+
+const dashboardListLimitDefault = 100
+
+// dashboardListLimitFromEnv reads DASHBOARD_LIST_LIMIT (default 100). Zero
+// disables the cap and comes back as -1 so an unset Server field still means
+// the default.
+func dashboardListLimitFromEnv() int {
+	limit := intEnvOr("DASHBOARD_LIST_LIMIT", dashboardListLimitDefault)
+	if limit <= 0 {
+		return -1
+	}
+	return limit
+}
+
+// dashboardListLimit caps the todo and active lists of the JSON dashboard;
+// 0 means no cap.
+func (s *Server) dashboardListLimit() int {
+	switch {
+	case s.dashboardLimit == 0:
+		return dashboardListLimitDefault
+	case s.dashboardLimit < 0:
+		return 0
+	}
+	return s.dashboardLimit
+}
+
+// handleListProcesses serves the JSON process list for one stream.
+// participant=me narrows it to processes where the caller completed a substep.
+func (s *Server) handleListProcesses(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, _, ok := s.requireAuthenticatedPost(w, r)
+	if !ok {
+		return
+	}
+	workflowKey, cfg, err := s.selectedWorkflowUnvalidated(r)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if s.store == nil {
+		http.Error(w, "store not configured", http.StatusInternalServerError)
+		return
+	}
+
+	metadataKey, metadataValue, hasMetadataFilter, err := parseProcessMetadataFilter(r.URL.Query().Get("metadata"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var processes []Process
+	switch participant := strings.TrimSpace(r.URL.Query().Get("participant")); participant {
+	case "":
+		if hasMetadataFilter {
+			processes, err = s.store.ListProcessesByMetadata(r.Context(), workflowKey, metadataKey, metadataValue)
+		} else {
+			processes, err = s.store.ListRecentProcessesByWorkflow(r.Context(), workflowKey, 0)
+		}
+	case "me":
+		processes, err = s.store.ListProcessesByParticipant(r.Context(), workflowKey, accountActorID(user))
+		if err == nil && hasMetadataFilter {
+			processes = filterProcessesByMetadata(processes, metadataKey, metadataValue)
+		}
+	default:
+		http.Error(w, "unsupported participant filter", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		logAndHTTPError(w, r, http.StatusInternalServerError, "failed to list processes", err, "failed to list processes for workflow %s", workflowKey)
+		return
+	}
+	processes = s.visibleProcesses(user, cfg, processes)
+
+	response := ProcessListResponse{
+		WorkflowKey: workflowKey,
+		Processes:   make([]ProcessListItem, 0, len(processes)),
+	}
+	for idx := range processes {
+		process := &processes[idx]
+		process.Progress = normalizeProgressKeys(process.Progress)
+		response.Processes = append(response.Processes, processListItem(workflowKey, cfg, process))
+	}
+	writeJSON(w, response)
+}
+
+// processLastActivity is when the process was last written to. Processes
+// stored before updatedAt was tracked fall back to their creation time.
+func processLastActivity(process *Process) time.Time {
+	if process == nil {
+		return time.Time{}
+	}
+	if process.UpdatedAt.IsZero() {
+		return process.CreatedAt
+	}
+	return process.UpdatedAt
+}
+
+func processListItem(workflowKey string, cfg RuntimeConfig, process *Process) ProcessListItem {
+	return ProcessListItem{
+		ProcessID: process.ID.Hex(),
+		Name:      strings.TrimSpace(process.Name),
+		CreatedAt: process.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt: processLastActivity(process).UTC().Format(time.RFC3339),
+		CreatedBy: processCreatedBy(process),
+		Status:    deriveProcessStatus(cfg.Workflow, process),
+		URL:       streamInstancePath(workflowKey, process.ID.Hex()),
+		Metadata:  process.Metadata,
+	}
+}
+
 func (s *Server) handleStartProcess(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	user, _, ok := s.requireAuthenticatedPost(w, r)
+	if !ok {
+		return
+	}
 	workflowKey, cfg, ok := s.selectedWorkflowOrRedirectHome(w, r)
 	if !ok {
 		return
 	}
+	if !s.canStartWorkflow(cfg.Workflow, user) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	limitKey := ""
+	if !user.IsPlatformAdmin {
+		limitKey = accountActorID(user) + "|" + workflowKey
+		if allowed, retryAfter := s.startLimiter.Check(limitKey, s.nowUTC()); !allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(int((retryAfter+time.Second-1)/time.Second)))
+			http.Error(w, "too many processes created, try again later", http.StatusTooManyRequests)
+			return
+		}
+	}
 	ctx := r.Context()
+	createdBy := legacyProcessCreator
+	if s.enforceAuth {
+		createdBy = accountActorID(user)
+	}
 	process := Process{
 		WorkflowDefID: s.workflowDefID,
 		WorkflowKey:   workflowKey,
 		Name:          normalizeProcessName(r.FormValue("name")),
 		CreatedAt:     s.nowUTC(),
-		CreatedBy:     "demo",
+		CreatedBy:     createdBy,
 		Status:        "active",
 		Progress:      map[string]ProcessStep{},
 	}
@@ -4958,15 +6034,55 @@ func (s *Server) handleStartProcess(w http.ResponseWriter, r *http.Request) {
 			process.Progress[encodeProgressKey(sub.SubstepID)] = ProcessStep{State: "pending"}
 		}
 	}
-	id, err := s.store.InsertProcess(ctx, process)
+	var completions []startFieldCompletion
+	if len(cfg.Workflow.StartFields) > 0 {
+		process.ID = primitive.NewObjectID()
+		var status int
+		var err error
+		if completions, status, err = s.captureStartFields(r, user, workflowKey, cfg, &process); err != nil {
+			s.startFieldsError(w, r, user, workflowKey, cfg, status, err)
+			return
+		}
+	}
+	if err := s.assignProcessCode(ctx, cfg.Workflow, &process); err != nil {
+		logAndHTTPError(w, r, http.StatusInternalServerError, "failed to number process", err, "failed to assign process code in workflow %s", workflowKey)
+		return
+	}
+	id, err := s.processService().StartProcess(ctx, cfg, &process, completions)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	for _, role := range s.roles(cfg) {
-		s.sse.Broadcast("role:"+workflowKey+":"+role, "role-updated")
+	process.ID = id
+	if limitKey != "" {
+		s.startLimiter.Record(limitKey, s.nowUTC())
+	}
+	s.broadcastRolesUpdated(workflowKey, cfg)
+	http.Redirect(w, r, streamInstancePath(workflowKey, processRef(&process)), http.StatusSeeOther)
+}
+
+// canStartWorkflow reports whether user may start new instances of def. An
+// empty startRoles list keeps the workflow open to every authenticated user;
+// platform admins and unenforced (local dev) servers are always allowed.
+func (s *Server) canStartWorkflow(def WorkflowDef, user *AccountUser) bool {
+	if !workflowEnabled(def) {
+		return false
+	}
+	if len(def.StartRoles) == 0 || !s.enforceAuth {
+		return true
+	}
+	if user == nil {
+		return false
+	}
+	if user.IsPlatformAdmin {
+		return true
+	}
+	for _, role := range def.StartRoles {
+		if containsRole(user.RoleSlugs, role) {
+			return true
+		}
 	}
-	http.Redirect(w, r, streamInstancePath(workflowKey, id.Hex()), http.StatusSeeOther)
+	return false
 }
 
 const maxProcessNameRunes = 80
@@ -4986,12 +6102,16 @@ func normalizeProcessName(input string) string {
 func (s *Server) defaultWorkflowKey() string {
 	catalog, err := s.workflowCatalog()
 	if err == nil {
-		if _, ok := catalog["workflow"]; ok {
+		if cfg, ok := catalog[s.defaultWorkflow]; ok && s.defaultWorkflow != "" && workflowEnabled(cfg.Workflow) {
+			return s.defaultWorkflow
+		}
+		if cfg, ok := catalog["workflow"]; ok && workflowEnabled(cfg.Workflow) {
 			return "workflow"
 		}
-		keys := sortedWorkflowKeys(catalog)
-		if len(keys) > 0 {
-			return keys[0]
+		for _, key := range sortedWorkflowKeys(catalog) {
+			if workflowEnabled(catalog[key].Workflow) {
+				return key
+			}
 		}
 	}
 	base := strings.TrimSpace(filepath.Base(strings.TrimSpace(s.configDir)))
@@ -5001,6 +6121,23 @@ func (s *Server) defaultWorkflowKey() string {
 	return strings.TrimSpace(base)
 }
 
+// validateDefaultWorkflowKey fails when DEFAULT_WORKFLOW_KEY names a workflow
+// that is not in the catalog, so a typo is caught at startup instead of
+// silently falling back.
+func (s *Server) validateDefaultWorkflowKey() error {
+	if s.defaultWorkflow == "" {
+		return nil
+	}
+	catalog, err := s.workflowCatalog()
+	if err != nil {
+		return err
+	}
+	if _, ok := catalog[s.defaultWorkflow]; !ok {
+		return fmt.Errorf("DEFAULT_WORKFLOW_KEY %q does not match any workflow (available: %s)", s.defaultWorkflow, strings.Join(sortedWorkflowKeys(catalog), ", "))
+	}
+	return nil
+}
+
 func (s *Server) processBelongsToWorkflow(process *Process, workflowKey string) bool {
 	if process == nil {
 		return false
@@ -5020,6 +6157,27 @@ func (s *Server) handleProcessRoutes(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	processID := parts[0]
+	if s.simulations.has(processID, s.nowUTC()) {
+		s.handleSimulationRoutes(w, r, processID, parts)
+		return
+	}
+	if _, err := primitive.ObjectIDFromHex(processID); err != nil {
+		// A process code: resolve it once so every handler below keeps
+		// working with the ObjectID hex.
+		process, err := s.loadProcess(r.Context(), processID)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			logAndHTTPError(w, r, http.StatusInternalServerError, "failed to load process", err, "failed to resolve process code %s", processID)
+			return
+		}
+		processID = process.ID.Hex()
+	}
+	if isProcessViewRoute(parts, r.Method) && !s.allowProcessView(w, r, processID) {
+		return
+	}
 	if len(parts) == 1 && r.Method == http.MethodGet {
 		s.handleProcessPage(w, r, processID)
 		return
@@ -5032,10 +6190,42 @@ func (s *Server) handleProcessRoutes(w http.ResponseWriter, r *http.Request) {
 		s.handleNotarizedJSON(w, r, processID)
 		return
 	}
+	if len(parts) == 2 && parts[1] == "notarized.json.sig" && r.Method == http.MethodGet {
+		s.handleNotarizedSignature(w, r, processID)
+		return
+	}
+	if len(parts) == 2 && parts[1] == "bundle.zip" && r.Method == http.MethodGet {
+		s.handleProcessBundle(w, r, processID)
+		return
+	}
+	if len(parts) == 2 && parts[1] == "events.json" && r.Method == http.MethodGet {
+		s.handleProcessEvents(w, r, processID)
+		return
+	}
 	if len(parts) == 2 && parts[1] == "merkle.json" && r.Method == http.MethodGet {
 		s.handleMerkleJSON(w, r, processID)
 		return
 	}
+	if len(parts) == 2 && parts[1] == "availability.json" && r.Method == http.MethodGet {
+		s.handleProcessAvailability(w, r, processID)
+		return
+	}
+	if len(parts) == 2 && parts[1] == "attachments.json" && r.Method == http.MethodGet {
+		s.handleProcessAttachmentsJSON(w, r, processID)
+		return
+	}
+	if len(parts) == 3 && parts[1] == "merkle" && parts[2] == "root" && r.Method == http.MethodGet {
+		s.handleMerkleRoot(w, r, processID)
+		return
+	}
+	if len(parts) == 3 && parts[1] == "dpp" && parts[2] == "preview.json" && r.Method == http.MethodGet {
+		s.handleDPPPreview(w, r, processID)
+		return
+	}
+	if len(parts) == 3 && parts[1] == "dpp" && parts[2] == "regenerate" && r.Method == http.MethodPost {
+		s.handleRegenerateProcessDPP(w, r, processID)
+		return
+	}
 	if len(parts) == 2 && parts[1] == "content" && r.Method == http.MethodGet {
 		s.handleProcessContentPartial(w, r, processID)
 		return
@@ -5048,10 +6238,42 @@ func (s *Server) handleProcessRoutes(w http.ResponseWriter, r *http.Request) {
 		s.handleTerminateProcess(w, r, processID)
 		return
 	}
+	if len(parts) == 2 && parts[1] == "share" && r.Method == http.MethodPost {
+		s.handleCreateShareLink(w, r, processID)
+		return
+	}
+	if len(parts) == 2 && parts[1] == "metadata" && r.Method == http.MethodPost {
+		s.handleUpdateProcessMetadata(w, r, processID)
+		return
+	}
 	if len(parts) == 4 && parts[1] == "substep" && parts[3] == "complete" && r.Method == http.MethodPost {
 		s.handleCompleteSubstep(w, r, processID, parts[2])
 		return
 	}
+	if len(parts) == 4 && parts[1] == "substep" && parts[3] == "draft" && r.Method == http.MethodPost {
+		s.handleSaveSubstepDraft(w, r, processID, parts[2])
+		return
+	}
+	if len(parts) == 4 && parts[1] == "substep" && parts[3] == "amend" && r.Method == http.MethodPost {
+		s.handleAmendSubstep(w, r, processID, parts[2])
+		return
+	}
+	if len(parts) == 4 && parts[1] == "substep" && parts[3] == "reject" && r.Method == http.MethodPost {
+		s.handleRejectSubstep(w, r, processID, parts[2])
+		return
+	}
+	if len(parts) == 4 && parts[1] == "substep" && parts[3] == "can-complete" && r.Method == http.MethodGet {
+		s.handleCanCompleteSubstep(w, r, processID, parts[2])
+		return
+	}
+	if len(parts) == 4 && parts[1] == "substep" && parts[3] == "authz-context" && r.Method == http.MethodGet {
+		s.handleSubstepAuthzContext(w, r, processID, parts[2])
+		return
+	}
+	if len(parts) == 4 && parts[1] == "substep" && parts[3] == "notarization.json" && r.Method == http.MethodGet {
+		s.handleSubstepNotarization(w, r, processID, parts[2])
+		return
+	}
 	if len(parts) == 4 && parts[1] == "substep" && parts[3] == "override" {
 		switch r.Method {
 		case http.MethodGet:
@@ -5067,6 +6289,10 @@ func (s *Server) handleProcessRoutes(w http.ResponseWriter, r *http.Request) {
 		s.handleDownloadProcessAttachment(w, r, processID, parts[2])
 		return
 	}
+	if len(parts) == 3 && parts[1] == "blob" && r.Method == http.MethodGet {
+		s.handleDownloadProcessBlob(w, r, processID, parts[2])
+		return
+	}
 	http.NotFound(w, r)
 }
 
@@ -5122,25 +6348,39 @@ func (s *Server) handleProcessPage(w http.ResponseWriter, r *http.Request, proce
 func (s *Server) buildProcessPageView(ctx context.Context, pageBase PageBase, cfg RuntimeConfig, workflowKey string, process *Process, actor Actor, selectedSubstepID, message string, onlyRole bool) ProcessPageView {
 	detail := s.buildStreamInstanceDetailView(ctx, cfg, workflowKey, process, actor, selectedSubstepID, message, onlyRole)
 	processID := ""
+	processCode := ""
 	instanceName := ""
+	createdBy := ""
 	status := processStatusActive
+	var metadata []ProcessMetadataEntry
+	metadataURL := ""
 	if process != nil {
 		processID = process.ID.Hex()
+		processCode = strings.TrimSpace(process.Code)
 		instanceName = strings.TrimSpace(process.Name)
+		createdBy = s.createdByDisplay(ctx, cfg.Workflow, actor, process.CreatedBy, map[string]userIdentityView{})
 		status = deriveProcessStatus(cfg.Workflow, process)
+		metadata = processMetadataEntries(process.Metadata)
+		if !process.ID.IsZero() {
+			metadataURL = streamInstancePath(workflowKey, processID) + "/metadata"
+		}
 	}
 	return ProcessPageView{
 		PageBase:     pageBase,
 		Breadcrumbs:  buildProcessBreadcrumbs(workflowKey, pageBase.WorkflowName, instanceName, processID),
 		ProcessID:    processID,
+		ProcessCode:  processCode,
 		InstanceName: instanceName,
+		CreatedBy:    createdBy,
 		Status:       status,
 		StatusLabel:  processStatusLabel(status),
+		Metadata:     metadata,
+		MetadataURL:  metadataURL,
 		Detail:       detail,
 		DPPURL:       detail.DPPURL,
 		DPPGS1:       detail.DPPGS1,
 		Attachments:  detail.Attachments,
-	}
+	}.withAttachmentTotals()
 }
 
 func buildWorkflowPreviewProcess(def WorkflowDef, workflowKey string) *Process {
@@ -5239,7 +6479,8 @@ func (s *Server) handleDigitalLinkDPP(w http.ResponseWriter, r *http.Request) {
 		s.handleDigitalLinkDPPAttachment(w, r, gtin, lot, serial, attachmentID)
 		return
 	}
-	gtin, lot, serial, err := parseDigitalLinkPath(r.URL.Path)
+	path, signatureOnly := strings.CutSuffix(strings.TrimRight(r.URL.Path, "/"), "/notarized.json.sig")
+	gtin, lot, serial, err := parseDigitalLinkPath(path)
 	if err != nil {
 		http.NotFound(w, r)
 		return
@@ -5262,10 +6503,21 @@ func (s *Server) handleDigitalLinkDPP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	export := buildNotarizedExport(cfg.Workflow, process)
+	if signatureOnly {
+		s.writeNotarizedSignature(w, r, export)
+		return
+	}
 	link := digitalLinkURL(gtin, lot, serial)
+	product := processDPPProductInfo(cfg.Workflow, cfg.DPP, process)
 	if prefersJSONResponse(r) {
+		export, err := s.signNotarizedExport(export)
+		if err != nil {
+			logAndHTTPError(w, r, http.StatusInternalServerError, "failed to sign export", err, "failed to sign notarized export for process %s", process.ID.Hex())
+			return
+		}
 		response := map[string]interface{}{
 			"digital_link": link,
+			"product":      product,
 			"workflow": map[string]string{
 				"key":         workflowKey,
 				"name":        cfg.Workflow.Name,
@@ -5286,18 +6538,20 @@ func (s *Server) handleDigitalLinkDPP(w http.ResponseWriter, r *http.Request) {
 	traceability = publicDPPTraceabilityAttachmentURLs(traceability, link)
 	traceability = s.applyDoneByIdentityFallbackToDPPTraceability(r.Context(), traceability)
 	view := DPPPageView{
-		PageBase: s.pageBase("dpp_body", workflowKey, cfg.Workflow.Name),
-		ProcessID:    process.ID.Hex(),
-		DigitalLink:  link,
-		GTIN:         gtin,
-		Lot:          lot,
-		Serial:       serial,
-		IssuedAt:     issuedAt,
-		Workflow:     cfg.Workflow,
-		Traceability: traceability,
-		Integrity:    buildDPPIntegrityView(export.Merkle),
-		Export:       export,
-		Termination:  s.buildStreamTerminationDetailsView(r.Context(), cfg.Workflow, Actor{}, process.Termination),
+		PageBase:                s.pageBase("dpp_body", workflowKey, cfg.Workflow.Name),
+		ProcessID:               process.ID.Hex(),
+		DigitalLink:             link,
+		GTIN:                    gtin,
+		Lot:                     lot,
+		Serial:                  serial,
+		IssuedAt:                issuedAt,
+		Product:                 product,
+		Workflow:                cfg.Workflow,
+		WorkflowDescriptionHTML: renderMarkdown(cfg.Workflow.Description),
+		Traceability:            traceability,
+		Integrity:               buildDPPIntegrityView(export.Merkle),
+		Export:                  export,
+		Termination:             s.buildStreamTerminationDetailsView(r.Context(), cfg.Workflow, Actor{}, process.Termination),
 	}
 	if err := s.tmpl.ExecuteTemplate(w, "dpp.html", view); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -5435,7 +6689,8 @@ func (s *Server) handleProcessDownloadsPartial(w http.ResponseWriter, r *http.Re
 		PageBase:  s.pageBase("process_body", workflowKey, cfg.Workflow.Name),
 		ProcessID: process.ID.Hex(),
 	}
-	view.Attachments = buildProcessDownloadAttachments(workflowKey, process, collectProcessAttachments(cfg.Workflow, process))
+	view.Attachments = buildProcessDownloadAttachments(workflowKey, process, s.withAttachmentSizes(r.Context(), collectProcessAttachments(cfg.Workflow, process)))
+	view = view.withAttachmentTotals()
 	if process.DPP != nil {
 		view.DPPURL = digitalLinkURL(process.DPP.GTIN, process.DPP.Lot, process.DPP.Serial)
 		view.DPPGS1 = gs1ElementString(process.DPP.GTIN, process.DPP.Lot, process.DPP.Serial)
@@ -5483,8 +6738,8 @@ func (s *Server) handleDownloadAllFiles(w http.ResponseWriter, r *http.Request,
 		}
 	}
 
-	nameCounts := map[string]int{}
-	for _, file := range files {
+	entryNames := attachmentZipEntryNames(files)
+	for idx, file := range files {
 		attachmentID, err := primitive.ObjectIDFromHex(file.AttachmentID)
 		if err != nil {
 			continue
@@ -5495,19 +6750,29 @@ func (s *Server) handleDownloadAllFiles(w http.ResponseWriter, r *http.Request,
 		}
 		defer download.Close()
 
+		entry, err := zipWriter.Create(entryNames[idx])
+		if err != nil {
+			continue
+		}
+		_, _ = io.Copy(entry, download)
+	}
+}
+
+// attachmentZipEntryNames names each file "<substep>-<filename>", numbering
+// repeats in order, so the same process always yields the same names.
+func attachmentZipEntryNames(files []ProcessAttachmentExport) []string {
+	names := make([]string, len(files))
+	nameCounts := map[string]int{}
+	for idx, file := range files {
 		safeName := sanitizeAttachmentFilename(file.Filename)
 		baseName := fmt.Sprintf("%s-%s", strings.ReplaceAll(file.SubstepID, ".", "_"), safeName)
 		nameCounts[baseName]++
-		entryName := baseName
+		names[idx] = baseName
 		if nameCounts[baseName] > 1 {
-			entryName = fmt.Sprintf("%s-%d", baseName, nameCounts[baseName])
+			names[idx] = fmt.Sprintf("%s-%d", baseName, nameCounts[baseName])
 		}
-		entry, err := zipWriter.Create(entryName)
-		if err != nil {
-			continue
-		}
-		_, _ = io.Copy(entry, download)
 	}
+	return names
 }
 
 func (s *Server) handleNotarizedJSON(w http.ResponseWriter, r *http.Request, processID string) {
@@ -5524,8 +6789,7 @@ func (s *Server) handleNotarizedJSON(w http.ResponseWriter, r *http.Request, pro
 		http.NotFound(w, r)
 		return
 	}
-	export := buildNotarizedExport(cfg.Workflow, process)
-	writeJSON(w, export)
+	s.writeNotarizedExport(w, r, buildNotarizedExport(cfg.Workflow, process))
 }
 
 func (s *Server) handleMerkleJSON(w http.ResponseWriter, r *http.Request, processID string) {
@@ -5543,9 +6807,170 @@ func (s *Server) handleMerkleJSON(w http.ResponseWriter, r *http.Request, proces
 		return
 	}
 	export := buildNotarizedExport(cfg.Workflow, process)
+	if writeNotModified(w, r, quoteETag(export.Merkle.Root)) {
+		return
+	}
 	writeJSON(w, export.Merkle)
 }
 
+// handleMerkleRoot previews the root merkle.json would report right now,
+// without the leaves and levels. Locked and available substeps are hashed
+// too, so the root is defined at every point of the process.
+func (s *Server) handleMerkleRoot(w http.ResponseWriter, r *http.Request, processID string) {
+	workflowKey, cfg, ok := s.selectedWorkflowOrRedirectHome(w, r)
+	if !ok {
+		return
+	}
+	process, err := s.loadProcess(r.Context(), processID)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if !s.processBelongsToWorkflow(process, workflowKey) {
+		http.NotFound(w, r)
+		return
+	}
+	export := buildNotarizedExport(cfg.Workflow, process)
+	if writeNotModified(w, r, quoteETag(export.Merkle.Root)) {
+		return
+	}
+	preview := MerkleRootPreview{Root: export.Merkle.Root, SubstepCount: len(export.Merkle.Leaves)}
+	for _, step := range export.Steps {
+		for _, sub := range step.Substeps {
+			if sub.Status == "done" {
+				preview.DoneCount++
+			}
+		}
+	}
+	writeJSON(w, preview)
+}
+
+// handleProcessAvailability serves availability.json: every substep of the
+// workflow mapped to "done", "available" or "locked" as computeAvailability
+// sees it, so clients can enable actions without re-rendering the page.
+func (s *Server) handleProcessAvailability(w http.ResponseWriter, r *http.Request, processID string) {
+	workflowKey, cfg, ok := s.selectedWorkflowOrRedirectHome(w, r)
+	if !ok {
+		return
+	}
+	process, err := s.loadProcess(r.Context(), processID)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if !s.processBelongsToWorkflow(process, workflowKey) {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, substepAvailabilityStates(cfg.Workflow, process))
+}
+
+// notarizedExportETag derives a strong ETag from the parts of the export that
+// can change once substeps are notarized: the Merkle root and the lifecycle
+// status (including termination details).
+func notarizedExportETag(export NotarizedProcessExport) string {
+	parts := []string{export.Merkle.Root, export.Status}
+	if export.Termination != nil {
+		parts = append(parts, export.Termination.Reason, export.Termination.EndedAt, export.Termination.EndedBy, export.Termination.EndedRole, export.Termination.SubstepID)
+	}
+	if export.Signature != nil {
+		parts = append(parts, export.Signature.KeyID, export.Signature.Value)
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return quoteETag(hex.EncodeToString(sum[:]))
+}
+
+func quoteETag(value string) string {
+	return `"` + value + `"`
+}
+
+// writeNotModified sets the ETag header and answers 304 when the request's
+// If-None-Match already matches it. It reports whether the response was
+// written.
+func writeNotModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("ETag", etag)
+	for _, candidate := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+// handleRegenerateProcessDPP rebuilds the DPP of a completed process from the
+// current workflow config. Completion only generates a DPP once, so this is
+// the platform admin escape hatch after correcting e.g. the GTIN.
+func (s *Server) handleRegenerateProcessDPP(w http.ResponseWriter, r *http.Request, processID string) {
+	user, _, ok := s.requireAuthenticatedPost(w, r)
+	if !ok {
+		return
+	}
+	allowed, err := s.canAccessPlatformAdminConsole(r.Context(), user)
+	if err != nil {
+		logAndHTTPError(w, r, http.StatusBadGateway, "cerbos check failed", err, "cerbos check failed for dpp regenerate")
+		return
+	}
+	if !allowed {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	workflowKey, cfg, err := s.selectedWorkflowUnvalidated(r)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	process, err := s.loadProcess(r.Context(), processID)
+	if err != nil || !s.processBelongsToWorkflow(process, workflowKey) {
+		http.NotFound(w, r)
+		return
+	}
+	if !isProcessComplete(cfg.Workflow, process) {
+		http.Error(w, "process is not completed", http.StatusConflict)
+		return
+	}
+	dpp, err := buildProcessDPP(cfg.Workflow, cfg.DPP, process, s.nowUTC())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	existing, err := s.store.LoadProcessByDigitalLink(r.Context(), dpp.GTIN, dpp.Lot, dpp.Serial)
+	switch {
+	case err == nil && existing.ID != process.ID:
+		http.Error(w, "digital link already assigned to another process", http.StatusConflict)
+		return
+	case err != nil && !errors.Is(err, mongo.ErrNoDocuments):
+		logAndHTTPError(w, r, http.StatusInternalServerError, "failed to check digital link", err, "dpp regenerate lookup failed for process %s", process.ID.Hex())
+		return
+	}
+	if err := s.store.UpdateProcessDPP(r.Context(), process.ID, workflowKey, dpp); err != nil {
+		logAndHTTPError(w, r, http.StatusInternalServerError, "failed to update dpp", err, "dpp regenerate update failed for process %s", process.ID.Hex())
+		return
+	}
+	appendProcessEvent(r.Context(), s.store, ProcessEvent{
+		ProcessID:   process.ID,
+		WorkflowKey: workflowKey,
+		Type:        processEventDPPRegenerated,
+		Actor:       &Actor{ID: accountActorID(user)},
+		At:          dpp.GeneratedAt,
+		Detail:      digitalLinkURL(dpp.GTIN, dpp.Lot, dpp.Serial),
+	})
+	writeJSON(w, ProcessDPPResponse{
+		ProcessID:   process.ID.Hex(),
+		GTIN:        dpp.GTIN,
+		Lot:         dpp.Lot,
+		Serial:      dpp.Serial,
+		DigitalLink: digitalLinkURL(dpp.GTIN, dpp.Lot, dpp.Serial),
+		GeneratedAt: rfc3339UTC(dpp.GeneratedAt),
+		DPPProductInfo: DPPProductInfo{
+			ProductName:        dpp.ProductName,
+			ProductDescription: dpp.ProductDescription,
+			OwnerName:          dpp.OwnerName,
+		},
+	})
+}
+
 func (s *Server) handleDownloadProcessAttachment(w http.ResponseWriter, r *http.Request, processID, attachmentID string) {
 	workflowKey, _, ok := s.selectedWorkflowOrRedirectHome(w, r)
 	if !ok {
@@ -5563,6 +6988,34 @@ func (s *Server) handleDownloadProcessAttachment(w http.ResponseWriter, r *http.
 	s.streamProcessAttachment(w, r, process, attachmentID)
 }
 
+// handleDownloadProcessBlob streams the process attachment whose content hash
+// matches sha256, as listed in notarized.json, whichever substep holds it.
+func (s *Server) handleDownloadProcessBlob(w http.ResponseWriter, r *http.Request, processID, sha256 string) {
+	workflowKey, _, ok := s.selectedWorkflowOrRedirectHome(w, r)
+	if !ok {
+		return
+	}
+	sha256 = strings.ToLower(strings.TrimSpace(sha256))
+	if decoded, err := hex.DecodeString(sha256); err != nil || len(decoded) != 32 {
+		http.NotFound(w, r)
+		return
+	}
+	process, err := s.loadProcess(r.Context(), processID)
+	if err != nil || !s.processBelongsToWorkflow(process, workflowKey) {
+		http.NotFound(w, r)
+		return
+	}
+	attachment, err := s.store.LoadAttachmentBySHA256(r.Context(), process.ID, sha256)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			logRequestError(r, err, "failed to look up attachment %s for process %s", sha256, processID)
+		}
+		http.NotFound(w, r)
+		return
+	}
+	s.streamProcessAttachment(w, r, process, attachment.ID.Hex())
+}
+
 func (s *Server) streamProcessAttachment(w http.ResponseWriter, r *http.Request, process *Process, attachmentID string) {
 	if process == nil {
 		http.NotFound(w, r)
@@ -5661,7 +7114,8 @@ func (s *Server) authorizeSubstepOverrideRequest(r *http.Request, user *AccountU
 	allowed, err := s.authorizer.CanComplete(r.Context(), actor, processID, workflowKey, canonical, step.Order, step.OrganizationSlug, sequenceOK)
 	if err != nil {
 		logRequestError(r, err, "cerbos check failed for process %s substep %s override", processID, substepID)
-		return process, canonical, step, actor, http.StatusBadGateway, "Cerbos check failed.", false
+		status, message := authorizerErrorResponse(err)
+		return process, canonical, step, actor, status, message, false
 	}
 	if !allowed {
 		return process, canonical, step, actor, http.StatusForbidden, "Not authorized for this action.", false
@@ -5763,6 +7217,15 @@ func (s *Server) handleSaveSubstepOverride(w http.ResponseWriter, r *http.Reques
 		http.Error(w, "Failed to save local adaptation.", http.StatusInternalServerError)
 		return
 	}
+	appendProcessEvent(r.Context(), s.store, ProcessEvent{
+		ProcessID:   process.ID,
+		WorkflowKey: workflowKey,
+		Type:        processEventSubstepAdapted,
+		Actor:       &actor,
+		SubstepID:   canonical.SubstepID,
+		At:          now,
+		Detail:      reason,
+	})
 	if s.sse != nil {
 		s.sse.Broadcast("process:"+workflowKey+":"+process.ID.Hex(), "process-updated")
 	}
@@ -5793,15 +7256,7 @@ func (s *Server) handleCompleteSubstep(w http.ResponseWriter, r *http.Request, p
 	if !selected {
 		return
 	}
-	actor := Actor{
-		ID:          accountActorID(user),
-		OrgSlug:     user.OrgSlug,
-		RoleSlugs:   append([]string(nil), user.RoleSlugs...),
-		WorkflowKey: workflowKey,
-	}
-	if len(user.RoleSlugs) > 0 {
-		actor.Role = user.RoleSlugs[0]
-	}
+	actor := completionActor(user, workflowKey)
 	if actor.WorkflowKey != "" && actor.WorkflowKey != workflowKey {
 		s.renderActionErrorForRequest(w, r, http.StatusForbidden, "Not authorized for this action.", nil, actor)
 		return
@@ -5820,6 +7275,10 @@ func (s *Server) handleCompleteSubstep(w http.ResponseWriter, r *http.Request, p
 		s.renderActionErrorForRequest(w, r, http.StatusNotFound, "Process not found.", process, actor)
 		return
 	}
+	if !processAcceptsCompletions(process) {
+		s.renderActionErrorForRequest(w, r, http.StatusConflict, "Stream is already ended.", process, actor)
+		return
+	}
 
 	substep, step, err := findSubstep(cfg.Workflow, substepID)
 	if err != nil {
@@ -5838,34 +7297,12 @@ func (s *Server) handleCompleteSubstep(w http.ResponseWriter, r *http.Request, p
 		s.renderActionErrorForRequest(w, r, http.StatusBadRequest, "Invalid form.", process, actor)
 		return
 	}
-	activeRole := strings.TrimSpace(r.FormValue("activeRole"))
-	if activeRole == "" && len(actor.RoleSlugs) == 1 {
-		activeRole = actor.RoleSlugs[0]
-	}
-	allowedRoles := substepRoles(substep)
-	if !s.enforceAuth && activeRole == "" && len(allowedRoles) > 0 {
-		activeRole = allowedRoles[0]
-		actor.RoleSlugs = append([]string(nil), allowedRoles...)
-	}
-	if activeRole == "" || !containsRole(actor.RoleSlugs, activeRole) || !containsRole(allowedRoles, activeRole) {
-		s.renderActionErrorForRequest(w, r, http.StatusForbidden, "Not authorized for this action.", process, actor)
-		return
-	}
-	actor.Role = activeRole
-
-	sequenceOK := isSequenceOK(cfg.Workflow, process, substepID)
-	if s.authorizer == nil {
-		s.renderActionErrorForRequest(w, r, http.StatusBadGateway, "Cerbos check failed.", process, actor)
-		return
-	}
-	allowed, err := s.authorizer.CanComplete(r.Context(), actor, processID, workflowKey, substep, step.Order, step.OrganizationSlug, sequenceOK)
-	if err != nil {
-		logRequestError(r, err, "cerbos check failed for process %s substep %s", processID, substepID)
-		s.renderActionErrorForRequest(w, r, http.StatusBadGateway, "Cerbos check failed.", process, actor)
-		return
+	check := s.checkCompletion(ctx, &actor, r.FormValue("activeRole"), workflowKey, cfg, process, substep, step)
+	if check.cerbosErr != nil {
+		logRequestError(r, check.cerbosErr, "cerbos check failed for process %s substep %s", processID, substepID)
 	}
-	if !sequenceOK {
-		if progress, ok := process.Progress[substepID]; ok && progress.State == "done" && containsRole(allowedRoles, actor.Role) {
+	if !check.Allowed {
+		if !check.SequenceOK && check.AlreadyDone && check.Status == http.StatusConflict {
 			nextReq := cloneRequestWithSelectedSubstep(r, "")
 			if isProcessContentTargetRequest(r) {
 				s.renderProcessContent(w, nextReq, process, actor, "")
@@ -5878,11 +7315,7 @@ func (s *Server) handleCompleteSubstep(w http.ResponseWriter, r *http.Request, p
 			s.renderDepartmentProcessPage(w, nextReq, process, actor, "")
 			return
 		}
-		s.renderActionErrorForRequest(w, r, http.StatusConflict, "Step is locked: complete previous steps first.", process, actor)
-		return
-	}
-	if !allowed {
-		s.renderActionErrorForRequest(w, r, http.StatusForbidden, "Not authorized for this action.", process, actor)
+		s.renderActionErrorForRequest(w, r, check.Status, check.Reason, process, actor)
 		return
 	}
 
@@ -5892,18 +7325,34 @@ func (s *Server) handleCompleteSubstep(w http.ResponseWriter, r *http.Request, p
 		effective = substep
 	}
 	now := s.nowUTC()
+	uploads := &savedAttachments{}
+	r = r.WithContext(withSavedAttachments(r.Context(), uploads))
 	payload, err := s.parseCompletionPayload(r, process.ID, effective, now)
 	if err != nil {
+		s.discardSavedAttachments(r, uploads)
 		switch {
 		case errors.Is(err, ErrAttachmentTooLarge):
 			s.renderActionErrorForRequest(w, r, http.StatusRequestEntityTooLarge, "File too large.", process, actor)
+		case errors.Is(err, errAttachmentRejected):
+			logRequestError(r, err, "rejected upload for process %s substep %s", processID, substepID)
+			s.renderActionErrorForRequest(w, r, http.StatusUnprocessableEntity, "File rejected by virus scan.", process, actor)
+		case errors.Is(err, errAttachmentScanFailed):
+			logRequestError(r, err, "failed to scan upload for process %s substep %s", processID, substepID)
+			s.renderActionErrorForRequest(w, r, http.StatusBadGateway, "File scan failed.", process, actor)
 		case errors.Is(err, errInvalidForm):
 			s.renderActionErrorForRequest(w, r, http.StatusBadRequest, "Invalid form.", process, actor)
+		case errors.Is(err, errPayloadTooComplex):
+			s.renderActionErrorForRequest(w, r, http.StatusUnprocessableEntity, payloadTooComplexMessage, process, actor)
 		default:
 			s.renderActionErrorForRequest(w, r, http.StatusBadRequest, err.Error(), process, actor)
 		}
 		return
 	}
+	if substep.RequireConfirmation && !completionConfirmed(r) {
+		s.discardSavedAttachments(r, uploads)
+		s.renderActionErrorForRequest(w, r, http.StatusBadRequest, completionConfirmationMessage, process, actor)
+		return
+	}
 
 	process, err = s.processService().CompleteSubstep(ctx, CompleteSubstepCmd{
 		Process:     process,
@@ -5916,6 +7365,9 @@ func (s *Server) handleCompleteSubstep(w http.ResponseWriter, r *http.Request, p
 		Now:         now,
 	})
 	if err != nil {
+		if payloadNotPersisted(err) {
+			s.discardSavedAttachments(r, uploads)
+		}
 		switch {
 		case errors.Is(err, ErrProgressUpdate):
 			logRequestError(r, err, "failed to update process %s substep %s", process.ID.Hex(), substepID)
@@ -5923,6 +7375,10 @@ func (s *Server) handleCompleteSubstep(w http.ResponseWriter, r *http.Request, p
 		case errors.Is(err, ErrNotarization):
 			logRequestError(r, err, "failed to notarize process %s substep %s", process.ID.Hex(), substepID)
 			s.renderActionErrorForRequest(w, r, http.StatusInternalServerError, "Failed to notarize payload.", process, actor)
+		case errors.Is(err, ErrIllegalStatusTransition):
+			s.renderActionErrorForRequest(w, r, http.StatusConflict, "Stream is already ended.", process, actor)
+		case errors.Is(err, ErrDPPInputMissing):
+			s.renderActionErrorForRequest(w, r, http.StatusUnprocessableEntity, dppInputMissingMessage(err), process, actor)
 		default:
 			logRequestError(r, err, "failed to complete process %s substep %s", process.ID.Hex(), substepID)
 			s.renderActionErrorForRequest(w, r, http.StatusInternalServerError, "Failed to update process.", process, actor)
@@ -5931,9 +7387,7 @@ func (s *Server) handleCompleteSubstep(w http.ResponseWriter, r *http.Request, p
 	}
 
 	s.sse.Broadcast("process:"+workflowKey+":"+processID, "process-updated")
-	for _, role := range s.roles(cfg) {
-		s.sse.Broadcast("role:"+workflowKey+":"+role, "role-updated")
-	}
+	s.broadcastRolesUpdated(workflowKey, cfg)
 	nextReq := cloneRequestWithSelectedSubstep(r, "")
 	if isProcessContentTargetRequest(r) {
 		s.renderProcessContent(w, nextReq, process, actor, "")
@@ -6016,7 +7470,8 @@ func (s *Server) handleTerminateProcess(w http.ResponseWriter, r *http.Request,
 	allowed, err := s.authorizer.CanComplete(r.Context(), actor, processID, workflowKey, substep, step.Order, step.OrganizationSlug, true)
 	if err != nil {
 		logRequestError(r, err, "cerbos check failed for process %s termination at substep %s", processID, substep.SubstepID)
-		s.renderActionErrorForRequest(w, r, http.StatusBadGateway, "Cerbos check failed.", process, actor)
+		status, message := authorizerErrorResponse(err)
+		s.renderActionErrorForRequest(w, r, status, message, process, actor)
 		return
 	}
 	if !allowed {
@@ -6031,11 +7486,23 @@ func (s *Server) handleTerminateProcess(w http.ResponseWriter, r *http.Request,
 		Actor:     &actor,
 		SubstepID: substep.SubstepID,
 	}
-	if err := s.store.UpdateProcessTermination(r.Context(), process.ID, workflowKey, termination); err != nil {
+	if err := s.store.UpdateProcessTermination(r.Context(), process.ID, workflowKey, termination); errors.Is(err, ErrIllegalStatusTransition) {
+		s.renderActionErrorForRequest(w, r, http.StatusConflict, "Stream is already ended.", process, actor)
+		return
+	} else if err != nil {
 		logRequestError(r, err, "failed to terminate process %s", process.ID.Hex())
 		s.renderActionErrorForRequest(w, r, http.StatusInternalServerError, "Failed to end stream.", process, actor)
 		return
 	}
+	appendProcessEvent(r.Context(), s.store, ProcessEvent{
+		ProcessID:   process.ID,
+		WorkflowKey: workflowKey,
+		Type:        processEventTerminated,
+		Actor:       &actor,
+		SubstepID:   substep.SubstepID,
+		At:          now,
+		Detail:      reason,
+	})
 	process, _ = s.loadProcess(r.Context(), processID)
 	if process != nil && cfg.DPP.Enabled && process.DPP == nil {
 		dpp, dppErr := buildProcessDPP(cfg.Workflow, cfg.DPP, process, now)
@@ -6048,9 +7515,7 @@ func (s *Server) handleTerminateProcess(w http.ResponseWriter, r *http.Request,
 	}
 
 	s.sse.Broadcast("process:"+workflowKey+":"+processID, "process-updated")
-	for _, role := range s.roles(cfg) {
-		s.sse.Broadcast("role:"+workflowKey+":"+role, "role-updated")
-	}
+	s.broadcastRolesUpdated(workflowKey, cfg)
 	nextReq := cloneRequestWithSelectedSubstep(r, "")
 	if isProcessContentTargetRequest(r) {
 		s.renderProcessContent(w, nextReq, process, actor, "")
@@ -6068,9 +7533,38 @@ var (
 )
 
 func (s *Server) parseCompletionPayload(r *http.Request, processID primitive.ObjectID, substep WorkflowSub, now time.Time) (map[string]interface{}, error) {
+	if isAcknowledgeSubstep(substep) {
+		return acknowledgePayload(substep), nil
+	}
+	if isSignatureSubstep(substep) {
+		return s.parseSignaturePayload(r, processID, substep, now)
+	}
+	if isMultiselectSubstep(substep) {
+		return parseMultiselectPayload(r, substep)
+	}
+	if isURLSubstep(substep) {
+		return parseURLPayload(r, substep)
+	}
 	return s.parseFormataPayload(r, processID, substep, now)
 }
 
+// isAcknowledgeSubstep reports whether sub is an "I have read and agree"
+// step that completes without any form data.
+func isAcknowledgeSubstep(sub WorkflowSub) bool {
+	return normalizeInputTypeForCheck(sub.InputType) == "acknowledge"
+}
+
+// acknowledgePayload is the fixed payload notarized for acknowledge substeps,
+// so every acknowledgement of the same substep has the same digest. Posted
+// form values are ignored.
+func acknowledgePayload(sub WorkflowSub) map[string]interface{} {
+	key := strings.TrimSpace(sub.InputKey)
+	if key == "" {
+		key = "acknowledged"
+	}
+	return map[string]interface{}{key: true}
+}
+
 type decodedDataURL struct {
 	ContentType string
 	Data        []byte
@@ -6143,12 +7637,19 @@ func formMapWithoutValue(values url.Values) map[string]interface{} {
 }
 
 func (s *Server) persistFormataAttachments(ctx context.Context, processID primitive.ObjectID, substep WorkflowSub, raw interface{}, now time.Time, path []string) (interface{}, error) {
+	return s.persistFormataValue(ctx, processID, substep, raw, now, path, 0, s.newFormataPayloadBudget())
+}
+
+func (s *Server) persistFormataValue(ctx context.Context, processID primitive.ObjectID, substep WorkflowSub, raw interface{}, now time.Time, path []string, depth int, budget *formataPayloadBudget) (interface{}, error) {
+	if err := budget.visit(depth); err != nil {
+		return nil, err
+	}
 	switch typed := raw.(type) {
 	case map[string]interface{}:
 		normalized := make(map[string]interface{}, len(typed))
 		for key, value := range typed {
 			nextPath := append(append([]string(nil), path...), key)
-			converted, err := s.persistFormataAttachments(ctx, processID, substep, value, now, nextPath)
+			converted, err := s.persistFormataValue(ctx, processID, substep, value, now, nextPath, depth+1, budget)
 			if err != nil {
 				return nil, err
 			}
@@ -6156,12 +7657,12 @@ func (s *Server) persistFormataAttachments(ctx context.Context, processID primit
 		}
 		return normalized, nil
 	case primitive.M:
-		return s.persistFormataAttachments(ctx, processID, substep, map[string]interface{}(typed), now, path)
+		return s.persistFormataValue(ctx, processID, substep, map[string]interface{}(typed), now, path, depth, budget)
 	case []interface{}:
 		normalized := make([]interface{}, len(typed))
 		for index, value := range typed {
 			nextPath := append(append([]string(nil), path...), strconv.Itoa(index))
-			converted, err := s.persistFormataAttachments(ctx, processID, substep, value, now, nextPath)
+			converted, err := s.persistFormataValue(ctx, processID, substep, value, now, nextPath, depth+1, budget)
 			if err != nil {
 				return nil, err
 			}
@@ -6174,6 +7675,9 @@ func (s *Server) persistFormataAttachments(ctx context.Context, processID primit
 			return typed, nil
 		}
 		filename := formataAttachmentFilename(substep.SubstepID, path, dataURL.ContentType)
+		if err := s.scanAttachment(ctx, filename, dataURL.Data); err != nil {
+			return nil, err
+		}
 		attachment, err := s.store.SaveAttachment(ctx, AttachmentUpload{
 			ProcessID:   processID,
 			SubstepID:   substep.SubstepID,
@@ -6185,6 +7689,7 @@ func (s *Server) persistFormataAttachments(ctx context.Context, processID primit
 		if err != nil {
 			return nil, err
 		}
+		recordSavedAttachment(ctx, attachment.ID)
 		return map[string]interface{}{
 			"attachmentId": attachment.ID.Hex(),
 			"filename":     attachment.Filename,
@@ -6349,7 +7854,7 @@ func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
 	}
 	workflowKey, cfg, err := s.selectedWorkflow(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeWorkflowSelectionError(w, err)
 		return
 	}
 	queryWorkflow := strings.TrimSpace(r.URL.Query().Get("workflow"))
@@ -6383,11 +7888,23 @@ func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
 	ch := s.sse.Subscribe(streamKey)
 	defer s.sse.Unsubscribe(streamKey, ch)
 
+	// A comment line every heartbeat keeps proxies and load balancers from
+	// closing a stream that has no broadcasts for a while.
+	heartbeat := s.sseHeartbeat
+	if heartbeat <= 0 {
+		heartbeat = sseHeartbeatDefault
+	}
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
 	ctx := r.Context()
 	for {
 		select {
 		case <-ctx.Done():
 			return
+		case <-ticker.C:
+			fmt.Fprint(w, ": keepalive\n\n")
+			flusher.Flush()
 		case msg := <-ch:
 			eventName := "process-updated"
 			if role != "" {
@@ -6400,14 +7917,15 @@ func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// loadProcess loads a live process by ObjectID hex or by its code within the
+// workflow selected on ctx (see processRef).
 func (s *Server) loadProcess(ctx context.Context, id string) (*Process, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
+	process, err := s.loadProcessByRef(ctx, s.contextWorkflowKey(ctx), id)
 	if err != nil {
 		return nil, err
 	}
-	process, err := s.store.LoadProcessByID(ctx, objectID)
-	if err != nil {
-		return nil, err
+	if process.DeletedAt != nil {
+		return nil, mongo.ErrNoDocuments
 	}
 	process.Progress = normalizeProgressKeys(process.Progress)
 	process.Overrides = normalizeSubstepOverrideKeys(process.Overrides)
@@ -6461,17 +7979,53 @@ func parseRuntimeConfigData(source string, data []byte) (RuntimeConfig, error) {
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return RuntimeConfig{}, fmt.Errorf("parse config %s: %w", source, err)
 	}
-	normalizeWorkflowConfig(&cfg)
+	if err := validateWorkflowConfig(&cfg); err != nil {
+		return RuntimeConfig{}, fmt.Errorf("%s: %w", source, err)
+	}
+	return cfg, nil
+}
+
+// validateWorkflowConfig normalizes a freshly parsed workflow config in place
+// and runs the structural checks shared by catalog load and dry-run validation.
+func validateWorkflowConfig(cfg *RuntimeConfig) error {
+	normalizeWorkflowConfig(cfg)
 	if cfg.Workflow.Name == "" || len(cfg.Workflow.Steps) == 0 {
-		return RuntimeConfig{}, fmt.Errorf("workflow config is empty in %s", source)
+		return errors.New("workflow config is empty")
 	}
 	if err := normalizeInputTypes(&cfg.Workflow); err != nil {
-		return RuntimeConfig{}, fmt.Errorf("%s: %w", source, err)
+		return err
+	}
+	if err := validateSubstepDependencies(&cfg.Workflow); err != nil {
+		return err
+	}
+	if err := normalizeProcessCodePrefix(&cfg.Workflow); err != nil {
+		return err
+	}
+	if err := normalizeWorkflowViewAccess(&cfg.Workflow); err != nil {
+		return err
+	}
+	if err := validateSubstepVisibility(&cfg.Workflow); err != nil {
+		return err
+	}
+	if err := normalizeWorkflowDoneWhen(&cfg.Workflow); err != nil {
+		return err
+	}
+	if err := validateWorkflowStartFields(&cfg.Workflow); err != nil {
+		return err
+	}
+	if cfg.Workflow.RetentionDays < 0 {
+		return errors.New("retentionDays must not be negative")
+	}
+	if err := normalizeSubstepLabels(cfg); err != nil {
+		return err
 	}
 	if err := normalizeDPPConfig(&cfg.DPP); err != nil {
-		return RuntimeConfig{}, fmt.Errorf("%s: %w", source, err)
+		return err
 	}
-	return cfg, nil
+	if err := validateDPPProductInputKeys(cfg.Workflow, cfg.DPP); err != nil {
+		return err
+	}
+	return validateDPPRequiredInputKeys(cfg.Workflow, cfg.DPP)
 }
 
 func workflowCatalogModTime(stream FormataBuilderStream) time.Time {
@@ -6643,6 +8197,16 @@ func normalizeWorkflowConfig(cfg *RuntimeConfig) {
 			}
 		}
 	}
+	var startRoles []string
+	for _, role := range cfg.Workflow.StartRoles {
+		if role = strings.TrimSpace(role); role != "" {
+			startRoles = append(startRoles, role)
+		}
+	}
+	if len(startRoles) > 0 {
+		startRoles = dedupeStrings(startRoles)
+	}
+	cfg.Workflow.StartRoles = startRoles
 }
 
 func (s *Server) isKnownRole(cfg RuntimeConfig, role string) bool {
@@ -6703,6 +8267,38 @@ func substepRoles(sub WorkflowSub) []string {
 	return nil
 }
 
+// substepAssignees returns the trimmed, non-empty AssignedUserIDs of sub.
+func substepAssignees(sub WorkflowSub) []string {
+	out := make([]string, 0, len(sub.AssignedUserIDs))
+	for _, id := range sub.AssignedUserIDs {
+		if trimmed := strings.TrimSpace(id); trimmed != "" {
+			out = append(out, trimmed)
+		}
+	}
+	return out
+}
+
+// assignedToReason is the disabled reason of a substep assigned to others.
+func assignedToReason(assignees []string) string {
+	return "Assigned to " + strings.Join(assignees, ", ")
+}
+
+// substepAssignedTo reports whether actorID may complete sub. Substeps
+// without assignees are open to everyone holding a matching role.
+func substepAssignedTo(sub WorkflowSub, actorID string) bool {
+	assignees := substepAssignees(sub)
+	if len(assignees) == 0 {
+		return true
+	}
+	actorID = strings.TrimSpace(actorID)
+	for _, id := range assignees {
+		if id == actorID || appwriteActorID(id) == actorID {
+			return true
+		}
+	}
+	return false
+}
+
 func intersectRoles(allowed []string, owned []string) []string {
 	ownedSet := map[string]struct{}{}
 	for _, role := range owned {
@@ -6765,6 +8361,32 @@ func organizationLogoURLMap(ctx context.Context, identity IdentityStore) map[str
 	return out
 }
 
+// organizationBrand loads the name and logo of the organization with slug
+// for the login and invite pages. Unknown or empty slugs, and with
+// publicOnly orgs that did not opt in to PublicBranding, return nil so the
+// page falls back to the generic look.
+func (s *Server) organizationBrand(ctx context.Context, slug string, publicOnly bool) *OrgBrandView {
+	slug = strings.TrimSpace(slug)
+	if slug == "" || s.identity == nil {
+		return nil
+	}
+	org, err := s.identity.GetOrganizationBySlug(ctx, slug)
+	if err != nil || org == nil || (publicOnly && !org.PublicBranding) {
+		if err != nil && !errors.Is(err, ErrIdentityNotFound) {
+			log.Printf("failed to load organization %s branding: %v", slug, err)
+		}
+		return nil
+	}
+	brand := &OrgBrandView{Slug: strings.TrimSpace(org.Slug), Name: strings.TrimSpace(org.Name)}
+	if brand.Name == "" {
+		brand.Name = brand.Slug
+	}
+	if strings.TrimSpace(org.LogoFileID) != "" {
+		brand.LogoURL = "/organization/logo/" + url.PathEscape(brand.Slug)
+	}
+	return brand
+}
+
 func organizationDisplayName(slug string, orgNames map[string]string) string {
 	slug = strings.TrimSpace(slug)
 	if slug == "" {
@@ -6796,23 +8418,25 @@ func collectProcessAttachments(def WorkflowDef, process *Process) []ProcessAttac
 		if !ok || progress.State != "done" {
 			continue
 		}
-		for _, meta := range attachmentsFromValue(progress.Data) {
-			if meta.AttachmentID == "" {
-				continue
-			}
-			key := sub.SubstepID + ":" + meta.AttachmentID
-			if _, exists := seen[key]; exists {
-				continue
+		for _, data := range stepDataHistory(progress) {
+			for _, meta := range attachmentsFromValue(data) {
+				if meta.AttachmentID == "" {
+					continue
+				}
+				key := sub.SubstepID + ":" + meta.AttachmentID
+				if _, exists := seen[key]; exists {
+					continue
+				}
+				seen[key] = struct{}{}
+				files = append(files, ProcessAttachmentExport{
+					SubstepID:    sub.SubstepID,
+					AttachmentID: meta.AttachmentID,
+					Filename:     meta.Filename,
+					ContentType:  meta.ContentType,
+					SizeBytes:    meta.SizeBytes,
+					SHA256:       meta.SHA256,
+				})
 			}
-			seen[key] = struct{}{}
-			files = append(files, ProcessAttachmentExport{
-				SubstepID:    sub.SubstepID,
-				AttachmentID: meta.AttachmentID,
-				Filename:     meta.Filename,
-				ContentType:  meta.ContentType,
-				SizeBytes:    meta.SizeBytes,
-				SHA256:       meta.SHA256,
-			})
 		}
 	}
 	return files
@@ -6822,6 +8446,25 @@ func buildProcessDownloadAttachments(workflowKey string, process *Process, files
 	if process == nil || len(files) == 0 {
 		return nil
 	}
+	ordered := sortedProcessAttachments(files)
+	views := make([]ProcessDownloadAttachment, 0, len(ordered))
+	for _, file := range ordered {
+		if strings.TrimSpace(file.AttachmentID) == "" {
+			continue
+		}
+		views = append(views, ProcessDownloadAttachment{
+			SubstepID: file.SubstepID,
+			Filename:  sanitizeAttachmentFilename(file.Filename),
+			URL:       processAttachmentURL(workflowKey, process, file.AttachmentID),
+			SizeBytes: file.SizeBytes,
+		})
+	}
+	return views
+}
+
+// sortedProcessAttachments orders files by substep, filename and attachment
+// id, the order the downloads partial and attachments.json list them in.
+func sortedProcessAttachments(files []ProcessAttachmentExport) []ProcessAttachmentExport {
 	ordered := append([]ProcessAttachmentExport(nil), files...)
 	sort.Slice(ordered, func(i, j int) bool {
 		if ordered[i].SubstepID != ordered[j].SubstepID {
@@ -6832,18 +8475,58 @@ func buildProcessDownloadAttachments(workflowKey string, process *Process, files
 		}
 		return ordered[i].AttachmentID < ordered[j].AttachmentID
 	})
-	views := make([]ProcessDownloadAttachment, 0, len(ordered))
-	for _, file := range ordered {
-		if strings.TrimSpace(file.AttachmentID) == "" {
+	return ordered
+}
+
+func processAttachmentURL(workflowKey string, process *Process, attachmentID string) string {
+	return fmt.Sprintf("%s/attachment/%s/file", streamInstancePath(workflowKey, process.ID.Hex()), attachmentID)
+}
+
+// withAttachmentSizes fills SizeBytes for attachment metadata recorded without
+// a size (older payloads) from the stored attachment.
+func (s *Server) withAttachmentSizes(ctx context.Context, files []ProcessAttachmentExport) []ProcessAttachmentExport {
+	if s.store == nil {
+		return files
+	}
+	for i, file := range files {
+		if file.SizeBytes > 0 {
 			continue
 		}
-		views = append(views, ProcessDownloadAttachment{
-			SubstepID: file.SubstepID,
-			Filename:  sanitizeAttachmentFilename(file.Filename),
-			URL:       fmt.Sprintf("%s/attachment/%s/file", streamInstancePath(workflowKey, process.ID.Hex()), file.AttachmentID),
-		})
+		id, err := primitive.ObjectIDFromHex(strings.TrimSpace(file.AttachmentID))
+		if err != nil {
+			continue
+		}
+		attachment, err := s.store.LoadAttachmentByID(ctx, id)
+		if err != nil || attachment == nil {
+			continue
+		}
+		files[i].SizeBytes = attachment.SizeBytes
 	}
-	return views
+	return files
+}
+
+func (v ProcessPageView) withAttachmentTotals() ProcessPageView {
+	v.AttachmentCount = len(v.Attachments)
+	v.AttachmentTotalBytes = 0
+	for _, attachment := range v.Attachments {
+		v.AttachmentTotalBytes += attachment.SizeBytes
+	}
+	v.AttachmentTotalSize = formatByteSize(v.AttachmentTotalBytes)
+	v.AttachmentsLarge = v.AttachmentTotalBytes > attachmentZipWarnBytes()
+	return v
+}
+
+func formatByteSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit && exp < 4; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTP"[exp])
 }
 
 func attachmentsFromValue(raw interface{}) []NotarizedAttachment {
@@ -6983,12 +8666,14 @@ func buildNotarizedExport(def WorkflowDef, process *Process) NotarizedProcessExp
 	status := deriveProcessStatus(def, process)
 	export.ProcessID = process.ID.Hex()
 	export.CreatedAt = process.CreatedAt.Format(time.RFC3339)
+	export.CreatedBy = processCreatedBy(process)
 	export.Status = status
 	if process.Termination != nil {
 		export.Termination = notarizedProcessTermination(process.Termination)
 	}
 
 	availableMap := computeAvailability(def, process)
+	hidden := hiddenSubsteps(def, process)
 	var leaves []MerkleLeaf
 	for _, step := range sortedSteps(def) {
 		stepEntry := NotarizedStep{StepID: step.StepID, Title: step.Title}
@@ -7008,12 +8693,16 @@ func buildNotarizedExport(def WorkflowDef, process *Process) NotarizedProcessExp
 					entry.DoneBy = progress.DoneBy.ID
 					entry.DoneRole = progress.DoneBy.Role
 				}
+				entry.DurationSeconds = progress.DurationSeconds
 				entry.Description = progress.Description
 				entry.Payload = progress.Data
 				entry.Digest = digestPayload(progress.Data)
+				entry.Amendments = notarizedAmendments(progress)
 				if override, ok := process.Overrides[sub.SubstepID]; ok && strings.TrimSpace(override.SubstepID) != "" {
 					entry.LocalAdaptationReason = strings.TrimSpace(override.Reason)
 				}
+			} else if hidden[sub.SubstepID] {
+				state = "skipped"
 			} else if availableMap[sub.SubstepID] {
 				state = "available"
 			}
@@ -7066,19 +8755,21 @@ func processTerminationView(termination *ProcessTermination) *ProcessTermination
 
 func hashMerkleLeaf(substepID string, entry NotarizedSubstep) string {
 	payload := struct {
-		SubstepID string                 `json:"substep_id"`
-		Status    string                 `json:"status"`
-		DoneAt    string                 `json:"done_at,omitempty"`
-		DoneBy    string                 `json:"done_by,omitempty"`
-		DoneRole  string                 `json:"done_role,omitempty"`
-		Payload   map[string]interface{} `json:"payload,omitempty"`
+		SubstepID  string                 `json:"substep_id"`
+		Status     string                 `json:"status"`
+		DoneAt     string                 `json:"done_at,omitempty"`
+		DoneBy     string                 `json:"done_by,omitempty"`
+		DoneRole   string                 `json:"done_role,omitempty"`
+		Payload    map[string]interface{} `json:"payload,omitempty"`
+		Amendments []NotarizedAmendment   `json:"amendments,omitempty"`
 	}{
-		SubstepID: substepID,
-		Status:    entry.Status,
-		DoneAt:    entry.DoneAt,
-		DoneBy:    entry.DoneBy,
-		DoneRole:  entry.DoneRole,
-		Payload:   entry.Payload,
+		SubstepID:  substepID,
+		Status:     entry.Status,
+		DoneAt:     entry.DoneAt,
+		DoneBy:     entry.DoneBy,
+		DoneRole:   entry.DoneRole,
+		Payload:    canonicalPayload(entry.Payload),
+		Amendments: canonicalAmendments(entry.Amendments),
 	}
 	data, _ := json.Marshal(payload)
 	hash := sha256.Sum256(data)
@@ -7422,6 +9113,25 @@ func cssValue(value, fallback string) template.CSS {
 	return template.CSS(trimmed)
 }
 
+// substepAvailabilityStates folds progress and computeAvailability into one
+// state per substep: "done", "available" or "locked". Substeps hidden by
+// visibleWhen and every pending substep of a closed process are "locked".
+func substepAvailabilityStates(def WorkflowDef, process *Process) map[string]string {
+	available := computeAvailability(def, process)
+	states := make(map[string]string, len(available))
+	for _, sub := range orderedSubsteps(def) {
+		switch {
+		case isSubstepDone(process, sub.SubstepID):
+			states[sub.SubstepID] = "done"
+		case available[sub.SubstepID]:
+			states[sub.SubstepID] = "available"
+		default:
+			states[sub.SubstepID] = "locked"
+		}
+	}
+	return states
+}
+
 func computeAvailability(def WorkflowDef, process *Process) map[string]bool {
 	available := map[string]bool{}
 	if isProcessClosed(def, process) {
@@ -7430,53 +9140,87 @@ func computeAvailability(def WorkflowDef, process *Process) map[string]bool {
 		}
 		return available
 	}
-	ordered := orderedSubsteps(def)
-	allPrevDone := true
-	for _, sub := range ordered {
-		done := false
-		if process != nil {
-			if entry, ok := process.Progress[sub.SubstepID]; ok && entry.State == "done" {
-				done = true
-			}
-		}
-		if done {
+	hidden := hiddenSubsteps(def, process)
+	for _, sub := range orderedSubsteps(def) {
+		if isSubstepDone(process, sub.SubstepID) || hidden[sub.SubstepID] {
 			available[sub.SubstepID] = false
 			continue
 		}
-		if allPrevDone {
-			available[sub.SubstepID] = true
-			allPrevDone = false
-		} else {
-			available[sub.SubstepID] = false
-		}
+		available[sub.SubstepID] = isSequenceOK(def, process, sub.SubstepID)
 	}
 	return available
 }
 
+// isSequenceOK reports whether every prerequisite of substepID is done or
+// hidden by visibleWhen. A substep with DependsOn waits only for those
+// substeps; otherwise it waits for every substep before it in workflow order.
+// A hidden substep is never in sequence.
 func isSequenceOK(def WorkflowDef, process *Process, substepID string) bool {
+	hidden := hiddenSubsteps(def, process)
+	if hidden[substepID] {
+		return false
+	}
 	ordered := orderedSubsteps(def)
-	for _, sub := range ordered {
-		if sub.SubstepID == substepID {
-			return true
+	for idx, sub := range ordered {
+		if sub.SubstepID != substepID {
+			continue
 		}
-		if process == nil {
-			return false
+		if len(sub.DependsOn) > 0 {
+			for _, dep := range sub.DependsOn {
+				if !isSubstepSettled(def, process, hidden, dep) {
+					return false
+				}
+			}
+			return true
 		}
-		if entry, ok := process.Progress[sub.SubstepID]; !ok || entry.State != "done" {
-			return false
+		for _, prev := range ordered[:idx] {
+			if !isSubstepSettled(def, process, hidden, prev.SubstepID) {
+				return false
+			}
 		}
+		return true
 	}
 	return false
 }
 
-func isProcessDone(def WorkflowDef, process *Process) bool {
-	for _, sub := range orderedSubsteps(def) {
-		entry, ok := process.Progress[sub.SubstepID]
-		if !ok || entry.State != "done" {
-			return false
+// substepAvailableAt returns when substepID became available: the latest
+// DoneAt among the substeps it waits on (its dependsOn list, or every earlier
+// substep), or the process creation time when it had nothing to wait for.
+func substepAvailableAt(def WorkflowDef, process *Process, substepID string) time.Time {
+	if process == nil {
+		return time.Time{}
+	}
+	ordered := orderedSubsteps(def)
+	var prerequisites []string
+	for idx, sub := range ordered {
+		if sub.SubstepID != substepID {
+			continue
+		}
+		if len(sub.DependsOn) > 0 {
+			prerequisites = sub.DependsOn
+			break
 		}
+		for _, prev := range ordered[:idx] {
+			prerequisites = append(prerequisites, prev.SubstepID)
+		}
+		break
 	}
-	return true
+	availableAt := process.CreatedAt
+	for _, id := range prerequisites {
+		entry, ok := process.Progress[id]
+		if ok && entry.DoneAt != nil && entry.DoneAt.After(availableAt) {
+			availableAt = *entry.DoneAt
+		}
+	}
+	return availableAt
+}
+
+func isSubstepDone(process *Process, substepID string) bool {
+	if process == nil {
+		return false
+	}
+	entry, ok := process.Progress[substepID]
+	return ok && entry.State == "done"
 }
 
 func findSubstep(def WorkflowDef, substepID string) (WorkflowSub, WorkflowStep, error) {
@@ -7507,20 +9251,134 @@ func normalizeInputTypes(workflow *WorkflowDef) error {
 	return nil
 }
 
+// validateSubstepDependencies trims DependsOn entries and rejects unknown,
+// self-referencing or cyclic substep dependencies.
+func validateSubstepDependencies(workflow *WorkflowDef) error {
+	deps := map[string][]string{}
+	for stepIndex := range workflow.Steps {
+		for substepIndex := range workflow.Steps[stepIndex].Substep {
+			substep := &workflow.Steps[stepIndex].Substep[substepIndex]
+			deps[substep.SubstepID] = nil
+		}
+	}
+	for stepIndex := range workflow.Steps {
+		for substepIndex := range workflow.Steps[stepIndex].Substep {
+			substep := &workflow.Steps[stepIndex].Substep[substepIndex]
+			if len(substep.DependsOn) == 0 {
+				continue
+			}
+			normalized := make([]string, 0, len(substep.DependsOn))
+			for _, dep := range substep.DependsOn {
+				dep = strings.TrimSpace(dep)
+				if dep == "" {
+					continue
+				}
+				if dep == substep.SubstepID {
+					return fmt.Errorf("invalid dependsOn for substep %s: substep cannot depend on itself", substep.SubstepID)
+				}
+				if _, ok := deps[dep]; !ok {
+					return fmt.Errorf("invalid dependsOn for substep %s: unknown substep %q", substep.SubstepID, dep)
+				}
+				normalized = append(normalized, dep)
+			}
+			substep.DependsOn = dedupeStrings(normalized)
+			deps[substep.SubstepID] = substep.DependsOn
+		}
+	}
+
+	// Without dependsOn a substep waits for every earlier one (see
+	// isSequenceOK), so those implicit edges can close a cycle too.
+	ordered := orderedSubsteps(*workflow)
+	for idx, sub := range ordered {
+		if len(deps[sub.SubstepID]) > 0 {
+			continue
+		}
+		earlier := make([]string, 0, idx)
+		for _, prev := range ordered[:idx] {
+			earlier = append(earlier, prev.SubstepID)
+		}
+		deps[sub.SubstepID] = earlier
+	}
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := map[string]int{}
+	var visit func(id string, path []string) error
+	visit = func(id string, path []string) error {
+		switch state[id] {
+		case visiting:
+			return fmt.Errorf("invalid dependsOn: dependency cycle %s", strings.Join(append(path, id), " -> "))
+		case visited:
+			return nil
+		}
+		state[id] = visiting
+		for _, dep := range deps[id] {
+			if err := visit(dep, append(path, id)); err != nil {
+				return err
+			}
+		}
+		state[id] = visited
+		return nil
+	}
+	for _, sub := range ordered {
+		if err := visit(sub.SubstepID, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func normalizeInputType(value string) (string, error) {
 	switch strings.ToLower(strings.TrimSpace(value)) {
 	case "formata", "schema", "jsonschema":
 		return "formata", nil
+	case "acknowledge":
+		return "acknowledge", nil
+	case "signature":
+		return "signature", nil
+	case "multiselect":
+		return "multiselect", nil
+	case "url":
+		return "url", nil
 	default:
-		return "", fmt.Errorf("unsupported value %q (allowed: formata)", value)
+		return "", fmt.Errorf("unsupported value %q (allowed: formata, acknowledge, signature, multiselect, url)", value)
 	}
 }
 
 func normalizeSubstepInputConfig(substep *WorkflowSub) error {
+	if err := normalizeSubstepHelpConfig(substep); err != nil {
+		return err
+	}
+	if isAcknowledgeSubstep(*substep) {
+		if len(substep.Schema) > 0 || len(substep.UISchema) > 0 {
+			return errors.New("schema is not allowed when inputType=acknowledge")
+		}
+		return nil
+	}
+	if isSignatureSubstep(*substep) {
+		if len(substep.Schema) > 0 || len(substep.UISchema) > 0 {
+			return errors.New("schema is not allowed when inputType=signature")
+		}
+		return nil
+	}
+	if len(substep.AllowedHosts) > 0 && !isURLSubstep(*substep) {
+		return errors.New("allowedHosts are only allowed when inputType=url")
+	}
+	if isMultiselectSubstep(*substep) {
+		return normalizeMultiselectConfig(substep)
+	}
+	if len(substep.Options) > 0 {
+		return errors.New("options are only allowed when inputType=multiselect")
+	}
+	if isURLSubstep(*substep) {
+		return normalizeURLConfig(substep)
+	}
 	if len(substep.Schema) == 0 {
 		return errors.New("schema is required when inputType=formata")
 	}
-	return nil
+	return validateSubstepNumberRangeConfig(*substep)
 }
 
 func normalizeDPPConfig(cfg *DPPConfig) error {
@@ -7530,8 +9388,11 @@ func normalizeDPPConfig(cfg *DPPConfig) error {
 	cfg.SerialInputKey = strings.TrimSpace(cfg.SerialInputKey)
 	cfg.SerialStrategy = strings.TrimSpace(cfg.SerialStrategy)
 	cfg.ProductName = strings.TrimSpace(cfg.ProductName)
+	cfg.ProductNameInputKey = strings.TrimSpace(cfg.ProductNameInputKey)
 	cfg.ProductDescription = strings.TrimSpace(cfg.ProductDescription)
+	cfg.ProductDescriptionInputKey = strings.TrimSpace(cfg.ProductDescriptionInputKey)
 	cfg.OwnerName = strings.TrimSpace(cfg.OwnerName)
+	cfg.OwnerNameInputKey = strings.TrimSpace(cfg.OwnerNameInputKey)
 
 	if cfg.LotInputKey == "" {
 		cfg.LotInputKey = "batchId"
@@ -7547,11 +9408,21 @@ func normalizeDPPConfig(cfg *DPPConfig) error {
 		return err
 	}
 	cfg.SerialStrategy = normalizedStrategy
+	if cfg.RequireSerialInput && cfg.SerialInputKey == "" {
+		return errors.New("dpp.requireSerialInput needs dpp.serialInputKey")
+	}
 
 	if !cfg.Enabled {
 		return nil
 	}
 
+	if cfg.GTINComputeCheckDigit {
+		completed, err := appendGTINCheckDigit(cfg.GTIN)
+		if err != nil {
+			return err
+		}
+		cfg.GTIN = completed
+	}
 	normalizedGTIN, err := normalizeGTIN(cfg.GTIN)
 	if err != nil {
 		return err
@@ -7576,9 +9447,40 @@ func normalizeGTIN(raw string) (string, error) {
 	if len(trimmed) < 14 {
 		trimmed = strings.Repeat("0", 14-len(trimmed)) + trimmed
 	}
+	if want := gs1CheckDigit(trimmed[:13]); trimmed[13] != want {
+		return "", fmt.Errorf("dpp.gtin has an invalid check digit: %q (expected %c)", raw, want)
+	}
 	return trimmed, nil
 }
 
+// gs1CheckDigit computes the GS1 Mod-10 check digit for body: digits are
+// weighted 3,1,3,… from the right and the digit tops the sum up to a
+// multiple of ten.
+func gs1CheckDigit(body string) byte {
+	sum := 0
+	for i := 0; i < len(body); i++ {
+		digit := int(body[len(body)-1-i] - '0')
+		if i%2 == 0 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+func appendGTINCheckDigit(raw string) (string, error) {
+	trimmed := strings.TrimSpace(raw)
+	if len(trimmed) != 13 {
+		return "", fmt.Errorf("dpp.gtinComputeCheckDigit needs a 13-digit gtin: %q", raw)
+	}
+	for _, char := range trimmed {
+		if char < '0' || char > '9' {
+			return "", fmt.Errorf("dpp.gtin must contain only digits: %q", raw)
+		}
+	}
+	return trimmed + string(gs1CheckDigit(trimmed)), nil
+}
+
 func normalizeDPPSerialStrategy(raw string) (string, error) {
 	strategy := strings.TrimSpace(raw)
 	if strategy == "" {
@@ -7620,10 +9522,11 @@ func processStepDescription(progress ProcessStep, sub WorkflowSub) string {
 }
 
 func processStepDataValue(progress ProcessStep, sub WorkflowSub) (interface{}, bool) {
-	if progress.Data == nil {
+	data := currentStepData(progress)
+	if data == nil {
 		return nil, false
 	}
-	return progress.Data, true
+	return data, true
 }
 
 func normalizePayload(sub WorkflowSub, value string) (map[string]interface{}, error) {
@@ -7635,6 +9538,25 @@ func normalizePayload(sub WorkflowSub, value string) (map[string]interface{}, er
 	if !ok {
 		return nil, errors.New("Value must be a valid JSON object.")
 	}
+	if isMultiselectSubstep(sub) {
+		key := multiselectInputKey(sub)
+		selected, err := normalizeMultiselectValue(sub, valueObject[key])
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{key: selected}, nil
+	}
+	if isURLSubstep(sub) {
+		key := urlInputKey(sub)
+		link, err := normalizeURLValue(sub, valueObject[key])
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{key: link}, nil
+	}
+	if err := validateNumberRange(sub, valueObject); err != nil {
+		return nil, err
+	}
 	return valueObject, nil
 }
 
@@ -7647,7 +9569,7 @@ func prefersJSONResponse(r *http.Request) bool {
 }
 
 func digestPayload(payload map[string]interface{}) string {
-	data, _ := json.Marshal(payload)
+	data, _ := json.Marshal(canonicalValue(payload))
 	hash := sha256.Sum256(data)
 	return hex.EncodeToString(hash[:])
 }
@@ -7663,7 +9585,7 @@ func (s *Server) processService() *ProcessService {
 	if s.process != nil {
 		return s.process
 	}
-	s.process = &ProcessService{store: s.store, now: s.now}
+	s.process = &ProcessService{store: s.store, now: s.now, signer: s.exportSigner, webhookClient: s.webhookClient}
 	return s.process
 }
 
@@ -7699,7 +9621,7 @@ func (s *Server) renderDepartmentProcessPage(w http.ResponseWriter, r *http.Requ
 		cfg, err = s.runtimeConfig()
 	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeWorkflowSelectionError(w, err)
 		return
 	}
 	ctx := context.Background()
@@ -7734,7 +9656,7 @@ func (s *Server) renderProcessContent(w http.ResponseWriter, r *http.Request, pr
 		cfg, err = s.runtimeConfig()
 	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeWorkflowSelectionError(w, err)
 		return
 	}
 	ctx := context.Background()
@@ -7794,3 +9716,33 @@ func (h *SSEHub) Broadcast(processID, message string) {
 		}
 	}
 }
+
+// BroadcastMany sends message to the subscribers of every key under a single
+// lock. Unsubscribe drops keys whose last subscriber leaves, so keys nobody
+// listens on cost one map lookup.
+func (h *SSEHub) BroadcastMany(keys []string, message string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.stream) == 0 {
+		return
+	}
+	for _, key := range keys {
+		for ch := range h.stream[key] {
+			select {
+			case ch <- message:
+			default:
+			}
+		}
+	}
+}
+
+// broadcastRolesUpdated tells the dashboards of every workflow role to
+// refresh.
+func (s *Server) broadcastRolesUpdated(workflowKey string, cfg RuntimeConfig) {
+	roles := s.roles(cfg)
+	keys := make([]string, 0, len(roles))
+	for _, role := range roles {
+		keys = append(keys, "role:"+workflowKey+":"+role)
+	}
+	s.sse.BroadcastMany(keys, "role-updated")
+}
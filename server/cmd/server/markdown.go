@@ -0,0 +1,158 @@
+package main
+
+import (
+	"html"
+	"html/template"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	markdownUnsafeBlockRe = regexp.MustCompile(`(?is)<(script|iframe|style|object|embed)\b[^>]*>.*?</(script|iframe|style|object|embed)\s*>`)
+	markdownUnsafeTagRe   = regexp.MustCompile(`(?i)</?(script|iframe|style|object|embed)\b[^>]*>`)
+	markdownHeadingRe     = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	markdownBulletRe      = regexp.MustCompile(`^[-*+]\s+(.*)$`)
+	markdownOrderedRe     = regexp.MustCompile(`^\d+[.)]\s+(.*)$`)
+	markdownLinkRe        = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	markdownBoldRe        = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	markdownItalicRe      = regexp.MustCompile(`\*([^*]+)\*`)
+)
+
+// renderMarkdown converts the small markdown subset used in workflow
+// descriptions (paragraphs, headings, lists, fenced code, emphasis, inline
+// code and links) into sanitized HTML. Raw HTML is never passed through:
+// script-like elements are dropped and everything else is escaped.
+func renderMarkdown(source string) template.HTML {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	source = markdownUnsafeBlockRe.ReplaceAllString(source, "")
+	source = markdownUnsafeTagRe.ReplaceAllString(source, "")
+	if strings.TrimSpace(source) == "" {
+		return ""
+	}
+
+	var out strings.Builder
+	var paragraph []string
+	listTag := ""
+	inCode := false
+	var code []string
+
+	flushParagraph := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+		out.WriteString("<p>" + renderMarkdownInline(strings.Join(paragraph, " ")) + "</p>")
+		paragraph = nil
+	}
+	closeList := func() {
+		if listTag == "" {
+			return
+		}
+		out.WriteString("</" + listTag + ">")
+		listTag = ""
+	}
+	openList := func(tag string) {
+		if listTag == tag {
+			return
+		}
+		closeList()
+		out.WriteString("<" + tag + ">")
+		listTag = tag
+	}
+
+	for _, line := range strings.Split(source, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			if inCode {
+				out.WriteString("<pre><code>" + html.EscapeString(strings.Join(code, "\n")) + "</code></pre>")
+				code = nil
+				inCode = false
+				continue
+			}
+			flushParagraph()
+			closeList()
+			inCode = true
+			continue
+		}
+		if inCode {
+			code = append(code, line)
+			continue
+		}
+		if trimmed == "" {
+			flushParagraph()
+			closeList()
+			continue
+		}
+		if match := markdownHeadingRe.FindStringSubmatch(trimmed); match != nil {
+			flushParagraph()
+			closeList()
+			tag := "h" + string(rune('0'+len(match[1])))
+			out.WriteString("<" + tag + ">" + renderMarkdownInline(match[2]) + "</" + tag + ">")
+			continue
+		}
+		if match := markdownBulletRe.FindStringSubmatch(trimmed); match != nil {
+			flushParagraph()
+			openList("ul")
+			out.WriteString("<li>" + renderMarkdownInline(match[1]) + "</li>")
+			continue
+		}
+		if match := markdownOrderedRe.FindStringSubmatch(trimmed); match != nil {
+			flushParagraph()
+			openList("ol")
+			out.WriteString("<li>" + renderMarkdownInline(match[1]) + "</li>")
+			continue
+		}
+		closeList()
+		paragraph = append(paragraph, trimmed)
+	}
+	if inCode {
+		out.WriteString("<pre><code>" + html.EscapeString(strings.Join(code, "\n")) + "</code></pre>")
+	}
+	flushParagraph()
+	closeList()
+	return template.HTML(out.String())
+}
+
+func renderMarkdownInline(text string) string {
+	segments := strings.Split(text, "`")
+	var out strings.Builder
+	for idx, segment := range segments {
+		if idx%2 == 1 && idx < len(segments)-1 {
+			out.WriteString("<code>" + html.EscapeString(segment) + "</code>")
+			continue
+		}
+		if idx%2 == 1 {
+			out.WriteString("`")
+		}
+		out.WriteString(renderMarkdownEmphasis(html.EscapeString(segment)))
+	}
+	return out.String()
+}
+
+func renderMarkdownEmphasis(escaped string) string {
+	escaped = markdownLinkRe.ReplaceAllStringFunc(escaped, func(match string) string {
+		parts := markdownLinkRe.FindStringSubmatch(match)
+		href := html.UnescapeString(parts[2])
+		if !isSafeMarkdownURL(href) {
+			return parts[1]
+		}
+		return `<a href="` + html.EscapeString(href) + `" rel="noopener noreferrer">` + parts[1] + `</a>`
+	})
+	escaped = markdownBoldRe.ReplaceAllString(escaped, "<strong>$1</strong>")
+	return markdownItalicRe.ReplaceAllString(escaped, "<em>$1</em>")
+}
+
+func isSafeMarkdownURL(raw string) bool {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(parsed.Scheme) {
+	case "http", "https", "mailto":
+		return true
+	case "":
+		return (strings.HasPrefix(raw, "/") && !strings.HasPrefix(raw, "//")) || strings.HasPrefix(raw, "#")
+	default:
+		return false
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdownBlocksAndInline(t *testing.T) {
+	got := string(renderMarkdown("## Overview\n\nTrack **batches** with *care*.\nSee [docs](https://example.com/docs?a=1&b=2) and `cfg.yaml`.\n\n- one\n- two\n\n1. first\n2. second\n\n```\n<raw>\n```"))
+	for _, want := range []string{
+		"<h2>Overview</h2>",
+		"<p>Track <strong>batches</strong> with <em>care</em>. See <a href=\"https://example.com/docs?a=1&amp;b=2\" rel=\"noopener noreferrer\">docs</a> and <code>cfg.yaml</code>.</p>",
+		"<ul><li>one</li><li>two</li></ul>",
+		"<ol><li>first</li><li>second</li></ol>",
+		"<pre><code>&lt;raw&gt;</code></pre>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in rendered markdown, got %q", want, got)
+		}
+	}
+}
+
+func TestRenderMarkdownSanitizesHTML(t *testing.T) {
+	got := string(renderMarkdown("Hello <script>alert(1)</script><iframe src=\"https://evil.test\"></iframe> <b onclick=\"x()\">bold</b>\n\n[click](javascript:void) [proto](//evil.test) [local](/my)"))
+	for _, unwanted := range []string{"<script", "alert(1)</", "<iframe", "<b ", "javascript:", "//evil.test"} {
+		if strings.Contains(got, unwanted) {
+			t.Fatalf("expected %q to be stripped, got %q", unwanted, got)
+		}
+	}
+	for _, want := range []string{"&lt;b onclick=&#34;x()&#34;&gt;bold&lt;/b&gt;", "<p>click proto <a href=\"/my\" rel=\"noopener noreferrer\">local</a></p>"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in rendered markdown, got %q", want, got)
+		}
+	}
+	if got := renderMarkdown("  <script>x</script> "); got != "" {
+		t.Fatalf("renderMarkdown(script only) = %q, want empty", got)
+	}
+}
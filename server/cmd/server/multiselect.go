@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SubstepOption is one checkbox of a multiselect substep. Value is what gets
+// notarized; Label is shown to users and defaults to Value.
+type SubstepOption struct {
+	Value string `bson:"value" yaml:"value"`
+	Label string `bson:"label,omitempty" yaml:"label,omitempty"`
+}
+
+// isMultiselectSubstep reports whether sub asks for any subset of its
+// Options.
+func isMultiselectSubstep(sub WorkflowSub) bool {
+	return normalizeInputTypeForCheck(sub.InputType) == "multiselect"
+}
+
+func multiselectInputKey(sub WorkflowSub) string {
+	if key := strings.TrimSpace(sub.InputKey); key != "" {
+		return key
+	}
+	return "selected"
+}
+
+// normalizeMultiselectConfig trims the options of a multiselect substep and
+// rejects empty or duplicate values.
+func normalizeMultiselectConfig(substep *WorkflowSub) error {
+	if len(substep.Schema) > 0 || len(substep.UISchema) > 0 {
+		return errors.New("schema is not allowed when inputType=multiselect")
+	}
+	if len(substep.Options) == 0 {
+		return errors.New("options are required when inputType=multiselect")
+	}
+	seen := make(map[string]bool, len(substep.Options))
+	for idx := range substep.Options {
+		option := &substep.Options[idx]
+		option.Value = strings.TrimSpace(option.Value)
+		option.Label = strings.TrimSpace(option.Label)
+		if option.Value == "" {
+			return fmt.Errorf("options[%d].value is required", idx)
+		}
+		if seen[option.Value] {
+			return fmt.Errorf("options value %q is duplicated", option.Value)
+		}
+		seen[option.Value] = true
+		if option.Label == "" {
+			option.Label = option.Value
+		}
+	}
+	return nil
+}
+
+// normalizeMultiselectValue checks that raw, a string or a list of strings,
+// only names option values, and returns the selection deduplicated and
+// sorted so the same choices always hash to the same digest.
+func normalizeMultiselectValue(sub WorkflowSub, raw interface{}) ([]interface{}, error) {
+	var items []interface{}
+	switch typed := raw.(type) {
+	case nil:
+	case string:
+		items = []interface{}{typed}
+	case []interface{}:
+		items = typed
+	case primitive.A:
+		items = typed
+	default:
+		return nil, fmt.Errorf("%s must be a list of options.", multiselectInputKey(sub))
+	}
+	allowed := make(map[string]bool, len(sub.Options))
+	for _, option := range sub.Options {
+		allowed[strings.TrimSpace(option.Value)] = true
+	}
+	seen := map[string]bool{}
+	selected := []string{}
+	for _, item := range items {
+		value, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s must be a list of options.", multiselectInputKey(sub))
+		}
+		value = strings.TrimSpace(value)
+		if value == "" || seen[value] {
+			continue
+		}
+		if !allowed[value] {
+			return nil, fmt.Errorf("%q is not an option of this step.", value)
+		}
+		seen[value] = true
+		selected = append(selected, value)
+	}
+	sort.Strings(selected)
+	normalized := make([]interface{}, 0, len(selected))
+	for _, value := range selected {
+		normalized = append(normalized, value)
+	}
+	return normalized, nil
+}
+
+// parseMultiselectPayload reads the checked boxes, posted as repeated
+// inputKey fields, or a JSON object in "value" for API clients, and returns
+// {<inputKey>: [...]} through normalizePayload.
+func parseMultiselectPayload(r *http.Request, substep WorkflowSub) (map[string]interface{}, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, errInvalidForm
+	}
+	rawValue := strings.TrimSpace(r.PostForm.Get("value"))
+	if rawValue == "" {
+		selected := r.PostForm[multiselectInputKey(substep)]
+		if selected == nil {
+			selected = []string{}
+		}
+		data, err := json.Marshal(map[string]interface{}{multiselectInputKey(substep): selected})
+		if err != nil {
+			return nil, errInvalidForm
+		}
+		rawValue = string(data)
+	}
+	return normalizePayload(substep, rawValue)
+}
+
+// substepDisplayValues flattens a stored payload for display. Multiselect
+// answers are shown as their option labels joined in option order, url
+// answers as a link (url_input.go).
+func substepDisplayValues(sub WorkflowSub, raw interface{}) []SubstepKV {
+	data, ok := raw.(map[string]interface{})
+	if isURLSubstep(sub) && ok {
+		return urlDisplayValues(sub, data)
+	}
+	if !isMultiselectSubstep(sub) || !ok {
+		return flattenDisplayValues("", raw)
+	}
+	key := multiselectInputKey(sub)
+	selected, err := normalizeMultiselectValue(sub, data[key])
+	if err != nil {
+		return flattenDisplayValues("", raw)
+	}
+	chosen := make(map[string]bool, len(selected))
+	for _, value := range selected {
+		chosen[value.(string)] = true
+	}
+	var labels []string
+	for _, option := range sub.Options {
+		if chosen[strings.TrimSpace(option.Value)] {
+			label := strings.TrimSpace(option.Label)
+			if label == "" {
+				label = strings.TrimSpace(option.Value)
+			}
+			labels = append(labels, label)
+		}
+	}
+	value := strings.Join(labels, ", ")
+	if value == "" {
+		value = "None selected"
+	}
+	return []SubstepKV{{Key: key, Value: value}}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func testMultiselectSubstep() WorkflowSub {
+	return WorkflowSub{SubstepID: "1.1", InputType: "multiselect", InputKey: "checks", Options: []SubstepOption{
+		{Value: "seal", Label: "Seal intact"},
+		{Value: "temp", Label: "Temperature logged"},
+		{Value: "docs"},
+	}}
+}
+
+func TestNormalizeInputTypesMultiselect(t *testing.T) {
+	workflow := WorkflowDef{Steps: []WorkflowStep{{StepID: "1", Substep: []WorkflowSub{
+		{SubstepID: "1.1", InputType: " MultiSelect ", Options: []SubstepOption{{Value: " a "}, {Value: "b", Label: "B"}}},
+	}}}}
+	if err := normalizeInputTypes(&workflow); err != nil {
+		t.Fatalf("normalizeInputTypes: %v", err)
+	}
+	sub := workflow.Steps[0].Substep[0]
+	if sub.InputType != "multiselect" || !reflect.DeepEqual(sub.Options, []SubstepOption{{Value: "a", Label: "a"}, {Value: "b", Label: "B"}}) {
+		t.Fatalf("substep = %#v", sub)
+	}
+
+	for name, options := range map[string][]SubstepOption{
+		"missing":   nil,
+		"blank":     {{Value: " "}},
+		"duplicate": {{Value: "a"}, {Value: "a"}},
+	} {
+		workflow.Steps[0].Substep[0].Options = options
+		if err := normalizeInputTypes(&workflow); err == nil {
+			t.Fatalf("%s options: expected error", name)
+		}
+	}
+	workflow.Steps[0].Substep[0] = WorkflowSub{SubstepID: "1.1", InputType: "formata", Schema: map[string]interface{}{"type": "object"}, Options: []SubstepOption{{Value: "a"}}}
+	if err := normalizeInputTypes(&workflow); err == nil || !strings.Contains(err.Error(), "only allowed when inputType=multiselect") {
+		t.Fatalf("expected options rejection on formata, got %v", err)
+	}
+}
+
+func TestNormalizePayloadMultiselectIsOrderStable(t *testing.T) {
+	sub := testMultiselectSubstep()
+	first, err := normalizePayload(sub, `{"checks":["temp","seal","temp"]}`)
+	if err != nil {
+		t.Fatalf("normalizePayload: %v", err)
+	}
+	second, err := normalizePayload(sub, `{"checks":["seal","temp"]}`)
+	if err != nil {
+		t.Fatalf("normalizePayload: %v", err)
+	}
+	if !reflect.DeepEqual(first, map[string]interface{}{"checks": []interface{}{"seal", "temp"}}) || digestPayload(first) != digestPayload(second) {
+		t.Fatalf("first = %#v second = %#v", first, second)
+	}
+	if _, err := normalizePayload(sub, `{"checks":["seal","other"]}`); err == nil || !strings.Contains(err.Error(), `"other" is not an option`) {
+		t.Fatalf("expected unknown option error, got %v", err)
+	}
+	if _, err := normalizePayload(sub, `{"checks":[1]}`); err == nil {
+		t.Fatal("expected non-string option to be rejected")
+	}
+	if got := substepDisplayValues(sub, first); !reflect.DeepEqual(got, []SubstepKV{{Key: "checks", Value: "Seal intact, Temperature logged"}}) {
+		t.Fatalf("display values = %#v", got)
+	}
+}
+
+func TestHandleCompleteSubstepMultiselect(t *testing.T) {
+	store := NewMemoryStore()
+	server, processID, _ := newServerForCompleteTests(t, store, fakeAuthorizer{})
+	cfg := testFormataRuntimeConfig()
+	sub := &cfg.Workflow.Steps[0].Substep[0]
+	multiselect := testMultiselectSubstep()
+	sub.InputType, sub.InputKey, sub.Options, sub.Schema = multiselect.InputType, multiselect.InputKey, multiselect.Options, nil
+	server.configProvider = func() (RuntimeConfig, error) { return cfg, nil }
+	post := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/process/"+processID+"/substep/1.1/complete", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		server.handleCompleteSubstep(rec, req, processID, "1.1")
+		return rec
+	}
+
+	if rec := post(url.Values{"checks": {"seal", "forged"}}); rec.Code != http.StatusBadRequest {
+		t.Fatalf("unknown option status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if rec := post(url.Values{"checks": {"temp", "seal"}}); rec.Code != http.StatusOK {
+		t.Fatalf("complete status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	id, _ := primitive.ObjectIDFromHex(processID)
+	process, _ := store.SnapshotProcess(id)
+	progress := normalizeProgressKeys(process.Progress)["1.1"]
+	if progress.State != "done" || !reflect.DeepEqual(progress.Data, map[string]interface{}{"checks": []interface{}{"seal", "temp"}}) {
+		t.Fatalf("progress = %#v", progress)
+	}
+}
+
+func TestSubstepBodyTemplateMultiselectRendersCheckboxes(t *testing.T) {
+	tmpl := parseTestTemplates(t)
+	sub := testMultiselectSubstep()
+	action := withSubstepBodyMode(SubstepBodyView{
+		WorkflowKey:   "workflow",
+		ProcessID:     "process-1",
+		SubstepID:     "1.1",
+		InputKey:      sub.InputKey,
+		InputType:     "multiselect",
+		Options:       sub.Options,
+		Status:        "available",
+		MatchingRoles: []SubstepRoleOption{{Slug: "dep1", Label: "Dep 1"}},
+	})
+	var out bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&out, "substep_body", action); err != nil {
+		t.Fatalf("render substep_body template: %v", err)
+	}
+	body := out.String()
+	for _, marker := range []string{`type="checkbox"`, `name="checks"`, `value="temp"`, "Temperature logged", "docs"} {
+		if !strings.Contains(body, marker) {
+			t.Fatalf("expected %q in body: %s", marker, body)
+		}
+	}
+	if strings.Contains(body, "js-formata-host") {
+		t.Fatalf("multiselect substep must not render a formata form: %s", body)
+	}
+}
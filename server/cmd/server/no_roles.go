@@ -0,0 +1,32 @@
+package main
+
+import "strings"
+
+// noRolesMessage replaces the empty lists a signed-in user without any role
+// would otherwise see: org admins can create accounts and invites with no
+// roles, and such users can sign in before one is assigned.
+const noRolesMessage = "You have no roles assigned yet. Contact your organization admin to get access to streams."
+
+// userHasNoRoles reports whether user is signed in without any role.
+// Platform admins do not need one, and without enforced auth every user
+// acts with every workflow role.
+func (s *Server) userHasNoRoles(user *AccountUser) bool {
+	if !s.enforceAuth || user == nil || user.IsPlatformAdmin {
+		return false
+	}
+	for _, role := range user.RoleSlugs {
+		if strings.TrimSpace(role) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// noRolesNotice returns noRolesMessage for users without roles and "" for
+// everyone else, ready for the no_roles_notice template.
+func (s *Server) noRolesNotice(user *AccountUser) string {
+	if s.userHasNoRoles(user) {
+		return noRolesMessage
+	}
+	return ""
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUserHasNoRoles(t *testing.T) {
+	server := &Server{enforceAuth: true}
+	if !server.userHasNoRoles(&AccountUser{RoleSlugs: []string{" "}}) {
+		t.Fatal("expected blank roles to count as none")
+	}
+	if server.userHasNoRoles(&AccountUser{RoleSlugs: []string{"dep1"}}) || server.userHasNoRoles(&AccountUser{IsPlatformAdmin: true}) || server.userHasNoRoles(nil) {
+		t.Fatal("expected users with a role, platform admins and anonymous users to be excluded")
+	}
+	if (&Server{}).userHasNoRoles(&AccountUser{}) {
+		t.Fatal("expected no notice without enforced auth")
+	}
+}
+
+func TestNoRolesUserSeesNoticeOnHomeAndDashboard(t *testing.T) {
+	now := time.Date(2026, 2, 4, 11, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	writeTwoSubstepWorkflowConfig(t, filepath.Join(dir, "alpha.yaml"), "Alpha")
+	user := AccountUser{ID: primitive.NewObjectID(), IdentityUserID: "user-1", Email: "user@example.com", Status: "active", OrgSlug: "org1"}
+	server := &Server{
+		store:       NewMemoryStore(),
+		tmpl:        parseTestTemplates(t),
+		configDir:   dir,
+		authorizer:  fakeAuthorizer{},
+		identity:    testIdentityForSessions(now, map[string]AccountUser{"session-user": user}),
+		enforceAuth: true,
+		now:         func() time.Time { return now },
+	}
+	get := func(handler http.HandlerFunc, path string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.AddCookie(&http.Cookie{Name: "attesta_session", Value: "session-user"})
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s status = %d, want %d: %s", path, rec.Code, http.StatusOK, rec.Body.String())
+		}
+		return rec
+	}
+
+	for _, path := range []string{"/", "/my"} {
+		handler := server.handlePublicHome
+		if path == "/my" {
+			handler = server.handleHome
+		}
+		body := get(handler, path).Body.String()
+		if !strings.Contains(body, `class="warning no-roles-notice"`) || !strings.Contains(body, "You have no roles assigned yet.") {
+			t.Fatalf("%s: expected no roles notice, got %q", path, body)
+		}
+		if strings.Contains(body, "stream-card-") {
+			t.Fatalf("%s: expected no stream cards, got %q", path, body)
+		}
+	}
+
+	var response AggregatedDashboardResponse
+	if err := json.Unmarshal(get(server.handleMyRoutes, "/my/dashboard").Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode dashboard: %v", err)
+	}
+	if !response.NoRoles || response.Message != noRolesMessage || len(response.Workflows) != 0 {
+		t.Fatalf("dashboard = %#v", response)
+	}
+}
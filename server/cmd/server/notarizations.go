@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type NotarizationView struct {
+	SubstepID    string                 `json:"substep_id"`
+	ActorID      string                 `json:"actor_id,omitempty"`
+	ActorRole    string                 `json:"actor_role,omitempty"`
+	CreatedAt    string                 `json:"created_at"`
+	Method       string                 `json:"method"`
+	Digest       string                 `json:"digest"`
+	AmendsDigest string                 `json:"amends_digest,omitempty"`
+	Payload      map[string]interface{} `json:"payload"`
+}
+
+// SubstepNotarizationResponse is notarization.json: the latest notarization
+// of a substep plus its whole chain, oldest first, so amended values can be
+// traced back to the original submission.
+type SubstepNotarizationResponse struct {
+	ProcessID    string             `json:"process_id"`
+	SubstepID    string             `json:"substep_id"`
+	Notarization NotarizationView   `json:"notarization"`
+	Chain        []NotarizationView `json:"chain"`
+}
+
+func notarizationView(notarization Notarization) NotarizationView {
+	return NotarizationView{
+		SubstepID:    notarization.SubstepID,
+		ActorID:      strings.TrimSpace(notarization.Actor.ID),
+		ActorRole:    strings.TrimSpace(notarization.Actor.Role),
+		CreatedAt:    notarization.CreatedAt.UTC().Format(time.RFC3339),
+		Method:       notarization.FakeNotary.Method,
+		Digest:       notarization.FakeNotary.Digest,
+		AmendsDigest: notarization.AmendsDigest,
+		Payload:      notarization.Payload,
+	}
+}
+
+// handleSubstepNotarization serves notarization.json for one substep. It
+// answers 404 until the substep has been notarized.
+func (s *Server) handleSubstepNotarization(w http.ResponseWriter, r *http.Request, processID, substepID string) {
+	workflowKey, _, ok := s.selectedWorkflowOrRedirectHome(w, r)
+	if !ok {
+		return
+	}
+	process, err := s.loadProcess(r.Context(), processID)
+	if err != nil || !s.processBelongsToWorkflow(process, workflowKey) {
+		http.NotFound(w, r)
+		return
+	}
+	latest, err := s.store.GetNotarizationBySubstep(r.Context(), process.ID, substepID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		logAndHTTPError(w, r, http.StatusInternalServerError, "failed to load notarization", err, "failed to load notarization for process %s substep %s", processID, substepID)
+		return
+	}
+	notarizations, err := s.store.ListNotarizations(r.Context(), process.ID)
+	if err != nil {
+		logAndHTTPError(w, r, http.StatusInternalServerError, "failed to load notarizations", err, "failed to list notarizations for process %s", processID)
+		return
+	}
+	response := SubstepNotarizationResponse{
+		ProcessID:    process.ID.Hex(),
+		SubstepID:    substepID,
+		Notarization: notarizationView(*latest),
+		Chain:        []NotarizationView{},
+	}
+	for _, notarization := range notarizations {
+		if notarization.SubstepID == substepID {
+			response.Chain = append(response.Chain, notarizationView(notarization))
+		}
+	}
+	writeJSON(w, response)
+}
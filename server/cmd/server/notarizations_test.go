@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestHandleSubstepNotarizationReturnsLatestAndChain(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Date(2026, 2, 3, 9, 0, 0, 0, time.UTC)
+	processID := store.SeedProcess(Process{WorkflowKey: "workflow", CreatedAt: now, Status: "active"})
+	actor := Actor{ID: "u1", Role: "dep1"}
+	original := map[string]interface{}{"value": 1.0}
+	amended := map[string]interface{}{"value": 2.0}
+	_ = store.InsertNotarization(context.Background(), Notarization{ProcessID: processID, SubstepID: "1.1", Payload: amended, Actor: actor, CreatedAt: now.Add(2 * time.Minute), FakeNotary: FakeNotary{Method: "sha256", Digest: digestPayload(amended)}, AmendsDigest: digestPayload(original)})
+	_ = store.InsertNotarization(context.Background(), Notarization{ProcessID: processID, SubstepID: "1.1", Payload: original, Actor: actor, CreatedAt: now.Add(time.Minute), FakeNotary: FakeNotary{Method: "sha256", Digest: digestPayload(original)}})
+	_ = store.InsertNotarization(context.Background(), Notarization{ProcessID: processID, SubstepID: "1.2", Payload: original, Actor: actor, CreatedAt: now.Add(3 * time.Minute), FakeNotary: FakeNotary{Method: "sha256", Digest: digestPayload(original)}})
+	_ = store.InsertNotarization(context.Background(), Notarization{ProcessID: primitive.NewObjectID(), SubstepID: "1.1", CreatedAt: now})
+
+	server := &Server{
+		store: store,
+		configProvider: func() (RuntimeConfig, error) {
+			return testRuntimeConfig(), nil
+		},
+	}
+	req := httptest.NewRequest(http.MethodGet, "/process/"+processID.Hex()+"/substep/1.1/notarization.json", nil)
+	rec := httptest.NewRecorder()
+	server.handleSubstepNotarization(rec, req, processID.Hex(), "1.1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var response SubstepNotarizationResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if response.ProcessID != processID.Hex() || response.SubstepID != "1.1" {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+	latest := response.Notarization
+	if latest.Digest != digestPayload(amended) || latest.AmendsDigest != digestPayload(original) || latest.ActorID != "u1" || latest.ActorRole != "dep1" || latest.CreatedAt != "2026-02-03T09:02:00Z" || latest.Method != "sha256" {
+		t.Fatalf("unexpected latest notarization: %+v", latest)
+	}
+	if len(response.Chain) != 2 || response.Chain[0].Digest != digestPayload(original) || response.Chain[1].Digest != digestPayload(amended) {
+		t.Fatalf("unexpected chain: %+v", response.Chain)
+	}
+
+	rec = httptest.NewRecorder()
+	server.handleSubstepNotarization(rec, httptest.NewRequest(http.MethodGet, "/process/"+processID.Hex()+"/substep/2.1/notarization.json", nil), processID.Hex(), "2.1")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status for unnotarized substep = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestMongoStoreListNotarizationsReportsCursorError(t *testing.T) {
+	cursorErr := errors.New("cursor interrupted")
+	collection := &fakeMongoCollection{
+		findFn: func(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (mongoCursorPort, error) {
+			return &fakeAnyCursor{err: cursorErr}, nil
+		},
+	}
+	store := &MongoStore{dbPort: &fakeMongoDatabase{collections: map[string]*fakeMongoCollection{"notarizations": collection}}}
+	if _, err := store.ListNotarizations(context.Background(), primitive.NewObjectID()); !errors.Is(err, cursorErr) {
+		t.Fatalf("expected cursor error, got %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const notarizedSignatureAlgorithm = "HMAC-SHA256"
+
+// NotarizedSignature is the detached signature carried in notarized.json. It
+// covers the canonical JSON of the export with the signature field removed.
+type NotarizedSignature struct {
+	Algorithm string `json:"algorithm"`
+	KeyID     string `json:"key_id,omitempty"`
+	Value     string `json:"value"`
+}
+
+// exportSigner signs notarized exports with a shared secret
+// (NOTARIZED_SIGNING_KEY). KeyID (NOTARIZED_SIGNING_KEY_ID) lets recipients
+// tell rotated keys apart.
+type exportSigner struct {
+	key   []byte
+	keyID string
+}
+
+func exportSignerFromEnv() *exportSigner {
+	key := strings.TrimSpace(os.Getenv("NOTARIZED_SIGNING_KEY"))
+	if key == "" {
+		return nil
+	}
+	return &exportSigner{key: []byte(key), keyID: strings.TrimSpace(os.Getenv("NOTARIZED_SIGNING_KEY_ID"))}
+}
+
+func (s *exportSigner) sign(export NotarizedProcessExport) (NotarizedSignature, error) {
+	export.Signature = nil
+	return s.signValue(export)
+}
+
+// signValue signs the canonical JSON of value. Callers clear any embedded
+// signature field first.
+func (s *exportSigner) signValue(value interface{}) (NotarizedSignature, error) {
+	canonical, err := canonicalJSON(value)
+	if err != nil {
+		return NotarizedSignature{}, err
+	}
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write(canonical)
+	return NotarizedSignature{
+		Algorithm: notarizedSignatureAlgorithm,
+		KeyID:     s.keyID,
+		Value:     hex.EncodeToString(mac.Sum(nil)),
+	}, nil
+}
+
+// canonicalJSON encodes value with object keys sorted at every level, no
+// insignificant whitespace and no HTML escaping, so the bytes are the same
+// on every run and easy to reproduce outside Go.
+func canonicalJSON(value interface{}) ([]byte, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(generic); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(out.Bytes(), []byte("\n")), nil
+}
+
+// signNotarizedExport attaches a signature when a signing key is configured
+// and returns the export unchanged otherwise.
+func (s *Server) signNotarizedExport(export NotarizedProcessExport) (NotarizedProcessExport, error) {
+	if s.exportSigner == nil {
+		return export, nil
+	}
+	signature, err := s.exportSigner.sign(export)
+	if err != nil {
+		return NotarizedProcessExport{}, err
+	}
+	export.Signature = &signature
+	return export, nil
+}
+
+// writeNotarizedExport signs export when configured and answers it as JSON,
+// honouring If-None-Match.
+func (s *Server) writeNotarizedExport(w http.ResponseWriter, r *http.Request, export NotarizedProcessExport) {
+	signed, err := s.signNotarizedExport(export)
+	if err != nil {
+		logAndHTTPError(w, r, http.StatusInternalServerError, "failed to sign export", err, "failed to sign notarized export for process %s", export.ProcessID)
+		return
+	}
+	if writeNotModified(w, r, notarizedExportETag(signed)) {
+		return
+	}
+	writeJSON(w, signed)
+}
+
+// handleNotarizedSignature serves notarized.json.sig: the hex signature alone,
+// for recipients who keep the JSON and its signature as separate files.
+func (s *Server) handleNotarizedSignature(w http.ResponseWriter, r *http.Request, processID string) {
+	if s.exportSigner == nil {
+		http.NotFound(w, r)
+		return
+	}
+	workflowKey, cfg, ok := s.selectedWorkflowOrRedirectHome(w, r)
+	if !ok {
+		return
+	}
+	process, err := s.loadProcess(r.Context(), processID)
+	if err != nil || !s.processBelongsToWorkflow(process, workflowKey) {
+		http.NotFound(w, r)
+		return
+	}
+	s.writeNotarizedSignature(w, r, buildNotarizedExport(cfg.Workflow, process))
+}
+
+// writeNotarizedSignature answers the bare signature of export, or 404 when
+// no signing key is configured. Every notarized.json.sig route uses it so
+// the value always matches the export served next to it.
+func (s *Server) writeNotarizedSignature(w http.ResponseWriter, r *http.Request, export NotarizedProcessExport) {
+	if s.exportSigner == nil {
+		http.NotFound(w, r)
+		return
+	}
+	signature, err := s.exportSigner.sign(export)
+	if err != nil {
+		logAndHTTPError(w, r, http.StatusInternalServerError, "failed to sign export", err, "failed to sign notarized export for process %s", export.ProcessID)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Signature-Algorithm", signature.Algorithm)
+	if signature.KeyID != "" {
+		w.Header().Set("X-Signature-Key-Id", signature.KeyID)
+	}
+	_, _ = w.Write([]byte(signature.Value + "\n"))
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCanonicalJSONSortsKeysWithoutEscaping(t *testing.T) {
+	got, err := canonicalJSON(map[string]interface{}{
+		"b": 1.50,
+		"a": map[string]interface{}{"z": "<x>", "y": []interface{}{2, "&"}},
+	})
+	if err != nil {
+		t.Fatalf("canonicalJSON: %v", err)
+	}
+	if want := `{"a":{"y":[2,"&"],"z":"<x>"},"b":1.5}`; string(got) != want {
+		t.Fatalf("canonical = %s, want %s", got, want)
+	}
+}
+
+func TestNotarizedExportSignature(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Date(2026, 2, 3, 9, 0, 0, 0, time.UTC)
+	processID := store.SeedProcess(Process{
+		ID:        primitive.NewObjectID(),
+		CreatedAt: now,
+		Status:    "active",
+		Progress: map[string]ProcessStep{
+			"1_1": {State: "done", DoneAt: ptrTime(now), DoneBy: &Actor{ID: "u1", Role: "dep1"}, Data: map[string]interface{}{"value": 42}},
+		},
+	})
+	server := &Server{
+		store:          store,
+		configProvider: func() (RuntimeConfig, error) { return testRuntimeConfig(), nil },
+	}
+	get := func(handler func(http.ResponseWriter, *http.Request, string)) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, "/instance/"+processID.Hex()+"/notarized.json", nil), processID.Hex())
+		return rec
+	}
+
+	if body := get(server.handleNotarizedJSON).Body.String(); strings.Contains(body, `"signature"`) {
+		t.Fatalf("expected unsigned export without a key, got %s", body)
+	}
+	if rec := get(server.handleNotarizedSignature); rec.Code != http.StatusNotFound {
+		t.Fatalf("signature without key status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	server.exportSigner = &exportSigner{key: []byte("secret"), keyID: "2026-02"}
+	var export NotarizedProcessExport
+	if err := json.Unmarshal(get(server.handleNotarizedJSON).Body.Bytes(), &export); err != nil {
+		t.Fatalf("decode export: %v", err)
+	}
+	if export.Signature == nil || export.Signature.Algorithm != notarizedSignatureAlgorithm || export.Signature.KeyID != "2026-02" {
+		t.Fatalf("unexpected signature %#v", export.Signature)
+	}
+	signature := *export.Signature
+	export.Signature = nil
+	canonical, err := canonicalJSON(export)
+	if err != nil {
+		t.Fatalf("canonicalJSON: %v", err)
+	}
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(canonical)
+	if want := hex.EncodeToString(mac.Sum(nil)); signature.Value != want {
+		t.Fatalf("signature = %s, want %s", signature.Value, want)
+	}
+
+	rec := get(server.handleNotarizedSignature)
+	if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != signature.Value {
+		t.Fatalf("detached signature status = %d body = %q", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("X-Signature-Key-Id") != "2026-02" {
+		t.Fatalf("expected key id header, got %#v", rec.Header())
+	}
+}
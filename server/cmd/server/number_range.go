@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const numberRangeStepTolerance = 1e-9
+
+func substepHasNumberRange(sub WorkflowSub) bool {
+	return sub.Min != nil || sub.Max != nil || sub.Step != nil
+}
+
+// validateSubstepNumberRangeConfig rejects bounds that no value could satisfy.
+func validateSubstepNumberRangeConfig(sub WorkflowSub) error {
+	if !substepHasNumberRange(sub) {
+		return nil
+	}
+	if strings.TrimSpace(sub.InputKey) == "" {
+		return errors.New("min/max/step require inputKey")
+	}
+	if sub.Min != nil && sub.Max != nil && *sub.Min > *sub.Max {
+		return fmt.Errorf("min %s is greater than max %s", formatRangeNumber(*sub.Min), formatRangeNumber(*sub.Max))
+	}
+	if sub.Step != nil && *sub.Step <= 0 {
+		return fmt.Errorf("step must be greater than 0, got %s", formatRangeNumber(*sub.Step))
+	}
+	return nil
+}
+
+// validateNumberRange checks payload[sub.InputKey] against the substep
+// min/max/step bounds. Missing values are left to the form schema.
+func validateNumberRange(sub WorkflowSub, payload map[string]interface{}) error {
+	if !substepHasNumberRange(sub) {
+		return nil
+	}
+	raw, ok := payload[strings.TrimSpace(sub.InputKey)]
+	if !ok || raw == nil {
+		return nil
+	}
+	label := strings.TrimSpace(sub.Title)
+	if label == "" {
+		label = strings.TrimSpace(sub.InputKey)
+	}
+	value, ok := rangeNumberValue(raw)
+	if !ok {
+		return fmt.Errorf("%s must be a number.", label)
+	}
+	if sub.Min != nil && value < *sub.Min {
+		return fmt.Errorf("%s must be at least %s.", label, formatRangeNumber(*sub.Min))
+	}
+	if sub.Max != nil && value > *sub.Max {
+		return fmt.Errorf("%s must be at most %s.", label, formatRangeNumber(*sub.Max))
+	}
+	if sub.Step != nil {
+		base := 0.0
+		if sub.Min != nil {
+			base = *sub.Min
+		}
+		steps := (value - base) / *sub.Step
+		if math.Abs(steps-math.Round(steps)) > numberRangeStepTolerance {
+			if base == 0 {
+				return fmt.Errorf("%s must be a multiple of %s.", label, formatRangeNumber(*sub.Step))
+			}
+			return fmt.Errorf("%s must be %s plus a multiple of %s.", label, formatRangeNumber(base), formatRangeNumber(*sub.Step))
+		}
+	}
+	return nil
+}
+
+func rangeNumberValue(raw interface{}) (float64, bool) {
+	switch typed := raw.(type) {
+	case float64:
+		return typed, true
+	case int:
+		return float64(typed), true
+	case int64:
+		return float64(typed), true
+	case string:
+		value, err := strconv.ParseFloat(strings.TrimSpace(typed), 64)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+			return 0, false
+		}
+		return value, true
+	default:
+		return 0, false
+	}
+}
+
+func formatRangeNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// schemaWithNumberRange copies the substep bounds onto the inputKey property
+// of the form schema so the rendered number input gets matching
+// min/max/step attributes. Server-side validation remains authoritative.
+func schemaWithNumberRange(sub WorkflowSub) map[string]interface{} {
+	if !substepHasNumberRange(sub) || sub.Schema == nil {
+		return sub.Schema
+	}
+	schema := cloneInterfaceMap(sub.Schema)
+	properties, ok := schema["properties"].(map[string]interface{})
+	if !ok {
+		return sub.Schema
+	}
+	property, ok := properties[strings.TrimSpace(sub.InputKey)].(map[string]interface{})
+	if !ok {
+		return sub.Schema
+	}
+	if sub.Min != nil {
+		property["minimum"] = *sub.Min
+	}
+	if sub.Max != nil {
+		property["maximum"] = *sub.Max
+	}
+	if sub.Step != nil && (sub.Min == nil || *sub.Min == 0) {
+		property["multipleOf"] = *sub.Step
+	}
+	return schema
+}
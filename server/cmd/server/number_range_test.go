@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func ptrFloat(value float64) *float64 {
+	return &value
+}
+
+func TestNormalizePayloadNumberRange(t *testing.T) {
+	sub := WorkflowSub{
+		Title:     "Temperature",
+		InputKey:  "value",
+		InputType: "formata",
+		Min:       ptrFloat(-2.5),
+		Max:       ptrFloat(10),
+		Step:      ptrFloat(0.5),
+	}
+
+	for _, value := range []string{`-2.5`, `10`, `0.5`, `"3"`} {
+		if _, err := normalizePayload(sub, `{"value":`+value+`}`); err != nil {
+			t.Fatalf("normalizePayload(%s): %v", value, err)
+		}
+	}
+	if _, err := normalizePayload(sub, `{"other":1}`); err != nil {
+		t.Fatalf("expected missing value to be left to schema, got %v", err)
+	}
+
+	for value, want := range map[string]string{
+		`-2.6`:  "Temperature must be at least -2.5.",
+		`10.01`: "Temperature must be at most 10.",
+		`0.75`:  "Temperature must be -2.5 plus a multiple of 0.5.",
+		`"abc"`: "Temperature must be a number.",
+		`true`:  "Temperature must be a number.",
+	} {
+		_, err := normalizePayload(sub, `{"value":`+value+`}`)
+		if err == nil || err.Error() != want {
+			t.Fatalf("normalizePayload(%s) error = %v, want %q", value, err, want)
+		}
+	}
+
+	stepOnly := WorkflowSub{InputKey: "qty", Step: ptrFloat(5)}
+	if _, err := normalizePayload(stepOnly, `{"qty":12}`); err == nil || err.Error() != "qty must be a multiple of 5." {
+		t.Fatalf("expected step violation, got %v", err)
+	}
+}
+
+func TestValidateSubstepNumberRangeConfig(t *testing.T) {
+	for name, tc := range map[string]struct {
+		sub  WorkflowSub
+		want string
+	}{
+		"inverted":  {sub: WorkflowSub{InputKey: "v", Min: ptrFloat(5), Max: ptrFloat(1)}, want: "min 5 is greater than max 1"},
+		"zero step": {sub: WorkflowSub{InputKey: "v", Step: ptrFloat(0)}, want: "step must be greater than 0"},
+		"no key":    {sub: WorkflowSub{Min: ptrFloat(0)}, want: "require inputKey"},
+	} {
+		err := validateSubstepNumberRangeConfig(tc.sub)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%s: error = %v, want %q", name, err, tc.want)
+		}
+	}
+	if err := validateSubstepNumberRangeConfig(WorkflowSub{InputKey: "v", Min: ptrFloat(1), Max: ptrFloat(1)}); err != nil {
+		t.Fatalf("expected equal bounds to be valid, got %v", err)
+	}
+}
+
+func TestSchemaWithNumberRange(t *testing.T) {
+	sub := WorkflowSub{
+		InputKey: "value",
+		Min:      ptrFloat(0),
+		Max:      ptrFloat(100),
+		Step:     ptrFloat(5),
+		Schema: map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"value": map[string]interface{}{"type": "number"}},
+		},
+	}
+	got := marshalJSONCompact(schemaWithNumberRange(sub))
+	var decoded struct {
+		Properties map[string]map[string]interface{} `json:"properties"`
+	}
+	if err := json.Unmarshal([]byte(got), &decoded); err != nil {
+		t.Fatalf("decode schema: %v", err)
+	}
+	value := decoded.Properties["value"]
+	if value["minimum"] != float64(0) || value["maximum"] != float64(100) || value["multipleOf"] != float64(5) {
+		t.Fatalf("unexpected value property %#v", value)
+	}
+	if _, ok := sub.Schema["properties"].(map[string]interface{})["value"].(map[string]interface{})["minimum"]; ok {
+		t.Fatal("expected canonical schema to stay untouched")
+	}
+}
@@ -36,14 +36,17 @@ func TestOrgAdminTemplateRoleUsageStates(t *testing.T) {
 	if !strings.Contains(body, `data-role-palette="blue"`) || !strings.Contains(body, `data-role-palette="emerald"`) {
 		t.Fatalf("expected role palette attributes in output, got: %s", body)
 	}
-	if !strings.Contains(compactBody, `aria-label="Edit role"`) || !strings.Contains(compactBody, `title="Role in use"`) || !strings.Contains(compactBody, `disabled`) {
-		t.Fatalf("expected in-use edit button to be disabled, got: %s", body)
-	}
-	if !strings.Contains(compactBody, `aria-label="Delete role"`) || !strings.Contains(compactBody, `title="Role in use"`) || !strings.Contains(compactBody, `disabled`) {
+	if !strings.Contains(compactBody, `aria-label="Delete role" title="Role in use" disabled`) {
 		t.Fatalf("expected in-use delete button to be disabled, got: %s", body)
 	}
-	if strings.Contains(body, `id="edit-role-approver"`) || strings.Contains(body, `id="delete-role-approver"`) {
-		t.Fatalf("did not expect dialogs for in-use role, got: %s", body)
+	if strings.Contains(compactBody, `aria-label="Edit role" title="Role in use" disabled`) {
+		t.Fatalf("expected in-use roles to stay editable, got: %s", body)
+	}
+	if !strings.Contains(body, `id="edit-role-approver"`) || !strings.Contains(body, `action="/my/organization/roles/approver/rename"`) {
+		t.Fatalf("expected in-use role to be renamed in place, got: %s", body)
+	}
+	if strings.Contains(body, `id="delete-role-approver"`) {
+		t.Fatalf("did not expect a delete dialog for in-use role, got: %s", body)
 	}
 	if !strings.Contains(compactBody, `Not used`) {
 		t.Fatalf("expected unused role helper text, got: %s", body)
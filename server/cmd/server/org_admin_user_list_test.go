@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPageOrgAdminUserRows(t *testing.T) {
+	users := make([]OrgAdminUserRow, 0, 45)
+	for idx := 1; idx <= 45; idx++ {
+		domain := "example.com"
+		if idx%3 == 0 {
+			domain = "Partner.test"
+		}
+		users = append(users, OrgAdminUserRow{UserID: fmt.Sprintf("user-%d", idx), Email: fmt.Sprintf("user%d@%s", idx, domain)})
+	}
+
+	page, list := pageOrgAdminUserRows(users, "", 3)
+	if len(page) != 5 || page[0].UserID != "user-41" || list.TotalPages != 3 || list.MatchedCount != 45 || list.HasNextPage {
+		t.Fatalf("unexpected last page %d rows, %#v", len(page), list)
+	}
+	if list.PreviousURL != "/my/organization/members?page=2" || list.PageLinks[0].URL != "/my/organization/members" {
+		t.Fatalf("unexpected page urls %#v", list)
+	}
+
+	page, list = pageOrgAdminUserRows(users, " partner ", 9)
+	if len(page) != 15 || list.Query != "partner" || list.CurrentPage != 1 || list.TotalPages != 1 {
+		t.Fatalf("unexpected filtered page %d rows, %#v", len(page), list)
+	}
+	for _, row := range page {
+		if !strings.Contains(row.Email, "Partner") {
+			t.Fatalf("unexpected match %q", row.Email)
+		}
+	}
+
+	page, list = pageOrgAdminUserRows(users, "nobody", 1)
+	if len(page) != 0 || list.MatchedCount != 0 || list.TotalPages != 1 {
+		t.Fatalf("expected no matches, got %d rows, %#v", len(page), list)
+	}
+}
+
+func TestRenderOrgAdminPagesUsersFromRequest(t *testing.T) {
+	orgUsers := make([]IdentityUser, 0, orgAdminUsersPerPage+5)
+	for idx := 1; idx <= orgAdminUsersPerPage+5; idx++ {
+		orgUsers = append(orgUsers, IdentityUser{ID: fmt.Sprintf("user-%d", idx), Email: fmt.Sprintf("member%d@example.com", idx), OrgSlug: "acme", Status: "active"})
+	}
+	server := &Server{
+		tmpl: testTemplates(),
+		identity: &fakeIdentityStore{
+			getOrganizationBySlugFunc: func(ctx context.Context, slug string) (*IdentityOrg, error) {
+				return &IdentityOrg{ID: "team-1", Slug: "acme", Name: "Acme"}, nil
+			},
+			listOrganizationUsersFunc: func(ctx context.Context, orgSlug string) ([]IdentityUser, error) {
+				return orgUsers, nil
+			},
+		},
+	}
+	adminOrgID := stableOrgObjectID("acme")
+	admin := &AccountUser{IdentityUserID: "owner", Email: "owner@example.com", OrgID: &adminOrgID, OrgSlug: "acme", RoleSlugs: []string{"org-admin"}, Status: "active"}
+
+	for _, tc := range []struct {
+		target string
+		want   string
+	}{
+		{target: "/my/organization/members", want: fmt.Sprintf("USERS %d ", orgAdminUsersPerPage)},
+		{target: "/my/organization/members?page=2", want: "USERS 5 "},
+		{target: "/my/organization/members?q=member2", want: "USERS 7 "},
+	} {
+		rec := httptest.NewRecorder()
+		server.renderOrgAdminWithErrors(rec, httptest.NewRequest(http.MethodGet, tc.target, nil), admin, "acme", "", OrgAdminErrors{})
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), tc.want) {
+			t.Fatalf("%s: status = %d, want %q in %q", tc.target, rec.Code, tc.want, rec.Body.String())
+		}
+	}
+}
+
+func TestOrgAdminTemplateKeepsUserListStateInForms(t *testing.T) {
+	tmpl := parseTestTemplates(t)
+	users := []OrgAdminUserRow{{UserID: "user-1", Email: "member@example.com", Activated: true}}
+	_, list := pageOrgAdminUserRows(append(users, make([]OrgAdminUserRow, orgAdminUsersPerPage)...), "", 2)
+	list.Query = "member"
+
+	var out bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&out, "org_admin_body", OrgAdminView{
+		Organization: Organization{Name: "Acme", Slug: "acme"},
+		ActivePanel:  "members",
+		Users:        users,
+		UserList:     list,
+	}); err != nil {
+		t.Fatalf("render org admin template: %v", err)
+	}
+	body := out.String()
+	for _, marker := range []string{
+		`id="org-admin-user-search-input"`,
+		`name="q"
+                            value="member"`,
+		`name="page"
+                            value="2"`,
+		`aria-label="Users pagination"`,
+	} {
+		if !strings.Contains(body, marker) {
+			t.Fatalf("expected marker %q in output, got: %s", marker, body)
+		}
+	}
+}
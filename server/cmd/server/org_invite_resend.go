@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// handleOrgAdminInviteResend serves POST /my/organization/invites/resend. The
+// pending or expired invite for `email` is replaced with a fresh one, so the
+// old link stops working and the new one expires a week from now.
+func (s *Server) handleOrgAdminInviteResend(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	admin, ok := s.requireOrgAdmin(w, r)
+	if !ok {
+		return
+	}
+	if !userHasOrganizationContext(admin) {
+		s.renderOrgAdminWithErrors(w, r, admin, "", "", OrgAdminErrors{Organization: "create organization first"})
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		logAndHTTPError(w, r, http.StatusBadRequest, "invalid form", err, "failed to parse org-admin invite resend form")
+		return
+	}
+	if s.identity == nil {
+		http.Error(w, "identity unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	email := strings.ToLower(strings.TrimSpace(r.FormValue("email")))
+	if email == "" {
+		s.renderOrgAdminWithErrors(w, r, admin, admin.OrgSlug, "", OrgAdminErrors{Invite: "email is required"})
+		return
+	}
+	org, err := s.identity.GetOrganizationBySlug(r.Context(), admin.OrgSlug)
+	if err != nil || org == nil {
+		if err != nil {
+			logRequestError(r, err, "failed to load organization %s for invite resend", admin.OrgSlug)
+		}
+		http.NotFound(w, r)
+		return
+	}
+	memberships, err := s.identity.ListOrganizationMemberships(r.Context(), admin.OrgSlug)
+	if err != nil {
+		s.logAndRenderOrgAdminError(w, r, admin, admin.OrgSlug, "", OrgAdminErrors{Invite: "failed to resend invite"}, err, "failed to list memberships for organization %s during invite resend", admin.OrgSlug)
+		return
+	}
+	var invite *IdentityMembership
+	for idx := range memberships {
+		if strings.EqualFold(strings.TrimSpace(memberships[idx].Email), email) {
+			invite = &memberships[idx]
+			break
+		}
+	}
+	if invite == nil {
+		s.renderOrgAdminWithErrors(w, r, admin, admin.OrgSlug, "", OrgAdminErrors{Invite: "invite not found"})
+		return
+	}
+	if invite.Confirmed {
+		s.renderOrgAdminWithErrors(w, r, admin, admin.OrgSlug, "", OrgAdminErrors{Invite: "invite already accepted"})
+		return
+	}
+	if err := checkInviteEmailDomain(*org, email); err != nil {
+		s.renderOrgAdminWithErrors(w, r, admin, admin.OrgSlug, "", OrgAdminErrors{Invite: err.Error()})
+		return
+	}
+	sessionSecret, err := sessionSecretFromRequest(r)
+	if err != nil {
+		logAndHTTPError(w, r, http.StatusUnauthorized, "unauthorized", err, "failed to read session secret for invite resend in %s", admin.OrgSlug)
+		return
+	}
+	if _, err := s.identity.ResendOrganizationInvite(r.Context(), sessionSecret, admin.OrgSlug, invite.ID, s.inviteRedirectURL(r)); err != nil {
+		switch {
+		case errors.Is(err, ErrIdentityInviteAccepted):
+			s.renderOrgAdminWithErrors(w, r, admin, admin.OrgSlug, "", OrgAdminErrors{Invite: "invite already accepted"})
+		case errors.Is(err, ErrIdentityNotFound):
+			s.renderOrgAdminWithErrors(w, r, admin, admin.OrgSlug, "", OrgAdminErrors{Invite: "invite not found"})
+		default:
+			s.logAndRenderOrgAdminError(w, r, admin, admin.OrgSlug, "", OrgAdminErrors{Invite: "failed to resend invite"}, err, "failed to resend invite for %s in organization %s", email, admin.OrgSlug)
+		}
+		return
+	}
+	http.Redirect(w, r, organizationPath("members"), http.StatusSeeOther)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandleOrgAdminInviteResend(t *testing.T) {
+	now := time.Date(2026, 2, 1, 10, 0, 0, 0, time.UTC)
+	memberships := []IdentityMembership{
+		{ID: "membership-pending", Email: "late@example.com", RoleSlugs: []string{"approver"}, InvitedAt: now.Add(-10 * 24 * time.Hour)},
+		{ID: "membership-accepted", Email: "joined@example.com", Confirmed: true, InvitedAt: now.Add(-2 * 24 * time.Hour)},
+	}
+	var resent []string
+	var resentRedirect string
+	server := &Server{
+		authorizer: fakeAuthorizer{},
+		store:      NewMemoryStore(),
+		identity: &fakeIdentityStore{
+			getSessionFunc: func(ctx context.Context, sessionSecret string) (IdentitySession, error) {
+				return fakeIdentitySession(sessionSecret, "user-1", now.Add(time.Hour)), nil
+			},
+			getCurrentUserFunc: func(ctx context.Context, sessionSecret string) (IdentityUser, error) {
+				return IdentityUser{ID: "user-1", Email: "owner@example.com", OrgSlug: "acme", Labels: []string{identityOrgAdminLabel}, IsOrgAdmin: true, Status: "active"}, nil
+			},
+			getOrganizationBySlugFunc: func(ctx context.Context, slug string) (*IdentityOrg, error) {
+				return &IdentityOrg{ID: "team-1", Slug: "acme", Name: "Acme Org", Roles: []IdentityRole{{Slug: "approver", Name: "Approver"}}}, nil
+			},
+			listOrganizationMembershipsFunc: func(ctx context.Context, orgSlug string) ([]IdentityMembership, error) {
+				return memberships, nil
+			},
+			resendOrganizationInviteFunc: func(ctx context.Context, sessionSecret, orgSlug, membershipID, redirectURL string) (IdentityMembership, error) {
+				resent = append(resent, membershipID)
+				resentRedirect = redirectURL
+				return IdentityMembership{ID: "membership-new", Email: "late@example.com", InvitedAt: now}, nil
+			},
+			listOrganizationUsersFunc: func(ctx context.Context, orgSlug string) ([]IdentityUser, error) { return nil, nil },
+		},
+		tmpl:        testTemplates(),
+		enforceAuth: true,
+		now:         func() time.Time { return now },
+	}
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/organization/invites/resend", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(&http.Cookie{Name: "attesta_session", Value: "session-1"})
+		rec := httptest.NewRecorder()
+		server.handleOrganizationRoutes(rec, req)
+		return rec
+	}
+
+	rec := post("email=Late%40Example.com")
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != organizationPath("members") {
+		t.Fatalf("status = %d location = %q body = %q", rec.Code, rec.Header().Get("Location"), rec.Body.String())
+	}
+	if len(resent) != 1 || resent[0] != "membership-pending" || !strings.Contains(resentRedirect, "/invite/accept") {
+		t.Fatalf("resend calls = %#v redirect = %q", resent, resentRedirect)
+	}
+
+	for body, want := range map[string]string{
+		"email=joined%40example.com": "invite already accepted",
+		"email=ghost%40example.com":  "invite not found",
+		"email=":                     "email is required",
+	} {
+		rec = post(body)
+		if !strings.Contains(rec.Body.String(), want) {
+			t.Fatalf("%s: expected %q, got %d %q", body, want, rec.Code, rec.Body.String())
+		}
+	}
+	if len(resent) != 1 {
+		t.Fatalf("expected no further resend calls, got %#v", resent)
+	}
+}
+
+func TestOrgAdminTemplateOffersResendForPendingInvites(t *testing.T) {
+	tmpl := parseTestTemplates(t)
+	view := OrgAdminView{
+		Organization: Organization{Name: "Acme Org", Slug: "acme"},
+		Users: []OrgAdminUserRow{
+			{UserID: "user-1", Email: "member@example.com", Activated: true},
+			{UserID: "user-2", Email: "late@example.com"},
+		},
+	}
+	var out bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&out, "org_admin_body", view); err != nil {
+		t.Fatalf("render org_admin_body: %v", err)
+	}
+	body := out.String()
+	if strings.Count(body, `action="/my/organization/invites/resend"`) != 1 || !strings.Contains(body, `name="email" value="late@example.com"`) {
+		t.Fatalf("expected one resend form for the pending invite, got:\n%s", body)
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+type RoleDeleteWorkflow struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+// RoleDeleteConflict is the 409 body of a refused role delete: who still
+// holds the role and which loaded workflows reference it.
+type RoleDeleteConflict struct {
+	Error     string               `json:"error"`
+	Role      string               `json:"role"`
+	Users     []string             `json:"users"`
+	Workflows []RoleDeleteWorkflow `json:"workflows"`
+}
+
+// removeIdentityRole returns roles without the one matching roleSlug; ok is
+// false when no role has that slug.
+func removeIdentityRole(roles []IdentityRole, roleSlug string) ([]IdentityRole, bool) {
+	updated := make([]IdentityRole, 0, len(roles))
+	found := false
+	for _, role := range roles {
+		if containsRole([]string{role.Slug}, roleSlug) {
+			found = true
+			continue
+		}
+		updated = append(updated, role)
+	}
+	return updated, found
+}
+
+// roleHolderEmails lists the org users and invites that carry roleSlug,
+// sorted and without duplicates.
+func roleHolderEmails(roleSlug string, users []IdentityUser, memberships []IdentityMembership) []string {
+	seen := map[string]bool{}
+	emails := []string{}
+	add := func(email string) {
+		email = strings.TrimSpace(email)
+		key := strings.ToLower(email)
+		if email == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		emails = append(emails, email)
+	}
+	for _, user := range users {
+		if isPlatformAdminIdentityUser(user) {
+			continue
+		}
+		if containsRole(decodeIdentityRoleLabels(user.Labels), roleSlug) {
+			add(user.Email)
+		}
+	}
+	for _, membership := range memberships {
+		if containsRole(membership.RoleSlugs, roleSlug) {
+			add(membership.Email)
+		}
+	}
+	sort.Strings(emails)
+	return emails
+}
+
+// workflowReferencesRole reports whether cfg declares roleSlug for orgSlug or
+// uses it to start processes, complete substeps or reject them. Substeps are
+// only checked in steps of orgSlug, or in steps without an organization.
+func workflowReferencesRole(cfg RuntimeConfig, orgSlug, roleSlug string) bool {
+	for _, role := range cfg.Roles {
+		if canonifySlug(role.OrgSlug) == canonifySlug(orgSlug) && containsRole([]string{role.Slug}, roleSlug) {
+			return true
+		}
+	}
+	if containsRole(cfg.Workflow.StartRoles, roleSlug) {
+		return true
+	}
+	for _, step := range cfg.Workflow.Steps {
+		if stepOrg := canonifySlug(step.OrganizationSlug); stepOrg != "" && stepOrg != canonifySlug(orgSlug) {
+			continue
+		}
+		for _, sub := range step.Substep {
+			if containsRole(substepRoles(sub), roleSlug) || containsRole(sub.RejectRoles, roleSlug) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// roleReferencingWorkflows lists the catalog workflows that reference
+// roleSlug, ordered by key.
+func roleReferencingWorkflows(catalog map[string]RuntimeConfig, orgSlug, roleSlug string) []RoleDeleteWorkflow {
+	workflows := []RoleDeleteWorkflow{}
+	for key, cfg := range catalog {
+		if workflowReferencesRole(cfg, orgSlug, roleSlug) {
+			workflows = append(workflows, RoleDeleteWorkflow{Key: key, Name: cfg.Workflow.Name})
+		}
+	}
+	sort.Slice(workflows, func(i, j int) bool { return workflows[i].Key < workflows[j].Key })
+	return workflows
+}
+
+// Message is the flash shown in the org-admin delete dialog for conflict.
+func (conflict RoleDeleteConflict) Message() string {
+	parts := []string{}
+	if len(conflict.Users) > 0 {
+		parts = append(parts, "held by "+strings.Join(conflict.Users, ", "))
+	}
+	if len(conflict.Workflows) > 0 {
+		names := make([]string, 0, len(conflict.Workflows))
+		for _, workflow := range conflict.Workflows {
+			names = append(names, firstNonEmpty(workflow.Name, workflow.Key))
+		}
+		parts = append(parts, "used by workflow "+strings.Join(names, ", "))
+	}
+	return conflict.Error + ": " + strings.Join(parts, "; ")
+}
+
+// handleOrgAdminRoleDelete serves POST /my/organization/roles/:slug/delete.
+// The role is removed only when no user or invite holds it and no loaded
+// workflow references it; otherwise it answers 409 with the blockers, as
+// JSON for API clients and in the delete dialog of the roles panel for the
+// org-admin page form.
+func (s *Server) handleOrgAdminRoleDelete(w http.ResponseWriter, r *http.Request, roleSlug string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, ok := s.requireOrgAdmin(w, r)
+	if !ok {
+		return
+	}
+	if !userHasOrganizationContext(user) {
+		s.renderOrgAdminWithErrors(w, r, user, "", "", OrgAdminErrors{Organization: "create organization first"})
+		return
+	}
+	if s.identity == nil {
+		http.Error(w, "identity unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	roleSlug = strings.TrimSpace(roleSlug)
+	org, err := s.identity.GetOrganizationBySlug(r.Context(), user.OrgSlug)
+	if err != nil || org == nil {
+		if err != nil {
+			logRequestError(r, err, "failed to load organization %s for role delete", user.OrgSlug)
+		}
+		http.NotFound(w, r)
+		return
+	}
+	if !identityOrgHasRole(*org, roleSlug) || containsRole([]string{roleSlug}, "org-admin") || containsRole([]string{roleSlug}, "org_admin") {
+		http.Error(w, "role not found", http.StatusNotFound)
+		return
+	}
+	orgUsers, err := s.identity.ListOrganizationUsers(r.Context(), user.OrgSlug)
+	if err != nil {
+		logAndHTTPError(w, r, http.StatusInternalServerError, "failed to load organization users", err, "failed to list organization users for role delete in %s", user.OrgSlug)
+		return
+	}
+	memberships, err := s.identity.ListOrganizationMemberships(r.Context(), user.OrgSlug)
+	if err != nil {
+		logAndHTTPError(w, r, http.StatusInternalServerError, "failed to load organization users", err, "failed to list organization memberships for role delete in %s", user.OrgSlug)
+		return
+	}
+	catalog, err := s.workflowCatalog()
+	if err != nil {
+		logAndHTTPError(w, r, http.StatusInternalServerError, "failed to load workflows", err, "failed to load workflow catalog for role delete in %s", user.OrgSlug)
+		return
+	}
+	holders := roleHolderEmails(roleSlug, orgUsers, memberships)
+	workflows := roleReferencingWorkflows(catalog, user.OrgSlug, roleSlug)
+	if len(holders) > 0 || len(workflows) > 0 {
+		conflict := RoleDeleteConflict{
+			Error:     "role is still in use",
+			Role:      roleSlug,
+			Users:     holders,
+			Workflows: workflows,
+		}
+		if !prefersJSONResponse(r) {
+			w.WriteHeader(http.StatusConflict)
+			s.renderOrgAdminWithErrors(w, r, user, user.OrgSlug, "", OrgAdminErrors{Role: conflict.Message(), RoleAction: "delete", RoleSlug: roleSlug})
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		writeJSON(w, conflict)
+		return
+	}
+	sessionSecret, err := sessionSecretFromRequest(r)
+	if err != nil {
+		logAndHTTPError(w, r, http.StatusUnauthorized, "unauthorized", err, "failed to read session secret for org-admin role delete")
+		return
+	}
+	if _, err := s.identity.DeleteRole(r.Context(), sessionSecret, user.OrgSlug, roleSlug); err != nil {
+		if errors.Is(err, ErrIdentityNotFound) {
+			http.Error(w, "role not found", http.StatusNotFound)
+			return
+		}
+		logAndHTTPError(w, r, http.StatusInternalServerError, "failed to delete role", err, "failed to delete role %s from organization %s", roleSlug, user.OrgSlug)
+		return
+	}
+	http.Redirect(w, r, organizationPath("roles"), http.StatusSeeOther)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWorkflowReferencesRoleIsScopedToOrganization(t *testing.T) {
+	cfg := testRuntimeConfig()
+	cfg.Workflow.Steps[0].OrganizationSlug = "org1"
+	cfg.Workflow.Steps[1].OrganizationSlug = "org2"
+	if !workflowReferencesRole(cfg, "org1", "dep1") {
+		t.Fatal("expected dep1 to be referenced in org1")
+	}
+	if workflowReferencesRole(cfg, "org1", "dep2") {
+		t.Fatal("dep2 belongs to a step of org2")
+	}
+	cfg.Workflow.StartRoles = []string{"dep2"}
+	if !workflowReferencesRole(cfg, "org1", "dep2") {
+		t.Fatal("expected start roles to count as a reference")
+	}
+}
+
+func TestHandleOrgAdminRoleDelete(t *testing.T) {
+	now := time.Date(2026, 2, 1, 10, 0, 0, 0, time.UTC)
+	tempDir := t.TempDir()
+	writeWorkflowConfig(t, tempDir+"/workflow.yaml", "Main workflow", "string")
+	org := &IdentityOrg{
+		ID:   "team-1",
+		Slug: "org1",
+		Name: "Org 1",
+		Roles: []IdentityRole{
+			{Slug: "dep1", Name: "Dep 1"},
+			{Slug: "qa", Name: "QA"},
+			{Slug: "spare", Name: "Spare"},
+		},
+	}
+	var deleted []string
+	server := &Server{
+		authorizer: fakeAuthorizer{},
+		store:      NewMemoryStore(),
+		configDir:  tempDir,
+		identity: &fakeIdentityStore{
+			getSessionFunc: func(ctx context.Context, sessionSecret string) (IdentitySession, error) {
+				return fakeIdentitySession(sessionSecret, "user-1", now.Add(time.Hour)), nil
+			},
+			getCurrentUserFunc: func(ctx context.Context, sessionSecret string) (IdentityUser, error) {
+				return IdentityUser{ID: "user-1", Email: "owner@example.com", OrgSlug: "org1", Labels: []string{identityOrgAdminLabel}, IsOrgAdmin: true, Status: "active"}, nil
+			},
+			getOrganizationBySlugFunc: func(ctx context.Context, slug string) (*IdentityOrg, error) {
+				current := *org
+				return &current, nil
+			},
+			listOrganizationUsersFunc: func(ctx context.Context, orgSlug string) ([]IdentityUser, error) {
+				return []IdentityUser{{ID: "user-2", Email: "qa@example.com", Labels: []string{encodeIdentityRoleLabel("qa")}}}, nil
+			},
+			listOrganizationMembershipsFunc: func(ctx context.Context, orgSlug string) ([]IdentityMembership, error) {
+				return []IdentityMembership{{Email: "invitee@example.com", RoleSlugs: []string{"qa"}}}, nil
+			},
+			deleteRoleFunc: func(ctx context.Context, sessionSecret, orgSlug, roleSlug string) (IdentityOrg, error) {
+				deleted = append(deleted, roleSlug)
+				return *org, nil
+			},
+		},
+		tmpl:        testTemplates(),
+		enforceAuth: true,
+		now:         func() time.Time { return now },
+	}
+	post := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.Header.Set("Accept", "application/json")
+		req.AddCookie(&http.Cookie{Name: "attesta_session", Value: "session-1"})
+		rec := httptest.NewRecorder()
+		server.handleOrganizationRoutes(rec, req)
+		return rec
+	}
+
+	rec := post("/organization/roles/qa/delete")
+	var conflict RoleDeleteConflict
+	if rec.Code != http.StatusConflict || json.Unmarshal(rec.Body.Bytes(), &conflict) != nil {
+		t.Fatalf("held role status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	if strings.Join(conflict.Users, ",") != "invitee@example.com,qa@example.com" || len(conflict.Workflows) != 0 {
+		t.Fatalf("unexpected conflict %+v", conflict)
+	}
+
+	rec = post("/organization/roles/dep1/delete")
+	conflict = RoleDeleteConflict{}
+	if rec.Code != http.StatusConflict || json.Unmarshal(rec.Body.Bytes(), &conflict) != nil {
+		t.Fatalf("workflow role status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	if len(conflict.Users) != 0 || len(conflict.Workflows) != 1 || conflict.Workflows[0].Key != "workflow" || conflict.Workflows[0].Name != "Main workflow" {
+		t.Fatalf("unexpected conflict %+v", conflict)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/organization/roles/qa/delete", nil)
+	req.AddCookie(&http.Cookie{Name: "attesta_session", Value: "session-1"})
+	rec = httptest.NewRecorder()
+	server.handleOrganizationRoutes(rec, req)
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "role is still in use: held by invitee@example.com, qa@example.com") {
+		t.Fatalf("form post status = %d body = %s", rec.Code, rec.Body.String())
+	}
+
+	if rec := post("/organization/roles/ghost/delete"); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown role status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if len(deleted) != 0 {
+		t.Fatalf("expected refused deletes to keep roles, got %v", deleted)
+	}
+
+	rec = post("/organization/roles/spare/delete")
+	if rec.Code != http.StatusSeeOther || len(deleted) != 1 || deleted[0] != "spare" {
+		t.Fatalf("unused role status = %d, deleted = %v", rec.Code, deleted)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// renameIdentityRole returns a copy of roles where the role matching
+// role.Slug takes role's name and palette. The slug itself is kept as
+// stored and the legacy color/border fields are cleared in favor of the
+// palette. ok is false when no role has that slug.
+func renameIdentityRole(roles []IdentityRole, role IdentityRole) ([]IdentityRole, bool) {
+	updated := append([]IdentityRole(nil), roles...)
+	found := false
+	for idx := range updated {
+		if !containsRole([]string{updated[idx].Slug}, role.Slug) {
+			continue
+		}
+		found = true
+		updated[idx].Name = strings.TrimSpace(role.Name)
+		updated[idx].Palette = canonifySlug(role.Palette)
+		updated[idx].Color = ""
+		updated[idx].Border = ""
+	}
+	return updated, found
+}
+
+// handleOrgAdminRoleRename serves POST /my/organization/roles/:slug/rename.
+// Unlike intent=set_role it never touches the slug, so it is allowed on
+// roles that are assigned to users or referenced by workflows.
+func (s *Server) handleOrgAdminRoleRename(w http.ResponseWriter, r *http.Request, roleSlug string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, ok := s.requireOrgAdmin(w, r)
+	if !ok {
+		return
+	}
+	if !userHasOrganizationContext(user) {
+		s.renderOrgAdminWithErrors(w, r, user, "", "", OrgAdminErrors{Organization: "create organization first"})
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		logAndHTTPError(w, r, http.StatusBadRequest, "invalid form", err, "failed to parse org-admin role rename form")
+		return
+	}
+	if s.identity == nil {
+		http.Error(w, "identity unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	roleSlug = strings.TrimSpace(roleSlug)
+	name := strings.TrimSpace(r.FormValue("name"))
+	palette := strings.TrimSpace(r.FormValue("palette"))
+	org, err := s.identity.GetOrganizationBySlug(r.Context(), user.OrgSlug)
+	if err != nil || org == nil {
+		if err != nil {
+			logRequestError(r, err, "failed to load organization %s for role rename", user.OrgSlug)
+		}
+		http.NotFound(w, r)
+		return
+	}
+	var current *IdentityRole
+	for idx := range org.Roles {
+		if containsRole([]string{org.Roles[idx].Slug}, roleSlug) {
+			current = &org.Roles[idx]
+			break
+		}
+	}
+	if current == nil {
+		s.renderOrgAdminWithErrors(w, r, user, user.OrgSlug, "", OrgAdminErrors{Role: "role not found", RoleAction: "edit", RoleSlug: roleSlug, RoleName: name, RolePalette: palette})
+		return
+	}
+	if name == "" {
+		s.renderOrgAdminWithErrors(w, r, user, user.OrgSlug, "", OrgAdminErrors{Role: "role name is required", RoleAction: "edit", RoleSlug: current.Slug, RolePalette: palette})
+		return
+	}
+	if palette == "" {
+		palette = resolveRolePalette(*current)
+	}
+	sessionSecret, err := sessionSecretFromRequest(r)
+	if err != nil {
+		logAndHTTPError(w, r, http.StatusUnauthorized, "unauthorized", err, "failed to read session secret for org-admin role rename")
+		return
+	}
+	if _, err := s.identity.UpdateRole(r.Context(), sessionSecret, user.OrgSlug, IdentityRole{Slug: current.Slug, Name: name, Palette: palette}); err != nil {
+		if errors.Is(err, ErrIdentityNotFound) {
+			s.renderOrgAdminWithErrors(w, r, user, user.OrgSlug, "", OrgAdminErrors{Role: "role not found", RoleAction: "edit", RoleSlug: current.Slug, RoleName: name, RolePalette: palette})
+			return
+		}
+		s.logAndRenderOrgAdminError(w, r, user, user.OrgSlug, "", OrgAdminErrors{Role: "failed to rename role", RoleAction: "edit", RoleSlug: current.Slug, RoleName: name, RolePalette: palette}, err, "failed to rename role %s in organization %s", current.Slug, user.OrgSlug)
+		return
+	}
+	http.Redirect(w, r, organizationPath("roles"), http.StatusSeeOther)
+}
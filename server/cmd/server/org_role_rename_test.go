@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenameIdentityRoleKeepsSlug(t *testing.T) {
+	roles := []IdentityRole{
+		{Slug: "qa-reviewer", Name: "QA Reviewer", Color: "var(--role-blue-bg)", Border: "var(--role-blue-border)"},
+		{Slug: "approver", Name: "Approver", Palette: "green"},
+	}
+	updated, ok := renameIdentityRole(roles, IdentityRole{Slug: "qa-reviewer", Name: " Lead Reviewer ", Palette: "Amber"})
+	if !ok {
+		t.Fatal("expected role to be found")
+	}
+	if got := updated[0]; got.Slug != "qa-reviewer" || got.Name != "Lead Reviewer" || got.Palette != "amber" || got.Color != "" || got.Border != "" {
+		t.Fatalf("renamed role = %#v", got)
+	}
+	if roles[0].Name != "QA Reviewer" || updated[1] != roles[1] {
+		t.Fatalf("expected input untouched and other roles kept, got %#v / %#v", roles, updated)
+	}
+	if _, ok := renameIdentityRole(roles, IdentityRole{Slug: "ghost", Name: "Ghost"}); ok {
+		t.Fatal("expected unknown slug to be reported")
+	}
+}
+
+func TestHandleOrgAdminRoleRename(t *testing.T) {
+	now := time.Date(2026, 2, 1, 10, 0, 0, 0, time.UTC)
+	org := &IdentityOrg{
+		ID:    "team-1",
+		Slug:  "acme",
+		Name:  "Acme Org",
+		Roles: []IdentityRole{{Slug: "qa-reviewer", Name: "QA Reviewer", Palette: "blue"}},
+	}
+	var renamed []IdentityRole
+	server := &Server{
+		authorizer: fakeAuthorizer{},
+		store:      NewMemoryStore(),
+		identity: &fakeIdentityStore{
+			getSessionFunc: func(ctx context.Context, sessionSecret string) (IdentitySession, error) {
+				return fakeIdentitySession(sessionSecret, "user-1", now.Add(time.Hour)), nil
+			},
+			getCurrentUserFunc: func(ctx context.Context, sessionSecret string) (IdentityUser, error) {
+				return IdentityUser{ID: "user-1", Email: "owner@example.com", OrgSlug: "acme", Labels: []string{identityOrgAdminLabel}, IsOrgAdmin: true, Status: "active"}, nil
+			},
+			getOrganizationBySlugFunc: func(ctx context.Context, slug string) (*IdentityOrg, error) {
+				current := *org
+				return &current, nil
+			},
+			updateRoleFunc: func(ctx context.Context, sessionSecret, orgSlug string, role IdentityRole) (IdentityOrg, error) {
+				renamed = append(renamed, role)
+				return *org, nil
+			},
+			listOrganizationUsersFunc:       func(ctx context.Context, orgSlug string) ([]IdentityUser, error) { return nil, nil },
+			listOrganizationMembershipsFunc: func(ctx context.Context, orgSlug string) ([]IdentityMembership, error) { return nil, nil },
+		},
+		tmpl:        testTemplates(),
+		enforceAuth: true,
+		now:         func() time.Time { return now },
+	}
+	post := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(&http.Cookie{Name: "attesta_session", Value: "session-1"})
+		rec := httptest.NewRecorder()
+		server.handleOrganizationRoutes(rec, req)
+		return rec
+	}
+
+	rec := post("/organization/roles/qa-reviewer/rename", "name=Lead+Reviewer")
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != organizationPath("roles") {
+		t.Fatalf("status = %d location = %q", rec.Code, rec.Header().Get("Location"))
+	}
+	if len(renamed) != 1 || renamed[0].Slug != "qa-reviewer" || renamed[0].Name != "Lead Reviewer" || renamed[0].Palette != "blue" {
+		t.Fatalf("UpdateRole calls = %#v", renamed)
+	}
+
+	for _, path := range []string{"/organization/roles/ghost/rename", "/organization/roles/qa-reviewer/rename"} {
+		body := "name=Other"
+		if !strings.Contains(path, "ghost") {
+			body = "name=+"
+		}
+		if rec := post(path, body); rec.Code == http.StatusSeeOther {
+			t.Fatalf("%s %q: expected an error, got redirect", path, body)
+		}
+	}
+	if rec := post("/organization/roles/a/b/rename", "name=Other"); rec.Code != http.StatusNotFound {
+		t.Fatalf("nested slug status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if len(renamed) != 1 {
+		t.Fatalf("expected rejected requests not to update, got %#v", renamed)
+	}
+}
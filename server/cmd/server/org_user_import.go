@@ -0,0 +1,279 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	orgUserImportMaxBytes = 1 << 20
+	orgUserImportMaxRows  = 500
+)
+
+const (
+	orgInviteCreated = "created"
+	orgInviteUpdated = "updated"
+	orgInviteSkipped = "skipped"
+)
+
+// orgInviteError describes why an invite row was refused. Message is shown to
+// the org admin; Err and LogMessage are only set for unexpected failures.
+type orgInviteError struct {
+	Message      string
+	Err          error
+	LogMessage   string
+	Unauthorized bool
+}
+
+type OrgUserImportRow struct {
+	Row    int      `json:"row"`
+	Email  string   `json:"email,omitempty"`
+	Roles  []string `json:"roles,omitempty"`
+	Status string   `json:"status"`
+	Error  string   `json:"error,omitempty"`
+}
+
+type OrgUserImportSummary struct {
+	Created int                `json:"created"`
+	Updated int                `json:"updated"`
+	Skipped int                `json:"skipped"`
+	Failed  int                `json:"failed"`
+	Rows    []OrgUserImportRow `json:"rows"`
+}
+
+// inviteOrganizationMember applies one invite to admin's organization. A
+// confirmed member or same-org user gets the new roles, a pending invite is
+// updated (or skipped when the roles already match), and anyone else is
+// invited. Emails that belong to another organization are refused.
+func (s *Server) inviteOrganizationMember(r *http.Request, admin *AccountUser, org *IdentityOrg, email string, selectedRoles []string) (string, *orgInviteError) {
+	allowedRoles := ensureOrgAdminRoleOption(rolesFromIdentityOrg(*org))
+	allowed := make(map[string]struct{}, len(allowedRoles))
+	for _, role := range allowedRoles {
+		allowed[strings.TrimSpace(role.Slug)] = struct{}{}
+	}
+	for _, roleSlug := range selectedRoles {
+		if _, ok := allowed[strings.TrimSpace(roleSlug)]; !ok {
+			return "", &orgInviteError{Message: "role not found"}
+		}
+	}
+	isOrgAdmin := containsRole(selectedRoles, "org-admin")
+	businessRoles := make([]string, 0, len(selectedRoles))
+	for _, roleSlug := range selectedRoles {
+		if containsRole([]string{roleSlug}, "org-admin") || containsRole([]string{roleSlug}, "org_admin") {
+			isOrgAdmin = true
+			continue
+		}
+		businessRoles = append(businessRoles, roleSlug)
+	}
+	labels := make([]string, 0, len(businessRoles)+1)
+	for _, roleSlug := range businessRoles {
+		labels = append(labels, encodeIdentityRoleLabel(roleSlug))
+	}
+	if isOrgAdmin {
+		labels = append(labels, identityOrgAdminLabel)
+	}
+
+	memberships, err := s.identity.ListOrganizationMemberships(r.Context(), admin.OrgSlug)
+	if err != nil {
+		return "", &orgInviteError{Message: "failed to create invite", Err: err, LogMessage: fmt.Sprintf("failed to list memberships for organization %s during invite", admin.OrgSlug)}
+	}
+	for _, membership := range memberships {
+		if !strings.EqualFold(strings.TrimSpace(membership.Email), email) {
+			continue
+		}
+		if membership.Confirmed {
+			if _, err := s.identity.UpdateUserLabels(r.Context(), membership.UserID, labels); err != nil {
+				return "", &orgInviteError{Message: "failed to update user roles", Err: err, LogMessage: fmt.Sprintf("failed to update labels for invited member %s in organization %s", membership.UserID, admin.OrgSlug)}
+			}
+			return orgInviteUpdated, nil
+		}
+		currentRoles := append([]string{}, membership.RoleSlugs...)
+		if membership.IsOrgAdmin {
+			currentRoles = append(currentRoles, "org-admin")
+		}
+		if roleSlugsKey(currentRoles) == roleSlugsKey(selectedRoles) {
+			return orgInviteSkipped, nil
+		}
+		sessionSecret, err := sessionSecretFromRequest(r)
+		if err != nil {
+			return "", &orgInviteError{Unauthorized: true, Err: err, LogMessage: fmt.Sprintf("failed to read session secret for membership update in %s", admin.OrgSlug)}
+		}
+		if _, err := s.identity.UpdateOrganizationMembership(r.Context(), sessionSecret, admin.OrgSlug, membership.ID, businessRoles, isOrgAdmin); err != nil {
+			return "", &orgInviteError{Message: "failed to create invite", Err: err, LogMessage: fmt.Sprintf("failed to update membership %s in organization %s", membership.ID, admin.OrgSlug)}
+		}
+		return orgInviteUpdated, nil
+	}
+	existingUser, err := s.identity.GetUserByEmail(r.Context(), email)
+	switch {
+	case err == nil && existingUser.OrgSlug != "" && !strings.EqualFold(strings.TrimSpace(existingUser.OrgSlug), strings.TrimSpace(admin.OrgSlug)):
+		return "", &orgInviteError{Message: "email already belongs to another organization"}
+	case err == nil && strings.EqualFold(strings.TrimSpace(existingUser.OrgSlug), strings.TrimSpace(admin.OrgSlug)):
+		if _, err := s.identity.UpdateUserLabels(r.Context(), existingUser.ID, labels); err != nil {
+			return "", &orgInviteError{Message: "failed to update user roles", Err: err, LogMessage: fmt.Sprintf("failed to update labels for existing user %s in organization %s", existingUser.ID, admin.OrgSlug)}
+		}
+		return orgInviteUpdated, nil
+	case err != nil && !errors.Is(err, ErrIdentityNotFound):
+		return "", &orgInviteError{Message: "failed to load existing user", Err: err, LogMessage: fmt.Sprintf("failed to look up existing user %s during invite", email)}
+	}
+	if err := checkInviteEmailDomain(*org, email); err != nil {
+		return "", &orgInviteError{Message: err.Error()}
+	}
+	sessionSecret, err := sessionSecretFromRequest(r)
+	if err != nil {
+		return "", &orgInviteError{Unauthorized: true, Err: err, LogMessage: fmt.Sprintf("failed to read session secret for invite creation in %s", admin.OrgSlug)}
+	}
+	if _, err := s.identity.InviteOrganizationUser(r.Context(), sessionSecret, admin.OrgSlug, email, s.inviteRedirectURL(r), businessRoles, isOrgAdmin); err != nil {
+		return "", &orgInviteError{Message: "failed to create invite", Err: err, LogMessage: fmt.Sprintf("failed to create invite for %s in organization %s", email, admin.OrgSlug)}
+	}
+	return orgInviteCreated, nil
+}
+
+// handleOrgAdminUserImport serves POST /my/organization/users/import. The
+// CSV (multipart field "file") has one email,roles row per user, roles
+// separated by ";". Every row is processed on its own; the JSON summary lists
+// per-row failures instead of rejecting the batch.
+func (s *Server) handleOrgAdminUserImport(w http.ResponseWriter, r *http.Request) {
+	admin, ok := s.requireOrgAdmin(w, r)
+	if !ok {
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.identity == nil {
+		http.Error(w, "identity unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if !userHasOrganizationContext(admin) {
+		http.Error(w, "create organization first", http.StatusBadRequest)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, orgUserImportMaxBytes)
+	if err := r.ParseMultipartForm(orgUserImportMaxBytes); err != nil {
+		if isRequestTooLarge(err) {
+			http.Error(w, "csv file too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "csv file is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	records, err := readOrgUserImportCSV(file)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, err := sessionSecretFromRequest(r); err != nil {
+		logAndHTTPError(w, r, http.StatusUnauthorized, "unauthorized", err, "failed to read session secret for user import in %s", admin.OrgSlug)
+		return
+	}
+	org, err := s.identity.GetOrganizationBySlug(r.Context(), admin.OrgSlug)
+	if err != nil || org == nil {
+		if err != nil {
+			logRequestError(r, err, "failed to load organization %s for user import", admin.OrgSlug)
+		}
+		http.NotFound(w, r)
+		return
+	}
+
+	summary := OrgUserImportSummary{Rows: make([]OrgUserImportRow, 0, len(records))}
+	seen := map[string]int{}
+	for _, record := range records {
+		row := OrgUserImportRow{Row: record.Line, Email: record.Email, Roles: record.Roles}
+		switch {
+		case record.Error != "":
+			row.Status, row.Error = "failed", record.Error
+		case seen[record.Email] != 0:
+			row.Status, row.Error = "failed", fmt.Sprintf("duplicate of row %d", seen[record.Email])
+		default:
+			seen[record.Email] = record.Line
+			outcome, inviteErr := s.inviteOrganizationMember(r, admin, org, record.Email, record.Roles)
+			if inviteErr != nil {
+				if inviteErr.Err != nil {
+					logRequestError(r, inviteErr.Err, "user import row %d: %s", record.Line, inviteErr.LogMessage)
+				}
+				row.Status, row.Error = "failed", inviteErr.Message
+				if inviteErr.Unauthorized {
+					row.Error = "unauthorized"
+				}
+			} else {
+				row.Status = outcome
+			}
+		}
+		switch row.Status {
+		case orgInviteCreated:
+			summary.Created++
+		case orgInviteUpdated:
+			summary.Updated++
+		case orgInviteSkipped:
+			summary.Skipped++
+		default:
+			summary.Failed++
+		}
+		summary.Rows = append(summary.Rows, row)
+	}
+	writeJSON(w, summary)
+}
+
+type orgUserImportRecord struct {
+	Line  int
+	Email string
+	Roles []string
+	Error string
+}
+
+// readOrgUserImportCSV parses the upload. A leading "email,roles" header is
+// optional and blank lines are ignored. Row-level problems are returned on the
+// record so the rest of the batch still runs.
+func readOrgUserImportCSV(content io.Reader) ([]orgUserImportRecord, error) {
+	reader := csv.NewReader(content)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records := []orgUserImportRecord{}
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		if len(fields) == 0 || (len(fields) == 1 && strings.TrimSpace(fields[0]) == "") {
+			continue
+		}
+		email := strings.ToLower(strings.TrimSpace(fields[0]))
+		if len(records) == 0 && email == "email" {
+			continue
+		}
+		if len(records) == orgUserImportMaxRows {
+			return nil, fmt.Errorf("csv has more than %d rows", orgUserImportMaxRows)
+		}
+		record := orgUserImportRecord{Line: line, Email: email}
+		switch {
+		case len(fields) > 2:
+			record.Error = "expected email,roles"
+		case email == "" || !strings.Contains(email, "@"):
+			record.Error = "invalid email"
+		}
+		if len(fields) == 2 {
+			record.Roles = canonifyRoleSlugs(strings.Split(fields[1], ";"))
+		}
+		records = append(records, record)
+	}
+	if len(records) == 0 {
+		return nil, errors.New("csv has no rows")
+	}
+	return records, nil
+}
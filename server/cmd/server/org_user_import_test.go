@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHandleOrgAdminUserImport(t *testing.T) {
+	now := time.Now().UTC()
+	invited := map[string][]string{}
+	updatedUsers := map[string][]string{}
+	server := &Server{
+		authorizer: fakeAuthorizer{},
+		store:      NewMemoryStore(),
+		identity: &fakeIdentityStore{
+			getSessionFunc: func(ctx context.Context, sessionSecret string) (IdentitySession, error) {
+				return fakeIdentitySession(sessionSecret, "user-1", now.Add(time.Hour)), nil
+			},
+			getCurrentUserFunc: func(ctx context.Context, sessionSecret string) (IdentityUser, error) {
+				return IdentityUser{ID: "user-1", Email: "owner@example.com", OrgSlug: "acme", Labels: []string{identityOrgAdminLabel}, IsOrgAdmin: true, Status: "active"}, nil
+			},
+			getOrganizationBySlugFunc: func(ctx context.Context, slug string) (*IdentityOrg, error) {
+				org := IdentityOrg{ID: "team-1", Slug: "acme", Name: "Acme Org", Roles: []IdentityRole{{Slug: "approver", Name: "Approver"}, {Slug: "qa", Name: "QA"}}}
+				return &org, nil
+			},
+			listOrganizationMembershipsFunc: func(ctx context.Context, orgSlug string) ([]IdentityMembership, error) {
+				return []IdentityMembership{{ID: "membership-1", Email: "pending@example.com", RoleSlugs: []string{"approver"}}}, nil
+			},
+			getUserByEmailFunc: func(ctx context.Context, email string) (IdentityUser, error) {
+				switch email {
+				case "member@example.com":
+					return IdentityUser{ID: "user-2", Email: email, OrgSlug: "acme", Status: "active"}, nil
+				case "other@example.com":
+					return IdentityUser{ID: "user-3", Email: email, OrgSlug: "other-org", Status: "active"}, nil
+				default:
+					return IdentityUser{}, ErrIdentityNotFound
+				}
+			},
+			updateUserLabelsFunc: func(ctx context.Context, userID string, labels []string) (IdentityUser, error) {
+				updatedUsers[userID] = append([]string(nil), labels...)
+				return IdentityUser{ID: userID, Labels: labels}, nil
+			},
+			inviteOrganizationUserFunc: func(ctx context.Context, sessionSecret, orgSlug, email, redirectURL string, roleSlugs []string, isOrgAdmin bool) (IdentityMembership, error) {
+				invited[email] = append([]string(nil), roleSlugs...)
+				return IdentityMembership{ID: "membership-" + email, Email: email}, nil
+			},
+		},
+		tmpl:        testTemplates(),
+		enforceAuth: true,
+		now:         func() time.Time { return now },
+	}
+
+	csvBody := "email,roles\n" +
+		"New@Example.com,approver;qa\n" +
+		"member@example.com,qa\n" +
+		"pending@example.com,approver\n" +
+		"other@example.com,approver\n" +
+		"typo@example.com,missing\n" +
+		"not-an-email,approver\n" +
+		"\n" +
+		"new@example.com,qa\n"
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", "users.csv")
+	if err != nil {
+		t.Fatalf("CreateFormFile: %v", err)
+	}
+	_, _ = part.Write([]byte(csvBody))
+	_ = writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/my/organization/users/import", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.AddCookie(&http.Cookie{Name: "attesta_session", Value: "session-1"})
+	rec := httptest.NewRecorder()
+	server.handleOrgAdminUserImport(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	var summary OrgUserImportSummary
+	if err := json.Unmarshal(rec.Body.Bytes(), &summary); err != nil {
+		t.Fatalf("decode summary: %v", err)
+	}
+	if summary.Created != 1 || summary.Updated != 1 || summary.Skipped != 1 || summary.Failed != 4 {
+		t.Fatalf("unexpected summary counts %#v", summary)
+	}
+	gotErrors := map[int]string{}
+	for _, row := range summary.Rows {
+		if row.Error != "" {
+			gotErrors[row.Row] = row.Error
+		}
+	}
+	wantErrors := map[int]string{
+		5: "email already belongs to another organization",
+		6: "role not found",
+		7: "invalid email",
+		9: "duplicate of row 2",
+	}
+	if !reflect.DeepEqual(gotErrors, wantErrors) {
+		t.Fatalf("row errors = %#v, want %#v", gotErrors, wantErrors)
+	}
+	if !reflect.DeepEqual(invited, map[string][]string{"new@example.com": {"approver", "qa"}}) {
+		t.Fatalf("invites = %#v", invited)
+	}
+	if !reflect.DeepEqual(updatedUsers, map[string][]string{"user-2": {encodeIdentityRoleLabel("qa")}}) {
+		t.Fatalf("updated users = %#v", updatedUsers)
+	}
+}
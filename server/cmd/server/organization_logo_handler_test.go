@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -16,7 +17,7 @@ func TestHandleOrganizationLogo(t *testing.T) {
 					if slug != "acme" {
 						t.Fatalf("slug = %q, want acme", slug)
 					}
-					return &IdentityOrg{Slug: "acme", LogoFileID: "logo-1"}, nil
+					return &IdentityOrg{Slug: "acme", LogoFileID: "logo-1", PublicBranding: true}, nil
 				},
 				getOrganizationLogoFunc: func(ctx context.Context, fileID string) (IdentityFile, error) {
 					if fileID != "logo-1" {
@@ -47,6 +48,35 @@ func TestHandleOrganizationLogo(t *testing.T) {
 		}
 	})
 
+	t.Run("anonymous visitors are sent to login unless branding is public", func(t *testing.T) {
+		server := &Server{
+			enforceAuth: true,
+			identity: &fakeIdentityStore{
+				getOrganizationBySlugFunc: func(ctx context.Context, slug string) (*IdentityOrg, error) {
+					if slug == "missing" {
+						return nil, ErrIdentityNotFound
+					}
+					return &IdentityOrg{Slug: slug, LogoFileID: "logo-1"}, nil
+				},
+				getOrganizationLogoFunc: func(ctx context.Context, fileID string) (IdentityFile, error) {
+					t.Fatal("did not expect logo download")
+					return IdentityFile{}, nil
+				},
+			},
+		}
+		for _, slug := range []string{"acme", "missing"} {
+			req := httptest.NewRequest(http.MethodGet, "/organization/logo/"+slug, nil)
+			rec := httptest.NewRecorder()
+			server.handleOrganizationLogo(rec, req)
+			if rec.Code != http.StatusSeeOther {
+				t.Fatalf("%s: status = %d, want %d", slug, rec.Code, http.StatusSeeOther)
+			}
+			if got := rec.Header().Get("Location"); !strings.HasPrefix(got, "/login?next=") {
+				t.Fatalf("%s: location = %q, want login redirect", slug, got)
+			}
+		}
+	})
+
 	t.Run("method not allowed", func(t *testing.T) {
 		server := &Server{}
 		req := httptest.NewRequest(http.MethodPost, "/organization/logo/acme", nil)
@@ -255,6 +255,7 @@ func TestStreamHomeBodyPanelMarkup(t *testing.T) {
 		Sort:          "time_desc",
 		FilterOptions: testHomeFilterOptions(),
 		ProcessGroups: testHomeActiveProcessGroups(nil, "all", "time_desc", 1),
+		CanStart:      true,
 	}
 	if err := tmpl.ExecuteTemplate(&out, "home_body", view); err != nil {
 		t.Fatalf("render home_body: %v", err)
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	formataPayloadDefaultMaxDepth    = 32
+	formataPayloadDefaultMaxElements = 10000
+)
+
+// errPayloadTooComplex rejects a formata payload nested deeper or holding
+// more values than FORMATA_PAYLOAD_MAX_DEPTH / FORMATA_PAYLOAD_MAX_ELEMENTS
+// allow. Handlers answer 422.
+var errPayloadTooComplex = errors.New("payload too complex")
+
+const payloadTooComplexMessage = "Form data is too complex."
+
+// formataPayloadMaxDepth reads FORMATA_PAYLOAD_MAX_DEPTH; values below one
+// fall back to the default.
+func formataPayloadMaxDepth() int {
+	if value := intEnvOr("FORMATA_PAYLOAD_MAX_DEPTH", formataPayloadDefaultMaxDepth); value > 0 {
+		return value
+	}
+	return formataPayloadDefaultMaxDepth
+}
+
+// formataPayloadMaxElements reads FORMATA_PAYLOAD_MAX_ELEMENTS; values below
+// one fall back to the default.
+func formataPayloadMaxElements() int {
+	if value := intEnvOr("FORMATA_PAYLOAD_MAX_ELEMENTS", formataPayloadDefaultMaxElements); value > 0 {
+		return value
+	}
+	return formataPayloadDefaultMaxElements
+}
+
+// formataPayloadBudget counts the values persistFormataAttachments walks so
+// a pathological payload fails before it is stored or its files are saved.
+type formataPayloadBudget struct {
+	maxDepth    int
+	maxElements int
+	elements    int
+}
+
+// newFormataPayloadBudget starts a walk under the limits read at startup.
+func (s *Server) newFormataPayloadBudget() *formataPayloadBudget {
+	budget := &formataPayloadBudget{maxDepth: s.formataMaxDepth, maxElements: s.formataMaxElements}
+	if budget.maxDepth <= 0 {
+		budget.maxDepth = formataPayloadDefaultMaxDepth
+	}
+	if budget.maxElements <= 0 {
+		budget.maxElements = formataPayloadDefaultMaxElements
+	}
+	return budget
+}
+
+// visit accounts for one value found inside depth maps or arrays.
+func (b *formataPayloadBudget) visit(depth int) error {
+	b.elements++
+	if depth > b.maxDepth {
+		return fmt.Errorf("%w: nested deeper than %d levels", errPayloadTooComplex, b.maxDepth)
+	}
+	if b.elements > b.maxElements {
+		return fmt.Errorf("%w: more than %d values", errPayloadTooComplex, b.maxElements)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func nestedPayload(depth int) map[string]interface{} {
+	payload := map[string]interface{}{"leaf": "x"}
+	for i := 0; i < depth; i++ {
+		payload = map[string]interface{}{"next": payload}
+	}
+	return payload
+}
+
+func TestPersistFormataAttachmentsLimitsDepthAndSize(t *testing.T) {
+	server := &Server{formataMaxDepth: 3, formataMaxElements: 5}
+	persist := func(raw interface{}) error {
+		_, err := server.persistFormataAttachments(context.Background(), primitive.NewObjectID(), WorkflowSub{SubstepID: "1.1"}, raw, time.Now(), nil)
+		return err
+	}
+
+	if err := persist(nestedPayload(2)); err != nil {
+		t.Fatalf("payload within limits: %v", err)
+	}
+	if err := persist(nestedPayload(3)); !errors.Is(err, errPayloadTooComplex) {
+		t.Fatalf("deep payload: expected errPayloadTooComplex, got %v", err)
+	}
+	if err := persist(map[string]interface{}{"items": []interface{}{1, 2, 3}}); err != nil {
+		t.Fatalf("five values: %v", err)
+	}
+	if err := persist(map[string]interface{}{"items": []interface{}{1, 2, 3, 4}}); !errors.Is(err, errPayloadTooComplex) {
+		t.Fatalf("six values: expected errPayloadTooComplex, got %v", err)
+	}
+
+	t.Setenv("FORMATA_PAYLOAD_MAX_DEPTH", "0")
+	if got := formataPayloadMaxDepth(); got != formataPayloadDefaultMaxDepth {
+		t.Fatalf("FORMATA_PAYLOAD_MAX_DEPTH=0 gives %d, want default", got)
+	}
+	t.Setenv("FORMATA_PAYLOAD_MAX_ELEMENTS", "7")
+	if got := formataPayloadMaxElements(); got != 7 {
+		t.Fatalf("FORMATA_PAYLOAD_MAX_ELEMENTS=7 gives %d", got)
+	}
+	if budget := (&Server{}).newFormataPayloadBudget(); budget.maxDepth != formataPayloadDefaultMaxDepth || budget.maxElements != formataPayloadDefaultMaxElements {
+		t.Fatalf("unset limits = %+v, want defaults", budget)
+	}
+}
+
+func TestHandleCompleteSubstepRejectsTooComplexPayload(t *testing.T) {
+	store := NewMemoryStore()
+	server, processID, _ := newServerForCompleteTests(t, store, fakeAuthorizer{})
+	server.formataMaxDepth = 4
+	value := `{"value":` + strings.Repeat(`{"a":`, 6) + `1` + strings.Repeat(`}`, 6) + `}`
+	req := httptest.NewRequest(http.MethodPost, "/process/"+processID+"/substep/1.1/complete", strings.NewReader(url.Values{"value": {value}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	server.handleCompleteSubstep(rec, req, processID, "1.1")
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+	id, _ := primitive.ObjectIDFromHex(processID)
+	if process, _ := store.SnapshotProcess(id); normalizeProgressKeys(process.Progress)["1.1"].State == "done" {
+		t.Fatal("too complex payload was stored")
+	}
+}
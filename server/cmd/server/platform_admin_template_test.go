@@ -49,6 +49,9 @@ func TestPlatformAdminTemplateOrganizationInviteAndPagination(t *testing.T) {
 	if !strings.Contains(body, `name="invite_email"`) || !strings.Contains(body, "Invite the first org admin now, or add more org admins later") {
 		t.Fatalf("expected optional create invite field and helper text, got: %s", body)
 	}
+	if !strings.Contains(body, `name="allowed_email_domains"`) {
+		t.Fatalf("expected optional create email domains field, got: %s", body)
+	}
 	if !strings.Contains(body, "At least one org admin accepted") || !strings.Contains(body, "All org admin invites pending") || !strings.Contains(body, "No org admin") {
 		t.Fatalf("expected organization status labels, got: %s", body)
 	}
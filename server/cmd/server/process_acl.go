@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	workflowViewAccessOpen       = "open"
+	workflowViewAccessRestricted = "restricted"
+)
+
+// normalizeWorkflowViewAccess lower-cases workflow.viewAccess and rejects
+// values other than open and restricted.
+func normalizeWorkflowViewAccess(workflow *WorkflowDef) error {
+	workflow.ViewAccess = strings.ToLower(strings.TrimSpace(workflow.ViewAccess))
+	switch workflow.ViewAccess {
+	case "", workflowViewAccessOpen, workflowViewAccessRestricted:
+		return nil
+	default:
+		return fmt.Errorf("invalid viewAccess %q: use %q or %q", workflow.ViewAccess, workflowViewAccessOpen, workflowViewAccessRestricted)
+	}
+}
+
+// canViewProcess reports whether user may read process in a restricted
+// workflow: platform admins, the process creator and participants, and
+// members holding one of the workflow's roles in their organization.
+func canViewProcess(user *AccountUser, cfg RuntimeConfig, process *Process) bool {
+	if user == nil || process == nil {
+		return false
+	}
+	if user.IsPlatformAdmin {
+		return true
+	}
+	actorID := accountActorID(user)
+	if strings.TrimSpace(process.CreatedBy) == actorID || containsRole(process.Participants, actorID) {
+		return true
+	}
+	orgSlug := strings.TrimSpace(user.OrgSlug)
+	for _, role := range cfg.Roles {
+		if strings.TrimSpace(role.OrgSlug) == orgSlug && containsRole(user.RoleSlugs, role.Slug) {
+			return true
+		}
+	}
+	return false
+}
+
+// isProcessViewRoute reports whether the /instance/:id/... route in parts
+// only reads the process: its page, partials, downloads and exports.
+func isProcessViewRoute(parts []string, method string) bool {
+	if method != http.MethodGet {
+		return false
+	}
+	switch len(parts) {
+	case 1:
+		return true
+	case 2:
+		switch parts[1] {
+		case "files.zip", "notarized.json", "notarized.json.sig", "bundle.zip", "events.json", "merkle.json", "availability.json", "attachments.json", "content", "downloads":
+			return true
+		}
+	case 3:
+		return (parts[1] == "merkle" && parts[2] == "root") || (parts[1] == "dpp" && parts[2] == "preview.json") || parts[1] == "blob"
+	case 4:
+		return (parts[1] == "substep" && parts[3] == "notarization.json") || (parts[1] == "attachment" && parts[3] == "file")
+	}
+	return false
+}
+
+// allowProcessView answers 403 and returns false when the selected workflow
+// is restricted and the signed-in user may not view processID. Anything it
+// cannot resolve (no session, unknown process) is left to the route's
+// handler, which authenticates and answers 404 itself.
+func (s *Server) allowProcessView(w http.ResponseWriter, r *http.Request, processID string) bool {
+	if !s.enforceAuth {
+		return true
+	}
+	workflowKey, cfg, err := s.selectedWorkflowUnvalidated(r)
+	if err != nil || cfg.Workflow.ViewAccess != workflowViewAccessRestricted {
+		return true
+	}
+	user, _, err := s.currentUser(r)
+	if err != nil {
+		return true
+	}
+	process, err := s.loadProcess(r.Context(), processID)
+	if err != nil || !s.processBelongsToWorkflow(process, workflowKey) {
+		return true
+	}
+	if canViewProcess(user, cfg, process) {
+		return true
+	}
+	http.Error(w, "forbidden", http.StatusForbidden)
+	return false
+}
+
+// processVisible reports whether user may see process at all: always, unless
+// auth is enforced and cfg restricts viewing.
+func (s *Server) processVisible(user *AccountUser, cfg RuntimeConfig, process *Process) bool {
+	if !s.enforceAuth || cfg.Workflow.ViewAccess != workflowViewAccessRestricted {
+		return true
+	}
+	return canViewProcess(user, cfg, process)
+}
+
+// visibleProcesses drops the processes of a restricted workflow that user
+// may not view, so lists, search, counts and reports match the process
+// routes. It filters processes in place.
+func (s *Server) visibleProcesses(user *AccountUser, cfg RuntimeConfig, processes []Process) []Process {
+	if !s.enforceAuth || cfg.Workflow.ViewAccess != workflowViewAccessRestricted {
+		return processes
+	}
+	visible := processes[:0]
+	for idx := range processes {
+		if canViewProcess(user, cfg, &processes[idx]) {
+			visible = append(visible, processes[idx])
+		}
+	}
+	return visible
+}
+
+// canEditProcessMetadata reports whether user may change the metadata of
+// process. Unlike viewing, this is checked in open workflows too: only the
+// people canViewProcess admits may edit, not every signed-in user.
+func (s *Server) canEditProcessMetadata(user *AccountUser, cfg RuntimeConfig, process *Process) bool {
+	return !s.enforceAuth || canViewProcess(user, cfg, process)
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNormalizeWorkflowViewAccess(t *testing.T) {
+	workflow := WorkflowDef{ViewAccess: " Restricted "}
+	if err := normalizeWorkflowViewAccess(&workflow); err != nil || workflow.ViewAccess != workflowViewAccessRestricted {
+		t.Fatalf("viewAccess = %q err = %v", workflow.ViewAccess, err)
+	}
+	workflow = WorkflowDef{}
+	if err := normalizeWorkflowViewAccess(&workflow); err != nil || workflow.ViewAccess != "" {
+		t.Fatalf("blank viewAccess = %q err = %v", workflow.ViewAccess, err)
+	}
+	workflow = WorkflowDef{ViewAccess: "bogus"}
+	if err := normalizeWorkflowViewAccess(&workflow); err == nil {
+		t.Fatal("expected invalid viewAccess to fail")
+	}
+}
+
+func TestCanViewProcess(t *testing.T) {
+	cfg := RuntimeConfig{Roles: []WorkflowRole{{OrgSlug: "org1", Slug: "dep1"}}}
+	process := &Process{CreatedBy: appwriteActorID("creator-1"), Participants: []string{appwriteActorID("participant-1")}}
+	cases := []struct {
+		name string
+		user *AccountUser
+		want bool
+	}{
+		{name: "platform admin", user: &AccountUser{IsPlatformAdmin: true}, want: true},
+		{name: "creator", user: &AccountUser{IdentityUserID: "creator-1"}, want: true},
+		{name: "participant", user: &AccountUser{IdentityUserID: "participant-1"}, want: true},
+		{name: "workflow role", user: &AccountUser{IdentityUserID: "member-1", OrgSlug: "org1", RoleSlugs: []string{"dep1"}}, want: true},
+		{name: "role in other org", user: &AccountUser{IdentityUserID: "member-2", OrgSlug: "org2", RoleSlugs: []string{"dep1"}}},
+		{name: "outsider", user: &AccountUser{IdentityUserID: "outsider-1", OrgSlug: "org1"}},
+		{name: "anonymous"},
+	}
+	for _, tc := range cases {
+		if got := canViewProcess(tc.user, cfg, process); got != tc.want {
+			t.Fatalf("%s: canViewProcess = %t, want %t", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestAllowProcessViewRestrictedWorkflow(t *testing.T) {
+	store := NewMemoryStore()
+	process := Process{ID: primitive.NewObjectID(), WorkflowKey: "workflow", CreatedBy: appwriteActorID("creator-1"), Status: "active"}
+	store.SeedProcess(process)
+	currentUser := IdentityUser{ID: "outsider-1", Email: "outsider@example.com", OrgSlug: "org2"}
+	server := &Server{
+		store: store,
+		identity: &fakeIdentityStore{
+			getSessionFunc: func(ctx context.Context, sessionSecret string) (IdentitySession, error) {
+				return IdentitySession{Secret: sessionSecret, ExpiresAt: time.Now().UTC().Add(time.Hour)}, nil
+			},
+			getCurrentUserFunc: func(ctx context.Context, sessionSecret string) (IdentityUser, error) {
+				return currentUser, nil
+			},
+		},
+		enforceAuth: true,
+	}
+	cfg := RuntimeConfig{
+		Workflow: WorkflowDef{Name: "Workflow", ViewAccess: workflowViewAccessRestricted},
+		Roles:    []WorkflowRole{{OrgSlug: "org1", Slug: "dep1"}},
+	}
+	allow := func(cfg RuntimeConfig) int {
+		req := httptest.NewRequest(http.MethodGet, "/my/streams/workflow/instance/"+process.ID.Hex()+"/notarized.json", nil)
+		req.AddCookie(&http.Cookie{Name: "attesta_session", Value: "session-1"})
+		req = req.WithContext(context.WithValue(req.Context(), workflowContextKey{}, workflowContextValue{Key: "workflow", Cfg: cfg}))
+		rec := httptest.NewRecorder()
+		if !server.allowProcessView(rec, req, process.ID.Hex()) {
+			return rec.Code
+		}
+		return http.StatusOK
+	}
+
+	if status := allow(cfg); status != http.StatusForbidden {
+		t.Fatalf("outsider status = %d, want %d", status, http.StatusForbidden)
+	}
+	currentUser = IdentityUser{ID: "member-1", Email: "member@example.com", OrgSlug: "org1", Labels: []string{encodeIdentityRoleLabel("dep1")}}
+	if status := allow(cfg); status != http.StatusOK {
+		t.Fatalf("role member status = %d, want %d", status, http.StatusOK)
+	}
+	currentUser = IdentityUser{ID: "creator-1", Email: "creator@example.com"}
+	if status := allow(cfg); status != http.StatusOK {
+		t.Fatalf("creator status = %d, want %d", status, http.StatusOK)
+	}
+
+	currentUser = IdentityUser{ID: "outsider-1", Email: "outsider@example.com", OrgSlug: "org2"}
+	cfg.Workflow.ViewAccess = ""
+	if status := allow(cfg); status != http.StatusOK {
+		t.Fatalf("open workflow status = %d, want %d", status, http.StatusOK)
+	}
+}
+
+func TestIsProcessViewRoute(t *testing.T) {
+	for _, parts := range [][]string{
+		{"p1"},
+		{"p1", "notarized.json"},
+		{"p1", "downloads"},
+		{"p1", "content"},
+		{"p1", "attachment", "a1", "file"},
+		{"p1", "substep", "1.1", "notarization.json"},
+	} {
+		if !isProcessViewRoute(parts, http.MethodGet) {
+			t.Fatalf("expected %v to be a view route", parts)
+		}
+	}
+	if isProcessViewRoute([]string{"p1", "substep", "1.1", "complete"}, http.MethodPost) {
+		t.Fatal("completion must not be gated as a view route")
+	}
+	if isProcessViewRoute([]string{"p1"}, http.MethodPost) {
+		t.Fatal("only GET requests are view routes")
+	}
+}
+
+func TestRestrictedWorkflowHidesProcessesOutsideProcessRoutes(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	own := store.SeedProcess(Process{WorkflowKey: "workflow", CreatedAt: now, CreatedBy: appwriteActorID("viewer-1"), Status: "active", Progress: map[string]ProcessStep{}})
+	hidden := store.SeedProcess(Process{WorkflowKey: "workflow", CreatedAt: now, CreatedBy: appwriteActorID("creator-1"), Status: "active", Progress: map[string]ProcessStep{}})
+	server := &Server{
+		store:      store,
+		authorizer: fakeAuthorizer{},
+		identity: &fakeIdentityStore{
+			getSessionFunc: func(ctx context.Context, sessionSecret string) (IdentitySession, error) {
+				return IdentitySession{Secret: sessionSecret, ExpiresAt: now.Add(time.Hour)}, nil
+			},
+			getCurrentUserFunc: func(ctx context.Context, sessionSecret string) (IdentityUser, error) {
+				return IdentityUser{ID: "viewer-1", Email: "viewer@example.com", OrgSlug: "org2"}, nil
+			},
+		},
+		enforceAuth: true,
+		now:         func() time.Time { return now },
+	}
+	cfg := testRuntimeConfig()
+	cfg.Workflow.ViewAccess = workflowViewAccessRestricted
+	request := func(method, target string, form url.Values) *http.Request {
+		req := httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(&http.Cookie{Name: "attesta_session", Value: "session-1"})
+		return req.WithContext(context.WithValue(req.Context(), workflowContextKey{}, workflowContextValue{Key: "workflow", Cfg: cfg}))
+	}
+
+	rec := httptest.NewRecorder()
+	server.handleCreateShareLink(rec, request(http.MethodPost, "/instance/"+hidden.Hex()+"/share", url.Values{}), hidden.Hex())
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("share hidden process status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+	rec = httptest.NewRecorder()
+	server.handleCreateShareLink(rec, request(http.MethodPost, "/instance/"+own.Hex()+"/share", url.Values{}), own.Hex())
+	if rec.Code == http.StatusForbidden {
+		t.Fatalf("share own process status = %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	server.handleProcessesExport(rec, request(http.MethodPost, "/processes/export", url.Values{"ids": {own.Hex() + "," + hidden.Hex()}}))
+	reader, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	if err != nil {
+		t.Fatalf("read export zip: %v (status %d)", err, rec.Code)
+	}
+	names := map[string]bool{}
+	for _, file := range reader.File {
+		names[file.Name] = true
+	}
+	if !names[own.Hex()+".json"] || names[hidden.Hex()+".json"] {
+		t.Fatalf("export entries = %v", names)
+	}
+
+	rec = httptest.NewRecorder()
+	server.handleListProcesses(rec, request(http.MethodGet, "/processes", nil))
+	var list ProcessListResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("decode list: %v (%s)", err, rec.Body.String())
+	}
+	if len(list.Processes) != 1 || list.Processes[0].ProcessID != own.Hex() {
+		t.Fatalf("listed processes = %#v", list.Processes)
+	}
+
+	cfg.Workflow.ViewAccess = ""
+	rec = httptest.NewRecorder()
+	server.handleListProcesses(rec, request(http.MethodGet, "/processes", nil))
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || len(list.Processes) != 2 {
+		t.Fatalf("open workflow list = %#v err = %v", list.Processes, err)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ProcessAttachmentListing is one attachment in attachments.json: the
+// downloads partial's data plus what an API client needs to fetch and
+// verify a single blob.
+type ProcessAttachmentListing struct {
+	SubstepID    string `json:"substep_id"`
+	AttachmentID string `json:"attachment_id"`
+	Filename     string `json:"filename"`
+	ContentType  string `json:"content_type,omitempty"`
+	SizeBytes    int64  `json:"size_bytes"`
+	SHA256       string `json:"sha256,omitempty"`
+	URL          string `json:"url"`
+}
+
+type ProcessAttachmentsResponse struct {
+	ProcessID   string                     `json:"process_id"`
+	Attachments []ProcessAttachmentListing `json:"attachments"`
+}
+
+// handleProcessAttachmentsJSON serves attachments.json: every attachment of
+// the completed substeps, in downloads order, with its download URL.
+func (s *Server) handleProcessAttachmentsJSON(w http.ResponseWriter, r *http.Request, processID string) {
+	workflowKey, cfg, ok := s.selectedWorkflowOrRedirectHome(w, r)
+	if !ok {
+		return
+	}
+	process, err := s.loadProcess(r.Context(), processID)
+	if err != nil || !s.processBelongsToWorkflow(process, workflowKey) {
+		http.NotFound(w, r)
+		return
+	}
+	files := sortedProcessAttachments(s.withAttachmentSizes(r.Context(), collectProcessAttachments(cfg.Workflow, process)))
+	response := ProcessAttachmentsResponse{
+		ProcessID:   process.ID.Hex(),
+		Attachments: make([]ProcessAttachmentListing, 0, len(files)),
+	}
+	for _, file := range files {
+		if strings.TrimSpace(file.AttachmentID) == "" {
+			continue
+		}
+		response.Attachments = append(response.Attachments, ProcessAttachmentListing{
+			SubstepID:    file.SubstepID,
+			AttachmentID: file.AttachmentID,
+			Filename:     sanitizeAttachmentFilename(file.Filename),
+			ContentType:  file.ContentType,
+			SizeBytes:    file.SizeBytes,
+			SHA256:       file.SHA256,
+			URL:          processAttachmentURL(workflowKey, process, file.AttachmentID),
+		})
+	}
+	writeJSON(w, response)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestHandleProcessAttachmentsJSON(t *testing.T) {
+	store := NewMemoryStore()
+	processID := primitive.NewObjectID()
+	stored, err := store.SaveAttachment(t.Context(), AttachmentUpload{ProcessID: processID, SubstepID: "1.3", Filename: "legacy.pdf", MaxBytes: 1 << 20}, strings.NewReader(strings.Repeat("x", 600)))
+	if err != nil {
+		t.Fatalf("SaveAttachment: %v", err)
+	}
+	reportID := primitive.NewObjectID().Hex()
+	store.SeedProcess(Process{
+		ID:          processID,
+		WorkflowKey: "workflow",
+		CreatedAt:   time.Now().UTC(),
+		Status:      "active",
+		Progress: map[string]ProcessStep{
+			"1_1": {State: "done", Data: map[string]interface{}{"value": 10}},
+			"1_2": {State: "pending", Data: map[string]interface{}{
+				"draft": map[string]interface{}{"attachmentId": primitive.NewObjectID().Hex(), "filename": "draft.pdf"},
+			}},
+			"1_3": {State: "done", Data: map[string]interface{}{
+				"report": map[string]interface{}{"attachmentId": reportID, "filename": "report.pdf", "contentType": "application/pdf", "size": int64(2048), "sha256": "abc123"},
+				"legacy": map[string]interface{}{"attachmentId": stored.ID.Hex(), "filename": "legacy.pdf"},
+			}},
+			"2_1": {State: "done", Data: map[string]interface{}{
+				"scan": map[string]interface{}{"attachmentId": primitive.NewObjectID().Hex(), "filename": "../etc/\"pass\r\nwd\".txt"},
+			}},
+		},
+	})
+	server := &Server{
+		store: store,
+		configProvider: func() (RuntimeConfig, error) {
+			return testRuntimeConfig(), nil
+		},
+	}
+
+	rec := httptest.NewRecorder()
+	server.handleProcessRoutes(rec, httptest.NewRequest(http.MethodGet, "/instance/"+processID.Hex()+"/attachments.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	var got ProcessAttachmentsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode attachments: %v", err)
+	}
+	if got.ProcessID != processID.Hex() || len(got.Attachments) != 3 {
+		t.Fatalf("attachments = %#v", got)
+	}
+	base := streamInstancePath("workflow", processID.Hex())
+	legacy, report := got.Attachments[0], got.Attachments[1]
+	if legacy.Filename != "legacy.pdf" || legacy.SubstepID != "1.3" || legacy.SizeBytes != 600 || legacy.URL != base+"/attachment/"+stored.ID.Hex()+"/file" {
+		t.Fatalf("legacy attachment = %#v", legacy)
+	}
+	if report != (ProcessAttachmentListing{SubstepID: "1.3", AttachmentID: reportID, Filename: "report.pdf", ContentType: "application/pdf", SizeBytes: 2048, SHA256: "abc123", URL: base + "/attachment/" + reportID + "/file"}) {
+		t.Fatalf("report attachment = %#v", report)
+	}
+	if scan := got.Attachments[2]; scan.Filename != ".._etc_pass__wd.txt" {
+		t.Fatalf("unsafe filename = %q, want it sanitized", scan.Filename)
+	}
+
+	rec = httptest.NewRecorder()
+	server.handleProcessRoutes(rec, httptest.NewRequest(http.MethodGet, "/instance/"+primitive.NewObjectID().Hex()+"/attachments.json", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown process status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandleProcessAttachmentsJSONEmpty(t *testing.T) {
+	store := NewMemoryStore()
+	process := Process{ID: primitive.NewObjectID(), WorkflowKey: "workflow", CreatedAt: time.Now().UTC(), Status: "active", Progress: map[string]ProcessStep{}}
+	store.SeedProcess(process)
+	server := &Server{
+		store: store,
+		configProvider: func() (RuntimeConfig, error) {
+			return testRuntimeConfig(), nil
+		},
+	}
+	rec := httptest.NewRecorder()
+	server.handleProcessRoutes(rec, httptest.NewRequest(http.MethodGet, "/instance/"+process.ID.Hex()+"/attachments.json", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"attachments": []`) {
+		t.Fatalf("status = %d body = %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var processCodePrefixPattern = regexp.MustCompile(`^[A-Z][A-Z0-9]{0,11}$`)
+
+// ErrProcessCodeConflict is returned when a process code is already used by
+// another process of the same workflow.
+var ErrProcessCodeConflict = errors.New("process: code already used in workflow")
+
+// normalizeProcessCodePrefix upper-cases workflow.processCodePrefix and
+// rejects prefixes that would not read as a code (letters and digits only,
+// starting with a letter, at most 12 characters).
+func normalizeProcessCodePrefix(workflow *WorkflowDef) error {
+	workflow.ProcessCodePrefix = strings.ToUpper(strings.TrimSpace(workflow.ProcessCodePrefix))
+	if workflow.ProcessCodePrefix == "" {
+		return nil
+	}
+	if !processCodePrefixPattern.MatchString(workflow.ProcessCodePrefix) {
+		return fmt.Errorf("invalid processCodePrefix %q: use up to 12 letters and digits, starting with a letter", workflow.ProcessCodePrefix)
+	}
+	return nil
+}
+
+// formatProcessCode renders the human code of a process, e.g.
+// PRC-2026-000123: prefix, creation year and the workflow's sequence number.
+func formatProcessCode(prefix string, createdAt time.Time, sequence int64) string {
+	return fmt.Sprintf("%s-%d-%06d", prefix, createdAt.UTC().Year(), sequence)
+}
+
+// processCodeSequence returns the sequence number at the end of a code
+// produced by formatProcessCode, or false when code does not end in one.
+func processCodeSequence(code string) (int64, bool) {
+	index := strings.LastIndex(code, "-")
+	if index < 0 {
+		return 0, false
+	}
+	sequence, err := strconv.ParseInt(code[index+1:], 10, 64)
+	if err != nil || sequence < 0 {
+		return 0, false
+	}
+	return sequence, true
+}
+
+// assignProcessCode sets process.Code from the workflow's counter when the
+// workflow configures processCodePrefix. Each call consumes a number, so
+// codes of failed inserts leave gaps rather than duplicates.
+func (s *Server) assignProcessCode(ctx context.Context, def WorkflowDef, process *Process) error {
+	if def.ProcessCodePrefix == "" {
+		return nil
+	}
+	sequence, err := s.store.NextProcessSequence(ctx, process.WorkflowKey)
+	if err != nil {
+		return err
+	}
+	process.Code = formatProcessCode(def.ProcessCodePrefix, process.CreatedAt, sequence)
+	return nil
+}
+
+// processRef is the identifier used in links to a process: its code when it
+// has one, the ObjectID hex otherwise. Both resolve through loadProcess.
+func processRef(process *Process) string {
+	if process == nil {
+		return ""
+	}
+	if code := strings.TrimSpace(process.Code); code != "" {
+		return code
+	}
+	return process.ID.Hex()
+}
+
+// loadProcessByRef resolves ref as an ObjectID hex or, failing that, as the
+// code of a process in workflowKey.
+func (s *Server) loadProcessByRef(ctx context.Context, workflowKey, ref string) (*Process, error) {
+	if objectID, err := primitive.ObjectIDFromHex(ref); err == nil {
+		return s.store.LoadProcessByID(ctx, objectID)
+	}
+	code := strings.ToUpper(strings.TrimSpace(ref))
+	if code == "" {
+		return nil, mongo.ErrNoDocuments
+	}
+	return s.store.LoadProcessByCode(ctx, workflowKey, code)
+}
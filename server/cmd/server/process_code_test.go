@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestNormalizeProcessCodePrefix(t *testing.T) {
+	workflow := WorkflowDef{ProcessCodePrefix: " prc "}
+	if err := normalizeProcessCodePrefix(&workflow); err != nil || workflow.ProcessCodePrefix != "PRC" {
+		t.Fatalf("prefix = %q, err = %v", workflow.ProcessCodePrefix, err)
+	}
+	for _, prefix := range []string{"1PRC", "PR-C", "ABCDEFGHIJKLM"} {
+		if err := normalizeProcessCodePrefix(&WorkflowDef{ProcessCodePrefix: prefix}); err == nil {
+			t.Fatalf("expected %q to be rejected", prefix)
+		}
+	}
+	if got := formatProcessCode("PRC", time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), 123); got != "PRC-2026-000123" {
+		t.Fatalf("formatProcessCode = %q", got)
+	}
+}
+
+func TestHandleStartProcessAssignsSequentialCodes(t *testing.T) {
+	now := time.Date(2026, 2, 2, 13, 0, 0, 0, time.UTC)
+	cfg := testRuntimeConfig()
+	cfg.Workflow.ProcessCodePrefix = "PRC"
+	store := NewMemoryStore()
+	server := &Server{
+		store:         store,
+		sse:           newSSEHub(),
+		now:           func() time.Time { return now },
+		workflowDefID: primitive.NewObjectID(),
+		configProvider: func() (RuntimeConfig, error) {
+			return cfg, nil
+		},
+	}
+	for i, want := range []string{"PRC-2026-000001", "PRC-2026-000002"} {
+		rr := httptest.NewRecorder()
+		server.handleStartProcess(rr, httptest.NewRequest(http.MethodPost, "/process/start", nil))
+		if rr.Code != http.StatusSeeOther {
+			t.Fatalf("start %d: status = %d", i, rr.Code)
+		}
+		if location := rr.Header().Get("Location"); !strings.HasSuffix(location, "/instance/"+want) {
+			t.Fatalf("start %d: location = %q, want code %s", i, location, want)
+		}
+		process, err := store.LoadProcessByCode(context.Background(), "workflow", want)
+		if err != nil || process.Code != want {
+			t.Fatalf("start %d: LoadProcessByCode = %#v, %v", i, process, err)
+		}
+	}
+
+	cfg.Workflow.ProcessCodePrefix = ""
+	rr := httptest.NewRecorder()
+	server.handleStartProcess(rr, httptest.NewRequest(http.MethodPost, "/process/start", nil))
+	id := rr.Header().Get("Location")[strings.LastIndex(rr.Header().Get("Location"), "/")+1:]
+	if _, err := primitive.ObjectIDFromHex(id); err != nil {
+		t.Fatalf("expected hex id without a prefix, got %q", id)
+	}
+}
+
+func TestProcessRoutesResolveCodes(t *testing.T) {
+	store := NewMemoryStore()
+	processID := store.SeedProcess(Process{
+		WorkflowKey: "workflow",
+		Code:        "PRC-2026-000007",
+		CreatedAt:   time.Date(2026, 2, 2, 13, 0, 0, 0, time.UTC),
+		Status:      "active",
+		Progress:    map[string]ProcessStep{"1_1": {State: "pending"}},
+	})
+	server := &Server{
+		store: store,
+		tmpl:  parseTestTemplates(t),
+		configProvider: func() (RuntimeConfig, error) {
+			return testRuntimeConfig(), nil
+		},
+	}
+
+	process, err := server.loadProcess(context.Background(), "prc-2026-000007")
+	if err != nil || process.ID != processID {
+		t.Fatalf("loadProcess by code = %v, %v", process, err)
+	}
+	if _, err := server.loadProcess(context.Background(), "PRC-2026-000008"); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Fatalf("expected unknown code to be not found, got %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	server.handleProcessRoutes(rec, httptest.NewRequest(http.MethodGet, "/instance/PRC-2026-000007/availability.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("availability by code status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	server.handleProcessRoutes(rec, httptest.NewRequest(http.MethodGet, "/instance/PRC-2026-000007", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "PRC-2026-000007") {
+		t.Fatalf("page by code status = %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	server.handleProcessRoutes(rec, httptest.NewRequest(http.MethodGet, "/instance/PRC-2026-000008", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown code status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestMemoryStoreProcessSequencesArePerWorkflow(t *testing.T) {
+	store := NewMemoryStore()
+	for _, want := range []int64{1, 2} {
+		if got, _ := store.NextProcessSequence(context.Background(), "a"); got != want {
+			t.Fatalf("sequence a = %d, want %d", got, want)
+		}
+	}
+	if got, _ := store.NextProcessSequence(context.Background(), "b"); got != 1 {
+		t.Fatalf("sequence b = %d, want 1", got)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Values of WorkflowDef.DoneWhen.
+const (
+	workflowDoneWhenAll      = "all"
+	workflowDoneWhenRequired = "required"
+)
+
+// normalizeWorkflowDoneWhen defaults doneWhen to "all" and checks that
+// `optional: true` substeps only appear where they change completion.
+func normalizeWorkflowDoneWhen(def *WorkflowDef) error {
+	def.DoneWhen = strings.ToLower(strings.TrimSpace(def.DoneWhen))
+	switch def.DoneWhen {
+	case "":
+		def.DoneWhen = workflowDoneWhenAll
+	case workflowDoneWhenAll, workflowDoneWhenRequired:
+	default:
+		return fmt.Errorf("doneWhen must be %q or %q", workflowDoneWhenAll, workflowDoneWhenRequired)
+	}
+	required := 0
+	for _, sub := range orderedSubsteps(*def) {
+		if !sub.Optional {
+			required++
+			continue
+		}
+		if def.DoneWhen != workflowDoneWhenRequired {
+			return fmt.Errorf("substep %s is optional but doneWhen is not %q", sub.SubstepID, workflowDoneWhenRequired)
+		}
+	}
+	if def.DoneWhen == workflowDoneWhenRequired && required == 0 {
+		return errors.New("doneWhen=required needs at least one substep that is not optional")
+	}
+	for _, sub := range orderedSubsteps(*def) {
+		if sub.Optional && !optionalSubstepOpensBeforeCompletion(*def, sub) {
+			return fmt.Errorf("substep %s is optional but only opens once every required substep is done; order it earlier or give it a dependsOn list", sub.SubstepID)
+		}
+	}
+	return nil
+}
+
+// optionalSubstepOpensBeforeCompletion reports whether sub can open while
+// some required substep is still pending. Optional substeps close with the
+// process, so one that waits for every required substep could never be
+// filled. Substeps with visibleWhen are not checked: their visibility
+// depends on answers this check does not have.
+func optionalSubstepOpensBeforeCompletion(def WorkflowDef, sub WorkflowSub) bool {
+	if strings.TrimSpace(sub.VisibleWhen) != "" {
+		return true
+	}
+	var required []string
+	for _, other := range orderedSubsteps(def) {
+		if substepRequiredForCompletion(def, other) {
+			required = append(required, other.SubstepID)
+		}
+	}
+	for _, pending := range required {
+		process := &Process{Progress: map[string]ProcessStep{}}
+		for _, id := range required {
+			if id != pending {
+				process.Progress[id] = ProcessStep{State: "done"}
+			}
+		}
+		if isSequenceOK(def, process, sub.SubstepID) {
+			return true
+		}
+	}
+	return false
+}
+
+// substepRequiredForCompletion reports whether sub has to be settled before
+// a process of def counts as complete.
+func substepRequiredForCompletion(def WorkflowDef, sub WorkflowSub) bool {
+	return !sub.Optional || def.DoneWhen != workflowDoneWhenRequired
+}
+
+// isProcessComplete is the single definition of a finished process: every
+// substep it still needs is done or hidden by visibleWhen. With doneWhen:
+// required, optional substeps are not needed.
+func isProcessComplete(def WorkflowDef, process *Process) bool {
+	hidden := hiddenSubsteps(def, process)
+	for _, sub := range orderedSubsteps(def) {
+		if !substepRequiredForCompletion(def, sub) {
+			continue
+		}
+		if !isSubstepSettled(def, process, hidden, sub.SubstepID) {
+			return false
+		}
+	}
+	return true
+}
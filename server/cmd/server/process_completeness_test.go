@@ -0,0 +1,181 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func optionalSubstepRuntimeConfig() RuntimeConfig {
+	schema := map[string]interface{}{"type": "object"}
+	return RuntimeConfig{
+		Workflow: WorkflowDef{
+			Name:     "Optional notes",
+			DoneWhen: workflowDoneWhenRequired,
+			Steps: []WorkflowStep{{
+				StepID: "1",
+				Title:  "Intake",
+				Order:  1,
+				Substep: []WorkflowSub{
+					{SubstepID: "1.1", Title: "Batch", Order: 1, Role: "dep1", InputKey: "batchId", InputType: "formata", Schema: schema},
+					{SubstepID: "1.2", Title: "Remarks", Order: 2, Role: "dep1", InputKey: "note", InputType: "formata", Schema: schema, Optional: true},
+				},
+			}},
+		},
+		DPP: DPPConfig{Enabled: true, GTIN: "09506000134352", LotInputKey: "batchId", LotDefault: "LOT", SerialStrategy: "process_id_hex"},
+	}
+}
+
+func TestIsProcessCompleteSkipsOptionalSubsteps(t *testing.T) {
+	cfg := optionalSubstepRuntimeConfig()
+	now := time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC)
+	process := &Process{ID: primitive.NewObjectID(), CreatedAt: now, Status: "active", Progress: map[string]ProcessStep{
+		"1.1": {State: "done", DoneAt: &now},
+	}}
+
+	if !isProcessComplete(cfg.Workflow, process) {
+		t.Fatal("expected process with only the optional substep pending to be complete")
+	}
+	if got := deriveProcessStatus(cfg.Workflow, process); got != processStatusDone {
+		t.Fatalf("status = %q, want done", got)
+	}
+	if got := buildNotarizedExport(cfg.Workflow, process).Status; got != processStatusDone {
+		t.Fatalf("export status = %q, want done", got)
+	}
+
+	cfg.Workflow.DoneWhen = workflowDoneWhenAll
+	if isProcessComplete(cfg.Workflow, process) {
+		t.Fatal("expected doneWhen=all to wait for every substep")
+	}
+}
+
+func TestCompleteSubstepFinalizesProcessWithOptionalSubstepPending(t *testing.T) {
+	cfg := optionalSubstepRuntimeConfig()
+	now := time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	svc := &ProcessService{store: store, now: func() time.Time { return now }}
+	processID := store.SeedProcess(Process{ID: primitive.NewObjectID(), WorkflowKey: "workflow", CreatedAt: now, Status: "active", Progress: map[string]ProcessStep{
+		"1_1": {State: "pending"},
+		"1_2": {State: "pending"},
+	}})
+	process, err := store.LoadProcessByID(t.Context(), processID)
+	if err != nil {
+		t.Fatalf("LoadProcessByID: %v", err)
+	}
+	process.Progress = normalizeProgressKeys(process.Progress)
+
+	updated, err := svc.CompleteSubstep(t.Context(), CompleteSubstepCmd{
+		Process:     process,
+		WorkflowKey: "workflow",
+		SubstepID:   "1.1",
+		Substep:     cfg.Workflow.Steps[0].Substep[0],
+		Actor:       Actor{ID: "user-1", Role: "dep1"},
+		Payload:     map[string]interface{}{"batchId": "B-1"},
+		Config:      cfg,
+		Now:         now,
+	})
+	if err != nil {
+		t.Fatalf("CompleteSubstep: %v", err)
+	}
+	if updated.Status != processStatusDone {
+		t.Fatalf("status = %q, want done", updated.Status)
+	}
+	if updated.DPP == nil || updated.DPP.Lot != "B-1" {
+		t.Fatalf("dpp = %#v, want one generated on completion", updated.DPP)
+	}
+	if computeAvailability(cfg.Workflow, updated)["1.2"] {
+		t.Fatal("expected the optional substep to close with the process")
+	}
+
+	// The DPP and completion webhook already describe the finished process,
+	// so a late optional submission must not change its data.
+	_, err = svc.CompleteSubstep(t.Context(), CompleteSubstepCmd{
+		Process:     updated,
+		WorkflowKey: "workflow",
+		SubstepID:   "1.2",
+		Substep:     cfg.Workflow.Steps[0].Substep[1],
+		Actor:       Actor{ID: "user-1", Role: "dep1"},
+		Payload:     map[string]interface{}{"note": "late remark"},
+		Config:      cfg,
+		Now:         now,
+	})
+	if !errors.Is(err, ErrIllegalStatusTransition) {
+		t.Fatalf("CompleteSubstep on a done process: expected ErrIllegalStatusTransition, got %v", err)
+	}
+	if err := store.UpdateProcessProgress(t.Context(), processID, "workflow", "1.2", ProcessStep{State: "done", DoneAt: &now}); !errors.Is(err, ErrIllegalStatusTransition) {
+		t.Fatalf("UpdateProcessProgress on a done process: expected ErrIllegalStatusTransition, got %v", err)
+	}
+	if stored, _ := store.SnapshotProcess(processID); isSubstepDone(&stored, "1.2") {
+		t.Fatal("late optional submission was stored")
+	}
+}
+
+func TestOptionalSubstepDoesNotBlockLaterSubsteps(t *testing.T) {
+	cfg := optionalSubstepRuntimeConfig()
+	schema := map[string]interface{}{"type": "object"}
+	cfg.Workflow.Steps[0].Substep = append(cfg.Workflow.Steps[0].Substep, WorkflowSub{SubstepID: "1.3", Title: "Release", Order: 3, Role: "dep1", InputKey: "release", InputType: "formata", Schema: schema})
+	now := time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC)
+	process := &Process{ID: primitive.NewObjectID(), CreatedAt: now, Status: "active", Progress: map[string]ProcessStep{
+		"1.1": {State: "done", DoneAt: &now},
+	}}
+
+	available := computeAvailability(cfg.Workflow, process)
+	if !available["1.2"] || !available["1.3"] {
+		t.Fatalf("availability = %#v, want optional 1.2 and later 1.3 open", available)
+	}
+	if isProcessComplete(cfg.Workflow, process) {
+		t.Fatal("expected the required 1.3 to keep the process open")
+	}
+
+	process.Progress["1.3"] = ProcessStep{State: "done", DoneAt: &now}
+	if !isProcessComplete(cfg.Workflow, process) {
+		t.Fatal("expected the process to complete with the optional substep never filled")
+	}
+
+	cfg.Workflow.DoneWhen = workflowDoneWhenAll
+	cfg.Workflow.Steps[0].Substep[1].Optional = false
+	delete(process.Progress, "1.3")
+	if computeAvailability(cfg.Workflow, process)["1.3"] {
+		t.Fatal("expected a required 1.2 to keep blocking 1.3")
+	}
+}
+
+func TestNormalizeWorkflowDoneWhen(t *testing.T) {
+	def := optionalSubstepRuntimeConfig().Workflow
+	def.DoneWhen = " Required "
+	err := normalizeWorkflowDoneWhen(&def)
+	if err == nil || !strings.Contains(err.Error(), "only opens once every required substep is done") {
+		t.Fatalf("expected trailing optional substep error, got %v", err)
+	}
+	def.Steps[0].Substep[0].Order, def.Steps[0].Substep[1].Order = 2, 1
+	if err := normalizeWorkflowDoneWhen(&def); err != nil || def.DoneWhen != workflowDoneWhenRequired {
+		t.Fatalf("normalize required = %q, %v", def.DoneWhen, err)
+	}
+
+	def.DoneWhen = ""
+	err = normalizeWorkflowDoneWhen(&def)
+	if err == nil || !strings.Contains(err.Error(), "substep 1.2 is optional") {
+		t.Fatalf("expected optional substep error, got %v", err)
+	}
+
+	def.Steps[0].Substep[1].Optional = false
+	def.DoneWhen = ""
+	if err := normalizeWorkflowDoneWhen(&def); err != nil || def.DoneWhen != workflowDoneWhenAll {
+		t.Fatalf("normalize default = %q, %v", def.DoneWhen, err)
+	}
+
+	def.DoneWhen = "most"
+	if err := normalizeWorkflowDoneWhen(&def); err == nil {
+		t.Fatal("expected unknown doneWhen error")
+	}
+
+	def.DoneWhen = workflowDoneWhenRequired
+	def.Steps[0].Substep[0].Optional = true
+	def.Steps[0].Substep[1].Optional = true
+	if err := normalizeWorkflowDoneWhen(&def); err == nil {
+		t.Fatal("expected error when every substep is optional")
+	}
+}
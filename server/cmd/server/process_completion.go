@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"strings"
 	"time"
 
@@ -15,11 +16,18 @@ import (
 var (
 	ErrProgressUpdate = errors.New("process: progress update failed")
 	ErrNotarization   = errors.New("process: notarization failed")
+	ErrSubstepNotDone = errors.New("process: substep is not completed")
 )
 
 type ProcessService struct {
 	store Store
 	now   func() time.Time
+	// signer and webhookClient are used for the process.completed webhook.
+	// webhookRetryDelay is the first backoff between delivery attempts; zero
+	// uses completionWebhookRetryDelay.
+	signer            *exportSigner
+	webhookClient     *http.Client
+	webhookRetryDelay time.Duration
 }
 
 type CompleteSubstepCmd struct {
@@ -33,6 +41,16 @@ type CompleteSubstepCmd struct {
 	Now         time.Time
 }
 
+type AmendSubstepCmd struct {
+	Process     *Process
+	WorkflowKey string
+	SubstepID   string
+	Actor       Actor
+	Payload     map[string]interface{}
+	Reason      string
+	Now         time.Time
+}
+
 func (p *ProcessService) serviceNow(fallback time.Time) time.Time {
 	if p != nil && p.now != nil {
 		return p.now().UTC()
@@ -47,6 +65,9 @@ func (p *ProcessService) CompleteSubstep(ctx context.Context, cmd CompleteSubste
 	if cmd.Process == nil {
 		return nil, fmt.Errorf("missing process")
 	}
+	if !processAcceptsCompletions(cmd.Process) {
+		return cmd.Process, fmt.Errorf("%w: process is %s", ErrIllegalStatusTransition, deriveProcessStatus(cmd.Config.Workflow, cmd.Process))
+	}
 	now := cmd.Now
 	if now.IsZero() {
 		now = p.serviceNow(time.Time{})
@@ -60,10 +81,16 @@ func (p *ProcessService) CompleteSubstep(ctx context.Context, cmd CompleteSubste
 		DoneBy:      &cmd.Actor,
 		Data:        cmd.Payload,
 	}
-	if err := p.store.UpdateProcessProgress(ctx, cmd.Process.ID, cmd.WorkflowKey, cmd.SubstepID, progressUpdate); err != nil {
-		return cmd.Process, fmt.Errorf("%w: %v", ErrProgressUpdate, err)
+	if availableAt := substepAvailableAt(cmd.Config.Workflow, cmd.Process, cmd.SubstepID); !availableAt.IsZero() {
+		duration := int64(now.Sub(availableAt) / time.Second)
+		if duration < 0 {
+			duration = 0
+		}
+		progressUpdate.DurationSeconds = &duration
+	}
+	if err := checkRequiredDPPInputs(cmd.Config, cmd.Process, cmd.SubstepID, progressUpdate); err != nil {
+		return cmd.Process, err
 	}
-
 	notary := Notarization{
 		ProcessID: cmd.Process.ID,
 		SubstepID: cmd.SubstepID,
@@ -75,21 +102,111 @@ func (p *ProcessService) CompleteSubstep(ctx context.Context, cmd CompleteSubste
 			Digest: digestPayload(cmd.Payload),
 		},
 	}
-	if err := p.store.InsertNotarization(ctx, notary); err != nil {
-		return cmd.Process, fmt.Errorf("%w: %v", ErrNotarization, err)
+	// The progress and its notarization are kept together or not at all. The
+	// done status and the DPP are written afterwards: EnsureCompletionArtifacts
+	// repairs them on the next load, and the completion webhook must not fire
+	// for a rolled back write.
+	if err := p.store.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := p.store.UpdateProcessProgress(ctx, cmd.Process.ID, cmd.WorkflowKey, cmd.SubstepID, progressUpdate); errors.Is(err, ErrIllegalStatusTransition) {
+			return err
+		} else if err != nil {
+			return fmt.Errorf("%w: %v", ErrProgressUpdate, err)
+		}
+		if err := p.store.InsertNotarization(ctx, notary); err != nil {
+			return fmt.Errorf("%w: %v", ErrNotarization, err)
+		}
+		return nil
+	}); err != nil {
+		return cmd.Process, err
+	}
+	if err := p.store.DeleteSubstepDrafts(ctx, cmd.Process.ID, cmd.SubstepID); err != nil {
+		log.Printf("failed to clear drafts of process %s substep %s: %v", cmd.Process.ID.Hex(), cmd.SubstepID, err)
 	}
+	appendProcessEvent(ctx, p.store, ProcessEvent{
+		ProcessID:   cmd.Process.ID,
+		WorkflowKey: cmd.WorkflowKey,
+		Type:        processEventSubstepCompleted,
+		Actor:       &cmd.Actor,
+		SubstepID:   cmd.SubstepID,
+		At:          now,
+		Detail:      notary.FakeNotary.Digest,
+	})
 
 	reloaded, err := p.reloadProcess(ctx, cmd.Process.ID)
 	if err != nil {
 		return cmd.Process, err
 	}
 
-	if isProcessDone(cmd.Config.Workflow, reloaded) {
+	if isProcessComplete(cmd.Config.Workflow, reloaded) {
 		return p.finalizeProcessIfDone(ctx, cmd.Config, cmd.WorkflowKey, reloaded, now), nil
 	}
 	return reloaded, nil
 }
 
+// AmendSubstep notarizes a corrected value for an already completed substep.
+// The original submission stays in place; the amendment is appended with the
+// digest of the value it supersedes.
+func (p *ProcessService) AmendSubstep(ctx context.Context, cmd AmendSubstepCmd) (*Process, error) {
+	if cmd.Process == nil {
+		return nil, fmt.Errorf("missing process")
+	}
+	if !processAcceptsAmendments(cmd.Process) {
+		return cmd.Process, fmt.Errorf("%w: process is %s", ErrIllegalStatusTransition, processStatusTerminated)
+	}
+	progress, ok := cmd.Process.Progress[cmd.SubstepID]
+	if !ok || progress.State != "done" {
+		return cmd.Process, ErrSubstepNotDone
+	}
+	now := cmd.Now
+	if now.IsZero() {
+		now = p.serviceNow(time.Time{})
+	}
+
+	amendment := ProcessAmendment{
+		Data:           cmd.Payload,
+		Digest:         digestPayload(cmd.Payload),
+		PreviousDigest: currentStepDigest(progress),
+		Reason:         cmd.Reason,
+		AmendedAt:      now,
+		AmendedBy:      &cmd.Actor,
+	}
+	notary := Notarization{
+		ProcessID: cmd.Process.ID,
+		SubstepID: cmd.SubstepID,
+		Payload:   cmd.Payload,
+		Actor:     cmd.Actor,
+		CreatedAt: now,
+		FakeNotary: FakeNotary{
+			Method: "sha256",
+			Digest: amendment.Digest,
+		},
+		AmendsDigest: amendment.PreviousDigest,
+	}
+	if err := p.store.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := p.store.AppendProcessAmendment(ctx, cmd.Process.ID, cmd.WorkflowKey, cmd.SubstepID, amendment); errors.Is(err, ErrIllegalStatusTransition) {
+			return err
+		} else if err != nil {
+			return fmt.Errorf("%w: %v", ErrProgressUpdate, err)
+		}
+		if err := p.store.InsertNotarization(ctx, notary); err != nil {
+			return fmt.Errorf("%w: %v", ErrNotarization, err)
+		}
+		return nil
+	}); err != nil {
+		return cmd.Process, err
+	}
+	appendProcessEvent(ctx, p.store, ProcessEvent{
+		ProcessID:   cmd.Process.ID,
+		WorkflowKey: cmd.WorkflowKey,
+		Type:        processEventSubstepAmended,
+		Actor:       &cmd.Actor,
+		SubstepID:   cmd.SubstepID,
+		At:          now,
+		Detail:      cmd.Reason,
+	})
+	return p.reloadProcess(ctx, cmd.Process.ID)
+}
+
 func (p *ProcessService) EnsureCompletionArtifacts(ctx context.Context, cfg RuntimeConfig, workflowKey string, process *Process) *Process {
 	if process == nil || !isProcessClosed(cfg.Workflow, process) {
 		return process
@@ -103,7 +220,7 @@ func (p *ProcessService) finalizeProcessIfDone(ctx context.Context, cfg RuntimeC
 	}
 
 	updated := false
-	if process.Termination == nil && strings.TrimSpace(process.Status) != "done" && isProcessDone(cfg.Workflow, process) {
+	if process.Termination == nil && strings.TrimSpace(process.Status) != "done" && isProcessComplete(cfg.Workflow, process) {
 		if err := p.store.UpdateProcessStatus(ctx, process.ID, workflowKey, "done"); err != nil {
 			log.Printf("failed to persist process status for %s: %v", process.ID.Hex(), err)
 		} else {
@@ -122,15 +239,16 @@ func (p *ProcessService) finalizeProcessIfDone(ctx context.Context, cfg RuntimeC
 		}
 	}
 
-	if !updated {
-		return process
-	}
-	reloaded, err := p.reloadProcess(ctx, process.ID)
-	if err != nil {
-		log.Printf("failed to reload process %s after completion artifact update: %v", process.ID.Hex(), err)
-		return process
+	if updated {
+		reloaded, err := p.reloadProcess(ctx, process.ID)
+		if err != nil {
+			log.Printf("failed to reload process %s after completion artifact update: %v", process.ID.Hex(), err)
+		} else {
+			process = reloaded
+		}
 	}
-	return reloaded
+	p.notifyProcessCompleted(ctx, cfg, workflowKey, process, generatedAt)
+	return process
 }
 
 func (p *ProcessService) reloadProcess(ctx context.Context, processID primitive.ObjectID) (*Process, error) {
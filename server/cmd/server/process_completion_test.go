@@ -242,7 +242,7 @@ func TestFinalizeProcessIfDoneStatusUpdateError(t *testing.T) {
 	}
 }
 
-func TestCompleteSubstepNotarizationErrorAfterProgressSaved(t *testing.T) {
+func TestCompleteSubstepNotarizationErrorRollsBackProgress(t *testing.T) {
 	store := NewMemoryStore()
 	store.InsertNotarizeErr = assertErr("notarize failed")
 	svc := &ProcessService{store: store, now: time.Now}
@@ -258,11 +258,14 @@ func TestCompleteSubstepNotarizationErrorAfterProgressSaved(t *testing.T) {
 		Payload: map[string]interface{}{"value": "x"}, Config: RuntimeConfig{Workflow: WorkflowDef{}},
 		Now: time.Now().UTC(),
 	})
-	if !errors.Is(err, ErrNotarization) {
-		t.Fatalf("expected ErrNotarization, got %v", err)
+	if !errors.Is(err, ErrNotarization) || !errors.Is(err, ErrTransactionRolledBack) {
+		t.Fatalf("expected rolled back ErrNotarization, got %v", err)
 	}
 	stored, _ := store.LoadProcessByID(context.Background(), processID)
-	if step := stored.Progress["1_1"]; step.State != "done" {
-		t.Fatalf("expected progress saved despite notarization failure, got %q", step.State)
+	if step := stored.Progress["1_1"]; step.State != "pending" {
+		t.Fatalf("expected progress rolled back after notarization failure, got %q", step.State)
+	}
+	if notarizations, _ := store.ListNotarizations(context.Background(), processID); len(notarizations) != 0 {
+		t.Fatalf("expected no notarization, got %d", len(notarizations))
 	}
 }
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	processEventStarted          = "process_started"
+	processEventSubstepCompleted = "substep_completed"
+	processEventSubstepAmended   = "substep_amended"
+	processEventSubstepRejected  = "substep_rejected"
+	processEventSubstepAdapted   = "substep_adapted"
+	processEventTerminated       = "process_terminated"
+	processEventDPPRegenerated   = "dpp_regenerated"
+	processEventWorkflowChanged  = "workflow_changed"
+)
+
+// ProcessEvent is one entry of the append-only process history kept in
+// process_events. Unlike notarizations it also records actions that carry no
+// payload, such as terminations and local adaptations.
+type ProcessEvent struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	ProcessID   primitive.ObjectID `bson:"processId"`
+	WorkflowKey string             `bson:"workflowKey"`
+	Type        string             `bson:"type"`
+	Actor       *Actor             `bson:"actor,omitempty"`
+	SubstepID   string             `bson:"substepId,omitempty"`
+	At          time.Time          `bson:"at"`
+	Detail      string             `bson:"detail,omitempty"`
+}
+
+type ProcessEventView struct {
+	Type      string `json:"type"`
+	ActorID   string `json:"actor_id,omitempty"`
+	ActorRole string `json:"actor_role,omitempty"`
+	SubstepID string `json:"substep_id,omitempty"`
+	At        string `json:"at"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+type ProcessEventsResponse struct {
+	ProcessID string             `json:"process_id"`
+	Events    []ProcessEventView `json:"events"`
+}
+
+// appendProcessEvent records event after the action it describes has been
+// stored. History is best effort: a failed write is logged and never undoes
+// or fails the action itself.
+func appendProcessEvent(ctx context.Context, store Store, event ProcessEvent) {
+	if store == nil {
+		return
+	}
+	if err := store.AppendProcessEvent(ctx, event); err != nil {
+		log.Printf("failed to record %s event for process %s: %v", event.Type, event.ProcessID.Hex(), err)
+	}
+}
+
+func processEventView(event ProcessEvent) ProcessEventView {
+	view := ProcessEventView{
+		Type:      event.Type,
+		SubstepID: event.SubstepID,
+		At:        event.At.UTC().Format(time.RFC3339),
+		Detail:    event.Detail,
+	}
+	if event.Actor != nil {
+		view.ActorID = strings.TrimSpace(event.Actor.ID)
+		view.ActorRole = strings.TrimSpace(event.Actor.Role)
+	}
+	return view
+}
+
+// handleProcessEvents serves events.json: the chronological history of the
+// process, oldest first.
+func (s *Server) handleProcessEvents(w http.ResponseWriter, r *http.Request, processID string) {
+	workflowKey, _, ok := s.selectedWorkflowOrRedirectHome(w, r)
+	if !ok {
+		return
+	}
+	process, err := s.loadProcess(r.Context(), processID)
+	if err != nil || !s.processBelongsToWorkflow(process, workflowKey) {
+		http.NotFound(w, r)
+		return
+	}
+	events, err := s.store.ListProcessEvents(r.Context(), process.ID)
+	if err != nil {
+		logAndHTTPError(w, r, http.StatusInternalServerError, "failed to load events", err, "failed to list events for process %s", processID)
+		return
+	}
+	response := ProcessEventsResponse{ProcessID: process.ID.Hex(), Events: make([]ProcessEventView, 0, len(events))}
+	for _, event := range events {
+		response.Events = append(response.Events, processEventView(event))
+	}
+	writeJSON(w, response)
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestProcessServiceRecordsCompletionAndAmendmentEvents(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Date(2026, 2, 3, 9, 0, 0, 0, time.UTC)
+	processID := store.SeedProcess(Process{
+		WorkflowKey: "workflow",
+		CreatedAt:   now,
+		Status:      "active",
+		Progress:    map[string]ProcessStep{"1_1": {State: "pending"}},
+	})
+	process, _ := store.LoadProcessByID(context.Background(), processID)
+	cfg := testRuntimeConfig()
+	sub, _, err := findSubstep(cfg.Workflow, "1.1")
+	if err != nil {
+		t.Fatalf("findSubstep: %v", err)
+	}
+	service := &ProcessService{store: store}
+	actor := Actor{ID: "u1", Role: "dep1"}
+	process, err = service.CompleteSubstep(context.Background(), CompleteSubstepCmd{
+		Process:     process,
+		WorkflowKey: "workflow",
+		SubstepID:   "1.1",
+		Substep:     sub,
+		Actor:       actor,
+		Payload:     map[string]interface{}{"value": 1},
+		Config:      cfg,
+		Now:         now.Add(time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("CompleteSubstep: %v", err)
+	}
+	if _, err := service.AmendSubstep(context.Background(), AmendSubstepCmd{
+		Process:     process,
+		WorkflowKey: "workflow",
+		SubstepID:   "1.1",
+		Actor:       actor,
+		Payload:     map[string]interface{}{"value": 2},
+		Reason:      "typo",
+		Now:         now.Add(2 * time.Minute),
+	}); err != nil {
+		t.Fatalf("AmendSubstep: %v", err)
+	}
+
+	server := &Server{
+		store: store,
+		configProvider: func() (RuntimeConfig, error) {
+			return cfg, nil
+		},
+	}
+	req := httptest.NewRequest(http.MethodGet, "/process/"+processID.Hex()+"/events.json", nil)
+	rec := httptest.NewRecorder()
+	server.handleProcessEvents(rec, req, processID.Hex())
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var response ProcessEventsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if response.ProcessID != processID.Hex() || len(response.Events) != 2 {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+	completed, amended := response.Events[0], response.Events[1]
+	if completed.Type != processEventSubstepCompleted || completed.SubstepID != "1.1" || completed.ActorID != "u1" || completed.ActorRole != "dep1" {
+		t.Fatalf("unexpected completion event: %+v", completed)
+	}
+	if completed.Detail != digestPayload(map[string]interface{}{"value": 1}) || completed.At != "2026-02-03T09:01:00Z" {
+		t.Fatalf("unexpected completion event details: %+v", completed)
+	}
+	if amended.Type != processEventSubstepAmended || amended.Detail != "typo" || amended.At != "2026-02-03T09:02:00Z" {
+		t.Fatalf("unexpected amendment event: %+v", amended)
+	}
+}
+
+func TestHandleProcessEventsNotFound(t *testing.T) {
+	server := &Server{
+		store: NewMemoryStore(),
+		configProvider: func() (RuntimeConfig, error) {
+			return testRuntimeConfig(), nil
+		},
+	}
+	processID := primitive.NewObjectID().Hex()
+	req := httptest.NewRequest(http.MethodGet, "/process/"+processID+"/events.json", nil)
+	rec := httptest.NewRecorder()
+	server.handleProcessEvents(rec, req, processID)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestMemoryStoreProcessEventsOrderedAndPurgedWithWorkflow(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Date(2026, 2, 3, 9, 0, 0, 0, time.UTC)
+	processID := store.SeedProcess(Process{WorkflowKey: "workflow", CreatedAt: now})
+	_ = store.AppendProcessEvent(context.Background(), ProcessEvent{ProcessID: processID, Type: processEventTerminated, At: now.Add(time.Hour)})
+	_ = store.AppendProcessEvent(context.Background(), ProcessEvent{ProcessID: processID, Type: processEventStarted, At: now})
+	_ = store.AppendProcessEvent(context.Background(), ProcessEvent{ProcessID: primitive.NewObjectID(), Type: processEventStarted, At: now})
+
+	events, err := store.ListProcessEvents(context.Background(), processID)
+	if err != nil {
+		t.Fatalf("ListProcessEvents: %v", err)
+	}
+	if len(events) != 2 || events[0].Type != processEventStarted || events[1].Type != processEventTerminated {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+
+	if err := store.DeleteWorkflowData(context.Background(), "workflow"); err != nil {
+		t.Fatalf("DeleteWorkflowData: %v", err)
+	}
+	events, _ = store.ListProcessEvents(context.Background(), processID)
+	if len(events) != 0 {
+		t.Fatalf("expected events removed with workflow data, got %d", len(events))
+	}
+}
+
+func TestMongoStoreListProcessEventsSortsChronologically(t *testing.T) {
+	db := &fakeMongoDatabase{}
+	store := &MongoStore{dbPort: db}
+	processID := primitive.NewObjectID()
+	collection := db.Collection("process_events").(*fakeMongoCollection)
+	collection.findFn = func(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (mongoCursorPort, error) {
+		return &fakeCursor{}, nil
+	}
+
+	if err := store.AppendProcessEvent(context.Background(), ProcessEvent{ProcessID: processID, Type: processEventStarted}); err != nil {
+		t.Fatalf("AppendProcessEvent: %v", err)
+	}
+	if len(collection.insertDocuments) != 1 {
+		t.Fatalf("insert count = %d, want 1", len(collection.insertDocuments))
+	}
+	if inserted := collection.insertDocuments[0].(ProcessEvent); inserted.ID.IsZero() {
+		t.Fatal("expected event id to be assigned")
+	}
+
+	if _, err := store.ListProcessEvents(context.Background(), processID); err != nil {
+		t.Fatalf("ListProcessEvents: %v", err)
+	}
+	filter := collection.findFilters[0].(bson.M)
+	if filter["processId"] != processID {
+		t.Fatalf("unexpected filter: %#v", filter)
+	}
+	sortSpec, ok := collection.findOptionsCalls[0][0].Sort.(bson.D)
+	if !ok || len(sortSpec) != 2 || sortSpec[0].Key != "at" || sortSpec[1].Key != "_id" {
+		t.Fatalf("unexpected sort: %#v", collection.findOptionsCalls[0][0].Sort)
+	}
+
+	if err := store.EnsureProcessEventsIndex(context.Background()); err != nil {
+		t.Fatalf("EnsureProcessEventsIndex: %v", err)
+	}
+	keys, ok := collection.createIndexesModels[0][0].Keys.(bson.D)
+	if !ok || len(keys) != 3 || keys[0].Key != "processId" || keys[1].Key != sortSpec[0].Key || keys[2].Key != sortSpec[1].Key {
+		t.Fatalf("index keys = %#v, want processId then the list sort", collection.createIndexesModels)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// processExportMaxIDs caps one batch export; auditors with more processes
+// split the request.
+const processExportMaxIDs = 100
+
+// ProcessExportIndex is index.json of a batch export: one row per exported
+// process plus the requested ids that were left out and why.
+type ProcessExportIndex struct {
+	WorkflowKey string                     `json:"workflow_key"`
+	Processes   []ProcessExportIndexEntry  `json:"processes"`
+	Skipped     []ProcessExportSkippedItem `json:"skipped"`
+}
+
+type ProcessExportIndexEntry struct {
+	ProcessID  string `json:"process_id"`
+	File       string `json:"file"`
+	Status     string `json:"status"`
+	MerkleRoot string `json:"merkle_root"`
+	Signed     bool   `json:"signed"`
+}
+
+type ProcessExportSkippedItem struct {
+	ProcessID string `json:"process_id"`
+	Reason    string `json:"reason"`
+}
+
+// parseProcessExportIDs reads the ids form field, repeated or comma
+// separated, dropping blanks and duplicates while keeping request order.
+func parseProcessExportIDs(values []string) ([]string, error) {
+	seen := map[string]bool{}
+	var ids []string
+	for _, value := range values {
+		for _, id := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' || r == '\n' || r == '\t' }) {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, errors.New("at least one process id is required")
+	}
+	if len(ids) > processExportMaxIDs {
+		return nil, fmt.Errorf("at most %d process ids can be exported at once", processExportMaxIDs)
+	}
+	return ids, nil
+}
+
+// handleProcessesExport serves POST /my/streams/:key/processes/export: a zip
+// holding the notarized export of every requested process as {id}.json and
+// index.json. Ids that are unknown or belong to another workflow are skipped
+// and listed in the index instead of failing the batch.
+func (s *Server) handleProcessesExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, _, ok := s.requireAuthenticatedPost(w, r)
+	if !ok {
+		return
+	}
+	workflowKey, cfg, ok := s.selectedWorkflowOrRedirectHome(w, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+	ids, err := parseProcessExportIDs(r.PostForm["ids"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	index := ProcessExportIndex{WorkflowKey: workflowKey, Processes: []ProcessExportIndexEntry{}, Skipped: []ProcessExportSkippedItem{}}
+	var exports []NotarizedProcessExport
+	exported := map[string]bool{}
+	for _, id := range ids {
+		process, err := s.loadProcess(ctx, id)
+		if err != nil {
+			if !errors.Is(err, mongo.ErrNoDocuments) {
+				logRequestError(r, err, "failed to load process %s for export", id)
+			}
+			index.Skipped = append(index.Skipped, ProcessExportSkippedItem{ProcessID: id, Reason: "not found"})
+			continue
+		}
+		if !s.processBelongsToWorkflow(process, workflowKey) {
+			index.Skipped = append(index.Skipped, ProcessExportSkippedItem{ProcessID: id, Reason: "not in this workflow"})
+			continue
+		}
+		if !s.processVisible(user, cfg, process) {
+			index.Skipped = append(index.Skipped, ProcessExportSkippedItem{ProcessID: id, Reason: "forbidden"})
+			continue
+		}
+		if exported[process.ID.Hex()] {
+			continue
+		}
+		exported[process.ID.Hex()] = true
+		export, err := s.signNotarizedExport(buildNotarizedExport(cfg.Workflow, process))
+		if err != nil {
+			logAndHTTPError(w, r, http.StatusInternalServerError, "failed to sign export", err, "failed to sign notarized export for process %s", process.ID.Hex())
+			return
+		}
+		exports = append(exports, export)
+		index.Processes = append(index.Processes, ProcessExportIndexEntry{
+			ProcessID:  export.ProcessID,
+			File:       export.ProcessID + ".json",
+			Status:     deriveProcessStatus(cfg.Workflow, process),
+			MerkleRoot: export.Merkle.Root,
+			Signed:     export.Signature != nil,
+		})
+	}
+
+	filename := fmt.Sprintf("%s-processes-export.zip", workflowKey)
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	zipWriter := zip.NewWriter(w)
+	defer zipWriter.Close()
+	bundle := &bundleWriter{zip: zipWriter, modified: s.nowUTC()}
+	for _, export := range exports {
+		if err := bundle.writeJSON(export.ProcessID+".json", export); err != nil {
+			logRequestError(r, err, "failed to write export of process %s", export.ProcessID)
+			return
+		}
+	}
+	if err := bundle.writeJSON("index.json", index); err != nil {
+		logRequestError(r, err, "failed to write export index for workflow %s", workflowKey)
+	}
+}
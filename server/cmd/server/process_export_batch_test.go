@@ -0,0 +1,104 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseProcessExportIDs(t *testing.T) {
+	ids, err := parseProcessExportIDs([]string{"a, b", "b", " c "})
+	if err != nil || !reflect.DeepEqual(ids, []string{"a", "b", "c"}) {
+		t.Fatalf("ids = %v err = %v", ids, err)
+	}
+	if _, err := parseProcessExportIDs([]string{" , "}); err == nil {
+		t.Fatal("expected empty list to be rejected")
+	}
+	many := make([]string, processExportMaxIDs+1)
+	for idx := range many {
+		many[idx] = strconv.Itoa(idx)
+	}
+	if _, err := parseProcessExportIDs(many); err == nil {
+		t.Fatal("expected cap to be enforced")
+	}
+}
+
+func TestHandleProcessesExportZipsNotarizedExports(t *testing.T) {
+	now := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	first := store.SeedProcess(Process{WorkflowKey: "workflow", CreatedAt: now, Status: "active", Progress: map[string]ProcessStep{
+		"1_1": {State: "done", DoneAt: ptrTime(now), Data: map[string]interface{}{"value": "lot-1"}},
+	}})
+	second := store.SeedProcess(Process{WorkflowKey: "workflow", CreatedAt: now, Status: "active", Progress: map[string]ProcessStep{}})
+	foreign := store.SeedProcess(Process{WorkflowKey: "other", CreatedAt: now, Status: "active", Progress: map[string]ProcessStep{}})
+	cfg := testRuntimeConfig()
+	server := &Server{store: store, authorizer: fakeAuthorizer{}, now: func() time.Time { return now }}
+
+	post := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/processes/export", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req = req.WithContext(context.WithValue(req.Context(), workflowContextKey{}, workflowContextValue{Key: "workflow", Cfg: cfg}))
+		rec := httptest.NewRecorder()
+		server.handleProcessesExport(rec, req)
+		return rec
+	}
+
+	if rec := post(url.Values{}); rec.Code != http.StatusBadRequest {
+		t.Fatalf("empty ids status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	rec := post(url.Values{"ids": {first.Hex() + "," + second.Hex(), foreign.Hex(), "missing"}})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/zip" {
+		t.Fatalf("content-type = %q", got)
+	}
+	reader, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	if err != nil {
+		t.Fatalf("read zip: %v", err)
+	}
+	files := map[string][]byte{}
+	for _, file := range reader.File {
+		handle, err := file.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", file.Name, err)
+		}
+		files[file.Name], _ = io.ReadAll(handle)
+		handle.Close()
+	}
+	if len(files) != 3 || files[first.Hex()+".json"] == nil || files[second.Hex()+".json"] == nil {
+		t.Fatalf("zip entries = %v", reflect.ValueOf(files).MapKeys())
+	}
+
+	var export NotarizedProcessExport
+	if err := json.Unmarshal(files[first.Hex()+".json"], &export); err != nil {
+		t.Fatalf("decode export: %v", err)
+	}
+	firstProcess, _ := store.SnapshotProcess(first)
+	firstProcess.Progress = normalizeProgressKeys(firstProcess.Progress)
+	if want := buildNotarizedExport(cfg.Workflow, &firstProcess).Merkle.Root; export.Merkle.Root != want {
+		t.Fatalf("merkle root = %s, want %s", export.Merkle.Root, want)
+	}
+
+	var index ProcessExportIndex
+	if err := json.Unmarshal(files["index.json"], &index); err != nil {
+		t.Fatalf("decode index: %v", err)
+	}
+	if len(index.Processes) != 2 || index.Processes[0].ProcessID != first.Hex() || index.Processes[0].File != first.Hex()+".json" || index.Processes[0].MerkleRoot != export.Merkle.Root {
+		t.Fatalf("index processes = %#v", index.Processes)
+	}
+	wantSkipped := []ProcessExportSkippedItem{{ProcessID: foreign.Hex(), Reason: "not in this workflow"}, {ProcessID: "missing", Reason: "not found"}}
+	if !reflect.DeepEqual(index.Skipped, wantSkipped) {
+		t.Fatalf("skipped = %#v", index.Skipped)
+	}
+}
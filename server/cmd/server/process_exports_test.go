@@ -145,6 +145,55 @@ func TestHandleMerkleJSON(t *testing.T) {
 	}
 }
 
+func TestHandleMerkleRootTracksProgress(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Date(2026, 2, 3, 9, 0, 0, 0, time.UTC)
+	processID := store.SeedProcess(Process{CreatedAt: now, Status: "active", Progress: map[string]ProcessStep{}})
+	server := &Server{
+		store: store,
+		configProvider: func() (RuntimeConfig, error) {
+			return testRuntimeConfig(), nil
+		},
+	}
+	preview := func() MerkleRootPreview {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/process/"+processID.Hex()+"/merkle/root", nil)
+		rec := httptest.NewRecorder()
+		server.handleMerkleRoot(rec, req, processID.Hex())
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+		}
+		var response MerkleRootPreview
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		return response
+	}
+
+	before := preview()
+	if before.Root == "" || before.DoneCount != 0 || before.SubstepCount != len(orderedSubsteps(testRuntimeConfig().Workflow)) {
+		t.Fatalf("unexpected preview before completion: %#v", before)
+	}
+	process, _ := store.LoadProcessByID(context.Background(), processID)
+	process.Progress["1_1"] = ProcessStep{State: "done", DoneAt: ptrTime(now), DoneBy: &Actor{ID: "u1", Role: "dep1"}, Data: map[string]interface{}{"value": 1}}
+	store.SeedProcess(*process)
+
+	after := preview()
+	if after.DoneCount != 1 || after.Root == before.Root {
+		t.Fatalf("expected root to move after completion, before %#v after %#v", before, after)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/process/"+processID.Hex()+"/merkle.json", nil)
+	rec := httptest.NewRecorder()
+	server.handleMerkleJSON(rec, req, processID.Hex())
+	var tree MerkleTree
+	if err := json.Unmarshal(rec.Body.Bytes(), &tree); err != nil {
+		t.Fatalf("decode merkle.json: %v", err)
+	}
+	if after.Root != tree.Root {
+		t.Fatalf("root = %q, want merkle.json root %q", after.Root, tree.Root)
+	}
+}
+
 func TestHandleMerkleJSONErrors(t *testing.T) {
 	store := NewMemoryStore()
 	server := &Server{
@@ -177,6 +226,67 @@ func TestHandleMerkleJSONErrors(t *testing.T) {
 	}
 }
 
+func TestHandleExportsConditionalGET(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Date(2026, 2, 3, 9, 0, 0, 0, time.UTC)
+	processID := primitive.NewObjectID()
+	store.SeedProcess(Process{
+		ID:        processID,
+		CreatedAt: now,
+		Status:    "active",
+		Progress: map[string]ProcessStep{
+			"1_1": {State: "done", DoneAt: ptrTime(now), DoneBy: &Actor{ID: "u1", Role: "dep1"}, Data: map[string]interface{}{"value": 42}},
+		},
+	})
+	server := &Server{
+		store: store,
+		configProvider: func() (RuntimeConfig, error) {
+			return testRuntimeConfig(), nil
+		},
+	}
+
+	handlers := map[string]func(http.ResponseWriter, *http.Request, string){
+		"notarized.json": server.handleNotarizedJSON,
+		"merkle.json":    server.handleMerkleJSON,
+	}
+	for name, handle := range handlers {
+		t.Run(name, func(t *testing.T) {
+			path := "/process/" + processID.Hex() + "/" + name
+			rec := httptest.NewRecorder()
+			handle(rec, httptest.NewRequest(http.MethodGet, path, nil), processID.Hex())
+			etag := rec.Header().Get("ETag")
+			if rec.Code != http.StatusOK || etag == "" || !strings.HasPrefix(etag, `"`) {
+				t.Fatalf("status = %d etag = %q, want 200 with quoted etag", rec.Code, etag)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			req.Header.Set("If-None-Match", `"stale", W/`+etag)
+			rec = httptest.NewRecorder()
+			handle(rec, req, processID.Hex())
+			if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+				t.Fatalf("status = %d body = %q, want 304 with empty body", rec.Code, rec.Body.String())
+			}
+			if got := rec.Header().Get("ETag"); got != etag {
+				t.Fatalf("304 etag = %q, want %q", got, etag)
+			}
+
+			req = httptest.NewRequest(http.MethodGet, path, nil)
+			req.Header.Set("If-None-Match", `"stale"`)
+			rec = httptest.NewRecorder()
+			handle(rec, req, processID.Hex())
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d for stale etag", rec.Code, http.StatusOK)
+			}
+		})
+	}
+
+	active := notarizedExportETag(NotarizedProcessExport{Status: "active", Merkle: MerkleTree{Root: "abc"}})
+	terminated := notarizedExportETag(NotarizedProcessExport{Status: "terminated", Merkle: MerkleTree{Root: "abc"}, Termination: &NotarizedProcessTermination{Reason: "recall"}})
+	if active == terminated {
+		t.Fatalf("expected status change to alter notarized etag, got %q for both", active)
+	}
+}
+
 func TestHandleDownloadAllFilesZip(t *testing.T) {
 	store := NewMemoryStore()
 	now := time.Date(2026, 2, 3, 9, 0, 0, 0, time.UTC)
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestHandleListProcessesFiltersByParticipant(t *testing.T) {
+	now := time.Date(2026, 2, 4, 11, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	mine := store.SeedProcess(Process{WorkflowKey: "workflow", CreatedAt: now.Add(-2 * time.Hour), Status: "active", Progress: map[string]ProcessStep{}})
+	other := store.SeedProcess(Process{WorkflowKey: "workflow", CreatedAt: now.Add(-time.Hour), Status: "active", Progress: map[string]ProcessStep{}})
+	store.SeedProcess(Process{WorkflowKey: "other", CreatedAt: now, Status: "active", Participants: []string{"appwrite:user-1"}})
+	doneAt := now
+	if err := store.UpdateProcessProgress(context.Background(), mine, "workflow", "1.1", ProcessStep{State: "done", DoneAt: &doneAt, DoneBy: &Actor{ID: "appwrite:user-1"}}); err != nil {
+		t.Fatalf("UpdateProcessProgress: %v", err)
+	}
+	if err := store.UpdateProcessProgress(context.Background(), other, "workflow", "1.1", ProcessStep{State: "done", DoneAt: &doneAt, DoneBy: &Actor{ID: "appwrite:user-2"}}); err != nil {
+		t.Fatalf("UpdateProcessProgress: %v", err)
+	}
+
+	user := AccountUser{ID: primitive.NewObjectID(), IdentityUserID: "user-1", Email: "user@example.com", Status: "active"}
+	server := &Server{
+		store:       store,
+		authorizer:  fakeAuthorizer{},
+		identity:    testIdentityForSessions(now, map[string]AccountUser{"session-user": user}),
+		enforceAuth: true,
+		now:         func() time.Time { return now },
+	}
+	request := func(method, query string, withSession bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/processes"+query, nil)
+		req = req.WithContext(context.WithValue(req.Context(), workflowContextKey{}, workflowContextValue{
+			Key: "workflow",
+			Cfg: testRuntimeConfig(),
+		}))
+		if withSession {
+			req.AddCookie(&http.Cookie{Name: "attesta_session", Value: "session-user"})
+		}
+		rec := httptest.NewRecorder()
+		server.handleListProcesses(rec, req)
+		return rec
+	}
+
+	rec := request(http.MethodGet, "?participant=me", true)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var response ProcessListResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if response.WorkflowKey != "workflow" || len(response.Processes) != 1 || response.Processes[0].ProcessID != mine.Hex() {
+		t.Fatalf("response = %#v, want only %s", response, mine.Hex())
+	}
+	if response.Processes[0].URL != streamInstancePath("workflow", mine.Hex()) || response.Processes[0].Status != processStatusActive {
+		t.Fatalf("item = %#v", response.Processes[0])
+	}
+
+	rec = request(http.MethodGet, "", true)
+	response = ProcessListResponse{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(response.Processes) != 2 || response.Processes[0].ProcessID != other.Hex() {
+		t.Fatalf("unfiltered response = %#v", response)
+	}
+
+	if rec := request(http.MethodGet, "?participant=someone", true); rec.Code != http.StatusBadRequest {
+		t.Fatalf("unsupported filter status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if rec := request(http.MethodGet, "?participant=me", false); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("anonymous status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	if rec := request(http.MethodPost, "?participant=me", true); rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("post status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestHandleWorkflowHomeJSON(t *testing.T) {
+	now := time.Date(2026, 2, 4, 11, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	fresh := store.SeedProcess(Process{WorkflowKey: "workflow", Name: "Batch 1", CreatedAt: now.Add(-2 * time.Hour), Status: processStatusActive, Progress: map[string]ProcessStep{}})
+	midway := processWithDone("1.1", "1.2", "1.3")
+	midway.WorkflowKey = "workflow"
+	midway.CreatedAt = now.Add(-time.Hour)
+	midway.Status = processStatusActive
+	midwayID := store.SeedProcess(*midway)
+	done := processWithDone("1.1", "1.2", "1.3", "2.1", "2.2", "3.1", "3.2")
+	done.WorkflowKey = "workflow"
+	done.CreatedAt = now.Add(-3 * time.Hour)
+	done.Status = processStatusDone
+	doneID := store.SeedProcess(*done)
+	store.SeedProcess(Process{WorkflowKey: "workflow", CreatedAt: now, Status: processStatusTerminated, Termination: &ProcessTermination{Reason: "cancelled", EndedAt: now}})
+
+	server := &Server{store: store, authorizer: fakeAuthorizer{}, now: func() time.Time { return now }}
+	req := httptest.NewRequest(http.MethodGet, "/?format=json", nil)
+	req = req.WithContext(context.WithValue(req.Context(), workflowContextKey{}, workflowContextValue{
+		Key: "workflow",
+		Cfg: testRuntimeConfig(),
+	}))
+	rec := httptest.NewRecorder()
+	server.handleWorkflowHome(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var response StreamDashboardResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(response.ActiveProcesses) != 2 || response.ActiveProcesses[0].ProcessID != midwayID.Hex() {
+		t.Fatalf("active = %#v", response.ActiveProcesses)
+	}
+	if len(response.DoneProcesses) != 1 || response.DoneProcesses[0].ProcessID != doneID.Hex() {
+		t.Fatalf("done = %#v", response.DoneProcesses)
+	}
+	if len(response.TodoActions) != 2 {
+		t.Fatalf("todo = %#v, want one action per active process", response.TodoActions)
+	}
+	if got := response.TodoActions[0]; got.ProcessID != midwayID.Hex() || got.SubstepID != "2.1" || got.Title != "D" || len(got.Roles) != 1 || got.Roles[0] != "dep2" {
+		t.Fatalf("first todo = %#v", got)
+	}
+	if got := response.TodoActions[1]; got.ProcessID != fresh.Hex() || got.ProcessName != "Batch 1" || got.SubstepID != "1.1" || got.URL != streamInstancePath("workflow", fresh.Hex()) {
+		t.Fatalf("second todo = %#v", got)
+	}
+}
+
+func TestHandleWorkflowHomeJSONCapsLists(t *testing.T) {
+	now := time.Date(2026, 2, 4, 11, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	for i := 0; i < 5; i++ {
+		store.SeedProcess(Process{WorkflowKey: "workflow", CreatedAt: now.Add(time.Duration(i) * time.Minute), Status: processStatusActive, Progress: map[string]ProcessStep{}})
+	}
+	server := &Server{store: store, authorizer: fakeAuthorizer{}, dashboardLimit: 2, now: func() time.Time { return now }}
+	req := httptest.NewRequest(http.MethodGet, "/?format=json", nil)
+	req = req.WithContext(context.WithValue(req.Context(), workflowContextKey{}, workflowContextValue{
+		Key: "workflow",
+		Cfg: testRuntimeConfig(),
+	}))
+	rec := httptest.NewRecorder()
+	server.handleWorkflowHome(rec, req)
+
+	var response StreamDashboardResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode response: %v (%s)", err, rec.Body.String())
+	}
+	if !response.Truncated || len(response.ActiveProcesses) != 2 || response.ActiveTotal != 5 || len(response.TodoActions) != 2 || response.TodoTotal != 5 {
+		t.Fatalf("unexpected capped dashboard %#v", response)
+	}
+}
+
+func TestHandleStreamDashboardCounts(t *testing.T) {
+	now := time.Date(2026, 2, 4, 11, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	for i := 0; i < 3; i++ {
+		store.SeedProcess(Process{WorkflowKey: "workflow", CreatedAt: now.Add(time.Duration(i) * time.Minute), Status: processStatusActive, Progress: map[string]ProcessStep{}})
+	}
+	done := processWithDone("1.1", "1.2", "1.3", "2.1", "2.2", "3.1", "3.2")
+	done.WorkflowKey = "workflow"
+	done.CreatedAt = now.Add(-3 * time.Hour)
+	done.Status = processStatusDone
+	store.SeedProcess(*done)
+	store.SeedProcess(Process{WorkflowKey: "workflow", CreatedAt: now, Status: processStatusTerminated, Termination: &ProcessTermination{Reason: "cancelled", EndedAt: now}})
+
+	server := &Server{store: store, authorizer: fakeAuthorizer{}, now: func() time.Time { return now }}
+	request := func(method string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/dashboard/counts.json", nil)
+		req = req.WithContext(context.WithValue(req.Context(), workflowContextKey{}, workflowContextValue{
+			Key: "workflow",
+			Cfg: testRuntimeConfig(),
+		}))
+		rec := httptest.NewRecorder()
+		server.handleStreamDashboardCounts(rec, req)
+		return rec
+	}
+
+	rec := request(http.MethodGet)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var counts StreamDashboardCounts
+	if err := json.Unmarshal(rec.Body.Bytes(), &counts); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if counts != (StreamDashboardCounts{Todo: 3, Active: 3, Done: 1}) {
+		t.Fatalf("counts = %#v", counts)
+	}
+	if rec := request(http.MethodPost); rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("post status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestDashboardListLimitFromEnv(t *testing.T) {
+	t.Setenv("DASHBOARD_LIST_LIMIT", "")
+	if got := (&Server{dashboardLimit: dashboardListLimitFromEnv()}).dashboardListLimit(); got != dashboardListLimitDefault {
+		t.Fatalf("unset limit = %d, want %d", got, dashboardListLimitDefault)
+	}
+	t.Setenv("DASHBOARD_LIST_LIMIT", "0")
+	if got := (&Server{dashboardLimit: dashboardListLimitFromEnv()}).dashboardListLimit(); got != 0 {
+		t.Fatalf("disabled limit = %d, want 0", got)
+	}
+	t.Setenv("DASHBOARD_LIST_LIMIT", "7")
+	if got := (&Server{dashboardLimit: dashboardListLimitFromEnv()}).dashboardListLimit(); got != 7 {
+		t.Fatalf("configured limit = %d, want 7", got)
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Process metadata is operator bookkeeping (ticket ids, customer refs)
+// attached to a process. It is never part of a substep payload, so digests,
+// notarizations and the notarized export's Merkle tree ignore it.
+const (
+	processMetadataMaxKeys       = 50
+	processMetadataMaxValueRunes = 1024
+)
+
+var processMetadataKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+type ProcessMetadataEntry struct {
+	Key   string
+	Value string
+}
+
+type ProcessMetadataResponse struct {
+	ProcessID string            `json:"process_id"`
+	Metadata  map[string]string `json:"metadata"`
+}
+
+func sortedMetadataKeys(metadata map[string]string) []string {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func processMetadataEntries(metadata map[string]string) []ProcessMetadataEntry {
+	entries := make([]ProcessMetadataEntry, 0, len(metadata))
+	for _, key := range sortedMetadataKeys(metadata) {
+		entries = append(entries, ProcessMetadataEntry{Key: key, Value: metadata[key]})
+	}
+	return entries
+}
+
+func validateProcessMetadataKey(key string) error {
+	if !processMetadataKeyPattern.MatchString(key) {
+		return fmt.Errorf("invalid metadata key %q: use up to 64 letters, digits, - or _", key)
+	}
+	return nil
+}
+
+// parseProcessMetadataUpdate reads the repeated key and value fields of the
+// posted form as pairs; other fields are ignored and a blank value removes
+// the key. current is used to enforce processMetadataMaxKeys on the result.
+func parseProcessMetadataUpdate(form url.Values, current map[string]string) (map[string]string, []string, error) {
+	keys, values := form["key"], form["value"]
+	if len(keys) != len(values) {
+		return nil, nil, errors.New("every metadata key needs a value field")
+	}
+	set := map[string]string{}
+	unset := []string{}
+	seen := map[string]bool{}
+	count := len(current)
+	for idx, key := range keys {
+		key = strings.TrimSpace(key)
+		if err := validateProcessMetadataKey(key); err != nil {
+			return nil, nil, err
+		}
+		value := strings.TrimSpace(values[idx])
+		if len([]rune(value)) > processMetadataMaxValueRunes {
+			return nil, nil, fmt.Errorf("metadata %q is longer than %d characters", key, processMetadataMaxValueRunes)
+		}
+		if seen[key] {
+			return nil, nil, fmt.Errorf("metadata %q is given twice", key)
+		}
+		seen[key] = true
+		_, exists := current[key]
+		switch {
+		case value == "" && exists:
+			unset = append(unset, key)
+			count--
+		case value != "":
+			set[key] = value
+			if !exists {
+				count++
+			}
+		}
+	}
+	if count > processMetadataMaxKeys {
+		return nil, nil, fmt.Errorf("a process can hold at most %d metadata keys", processMetadataMaxKeys)
+	}
+	sort.Strings(unset)
+	return set, unset, nil
+}
+
+// parseProcessMetadataFilter reads the ?metadata=key:value filter of the
+// process list API. ok is false when no filter is given.
+func parseProcessMetadataFilter(raw string) (string, string, bool, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", "", false, nil
+	}
+	key, value, found := strings.Cut(raw, ":")
+	key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+	if !found || value == "" {
+		return "", "", false, errors.New("metadata filter must be key:value")
+	}
+	if err := validateProcessMetadataKey(key); err != nil {
+		return "", "", false, err
+	}
+	return key, value, true, nil
+}
+
+func filterProcessesByMetadata(processes []Process, key, value string) []Process {
+	filtered := make([]Process, 0, len(processes))
+	for _, process := range processes {
+		if current, ok := process.Metadata[key]; ok && current == value {
+			filtered = append(filtered, process)
+		}
+	}
+	return filtered
+}
+
+// handleUpdateProcessMetadata serves POST .../instance/:id/metadata. Paired
+// key and value fields set metadata keys and blank values remove them; the
+// progress, and so every digest, is untouched. HTMX requests from the process
+// page get the re-rendered content, other clients the resulting metadata.
+func (s *Server) handleUpdateProcessMetadata(w http.ResponseWriter, r *http.Request, processID string) {
+	user, _, ok := s.requireAuthenticatedPost(w, r)
+	if !ok {
+		return
+	}
+	workflowKey, cfg, ok := s.selectedWorkflowOrRedirectHome(w, r)
+	if !ok {
+		return
+	}
+	actor := actorFromAccountUser(user, workflowKey)
+	if len(actor.RoleSlugs) == 0 && !s.enforceAuth {
+		actor.RoleSlugs = s.roles(cfg)
+	}
+	fail := func(status int, message string, process *Process) {
+		if isHTMXRequest(r) {
+			s.renderActionErrorForRequest(w, r, status, message, process, actor)
+			return
+		}
+		http.Error(w, message, status)
+	}
+	ctx := r.Context()
+	process, err := s.loadProcess(ctx, processID)
+	if err != nil || !s.processBelongsToWorkflow(process, workflowKey) {
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			logRequestError(r, err, "failed to load process %s for metadata", processID)
+		}
+		fail(http.StatusNotFound, "process not found", nil)
+		return
+	}
+	if !s.canEditProcessMetadata(user, cfg, process) {
+		fail(http.StatusForbidden, "forbidden", process)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		fail(http.StatusBadRequest, "invalid form", process)
+		return
+	}
+	set, unset, err := parseProcessMetadataUpdate(r.PostForm, process.Metadata)
+	if err != nil {
+		fail(http.StatusBadRequest, err.Error(), process)
+		return
+	}
+	if err := s.store.UpdateProcessMetadata(ctx, process.ID, set, unset); err != nil {
+		logAndHTTPError(w, r, http.StatusInternalServerError, "failed to update metadata", err, "failed to update metadata of process %s", process.ID.Hex())
+		return
+	}
+
+	metadata := map[string]string{}
+	for key, value := range process.Metadata {
+		metadata[key] = value
+	}
+	for key, value := range set {
+		metadata[key] = value
+	}
+	for _, key := range unset {
+		delete(metadata, key)
+	}
+	if isHTMXRequest(r) {
+		if len(metadata) == 0 {
+			metadata = nil
+		}
+		process.Metadata = metadata
+		s.renderProcessContent(w, r, process, actor, "")
+		return
+	}
+	writeJSON(w, ProcessMetadataResponse{ProcessID: process.ID.Hex(), Metadata: metadata})
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseProcessMetadataUpdate(t *testing.T) {
+	current := map[string]string{"ticket": "T-1", "customer": "ACME"}
+	set, unset, err := parseProcessMetadataUpdate(url.Values{
+		"key":        {"ticket", "customer", "unknown"},
+		"value":      {" T-2 ", "", ""},
+		"activeRole": {"dep1"},
+	}, current)
+	if err != nil {
+		t.Fatalf("parseProcessMetadataUpdate: %v", err)
+	}
+	if !reflect.DeepEqual(set, map[string]string{"ticket": "T-2"}) || !reflect.DeepEqual(unset, []string{"customer"}) {
+		t.Fatalf("set = %#v unset = %#v", set, unset)
+	}
+	if set, unset, err := parseProcessMetadataUpdate(url.Values{"activeRole": {"dep1"}}, current); err != nil || len(set) != 0 || len(unset) != 0 {
+		t.Fatalf("unrelated field: set = %#v unset = %#v err = %v", set, unset, err)
+	}
+	if _, _, err := parseProcessMetadataUpdate(url.Values{"key": {"bad key"}, "value": {"x"}}, nil); err == nil {
+		t.Fatal("expected invalid key to be rejected")
+	}
+	if _, _, err := parseProcessMetadataUpdate(url.Values{"key": {"ticket", "customer"}, "value": {"x"}}, nil); err == nil {
+		t.Fatal("expected unpaired key to be rejected")
+	}
+	if _, _, err := parseProcessMetadataUpdate(url.Values{"key": {"ticket", "ticket"}, "value": {"x", "y"}}, nil); err == nil {
+		t.Fatal("expected repeated key to be rejected")
+	}
+	if _, _, err := parseProcessMetadataUpdate(url.Values{"key": {"note"}, "value": {strings.Repeat("x", processMetadataMaxValueRunes+1)}}, nil); err == nil {
+		t.Fatal("expected long value to be rejected")
+	}
+	full := map[string]string{}
+	for idx := 0; idx < processMetadataMaxKeys; idx++ {
+		full["k"+strings.Repeat("x", idx)] = "v"
+	}
+	if _, _, err := parseProcessMetadataUpdate(url.Values{"key": {"extra"}, "value": {"v"}}, full); err == nil {
+		t.Fatal("expected key limit to be enforced")
+	}
+}
+
+func TestHandleUpdateProcessMetadataLeavesNotarizedExportUntouched(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	doneAt := now.Add(-time.Hour)
+	store := NewMemoryStore()
+	processID := store.SeedProcess(Process{
+		WorkflowKey: "workflow",
+		CreatedAt:   now.Add(-2 * time.Hour),
+		Status:      "active",
+		Progress: map[string]ProcessStep{
+			"1_1": {State: "done", DoneAt: &doneAt, DoneBy: &Actor{ID: "u1", Role: "dep1"}, Data: map[string]interface{}{"value": "lot-42"}},
+		},
+	})
+	other := store.SeedProcess(Process{WorkflowKey: "workflow", CreatedAt: now, Status: "active", Progress: map[string]ProcessStep{}})
+	server := &Server{store: store, tmpl: testTemplates(), authorizer: fakeAuthorizer{}, now: func() time.Time { return now }}
+	cfg := testRuntimeConfig()
+	withWorkflow := func(req *http.Request) *http.Request {
+		return req.WithContext(context.WithValue(req.Context(), workflowContextKey{}, workflowContextValue{Key: "workflow", Cfg: cfg}))
+	}
+	post := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/instance/"+processID.Hex()+"/metadata", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		server.handleUpdateProcessMetadata(rec, withWorkflow(req), processID.Hex())
+		return rec
+	}
+
+	before, _ := store.SnapshotProcess(processID)
+	rootBefore := buildNotarizedExport(cfg.Workflow, &before).Merkle.Root
+
+	if rec := post(url.Values{"key": {"ticket id"}, "value": {"T-1"}}); rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid key status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	rec := post(url.Values{"key": {"ticket", "customer"}, "value": {"JIRA-981", "Acme Foods"}})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	var response ProcessMetadataResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if !reflect.DeepEqual(response.Metadata, map[string]string{"ticket": "JIRA-981", "customer": "Acme Foods"}) {
+		t.Fatalf("metadata = %#v", response.Metadata)
+	}
+	if rec := post(url.Values{"key": {"customer"}, "value": {""}}); rec.Code != http.StatusOK {
+		t.Fatalf("unset status = %d body = %s", rec.Code, rec.Body.String())
+	}
+
+	after, _ := store.SnapshotProcess(processID)
+	if !reflect.DeepEqual(after.Metadata, map[string]string{"ticket": "JIRA-981"}) {
+		t.Fatalf("stored metadata = %#v", after.Metadata)
+	}
+	if !reflect.DeepEqual(after.SearchText, []string{"lot-42", "JIRA-981"}) {
+		t.Fatalf("searchText = %#v, want the removed value dropped", after.SearchText)
+	}
+	if root := buildNotarizedExport(cfg.Workflow, &after).Merkle.Root; root != rootBefore {
+		t.Fatalf("merkle root changed from %s to %s", rootBefore, root)
+	}
+
+	list := func(query string) ProcessListResponse {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		server.handleListProcesses(rec, withWorkflow(httptest.NewRequest(http.MethodGet, "/processes"+query, nil)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("list status = %d body = %s", rec.Code, rec.Body.String())
+		}
+		var listed ProcessListResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &listed); err != nil {
+			t.Fatalf("decode list: %v", err)
+		}
+		return listed
+	}
+	if listed := list("?metadata=ticket:JIRA-981"); len(listed.Processes) != 1 || listed.Processes[0].ProcessID != processID.Hex() || listed.Processes[0].Metadata["ticket"] != "JIRA-981" {
+		t.Fatalf("filtered list = %#v", listed.Processes)
+	}
+	if listed := list(""); len(listed.Processes) != 2 {
+		t.Fatalf("unfiltered list = %#v, want %s and %s", listed.Processes, processID.Hex(), other.Hex())
+	}
+
+	searchRec := httptest.NewRecorder()
+	server.handleSearchProcesses(searchRec, withWorkflow(httptest.NewRequest(http.MethodGet, "/processes/search?q=jira", nil)))
+	var search ProcessSearchResponse
+	if err := json.Unmarshal(searchRec.Body.Bytes(), &search); err != nil {
+		t.Fatalf("decode search: %v (%s)", err, searchRec.Body.String())
+	}
+	want := []ProcessSearchMatch{{MetadataKey: "ticket", Title: "ticket", Match: "JIRA", After: "-981"}}
+	if len(search.Results) != 1 || !reflect.DeepEqual(search.Results[0].Matches, want) {
+		t.Fatalf("search results = %#v", search.Results)
+	}
+}
+
+func TestHandleUpdateProcessMetadataRequiresProcessAccess(t *testing.T) {
+	store := NewMemoryStore()
+	processID := store.SeedProcess(Process{WorkflowKey: "workflow", CreatedBy: appwriteActorID("creator-1"), Status: "active", Progress: map[string]ProcessStep{}})
+	currentUser := IdentityUser{ID: "outsider-1", Email: "outsider@example.com", OrgSlug: "org2"}
+	server := &Server{
+		store: store,
+		tmpl:  testTemplates(),
+		identity: &fakeIdentityStore{
+			getSessionFunc: func(ctx context.Context, sessionSecret string) (IdentitySession, error) {
+				return IdentitySession{Secret: sessionSecret, ExpiresAt: time.Now().UTC().Add(time.Hour)}, nil
+			},
+			getCurrentUserFunc: func(ctx context.Context, sessionSecret string) (IdentityUser, error) {
+				return currentUser, nil
+			},
+		},
+		authorizer:  fakeAuthorizer{},
+		enforceAuth: true,
+	}
+	cfg := testRuntimeConfig()
+	post := func() int {
+		form := url.Values{"key": {"ticket"}, "value": {"T-1"}}
+		req := httptest.NewRequest(http.MethodPost, "/instance/"+processID.Hex()+"/metadata", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(&http.Cookie{Name: "attesta_session", Value: "session-1"})
+		req = req.WithContext(context.WithValue(req.Context(), workflowContextKey{}, workflowContextValue{Key: "workflow", Cfg: cfg}))
+		rec := httptest.NewRecorder()
+		server.handleUpdateProcessMetadata(rec, req, processID.Hex())
+		return rec.Code
+	}
+
+	if code := post(); code != http.StatusForbidden {
+		t.Fatalf("outsider status = %d, want %d", code, http.StatusForbidden)
+	}
+	if stored, _ := store.SnapshotProcess(processID); stored.Metadata != nil {
+		t.Fatalf("metadata = %#v, want untouched", stored.Metadata)
+	}
+	currentUser = IdentityUser{ID: "creator-1", Email: "creator@example.com", OrgSlug: "org2"}
+	if code := post(); code != http.StatusOK {
+		t.Fatalf("creator status = %d, want %d", code, http.StatusOK)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"html/template"
 	"net/http"
 	"net/http/httptest"
@@ -341,6 +342,57 @@ func TestHandleProcessDownloadsPartialExcludesDPPSection(t *testing.T) {
 	}
 }
 
+func TestHandleProcessDownloadsPartialShowsAttachmentTotals(t *testing.T) {
+	t.Setenv("ATTACHMENT_ZIP_WARN_BYTES", "1000")
+	store := NewMemoryStore()
+	processID := primitive.NewObjectID()
+	stored, err := store.SaveAttachment(t.Context(), AttachmentUpload{ProcessID: processID, SubstepID: "1.3", Filename: "legacy.pdf", MaxBytes: 1 << 20}, strings.NewReader(strings.Repeat("x", 600)))
+	if err != nil {
+		t.Fatalf("SaveAttachment: %v", err)
+	}
+	store.SeedProcess(Process{
+		ID:          processID,
+		WorkflowKey: "workflow",
+		CreatedAt:   time.Now().UTC(),
+		Status:      "done",
+		Progress: map[string]ProcessStep{
+			"1_1": {State: "done", Data: map[string]interface{}{"value": 10}},
+			"1_3": {State: "done", Data: map[string]interface{}{
+				"legacy": map[string]interface{}{"attachmentId": stored.ID.Hex(), "filename": "legacy.pdf"},
+				"report": map[string]interface{}{"attachmentId": primitive.NewObjectID().Hex(), "filename": "report.pdf", "size": int64(2048)},
+			}},
+		},
+	})
+	server := &Server{
+		store: store,
+		tmpl:  parseTestTemplates(t),
+		configProvider: func() (RuntimeConfig, error) {
+			return testRuntimeConfig(), nil
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/instance/"+processID.Hex()+"/downloads", nil)
+	rr := httptest.NewRecorder()
+	server.handleProcessRoutes(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	body := rr.Body.String()
+	if !strings.Contains(body, "Attachments (2, 2.6 KiB)") {
+		t.Fatalf("expected attachment count and total size, got %q", body)
+	}
+	if !strings.Contains(body, "large zip (2.6 KiB)") {
+		t.Fatalf("expected large zip warning, got %q", body)
+	}
+
+	t.Setenv("ATTACHMENT_ZIP_WARN_BYTES", "10000")
+	rr = httptest.NewRecorder()
+	server.handleProcessRoutes(rr, httptest.NewRequest(http.MethodGet, "/instance/"+processID.Hex()+"/downloads", nil))
+	if strings.Contains(rr.Body.String(), "large zip") {
+		t.Fatalf("expected no warning below threshold, got %q", rr.Body.String())
+	}
+}
+
 func TestHandleProcessDownloadsPartialBackfillsDPPForDoneProcess(t *testing.T) {
 	store := NewMemoryStore()
 	processID := primitive.NewObjectID()
@@ -509,3 +561,47 @@ func seedProcessWithPending(store *MemoryStore) primitive.ObjectID {
 	}
 	return store.SeedProcess(process)
 }
+
+func TestHandleProcessAvailabilityMapsEverySubstep(t *testing.T) {
+	store := NewMemoryStore()
+	processID := store.SeedProcess(Process{
+		ID:          primitive.NewObjectID(),
+		WorkflowKey: "workflow",
+		Status:      "active",
+		Progress: map[string]ProcessStep{
+			"1_1": {State: "done"},
+			"1_2": {State: "pending"},
+		},
+	})
+	server := &Server{
+		store: store,
+		configProvider: func() (RuntimeConfig, error) {
+			return testRuntimeConfig(), nil
+		},
+	}
+
+	rec := httptest.NewRecorder()
+	server.handleProcessRoutes(rec, httptest.NewRequest(http.MethodGet, "/instance/"+processID.Hex()+"/availability.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var states map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &states); err != nil {
+		t.Fatalf("decode availability: %v", err)
+	}
+	want := map[string]string{"1.1": "done", "1.2": "available", "1.3": "locked", "2.1": "locked", "2.2": "locked", "3.1": "locked", "3.2": "locked"}
+	if len(states) != len(want) {
+		t.Fatalf("states = %v, want %v", states, want)
+	}
+	for id, state := range want {
+		if states[id] != state {
+			t.Fatalf("state of %s = %q, want %q (all: %v)", id, states[id], state, states)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	server.handleProcessRoutes(rec, httptest.NewRequest(http.MethodGet, "/instance/"+primitive.NewObjectID().Hex()+"/availability.json", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("missing process status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
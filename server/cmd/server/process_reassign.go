@@ -0,0 +1,165 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const processWorkflowAdminPrefix = "/admin/process/"
+
+type ProcessWorkflowReassignResponse struct {
+	ProcessID       string   `json:"process_id"`
+	FromWorkflowKey string   `json:"from_workflow_key"`
+	WorkflowKey     string   `json:"workflow_key"`
+	Code            string   `json:"code,omitempty"`
+	MissingSubsteps []string `json:"missing_substeps,omitempty"`
+	Error           string   `json:"error,omitempty"`
+}
+
+// missingSubstepsForMove lists the substep ids process depends on that target
+// does not define: every substep of the current workflow (when it still
+// loads) plus any recorded progress or override key. An empty result means
+// the process can move without orphaning progress.
+func missingSubstepsForMove(process *Process, current *RuntimeConfig, target RuntimeConfig) []string {
+	defined := map[string]struct{}{}
+	for _, sub := range orderedSubsteps(target.Workflow) {
+		defined[strings.TrimSpace(sub.SubstepID)] = struct{}{}
+	}
+	needed := map[string]struct{}{}
+	if current != nil {
+		for _, sub := range orderedSubsteps(current.Workflow) {
+			needed[strings.TrimSpace(sub.SubstepID)] = struct{}{}
+		}
+	}
+	for key := range normalizeProgressKeys(process.Progress) {
+		needed[key] = struct{}{}
+	}
+	for key := range normalizeSubstepOverrideKeys(process.Overrides) {
+		needed[key] = struct{}{}
+	}
+	missing := []string{}
+	for id := range needed {
+		if _, ok := defined[id]; !ok && id != "" {
+			missing = append(missing, id)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// handleProcessWorkflowReassign serves POST /admin/process/:id/workflow for
+// platform admins: it moves a process created under the wrong workflow to
+// the workflow named by the workflow_key form value. Moves that would leave
+// progress keys unresolved answer 409 with the missing substep ids. The
+// process takes a new code from the target workflow's sequence, or loses its
+// code when the target has no processCodePrefix.
+func (s *Server) handleProcessWorkflowReassign(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, processWorkflowAdminPrefix), "/"), "/")
+	if len(parts) != 2 || parts[1] != "workflow" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, _, ok := s.requireAuthenticatedPost(w, r)
+	if !ok {
+		return
+	}
+	if s.enforceAuth {
+		allowed, err := s.canAccessPlatformAdminConsole(r.Context(), user)
+		if err != nil {
+			status, message := authorizerErrorResponse(err)
+			logAndHTTPError(w, r, status, message, err, "cerbos check failed for process workflow reassignment")
+			return
+		}
+		if !allowed {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+	}
+	processID, err := primitive.ObjectIDFromHex(strings.TrimSpace(parts[0]))
+	if err != nil {
+		http.Error(w, "invalid process id", http.StatusBadRequest)
+		return
+	}
+	targetKey := strings.TrimSpace(r.FormValue("workflow_key"))
+	if targetKey == "" {
+		http.Error(w, "workflow_key is required", http.StatusBadRequest)
+		return
+	}
+	target, err := s.workflowByKey(targetKey)
+	if err != nil {
+		http.Error(w, "unknown workflow", http.StatusNotFound)
+		return
+	}
+	process, err := s.store.LoadProcessByID(r.Context(), processID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		logAndHTTPError(w, r, http.StatusInternalServerError, "failed to load process", err, "failed to load process %s for workflow reassignment", processID.Hex())
+		return
+	}
+	fromKey := strings.TrimSpace(process.WorkflowKey)
+	response := ProcessWorkflowReassignResponse{
+		ProcessID:       processID.Hex(),
+		FromWorkflowKey: fromKey,
+		WorkflowKey:     targetKey,
+	}
+	if fromKey == targetKey {
+		writeJSON(w, response)
+		return
+	}
+	var current *RuntimeConfig
+	if cfg, err := s.workflowByKey(fromKey); err == nil {
+		current = &cfg
+	}
+	if missing := missingSubstepsForMove(process, current, target); len(missing) > 0 {
+		response.MissingSubsteps = missing
+		response.Error = "target workflow is missing substeps used by this process"
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		writeJSON(w, response)
+		return
+	}
+	moved := Process{WorkflowKey: targetKey, CreatedAt: process.CreatedAt}
+	if err := s.assignProcessCode(r.Context(), target.Workflow, &moved); err != nil {
+		logAndHTTPError(w, r, http.StatusInternalServerError, "failed to assign process code", err, "failed to assign code in workflow %s to process %s", targetKey, processID.Hex())
+		return
+	}
+	response.Code = moved.Code
+	if err := s.store.UpdateProcessWorkflowKey(r.Context(), processID, targetKey, moved.Code); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.NotFound(w, r)
+			return
+		}
+		if errors.Is(err, ErrProcessCodeConflict) {
+			response.Error = "process code " + moved.Code + " is already used in the target workflow"
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusConflict)
+			writeJSON(w, response)
+			return
+		}
+		logAndHTTPError(w, r, http.StatusInternalServerError, "failed to update process", err, "failed to move process %s to workflow %s", processID.Hex(), targetKey)
+		return
+	}
+	log.Printf("process %s moved from workflow %s to %s by %s", processID.Hex(), fromKey, targetKey, accountActorID(user))
+	appendProcessEvent(r.Context(), s.store, ProcessEvent{
+		ProcessID:   processID,
+		WorkflowKey: targetKey,
+		Type:        processEventWorkflowChanged,
+		Actor:       &Actor{ID: accountActorID(user)},
+		At:          s.nowUTC(),
+		Detail:      fromKey + " -> " + targetKey,
+	})
+	writeJSON(w, response)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestHandleProcessWorkflowReassignChecksSubsteps(t *testing.T) {
+	now := time.Date(2026, 2, 4, 11, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	writeTwoSubstepWorkflowConfig(t, filepath.Join(dir, "alpha.yaml"), "Alpha")
+	writeTwoSubstepWorkflowConfig(t, filepath.Join(dir, "beta.yaml"), "Beta")
+	writeWorkflowConfig(t, filepath.Join(dir, "gamma.yaml"), "Gamma", "string")
+
+	store := NewMemoryStore()
+	doneAt := now
+	processID := store.SeedProcess(Process{
+		WorkflowKey: "alpha",
+		CreatedAt:   now,
+		Status:      processStatusActive,
+		Progress:    map[string]ProcessStep{"1_1": {State: "done", DoneAt: &doneAt}},
+	})
+	server := &Server{store: store, configDir: dir, authorizer: fakeAuthorizer{}, now: func() time.Time { return now }}
+	mux := server.newMux()
+	move := func(id, target string) *httptest.ResponseRecorder {
+		form := url.Values{"workflow_key": {target}}
+		req := httptest.NewRequest(http.MethodPost, processWorkflowAdminPrefix+id+"/workflow", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := move(processID.Hex(), "gamma")
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("incompatible move status = %d, want %d: %s", rec.Code, http.StatusConflict, rec.Body.String())
+	}
+	var response ProcessWorkflowReassignResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if strings.Join(response.MissingSubsteps, ",") != "1.2" {
+		t.Fatalf("missing = %v, want [1.2]", response.MissingSubsteps)
+	}
+	if snapshot, _ := store.SnapshotProcess(processID); snapshot.WorkflowKey != "alpha" {
+		t.Fatalf("rejected move changed workflow to %q", snapshot.WorkflowKey)
+	}
+
+	rec = move(processID.Hex(), "beta")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("move status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if snapshot, _ := store.SnapshotProcess(processID); snapshot.WorkflowKey != "beta" {
+		t.Fatalf("workflow key = %q, want beta", snapshot.WorkflowKey)
+	}
+	events, err := store.ListProcessEvents(context.Background(), processID)
+	if err != nil || len(events) != 1 || events[0].Type != processEventWorkflowChanged || events[0].Detail != "alpha -> beta" {
+		t.Fatalf("events = %#v, %v", events, err)
+	}
+
+	if rec := move(processID.Hex(), "missing"); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown workflow status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if rec := move(primitive.NewObjectID().Hex(), "alpha"); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown process status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, processWorkflowAdminPrefix+processID.Hex()+"/workflow", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("GET status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestHandleProcessWorkflowReassignRenumbersCode(t *testing.T) {
+	now := time.Date(2026, 2, 4, 11, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	writeTwoSubstepWorkflowConfig(t, filepath.Join(dir, "alpha.yaml"), "Alpha")
+	betaPath := filepath.Join(dir, "beta.yaml")
+	writeTwoSubstepWorkflowConfig(t, betaPath, "Beta")
+	data, err := os.ReadFile(betaPath)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	if err := os.WriteFile(betaPath, []byte(strings.Replace(string(data), "  steps:\n", "  processCodePrefix: BET\n  steps:\n", 1)), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	store := NewMemoryStore()
+	first := store.SeedProcess(Process{WorkflowKey: "alpha", Code: "ALP-2026-000001", CreatedAt: now, Status: processStatusActive})
+	second := store.SeedProcess(Process{WorkflowKey: "alpha", Code: "ALP-2026-000002", CreatedAt: now, Status: processStatusActive})
+	store.SeedProcess(Process{WorkflowKey: "beta", Code: "BET-2026-000002", CreatedAt: now, Status: processStatusActive})
+	server := &Server{store: store, configDir: dir, authorizer: fakeAuthorizer{}, now: func() time.Time { return now }}
+	mux := server.newMux()
+	move := func(id primitive.ObjectID, target string) (*httptest.ResponseRecorder, ProcessWorkflowReassignResponse) {
+		form := url.Values{"workflow_key": {target}}
+		req := httptest.NewRequest(http.MethodPost, processWorkflowAdminPrefix+id.Hex()+"/workflow", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		var response ProcessWorkflowReassignResponse
+		_ = json.Unmarshal(rec.Body.Bytes(), &response)
+		return rec, response
+	}
+
+	rec, response := move(first, "beta")
+	if rec.Code != http.StatusOK || response.Code != "BET-2026-000001" {
+		t.Fatalf("move to beta status = %d response = %#v", rec.Code, response)
+	}
+	if snapshot, _ := store.SnapshotProcess(first); snapshot.Code != "BET-2026-000001" {
+		t.Fatalf("stored code = %q, want BET-2026-000001", snapshot.Code)
+	}
+
+	rec, response = move(second, "beta")
+	if rec.Code != http.StatusConflict || !strings.Contains(response.Error, "BET-2026-000002") {
+		t.Fatalf("conflicting code status = %d response = %#v", rec.Code, response)
+	}
+	if snapshot, _ := store.SnapshotProcess(second); snapshot.WorkflowKey != "alpha" || snapshot.Code != "ALP-2026-000002" {
+		t.Fatalf("rejected move changed process to %q/%q", snapshot.WorkflowKey, snapshot.Code)
+	}
+
+	rec, response = move(first, "alpha")
+	if rec.Code != http.StatusOK || response.Code != "" {
+		t.Fatalf("move to alpha status = %d response = %#v", rec.Code, response)
+	}
+	if snapshot, _ := store.SnapshotProcess(first); snapshot.Code != "" {
+		t.Fatalf("expected code cleared in a workflow without prefix, got %q", snapshot.Code)
+	}
+}
@@ -53,7 +53,10 @@ func TestHandleProcessRoutesDispatchesEndpoints(t *testing.T) {
 		{name: "process content", method: http.MethodGet, path: "/instance/" + process.ID.Hex() + "/content", wantStatus: http.StatusOK, wantBody: "PROCESS_CONTENT"},
 		{name: "notarized export", method: http.MethodGet, path: "/instance/" + process.ID.Hex() + "/notarized.json", wantStatus: http.StatusOK},
 		{name: "merkle export", method: http.MethodGet, path: "/instance/" + process.ID.Hex() + "/merkle.json", wantStatus: http.StatusOK},
+		{name: "merkle root preview", method: http.MethodGet, path: "/instance/" + process.ID.Hex() + "/merkle/root", wantStatus: http.StatusOK},
 		{name: "all files zip", method: http.MethodGet, path: "/instance/" + process.ID.Hex() + "/files.zip", wantStatus: http.StatusOK},
+		{name: "export bundle zip", method: http.MethodGet, path: "/instance/" + process.ID.Hex() + "/bundle.zip", wantStatus: http.StatusOK},
+		{name: "event history", method: http.MethodGet, path: "/instance/" + process.ID.Hex() + "/events.json", wantStatus: http.StatusOK},
 		{name: "complete substep", method: http.MethodPost, path: "/instance/" + process.ID.Hex() + "/substep/1.1/complete", body: "value=%7B%22status%22%3A%22ok%22%7D", wantStatus: http.StatusOK, wantBody: "PROCESS " + process.ID.Hex()},
 	}
 
@@ -243,8 +246,8 @@ func TestHandleWorkflowRoutesRedirectsInvalidWorkflowToWorkflowHome(t *testing.T
 	if parsed.Path != "/my/streams/workflow/" {
 		t.Fatalf("redirect path = %q, want %q", parsed.Path, "/my/streams/workflow/")
 	}
-	if got := parsed.Query().Get("error"); !strings.Contains(got, "workflow references are invalid") {
-		t.Fatalf("redirect error = %q, want workflow validation message", got)
+	if got := parsed.Query().Get("error"); got != workflowUnavailableMessage {
+		t.Fatalf("redirect error = %q, want %q", got, workflowUnavailableMessage)
 	}
 }
 
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ProcessSequenceView is one workflow's process counter in
+// /admin/sequences. Value is the last number handed out; NextCode is the code
+// the next process would get when the workflow has a processCodePrefix.
+type ProcessSequenceView struct {
+	WorkflowKey  string `json:"workflow_key"`
+	WorkflowName string `json:"workflow_name,omitempty"`
+	Prefix       string `json:"prefix,omitempty"`
+	Value        int64  `json:"value"`
+	NextCode     string `json:"next_code,omitempty"`
+}
+
+type ProcessSequencesResponse struct {
+	Sequences []ProcessSequenceView `json:"sequences"`
+}
+
+func (s *Server) processSequenceView(workflowKey string, cfg RuntimeConfig, known bool, value int64) ProcessSequenceView {
+	view := ProcessSequenceView{WorkflowKey: workflowKey, Value: value}
+	if known {
+		view.WorkflowName = cfg.Workflow.Name
+		view.Prefix = cfg.Workflow.ProcessCodePrefix
+	}
+	if view.Prefix != "" {
+		view.NextCode = formatProcessCode(view.Prefix, s.nowUTC(), value+1)
+	}
+	return view
+}
+
+// handleAdminSequences serves the platform admin counter console:
+// GET /admin/sequences lists the process counter of every catalog workflow
+// plus any counter left by a removed workflow, and
+// POST /admin/sequences/:workflowKey/set (form value=N) moves one forward.
+func (s *Server) handleAdminSequences(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/admin/sequences"), "/")
+	switch {
+	case path == "" && r.Method == http.MethodGet:
+		if _, ok := s.requirePlatformAdmin(w, r); !ok {
+			return
+		}
+		s.listProcessSequences(w, r)
+	case strings.HasSuffix(path, "/set") && r.Method == http.MethodPost:
+		if _, ok := s.requirePlatformAdmin(w, r); !ok {
+			return
+		}
+		s.setProcessSequence(w, r, strings.TrimSuffix(path, "/set"))
+	case path == "" || strings.HasSuffix(path, "/set"):
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) listProcessSequences(w http.ResponseWriter, r *http.Request) {
+	catalog, err := s.workflowCatalog()
+	if err != nil {
+		logAndHTTPError(w, r, http.StatusInternalServerError, "workflow catalog unavailable", err, "load workflow catalog for sequences")
+		return
+	}
+	values, err := s.store.ListProcessSequences(r.Context())
+	if err != nil {
+		logAndHTTPError(w, r, http.StatusInternalServerError, "sequences unavailable", err, "list process sequences")
+		return
+	}
+	keys := make([]string, 0, len(catalog)+len(values))
+	for key := range catalog {
+		keys = append(keys, key)
+	}
+	for key := range values {
+		if _, ok := catalog[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	response := ProcessSequencesResponse{Sequences: make([]ProcessSequenceView, 0, len(keys))}
+	for _, key := range keys {
+		cfg, known := catalog[key]
+		response.Sequences = append(response.Sequences, s.processSequenceView(key, cfg, known, values[key]))
+	}
+	writeJSON(w, response)
+}
+
+func (s *Server) setProcessSequence(w http.ResponseWriter, r *http.Request, workflowKey string) {
+	workflowKey = strings.TrimSpace(workflowKey)
+	cfg, err := s.workflowByKey(workflowKey)
+	if workflowKey == "" || strings.Contains(workflowKey, "/") || err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	value, err := strconv.ParseInt(strings.TrimSpace(r.FormValue("value")), 10, 64)
+	if err != nil || value < 0 {
+		http.Error(w, "value must be a non-negative integer", http.StatusBadRequest)
+		return
+	}
+	latest, err := s.store.LoadLatestProcessByWorkflow(r.Context(), workflowKey)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		logAndHTTPError(w, r, http.StatusInternalServerError, "sequence update failed", err, "load latest process of %s", workflowKey)
+		return
+	}
+	// The counter may have been reset or restored from an older backup, so
+	// the codes already issued bound the value as well.
+	if latest != nil {
+		if used, ok := processCodeSequence(latest.Code); ok && value < used {
+			http.Error(w, "value is below the current sequence", http.StatusConflict)
+			return
+		}
+	}
+	if err := s.store.SetProcessSequence(r.Context(), workflowKey, value); err != nil {
+		if errors.Is(err, ErrSequenceBelowCurrent) {
+			http.Error(w, "value is below the current sequence", http.StatusConflict)
+			return
+		}
+		logAndHTTPError(w, r, http.StatusInternalServerError, "sequence update failed", err, "set process sequence of %s", workflowKey)
+		return
+	}
+	writeJSON(w, s.processSequenceView(workflowKey, cfg, true, value))
+}
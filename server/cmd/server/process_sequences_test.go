@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestMemoryStoreSetProcessSequenceRejectsLowering(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	store.NextProcessSequence(ctx, "a")
+	store.NextProcessSequence(ctx, "a")
+	if err := store.SetProcessSequence(ctx, "a", 1); !errors.Is(err, ErrSequenceBelowCurrent) {
+		t.Fatalf("lowering: expected ErrSequenceBelowCurrent, got %v", err)
+	}
+	if err := store.SetProcessSequence(ctx, "a", 40); err != nil {
+		t.Fatalf("raise: %v", err)
+	}
+	if got, _ := store.NextProcessSequence(ctx, "a"); got != 41 {
+		t.Fatalf("next after raise = %d, want 41", got)
+	}
+	if err := store.SetProcessSequence(ctx, "b", 7); err != nil {
+		t.Fatalf("new counter: %v", err)
+	}
+	if got, _ := store.ListProcessSequences(ctx); len(got) != 2 || got["a"] != 41 || got["b"] != 7 {
+		t.Fatalf("sequences = %v", got)
+	}
+}
+
+func TestMongoStoreSetProcessSequenceUsesMaxUpsert(t *testing.T) {
+	current := int64(0)
+	found := false
+	collection := &fakeMongoCollection{
+		findOneAndUpdateFn: func(ctx context.Context, filter interface{}, update interface{}, opts ...*options.FindOneAndUpdateOptions) mongoSingleResultPort {
+			if !found {
+				return fakeSingleResult{err: mongo.ErrNoDocuments}
+			}
+			return fakeSingleResult{decodeFn: func(v interface{}) error {
+				raw, err := bson.Marshal(bson.M{"seq": current})
+				if err != nil {
+					return err
+				}
+				return bson.Unmarshal(raw, v)
+			}}
+		},
+	}
+	store := &MongoStore{dbPort: &fakeMongoDatabase{collections: map[string]*fakeMongoCollection{"counters": collection}}}
+	ctx := context.Background()
+
+	if err := store.SetProcessSequence(ctx, "a", 7); err != nil {
+		t.Fatalf("new counter: %v", err)
+	}
+	found, current = true, 9
+	if err := store.SetProcessSequence(ctx, "a", 5); !errors.Is(err, ErrSequenceBelowCurrent) {
+		t.Fatalf("lowering: expected ErrSequenceBelowCurrent, got %v", err)
+	}
+	if err := store.SetProcessSequence(ctx, "a", 9); err != nil {
+		t.Fatalf("same value: %v", err)
+	}
+	if len(collection.findOneFilters) != 0 || len(collection.insertDocuments) != 0 || len(collection.updateOneFilters) != 0 {
+		t.Fatalf("expected a single FindOneAndUpdate per call")
+	}
+	if update, ok := collection.findOneAndUpdUpdate[0].(bson.M); !ok || update["$max"] == nil {
+		t.Fatalf("update = %#v, want $max", collection.findOneAndUpdUpdate[0])
+	}
+}
+
+func TestMongoStoreListProcessSequencesReportsCursorError(t *testing.T) {
+	cursorErr := errors.New("cursor interrupted")
+	collection := &fakeMongoCollection{
+		findFn: func(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (mongoCursorPort, error) {
+			return &fakeAnyCursor{err: cursorErr}, nil
+		},
+	}
+	store := &MongoStore{dbPort: &fakeMongoDatabase{collections: map[string]*fakeMongoCollection{"counters": collection}}}
+	if _, err := store.ListProcessSequences(context.Background()); !errors.Is(err, cursorErr) {
+		t.Fatalf("expected cursor error, got %v", err)
+	}
+}
+
+func TestHandleAdminSequences(t *testing.T) {
+	t.Setenv("ADMIN_EMAIL", "admin@example.com")
+	t.Setenv("ADMIN_PASSWORD", "change-me")
+
+	tempDir := t.TempDir()
+	writeWorkflowConfig(t, filepath.Join(tempDir, "workflow.yaml"), "Main workflow", "string")
+	store := NewMemoryStore()
+	store.NextProcessSequence(context.Background(), "workflow")
+	store.NextProcessSequence(context.Background(), "removed")
+	server := &Server{
+		store:       store,
+		authorizer:  fakeAuthorizer{},
+		configDir:   tempDir,
+		tmpl:        testTemplates(),
+		enforceAuth: true,
+		now:         func() time.Time { return time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC) },
+	}
+	serve := func(method, target string, form url.Values) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(&http.Cookie{Name: "attesta_session", Value: platformAdminSessionValue()})
+		rec := httptest.NewRecorder()
+		server.handleAdminSequences(rec, req)
+		return rec
+	}
+
+	rec := serve(http.MethodGet, "/admin/sequences", nil)
+	var response ProcessSequencesResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("list status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	if len(response.Sequences) != 2 || response.Sequences[0].WorkflowKey != "removed" || response.Sequences[1].WorkflowName != "Main workflow" || response.Sequences[1].Value != 1 {
+		t.Fatalf("sequences = %#v", response.Sequences)
+	}
+
+	if rec := serve(http.MethodPost, "/admin/sequences/workflow/set", url.Values{"value": {"0"}}); rec.Code != http.StatusConflict {
+		t.Fatalf("lowering status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if _, err := store.InsertProcess(context.Background(), Process{WorkflowKey: "workflow", Code: "MW-2026-000090", CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("insert process: %v", err)
+	}
+	if rec := serve(http.MethodPost, "/admin/sequences/workflow/set", url.Values{"value": {"50"}}); rec.Code != http.StatusConflict {
+		t.Fatalf("below issued code status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if rec := serve(http.MethodPost, "/admin/sequences/workflow/set", url.Values{"value": {"-3"}}); rec.Code != http.StatusBadRequest {
+		t.Fatalf("negative status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if rec := serve(http.MethodPost, "/admin/sequences/unknown/set", url.Values{"value": {"5"}}); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown workflow status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if rec := serve(http.MethodGet, "/admin/sequences/workflow/set", nil); rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("GET set status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+	if rec := serve(http.MethodPost, "/admin/sequences/workflow/set", url.Values{"value": {"120"}}); rec.Code != http.StatusOK {
+		t.Fatalf("set status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	if got, _ := store.NextProcessSequence(context.Background(), "workflow"); got != 121 {
+		t.Fatalf("next after set = %d, want 121", got)
+	}
+
+	server.authorizer = fakeAuthorizer{accessDecide: func(*AccountUser, string, string, map[string]interface{}, string) (bool, error) { return false, nil }}
+	if rec := serve(http.MethodPost, "/admin/sequences/workflow/set", url.Values{"value": {"500"}}); rec.Code != http.StatusForbidden {
+		t.Fatalf("denied status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
+
+func TestProcessSequenceViewNextCode(t *testing.T) {
+	server := &Server{now: func() time.Time { return time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC) }}
+	cfg := RuntimeConfig{Workflow: WorkflowDef{Name: "Orders", ProcessCodePrefix: "PRC"}}
+	if view := server.processSequenceView("orders", cfg, true, 122); view.NextCode != "PRC-2026-000123" {
+		t.Fatalf("next code = %q", view.NextCode)
+	}
+	if view := server.processSequenceView("gone", RuntimeConfig{}, false, 3); view.NextCode != "" || view.WorkflowName != "" {
+		t.Fatalf("unknown workflow view = %#v", view)
+	}
+}
@@ -0,0 +1,462 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ProcessNewView is the creation form of a workflow with startFields: the
+// instance name plus the inputs of every captured substep.
+type ProcessNewView struct {
+	PageBase
+	Breadcrumbs BreadcrumbsView
+	ActionURL   string
+	Name        string
+	Fields      []StartFieldView
+	Error       string
+}
+
+// StartFieldView is one startFields substep on the creation form.
+type StartFieldView struct {
+	SubstepID string
+	Title     string
+	InputType string
+	Inputs    []StartFieldInput
+	Options   []SubstepOption
+	// Name is the form field of multiselect and url substeps.
+	Name string
+}
+
+// StartFieldInput is one scalar schema property of a formata start field.
+type StartFieldInput struct {
+	Name     string
+	Label    string
+	Type     string
+	Required bool
+	Choices  []string
+}
+
+// startFieldCompletion is a captured start field, ready to be stored with
+// the new process.
+type startFieldCompletion struct {
+	SubstepID string
+	Progress  ProcessStep
+	Notary    Notarization
+}
+
+// startFieldFormName namespaces the form fields of a start field so two
+// substeps may share an inputKey.
+func startFieldFormName(substepID, key string) string {
+	return "start." + substepID + "." + key
+}
+
+// validateWorkflowStartFields checks that startFields names distinct
+// substeps the creation form can render: formata substeps with scalar
+// properties, multiselect or url.
+func validateWorkflowStartFields(def *WorkflowDef) error {
+	seen := make(map[string]bool, len(def.StartFields))
+	for idx, id := range def.StartFields {
+		id = strings.TrimSpace(id)
+		def.StartFields[idx] = id
+		if id == "" {
+			return fmt.Errorf("startFields[%d] is required", idx)
+		}
+		if seen[id] {
+			return fmt.Errorf("startFields value %q is duplicated", id)
+		}
+		seen[id] = true
+		sub, _, err := findSubstep(*def, id)
+		if err != nil {
+			return fmt.Errorf("startFields substep %q not found", id)
+		}
+		if sub.RequireConfirmation {
+			return fmt.Errorf("startFields substep %q must not set requireConfirmation", id)
+		}
+		if isMultiselectSubstep(sub) || isURLSubstep(sub) {
+			continue
+		}
+		if isAcknowledgeSubstep(sub) || isSignatureSubstep(sub) {
+			return fmt.Errorf("startFields substep %q must be formata, multiselect or url", id)
+		}
+		properties, _ := sub.Schema["properties"].(map[string]interface{})
+		if len(properties) == 0 {
+			return fmt.Errorf("startFields substep %q has no schema properties", id)
+		}
+		for key, raw := range properties {
+			property, _ := raw.(map[string]interface{})
+			if startFieldInputType(property) == "" {
+				return fmt.Errorf("startFields substep %q property %q is not a scalar", id, key)
+			}
+		}
+	}
+	return nil
+}
+
+// startFieldInputType maps a scalar schema property to an HTML input type,
+// or "" when the form cannot capture it.
+func startFieldInputType(property map[string]interface{}) string {
+	if _, ok := property["enum"].([]interface{}); ok {
+		return "select"
+	}
+	if format, _ := property["format"].(string); format != "" && format != "date" && format != "email" {
+		return ""
+	}
+	switch property["type"] {
+	case "string":
+		if format, _ := property["format"].(string); format != "" {
+			return format
+		}
+		return "text"
+	case "number", "integer":
+		return "number"
+	case "boolean":
+		return "checkbox"
+	}
+	return ""
+}
+
+func startFieldViews(cfg RuntimeConfig) []StartFieldView {
+	views := make([]StartFieldView, 0, len(cfg.Workflow.StartFields))
+	for _, id := range cfg.Workflow.StartFields {
+		sub, _, err := findSubstep(cfg.Workflow, id)
+		if err != nil {
+			continue
+		}
+		view := StartFieldView{SubstepID: sub.SubstepID, Title: sub.Title, InputType: normalizeInputTypeForCheck(sub.InputType)}
+		switch {
+		case isMultiselectSubstep(sub):
+			view.Options = sub.Options
+			view.Name = startFieldFormName(sub.SubstepID, multiselectInputKey(sub))
+		case isURLSubstep(sub):
+			view.Name = startFieldFormName(sub.SubstepID, urlInputKey(sub))
+		default:
+			view.Inputs = startFieldInputs(sub)
+		}
+		views = append(views, view)
+	}
+	return views
+}
+
+func startFieldInputs(sub WorkflowSub) []StartFieldInput {
+	properties, _ := sub.Schema["properties"].(map[string]interface{})
+	required := map[string]bool{}
+	if list, ok := sub.Schema["required"].([]interface{}); ok {
+		for _, item := range list {
+			if key, ok := item.(string); ok {
+				required[key] = true
+			}
+		}
+	}
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	inputs := make([]StartFieldInput, 0, len(keys))
+	for _, key := range keys {
+		property, _ := properties[key].(map[string]interface{})
+		input := StartFieldInput{
+			Name:     startFieldFormName(sub.SubstepID, key),
+			Label:    key,
+			Type:     startFieldInputType(property),
+			Required: required[key],
+		}
+		if title, ok := property["title"].(string); ok && strings.TrimSpace(title) != "" {
+			input.Label = strings.TrimSpace(title)
+		}
+		if enum, ok := property["enum"].([]interface{}); ok {
+			for _, choice := range enum {
+				input.Choices = append(input.Choices, fmt.Sprint(choice))
+			}
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs
+}
+
+// startFieldPayload reads the namespaced form fields of sub and returns the
+// payload the substep would have received through its own form, checked by
+// normalizePayload.
+func startFieldPayload(r *http.Request, sub WorkflowSub) (map[string]interface{}, error) {
+	var value map[string]interface{}
+	switch {
+	case isMultiselectSubstep(sub):
+		selected := r.PostForm[startFieldFormName(sub.SubstepID, multiselectInputKey(sub))]
+		if selected == nil {
+			selected = []string{}
+		}
+		value = map[string]interface{}{multiselectInputKey(sub): selected}
+	case isURLSubstep(sub):
+		value = map[string]interface{}{urlInputKey(sub): r.PostForm.Get(startFieldFormName(sub.SubstepID, urlInputKey(sub)))}
+	default:
+		properties, _ := sub.Schema["properties"].(map[string]interface{})
+		value = map[string]interface{}{}
+		for _, input := range startFieldInputs(sub) {
+			key := strings.TrimPrefix(input.Name, startFieldFormName(sub.SubstepID, ""))
+			raw := strings.TrimSpace(r.PostForm.Get(input.Name))
+			if input.Type == "checkbox" {
+				value[key] = raw != ""
+				continue
+			}
+			if raw == "" {
+				if input.Required {
+					return nil, fmt.Errorf("%s is required.", input.Label)
+				}
+				continue
+			}
+			property, _ := properties[key].(map[string]interface{})
+			if enum, ok := property["enum"].([]interface{}); ok {
+				chosen, found := interface{}(nil), false
+				for _, choice := range enum {
+					if fmt.Sprint(choice) == raw {
+						chosen, found = choice, true
+						break
+					}
+				}
+				if !found {
+					return nil, fmt.Errorf("%q is not an option of %s.", raw, input.Label)
+				}
+				value[key] = chosen
+				continue
+			}
+			if input.Type == "number" {
+				number, err := strconv.ParseFloat(raw, 64)
+				if err != nil {
+					return nil, fmt.Errorf("%s must be a number.", input.Label)
+				}
+				if property["type"] == "integer" && number != float64(int64(number)) {
+					return nil, fmt.Errorf("%s must be a whole number.", input.Label)
+				}
+				value[key] = number
+				continue
+			}
+			value[key] = raw
+		}
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, errInvalidForm
+	}
+	return normalizePayload(sub, string(data))
+}
+
+// captureStartFields applies the startFields of cfg to process in order,
+// running the same role, assignment, sequence and authorizer checks as a
+// regular completion. On success process.Progress holds the completed
+// entries and Participants and SearchText reflect them, as UpdateProcessProgress
+// would have left them; nothing is stored yet.
+func (s *Server) captureStartFields(r *http.Request, user *AccountUser, workflowKey string, cfg RuntimeConfig, process *Process) ([]startFieldCompletion, int, error) {
+	if len(cfg.Workflow.StartFields) == 0 {
+		return nil, http.StatusOK, nil
+	}
+	if err := r.ParseForm(); err != nil {
+		return nil, http.StatusBadRequest, errors.New("Invalid form.")
+	}
+	if s.authorizer == nil {
+		return nil, http.StatusBadGateway, errors.New("Cerbos check failed.")
+	}
+	actor := Actor{ID: accountActorID(user), WorkflowKey: workflowKey}
+	if user != nil {
+		actor.OrgSlug = user.OrgSlug
+		actor.RoleSlugs = append([]string(nil), user.RoleSlugs...)
+	}
+	projected := *process
+	projected.Progress = normalizeProgressKeys(process.Progress)
+	completions := make([]startFieldCompletion, 0, len(cfg.Workflow.StartFields))
+	for _, substepID := range cfg.Workflow.StartFields {
+		substep, step, err := findSubstep(cfg.Workflow, substepID)
+		if err != nil {
+			return nil, http.StatusBadRequest, err
+		}
+		fieldActor := actor
+		allowedRoles := substepRoles(substep)
+		for _, role := range allowedRoles {
+			if containsRole(fieldActor.RoleSlugs, role) {
+				fieldActor.Role = role
+				break
+			}
+		}
+		if fieldActor.Role == "" && !s.enforceAuth && len(allowedRoles) > 0 {
+			fieldActor.Role = allowedRoles[0]
+			fieldActor.RoleSlugs = append([]string(nil), allowedRoles...)
+		}
+		if fieldActor.Role == "" || !substepAssignedTo(substep, fieldActor.ID) {
+			return nil, http.StatusForbidden, fmt.Errorf("Not authorized to fill %s.", substep.Title)
+		}
+		sequenceOK := isSequenceOK(cfg.Workflow, &projected, substepID)
+		allowed, err := s.authorizer.CanComplete(r.Context(), fieldActor, process.ID.Hex(), workflowKey, substep, step.Order, step.OrganizationSlug, sequenceOK)
+		if err != nil {
+			logRequestError(r, err, "cerbos check failed for new process substep %s", substepID)
+			status, message := authorizerErrorResponse(err)
+			return nil, status, errors.New(message)
+		}
+		if !sequenceOK {
+			return nil, http.StatusConflict, fmt.Errorf("%s is locked: complete previous steps first.", substep.Title)
+		}
+		if !allowed {
+			return nil, http.StatusForbidden, fmt.Errorf("Not authorized to fill %s.", substep.Title)
+		}
+		payload, err := startFieldPayload(r, substep)
+		if err != nil {
+			if errors.Is(err, errInvalidForm) {
+				return nil, http.StatusBadRequest, errors.New("Invalid form.")
+			}
+			return nil, http.StatusBadRequest, err
+		}
+
+		now := process.CreatedAt
+		description := substep.InputKey
+		duration := int64(0)
+		progress := ProcessStep{
+			State:           "done",
+			Description:     &description,
+			DoneAt:          &now,
+			DoneBy:          &fieldActor,
+			Data:            payload,
+			DurationSeconds: &duration,
+		}
+		if err := checkRequiredDPPInputs(cfg, &projected, substepID, progress); err != nil {
+			return nil, http.StatusUnprocessableEntity, errors.New(dppInputMissingMessage(err))
+		}
+		projected.Progress[substepID] = progress
+		completions = append(completions, startFieldCompletion{
+			SubstepID: substepID,
+			Progress:  progress,
+			Notary: Notarization{
+				ProcessID: process.ID,
+				SubstepID: substepID,
+				Payload:   payload,
+				Actor:     fieldActor,
+				CreatedAt: now,
+				FakeNotary: FakeNotary{
+					Method: "sha256",
+					Digest: digestPayload(payload),
+				},
+			},
+		})
+	}
+	for _, completion := range completions {
+		process.Progress[encodeProgressKey(completion.SubstepID)] = completion.Progress
+	}
+	process.Participants = processParticipants(process.Progress)
+	process.SearchText = processIndexText(*process)
+	return completions, http.StatusOK, nil
+}
+
+// StartProcess stores a new process together with the notarizations of its
+// captured start fields, all or nothing, and records its events.
+func (p *ProcessService) StartProcess(ctx context.Context, cfg RuntimeConfig, process *Process, completions []startFieldCompletion) (primitive.ObjectID, error) {
+	var id primitive.ObjectID
+	insert := func(ctx context.Context) error {
+		var err error
+		if id, err = p.store.InsertProcess(ctx, *process); err != nil {
+			return err
+		}
+		for _, completion := range completions {
+			if err := p.store.InsertNotarization(ctx, completion.Notary); err != nil {
+				return fmt.Errorf("%w: %v", ErrNotarization, err)
+			}
+		}
+		return nil
+	}
+	var err error
+	if len(completions) == 0 {
+		err = insert(ctx)
+	} else {
+		err = p.store.WithTransaction(ctx, insert)
+	}
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	appendProcessEvent(ctx, p.store, ProcessEvent{
+		ProcessID:   id,
+		WorkflowKey: process.WorkflowKey,
+		Type:        processEventStarted,
+		Actor:       &Actor{ID: process.CreatedBy},
+		At:          process.CreatedAt,
+		Detail:      process.Name,
+	})
+	if len(completions) == 0 {
+		return id, nil
+	}
+	for _, completion := range completions {
+		appendProcessEvent(ctx, p.store, ProcessEvent{
+			ProcessID:   id,
+			WorkflowKey: process.WorkflowKey,
+			Type:        processEventSubstepCompleted,
+			Actor:       completion.Progress.DoneBy,
+			SubstepID:   completion.SubstepID,
+			At:          process.CreatedAt,
+			Detail:      completion.Notary.FakeNotary.Digest,
+		})
+	}
+	if reloaded, err := p.reloadProcess(ctx, id); err != nil {
+		log.Printf("failed to reload process %s after start: %v", id.Hex(), err)
+	} else if isProcessComplete(cfg.Workflow, reloaded) {
+		p.finalizeProcessIfDone(ctx, cfg, process.WorkflowKey, reloaded, process.CreatedAt)
+	}
+	return id, nil
+}
+
+// newProcessFormURL is the creation form of a workflow with startFields, or
+// "" when the stream page's name-only dialog is enough.
+func newProcessFormURL(workflowKey string, cfg RuntimeConfig) string {
+	if len(cfg.Workflow.StartFields) == 0 {
+		return ""
+	}
+	return streamPath(workflowKey) + "/instance/new"
+}
+
+// handleNewProcessForm serves GET /my/streams/:key/instance/new.
+func (s *Server) handleNewProcessForm(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, _, ok := s.requireAuthenticatedPage(w, r)
+	if !ok {
+		return
+	}
+	workflowKey, cfg, ok := s.selectedWorkflowOrRedirectHome(w, r)
+	if !ok {
+		return
+	}
+	if !s.canStartWorkflow(cfg.Workflow, user) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	s.renderNewProcessForm(w, user, workflowKey, cfg, "", "")
+}
+
+func (s *Server) renderNewProcessForm(w http.ResponseWriter, user *AccountUser, workflowKey string, cfg RuntimeConfig, name, message string) {
+	breadcrumbs := buildStreamBreadcrumbs(workflowKey, cfg.Workflow.Name)
+	breadcrumbs.Items[len(breadcrumbs.Items)-1].Current = false
+	breadcrumbs.Items = append(breadcrumbs.Items, BreadcrumbItem{Label: "New instance", Href: newProcessFormURL(workflowKey, cfg), Current: true})
+	view := ProcessNewView{
+		PageBase:    s.pageBaseForUser(user, "process_new_body", workflowKey, cfg.Workflow.Name),
+		Breadcrumbs: breadcrumbs,
+		ActionURL:   streamPath(workflowKey) + "/instance/start",
+		Name:        name,
+		Fields:      startFieldViews(cfg),
+		Error:       message,
+	}
+	if err := s.tmpl.ExecuteTemplate(w, "process_new.html", view); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// startFieldsError shows the creation form again with the reason the
+// submission was rejected.
+func (s *Server) startFieldsError(w http.ResponseWriter, r *http.Request, user *AccountUser, workflowKey string, cfg RuntimeConfig, status int, err error) {
+	w.WriteHeader(status)
+	s.renderNewProcessForm(w, user, workflowKey, cfg, normalizeProcessName(r.FormValue("name")), err.Error())
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func startFieldsRuntimeConfig() RuntimeConfig {
+	cfg := testRuntimeConfig()
+	first := &cfg.Workflow.Steps[0].Substep[0]
+	first.Schema = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"value": map[string]interface{}{"type": "integer", "title": "Quantity"},
+			"unit":  map[string]interface{}{"type": "string", "enum": []interface{}{"kg", "t"}},
+		},
+		"required": []interface{}{"value"},
+	}
+	second := &cfg.Workflow.Steps[0].Substep[1]
+	second.InputType, second.Schema = "url", nil
+	cfg.Workflow.StartFields = []string{"1.1", "1.2"}
+	return cfg
+}
+
+func TestValidateWorkflowStartFields(t *testing.T) {
+	cfg := startFieldsRuntimeConfig()
+	cfg.Workflow.StartFields = []string{" 1.1 ", "1.2"}
+	if err := validateWorkflowStartFields(&cfg.Workflow); err != nil {
+		t.Fatalf("validateWorkflowStartFields: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Workflow.StartFields, []string{"1.1", "1.2"}) {
+		t.Fatalf("startFields = %v", cfg.Workflow.StartFields)
+	}
+
+	cfg.Workflow.Steps[0].Substep[2].Schema = map[string]interface{}{"properties": map[string]interface{}{
+		"attachment": map[string]interface{}{"type": "string", "format": "data-url"},
+	}}
+	cfg.Workflow.Steps[1].Substep[0].InputType = "signature"
+	for name, fields := range map[string][]string{
+		"unknown":    {"9.9"},
+		"blank":      {" "},
+		"duplicate":  {"1.1", "1.1"},
+		"attachment": {"1.3"},
+		"signature":  {"2.1"},
+	} {
+		cfg.Workflow.StartFields = fields
+		if err := validateWorkflowStartFields(&cfg.Workflow); err == nil {
+			t.Fatalf("%s startFields: expected error", name)
+		}
+	}
+}
+
+func TestHandleStartProcessCapturesStartFields(t *testing.T) {
+	now := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	cfg := startFieldsRuntimeConfig()
+	store := NewMemoryStore()
+	server := &Server{
+		store:          store,
+		sse:            newSSEHub(),
+		tmpl:           parseTestTemplates(t),
+		authorizer:     fakeAuthorizer{},
+		now:            func() time.Time { return now },
+		configProvider: func() (RuntimeConfig, error) { return cfg, nil },
+	}
+	start := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/instance/start", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		server.handleStartProcess(rec, req)
+		return rec
+	}
+	countProcesses := func() int {
+		processes, _ := store.ListRecentProcessesByWorkflow(context.Background(), "workflow", 0)
+		return len(processes)
+	}
+
+	rec := start(url.Values{"name": {"Order 7"}, "start.1.1.unit": {"kg"}, "start.1.2.note": {"https://example.com/po/7"}})
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "Quantity is required.") {
+		t.Fatalf("missing required field: status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	if rec := start(url.Values{"start.1.1.value": {"2.5"}, "start.1.2.note": {"https://example.com"}}); rec.Code != http.StatusBadRequest {
+		t.Fatalf("fractional integer status = %d", rec.Code)
+	}
+	if rec := start(url.Values{"start.1.1.value": {"2"}, "start.1.2.note": {"ftp://example.com"}}); rec.Code != http.StatusBadRequest {
+		t.Fatalf("ftp link status = %d", rec.Code)
+	}
+	if got := countProcesses(); got != 0 {
+		t.Fatalf("rejected forms stored %d processes", got)
+	}
+
+	rec = start(url.Values{"name": {"Order 7"}, "start.1.1.value": {"12"}, "start.1.1.unit": {"kg"}, "start.1.2.note": {"https://example.com/po/7"}})
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("start status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	location := rec.Header().Get("Location")
+	id, err := primitive.ObjectIDFromHex(location[strings.LastIndex(location, "/")+1:])
+	if err != nil {
+		t.Fatalf("location = %q", location)
+	}
+	process, _ := store.SnapshotProcess(id)
+	progress := normalizeProgressKeys(process.Progress)
+	if progress["1.1"].State != "done" || !reflect.DeepEqual(progress["1.1"].Data, map[string]interface{}{"value": float64(12), "unit": "kg"}) {
+		t.Fatalf("1.1 = %#v", progress["1.1"])
+	}
+	if progress["1.2"].State != "done" || progress["1.2"].DoneBy == nil || progress["1.2"].DoneBy.Role != "dep1" {
+		t.Fatalf("1.2 = %#v", progress["1.2"])
+	}
+	if progress["1.3"].State != "pending" {
+		t.Fatalf("1.3 = %#v, want pending", progress["1.3"])
+	}
+	if !reflect.DeepEqual(process.Participants, []string{progress["1.1"].DoneBy.ID}) {
+		t.Fatalf("participants = %#v, want the start field actor", process.Participants)
+	}
+	if !containsRole(process.SearchText, "kg") || !containsRole(process.SearchText, "https://example.com/po/7") {
+		t.Fatalf("searchText = %#v, want the captured values", process.SearchText)
+	}
+	notarizations, _ := store.ListNotarizations(context.Background(), id)
+	if len(notarizations) != 2 || notarizations[0].FakeNotary.Digest != digestPayload(progress["1.1"].Data) {
+		t.Fatalf("notarizations = %#v", notarizations)
+	}
+	events, _ := store.ListProcessEvents(context.Background(), id)
+	if len(events) != 3 || events[0].Type != processEventStarted || events[2].SubstepID != "1.2" {
+		t.Fatalf("events = %#v", events)
+	}
+}
+
+func TestHandleStartProcessStartFieldsKeepChecks(t *testing.T) {
+	cfg := startFieldsRuntimeConfig()
+	cfg.Workflow.StartFields = []string{"1.2"}
+	store := NewMemoryStore()
+	server := &Server{
+		store:          store,
+		sse:            newSSEHub(),
+		tmpl:           parseTestTemplates(t),
+		authorizer:     fakeAuthorizer{},
+		configProvider: func() (RuntimeConfig, error) { return cfg, nil },
+	}
+	start := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/instance/start", strings.NewReader(url.Values{"start.1.2.note": {"https://example.com"}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		server.handleStartProcess(rec, req)
+		return rec
+	}
+	if rec := start(); rec.Code != http.StatusConflict {
+		t.Fatalf("out of sequence status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+
+	cfg.Workflow.StartFields = []string{"1.1"}
+	cfg.Workflow.Steps[0].Substep[0].Schema = map[string]interface{}{"properties": map[string]interface{}{}}
+	server.authorizer = fakeAuthorizer{decide: func(Actor, string, string, WorkflowSub, int, string, bool) (bool, error) { return false, nil }}
+	if rec := start(); rec.Code != http.StatusForbidden {
+		t.Fatalf("denied status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+	if processes, _ := store.ListRecentProcessesByWorkflow(context.Background(), "workflow", 0); len(processes) != 0 {
+		t.Fatalf("rejected starts stored %d processes", len(processes))
+	}
+}
+
+func TestProcessNewTemplateRendersStartFields(t *testing.T) {
+	tmpl := parseTestTemplates(t)
+	cfg := startFieldsRuntimeConfig()
+	var out bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&out, "process_new_body", ProcessNewView{
+		ActionURL: "/my/streams/workflow/instance/start",
+		Fields:    startFieldViews(cfg),
+		Error:     "Quantity is required.",
+	}); err != nil {
+		t.Fatalf("render process_new_body: %v", err)
+	}
+	body := out.String()
+	for _, marker := range []string{
+		`action="/my/streams/workflow/instance/start"`,
+		`name="start.1.1.value"`,
+		`type="number"`,
+		`<option value="kg">`,
+		`name="start.1.2.note" type="url"`,
+		"Quantity is required.",
+	} {
+		if !strings.Contains(body, marker) {
+			t.Fatalf("expected %q in body: %s", marker, body)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+var ErrIllegalStatusTransition = errors.New("process: illegal status transition")
+
+// terminalProcessStatuses lists the statuses a process never leaves. Adding a
+// status here makes the stores and completion path treat it as final.
+var terminalProcessStatuses = map[string]bool{
+	processStatusDone:       true,
+	processStatusTerminated: true,
+}
+
+func normalizeProcessStatus(status string) string {
+	status = strings.TrimSpace(status)
+	if status == "" {
+		return processStatusActive
+	}
+	return status
+}
+
+func isTerminalProcessStatus(status string) bool {
+	return terminalProcessStatuses[normalizeProcessStatus(status)]
+}
+
+// canTransition reports whether a process may move from one stored status to
+// another. Re-applying the current status is always allowed so repeated
+// finalization stays idempotent; otherwise terminal statuses are final.
+func canTransition(from, to string) bool {
+	from = normalizeProcessStatus(from)
+	to = normalizeProcessStatus(to)
+	if from == to {
+		return true
+	}
+	return !terminalProcessStatuses[from]
+}
+
+// statusesBlockingTransitionTo lists the stored statuses canTransition rejects
+// for a move to status, for use as a Mongo $nin guard.
+func statusesBlockingTransitionTo(status string) []string {
+	status = normalizeProcessStatus(status)
+	var blocked []string
+	for terminal := range terminalProcessStatuses {
+		if terminal != status {
+			blocked = append(blocked, terminal)
+		}
+	}
+	sort.Strings(blocked)
+	return blocked
+}
+
+// processAcceptsAmendments reports whether completed substeps may still be
+// amended: a finished process can be corrected, a terminated one cannot.
+func processAcceptsAmendments(process *Process) bool {
+	return process != nil && process.Termination == nil && normalizeProcessStatus(process.Status) != processStatusTerminated
+}
+
+// processAcceptsCompletions reports whether substeps may still be completed.
+// Terminated processes and those stored with a terminal status are closed.
+func processAcceptsCompletions(process *Process) bool {
+	if process == nil {
+		return false
+	}
+	return process.Termination == nil && !isTerminalProcessStatus(process.Status)
+}
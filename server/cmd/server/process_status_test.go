@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestCanTransitionMatrix(t *testing.T) {
+	statuses := []string{"", processStatusActive, processStatusDone, processStatusTerminated}
+	want := map[string]map[string]bool{
+		"":                      {"": true, processStatusActive: true, processStatusDone: true, processStatusTerminated: true},
+		processStatusActive:     {"": true, processStatusActive: true, processStatusDone: true, processStatusTerminated: true},
+		processStatusDone:       {"": false, processStatusActive: false, processStatusDone: true, processStatusTerminated: false},
+		processStatusTerminated: {"": false, processStatusActive: false, processStatusDone: false, processStatusTerminated: true},
+	}
+	for _, from := range statuses {
+		for _, to := range statuses {
+			if got := canTransition(from, to); got != want[from][to] {
+				t.Errorf("canTransition(%q, %q) = %v, want %v", from, to, got, want[from][to])
+			}
+		}
+	}
+}
+
+func TestStatusesBlockingTransitionTo(t *testing.T) {
+	if got := statusesBlockingTransitionTo(processStatusDone); !reflect.DeepEqual(got, []string{processStatusTerminated}) {
+		t.Fatalf("blocking done = %v", got)
+	}
+	if got := statusesBlockingTransitionTo(processStatusActive); !reflect.DeepEqual(got, []string{processStatusDone, processStatusTerminated}) {
+		t.Fatalf("blocking active = %v", got)
+	}
+}
+
+func TestMemoryStoreRejectsIllegalStatusTransitions(t *testing.T) {
+	store := NewMemoryStore()
+	doneID := store.SeedProcess(Process{WorkflowKey: "workflow", Status: processStatusDone})
+	if err := store.UpdateProcessStatus(context.Background(), doneID, "workflow", processStatusActive); !errors.Is(err, ErrIllegalStatusTransition) {
+		t.Fatalf("done -> active err = %v, want %v", err, ErrIllegalStatusTransition)
+	}
+	if err := store.UpdateProcessStatus(context.Background(), doneID, "workflow", processStatusDone); err != nil {
+		t.Fatalf("done -> done err = %v", err)
+	}
+	if err := store.UpdateProcessTermination(context.Background(), doneID, "workflow", ProcessTermination{Reason: "late"}); !errors.Is(err, ErrIllegalStatusTransition) {
+		t.Fatalf("done -> terminated err = %v, want %v", err, ErrIllegalStatusTransition)
+	}
+	snapshot, _ := store.SnapshotProcess(doneID)
+	if snapshot.Status != processStatusDone || snapshot.Termination != nil {
+		t.Fatalf("expected done process untouched, got %#v", snapshot)
+	}
+
+	activeID := store.SeedProcess(Process{WorkflowKey: "workflow", Status: processStatusActive})
+	if err := store.UpdateProcessTermination(context.Background(), activeID, "workflow", ProcessTermination{Reason: "stop"}); err != nil {
+		t.Fatalf("active -> terminated err = %v", err)
+	}
+	if err := store.UpdateProcessStatus(context.Background(), activeID, "workflow", processStatusDone); !errors.Is(err, ErrIllegalStatusTransition) {
+		t.Fatalf("terminated -> done err = %v, want %v", err, ErrIllegalStatusTransition)
+	}
+	if err := store.UpdateProcessTermination(context.Background(), activeID, "workflow", ProcessTermination{Reason: "again"}); !errors.Is(err, ErrIllegalStatusTransition) {
+		t.Fatalf("terminated -> terminated err = %v, want %v", err, ErrIllegalStatusTransition)
+	}
+	if err := store.UpdateProcessProgress(context.Background(), activeID, "workflow", "1.1", ProcessStep{State: "done"}); !errors.Is(err, ErrIllegalStatusTransition) {
+		t.Fatalf("progress on terminated err = %v, want %v", err, ErrIllegalStatusTransition)
+	}
+	snapshot, _ = store.SnapshotProcess(activeID)
+	if snapshot.Termination == nil || snapshot.Termination.Reason != "stop" || len(snapshot.Progress) != 0 {
+		t.Fatalf("expected the first termination and no progress, got %#v", snapshot)
+	}
+}
+
+func TestMongoStoreUpdateProcessStatusGuardsTerminalStatuses(t *testing.T) {
+	processes := &fakeMongoCollection{}
+	db := &fakeMongoDatabase{collections: map[string]*fakeMongoCollection{"processes": processes}}
+	store := &MongoStore{dbPort: db}
+	id := primitive.NewObjectID()
+
+	processes.updateOneFn = func(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+		return &mongo.UpdateResult{MatchedCount: 0}, nil
+	}
+	processes.findOneFn = func(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) mongoSingleResultPort {
+		return fakeSingleResult{}
+	}
+	if err := store.UpdateProcessStatus(t.Context(), id, "wf-a", processStatusActive); !errors.Is(err, ErrIllegalStatusTransition) {
+		t.Fatalf("UpdateProcessStatus err = %v, want %v", err, ErrIllegalStatusTransition)
+	}
+	wantFilter := bson.M{"_id": id, "status": bson.M{"$nin": []string{processStatusDone, processStatusTerminated}}}
+	if !reflect.DeepEqual(processes.updateOneFilters[0], wantFilter) {
+		t.Fatalf("filter = %#v, want %#v", processes.updateOneFilters[0], wantFilter)
+	}
+
+	if err := store.UpdateProcessTermination(t.Context(), id, "wf-a", ProcessTermination{Reason: "stop"}); !errors.Is(err, ErrIllegalStatusTransition) {
+		t.Fatalf("UpdateProcessTermination err = %v, want %v", err, ErrIllegalStatusTransition)
+	}
+	wantFilter = bson.M{"_id": id, "status": bson.M{"$nin": []string{processStatusDone}}, "termination": bson.M{"$exists": false}}
+	if !reflect.DeepEqual(processes.updateOneFilters[1], wantFilter) {
+		t.Fatalf("termination filter = %#v, want %#v", processes.updateOneFilters[1], wantFilter)
+	}
+
+	processes.findOneAndUpdateFn = func(ctx context.Context, filter interface{}, update interface{}, opts ...*options.FindOneAndUpdateOptions) mongoSingleResultPort {
+		return fakeSingleResult{err: mongo.ErrNoDocuments}
+	}
+	if err := store.UpdateProcessProgress(t.Context(), id, "wf-a", "1.1", ProcessStep{State: "done"}); !errors.Is(err, ErrIllegalStatusTransition) {
+		t.Fatalf("UpdateProcessProgress err = %v, want %v", err, ErrIllegalStatusTransition)
+	}
+	wantFilter = bson.M{"_id": id, "status": bson.M{"$nin": []string{processStatusDone, processStatusTerminated}}, "termination": bson.M{"$exists": false}}
+	if !reflect.DeepEqual(processes.findOneAndUpdFilter[0], wantFilter) {
+		t.Fatalf("progress filter = %#v, want %#v", processes.findOneAndUpdFilter[0], wantFilter)
+	}
+
+	processes.findOneFn = nil
+	if err := store.UpdateProcessStatus(t.Context(), id, "wf-a", processStatusDone); err != nil {
+		t.Fatalf("missing process err = %v, want nil", err)
+	}
+}
+
+func TestProcessServiceRejectsCompletionOnClosedProcess(t *testing.T) {
+	store := NewMemoryStore()
+	cfg := testRuntimeConfig()
+	sub, _, _ := findSubstep(cfg.Workflow, "1.1")
+	service := &ProcessService{store: store}
+	for _, process := range []*Process{
+		{ID: primitive.NewObjectID(), Status: processStatusDone},
+		{ID: primitive.NewObjectID(), Status: processStatusActive, Termination: &ProcessTermination{EndedAt: time.Now()}},
+	} {
+		store.SeedProcess(*process)
+		_, err := service.CompleteSubstep(context.Background(), CompleteSubstepCmd{
+			Process:   process,
+			SubstepID: "1.1",
+			Substep:   sub,
+			Config:    cfg,
+			Payload:   map[string]interface{}{"value": 1},
+		})
+		if !errors.Is(err, ErrIllegalStatusTransition) {
+			t.Fatalf("CompleteSubstep(%q) err = %v, want %v", process.Status, err, ErrIllegalStatusTransition)
+		}
+		snapshot, _ := store.SnapshotProcess(process.ID)
+		if _, ok := snapshot.Progress["1_1"]; ok {
+			t.Fatalf("expected no progress written for closed process")
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter is a fixed-window counter keyed by caller. The clock is passed
+// in by the caller so handlers can reuse Server.now and tests stay
+// deterministic.
+type rateLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	window  time.Duration
+	buckets map[string]rateLimitBucket
+}
+
+type rateLimitBucket struct {
+	start time.Time
+	count int
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	if limit <= 0 || window <= 0 {
+		return nil
+	}
+	return &rateLimiter{
+		limit:   limit,
+		window:  window,
+		buckets: map[string]rateLimitBucket{},
+	}
+}
+
+// Check reports whether key still has room in its current window at now and,
+// when it does not, how long until the window resets. It records nothing, so
+// a request that fails after the check does not use up the budget. A nil
+// limiter allows everything.
+func (l *rateLimiter) Check(key string, now time.Time) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bucket := l.bucketLocked(key, now)
+	if bucket.count >= l.limit {
+		return false, bucket.start.Add(l.window).Sub(now)
+	}
+	return true, 0
+}
+
+// Record counts one successful attempt for key at now.
+func (l *rateLimiter) Record(key string, now time.Time) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bucket := l.bucketLocked(key, now)
+	bucket.count++
+	l.buckets[key] = bucket
+}
+
+// bucketLocked returns the bucket of key, starting a new window when the
+// previous one has run out. Callers hold l.mu.
+func (l *rateLimiter) bucketLocked(key string, now time.Time) rateLimitBucket {
+	bucket, ok := l.buckets[key]
+	if !ok || !now.Before(bucket.start.Add(l.window)) {
+		bucket = rateLimitBucket{start: now}
+		l.pruneLocked(now)
+	}
+	return bucket
+}
+
+func (l *rateLimiter) pruneLocked(now time.Time) {
+	for key, bucket := range l.buckets {
+		if !now.Before(bucket.start.Add(l.window)) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func processCreateLimitPerHour() int {
+	limit := intEnvOr("PROCESS_CREATE_LIMIT_PER_HOUR", 60)
+	if limit < 0 {
+		return 0
+	}
+	return limit
+}
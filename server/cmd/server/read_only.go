@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const readOnlyMessage = "Attesta is in read-only maintenance mode. Changes are temporarily disabled."
+
+const readOnlyTogglePath = "/admin/read-only"
+
+type ReadOnlyStatusResponse struct {
+	ReadOnly bool `json:"read_only"`
+}
+
+// readOnlyRefresh is how long an instance trusts its cached read-only flag
+// before reloading it from the store, so a toggle on one replica reaches the
+// others within that delay.
+const readOnlyRefresh = 5 * time.Second
+
+// isReadOnly reports the maintenance mode shared through the store. Without a
+// store the cached flag is authoritative.
+func (s *Server) isReadOnly() bool {
+	if s == nil {
+		return false
+	}
+	if s.store != nil {
+		now := s.nowUTC()
+		if now.Sub(time.Unix(0, s.readOnlyCheckedAt.Load())) >= readOnlyRefresh {
+			if enabled, err := s.store.LoadReadOnly(context.Background()); err != nil {
+				log.Printf("failed to load read-only mode: %v", err)
+			} else {
+				s.readOnly.Store(enabled)
+			}
+			s.readOnlyCheckedAt.Store(now.UnixNano())
+		}
+	}
+	return s.readOnly.Load()
+}
+
+// setReadOnly persists the mode for every instance and updates this one's
+// cache right away.
+func (s *Server) setReadOnly(ctx context.Context, enabled bool) error {
+	if s.store != nil {
+		if err := s.store.SetReadOnly(ctx, enabled); err != nil {
+			return err
+		}
+	}
+	s.readOnly.Store(enabled)
+	s.readOnlyCheckedAt.Store(s.nowUTC().UnixNano())
+	return nil
+}
+
+// readOnlyStateChangingGETs are GET routes that write anyway, such as
+// accepting an invite from an emailed link.
+var readOnlyStateChangingGETs = []string{"/invite/accept"}
+
+// readOnlyExempt lists the requests that stay available in maintenance mode:
+// every safe method except readOnlyStateChangingGETs, signing in/out, and the
+// toggle itself so a platform admin can switch the mode back off.
+func readOnlyExempt(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		for _, prefix := range readOnlyStateChangingGETs {
+			if strings.HasPrefix(r.URL.Path, prefix) {
+				return false
+			}
+		}
+		return true
+	}
+	switch strings.TrimRight(r.URL.Path, "/") {
+	case "/login", "/logout", readOnlyTogglePath:
+		return true
+	default:
+		return false
+	}
+}
+
+// withReadOnlyGuard rejects mutating requests with 503 while read-only mode is
+// enabled, so individual handlers don't need their own checks.
+func (s *Server) withReadOnlyGuard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.isReadOnly() && !readOnlyExempt(r) {
+			w.Header().Set("Retry-After", "300")
+			http.Error(w, readOnlyMessage, http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleReadOnlyToggle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, _, ok := s.requireAuthenticatedPost(w, r)
+	if !ok {
+		return
+	}
+	allowed, err := s.canAccessPlatformAdminConsole(r.Context(), user)
+	if err != nil {
+		logAndHTTPError(w, r, http.StatusBadGateway, "cerbos check failed", err, "cerbos check failed for read-only toggle")
+		return
+	}
+	if !allowed {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	if r.Method == http.MethodPost {
+		enabled, err := strconv.ParseBool(strings.TrimSpace(r.FormValue("enabled")))
+		if err != nil {
+			http.Error(w, "enabled must be true or false", http.StatusBadRequest)
+			return
+		}
+		if err := s.setReadOnly(r.Context(), enabled); err != nil {
+			logAndHTTPError(w, r, http.StatusInternalServerError, "failed to update read-only mode", err, "failed to store read-only mode %t", enabled)
+			return
+		}
+		log.Printf("read-only mode set to %t by %s", enabled, accountActorID(user))
+	}
+	writeJSON(w, ReadOnlyStatusResponse{ReadOnly: s.isReadOnly()})
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestWithReadOnlyGuard(t *testing.T) {
+	server := &Server{}
+	handler := server.withReadOnlyGuard(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	if rec := serve(http.MethodPost, "/my/streams/workflow/process/start"); rec.Code != http.StatusTeapot {
+		t.Fatalf("status = %d, want passthrough while writable", rec.Code)
+	}
+
+	server.readOnly.Store(true)
+	rec := serve(http.MethodPost, "/my/streams/workflow/process/start")
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	if rec.Header().Get("Retry-After") == "" || !strings.Contains(rec.Body.String(), "read-only") {
+		t.Fatalf("unexpected read-only response headers=%v body=%q", rec.Header(), rec.Body.String())
+	}
+	for _, tc := range []struct{ method, path string }{
+		{http.MethodGet, "/my/streams/workflow/"},
+		{http.MethodHead, "/my/streams/workflow/"},
+		{http.MethodPost, "/login"},
+		{http.MethodPost, "/logout"},
+		{http.MethodPost, readOnlyTogglePath},
+	} {
+		if rec := serve(tc.method, tc.path); rec.Code != http.StatusTeapot {
+			t.Fatalf("%s %s status = %d, want passthrough", tc.method, tc.path, rec.Code)
+		}
+	}
+	if rec := serve(http.MethodGet, "/invite/accept?teamId=t&membershipId=m&userId=u&secret=s"); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("invite accept status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestReadOnlyModeIsSharedThroughTheStore(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Date(2026, 2, 2, 13, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	first := &Server{store: store, now: clock}
+	second := &Server{store: store, now: clock}
+	if second.isReadOnly() {
+		t.Fatal("expected read-only mode to start off")
+	}
+
+	if err := first.setReadOnly(t.Context(), true); err != nil {
+		t.Fatalf("setReadOnly: %v", err)
+	}
+	if !first.isReadOnly() {
+		t.Fatal("expected the toggling instance to switch at once")
+	}
+	if second.isReadOnly() {
+		t.Fatal("expected the other instance to keep its cache until the refresh")
+	}
+	now = now.Add(readOnlyRefresh)
+	if !second.isReadOnly() {
+		t.Fatal("expected the other instance to pick up the stored mode")
+	}
+}
+
+func TestMongoStoreReadOnlySetting(t *testing.T) {
+	db := &fakeMongoDatabase{}
+	store := &MongoStore{dbPort: db}
+	if enabled, err := store.LoadReadOnly(t.Context()); err != nil || enabled {
+		t.Fatalf("LoadReadOnly without document = %t, %v; want false", enabled, err)
+	}
+	if err := store.SetReadOnly(t.Context(), true); err != nil {
+		t.Fatalf("SetReadOnly: %v", err)
+	}
+	collection := db.Collection("settings").(*fakeMongoCollection)
+	if filter := collection.updateOneFilters[0].(bson.M); filter["_id"] != readOnlySettingID {
+		t.Fatalf("filter = %#v", filter)
+	}
+	if opts := collection.updateOneOptions[0]; len(opts) != 1 || opts[0].Upsert == nil || !*opts[0].Upsert {
+		t.Fatalf("expected upsert, got %#v", opts)
+	}
+}
+
+func TestHandleReadOnlyToggle(t *testing.T) {
+	t.Setenv("ADMIN_EMAIL", "admin@example.com")
+	t.Setenv("ADMIN_PASSWORD", "change-me")
+
+	now := time.Now().UTC()
+	member := AccountUser{ID: primitive.NewObjectID(), Email: "member@example.com", Status: "active"}
+	server := &Server{
+		authorizer:  fakeAuthorizer{},
+		identity:    testIdentityForSessions(now, map[string]AccountUser{"session-member": member}),
+		enforceAuth: true,
+		now:         func() time.Time { return now },
+	}
+	toggle := func(session, enabled string) *httptest.ResponseRecorder {
+		form := url.Values{"enabled": {enabled}}
+		req := httptest.NewRequest(http.MethodPost, readOnlyTogglePath, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if session != "" {
+			req.AddCookie(&http.Cookie{Name: "attesta_session", Value: session})
+		}
+		rec := httptest.NewRecorder()
+		server.handleReadOnlyToggle(rec, req)
+		return rec
+	}
+
+	if rec := toggle("", "true"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("anonymous status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	if rec := toggle("session-member", "true"); rec.Code != http.StatusForbidden {
+		t.Fatalf("member status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+	if server.isReadOnly() {
+		t.Fatal("expected read-only mode to stay off")
+	}
+	if rec := toggle(platformAdminSessionValue(), "maybe"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid value status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	rec := toggle(platformAdminSessionValue(), "true")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"read_only": true`) {
+		t.Fatalf("enable status = %d body = %q", rec.Code, rec.Body.String())
+	}
+	if !server.isReadOnly() || !server.pageBase("", "", "").ReadOnly {
+		t.Fatal("expected read-only mode to be enabled and exposed to pages")
+	}
+	if rec := toggle(platformAdminSessionValue(), "false"); rec.Code != http.StatusOK || server.isReadOnly() {
+		t.Fatalf("disable status = %d read-only = %t", rec.Code, server.isReadOnly())
+	}
+}
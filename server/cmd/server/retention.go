@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const retentionLockName = "retention-sweeper"
+
+// retentionSweepConfig controls the background sweeper that enforces
+// per-workflow retentionDays on terminated processes. A zero Interval
+// disables the sweeper.
+type retentionSweepConfig struct {
+	Interval   time.Duration
+	HardDelete bool
+}
+
+func retentionSweepConfigFromEnv() retentionSweepConfig {
+	minutes := intEnvOr("RETENTION_SWEEP_INTERVAL_MINUTES", 60)
+	if minutes < 0 {
+		minutes = 0
+	}
+	return retentionSweepConfig{
+		Interval:   time.Duration(minutes) * time.Minute,
+		HardDelete: boolEnvOr("RETENTION_HARD_DELETE", false),
+	}
+}
+
+// runRetentionSweeper sweeps once per interval until ctx is cancelled. Every
+// instance runs the loop; the lock document makes sure only one of them does
+// the work in a given interval.
+func (s *Server) runRetentionSweeper(ctx context.Context, cfg retentionSweepConfig) {
+	if cfg.Interval <= 0 {
+		return
+	}
+	owner := primitive.NewObjectID().Hex()
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+	for {
+		if purged, err := s.sweepRetention(ctx, owner, cfg); err != nil {
+			log.Printf("retention sweep failed: %v", err)
+		} else if purged > 0 {
+			log.Printf("retention sweep removed %d terminated processes (hard delete: %t)", purged, cfg.HardDelete)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sweepRetention purges terminated processes older than their workflow's
+// retentionDays. It returns 0 without touching the store in read-only mode
+// or when another instance holds the sweeper lock.
+func (s *Server) sweepRetention(ctx context.Context, owner string, cfg retentionSweepConfig) (int, error) {
+	if s.isReadOnly() {
+		return 0, nil
+	}
+	now := s.nowUTC()
+	acquired, err := s.store.AcquireLock(ctx, retentionLockName, owner, now, cfg.Interval)
+	if err != nil || !acquired {
+		return 0, err
+	}
+	catalog, err := s.workflowCatalog()
+	if err != nil {
+		return 0, err
+	}
+	purged := 0
+	for _, key := range sortedWorkflowKeys(catalog) {
+		days := catalog[key].Workflow.RetentionDays
+		if days <= 0 {
+			continue
+		}
+		cutoff := now.Add(-time.Duration(days) * 24 * time.Hour)
+		count, err := s.store.PurgeTerminatedProcesses(ctx, key, cutoff, cfg.HardDelete, now)
+		if err != nil {
+			return purged, err
+		}
+		purged += count
+	}
+	return purged, nil
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func writeRetentionWorkflowConfig(t *testing.T, path string, retentionDays string) {
+	t.Helper()
+	writeWorkflowConfig(t, path, filepath.Base(path), "formata")
+	if retentionDays == "" {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	content := strings.Replace(string(data), "  steps:\n", "  retentionDays: "+retentionDays+"\n  steps:\n", 1)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+}
+
+func TestSweepRetention(t *testing.T) {
+	dir := t.TempDir()
+	writeRetentionWorkflowConfig(t, filepath.Join(dir, "kept.yaml"), "30")
+	writeRetentionWorkflowConfig(t, filepath.Join(dir, "forever.yaml"), "")
+	writeRetentionWorkflowConfig(t, filepath.Join(dir, "gone.yaml"), "30")
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	terminated := func(workflowKey string, endedAt time.Time) primitive.ObjectID {
+		return store.SeedProcess(Process{
+			WorkflowKey: workflowKey,
+			CreatedAt:   endedAt.Add(-time.Hour),
+			Status:      processStatusTerminated,
+			Termination: &ProcessTermination{Reason: "cancelled", EndedAt: endedAt},
+		})
+	}
+	expired := terminated("kept", now.AddDate(0, 0, -31))
+	if err := store.UpdateProcessDPP(t.Context(), expired, "kept", ProcessDPP{GTIN: "09506000134352", Lot: "LOT-1", Serial: expired.Hex()}); err != nil {
+		t.Fatalf("UpdateProcessDPP: %v", err)
+	}
+	gone := terminated("gone", now.AddDate(0, 0, -31))
+	recent := terminated("kept", now.AddDate(0, 0, -5))
+	noPolicy := terminated("forever", now.AddDate(0, 0, -400))
+	active := store.SeedProcess(Process{WorkflowKey: "kept", CreatedAt: now.AddDate(0, 0, -90), Status: processStatusActive})
+	if _, err := store.SaveAttachment(t.Context(), AttachmentUpload{ProcessID: expired, SubstepID: "1.1", Filename: "a.txt"}, strings.NewReader("a")); err != nil {
+		t.Fatalf("SaveAttachment: %v", err)
+	}
+
+	server := &Server{store: store, configDir: dir, now: func() time.Time { return now }}
+	cfg := retentionSweepConfig{Interval: time.Hour}
+
+	if err := server.setReadOnly(t.Context(), true); err != nil {
+		t.Fatalf("setReadOnly: %v", err)
+	}
+	if purged, err := server.sweepRetention(t.Context(), "instance-a", cfg); err != nil || purged != 0 {
+		t.Fatalf("read-only sweepRetention = %d, %v; want skipped", purged, err)
+	}
+	if err := server.setReadOnly(t.Context(), false); err != nil {
+		t.Fatalf("setReadOnly: %v", err)
+	}
+
+	purged, err := server.sweepRetention(t.Context(), "instance-a", cfg)
+	if err != nil || purged != 2 {
+		t.Fatalf("sweepRetention = %d, %v; want 2 soft deletes", purged, err)
+	}
+	snapshot, ok := store.SnapshotProcess(expired)
+	if !ok || snapshot.DeletedAt == nil || !snapshot.DeletedAt.Equal(now) {
+		t.Fatalf("expected expired process to be soft-deleted, got %#v", snapshot)
+	}
+	for _, id := range []primitive.ObjectID{recent, noPolicy, active} {
+		if snapshot, _ := store.SnapshotProcess(id); snapshot.DeletedAt != nil {
+			t.Fatalf("process %s should be kept", id.Hex())
+		}
+	}
+	listed, err := store.ListRecentProcessesByWorkflow(t.Context(), "kept", 0)
+	if err != nil || len(listed) != 2 {
+		t.Fatalf("expected soft-deleted process to be hidden from listings, got %d (%v)", len(listed), err)
+	}
+	if _, err := server.loadProcess(t.Context(), expired.Hex()); err == nil {
+		t.Fatal("expected soft-deleted process to be unavailable")
+	}
+	if _, err := store.LoadProcessByDigitalLink(t.Context(), "09506000134352", "LOT-1", expired.Hex()); err == nil {
+		t.Fatal("expected soft-deleted process to be unreachable by digital link")
+	}
+	if has, err := store.HasProcessesByWorkflow(t.Context(), "gone"); err != nil || has {
+		t.Fatalf("HasProcessesByWorkflow(gone) = %v, %v; want soft-deleted %s ignored", has, err, gone.Hex())
+	}
+
+	if purged, err := server.sweepRetention(t.Context(), "instance-b", cfg); err != nil || purged != 0 {
+		t.Fatalf("second instance sweep = %d, %v; want skipped while lock is held", purged, err)
+	}
+
+	cfg.HardDelete = true
+	purged, err = server.sweepRetention(t.Context(), "instance-a", cfg)
+	if err != nil || purged != 2 {
+		t.Fatalf("hard sweepRetention = %d, %v; want 2", purged, err)
+	}
+	if _, ok := store.SnapshotProcess(expired); ok {
+		t.Fatal("expected expired process to be hard-deleted")
+	}
+	if len(store.attachments) != 0 {
+		t.Fatalf("expected attachments of purged process to be removed, got %d", len(store.attachments))
+	}
+}
+
+func TestParseRuntimeConfigRejectsNegativeRetention(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "workflow.yaml")
+	writeRetentionWorkflowConfig(t, path, "-1")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	if _, err := parseRuntimeConfigData("workflow.yaml", data); err == nil || !strings.Contains(err.Error(), "retentionDays") {
+		t.Fatalf("expected retentionDays error, got %v", err)
+	}
+}
+
+func TestPurgeTerminatedProcessesMatchesLegacyDefaultWorkflow(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	legacy := store.SeedProcess(Process{
+		CreatedAt:   now.AddDate(0, 0, -60),
+		Status:      processStatusTerminated,
+		Termination: &ProcessTermination{EndedAt: now.AddDate(0, 0, -40)},
+	})
+	purged, err := store.PurgeTerminatedProcesses(t.Context(), "workflow", now.AddDate(0, 0, -30), true, now)
+	if err != nil || purged != 1 {
+		t.Fatalf("PurgeTerminatedProcesses = %d, %v; want the keyless process purged", purged, err)
+	}
+	if _, ok := store.SnapshotProcess(legacy); ok {
+		t.Fatal("expected legacy process to be hard-deleted")
+	}
+
+	collection := &fakeMongoCollection{
+		findFn: func(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (mongoCursorPort, error) {
+			return &fakeCursor{}, nil
+		},
+	}
+	mongoStore := &MongoStore{dbPort: &fakeMongoDatabase{collections: map[string]*fakeMongoCollection{"processes": collection}}}
+	if _, err := mongoStore.PurgeTerminatedProcesses(t.Context(), "workflow", now, false, now); err != nil {
+		t.Fatalf("PurgeTerminatedProcesses: %v", err)
+	}
+	filter := collection.findFilters[0].(bson.M)
+	want := []bson.M{{"workflowKey": "workflow"}, {"workflowKey": bson.M{"$exists": false}}}
+	if _, ok := filter["workflowKey"]; ok || !reflect.DeepEqual(filter["$or"], want) {
+		t.Fatalf("filter = %#v, want the legacy workflowKey $or", filter)
+	}
+}
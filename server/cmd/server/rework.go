@@ -0,0 +1,271 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ProcessRejection records a reviewer sending a completed substep back for
+// rework. ResetSubsteps lists every substep that was reset to pending: the
+// rejected one plus the completed substeps after it. Digest is the value
+// that was rejected; the submission itself stays in the notarizations.
+type ProcessRejection struct {
+	SubstepID       string    `bson:"substepId"`
+	Reason          string    `bson:"reason"`
+	RejectedAt      time.Time `bson:"rejectedAt"`
+	RejectedBy      *Actor    `bson:"rejectedBy,omitempty"`
+	ReviewSubstepID string    `bson:"reviewSubstepId,omitempty"`
+	Digest          string    `bson:"digest,omitempty"`
+	ResetSubsteps   []string  `bson:"resetSubsteps"`
+}
+
+func substepPosition(def WorkflowDef, substepID string) int {
+	for idx, sub := range orderedSubsteps(def) {
+		if sub.SubstepID == substepID {
+			return idx
+		}
+	}
+	return -1
+}
+
+// rejectingRoles returns the roles with which the reviewer, acting on the
+// available substep reviewer, may send target back: the reviewer's matching
+// roles when reviewer comes after target, narrowed by target.RejectRoles.
+func rejectingRoles(def WorkflowDef, target WorkflowSub, reviewer SubstepBodyView) []string {
+	targetPos := substepPosition(def, target.SubstepID)
+	if targetPos < 0 || substepPosition(def, reviewer.SubstepID) <= targetPos {
+		return nil
+	}
+	roles := make([]string, 0, len(reviewer.MatchingRoles))
+	for _, role := range reviewer.MatchingRoles {
+		if len(target.RejectRoles) > 0 && !containsRole(target.RejectRoles, role.Slug) {
+			continue
+		}
+		roles = append(roles, role.Slug)
+	}
+	return roles
+}
+
+// substepsToRework lists the substeps a rejection of targetID resets: the
+// target and every completed substep after it.
+func substepsToRework(def WorkflowDef, process *Process, targetID string) []string {
+	var reset []string
+	started := false
+	for _, sub := range orderedSubsteps(def) {
+		if sub.SubstepID == targetID {
+			started = true
+		}
+		if !started || process == nil {
+			continue
+		}
+		if step, ok := process.Progress[sub.SubstepID]; ok && step.State == "done" {
+			reset = append(reset, sub.SubstepID)
+		}
+	}
+	return reset
+}
+
+// latestRejection returns the most recent rejection of substepID, or nil.
+func latestRejection(process *Process, substepID string) *ProcessRejection {
+	if process == nil {
+		return nil
+	}
+	for idx := len(process.Rejections) - 1; idx >= 0; idx-- {
+		if process.Rejections[idx].SubstepID == substepID {
+			return &process.Rejections[idx]
+		}
+	}
+	return nil
+}
+
+// applyReworkNotice shows the latest rejection on a substep that is waiting
+// to be redone.
+func applyReworkNotice(view *SubstepBodyView, process *Process) {
+	if view.Status == "done" {
+		return
+	}
+	rejection := latestRejection(process, view.SubstepID)
+	if rejection == nil {
+		return
+	}
+	view.ReworkReason = rejection.Reason
+	view.ReworkAt = humanReadableTraceabilityTime(rejection.RejectedAt)
+	view.ReworkAtISO = rfc3339UTC(rejection.RejectedAt)
+	if rejection.RejectedBy != nil {
+		view.ReworkBy = strings.TrimSpace(rejection.RejectedBy.ID)
+	}
+}
+
+// markRejectableSubsteps offers "send back" on completed substeps the actor
+// may reject from the substep they can act on now.
+func markRejectableSubsteps(def WorkflowDef, workflowKey string, process *Process, actions []SubstepBodyView) {
+	reviewerIdx := -1
+	for idx, action := range actions {
+		if action.Status == "available" && !action.Disabled {
+			reviewerIdx = idx
+			break
+		}
+	}
+	if reviewerIdx < 0 {
+		return
+	}
+	reviewer := actions[reviewerIdx]
+	for idx := range actions {
+		if actions[idx].Status != "done" {
+			continue
+		}
+		target, _, err := findSubstep(def, actions[idx].SubstepID)
+		if err != nil {
+			continue
+		}
+		roles := rejectingRoles(def, target, reviewer)
+		if len(roles) == 0 {
+			continue
+		}
+		for _, role := range reviewer.MatchingRoles {
+			if containsRole(roles, role.Slug) {
+				actions[idx].RejectRoles = append(actions[idx].RejectRoles, role)
+			}
+		}
+		actions[idx].RejectURL = streamInstancePath(workflowKey, processIDString(process)) + "/substep/" + target.SubstepID + "/reject"
+	}
+}
+
+// handleRejectSubstep sends a completed substep back for rework. The caller
+// must be able to act on a later available substep (the review step) with a
+// role allowed by the target's rejectRoles; the target and every completed
+// substep after it return to pending.
+func (s *Server) handleRejectSubstep(w http.ResponseWriter, r *http.Request, processID, substepID string) {
+	user, _, ok := s.requireAuthenticatedPost(w, r)
+	if !ok {
+		return
+	}
+	workflowKey, cfg, selected := s.selectedWorkflowOrRedirectHome(w, r)
+	if !selected {
+		return
+	}
+	actor := actorFromAccountUser(user, workflowKey)
+	if len(actor.RoleSlugs) == 0 && !s.enforceAuth {
+		actor.RoleSlugs = s.roles(cfg)
+	}
+
+	ctx := r.Context()
+	process, err := s.loadProcess(ctx, processID)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			logRequestError(r, err, "failed to load process %s for substep %s rejection", processID, substepID)
+		}
+		s.renderActionErrorForRequest(w, r, http.StatusNotFound, "Process not found.", process, actor)
+		return
+	}
+	if !s.processBelongsToWorkflow(process, workflowKey) {
+		s.renderActionErrorForRequest(w, r, http.StatusNotFound, "Process not found.", process, actor)
+		return
+	}
+	if isProcessClosed(cfg.Workflow, process) {
+		s.renderActionErrorForRequest(w, r, http.StatusConflict, "Stream is already ended.", process, actor)
+		return
+	}
+	target, _, err := findSubstep(cfg.Workflow, substepID)
+	if err != nil {
+		s.renderActionErrorForRequest(w, r, http.StatusNotFound, "Substep not found.", process, actor)
+		return
+	}
+	progress, ok := process.Progress[target.SubstepID]
+	if !ok || progress.State != "done" {
+		s.renderActionErrorForRequest(w, r, http.StatusConflict, "Only completed substeps can be sent back.", process, actor)
+		return
+	}
+
+	_ = r.ParseForm()
+	reason := strings.TrimSpace(r.FormValue("reason"))
+	if reason == "" {
+		s.renderActionErrorForRequest(w, r, http.StatusBadRequest, "Rejection reason is required.", process, actor)
+		return
+	}
+	if len([]rune(reason)) > amendmentReasonMaxRunes {
+		s.renderActionErrorForRequest(w, r, http.StatusBadRequest, "Reason is too long.", process, actor)
+		return
+	}
+
+	reviewer, ok := nextAuthorizedSubstepBody(cfg.Workflow, process, workflowKey, actor, s.roleMetaIndex(ctx), cfg.Roles)
+	if !ok {
+		s.renderActionErrorForRequest(w, r, http.StatusForbidden, "Not authorized for this action.", process, actor)
+		return
+	}
+	allowedRoles := rejectingRoles(cfg.Workflow, target, reviewer)
+	activeRole := strings.TrimSpace(r.FormValue("activeRole"))
+	if activeRole == "" && len(allowedRoles) == 1 {
+		activeRole = allowedRoles[0]
+	}
+	if activeRole == "" || !containsRole(allowedRoles, activeRole) {
+		s.renderActionErrorForRequest(w, r, http.StatusForbidden, "Not authorized for this action.", process, actor)
+		return
+	}
+	actor.Role = activeRole
+	reviewSubstep, reviewStep, err := findSubstep(cfg.Workflow, reviewer.SubstepID)
+	if err != nil {
+		s.renderActionErrorForRequest(w, r, http.StatusNotFound, "Substep not found.", process, actor)
+		return
+	}
+
+	if s.authorizer == nil {
+		s.renderActionErrorForRequest(w, r, http.StatusBadGateway, "Cerbos check failed.", process, actor)
+		return
+	}
+	allowed, err := s.authorizer.CanComplete(ctx, actor, processID, workflowKey, reviewSubstep, reviewStep.Order, reviewStep.OrganizationSlug, true)
+	if err != nil {
+		logRequestError(r, err, "cerbos check failed for process %s substep %s rejection", processID, target.SubstepID)
+		status, message := authorizerErrorResponse(err)
+		s.renderActionErrorForRequest(w, r, status, message, process, actor)
+		return
+	}
+	if !allowed {
+		s.renderActionErrorForRequest(w, r, http.StatusForbidden, "Not authorized for this action.", process, actor)
+		return
+	}
+
+	now := s.nowUTC()
+	rejection := ProcessRejection{
+		SubstepID:       target.SubstepID,
+		Reason:          reason,
+		RejectedAt:      now,
+		RejectedBy:      &actor,
+		ReviewSubstepID: reviewer.SubstepID,
+		Digest:          currentStepDigest(progress),
+		ResetSubsteps:   substepsToRework(cfg.Workflow, process, target.SubstepID),
+	}
+	if err := s.store.RejectProcessSubstep(ctx, process.ID, workflowKey, rejection); errors.Is(err, ErrSubstepNotDone) {
+		s.renderActionErrorForRequest(w, r, http.StatusConflict, "Only completed substeps can be sent back.", process, actor)
+		return
+	} else if err != nil {
+		logRequestError(r, err, "failed to reject process %s substep %s", processID, target.SubstepID)
+		s.renderActionErrorForRequest(w, r, http.StatusInternalServerError, "Failed to update process.", process, actor)
+		return
+	}
+	appendProcessEvent(ctx, s.store, ProcessEvent{
+		ProcessID:   process.ID,
+		WorkflowKey: workflowKey,
+		Type:        processEventSubstepRejected,
+		Actor:       &actor,
+		SubstepID:   target.SubstepID,
+		At:          now,
+		Detail:      reason,
+	})
+
+	s.sse.Broadcast("process:"+workflowKey+":"+processID, "process-updated")
+	s.broadcastRolesUpdated(workflowKey, cfg)
+	if reloaded, err := s.loadProcess(ctx, processID); err == nil {
+		process = reloaded
+	}
+	nextReq := cloneRequestWithSelectedSubstep(r, target.SubstepID)
+	if isProcessContentTargetRequest(r) || isHTMXRequest(r) {
+		s.renderProcessContent(w, nextReq, process, actor, "")
+		return
+	}
+	s.renderDepartmentProcessPage(w, nextReq, process, actor, "")
+}
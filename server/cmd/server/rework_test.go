@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newServerForRejectTests(t *testing.T, store *MemoryStore) (*Server, string) {
+	t.Helper()
+	server, processID, now := newServerForCompleteTests(t, store, fakeAuthorizer{})
+	server.enforceAuth = true
+	server.identity = testIdentityForSessions(now, map[string]AccountUser{
+		"session-author":   {IdentityUserID: "u-1", RoleSlugs: []string{"dep1"}},
+		"session-reviewer": {IdentityUserID: "u-2", RoleSlugs: []string{"dep2"}},
+	})
+	id, _ := primitive.ObjectIDFromHex(processID)
+	for _, substepID := range []string{"1.1", "1.2", "1.3"} {
+		doneAt := now
+		step := ProcessStep{State: "done", DoneAt: &doneAt, DoneBy: &Actor{ID: "appwrite:u-1", Role: "dep1"}, Data: map[string]interface{}{"value": substepID}}
+		if err := store.UpdateProcessProgress(context.Background(), id, "", substepID, step); err != nil {
+			t.Fatalf("UpdateProcessProgress: %v", err)
+		}
+	}
+	return server, processID
+}
+
+func postReject(server *Server, processID, substepID, session string, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/process/"+processID+"/substep/"+substepID+"/reject", strings.NewReader(form.Encode()))
+	req.Header.Set("HX-Request", "true")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(&http.Cookie{Name: "attesta_session", Value: session})
+	rec := httptest.NewRecorder()
+	server.handleRejectSubstep(rec, req, processID, substepID)
+	return rec
+}
+
+func TestHandleRejectSubstepReopensTargetAndLaterSubsteps(t *testing.T) {
+	store := NewMemoryStore()
+	server, processID := newServerForRejectTests(t, store)
+	id, _ := primitive.ObjectIDFromHex(processID)
+
+	if rec := postReject(server, processID, "1.2", "session-reviewer", url.Values{}); rec.Code != http.StatusBadRequest {
+		t.Fatalf("missing reason status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if rec := postReject(server, processID, "1.2", "session-author", url.Values{"reason": {"wrong batch"}}); rec.Code != http.StatusForbidden {
+		t.Fatalf("author status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+	if rec := postReject(server, processID, "2.1", "session-reviewer", url.Values{"reason": {"wrong batch"}}); rec.Code != http.StatusConflict {
+		t.Fatalf("pending substep status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+
+	rec := postReject(server, processID, "1.2", "session-reviewer", url.Values{"reason": {"wrong batch"}})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("reject status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	snapshot, _ := store.SnapshotProcess(id)
+	progress := normalizeProgressKeys(snapshot.Progress)
+	if progress["1.1"].State != "done" || progress["1.2"].State != "pending" || progress["1.3"].State != "pending" {
+		t.Fatalf("unexpected progress after rejection %#v", progress)
+	}
+	if len(snapshot.Rejections) != 1 {
+		t.Fatalf("rejections = %#v", snapshot.Rejections)
+	}
+	rejection := snapshot.Rejections[0]
+	if rejection.SubstepID != "1.2" || rejection.Reason != "wrong batch" || rejection.ReviewSubstepID != "2.1" || rejection.RejectedBy == nil || rejection.RejectedBy.ID != "appwrite:u-2" || rejection.RejectedBy.Role != "dep2" {
+		t.Fatalf("unexpected rejection %#v", rejection)
+	}
+	if strings.Join(rejection.ResetSubsteps, ",") != "1.2,1.3" || rejection.Digest == "" {
+		t.Fatalf("unexpected reset list or digest %#v", rejection)
+	}
+	events, _ := store.ListProcessEvents(context.Background(), id)
+	if len(events) != 1 || events[0].Type != processEventSubstepRejected || events[0].SubstepID != "1.2" || events[0].Detail != "wrong batch" {
+		t.Fatalf("events = %#v", events)
+	}
+
+	process, _ := server.loadProcess(context.Background(), processID)
+	views := buildSubstepViews(testFormataRuntimeConfig().Workflow, process, "workflow", Actor{ID: "appwrite:u-1", RoleSlugs: []string{"dep1"}}, false, nil, nil)
+	rework := findSubstepView(t, views, "1.2")
+	if rework.Status != "available" || rework.ReworkReason != "wrong batch" || rework.ReworkBy != "appwrite:u-2" {
+		t.Fatalf("expected rework notice on 1.2, got %#v", rework)
+	}
+}
+
+func TestHandleRejectSubstepHonorsRejectRoles(t *testing.T) {
+	store := NewMemoryStore()
+	server, processID := newServerForRejectTests(t, store)
+	cfg := testFormataRuntimeConfig()
+	cfg.Workflow.Steps[0].Substep[1].RejectRoles = []string{"dep3"}
+	server.configProvider = func() (RuntimeConfig, error) { return cfg, nil }
+
+	if rec := postReject(server, processID, "1.2", "session-reviewer", url.Values{"reason": {"wrong batch"}}); rec.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+	if rec := postReject(server, processID, "1.1", "session-reviewer", url.Values{"reason": {"wrong batch"}}); rec.Code != http.StatusOK {
+		t.Fatalf("unrestricted substep status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+}
+
+func TestBuildSubstepViewsOffersRejectToReviewer(t *testing.T) {
+	cfg := testFormataRuntimeConfig()
+	process := processWithDone("1.1", "1.2", "1.3")
+	process.ID = primitive.NewObjectID()
+
+	reviewer := buildSubstepViews(cfg.Workflow, process, "workflow", Actor{ID: "appwrite:u-2", RoleSlugs: []string{"dep2"}}, false, nil, nil)
+	done := findSubstepView(t, reviewer, "1.3")
+	if done.RejectURL != streamInstancePath("workflow", process.ID.Hex())+"/substep/1.3/reject" || len(done.RejectRoles) != 1 || done.RejectRoles[0].Slug != "dep2" {
+		t.Fatalf("expected reject offer for reviewer, got url=%q roles=%#v", done.RejectURL, done.RejectRoles)
+	}
+	author := buildSubstepViews(cfg.Workflow, process, "workflow", Actor{ID: "appwrite:u-1", RoleSlugs: []string{"dep1"}}, false, nil, nil)
+	if view := findSubstepView(t, author, "1.3"); view.RejectURL != "" {
+		t.Fatalf("did not expect reject offer for author, got %q", view.RejectURL)
+	}
+}
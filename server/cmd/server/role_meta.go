@@ -67,17 +67,26 @@ func roleMetaForOrg(stepOrgSlug, roleSlug string, index map[roleMetaKey]RoleMeta
 	orgSlug := resolveRoleOrgSlug(stepOrgSlug, roleSlug, cfgRoles)
 	if orgSlug != "" {
 		if meta, ok := index[roleMetaKey{OrgSlug: orgSlug, RoleSlug: roleSlug}]; ok {
-			return meta
+			return withAutoRolePalette(meta)
 		}
 	}
 	for key, meta := range index {
 		if key.RoleSlug == roleSlug {
-			return meta
+			return withAutoRolePalette(meta)
 		}
 	}
 	return RoleMeta{
 		ID:      roleSlug,
 		Label:   roleSlug,
-		Palette: "fallback",
+		Palette: autoRolePalette(roleSlug),
 	}
 }
+
+// withAutoRolePalette keeps explicit palettes and gives roles without one a
+// palette derived from their slug.
+func withAutoRolePalette(meta RoleMeta) RoleMeta {
+	if meta.Palette == "fallback" {
+		meta.Palette = autoRolePalette(meta.ID)
+	}
+	return meta
+}
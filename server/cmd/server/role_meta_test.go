@@ -103,7 +103,7 @@ func TestRoleMetaForOrgScopedLookup(t *testing.T) {
 
 func TestRoleMetaForOrgFallbackWhenIdentityUnavailable(t *testing.T) {
 	got := roleMetaForOrg("org1", "unknown", map[roleMetaKey]RoleMeta{}, nil)
-	if got.Palette != "fallback" || got.Label != "unknown" {
+	if got.Palette != autoRolePalette("unknown") || got.Label != "unknown" {
 		t.Fatalf("fallback meta = %#v", got)
 	}
 	if got := (&Server{}).roleMetaIndex(context.Background()); len(got) != 0 {
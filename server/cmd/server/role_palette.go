@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// autoRolePalettes are the palettes roles without an explicit palette or
+// color are spread over. loadRolePalette sets them from ROLE_PALETTE at
+// startup; until then every palette except fallback is used.
+var autoRolePalettes = defaultAutoRolePaletteKeys()
+
+// autoRolePaletteKeys parses ROLE_PALETTE: comma-separated rolePaletteStyles
+// keys. Unset, it returns defaultAutoRolePaletteKeys.
+func autoRolePaletteKeys() ([]string, error) {
+	var keys []string
+	for _, item := range splitCSVEnv("ROLE_PALETTE") {
+		key := canonifySlug(item)
+		if _, ok := rolePaletteStyles[key]; !ok {
+			return nil, fmt.Errorf("invalid ROLE_PALETTE entry %q", item)
+		}
+		if !containsRole(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return defaultAutoRolePaletteKeys(), nil
+	}
+	return keys, nil
+}
+
+func defaultAutoRolePaletteKeys() []string {
+	keys := make([]string, 0, len(rolePaletteKeys))
+	for _, key := range rolePaletteKeys {
+		if _, ok := rolePaletteStyles[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// loadRolePalette parses ROLE_PALETTE once into autoRolePalettes. An unknown
+// palette fails startup instead of silently rendering those roles in
+// fallback grey.
+func loadRolePalette() error {
+	keys, err := autoRolePaletteKeys()
+	if err != nil {
+		return err
+	}
+	autoRolePalettes = keys
+	return nil
+}
+
+// autoRolePalette hashes roleSlug into autoRolePalettes so roles without
+// explicit colors still get distinct, stable badges.
+func autoRolePalette(roleSlug string) string {
+	if palette := hashRolePalette(autoRolePalettes, roleSlug); palette != "" {
+		return palette
+	}
+	return "fallback"
+}
+
+// hashRolePalette picks a stable entry of keys for raw, ignoring case and
+// repeated whitespace. It returns "" when raw is blank or keys is empty.
+func hashRolePalette(keys []string, raw string) string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(strings.TrimSpace(raw)), " "))
+	if normalized == "" || len(keys) == 0 {
+		return ""
+	}
+	hasher := fnv.New32a()
+	_, _ = hasher.Write([]byte(normalized))
+	return keys[int(hasher.Sum32()%uint32(len(keys)))]
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// setRolePalette loads value as ROLE_PALETTE for the rest of the test.
+func setRolePalette(t *testing.T, value string) {
+	t.Helper()
+	previous := autoRolePalettes
+	t.Cleanup(func() { autoRolePalettes = previous })
+	t.Setenv("ROLE_PALETTE", value)
+	if err := loadRolePalette(); err != nil {
+		t.Fatalf("loadRolePalette(%q): %v", value, err)
+	}
+}
+
+func TestAutoRolePaletteKeys(t *testing.T) {
+	t.Setenv("ROLE_PALETTE", "")
+	keys, err := autoRolePaletteKeys()
+	if err != nil || len(keys) != len(rolePaletteStyles) || containsRole(keys, "fallback") {
+		t.Fatalf("default keys = %v err = %v", keys, err)
+	}
+
+	t.Setenv("ROLE_PALETTE", " Teal, sky,teal ,violet")
+	keys, err = autoRolePaletteKeys()
+	if err != nil || !reflect.DeepEqual(keys, []string{"teal", "sky", "violet"}) {
+		t.Fatalf("configured keys = %v err = %v", keys, err)
+	}
+
+	setRolePalette(t, "sky")
+	t.Setenv("ROLE_PALETTE", "teal,mauve")
+	if err := loadRolePalette(); err == nil {
+		t.Fatal("expected unknown palette to fail loading")
+	}
+	if !reflect.DeepEqual(autoRolePalettes, []string{"sky"}) {
+		t.Fatalf("palettes after failed load = %v, want the previous ones", autoRolePalettes)
+	}
+}
+
+func TestAutoRolePalette(t *testing.T) {
+	setRolePalette(t, "teal,sky,violet")
+	seen := map[string]bool{}
+	for _, slug := range []string{"dep1", "dep2", "dep3", "qa", "logistics", "lab"} {
+		got := autoRolePalette(slug)
+		if !containsRole([]string{"teal", "sky", "violet"}, got) {
+			t.Fatalf("palette for %q = %q, outside ROLE_PALETTE", slug, got)
+		}
+		if again := autoRolePalette(slug); again != got {
+			t.Fatalf("palette for %q not stable: %q vs %q", slug, got, again)
+		}
+		seen[got] = true
+	}
+	if len(seen) < 2 {
+		t.Fatalf("expected roles to spread over the palette, got %v", seen)
+	}
+	if got := autoRolePalette(" "); got != "fallback" {
+		t.Fatalf("blank slug palette = %q, want fallback", got)
+	}
+
+	t.Setenv("ROLE_PALETTE", "rose")
+	if got := autoRolePalette("dep1"); got == "rose" {
+		t.Fatal("expected ROLE_PALETTE to be read only by loadRolePalette")
+	}
+}
+
+func TestRoleMetaForOrgKeepsExplicitPalette(t *testing.T) {
+	setRolePalette(t, "teal")
+	index := map[roleMetaKey]RoleMeta{
+		{OrgSlug: "org1", RoleSlug: "qa"}:  {ID: "qa", Label: "QA", Palette: "rose"},
+		{OrgSlug: "org1", RoleSlug: "lab"}: {ID: "lab", Label: "Lab", Palette: "fallback"},
+	}
+	if got := roleMetaForOrg("org1", "qa", index, nil); got.Palette != "rose" {
+		t.Fatalf("explicit palette = %q, want rose", got.Palette)
+	}
+	if got := roleMetaForOrg("org1", "lab", index, nil); got.Palette != "teal" || got.Label != "Lab" {
+		t.Fatalf("uncolored role meta = %#v", got)
+	}
+	if got := roleMetaForOrg("org1", "", index, nil); got.Palette != "fallback" {
+		t.Fatalf("blank role palette = %q, want fallback", got.Palette)
+	}
+}
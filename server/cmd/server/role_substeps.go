@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+)
+
+type RoleSubstepView struct {
+	StepID    string `json:"step_id"`
+	StepTitle string `json:"step_title"`
+	SubstepID string `json:"substep_id"`
+	Title     string `json:"title"`
+	Order     int    `json:"order"`
+}
+
+// RoleSubstepsResponse is roles/:role/substeps.json: the substeps of the
+// workflow definition a role may complete, in workflow order.
+type RoleSubstepsResponse struct {
+	WorkflowKey string            `json:"workflow_key"`
+	Role        string            `json:"role"`
+	Substeps    []RoleSubstepView `json:"substeps"`
+}
+
+// roleSubsteps lists the substeps of def whose substepRoles include role.
+func roleSubsteps(def WorkflowDef, role string) []RoleSubstepView {
+	substeps := []RoleSubstepView{}
+	for _, step := range sortedSteps(def) {
+		for _, sub := range sortedSubsteps(step) {
+			if !containsRole(substepRoles(sub), role) {
+				continue
+			}
+			substeps = append(substeps, RoleSubstepView{
+				StepID:    step.StepID,
+				StepTitle: step.Title,
+				SubstepID: sub.SubstepID,
+				Title:     sub.Title,
+				Order:     sub.Order,
+			})
+		}
+	}
+	return substeps
+}
+
+// handleRoleSubsteps serves GET /my/streams/:key/roles/:role/substeps.json.
+// It only reads the workflow definition, so no process is involved; unknown
+// roles are 404.
+func (s *Server) handleRoleSubsteps(w http.ResponseWriter, r *http.Request, role string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if _, _, ok := s.requireAuthenticatedPage(w, r); !ok {
+		return
+	}
+	workflowKey, cfg, err := s.selectedWorkflowUnvalidated(r)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	role = strings.TrimSpace(role)
+	if role == "" || !s.isKnownRole(cfg, role) {
+		http.Error(w, "unknown role", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, RoleSubstepsResponse{
+		WorkflowKey: workflowKey,
+		Role:        role,
+		Substeps:    roleSubsteps(cfg.Workflow, role),
+	})
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRoleSubstepsListsSubstepsInWorkflowOrder(t *testing.T) {
+	def := testRuntimeConfig().Workflow
+	def.Steps[0].Substep[1].Roles = []string{"dep2", "dep1"}
+
+	got := roleSubsteps(def, "dep2")
+	want := []string{"1.2", "2.1", "2.2"}
+	if len(got) != len(want) {
+		t.Fatalf("roleSubsteps(dep2) = %+v, want %v", got, want)
+	}
+	for idx, id := range want {
+		if got[idx].SubstepID != id {
+			t.Fatalf("roleSubsteps(dep2)[%d] = %s, want %s", idx, got[idx].SubstepID, id)
+		}
+	}
+	if got[0].StepID != "1" || got[0].Title != "B" || got[0].Order != 2 {
+		t.Fatalf("unexpected substep view %+v", got[0])
+	}
+	if got := roleSubsteps(def, "nobody"); got == nil || len(got) != 0 {
+		t.Fatalf("expected empty list for a role without substeps, got %#v", got)
+	}
+}
+
+func TestHandleRoleSubstepsRoute(t *testing.T) {
+	tempDir := t.TempDir()
+	writeWorkflowConfig(t, tempDir+"/workflow.yaml", "Main workflow", "string")
+	server := &Server{
+		store:      NewMemoryStore(),
+		tmpl:       testTemplates(),
+		authorizer: fakeAuthorizer{},
+		sse:        newSSEHub(),
+		now:        func() time.Time { return time.Date(2026, 2, 4, 11, 0, 0, 0, time.UTC) },
+		configDir:  tempDir,
+	}
+
+	rec := httptest.NewRecorder()
+	server.handleStreamRoutes(rec, httptest.NewRequest(http.MethodGet, "/streams/workflow/roles/dep1/substeps.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d (%s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var response RoleSubstepsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if response.WorkflowKey != "workflow" || response.Role != "dep1" || len(response.Substeps) != 1 || response.Substeps[0].SubstepID != "1.1" || response.Substeps[0].StepTitle != "Step 1" {
+		t.Fatalf("unexpected response %+v", response)
+	}
+
+	for _, path := range []string{"/streams/workflow/roles/ghost/substeps.json", "/streams/workflow/roles/dep1/x/substeps.json"} {
+		rec = httptest.NewRecorder()
+		server.handleStreamRoutes(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("%s status = %d, want %d", path, rec.Code, http.StatusNotFound)
+		}
+	}
+
+	server.enforceAuth = true
+	rec = httptest.NewRecorder()
+	server.handleStreamRoutes(rec, httptest.NewRequest(http.MethodGet, "/streams/workflow/roles/dep1/substeps.json", nil))
+	if rec.Code != http.StatusSeeOther || !strings.HasPrefix(rec.Header().Get("Location"), "/login?next=") {
+		t.Fatalf("anonymous status = %d location = %q, want login redirect", rec.Code, rec.Header().Get("Location"))
+	}
+}
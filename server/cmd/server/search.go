@@ -0,0 +1,266 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	processSearchMaxResults   = 50
+	processSearchMaxRunes     = 200
+	processSearchContextRunes = 40
+)
+
+type ProcessSearchResponse struct {
+	WorkflowKey string                `json:"workflow_key"`
+	Query       string                `json:"query"`
+	Results     []ProcessSearchResult `json:"results"`
+}
+
+type ProcessSearchResult struct {
+	ProcessListItem
+	Matches []ProcessSearchMatch `json:"matches"`
+}
+
+// ProcessSearchMatch points at the substep value that matched, or at the
+// process metadata key when MetadataKey is set. The value is split around the
+// match so clients can highlight it without parsing HTML.
+type ProcessSearchMatch struct {
+	SubstepID   string `json:"substep_id"`
+	MetadataKey string `json:"metadata_key,omitempty"`
+	Title       string `json:"title"`
+	Before      string `json:"before"`
+	Match       string `json:"match"`
+	After       string `json:"after"`
+}
+
+// searchableStrings flattens the string leaves of a substep payload. Only the
+// filename of attachment metadata is kept; ids and hashes are noise.
+func searchableStrings(data map[string]interface{}) []string {
+	var leaves []string
+	var walk func(value interface{})
+	walk = func(value interface{}) {
+		switch typed := value.(type) {
+		case string:
+			if trimmed := strings.TrimSpace(typed); trimmed != "" {
+				leaves = append(leaves, trimmed)
+			}
+		case []string:
+			for _, item := range typed {
+				walk(item)
+			}
+		case []interface{}:
+			for _, item := range typed {
+				walk(item)
+			}
+		case primitive.A:
+			walk([]interface{}(typed))
+		case primitive.M:
+			walk(map[string]interface{}(typed))
+		case map[string]interface{}:
+			if meta := attachmentMetaFromMap(typed); meta != nil {
+				walk(meta.Filename)
+				return
+			}
+			for _, key := range sortedMapKeys(typed) {
+				walk(typed[key])
+			}
+		}
+	}
+	walk(data)
+	return leaves
+}
+
+func sortedMapKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// processSearchText is the value stored in Process.SearchText: every string
+// leaf of every completed substep, including amended values.
+func processSearchText(progress map[string]ProcessStep) []string {
+	text := []string{}
+	for _, key := range sortedProgressKeys(progress) {
+		step := progress[key]
+		if step.State != "done" {
+			continue
+		}
+		for _, data := range stepDataHistory(step) {
+			text = append(text, searchableStrings(data)...)
+		}
+	}
+	return dedupeStrings(text)
+}
+
+// processIndexText is processSearchText plus the metadata values, the full
+// searchText of a process. Metadata updates rebuild it so removed values stop
+// matching.
+func processIndexText(process Process) []string {
+	text := processSearchText(process.Progress)
+	for _, key := range sortedMetadataKeys(process.Metadata) {
+		text = append(text, process.Metadata[key])
+	}
+	return dedupeStrings(text)
+}
+
+func sortedProgressKeys(progress map[string]ProcessStep) []string {
+	keys := make([]string, 0, len(progress))
+	for key := range progress {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// processMatchesSearch reports whether any completed substep value or
+// metadata value contains query, ignoring case.
+func processMatchesSearch(process Process, query string) bool {
+	needle := strings.ToLower(strings.TrimSpace(query))
+	if needle == "" {
+		return false
+	}
+	for _, leaf := range processIndexText(process) {
+		if strings.Contains(strings.ToLower(leaf), needle) {
+			return true
+		}
+	}
+	return false
+}
+
+// processSearchMatches finds, per completed substep in workflow order, the
+// first value containing query, then the matching metadata values by key.
+// The current value wins over earlier amendments.
+func processSearchMatches(workflow WorkflowDef, process *Process, query string) []ProcessSearchMatch {
+	matches := []ProcessSearchMatch{}
+	for _, step := range workflow.Steps {
+		for _, substep := range step.Substep {
+			progress, ok := process.Progress[substep.SubstepID]
+			if !ok || progress.State != "done" {
+				continue
+			}
+			history := stepDataHistory(progress)
+			for idx := len(history) - 1; idx >= 0; idx-- {
+				if match, ok := searchMatchInData(history[idx], query); ok {
+					match.SubstepID = substep.SubstepID
+					match.Title = substep.Title
+					matches = append(matches, match)
+					break
+				}
+			}
+		}
+	}
+	for _, key := range sortedMetadataKeys(process.Metadata) {
+		if match, ok := searchMatchInData(map[string]interface{}{key: process.Metadata[key]}, query); ok {
+			match.MetadataKey = key
+			match.Title = key
+			matches = append(matches, match)
+		}
+	}
+	return matches
+}
+
+func searchMatchInData(data map[string]interface{}, query string) (ProcessSearchMatch, bool) {
+	needle := []rune(strings.ToLower(strings.TrimSpace(query)))
+	for _, leaf := range searchableStrings(data) {
+		runes := []rune(leaf)
+		lower := []rune(strings.ToLower(leaf))
+		if len(lower) != len(runes) {
+			runes = lower
+		}
+		start := runeIndex(lower, needle)
+		if start < 0 {
+			continue
+		}
+		end := start + len(needle)
+		before, after := runes[:start], runes[end:]
+		match := ProcessSearchMatch{Match: string(runes[start:end])}
+		if len(before) > processSearchContextRunes {
+			match.Before = "…" + string(before[len(before)-processSearchContextRunes:])
+		} else {
+			match.Before = string(before)
+		}
+		if len(after) > processSearchContextRunes {
+			match.After = string(after[:processSearchContextRunes]) + "…"
+		} else {
+			match.After = string(after)
+		}
+		return match, true
+	}
+	return ProcessSearchMatch{}, false
+}
+
+func runeIndex(haystack, needle []rune) int {
+	if len(needle) == 0 {
+		return -1
+	}
+	for start := 0; start+len(needle) <= len(haystack); start++ {
+		if string(haystack[start:start+len(needle)]) == string(needle) {
+			return start
+		}
+	}
+	return -1
+}
+
+// handleSearchProcesses serves GET /my/streams/:key/processes/search?q=…,
+// returning the stream instances whose completed substep values contain q.
+func (s *Server) handleSearchProcesses(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, _, ok := s.requireAuthenticatedPost(w, r)
+	if !ok {
+		return
+	}
+	workflowKey, cfg, err := s.selectedWorkflowUnvalidated(r)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if s.store == nil {
+		http.Error(w, "store not configured", http.StatusInternalServerError)
+		return
+	}
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		http.Error(w, "missing search query", http.StatusBadRequest)
+		return
+	}
+	if len([]rune(query)) > processSearchMaxRunes {
+		http.Error(w, "search query too long", http.StatusBadRequest)
+		return
+	}
+
+	processes, err := s.store.SearchProcesses(r.Context(), workflowKey, query, processSearchMaxResults)
+	if err != nil {
+		logAndHTTPError(w, r, http.StatusInternalServerError, "failed to search processes", err, "failed to search processes for workflow %s", workflowKey)
+		return
+	}
+	processes = s.visibleProcesses(user, cfg, processes)
+
+	response := ProcessSearchResponse{
+		WorkflowKey: workflowKey,
+		Query:       query,
+		Results:     make([]ProcessSearchResult, 0, len(processes)),
+	}
+	for idx := range processes {
+		process := &processes[idx]
+		process.Progress = normalizeProgressKeys(process.Progress)
+		matches := processSearchMatches(cfg.Workflow, process, query)
+		if len(matches) == 0 {
+			continue
+		}
+		response.Results = append(response.Results, ProcessSearchResult{
+			ProcessListItem: processListItem(workflowKey, cfg, process),
+			Matches:         matches,
+		})
+	}
+	writeJSON(w, response)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestSearchableStringsFlattensNestedPayload(t *testing.T) {
+	data := map[string]interface{}{
+		"invoice": map[string]interface{}{"number": "Invoice #12345", "amount": 10.5},
+		"tags":    []interface{}{"urgent", primitive.M{"note": " checked "}},
+		"scan": map[string]interface{}{
+			"attachmentId": primitive.NewObjectID().Hex(),
+			"filename":     "scan.pdf",
+			"sha256":       "abc",
+		},
+	}
+	got := searchableStrings(data)
+	want := []string{"Invoice #12345", "scan.pdf", "urgent", "checked"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("searchableStrings = %#v, want %#v", got, want)
+	}
+}
+
+func TestHandleSearchProcesses(t *testing.T) {
+	now := time.Date(2026, 2, 4, 11, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	match := store.SeedProcess(Process{WorkflowKey: "workflow", CreatedAt: now, Status: "active", Progress: map[string]ProcessStep{}})
+	other := store.SeedProcess(Process{WorkflowKey: "workflow", CreatedAt: now, Status: "active", Progress: map[string]ProcessStep{}})
+	store.SeedProcess(Process{WorkflowKey: "elsewhere", CreatedAt: now, Status: "active", Progress: map[string]ProcessStep{
+		"1_1": {State: "done", Data: map[string]interface{}{"value": "invoice #12345"}},
+	}})
+	for id, value := range map[primitive.ObjectID]string{match: "Paid with Invoice #12345 on delivery", other: "invoice #999"} {
+		if err := store.UpdateProcessProgress(context.Background(), id, "workflow", "1.2", ProcessStep{State: "done", Data: map[string]interface{}{"value": value}}); err != nil {
+			t.Fatalf("UpdateProcessProgress: %v", err)
+		}
+	}
+	if snapshot, _ := store.SnapshotProcess(match); !reflect.DeepEqual(snapshot.SearchText, []string{"Paid with Invoice #12345 on delivery"}) {
+		t.Fatalf("SearchText = %#v", snapshot.SearchText)
+	}
+
+	server := &Server{store: store, now: func() time.Time { return now }}
+	search := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/processes/search"+query, nil)
+		req = req.WithContext(context.WithValue(req.Context(), workflowContextKey{}, workflowContextValue{
+			Key: "workflow",
+			Cfg: testRuntimeConfig(),
+		}))
+		rec := httptest.NewRecorder()
+		server.handleSearchProcesses(rec, req)
+		return rec
+	}
+
+	if rec := search(""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("empty query status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	rec := search("?q=invoice+%2312345")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	var response ProcessSearchResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(response.Results) != 1 || response.Results[0].ProcessID != match.Hex() {
+		t.Fatalf("results = %#v, want only %s", response.Results, match.Hex())
+	}
+	want := []ProcessSearchMatch{{SubstepID: "1.2", Title: "B", Before: "Paid with ", Match: "Invoice #12345", After: " on delivery"}}
+	if !reflect.DeepEqual(response.Results[0].Matches, want) {
+		t.Fatalf("matches = %#v, want %#v", response.Results[0].Matches, want)
+	}
+}
+
+func TestMongoStoreSearchProcesses(t *testing.T) {
+	collection := &fakeMongoCollection{
+		findFn: func(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (mongoCursorPort, error) {
+			return &fakeCursor{docs: []Process{{ID: primitive.NewObjectID()}}}, nil
+		},
+	}
+	db := &fakeMongoDatabase{collections: map[string]*fakeMongoCollection{"processes": collection}}
+	store := &MongoStore{dbPort: db}
+
+	processes, err := store.SearchProcesses(t.Context(), "wf-a", ` say "hi" (v1.2) `, 50)
+	if err != nil || len(processes) != 1 {
+		t.Fatalf("SearchProcesses = %d, %v", len(processes), err)
+	}
+	want := bson.M{
+		"workflowKey": "wf-a",
+		"searchText":  bson.M{"$regex": `say "hi" \(v1\.2\)`, "$options": "i"},
+		"deletedAt":   bson.M{"$exists": false},
+	}
+	if !reflect.DeepEqual(collection.findFilters[0], want) {
+		t.Fatalf("find filter = %#v, want %#v", collection.findFilters[0], want)
+	}
+
+	pattern := regexp.MustCompile("(?i)" + collection.findFilters[0].(bson.M)["searchText"].(bson.M)["$regex"].(string))
+	for _, value := range []string{`Please SAY "hi" (v1.2) now`, `say "hi" (v1x2)`} {
+		process := Process{Metadata: map[string]string{"note": value}}
+		if got, want := pattern.MatchString(value), processMatchesSearch(process, ` say "hi" (v1.2) `); got != want {
+			t.Fatalf("%q: mongo pattern match = %v, memory match = %v", value, got, want)
+		}
+	}
+
+	progress := ProcessStep{State: "done", Data: map[string]interface{}{"value": "invoice"}}
+	if err := store.UpdateProcessProgress(t.Context(), primitive.NewObjectID(), "wf-a", "1.1", progress); err != nil {
+		t.Fatalf("UpdateProcessProgress: %v", err)
+	}
+	update, _ := collection.findOneAndUpdUpdate[0].(bson.M)
+	if !reflect.DeepEqual(update["$addToSet"], bson.M{"searchText": bson.M{"$each": []string{"invoice"}}}) {
+		t.Fatalf("update doc = %#v, want searchText $addToSet", update)
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	shareLinkDefaultDays = 7
+	shareLinkMaxDays     = 90
+	shareLinkReadOnlyMsg = "Shared read-only view."
+)
+
+type ShareLinkResponse struct {
+	URL              string `json:"url"`
+	ExpiresAt        string `json:"expires_at"`
+	IncludeNotarized bool   `json:"include_notarized"`
+}
+
+func sharePath(token string) string {
+	return "/share/" + strings.TrimSpace(token)
+}
+
+// handleCreateShareLink issues a token for POST
+// /my/streams/:key/instance/:id/share. The plain token is only returned
+// once; the store keeps its hash.
+func (s *Server) handleCreateShareLink(w http.ResponseWriter, r *http.Request, processID string) {
+	user, _, ok := s.requireAuthenticatedPost(w, r)
+	if !ok {
+		return
+	}
+	workflowKey, cfg, ok := s.selectedWorkflowOrRedirectHome(w, r)
+	if !ok {
+		return
+	}
+	process, err := s.loadProcess(r.Context(), processID)
+	if err != nil || !s.processBelongsToWorkflow(process, workflowKey) {
+		http.Error(w, "process not found", http.StatusNotFound)
+		return
+	}
+	if !s.processVisible(user, cfg, process) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+	days := shareLinkDefaultDays
+	if raw := strings.TrimSpace(r.FormValue("expiresInDays")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > shareLinkMaxDays {
+			http.Error(w, "expiresInDays must be between 1 and 90", http.StatusBadRequest)
+			return
+		}
+		days = parsed
+	}
+	includeNotarized := false
+	if raw := strings.TrimSpace(r.FormValue("notarized")); raw != "" {
+		includeNotarized, err = strconv.ParseBool(raw)
+		if err != nil {
+			http.Error(w, "notarized must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
+
+	token, err := newSessionID()
+	if err != nil {
+		logAndHTTPError(w, r, http.StatusInternalServerError, "failed to create share link", err, "failed to generate share token for process %s", processID)
+		return
+	}
+	now := s.nowUTC()
+	link, err := s.store.CreateShareLink(r.Context(), ShareLink{
+		TokenHash:        hashLookupToken(token),
+		ProcessID:        process.ID,
+		WorkflowKey:      workflowKey,
+		IncludeNotarized: includeNotarized,
+		CreatedBy:        accountActorID(user),
+		CreatedAt:        now,
+		ExpiresAt:        now.Add(time.Duration(days) * 24 * time.Hour),
+	})
+	if err != nil {
+		logAndHTTPError(w, r, http.StatusInternalServerError, "failed to create share link", err, "failed to store share link for process %s", processID)
+		return
+	}
+	writeJSON(w, ShareLinkResponse{
+		URL:              s.requestBaseURL(r) + sharePath(token),
+		ExpiresAt:        rfc3339UTC(link.ExpiresAt),
+		IncludeNotarized: link.IncludeNotarized,
+	})
+}
+
+// handleShare serves GET /share/:token and, when the link allows it,
+// /share/:token/notarized.json. It never consults the session: the token is
+// the only credential and it is bound to one process.
+func (s *Server) handleShare(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/share/"), "/"), "/")
+	token := parts[0]
+	if token == "" || len(parts) > 2 || (len(parts) == 2 && parts[1] != "notarized.json" && parts[1] != "notarized.json.sig") {
+		http.NotFound(w, r)
+		return
+	}
+	link, process, cfg, ok := s.resolveShareLink(w, r, token)
+	if !ok {
+		return
+	}
+
+	if len(parts) == 2 {
+		if !link.IncludeNotarized {
+			http.NotFound(w, r)
+			return
+		}
+		export := buildNotarizedExport(cfg.Workflow, process)
+		if parts[1] == "notarized.json.sig" {
+			s.writeNotarizedSignature(w, r, export)
+			return
+		}
+		s.writeNotarizedExport(w, r, export)
+		return
+	}
+
+	actor := Actor{WorkflowKey: link.WorkflowKey}
+	view := s.buildProcessPageView(
+		r.Context(),
+		s.pageBase("process_body", link.WorkflowKey, cfg.Workflow.Name),
+		cfg,
+		link.WorkflowKey,
+		process,
+		actor,
+		"",
+		"",
+		false,
+	)
+	view.Detail = makeStreamInstanceDetailReadOnly(view.Detail, shareLinkReadOnlyMsg)
+	view.Breadcrumbs = BreadcrumbsView{}
+	view.Attachments = nil
+	view = view.withAttachmentTotals()
+	view.Shared = true
+	view.MetadataURL = ""
+	if link.IncludeNotarized {
+		view.SharedNotarizedURL = sharePath(token) + "/notarized.json"
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Robots-Tag", "noindex")
+	if err := s.tmpl.ExecuteTemplate(w, "process.html", view); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *Server) resolveShareLink(w http.ResponseWriter, r *http.Request, token string) (*ShareLink, *Process, RuntimeConfig, bool) {
+	link, err := s.store.LoadShareLinkByTokenHash(r.Context(), hashLookupToken(token))
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			logRequestError(r, err, "failed to load share link")
+		}
+		http.NotFound(w, r)
+		return nil, nil, RuntimeConfig{}, false
+	}
+	if !link.ExpiresAt.After(s.nowUTC()) {
+		http.Error(w, "share link expired", http.StatusGone)
+		return nil, nil, RuntimeConfig{}, false
+	}
+	process, err := s.loadProcess(r.Context(), link.ProcessID.Hex())
+	if err != nil || !s.processBelongsToWorkflow(process, link.WorkflowKey) {
+		http.NotFound(w, r)
+		return nil, nil, RuntimeConfig{}, false
+	}
+	cfg, err := s.workflowByKey(link.WorkflowKey)
+	if err != nil {
+		http.NotFound(w, r)
+		return nil, nil, RuntimeConfig{}, false
+	}
+	return link, process, cfg, true
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestShareLinkLifecycle(t *testing.T) {
+	tempDir := t.TempDir()
+	writeWorkflowConfig(t, filepath.Join(tempDir, "workflow.yaml"), "Main workflow", "string")
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	doneAt := now.Add(-time.Hour)
+	store := NewMemoryStore()
+	processID := store.SeedProcess(Process{
+		WorkflowKey: "workflow",
+		CreatedAt:   now.Add(-2 * time.Hour),
+		Status:      "done",
+		Progress: map[string]ProcessStep{
+			"1_1": {State: "done", DoneAt: &doneAt, DoneBy: &Actor{ID: "u1", Role: "dep1"}, Data: map[string]interface{}{"value": "lot-42"}},
+		},
+	})
+	server := &Server{
+		store:      store,
+		tmpl:       parseTestTemplates(t),
+		authorizer: fakeAuthorizer{},
+		configDir:  tempDir,
+		now:        func() time.Time { return now },
+	}
+	cfg, err := server.workflowByKey("workflow")
+	if err != nil {
+		t.Fatalf("workflowByKey: %v", err)
+	}
+
+	create := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/instance/"+processID.Hex()+"/share", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req = req.WithContext(context.WithValue(req.Context(), workflowContextKey{}, workflowContextValue{Key: "workflow", Cfg: cfg}))
+		rec := httptest.NewRecorder()
+		server.handleCreateShareLink(rec, req, processID.Hex())
+		return rec
+	}
+	shareToken := func(rec *httptest.ResponseRecorder) string {
+		t.Helper()
+		var response ShareLinkResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("decode share response: %v (%s)", err, rec.Body.String())
+		}
+		_, token, found := strings.Cut(response.URL, "/share/")
+		if !found || token == "" {
+			t.Fatalf("unexpected share url %q", response.URL)
+		}
+		return token
+	}
+	mux := server.newMux()
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	if rec := create(url.Values{"expiresInDays": {"120"}}); rec.Code != http.StatusBadRequest {
+		t.Fatalf("out-of-range expiry status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	rec := create(url.Values{"expiresInDays": {"2"}, "notarized": {"true"}})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("create status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	token := shareToken(rec)
+	if link, err := store.LoadShareLinkByTokenHash(t.Context(), hashLookupToken(token)); err != nil || link.ProcessID != processID || !link.ExpiresAt.Equal(now.Add(48*time.Hour)) {
+		t.Fatalf("stored link = %#v, %v", link, err)
+	}
+	if _, err := store.LoadShareLinkByTokenHash(t.Context(), token); err == nil {
+		t.Fatal("expected the plain token not to be stored")
+	}
+
+	page := get("/share/" + token)
+	if page.Code != http.StatusOK {
+		t.Fatalf("share page status = %d body = %s", page.Code, page.Body.String())
+	}
+	body := page.Body.String()
+	if !strings.Contains(body, processID.Hex()) || !strings.Contains(body, `href="/share/`+token+`/notarized.json"`) {
+		t.Fatalf("expected shared process page with notarized link, got %s", body)
+	}
+	if strings.Contains(body, "data-process-id") || strings.Contains(body, "files.zip") {
+		t.Fatalf("expected shared page without live updates or authenticated downloads, got %s", body)
+	}
+
+	notarized := get("/share/" + token + "/notarized.json")
+	if notarized.Code != http.StatusOK || !strings.Contains(notarized.Body.String(), processID.Hex()) {
+		t.Fatalf("notarized status = %d body = %s", notarized.Code, notarized.Body.String())
+	}
+	if rec := get("/share/" + token + "/notarized.json.sig"); rec.Code != http.StatusNotFound {
+		t.Fatalf("signature without key status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	server.exportSigner = &exportSigner{key: []byte("secret")}
+	var signed NotarizedProcessExport
+	if err := json.Unmarshal(get("/share/"+token+"/notarized.json").Body.Bytes(), &signed); err != nil || signed.Signature == nil {
+		t.Fatalf("expected signed shared export, got %#v (%v)", signed.Signature, err)
+	}
+	if rec := get("/share/" + token + "/notarized.json.sig"); rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != signed.Signature.Value {
+		t.Fatalf("shared signature status = %d body = %q, want %q", rec.Code, rec.Body.String(), signed.Signature.Value)
+	}
+	server.exportSigner = nil
+
+	pageOnly := shareToken(create(url.Values{}))
+	if rec := get("/share/" + pageOnly + "/notarized.json"); rec.Code != http.StatusNotFound {
+		t.Fatalf("notarized without permission status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if rec := get("/share/" + token + "x"); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown token status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	now = now.Add(49 * time.Hour)
+	if rec := get("/share/" + token); rec.Code != http.StatusGone {
+		t.Fatalf("expired link status = %d, want %d", rec.Code, http.StatusGone)
+	}
+}
+
+func TestShareLinkTokenHashIsUnique(t *testing.T) {
+	db := &fakeMongoDatabase{}
+	mongoStore := &MongoStore{dbPort: db}
+	if err := mongoStore.EnsureShareLinkIndex(context.Background()); err != nil {
+		t.Fatalf("EnsureShareLinkIndex: %v", err)
+	}
+	models := db.Collection("share_links").(*fakeMongoCollection).createIndexesModels
+	if len(models) != 1 || len(models[0]) != 1 || models[0][0].Options == nil || models[0][0].Options.Unique == nil || !*models[0][0].Options.Unique {
+		t.Fatalf("index models = %#v, want one unique index", models)
+	}
+	if keys, ok := models[0][0].Keys.(bson.D); !ok || len(keys) != 1 || keys[0].Key != "tokenHash" {
+		t.Fatalf("index keys = %#v, want tokenHash", models[0][0].Keys)
+	}
+
+	store := NewMemoryStore()
+	if _, err := store.CreateShareLink(context.Background(), ShareLink{TokenHash: "hash-1"}); err != nil {
+		t.Fatalf("CreateShareLink: %v", err)
+	}
+	if _, err := store.CreateShareLink(context.Background(), ShareLink{TokenHash: "hash-1"}); err == nil {
+		t.Fatal("expected a second link with the same token hash to be rejected")
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	errSignatureMissing  = errors.New("Draw a signature before submitting.")
+	errSignatureMultiple = errors.New("Submit exactly one signature.")
+	errSignatureNotImage = errors.New("Signature must be an image.")
+	errSignatureTooLarge = errors.New("Signature image is too large.")
+)
+
+// Signatures are checked against these bounds from the image header before
+// decoding, so a small compressed upload cannot claim a huge canvas and
+// allocate its full bitmap.
+const (
+	maxSignatureSide   = 4096
+	maxSignaturePixels = 4 << 20
+)
+
+// isSignatureSubstep reports whether sub captures a drawn signature: one
+// image data URL posted as the "signature" form field.
+func isSignatureSubstep(sub WorkflowSub) bool {
+	return normalizeInputTypeForCheck(sub.InputType) == "signature"
+}
+
+func signatureInputKey(sub WorkflowSub) string {
+	if key := strings.TrimSpace(sub.InputKey); key != "" {
+		return key
+	}
+	return "signature"
+}
+
+// signaturePNG decodes the posted data URL and returns it as PNG. The data
+// URL must declare an image type and the bytes must decode as PNG, JPEG or
+// GIF; anything else is rejected so only images end up as signatures.
+// Images beyond maxSignatureSide or maxSignaturePixels are rejected before
+// their pixels are decoded.
+func signaturePNG(raw string) ([]byte, error) {
+	dataURL, ok := decodeDataURL(raw)
+	if !ok {
+		return nil, errSignatureNotImage
+	}
+	if !strings.HasPrefix(strings.ToLower(dataURL.ContentType), "image/") {
+		return nil, errSignatureNotImage
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(dataURL.Data))
+	if err != nil {
+		return nil, errSignatureNotImage
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, errSignatureNotImage
+	}
+	if config.Width > maxSignatureSide || config.Height > maxSignatureSide || config.Width*config.Height > maxSignaturePixels {
+		return nil, errSignatureTooLarge
+	}
+	img, format, err := image.Decode(bytes.NewReader(dataURL.Data))
+	if err != nil {
+		return nil, errSignatureNotImage
+	}
+	if format == "png" {
+		return dataURL.Data, nil
+	}
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, img); err != nil {
+		return nil, err
+	}
+	return encoded.Bytes(), nil
+}
+
+// postedSignature returns the single non-empty "signature" form value.
+func postedSignature(r *http.Request) (string, error) {
+	if err := r.ParseForm(); err != nil {
+		return "", errInvalidForm
+	}
+	var values []string
+	for _, value := range r.PostForm["signature"] {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	switch len(values) {
+	case 0:
+		return "", errSignatureMissing
+	case 1:
+		return values[0], nil
+	default:
+		return "", errSignatureMultiple
+	}
+}
+
+// parseSignaturePayload stores the drawn signature as a PNG attachment and
+// returns the payload notarized for the substep: the attachment reference
+// under the substep's inputKey, in the same shape formata file fields use.
+func (s *Server) parseSignaturePayload(r *http.Request, processID primitive.ObjectID, substep WorkflowSub, now time.Time) (map[string]interface{}, error) {
+	raw, err := postedSignature(r)
+	if err != nil {
+		return nil, err
+	}
+	data, err := signaturePNG(raw)
+	if err != nil {
+		return nil, err
+	}
+	key := signatureInputKey(substep)
+	filename := formataAttachmentFilename(substep.SubstepID, []string{key}, "image/png")
+	if err := s.scanAttachment(r.Context(), filename, data); err != nil {
+		return nil, err
+	}
+	attachment, err := s.store.SaveAttachment(r.Context(), AttachmentUpload{
+		ProcessID:   processID,
+		SubstepID:   substep.SubstepID,
+		Filename:    filename,
+		ContentType: "image/png",
+		MaxBytes:    attachmentMaxBytes(),
+		UploadedAt:  now,
+	}, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	recordSavedAttachment(r.Context(), attachment.ID)
+	return map[string]interface{}{
+		key: map[string]interface{}{
+			"attachmentId": attachment.ID.Hex(),
+			"filename":     attachment.Filename,
+			"contentType":  attachment.ContentType,
+			"size":         attachment.SizeBytes,
+			"sha256":       attachment.SHA256,
+		},
+	}, nil
+}
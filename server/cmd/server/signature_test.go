@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func testSignatureImage(t *testing.T, encode func(*bytes.Buffer, image.Image) error) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	img.Set(1, 1, color.Black)
+	var buf bytes.Buffer
+	if err := encode(&buf, img); err != nil {
+		t.Fatalf("encode image: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func testSignatureDataURL(contentType string, data []byte) string {
+	return "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(data)
+}
+
+func TestNormalizeInputTypesSignature(t *testing.T) {
+	workflow := WorkflowDef{Steps: []WorkflowStep{{StepID: "1", Substep: []WorkflowSub{
+		{SubstepID: "1.1", InputType: " Signature "},
+	}}}}
+	if err := normalizeInputTypes(&workflow); err != nil {
+		t.Fatalf("normalizeInputTypes: %v", err)
+	}
+	if got := workflow.Steps[0].Substep[0].InputType; got != "signature" {
+		t.Fatalf("inputType = %q, want signature", got)
+	}
+	workflow.Steps[0].Substep[0].Schema = map[string]interface{}{"type": "object"}
+	if err := normalizeInputTypes(&workflow); err == nil || !strings.Contains(err.Error(), "schema is not allowed") {
+		t.Fatalf("expected schema rejection, got %v", err)
+	}
+}
+
+func TestSignaturePNGValidatesImages(t *testing.T) {
+	pngData := testSignatureImage(t, func(buf *bytes.Buffer, img image.Image) error { return png.Encode(buf, img) })
+	got, err := signaturePNG(testSignatureDataURL("image/png", pngData))
+	if err != nil || !bytes.Equal(got, pngData) {
+		t.Fatalf("png signature = %d bytes, %v", len(got), err)
+	}
+
+	jpegData := testSignatureImage(t, func(buf *bytes.Buffer, img image.Image) error { return jpeg.Encode(buf, img, nil) })
+	got, err = signaturePNG(testSignatureDataURL("image/jpeg", jpegData))
+	if err != nil {
+		t.Fatalf("jpeg signature: %v", err)
+	}
+	if _, format, err := image.Decode(bytes.NewReader(got)); err != nil || format != "png" {
+		t.Fatalf("expected jpeg converted to png, got %q %v", format, err)
+	}
+
+	for name, raw := range map[string]string{
+		"plain text":      "hello",
+		"non-image type":  testSignatureDataURL("text/plain", pngData),
+		"image type, bad": testSignatureDataURL("image/png", []byte("not an image")),
+	} {
+		if _, err := signaturePNG(raw); err != errSignatureNotImage {
+			t.Fatalf("%s: expected errSignatureNotImage, got %v", name, err)
+		}
+	}
+
+	for name, rect := range map[string]image.Rectangle{
+		"too tall":       image.Rect(0, 0, 1, maxSignatureSide+1),
+		"too many pixel": image.Rect(0, 0, maxSignatureSide, maxSignaturePixels/maxSignatureSide+1),
+	} {
+		var huge bytes.Buffer
+		if err := png.Encode(&huge, image.NewGray(rect)); err != nil {
+			t.Fatalf("encode %s: %v", name, err)
+		}
+		if _, err := signaturePNG(testSignatureDataURL("image/png", huge.Bytes())); err != errSignatureTooLarge {
+			t.Fatalf("%s: expected errSignatureTooLarge, got %v", name, err)
+		}
+	}
+}
+
+func TestHandleCompleteSubstepSignature(t *testing.T) {
+	store := NewMemoryStore()
+	server, processID, _ := newServerForCompleteTests(t, store, fakeAuthorizer{})
+	cfg := testFormataRuntimeConfig()
+	sub := &cfg.Workflow.Steps[0].Substep[0]
+	sub.InputType = "signature"
+	sub.InputKey = "signedOff"
+	sub.Schema = nil
+	server.configProvider = func() (RuntimeConfig, error) { return cfg, nil }
+	post := func(action string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/process/"+processID+"/substep/1.1/"+action, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		if action == "amend" {
+			server.handleAmendSubstep(rec, req, processID, "1.1")
+		} else {
+			server.handleCompleteSubstep(rec, req, processID, "1.1")
+		}
+		return rec
+	}
+	pngData := testSignatureImage(t, func(buf *bytes.Buffer, img image.Image) error { return png.Encode(buf, img) })
+	signature := testSignatureDataURL("image/png", pngData)
+
+	if rec := post("complete", url.Values{}); rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "Draw a signature") {
+		t.Fatalf("missing signature status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	if rec := post("complete", url.Values{"signature": {signature, signature}}); rec.Code != http.StatusBadRequest {
+		t.Fatalf("two signatures status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if rec := post("complete", url.Values{"signature": {testSignatureDataURL("text/plain", []byte("hi"))}}); rec.Code != http.StatusBadRequest {
+		t.Fatalf("non-image status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if rec := post("complete", url.Values{"signature": {signature}}); rec.Code != http.StatusOK {
+		t.Fatalf("complete status = %d body = %s", rec.Code, rec.Body.String())
+	}
+
+	id, _ := primitive.ObjectIDFromHex(processID)
+	process, _ := store.SnapshotProcess(id)
+	progress := normalizeProgressKeys(process.Progress)["1.1"]
+	ref, ok := progress.Data["signedOff"].(map[string]interface{})
+	if progress.State != "done" || progress.DoneBy == nil || !ok {
+		t.Fatalf("unexpected progress %#v", progress)
+	}
+	attachmentID, _ := primitive.ObjectIDFromHex(ref["attachmentId"].(string))
+	attachment, err := store.LoadAttachmentByID(context.Background(), attachmentID)
+	if err != nil {
+		t.Fatalf("LoadAttachmentByID: %v", err)
+	}
+	if attachment.ContentType != "image/png" || attachment.Filename != "1_1-signedOff.png" || attachment.SubstepID != "1.1" {
+		t.Fatalf("unexpected attachment %#v", attachment)
+	}
+
+	if rec := post("amend", url.Values{"reason": {"typo"}}); rec.Code != http.StatusConflict {
+		t.Fatalf("amend status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+}
+
+func TestSubstepBodyTemplateSignatureRendersPad(t *testing.T) {
+	tmpl := parseTestTemplates(t)
+	action := withSubstepBodyMode(SubstepBodyView{
+		WorkflowKey:   "workflow",
+		ProcessID:     "process-1",
+		SubstepID:     "1.1",
+		InputType:     "signature",
+		Status:        "available",
+		MatchingRoles: []SubstepRoleOption{{Slug: "dep1", Label: "Dep 1"}},
+	})
+	var out bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&out, "substep_body", action); err != nil {
+		t.Fatalf("render substep_body template: %v", err)
+	}
+	body := out.String()
+	for _, marker := range []string{`js-signature-form`, `js-signature-canvas`, `name="signature"`, `action="/my/streams/workflow/instance/process-1/substep/1.1/complete?substep=1.1"`} {
+		if !strings.Contains(body, marker) {
+			t.Fatalf("expected %q in body: %s", marker, body)
+		}
+	}
+	if strings.Contains(body, "js-formata-host") {
+		t.Fatalf("signature substep must not render a formata form: %s", body)
+	}
+
+	done := withSubstepBodyMode(SubstepBodyView{
+		WorkflowKey: "workflow",
+		ProcessID:   "process-1",
+		SubstepID:   "1.1",
+		InputType:   "signature",
+		Status:      "done",
+		DoneBy:      "signer@example.com",
+		DoneAt:      "3 Mar 2026, 10:00",
+		DoneAtISO:   "2026-03-03T10:00:00Z",
+	})
+	out.Reset()
+	if err := tmpl.ExecuteTemplate(&out, "substep_body", done); err != nil {
+		t.Fatalf("render done substep_body template: %v", err)
+	}
+	if body := out.String(); !strings.Contains(body, "Signed by signer@example.com") || !strings.Contains(body, "2026-03-03T10:00:00Z") {
+		t.Fatalf("expected signer and time in result: %s", body)
+	}
+}
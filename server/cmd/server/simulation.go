@@ -0,0 +1,351 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// simulationTTL bounds how long a workflow preview stays clickable after its
+// last action. maxSimulationsPerUser caps the previews one user keeps open
+// across workflows; starting another drops their least recently used one.
+const (
+	simulationTTL         = 30 * time.Minute
+	maxSimulationsPerUser = 5
+)
+
+// simulationStore keeps the ephemeral processes behind /my/streams/:key/preview
+// in memory. They share the ObjectID space of real processes so the regular
+// process templates and routes render them unchanged, but they never reach
+// the Store: completions only update the in-memory copy, and nothing is
+// notarized, turned into a DPP or broadcast over SSE. Each user has at most
+// one preview per workflow and maxSimulationsPerUser overall, and idle
+// sessions are dropped after simulationTTL.
+type simulationStore struct {
+	mu       sync.Mutex
+	sessions map[string]*simulationSession
+}
+
+type simulationSession struct {
+	process   Process
+	ownerID   string
+	expiresAt time.Time
+}
+
+// start registers process as ownerID's preview of its workflow, replacing
+// any earlier preview of the same workflow by that user.
+func (p *simulationStore) start(process Process, ownerID string, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.sessions == nil {
+		p.sessions = map[string]*simulationSession{}
+	}
+	var owned []string
+	for id, session := range p.sessions {
+		switch {
+		case now.After(session.expiresAt):
+			delete(p.sessions, id)
+		case session.ownerID != ownerID:
+		case session.process.WorkflowKey == process.WorkflowKey:
+			delete(p.sessions, id)
+		default:
+			owned = append(owned, id)
+		}
+	}
+	for len(owned) >= maxSimulationsPerUser {
+		oldest := 0
+		for i, id := range owned {
+			if p.sessions[id].expiresAt.Before(p.sessions[owned[oldest]].expiresAt) {
+				oldest = i
+			}
+		}
+		delete(p.sessions, owned[oldest])
+		owned = append(owned[:oldest], owned[oldest+1:]...)
+	}
+	p.sessions[process.ID.Hex()] = &simulationSession{process: process, ownerID: ownerID, expiresAt: now.Add(simulationTTL)}
+}
+
+// has reports whether processID names a live simulation, whoever owns it.
+func (p *simulationStore) has(processID string, now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	session, ok := p.sessions[processID]
+	return ok && !now.After(session.expiresAt)
+}
+
+// load returns a copy of the simulated process when ownerID started it.
+func (p *simulationStore) load(processID, ownerID string, now time.Time) (*Process, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	session, ok := p.sessions[processID]
+	if ok && now.After(session.expiresAt) {
+		delete(p.sessions, processID)
+		return nil, false
+	}
+	if !ok || session.ownerID != ownerID {
+		return nil, false
+	}
+	process := session.process
+	process.Progress = make(map[string]ProcessStep, len(session.process.Progress))
+	for key, step := range session.process.Progress {
+		process.Progress[key] = step
+	}
+	return &process, true
+}
+
+func (p *simulationStore) save(process *Process, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if session, ok := p.sessions[process.ID.Hex()]; ok {
+		session.process = *process
+		session.expiresAt = now.Add(simulationTTL)
+	}
+}
+
+// simulationActor is the previewing user holding every role of the workflow,
+// so authors can click through all substeps while sequence rules still apply.
+func (s *Server) simulationActor(user *AccountUser, workflowKey string, cfg RuntimeConfig) Actor {
+	actor := actorFromAccountUser(user, workflowKey)
+	actor.RoleSlugs = s.roles(cfg)
+	actor.Role = ""
+	if len(actor.RoleSlugs) > 0 {
+		actor.Role = actor.RoleSlugs[0]
+	}
+	return actor
+}
+
+// handleStartSimulation serves GET /my/streams/:key/preview: it creates an
+// in-memory process for the workflow and redirects to its page.
+func (s *Server) handleStartSimulation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, _, ok := s.requireAuthenticatedPage(w, r)
+	if !ok {
+		return
+	}
+	workflowKey, cfg, ok := s.selectedWorkflowOrRedirectHome(w, r)
+	if !ok {
+		return
+	}
+	now := s.nowUTC()
+	process := buildWorkflowPreviewProcess(cfg.Workflow, workflowKey)
+	process.ID = primitive.NewObjectID()
+	process.Name = "Preview"
+	process.CreatedAt = now
+	process.CreatedBy = accountActorID(user)
+	s.simulations.start(*process, accountActorID(user), now)
+	http.Redirect(w, r, streamInstancePath(workflowKey, process.ID.Hex()), http.StatusSeeOther)
+}
+
+// handleSimulationRoutes answers the process routes of a simulated process:
+// the page, its content partial and substep completion. Everything else a
+// real process offers (exports, amendments, sharing) is 404.
+func (s *Server) handleSimulationRoutes(w http.ResponseWriter, r *http.Request, processID string, parts []string) {
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		s.handleSimulationPage(w, r, processID, false)
+	case len(parts) == 2 && parts[1] == "content" && r.Method == http.MethodGet:
+		s.handleSimulationPage(w, r, processID, true)
+	case len(parts) == 4 && parts[1] == "substep" && parts[3] == "complete" && r.Method == http.MethodPost:
+		s.handleSimulationComplete(w, r, processID, parts[2])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) handleSimulationPage(w http.ResponseWriter, r *http.Request, processID string, partial bool) {
+	user, _, ok := s.requireAuthenticatedPage(w, r)
+	if !ok {
+		return
+	}
+	workflowKey, cfg, ok := s.selectedWorkflowOrRedirectHome(w, r)
+	if !ok {
+		return
+	}
+	process, ok := s.simulations.load(processID, accountActorID(user), s.nowUTC())
+	if !ok || process.WorkflowKey != workflowKey {
+		http.Error(w, "process not found", http.StatusNotFound)
+		return
+	}
+	s.renderSimulation(w, r, user, cfg, workflowKey, process, partial, "")
+}
+
+// handleSimulationComplete marks a simulated substep done with the submitted
+// payload after the same sequence and role checks as a real completion.
+func (s *Server) handleSimulationComplete(w http.ResponseWriter, r *http.Request, processID, substepID string) {
+	user, _, ok := s.requireAuthenticatedPost(w, r)
+	if !ok {
+		return
+	}
+	workflowKey, cfg, ok := s.selectedWorkflowOrRedirectHome(w, r)
+	if !ok {
+		return
+	}
+	now := s.nowUTC()
+	process, ok := s.simulations.load(processID, accountActorID(user), now)
+	if !ok || process.WorkflowKey != workflowKey {
+		http.Error(w, "process not found", http.StatusNotFound)
+		return
+	}
+	partial := isHTMXRequest(r)
+	fail := func(status int, message string) {
+		w.WriteHeader(status)
+		s.renderSimulation(w, r, user, cfg, workflowKey, process, partial, message)
+	}
+	substep, _, err := findSubstep(cfg.Workflow, substepID)
+	if err != nil {
+		fail(http.StatusNotFound, "Substep not found.")
+		return
+	}
+	if !processAcceptsCompletions(process) {
+		fail(http.StatusConflict, "Stream is already ended.")
+		return
+	}
+	if progress, ok := process.Progress[substepID]; ok && progress.State == "done" {
+		fail(http.StatusConflict, "Substep is already completed.")
+		return
+	}
+	if !isSequenceOK(cfg.Workflow, process, substepID) {
+		fail(http.StatusConflict, "Step is locked: complete previous steps first.")
+		return
+	}
+	actor := s.simulationActor(user, workflowKey, cfg)
+	r.Body = http.MaxBytesReader(w, r.Body, completionFormMaxBytes())
+	if err := r.ParseForm(); err != nil {
+		fail(http.StatusBadRequest, "Invalid form.")
+		return
+	}
+	activeRole := strings.TrimSpace(r.FormValue("activeRole"))
+	allowedRoles := substepRoles(substep)
+	if activeRole == "" && len(allowedRoles) > 0 {
+		activeRole = allowedRoles[0]
+	}
+	if !containsRole(allowedRoles, activeRole) {
+		fail(http.StatusForbidden, "Not authorized for this action.")
+		return
+	}
+	actor.Role = activeRole
+
+	var payload map[string]interface{}
+	switch {
+	case isAcknowledgeSubstep(substep):
+		payload = acknowledgePayload(substep)
+	case isSignatureSubstep(substep):
+		payload, err = simulatedSignaturePayload(r, substep)
+		if err != nil {
+			fail(http.StatusBadRequest, err.Error())
+			return
+		}
+	case isMultiselectSubstep(substep):
+		payload, err = parseMultiselectPayload(r, substep)
+		if err != nil {
+			fail(http.StatusBadRequest, err.Error())
+			return
+		}
+	case isURLSubstep(substep):
+		payload, err = parseURLPayload(r, substep)
+		if err != nil {
+			fail(http.StatusBadRequest, err.Error())
+			return
+		}
+	default:
+		payload, err = parseFormataScalarPayload(r, substep)
+		if err != nil {
+			fail(http.StatusBadRequest, "Invalid form.")
+			return
+		}
+	}
+	description := substep.InputKey
+	process.Progress[substepID] = ProcessStep{
+		State:       "done",
+		Description: &description,
+		DoneAt:      &now,
+		DoneBy:      &actor,
+		Data:        payload,
+	}
+	if isProcessComplete(cfg.Workflow, process) {
+		process.Status = processStatusDone
+	}
+	s.simulations.save(process, now)
+	s.renderSimulation(w, cloneRequestWithSelectedSubstep(r, ""), user, cfg, workflowKey, process, partial, "")
+}
+
+// renderSimulation renders a simulated process with the regular process
+// templates, minus everything that would act on stored data.
+func (s *Server) renderSimulation(w http.ResponseWriter, r *http.Request, user *AccountUser, cfg RuntimeConfig, workflowKey string, process *Process, partial bool, message string) {
+	view := s.buildProcessPageView(
+		r.Context(),
+		s.pageBaseForUser(user, "process_body", workflowKey, cfg.Workflow.Name),
+		cfg,
+		workflowKey,
+		process,
+		s.simulationActor(user, workflowKey, cfg),
+		strings.TrimSpace(r.URL.Query().Get("substep")),
+		message,
+		false,
+	)
+	view.Detail = simulationStreamInstanceDetail(view.Detail)
+	view.DPPURL, view.DPPGS1 = "", ""
+	view.Attachments = nil
+	view = view.withAttachmentTotals()
+	view.Simulation = true
+	view.MetadataURL = ""
+	name := "process.html"
+	if partial {
+		name = "process_content.html"
+	}
+	if err := s.tmpl.ExecuteTemplate(w, name, view); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// simulationStreamInstanceDetail drops the actions a simulation cannot
+// perform: ending the stream, local form adaptation and rework.
+func simulationStreamInstanceDetail(view StreamInstanceDetailView) StreamInstanceDetailView {
+	view.CanTerminate = false
+	view.TerminateAction = ""
+	view.TerminateSubstep = ""
+	view.TerminateRoles = nil
+	view.DPPURL, view.DPPGS1 = "", ""
+	view.Attachments = nil
+	strip := func(body *SubstepBodyView) *SubstepBodyView {
+		if body == nil {
+			return nil
+		}
+		copied := *body
+		copied.CanAdaptForm = false
+		copied.AdaptURL = ""
+		copied.RejectURL = ""
+		copied.RejectRoles = nil
+		return &copied
+	}
+	view.SelectedBody = strip(view.SelectedBody)
+	for stepIndex := range view.Timeline {
+		for substepIndex := range view.Timeline[stepIndex].Substeps {
+			substep := &view.Timeline[stepIndex].Substeps[substepIndex]
+			substep.Body = strip(substep.Body)
+		}
+	}
+	return view
+}
+
+// simulatedSignaturePayload validates a drawn signature like a real
+// completion but keeps only its size, since simulations store no files.
+func simulatedSignaturePayload(r *http.Request, substep WorkflowSub) (map[string]interface{}, error) {
+	raw, err := postedSignature(r)
+	if err != nil {
+		return nil, err
+	}
+	data, err := signaturePNG(raw)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		signatureInputKey(substep): map[string]interface{}{"contentType": "image/png", "size": len(data)},
+	}, nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestWorkflowSimulationNeverTouchesStore(t *testing.T) {
+	now := time.Date(2026, 2, 4, 11, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	server := &Server{
+		store:      store,
+		authorizer: fakeAuthorizer{},
+		tmpl:       parseTestTemplates(t),
+		sse:        newSSEHub(),
+		now:        func() time.Time { return now },
+		configProvider: func() (RuntimeConfig, error) {
+			return testRuntimeConfig(), nil
+		},
+	}
+	scoped := func(req *http.Request) *http.Request {
+		return req.WithContext(context.WithValue(req.Context(), workflowContextKey{}, workflowContextValue{
+			Key: "workflow",
+			Cfg: testRuntimeConfig(),
+		}))
+	}
+
+	rec := httptest.NewRecorder()
+	server.handleStartSimulation(rec, scoped(httptest.NewRequest(http.MethodGet, "/preview", nil)))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("start status = %d, want %d", rec.Code, http.StatusSeeOther)
+	}
+	location := rec.Header().Get("Location")
+	prefix := streamInstancePath("workflow", "")
+	if !strings.HasPrefix(location, prefix) {
+		t.Fatalf("location = %q, want prefix %q", location, prefix)
+	}
+	processID := strings.TrimPrefix(location, prefix)
+
+	rec = httptest.NewRecorder()
+	server.handleProcessRoutes(rec, scoped(httptest.NewRequest(http.MethodGet, "/instance/"+processID, nil)))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Preview mode.") {
+		t.Fatalf("page status = %d, body missing preview notice", rec.Code)
+	}
+
+	complete := func(substepID string) *httptest.ResponseRecorder {
+		form := url.Values{"value": {`{"note":"ok"}`}}
+		req := httptest.NewRequest(http.MethodPost, "/instance/"+processID+"/substep/"+substepID+"/complete", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("HX-Request", "true")
+		rec := httptest.NewRecorder()
+		server.handleProcessRoutes(rec, scoped(req))
+		return rec
+	}
+	if rec := complete("2.1"); rec.Code != http.StatusConflict {
+		t.Fatalf("locked substep status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	if rec := complete("1.1"); rec.Code != http.StatusOK {
+		t.Fatalf("complete status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if rec := complete("1.1"); rec.Code != http.StatusConflict {
+		t.Fatalf("repeat completion status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	session := server.simulations.sessions[processID]
+	process, ok := server.simulations.load(processID, session.ownerID, now)
+	if !ok {
+		t.Fatal("expected simulated process to be loadable by its owner")
+	}
+	if _, ok := server.simulations.load(processID, "appwrite:someone-else", now); ok {
+		t.Fatal("expected simulation to be private to its owner")
+	}
+	if step := process.Progress["1.1"]; step.State != "done" || step.Data["note"] != "ok" || step.DoneBy == nil || step.DoneBy.Role != "dep1" {
+		t.Fatalf("unexpected simulated progress %#v", step)
+	}
+	if len(store.Notarizations()) != 0 || len(store.processes) != 0 {
+		t.Fatalf("expected no store writes, got %d notarizations and %d processes", len(store.Notarizations()), len(store.processes))
+	}
+
+	rec = httptest.NewRecorder()
+	server.handleProcessRoutes(rec, scoped(httptest.NewRequest(http.MethodGet, "/instance/"+processID+"/notarized.json", nil)))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("export status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if _, ok := server.simulations.load(processID, session.ownerID, now.Add(simulationTTL+time.Minute)); ok {
+		t.Fatal("expected simulation to expire")
+	}
+}
+
+func TestSimulationStoreBoundsSessionsPerUser(t *testing.T) {
+	now := time.Date(2026, 2, 4, 11, 0, 0, 0, time.UTC)
+	store := &simulationStore{}
+	start := func(owner, workflowKey string, at time.Time) string {
+		process := Process{ID: primitive.NewObjectID(), WorkflowKey: workflowKey}
+		store.start(process, owner, at)
+		return process.ID.Hex()
+	}
+
+	first := start("appwrite:u1", "alpha", now)
+	replaced := start("appwrite:u1", "alpha", now.Add(time.Minute))
+	if store.has(first, now.Add(time.Minute)) || !store.has(replaced, now.Add(time.Minute)) {
+		t.Fatal("expected a new preview of the same workflow to replace the old one")
+	}
+	other := start("appwrite:u2", "alpha", now.Add(time.Minute))
+
+	for i := 0; i < maxSimulationsPerUser; i++ {
+		start("appwrite:u1", fmt.Sprintf("workflow-%d", i), now.Add(time.Duration(2+i)*time.Minute))
+	}
+	owned := 0
+	for _, session := range store.sessions {
+		if session.ownerID == "appwrite:u1" {
+			owned++
+		}
+	}
+	if owned != maxSimulationsPerUser || store.has(replaced, now.Add(10*time.Minute)) {
+		t.Fatalf("owned = %d, least recently used kept = %t", owned, store.has(replaced, now.Add(10*time.Minute)))
+	}
+	if !store.has(other, now.Add(10*time.Minute)) {
+		t.Fatal("expected another user's preview to be unaffected by the cap")
+	}
+
+	if _, ok := store.load(other, "appwrite:u2", now.Add(time.Minute+simulationTTL+time.Second)); ok {
+		t.Fatal("expected an idle preview to expire")
+	}
+	if _, ok := store.sessions[other]; ok {
+		t.Fatal("expected the expired preview to be dropped")
+	}
+}
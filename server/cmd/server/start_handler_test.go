@@ -1,10 +1,12 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -57,3 +59,154 @@ func TestHandleStartProcessInsertErrorReturns500(t *testing.T) {
 		t.Fatalf("expected status %d, got %d", http.StatusInternalServerError, rr.Code)
 	}
 }
+
+func TestHandleStartProcessRateLimit(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Date(2026, 2, 2, 13, 0, 0, 0, time.UTC)
+	server := &Server{
+		store:         store,
+		sse:           newSSEHub(),
+		now:           func() time.Time { return now },
+		workflowDefID: primitive.NewObjectID(),
+		startLimiter:  newRateLimiter(2, time.Hour),
+		configProvider: func() (RuntimeConfig, error) {
+			return testRuntimeConfig(), nil
+		},
+	}
+	start := func() *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		server.handleStartProcess(rr, httptest.NewRequest(http.MethodPost, "/process/start", nil))
+		return rr
+	}
+
+	store.InsertProcessErr = errors.New("insert failed")
+	if rr := start(); rr.Code != http.StatusInternalServerError {
+		t.Fatalf("failed start: status = %d, want %d", rr.Code, http.StatusInternalServerError)
+	}
+	store.InsertProcessErr = nil
+
+	for i := 0; i < 2; i++ {
+		if rr := start(); rr.Code != http.StatusSeeOther {
+			t.Fatalf("start %d: status = %d, want %d", i, rr.Code, http.StatusSeeOther)
+		}
+	}
+	now = now.Add(20 * time.Minute)
+	rr := start()
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", rr.Code, http.StatusTooManyRequests)
+	}
+	if got := rr.Header().Get("Retry-After"); got != "2400" {
+		t.Fatalf("Retry-After = %q, want 2400", got)
+	}
+	if processes, _ := store.ListRecentProcessesByWorkflow(t.Context(), "workflow", 0); len(processes) != 2 {
+		t.Fatalf("expected rejected start not to insert, got %d processes", len(processes))
+	}
+
+	now = now.Add(40 * time.Minute)
+	if rr := start(); rr.Code != http.StatusSeeOther {
+		t.Fatalf("after window: status = %d, want %d", rr.Code, http.StatusSeeOther)
+	}
+}
+
+func TestRateLimiterKeysAndDisabled(t *testing.T) {
+	now := time.Date(2026, 2, 2, 13, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter(1, time.Minute)
+	if ok, _ := limiter.Check("a|workflow", now); !ok {
+		t.Fatal("expected first attempt to pass")
+	}
+	if ok, _ := limiter.Check("a|workflow", now); !ok {
+		t.Fatal("expected an unrecorded check not to count")
+	}
+	limiter.Record("a|workflow", now)
+	if ok, _ := limiter.Check("a|other", now); !ok {
+		t.Fatal("expected separate workflow key to pass")
+	}
+	if ok, wait := limiter.Check("a|workflow", now.Add(15*time.Second)); ok || wait != 45*time.Second {
+		t.Fatalf("Check = %v, %s; want false, 45s", ok, wait)
+	}
+	disabled := newRateLimiter(0, time.Hour)
+	if disabled != nil {
+		t.Fatal("expected zero limit to disable limiter")
+	}
+	disabled.Record("a", now)
+	if ok, _ := disabled.Check("a", now); !ok {
+		t.Fatal("expected nil limiter to allow")
+	}
+}
+
+func TestHandleStartProcessStartRoles(t *testing.T) {
+	now := time.Date(2026, 2, 2, 13, 0, 0, 0, time.UTC)
+	cfg := testRuntimeConfig()
+	cfg.Workflow.StartRoles = []string{"dep1"}
+	store := NewMemoryStore()
+	server := &Server{
+		store:         store,
+		sse:           newSSEHub(),
+		now:           func() time.Time { return now },
+		workflowDefID: primitive.NewObjectID(),
+		enforceAuth:   true,
+		identity: testIdentityForSessions(now, map[string]AccountUser{
+			"session-dep1": {IdentityUserID: "u-1", OrgSlug: "acme", RoleSlugs: []string{"dep1"}},
+			"session-dep2": {IdentityUserID: "u-2", OrgSlug: "acme", RoleSlugs: []string{"dep2"}},
+		}),
+	}
+	start := func(session string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/instance/start", nil)
+		req.AddCookie(&http.Cookie{Name: "attesta_session", Value: session})
+		req = req.WithContext(context.WithValue(req.Context(), workflowContextKey{}, workflowContextValue{Key: "workflow", Cfg: cfg}))
+		rr := httptest.NewRecorder()
+		server.handleStartProcess(rr, req)
+		return rr
+	}
+
+	if rr := start("session-dep2"); rr.Code != http.StatusForbidden {
+		t.Fatalf("dep2 status = %d, want %d", rr.Code, http.StatusForbidden)
+	}
+	if processes, _ := store.ListRecentProcessesByWorkflow(t.Context(), "workflow", 0); len(processes) != 0 {
+		t.Fatalf("expected forbidden start not to insert, got %d processes", len(processes))
+	}
+	if rr := start("session-dep1"); rr.Code != http.StatusSeeOther {
+		t.Fatalf("dep1 status = %d, want %d", rr.Code, http.StatusSeeOther)
+	}
+	processes, _ := store.ListRecentProcessesByWorkflow(t.Context(), "workflow", 0)
+	if len(processes) != 1 || processes[0].CreatedBy != "appwrite:u-1" {
+		t.Fatalf("expected process created by appwrite:u-1, got %#v", processes)
+	}
+
+	if !server.canStartWorkflow(WorkflowDef{}, &AccountUser{}) {
+		t.Fatal("expected empty startRoles to allow any user")
+	}
+	if !server.canStartWorkflow(cfg.Workflow, &AccountUser{IsPlatformAdmin: true}) {
+		t.Fatal("expected platform admin to be allowed")
+	}
+	if !(&Server{}).canStartWorkflow(cfg.Workflow, &AccountUser{}) {
+		t.Fatal("expected unenforced auth to allow start")
+	}
+}
+
+func TestHandleStartProcessRejectsDisabledWorkflow(t *testing.T) {
+	now := time.Date(2026, 2, 2, 13, 0, 0, 0, time.UTC)
+	cfg := testRuntimeConfig()
+	enabled := false
+	cfg.Workflow.Enabled = &enabled
+	store := NewMemoryStore()
+	server := &Server{
+		store:         store,
+		sse:           newSSEHub(),
+		now:           func() time.Time { return now },
+		workflowDefID: primitive.NewObjectID(),
+	}
+	req := httptest.NewRequest(http.MethodPost, "/instance/start", nil)
+	req = req.WithContext(context.WithValue(req.Context(), workflowContextKey{}, workflowContextValue{Key: "workflow", Cfg: cfg}))
+	rr := httptest.NewRecorder()
+	server.handleStartProcess(rr, req)
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d", rr.Code, http.StatusForbidden)
+	}
+	if processes, _ := store.ListRecentProcessesByWorkflow(t.Context(), "workflow", 0); len(processes) != 0 {
+		t.Fatalf("expected disabled workflow not to insert, got %d processes", len(processes))
+	}
+	if server.canStartWorkflow(cfg.Workflow, &AccountUser{IsPlatformAdmin: true}) {
+		t.Fatal("expected disabled workflow to block platform admins too")
+	}
+}
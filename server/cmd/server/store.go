@@ -6,7 +6,9 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"io"
+	"log"
 	"mime"
 	"path/filepath"
 	"regexp"
@@ -28,17 +30,43 @@ type Store interface {
 	LoadLatestProcessByWorkflow(ctx context.Context, workflowKey string) (*Process, error)
 	LoadProcessByDigitalLink(ctx context.Context, gtin, lot, serial string) (*Process, error)
 	ListRecentProcessesByWorkflow(ctx context.Context, workflowKey string, limit int64) ([]Process, error)
+	ListProcessesByParticipant(ctx context.Context, workflowKey, participantID string) ([]Process, error)
+	ListProcessesByMetadata(ctx context.Context, workflowKey, key, value string) ([]Process, error)
+	BackfillProcessParticipants(ctx context.Context) error
+	BackfillProcessSearchText(ctx context.Context) error
+	EnsureProcessCodeIndex(ctx context.Context) error
+	EnsureProcessEventsIndex(ctx context.Context) error
+	EnsureShareLinkIndex(ctx context.Context) error
+	EnsureSubstepDraftIndex(ctx context.Context) error
+	SearchProcesses(ctx context.Context, workflowKey, query string, limit int64) ([]Process, error)
 	HasProcessesByWorkflow(ctx context.Context, workflowKey string) (bool, error)
 	UpdateProcessProgress(ctx context.Context, id primitive.ObjectID, workflowKey, substepID string, progress ProcessStep) error
+	AppendProcessAmendment(ctx context.Context, id primitive.ObjectID, workflowKey, substepID string, amendment ProcessAmendment) error
 	UpdateProcessStatus(ctx context.Context, id primitive.ObjectID, workflowKey, status string) error
 	UpdateProcessTermination(ctx context.Context, id primitive.ObjectID, workflowKey string, termination ProcessTermination) error
 	UpdateProcessDPP(ctx context.Context, id primitive.ObjectID, workflowKey string, dpp ProcessDPP) error
+	UpdateProcessWorkflowKey(ctx context.Context, id primitive.ObjectID, workflowKey, code string) error
+	UpdateProcessMetadata(ctx context.Context, id primitive.ObjectID, set map[string]string, unset []string) error
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	MarkProcessCompletionNotified(ctx context.Context, id primitive.ObjectID, at time.Time) (bool, error)
+	ReleaseProcessCompletionNotified(ctx context.Context, id primitive.ObjectID, at time.Time) error
+	NextProcessSequence(ctx context.Context, workflowKey string) (int64, error)
+	ListProcessSequences(ctx context.Context) (map[string]int64, error)
+	SetProcessSequence(ctx context.Context, workflowKey string, value int64) error
+	ListUserRolesVersions(ctx context.Context) (map[string]int64, error)
+	ClaimUserRolesVersion(ctx context.Context, userID string, expected int64) error
+	LoadProcessByCode(ctx context.Context, workflowKey, code string) (*Process, error)
+	RejectProcessSubstep(ctx context.Context, id primitive.ObjectID, workflowKey string, rejection ProcessRejection) error
 	GetSubstepOverride(ctx context.Context, processID primitive.ObjectID, substepID string) (*SubstepOverride, error)
 	SaveSubstepOverride(ctx context.Context, processID primitive.ObjectID, workflowKey, substepID string, override SubstepOverride) error
 	InsertNotarization(ctx context.Context, notarization Notarization) error
+	GetNotarizationBySubstep(ctx context.Context, processID primitive.ObjectID, substepID string) (*Notarization, error)
+	ListNotarizations(ctx context.Context, processID primitive.ObjectID) ([]Notarization, error)
 	SaveAttachment(ctx context.Context, upload AttachmentUpload, content io.Reader) (Attachment, error)
 	LoadAttachmentByID(ctx context.Context, id primitive.ObjectID) (*Attachment, error)
+	LoadAttachmentBySHA256(ctx context.Context, processID primitive.ObjectID, sha256 string) (*Attachment, error)
 	OpenAttachmentDownload(ctx context.Context, id primitive.ObjectID) (io.ReadCloser, error)
+	DeleteAttachment(ctx context.Context, id primitive.ObjectID) error
 	SaveFormataBuilderStream(ctx context.Context, stream FormataBuilderStream) (FormataBuilderStream, error)
 	UpdateFormataBuilderStream(ctx context.Context, stream FormataBuilderStream) (FormataBuilderStream, error)
 	LoadFormataBuilderStream(ctx context.Context) (*FormataBuilderStream, error)
@@ -46,14 +74,53 @@ type Store interface {
 	ListFormataBuilderStreams(ctx context.Context) ([]FormataBuilderStream, error)
 	DeleteFormataBuilderStream(ctx context.Context, id primitive.ObjectID) error
 	DeleteWorkflowData(ctx context.Context, workflowKey string) error
+	DeleteProcessAttachments(ctx context.Context, processID primitive.ObjectID) error
+	PurgeTerminatedProcesses(ctx context.Context, workflowKey string, endedBefore time.Time, hardDelete bool, now time.Time) (int, error)
+	AcquireLock(ctx context.Context, name, owner string, now time.Time, ttl time.Duration) (bool, error)
+	LoadReadOnly(ctx context.Context) (bool, error)
+	SetReadOnly(ctx context.Context, enabled bool) error
+	CreateShareLink(ctx context.Context, link ShareLink) (ShareLink, error)
+	LoadShareLinkByTokenHash(ctx context.Context, tokenHash string) (*ShareLink, error)
+	AppendProcessEvent(ctx context.Context, event ProcessEvent) error
+	ListProcessEvents(ctx context.Context, processID primitive.ObjectID) ([]ProcessEvent, error)
+	SaveSubstepDraft(ctx context.Context, draft SubstepDraft) error
+	LoadSubstepDraft(ctx context.Context, processID primitive.ObjectID, substepID, userID string) (*SubstepDraft, error)
+	DeleteSubstepDrafts(ctx context.Context, processID primitive.ObjectID, substepID string) error
+}
+
+// SubstepDraft is one user's unfinished formata answer for a substep. Drafts
+// live outside the process document: they are never notarized, never count
+// towards availability and are dropped once the substep is completed.
+type SubstepDraft struct {
+	ID        primitive.ObjectID     `bson:"_id,omitempty"`
+	ProcessID primitive.ObjectID     `bson:"processId"`
+	SubstepID string                 `bson:"substepId"`
+	UserID    string                 `bson:"userId"`
+	Data      map[string]interface{} `bson:"data"`
+	UpdatedAt time.Time              `bson:"updatedAt"`
+}
+
+// ShareLink grants anonymous read-only access to a single process. Only the
+// hash of the token is stored.
+type ShareLink struct {
+	ID               primitive.ObjectID `bson:"_id,omitempty"`
+	TokenHash        string             `bson:"tokenHash"`
+	ProcessID        primitive.ObjectID `bson:"processId"`
+	WorkflowKey      string             `bson:"workflowKey"`
+	IncludeNotarized bool               `bson:"includeNotarized"`
+	CreatedBy        string             `bson:"createdBy,omitempty"`
+	CreatedAt        time.Time          `bson:"createdAt"`
+	ExpiresAt        time.Time          `bson:"expiresAt"`
 }
 
 type Organization struct {
-	ID               primitive.ObjectID `bson:"_id,omitempty"`
-	Slug             string             `bson:"slug"`
-	Name             string             `bson:"name"`
-	LogoAttachmentID string             `bson:"logoAttachmentId,omitempty"`
-	CreatedAt        time.Time          `bson:"createdAt"`
+	ID                  primitive.ObjectID `bson:"_id,omitempty"`
+	Slug                string             `bson:"slug"`
+	Name                string             `bson:"name"`
+	LogoAttachmentID    string             `bson:"logoAttachmentId,omitempty"`
+	AllowedEmailDomains []string           `bson:"allowedEmailDomains,omitempty"`
+	PublicBranding      bool               `bson:"publicBranding,omitempty"`
+	CreatedAt           time.Time          `bson:"createdAt"`
 }
 
 type Role struct {
@@ -95,6 +162,14 @@ const (
 type MongoStore struct {
 	db     *mongo.Database
 	dbPort mongoDatabasePort
+
+	// txnSupported caches whether the deployment runs transactions; see
+	// WithTransaction.
+	txnMu        sync.Mutex
+	txnSupported *bool
+
+	// retry applies to the idempotent operations wrapped in withRetry.
+	retry MongoRetryConfig
 }
 
 type mongoDatabasePort interface {
@@ -122,6 +197,7 @@ type mongoSingleResultPort interface {
 type mongoCursorPort interface {
 	Next(ctx context.Context) bool
 	Decode(val interface{}) error
+	Err() error
 	Close(ctx context.Context) error
 }
 
@@ -232,6 +308,10 @@ func (c mongoDriverCursor) Decode(val interface{}) error {
 	return c.cursor.Decode(val)
 }
 
+func (c mongoDriverCursor) Err() error {
+	return c.cursor.Err()
+}
+
 func (c mongoDriverCursor) Close(ctx context.Context) error {
 	return c.cursor.Close(ctx)
 }
@@ -272,6 +352,62 @@ func (s *MongoStore) database() mongoDatabasePort {
 
 var ErrAttachmentTooLarge = errors.New("attachment too large")
 
+// ErrSequenceBelowCurrent rejects moving a process counter backwards.
+var ErrSequenceBelowCurrent = errors.New("sequence below current value")
+
+// ErrRolesVersionConflict rejects a role update whose rolesVersion was
+// already claimed by another update.
+var ErrRolesVersionConflict = errors.New("roles version conflict")
+
+// ErrTransactionRolledBack marks a WithTransaction error after which none of
+// the writes made by fn were kept.
+var ErrTransactionRolledBack = errors.New("store: transaction rolled back")
+
+// WithTransaction runs fn in a Mongo transaction; the stores' methods join it
+// through the session carried by ctx. A standalone mongod has no
+// transactions, so there (and on stores built on test ports) fn runs
+// directly and its writes land one by one.
+func (s *MongoStore) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.db == nil || !s.supportsTransactions(ctx) {
+		return fn(ctx)
+	}
+	session, err := s.db.Client().StartSession()
+	if err != nil {
+		return fn(ctx)
+	}
+	defer session.EndSession(ctx)
+	if _, err := session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	}); err != nil {
+		return fmt.Errorf("%w: %w", ErrTransactionRolledBack, err)
+	}
+	return nil
+}
+
+// supportsTransactions asks the deployment once whether it is a replica set
+// member or a mongos. A failed probe is not cached.
+func (s *MongoStore) supportsTransactions(ctx context.Context) bool {
+	s.txnMu.Lock()
+	defer s.txnMu.Unlock()
+	if s.txnSupported != nil {
+		return *s.txnSupported
+	}
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := s.db.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		log.Printf("failed to probe mongo transaction support: %v", err)
+		return false
+	}
+	supported := hello.SetName != "" || hello.Msg == "isdbgrid"
+	if !supported {
+		log.Printf("mongo deployment is standalone: multi-write operations run without transactions")
+	}
+	s.txnSupported = &supported
+	return supported
+}
+
 type Attachment struct {
 	ID          primitive.ObjectID
 	ProcessID   primitive.ObjectID
@@ -306,12 +442,137 @@ func (s *MongoStore) InsertProcess(ctx context.Context, process Process) (primit
 
 func (s *MongoStore) LoadProcessByID(ctx context.Context, id primitive.ObjectID) (*Process, error) {
 	var process Process
-	if err := s.database().Collection("processes").FindOne(ctx, bson.M{"_id": id}).Decode(&process); err != nil {
+	err := s.withRetry(ctx, func() error {
+		process = Process{}
+		return s.database().Collection("processes").FindOne(ctx, bson.M{"_id": id}).Decode(&process)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &process, nil
+}
+
+// LoadProcessByCode finds the process of workflowKey with the given human
+// code; codes are only unique within a workflow.
+func (s *MongoStore) LoadProcessByCode(ctx context.Context, workflowKey, code string) (*Process, error) {
+	var process Process
+	if err := s.database().Collection("processes").FindOne(ctx, bson.M{"workflowKey": workflowKey, "code": code}).Decode(&process); err != nil {
 		return nil, err
 	}
 	return &process, nil
 }
 
+// NextProcessSequence atomically increments and returns the process counter
+// of workflowKey, starting at 1.
+func (s *MongoStore) NextProcessSequence(ctx context.Context, workflowKey string) (int64, error) {
+	var counter struct {
+		Seq int64 `bson:"seq"`
+	}
+	err := s.database().Collection("counters").FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": "process:" + workflowKey},
+		bson.M{"$inc": bson.M{"seq": int64(1)}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&counter)
+	if err != nil {
+		return 0, err
+	}
+	return counter.Seq, nil
+}
+
+// ListProcessSequences returns the current process counter of every
+// workflow that has one, keyed by workflow key.
+func (s *MongoStore) ListProcessSequences(ctx context.Context) (map[string]int64, error) {
+	cursor, err := s.database().Collection("counters").Find(ctx, bson.M{"_id": bson.M{"$regex": "^process:"}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	sequences := map[string]int64{}
+	for cursor.Next(ctx) {
+		var counter struct {
+			ID  string `bson:"_id"`
+			Seq int64  `bson:"seq"`
+		}
+		if err := cursor.Decode(&counter); err != nil {
+			return nil, err
+		}
+		sequences[strings.TrimPrefix(counter.ID, "process:")] = counter.Seq
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	return sequences, nil
+}
+
+// ListUserRolesVersions returns the role update counter of every user that
+// has one, keyed by user ID. Users without one are at version 0.
+func (s *MongoStore) ListUserRolesVersions(ctx context.Context) (map[string]int64, error) {
+	cursor, err := s.database().Collection("counters").Find(ctx, bson.M{"_id": bson.M{"$regex": "^roles:"}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	versions := map[string]int64{}
+	for cursor.Next(ctx) {
+		var counter struct {
+			ID  string `bson:"_id"`
+			Seq int64  `bson:"seq"`
+		}
+		if err := cursor.Decode(&counter); err != nil {
+			return nil, err
+		}
+		versions[strings.TrimPrefix(counter.ID, "roles:")] = counter.Seq
+	}
+	return versions, nil
+}
+
+// ClaimUserRolesVersion moves the role update counter of userID from expected
+// to expected+1 in one write. When another update got there first the filter
+// no longer matches, the upsert collides with the existing counter and
+// ErrRolesVersionConflict is returned.
+func (s *MongoStore) ClaimUserRolesVersion(ctx context.Context, userID string, expected int64) error {
+	_, err := s.database().Collection("counters").UpdateOne(
+		ctx,
+		bson.M{"_id": "roles:" + userID, "seq": expected},
+		bson.M{"$inc": bson.M{"seq": int64(1)}},
+		options.Update().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrRolesVersionConflict
+	}
+	return err
+}
+
+// SetProcessSequence moves the process counter of workflowKey to value. It
+// returns ErrSequenceBelowCurrent instead of lowering the counter, which
+// would hand out codes that are already taken. The write is a single $max
+// upsert, so it cannot race NextProcessSequence into a duplicate counter and
+// never lowers a counter that moved on meanwhile.
+func (s *MongoStore) SetProcessSequence(ctx context.Context, workflowKey string, value int64) error {
+	var previous struct {
+		Seq int64 `bson:"seq"`
+	}
+	err := s.database().Collection("counters").FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": "process:" + workflowKey},
+		bson.M{"$max": bson.M{"seq": value}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before),
+	).Decode(&previous)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if previous.Seq > value {
+		return ErrSequenceBelowCurrent
+	}
+	return nil
+}
+
 func (s *MongoStore) LoadLatestProcessByWorkflow(ctx context.Context, workflowKey string) (*Process, error) {
 	filter := bson.M{"workflowKey": workflowKey}
 	if workflowKey == "workflow" {
@@ -319,7 +580,7 @@ func (s *MongoStore) LoadLatestProcessByWorkflow(ctx context.Context, workflowKe
 	}
 	opts := options.FindOne().SetSort(bson.D{{Key: "createdAt", Value: -1}})
 	var process Process
-	if err := s.database().Collection("processes").FindOne(ctx, filter, opts).Decode(&process); err != nil {
+	if err := s.database().Collection("processes").FindOne(ctx, withoutDeletedProcesses(filter), opts).Decode(&process); err != nil {
 		return nil, err
 	}
 	return &process, nil
@@ -331,7 +592,199 @@ func (s *MongoStore) ListRecentProcessesByWorkflow(ctx context.Context, workflow
 		filter = bson.M{"$or": []bson.M{{"workflowKey": workflowKey}, {"workflowKey": bson.M{"$exists": false}}}}
 	}
 	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}).SetLimit(limit)
-	cursor, err := s.database().Collection("processes").Find(ctx, filter, opts)
+	cursor, err := s.database().Collection("processes").Find(ctx, withoutDeletedProcesses(filter), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var processes []Process
+	for cursor.Next(ctx) {
+		var process Process
+		if err := cursor.Decode(&process); err != nil {
+			continue
+		}
+		processes = append(processes, process)
+	}
+	return processes, nil
+}
+
+func (s *MongoStore) ListProcessesByParticipant(ctx context.Context, workflowKey, participantID string) ([]Process, error) {
+	filter := bson.M{"workflowKey": workflowKey, "participants": strings.TrimSpace(participantID)}
+	if workflowKey == "workflow" {
+		filter = bson.M{
+			"participants": strings.TrimSpace(participantID),
+			"$or":          []bson.M{{"workflowKey": workflowKey}, {"workflowKey": bson.M{"$exists": false}}},
+		}
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	cursor, err := s.database().Collection("processes").Find(ctx, withoutDeletedProcesses(filter), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var processes []Process
+	for cursor.Next(ctx) {
+		var process Process
+		if err := cursor.Decode(&process); err != nil {
+			continue
+		}
+		processes = append(processes, process)
+	}
+	return processes, nil
+}
+
+// ListProcessesByMetadata lists the processes of a workflow whose metadata
+// key equals value, newest first.
+func (s *MongoStore) ListProcessesByMetadata(ctx context.Context, workflowKey, key, value string) ([]Process, error) {
+	field := "metadata." + key
+	filter := bson.M{"workflowKey": workflowKey, field: value}
+	if workflowKey == "workflow" {
+		filter = bson.M{
+			field: value,
+			"$or": []bson.M{{"workflowKey": workflowKey}, {"workflowKey": bson.M{"$exists": false}}},
+		}
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	cursor, err := s.database().Collection("processes").Find(ctx, withoutDeletedProcesses(filter), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var processes []Process
+	for cursor.Next(ctx) {
+		var process Process
+		if err := cursor.Decode(&process); err != nil {
+			continue
+		}
+		processes = append(processes, process)
+	}
+	return processes, nil
+}
+
+// withoutDeletedProcesses hides processes soft-deleted by the retention sweeper
+// from listings.
+func withoutDeletedProcesses(filter bson.M) bson.M {
+	filter["deletedAt"] = bson.M{"$exists": false}
+	return filter
+}
+
+// runMigrationOnce runs migrate unless the migrations collection already
+// records name, then records it, so startup backfills scan the processes
+// collection once instead of on every boot.
+func (s *MongoStore) runMigrationOnce(ctx context.Context, name string, migrate func() error) error {
+	migrations := s.database().Collection("migrations")
+	err := migrations.FindOne(ctx, bson.M{"_id": name}).Err()
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+	if err := migrate(); err != nil {
+		return err
+	}
+	_, err = migrations.UpdateOne(
+		ctx,
+		bson.M{"_id": name},
+		bson.M{"$setOnInsert": bson.M{"doneAt": time.Now().UTC()}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// BackfillProcessParticipants fills the participants array for processes
+// stored before it existed and ensures the index used by
+// ListProcessesByParticipant. The backfill runs once (migration
+// "process_participants").
+func (s *MongoStore) BackfillProcessParticipants(ctx context.Context) error {
+	collection := s.database().Collection("processes")
+	if err := collection.CreateIndexes(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "workflowKey", Value: 1}, {Key: "participants", Value: 1}}},
+	}); err != nil {
+		return err
+	}
+	return s.runMigrationOnce(ctx, "process_participants", func() error {
+		cursor, err := collection.Find(ctx, bson.M{"participants": bson.M{"$exists": false}})
+		if err != nil {
+			return err
+		}
+		defer cursor.Close(ctx)
+
+		for cursor.Next(ctx) {
+			var process Process
+			if err := cursor.Decode(&process); err != nil {
+				continue
+			}
+			if _, err := collection.UpdateOne(
+				ctx,
+				bson.M{"_id": process.ID},
+				bson.M{"$set": bson.M{"participants": processParticipants(process.Progress)}},
+			); err != nil {
+				return err
+			}
+		}
+		return cursor.Err()
+	})
+}
+
+// EnsureProcessCodeIndex keeps process codes unique per workflow and backs
+// LoadProcessByCode. Processes without a code are not indexed.
+func (s *MongoStore) EnsureProcessCodeIndex(ctx context.Context) error {
+	return s.database().Collection("processes").CreateIndexes(ctx, []mongo.IndexModel{{
+		Keys: bson.D{{Key: "workflowKey", Value: 1}, {Key: "code", Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+			"code": bson.M{"$exists": true},
+		}),
+	}})
+}
+
+// BackfillProcessSearchText fills searchText for processes stored before it
+// existed. It runs once (migration "process_search_text").
+func (s *MongoStore) BackfillProcessSearchText(ctx context.Context) error {
+	collection := s.database().Collection("processes")
+	return s.runMigrationOnce(ctx, "process_search_text", func() error {
+		cursor, err := collection.Find(ctx, bson.M{"searchText": bson.M{"$exists": false}})
+		if err != nil {
+			return err
+		}
+		defer cursor.Close(ctx)
+
+		for cursor.Next(ctx) {
+			var process Process
+			if err := cursor.Decode(&process); err != nil {
+				continue
+			}
+			if _, err := collection.UpdateOne(
+				ctx,
+				bson.M{"_id": process.ID},
+				bson.M{"$set": bson.M{"searchText": processIndexText(process)}},
+			); err != nil {
+				return err
+			}
+		}
+		return cursor.Err()
+	})
+}
+
+// SearchProcesses returns the processes with a searchText value containing
+// query, ignoring case. It is the same substring match as
+// processMatchesSearch in MemoryStore; a $text index would only match whole
+// stemmed words, so "voice" would miss "invoice" here but not in memory.
+func (s *MongoStore) SearchProcesses(ctx context.Context, workflowKey, query string, limit int64) ([]Process, error) {
+	needle := strings.TrimSpace(query)
+	if needle == "" {
+		return nil, nil
+	}
+	filter := bson.M{"workflowKey": workflowKey}
+	if workflowKey == "workflow" {
+		filter = bson.M{"$or": []bson.M{{"workflowKey": workflowKey}, {"workflowKey": bson.M{"$exists": false}}}}
+	}
+	filter["searchText"] = bson.M{"$regex": regexp.QuoteMeta(needle), "$options": "i"}
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}).SetLimit(limit)
+	cursor, err := s.database().Collection("processes").Find(ctx, withoutDeletedProcesses(filter), opts)
 	if err != nil {
 		return nil, err
 	}
@@ -351,7 +804,7 @@ func (s *MongoStore) ListRecentProcessesByWorkflow(ctx context.Context, workflow
 func (s *MongoStore) HasProcessesByWorkflow(ctx context.Context, workflowKey string) (bool, error) {
 	err := s.database().Collection("processes").FindOne(
 		ctx,
-		bson.M{"workflowKey": strings.TrimSpace(workflowKey)},
+		withoutDeletedProcesses(bson.M{"workflowKey": strings.TrimSpace(workflowKey)}),
 		options.FindOne().SetProjection(bson.M{"_id": 1}),
 	).Err()
 	switch {
@@ -365,11 +818,11 @@ func (s *MongoStore) HasProcessesByWorkflow(ctx context.Context, workflowKey str
 }
 
 func (s *MongoStore) LoadProcessByDigitalLink(ctx context.Context, gtin, lot, serial string) (*Process, error) {
-	filter := bson.M{
+	filter := withoutDeletedProcesses(bson.M{
 		"dpp.gtin":   strings.TrimSpace(gtin),
 		"dpp.lot":    strings.TrimSpace(lot),
 		"dpp.serial": strings.TrimSpace(serial),
-	}
+	})
 	var process Process
 	if err := s.database().Collection("processes").FindOne(ctx, filter).Decode(&process); err != nil {
 		return nil, err
@@ -383,15 +836,129 @@ func (s *MongoStore) UpdateProcessProgress(ctx context.Context, id primitive.Obj
 			"workflowKey": workflowKey,
 			"progress." + encodeProgressKey(substepID): progress,
 		},
+		"$currentDate": bson.M{"updatedAt": true},
+	}
+	addToSet := bson.M{}
+	if participant := progressParticipant(progress); participant != "" {
+		addToSet["participants"] = participant
+	}
+	if progress.State == "done" {
+		if text := searchableStrings(progress.Data); len(text) > 0 {
+			addToSet["searchText"] = bson.M{"$each": text}
+		}
+	}
+	if len(addToSet) > 0 {
+		update["$addToSet"] = addToSet
+	}
+	// Done and terminated processes take no more progress, even from a
+	// completion that passed the handler's read check before the process
+	// closed: the DPP and completion webhook already describe the final data.
+	filter := bson.M{"_id": id, "status": bson.M{"$nin": []string{processStatusDone, processStatusTerminated}}, "termination": bson.M{"$exists": false}}
+	return s.withRetry(ctx, func() error {
+		collection := s.database().Collection("processes")
+		err := collection.FindOneAndUpdate(ctx, filter, update).Err()
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			return err
+		}
+		if findErr := collection.FindOne(ctx, bson.M{"_id": id}, options.FindOne().SetProjection(bson.M{"_id": 1})).Err(); findErr != nil {
+			return findErr
+		}
+		return ErrIllegalStatusTransition
+	})
+}
+
+// AppendProcessAmendment pushes an amendment onto a completed substep. The
+// original submission in progress.data is left untouched.
+func (s *MongoStore) AppendProcessAmendment(ctx context.Context, id primitive.ObjectID, workflowKey, substepID string, amendment ProcessAmendment) error {
+	key := "progress." + encodeProgressKey(substepID)
+	update := bson.M{
+		"$set":         bson.M{"workflowKey": workflowKey},
+		"$push":        bson.M{key + ".amendments": amendment},
+		"$currentDate": bson.M{"updatedAt": true},
+	}
+	if text := searchableStrings(amendment.Data); len(text) > 0 {
+		update["$addToSet"] = bson.M{"searchText": bson.M{"$each": text}}
+	}
+	collection := s.database().Collection("processes")
+	filter := bson.M{"_id": id, key + ".state": "done", "status": bson.M{"$ne": processStatusTerminated}, "termination": bson.M{"$exists": false}}
+	err := collection.FindOneAndUpdate(ctx, filter, update).Err()
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+	terminated := bson.M{"_id": id, "$or": []bson.M{{"status": processStatusTerminated}, {"termination": bson.M{"$exists": true}}}}
+	if findErr := collection.FindOne(ctx, terminated, options.FindOne().SetProjection(bson.M{"_id": 1})).Err(); findErr == nil {
+		return ErrIllegalStatusTransition
 	}
-	return s.database().Collection("processes").FindOneAndUpdate(ctx, bson.M{"_id": id}, update).Err()
+	return err
 }
 
+// UpdateProcessStatus sets status unless the stored status is terminal and
+// different (ErrIllegalStatusTransition). The guard is part of the update
+// filter, so concurrent writers cannot reopen a closed process.
 func (s *MongoStore) UpdateProcessStatus(ctx context.Context, id primitive.ObjectID, workflowKey, status string) error {
-	_, err := s.database().Collection("processes").UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"status": status, "workflowKey": workflowKey}})
+	update := bson.M{
+		"$set":         bson.M{"status": status, "workflowKey": workflowKey},
+		"$currentDate": bson.M{"updatedAt": true},
+	}
+	return s.withRetry(ctx, func() error {
+		return s.updateProcessGuarded(ctx, id, status, nil, update)
+	})
+}
+
+// MarkProcessCompletionNotified sets completedNotifiedAt only when it is
+// missing, so concurrent callers race on the filter and exactly one of them
+// gets true.
+func (s *MongoStore) MarkProcessCompletionNotified(ctx context.Context, id primitive.ObjectID, at time.Time) (bool, error) {
+	result, err := s.database().Collection("processes").UpdateOne(
+		ctx,
+		bson.M{"_id": id, "completedNotifiedAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"completedNotifiedAt": at}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result != nil && result.ModifiedCount > 0, nil
+}
+
+// ReleaseProcessCompletionNotified clears a completedNotifiedAt claim made
+// at `at` so a failed delivery is retried by the next completion check. A
+// newer claim is left alone.
+func (s *MongoStore) ReleaseProcessCompletionNotified(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+	_, err := s.database().Collection("processes").UpdateOne(
+		ctx,
+		bson.M{"_id": id, "completedNotifiedAt": at},
+		bson.M{"$unset": bson.M{"completedNotifiedAt": ""}},
+	)
 	return err
 }
 
+// updateProcessGuarded applies update when the stored status may move to
+// status and every guard field matches; otherwise an existing process
+// yields ErrIllegalStatusTransition.
+func (s *MongoStore) updateProcessGuarded(ctx context.Context, id primitive.ObjectID, status string, guard bson.M, update bson.M) error {
+	collection := s.database().Collection("processes")
+	filter := bson.M{"_id": id, "status": bson.M{"$nin": statusesBlockingTransitionTo(status)}}
+	for key, value := range guard {
+		filter[key] = value
+	}
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result == nil || result.MatchedCount > 0 {
+		return nil
+	}
+	err = collection.FindOne(ctx, bson.M{"_id": id}, options.FindOne().SetProjection(bson.M{"_id": 1})).Err()
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return nil
+	case err != nil:
+		return err
+	default:
+		return ErrIllegalStatusTransition
+	}
+}
+
 func (s *MongoStore) UpdateProcessTermination(ctx context.Context, id primitive.ObjectID, workflowKey string, termination ProcessTermination) error {
 	update := bson.M{
 		"$set": bson.M{
@@ -399,9 +966,11 @@ func (s *MongoStore) UpdateProcessTermination(ctx context.Context, id primitive.
 			"workflowKey": workflowKey,
 			"termination": termination,
 		},
+		"$currentDate": bson.M{"updatedAt": true},
 	}
-	_, err := s.database().Collection("processes").UpdateOne(ctx, bson.M{"_id": id}, update)
-	return err
+	// Re-terminating passes the status guard, so the first termination
+	// record is protected separately from a concurrent second request.
+	return s.updateProcessGuarded(ctx, id, processStatusTerminated, bson.M{"termination": bson.M{"$exists": false}}, update)
 }
 
 func (s *MongoStore) UpdateProcessDPP(ctx context.Context, id primitive.ObjectID, workflowKey string, dpp ProcessDPP) error {
@@ -410,29 +979,122 @@ func (s *MongoStore) UpdateProcessDPP(ctx context.Context, id primitive.ObjectID
 			"workflowKey": workflowKey,
 			"dpp":         dpp,
 		},
+		"$currentDate": bson.M{"updatedAt": true},
 	}
-	_, err := s.database().Collection("processes").UpdateOne(ctx, bson.M{"_id": id}, update)
-	return err
+	return s.withRetry(ctx, func() error {
+		_, err := s.database().Collection("processes").UpdateOne(ctx, bson.M{"_id": id}, update)
+		return err
+	})
 }
 
-func (s *MongoStore) GetSubstepOverride(ctx context.Context, processID primitive.ObjectID, substepID string) (*SubstepOverride, error) {
-	process, err := s.LoadProcessByID(ctx, processID)
-	if err != nil {
-		return nil, err
+// UpdateProcessMetadata sets and removes metadata keys, then rewrites
+// searchText from the updated document so removed values stop matching.
+func (s *MongoStore) UpdateProcessMetadata(ctx context.Context, id primitive.ObjectID, set map[string]string, unset []string) error {
+	update := bson.M{}
+	if len(set) > 0 {
+		fields := bson.M{}
+		for key, value := range set {
+			fields["metadata."+key] = value
+		}
+		update["$set"] = fields
 	}
-	overrides := normalizeSubstepOverrideKeys(process.Overrides)
-	override, ok := overrides[strings.TrimSpace(substepID)]
-	if !ok {
-		return nil, mongo.ErrNoDocuments
+	if len(unset) > 0 {
+		fields := bson.M{}
+		for _, key := range unset {
+			fields["metadata."+key] = ""
+		}
+		update["$unset"] = fields
 	}
-	cloned := cloneSubstepOverride(override)
-	return &cloned, nil
+	if len(update) == 0 {
+		return nil
+	}
+	collection := s.database().Collection("processes")
+	var process Process
+	if err := collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": id},
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&process); err != nil {
+		return err
+	}
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"searchText": processIndexText(process)}})
+	return err
 }
 
-func (s *MongoStore) SaveSubstepOverride(ctx context.Context, processID primitive.ObjectID, workflowKey, substepID string, override SubstepOverride) error {
-	existing, err := s.GetSubstepOverride(ctx, processID, substepID)
-	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
-		return err
+// UpdateProcessWorkflowKey moves a process to another workflow and sets its
+// code there, removing it when code is empty. Callers check that the target
+// workflow still resolves the stored progress keys. A code already used in
+// the target workflow returns ErrProcessCodeConflict.
+func (s *MongoStore) UpdateProcessWorkflowKey(ctx context.Context, id primitive.ObjectID, workflowKey, code string) error {
+	update := bson.M{
+		"$set":         bson.M{"workflowKey": strings.TrimSpace(workflowKey)},
+		"$currentDate": bson.M{"updatedAt": true},
+	}
+	if code = strings.TrimSpace(code); code != "" {
+		update["$set"].(bson.M)["code"] = code
+	} else {
+		update["$unset"] = bson.M{"code": ""}
+	}
+	result, err := s.database().Collection("processes").UpdateOne(ctx, bson.M{"_id": id}, update)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrProcessCodeConflict
+	}
+	if err != nil {
+		return err
+	}
+	if result != nil && result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// RejectProcessSubstep resets rejection.ResetSubsteps to pending and records
+// the rejection. The update only applies while the rejected substep is done
+// and the process is still open; otherwise it returns ErrSubstepNotDone.
+func (s *MongoStore) RejectProcessSubstep(ctx context.Context, id primitive.ObjectID, workflowKey string, rejection ProcessRejection) error {
+	set := bson.M{"workflowKey": workflowKey}
+	for _, substepID := range rejection.ResetSubsteps {
+		set["progress."+encodeProgressKey(substepID)] = ProcessStep{State: "pending"}
+	}
+	update := bson.M{
+		"$set":         set,
+		"$push":        bson.M{"rejections": rejection},
+		"$currentDate": bson.M{"updatedAt": true},
+	}
+	filter := bson.M{
+		"_id": id,
+		"progress." + encodeProgressKey(rejection.SubstepID) + ".state": "done",
+		"status": bson.M{"$nin": []string{processStatusDone, processStatusTerminated}},
+	}
+	result, err := s.database().Collection("processes").UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result != nil && result.MatchedCount == 0 {
+		return ErrSubstepNotDone
+	}
+	return nil
+}
+
+func (s *MongoStore) GetSubstepOverride(ctx context.Context, processID primitive.ObjectID, substepID string) (*SubstepOverride, error) {
+	process, err := s.LoadProcessByID(ctx, processID)
+	if err != nil {
+		return nil, err
+	}
+	overrides := normalizeSubstepOverrideKeys(process.Overrides)
+	override, ok := overrides[strings.TrimSpace(substepID)]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	cloned := cloneSubstepOverride(override)
+	return &cloned, nil
+}
+
+func (s *MongoStore) SaveSubstepOverride(ctx context.Context, processID primitive.ObjectID, workflowKey, substepID string, override SubstepOverride) error {
+	existing, err := s.GetSubstepOverride(ctx, processID, substepID)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
 	}
 	if existing != nil && !existing.CreatedAt.IsZero() {
 		override.CreatedAt = existing.CreatedAt
@@ -446,9 +1108,70 @@ func (s *MongoStore) SaveSubstepOverride(ctx context.Context, processID primitiv
 	return s.database().Collection("processes").FindOneAndUpdate(ctx, bson.M{"_id": processID}, update).Err()
 }
 
+// InsertNotarization fixes the _id before the first attempt, so a retry
+// after a write that landed but whose reply was lost hits a duplicate key
+// instead of storing the notarization twice; that duplicate means success.
 func (s *MongoStore) InsertNotarization(ctx context.Context, notarization Notarization) error {
-	_, err := s.database().Collection("notarizations").InsertOne(ctx, notarization)
-	return err
+	if notarization.ID.IsZero() {
+		notarization.ID = primitive.NewObjectID()
+	}
+	attempt := 0
+	return s.withRetry(ctx, func() error {
+		attempt++
+		_, err := s.database().Collection("notarizations").InsertOne(ctx, notarization)
+		if attempt > 1 && mongo.IsDuplicateKeyError(err) {
+			return nil
+		}
+		return err
+	})
+}
+
+// GetNotarizationBySubstep returns the latest notarization recorded for the
+// substep, which is the newest amendment once the value has been amended.
+func (s *MongoStore) GetNotarizationBySubstep(ctx context.Context, processID primitive.ObjectID, substepID string) (*Notarization, error) {
+	var notarization Notarization
+	err := s.withRetry(ctx, func() error {
+		notarization = Notarization{}
+		return s.database().Collection("notarizations").FindOne(
+			ctx,
+			bson.M{"processId": processID, "substepId": substepID},
+			options.FindOne().SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}),
+		).Decode(&notarization)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &notarization, nil
+}
+
+// ListNotarizations returns every notarization of the process, oldest first.
+func (s *MongoStore) ListNotarizations(ctx context.Context, processID primitive.ObjectID) ([]Notarization, error) {
+	var notarizations []Notarization
+	err := s.withRetry(ctx, func() error {
+		notarizations = nil
+		cursor, err := s.database().Collection("notarizations").Find(
+			ctx,
+			bson.M{"processId": processID},
+			options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}),
+		)
+		if err != nil {
+			return err
+		}
+		defer cursor.Close(ctx)
+
+		for cursor.Next(ctx) {
+			var notarization Notarization
+			if err := cursor.Decode(&notarization); err != nil {
+				return err
+			}
+			notarizations = append(notarizations, notarization)
+		}
+		return cursor.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return notarizations, nil
 }
 
 func (s *MongoStore) SaveAttachment(ctx context.Context, upload AttachmentUpload, content io.Reader) (Attachment, error) {
@@ -509,6 +1232,19 @@ func (s *MongoStore) SaveAttachment(ctx context.Context, upload AttachmentUpload
 }
 
 func (s *MongoStore) LoadAttachmentByID(ctx context.Context, id primitive.ObjectID) (*Attachment, error) {
+	return s.findAttachment(ctx, bson.M{"_id": id})
+}
+
+// LoadAttachmentBySHA256 returns the first attachment of processID whose
+// content hash is sha256.
+func (s *MongoStore) LoadAttachmentBySHA256(ctx context.Context, processID primitive.ObjectID, sha256 string) (*Attachment, error) {
+	return s.findAttachment(ctx, bson.M{
+		"metadata.processId": processID,
+		"metadata.sha256":    strings.ToLower(strings.TrimSpace(sha256)),
+	})
+}
+
+func (s *MongoStore) findAttachment(ctx context.Context, filter bson.M) (*Attachment, error) {
 	var doc struct {
 		ID         primitive.ObjectID `bson:"_id"`
 		Filename   string             `bson:"filename"`
@@ -522,7 +1258,7 @@ func (s *MongoStore) LoadAttachmentByID(ctx context.Context, id primitive.Object
 			SHA256      string             `bson:"sha256"`
 		} `bson:"metadata"`
 	}
-	if err := s.database().Collection("attachments.files").FindOne(ctx, bson.M{"_id": id}).Decode(&doc); err != nil {
+	if err := s.database().Collection("attachments.files").FindOne(ctx, filter).Decode(&doc); err != nil {
 		return nil, err
 	}
 	uploadedAt := doc.Metadata.UploadedAt
@@ -550,6 +1286,19 @@ func (s *MongoStore) OpenAttachmentDownload(ctx context.Context, id primitive.Ob
 	return bucket.OpenDownloadStream(id)
 }
 
+// DeleteAttachment removes one GridFS blob and its chunks. A blob that is
+// already gone is not an error.
+func (s *MongoStore) DeleteAttachment(ctx context.Context, id primitive.ObjectID) error {
+	bucket, err := s.attachmentsBucket()
+	if err != nil {
+		return err
+	}
+	if err := bucket.Delete(id); err != nil && !errors.Is(err, gridfs.ErrFileNotFound) {
+		return err
+	}
+	return nil
+}
+
 func (s *MongoStore) attachmentsBucket() (gridFSBucketPort, error) {
 	return s.database().NewGridFSBucket("attachments")
 }
@@ -560,6 +1309,15 @@ type MemoryStore struct {
 	notarizations  []Notarization
 	attachments    map[primitive.ObjectID]memoryAttachment
 	formataStreams map[primitive.ObjectID]FormataBuilderStream
+	locks          map[string]memoryLock
+	shareLinks     map[string]ShareLink
+	processEvents  []ProcessEvent
+	sequences      map[string]int64
+	rolesVersions  map[string]int64
+	drafts         map[string]SubstepDraft
+	readOnly       bool
+	// txMu serializes WithTransaction calls.
+	txMu sync.Mutex
 
 	InsertProcessErr  error
 	LoadProcessErr    error
@@ -570,6 +1328,11 @@ type MemoryStore struct {
 	InsertNotarizeErr error
 }
 
+type memoryLock struct {
+	owner     string
+	expiresAt time.Time
+}
+
 type memoryAttachment struct {
 	meta    Attachment
 	content []byte
@@ -583,6 +1346,30 @@ func NewMemoryStore() *MemoryStore {
 	}
 }
 
+// WithTransaction runs fn and, when it fails, puts back the processes and
+// notarizations it started from. Writes made outside a transaction while fn
+// runs are not isolated from the rollback.
+func (s *MemoryStore) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+	s.mu.RLock()
+	processes := make(map[primitive.ObjectID]Process, len(s.processes))
+	for id, process := range s.processes {
+		processes[id] = cloneProcess(process)
+	}
+	notarizations := append([]Notarization(nil), s.notarizations...)
+	s.mu.RUnlock()
+
+	if err := fn(ctx); err != nil {
+		s.mu.Lock()
+		s.processes = processes
+		s.notarizations = notarizations
+		s.mu.Unlock()
+		return fmt.Errorf("%w: %w", ErrTransactionRolledBack, err)
+	}
+	return nil
+}
+
 func (s *MemoryStore) SeedProcess(process Process) primitive.ObjectID {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -638,6 +1425,77 @@ func (s *MemoryStore) LoadProcessByID(_ context.Context, id primitive.ObjectID)
 	return &cloned, nil
 }
 
+func (s *MemoryStore) LoadProcessByCode(_ context.Context, workflowKey, code string) (*Process, error) {
+	if s.LoadProcessErr != nil {
+		return nil, s.LoadProcessErr
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, process := range s.processes {
+		if process.WorkflowKey == workflowKey && process.Code == code {
+			cloned := cloneProcess(process)
+			return &cloned, nil
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (s *MemoryStore) NextProcessSequence(_ context.Context, workflowKey string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sequences == nil {
+		s.sequences = map[string]int64{}
+	}
+	s.sequences[workflowKey]++
+	return s.sequences[workflowKey], nil
+}
+
+func (s *MemoryStore) ListProcessSequences(_ context.Context) (map[string]int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sequences := make(map[string]int64, len(s.sequences))
+	for workflowKey, value := range s.sequences {
+		sequences[workflowKey] = value
+	}
+	return sequences, nil
+}
+
+func (s *MemoryStore) ListUserRolesVersions(_ context.Context) (map[string]int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	versions := make(map[string]int64, len(s.rolesVersions))
+	for userID, value := range s.rolesVersions {
+		versions[userID] = value
+	}
+	return versions, nil
+}
+
+func (s *MemoryStore) ClaimUserRolesVersion(_ context.Context, userID string, expected int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rolesVersions == nil {
+		s.rolesVersions = map[string]int64{}
+	}
+	if s.rolesVersions[userID] != expected {
+		return ErrRolesVersionConflict
+	}
+	s.rolesVersions[userID]++
+	return nil
+}
+
+func (s *MemoryStore) SetProcessSequence(_ context.Context, workflowKey string, value int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sequences == nil {
+		s.sequences = map[string]int64{}
+	}
+	if value < s.sequences[workflowKey] {
+		return ErrSequenceBelowCurrent
+	}
+	s.sequences[workflowKey] = value
+	return nil
+}
+
 func (s *MemoryStore) LoadLatestProcessByWorkflow(_ context.Context, workflowKey string) (*Process, error) {
 	if s.LoadLatestErr != nil {
 		return nil, s.LoadLatestErr
@@ -650,6 +1508,9 @@ func (s *MemoryStore) LoadLatestProcessByWorkflow(_ context.Context, workflowKey
 	var latest Process
 	first := true
 	for _, process := range s.processes {
+		if process.DeletedAt != nil {
+			continue
+		}
 		key := strings.TrimSpace(process.WorkflowKey)
 		if key != workflowKey {
 			if !(workflowKey == "workflow" && key == "") {
@@ -676,12 +1537,145 @@ func (s *MemoryStore) ListRecentProcessesByWorkflow(_ context.Context, workflowK
 	defer s.mu.RUnlock()
 	items := make([]Process, 0, len(s.processes))
 	for _, process := range s.processes {
+		if process.DeletedAt != nil {
+			continue
+		}
+		key := strings.TrimSpace(process.WorkflowKey)
+		if key != workflowKey {
+			if !(workflowKey == "workflow" && key == "") {
+				continue
+			}
+		}
+		items = append(items, cloneProcess(process))
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].CreatedAt.After(items[j].CreatedAt)
+	})
+	if limit > 0 && int64(len(items)) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+func (s *MemoryStore) ListProcessesByParticipant(_ context.Context, workflowKey, participantID string) ([]Process, error) {
+	if s.ListProcessesErr != nil {
+		return nil, s.ListProcessesErr
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	participantID = strings.TrimSpace(participantID)
+	items := make([]Process, 0)
+	for _, process := range s.processes {
+		if process.DeletedAt != nil {
+			continue
+		}
+		key := strings.TrimSpace(process.WorkflowKey)
+		if key != workflowKey {
+			if !(workflowKey == "workflow" && key == "") {
+				continue
+			}
+		}
+		if participantID == "" || !containsRole(process.Participants, participantID) {
+			continue
+		}
+		items = append(items, cloneProcess(process))
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].CreatedAt.After(items[j].CreatedAt)
+	})
+	return items, nil
+}
+
+func (s *MemoryStore) ListProcessesByMetadata(_ context.Context, workflowKey, key, value string) ([]Process, error) {
+	if s.ListProcessesErr != nil {
+		return nil, s.ListProcessesErr
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	items := make([]Process, 0)
+	for _, process := range s.processes {
+		if process.DeletedAt != nil {
+			continue
+		}
+		stored := strings.TrimSpace(process.WorkflowKey)
+		if stored != workflowKey {
+			if !(workflowKey == "workflow" && stored == "") {
+				continue
+			}
+		}
+		if current, ok := process.Metadata[key]; !ok || current != value {
+			continue
+		}
+		items = append(items, cloneProcess(process))
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].CreatedAt.After(items[j].CreatedAt)
+	})
+	return items, nil
+}
+
+func (s *MemoryStore) BackfillProcessParticipants(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, process := range s.processes {
+		if process.Participants != nil {
+			continue
+		}
+		process.Participants = processParticipants(process.Progress)
+		s.processes[id] = process
+	}
+	return nil
+}
+
+func (s *MemoryStore) EnsureProcessCodeIndex(_ context.Context) error {
+	return nil
+}
+
+func (s *MemoryStore) EnsureProcessEventsIndex(_ context.Context) error {
+	return nil
+}
+
+func (s *MemoryStore) EnsureShareLinkIndex(_ context.Context) error {
+	return nil
+}
+
+func (s *MemoryStore) EnsureSubstepDraftIndex(_ context.Context) error {
+	return nil
+}
+
+func (s *MemoryStore) BackfillProcessSearchText(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, process := range s.processes {
+		if process.SearchText != nil {
+			continue
+		}
+		process.SearchText = processIndexText(process)
+		s.processes[id] = process
+	}
+	return nil
+}
+
+func (s *MemoryStore) SearchProcesses(_ context.Context, workflowKey, query string, limit int64) ([]Process, error) {
+	if s.ListProcessesErr != nil {
+		return nil, s.ListProcessesErr
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	items := make([]Process, 0)
+	for _, process := range s.processes {
+		if process.DeletedAt != nil {
+			continue
+		}
 		key := strings.TrimSpace(process.WorkflowKey)
 		if key != workflowKey {
 			if !(workflowKey == "workflow" && key == "") {
 				continue
 			}
 		}
+		if !processMatchesSearch(process, query) {
+			continue
+		}
 		items = append(items, cloneProcess(process))
 	}
 	sort.Slice(items, func(i, j int) bool {
@@ -697,7 +1691,7 @@ func (s *MemoryStore) HasProcessesByWorkflow(_ context.Context, workflowKey stri
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	for _, process := range s.processes {
-		if strings.TrimSpace(process.WorkflowKey) == strings.TrimSpace(workflowKey) {
+		if process.DeletedAt == nil && strings.TrimSpace(process.WorkflowKey) == strings.TrimSpace(workflowKey) {
 			return true, nil
 		}
 	}
@@ -714,18 +1708,28 @@ func (s *MemoryStore) UpdateProcessProgress(_ context.Context, id primitive.Obje
 	if !ok {
 		return mongo.ErrNoDocuments
 	}
+	if !processAcceptsCompletions(&process) {
+		return ErrIllegalStatusTransition
+	}
 	if process.Progress == nil {
 		process.Progress = map[string]ProcessStep{}
 	}
 	process.WorkflowKey = strings.TrimSpace(workflowKey)
+	process.UpdatedAt = time.Now().UTC()
 	process.Progress[encodeProgressKey(substepID)] = cloneProcessStep(progress)
+	if participant := progressParticipant(progress); participant != "" && !containsRole(process.Participants, participant) {
+		process.Participants = append(append([]string(nil), process.Participants...), participant)
+	}
+	if progress.State == "done" {
+		process.SearchText = dedupeStrings(append(append([]string(nil), process.SearchText...), searchableStrings(progress.Data)...))
+	}
 	s.processes[id] = process
 	return nil
 }
 
-func (s *MemoryStore) UpdateProcessStatus(_ context.Context, id primitive.ObjectID, workflowKey, status string) error {
-	if s.UpdateStatusErr != nil {
-		return s.UpdateStatusErr
+func (s *MemoryStore) AppendProcessAmendment(_ context.Context, id primitive.ObjectID, workflowKey, substepID string, amendment ProcessAmendment) error {
+	if s.UpdateProgressErr != nil {
+		return s.UpdateProgressErr
 	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -733,13 +1737,25 @@ func (s *MemoryStore) UpdateProcessStatus(_ context.Context, id primitive.Object
 	if !ok {
 		return mongo.ErrNoDocuments
 	}
+	if !processAcceptsAmendments(&process) {
+		return ErrIllegalStatusTransition
+	}
+	key := encodeProgressKey(substepID)
+	step, ok := process.Progress[key]
+	if !ok || step.State != "done" {
+		return mongo.ErrNoDocuments
+	}
+	step = cloneProcessStep(step)
+	step.Amendments = append(step.Amendments, cloneProcessAmendment(amendment))
 	process.WorkflowKey = strings.TrimSpace(workflowKey)
-	process.Status = status
+	process.UpdatedAt = time.Now().UTC()
+	process.Progress[key] = step
+	process.SearchText = dedupeStrings(append(append([]string(nil), process.SearchText...), searchableStrings(amendment.Data)...))
 	s.processes[id] = process
 	return nil
 }
 
-func (s *MemoryStore) UpdateProcessTermination(_ context.Context, id primitive.ObjectID, workflowKey string, termination ProcessTermination) error {
+func (s *MemoryStore) UpdateProcessStatus(_ context.Context, id primitive.ObjectID, workflowKey, status string) error {
 	if s.UpdateStatusErr != nil {
 		return s.UpdateStatusErr
 	}
@@ -749,9 +1765,67 @@ func (s *MemoryStore) UpdateProcessTermination(_ context.Context, id primitive.O
 	if !ok {
 		return mongo.ErrNoDocuments
 	}
+	if !canTransition(process.Status, status) {
+		return ErrIllegalStatusTransition
+	}
 	process.WorkflowKey = strings.TrimSpace(workflowKey)
-	process.Status = processStatusTerminated
-	process.Termination = cloneProcessTermination(&termination)
+	process.UpdatedAt = time.Now().UTC()
+	process.Status = status
+	s.processes[id] = process
+	return nil
+}
+
+// MarkProcessCompletionNotified sets completedNotifiedAt unless it is
+// already set and reports whether this call claimed it.
+func (s *MemoryStore) MarkProcessCompletionNotified(_ context.Context, id primitive.ObjectID, at time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	process, ok := s.processes[id]
+	if !ok {
+		return false, mongo.ErrNoDocuments
+	}
+	if process.CompletedNotifiedAt != nil {
+		return false, nil
+	}
+	process.CompletedNotifiedAt = &at
+	s.processes[id] = process
+	return true, nil
+}
+
+// ReleaseProcessCompletionNotified clears completedNotifiedAt when it is
+// still the claim made at `at`.
+func (s *MemoryStore) ReleaseProcessCompletionNotified(_ context.Context, id primitive.ObjectID, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	process, ok := s.processes[id]
+	if !ok {
+		return mongo.ErrNoDocuments
+	}
+	if process.CompletedNotifiedAt == nil || !process.CompletedNotifiedAt.Equal(at) {
+		return nil
+	}
+	process.CompletedNotifiedAt = nil
+	s.processes[id] = process
+	return nil
+}
+
+func (s *MemoryStore) UpdateProcessTermination(_ context.Context, id primitive.ObjectID, workflowKey string, termination ProcessTermination) error {
+	if s.UpdateStatusErr != nil {
+		return s.UpdateStatusErr
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	process, ok := s.processes[id]
+	if !ok {
+		return mongo.ErrNoDocuments
+	}
+	if !canTransition(process.Status, processStatusTerminated) || process.Termination != nil {
+		return ErrIllegalStatusTransition
+	}
+	process.WorkflowKey = strings.TrimSpace(workflowKey)
+	process.UpdatedAt = time.Now().UTC()
+	process.Status = processStatusTerminated
+	process.Termination = cloneProcessTermination(&termination)
 	s.processes[id] = process
 	return nil
 }
@@ -764,12 +1838,86 @@ func (s *MemoryStore) UpdateProcessDPP(_ context.Context, id primitive.ObjectID,
 		return mongo.ErrNoDocuments
 	}
 	process.WorkflowKey = strings.TrimSpace(workflowKey)
+	process.UpdatedAt = time.Now().UTC()
 	dppCopy := dpp
 	process.DPP = &dppCopy
 	s.processes[id] = process
 	return nil
 }
 
+func (s *MemoryStore) UpdateProcessMetadata(_ context.Context, id primitive.ObjectID, set map[string]string, unset []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	process, ok := s.processes[id]
+	if !ok {
+		return mongo.ErrNoDocuments
+	}
+	metadata := make(map[string]string, len(process.Metadata)+len(set))
+	for key, value := range process.Metadata {
+		metadata[key] = value
+	}
+	for key, value := range set {
+		metadata[key] = value
+	}
+	for _, key := range unset {
+		delete(metadata, key)
+	}
+	if len(metadata) == 0 {
+		metadata = nil
+	}
+	process.Metadata = metadata
+	process.SearchText = processIndexText(process)
+	s.processes[id] = process
+	return nil
+}
+
+func (s *MemoryStore) UpdateProcessWorkflowKey(_ context.Context, id primitive.ObjectID, workflowKey, code string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	process, ok := s.processes[id]
+	if !ok {
+		return mongo.ErrNoDocuments
+	}
+	workflowKey, code = strings.TrimSpace(workflowKey), strings.TrimSpace(code)
+	for otherID, other := range s.processes {
+		if code != "" && otherID != id && other.WorkflowKey == workflowKey && other.Code == code {
+			return ErrProcessCodeConflict
+		}
+	}
+	process.WorkflowKey = workflowKey
+	process.Code = code
+	process.UpdatedAt = time.Now().UTC()
+	s.processes[id] = process
+	return nil
+}
+
+func (s *MemoryStore) RejectProcessSubstep(_ context.Context, id primitive.ObjectID, workflowKey string, rejection ProcessRejection) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	process, ok := s.processes[id]
+	if !ok {
+		return mongo.ErrNoDocuments
+	}
+	progress := normalizeProgressKeys(process.Progress)
+	if progress[rejection.SubstepID].State != "done" || process.Status == processStatusDone || process.Status == processStatusTerminated {
+		return ErrSubstepNotDone
+	}
+	next := make(map[string]ProcessStep, len(process.Progress))
+	for key, step := range process.Progress {
+		next[key] = step
+	}
+	for _, substepID := range rejection.ResetSubsteps {
+		delete(next, substepID)
+		next[encodeProgressKey(substepID)] = ProcessStep{State: "pending"}
+	}
+	process.Progress = next
+	process.Rejections = append(append([]ProcessRejection(nil), process.Rejections...), rejection)
+	process.WorkflowKey = strings.TrimSpace(workflowKey)
+	process.UpdatedAt = time.Now().UTC()
+	s.processes[id] = process
+	return nil
+}
+
 func (s *MemoryStore) GetSubstepOverride(_ context.Context, processID primitive.ObjectID, substepID string) (*SubstepOverride, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -819,7 +1967,7 @@ func (s *MemoryStore) LoadProcessByDigitalLink(_ context.Context, gtin, lot, ser
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	for _, process := range s.processes {
-		if process.DPP == nil {
+		if process.DPP == nil || process.DeletedAt != nil {
 			continue
 		}
 		if process.DPP.GTIN == trimGTIN && process.DPP.Lot == trimLot && process.DPP.Serial == trimSerial {
@@ -843,6 +1991,32 @@ func (s *MemoryStore) InsertNotarization(_ context.Context, notarization Notariz
 	return nil
 }
 
+func (s *MemoryStore) GetNotarizationBySubstep(ctx context.Context, processID primitive.ObjectID, substepID string) (*Notarization, error) {
+	notarizations, _ := s.ListNotarizations(ctx, processID)
+	for idx := len(notarizations) - 1; idx >= 0; idx-- {
+		if notarizations[idx].SubstepID == substepID {
+			notarization := notarizations[idx]
+			return &notarization, nil
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (s *MemoryStore) ListNotarizations(_ context.Context, processID primitive.ObjectID) ([]Notarization, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var notarizations []Notarization
+	for _, notarization := range s.notarizations {
+		if notarization.ProcessID == processID {
+			notarizations = append(notarizations, notarization)
+		}
+	}
+	sort.SliceStable(notarizations, func(i, j int) bool {
+		return notarizations[i].CreatedAt.Before(notarizations[j].CreatedAt)
+	})
+	return notarizations, nil
+}
+
 func (s *MemoryStore) SaveAttachment(_ context.Context, upload AttachmentUpload, content io.Reader) (Attachment, error) {
 	filename := strings.TrimSpace(upload.Filename)
 	if filename == "" {
@@ -899,6 +2073,26 @@ func (s *MemoryStore) LoadAttachmentByID(_ context.Context, id primitive.ObjectI
 	return &attachment, nil
 }
 
+func (s *MemoryStore) LoadAttachmentBySHA256(_ context.Context, processID primitive.ObjectID, sha256 string) (*Attachment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	want := strings.ToLower(strings.TrimSpace(sha256))
+	var found *Attachment
+	for _, item := range s.attachments {
+		if item.meta.ProcessID != processID || item.meta.SHA256 != want {
+			continue
+		}
+		if found == nil || item.meta.UploadedAt.Before(found.UploadedAt) {
+			attachment := item.meta
+			found = &attachment
+		}
+	}
+	if found == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	return found, nil
+}
+
 func (s *MemoryStore) OpenAttachmentDownload(_ context.Context, id primitive.ObjectID) (io.ReadCloser, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -910,138 +2104,352 @@ func (s *MemoryStore) OpenAttachmentDownload(_ context.Context, id primitive.Obj
 	return io.NopCloser(bytes.NewReader(content)), nil
 }
 
-func (s *MemoryStore) SaveFormataBuilderStream(_ context.Context, stream FormataBuilderStream) (FormataBuilderStream, error) {
+func (s *MemoryStore) DeleteAttachment(_ context.Context, id primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.attachments, id)
+	return nil
+}
+
+func (s *MemoryStore) SaveFormataBuilderStream(_ context.Context, stream FormataBuilderStream) (FormataBuilderStream, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if stream.ID.IsZero() {
+		stream.ID = primitive.NewObjectID()
+	}
+	if stream.UpdatedAt.IsZero() {
+		stream.UpdatedAt = time.Now().UTC()
+	}
+	if strings.TrimSpace(stream.CreatedByUserID) == "" {
+		stream.CreatedByUserID = strings.TrimSpace(stream.UpdatedByUserID)
+	}
+	if _, exists := s.formataStreams[stream.ID]; exists {
+		return FormataBuilderStream{}, errors.New("formata builder stream id already exists")
+	}
+	s.formataStreams[stream.ID] = stream
+	return stream, nil
+}
+
+func (s *MemoryStore) UpdateFormataBuilderStream(_ context.Context, stream FormataBuilderStream) (FormataBuilderStream, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if stream.ID.IsZero() {
+		return FormataBuilderStream{}, mongo.ErrNoDocuments
+	}
+	if _, exists := s.formataStreams[stream.ID]; !exists {
+		return FormataBuilderStream{}, mongo.ErrNoDocuments
+	}
+	if stream.UpdatedAt.IsZero() {
+		stream.UpdatedAt = time.Now().UTC()
+	}
+	if strings.TrimSpace(stream.CreatedByUserID) == "" {
+		stream.CreatedByUserID = strings.TrimSpace(stream.UpdatedByUserID)
+	}
+	s.formataStreams[stream.ID] = stream
+	return stream, nil
+}
+
+func (s *MemoryStore) LoadFormataBuilderStream(_ context.Context) (*FormataBuilderStream, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.formataStreams) == 0 {
+		return nil, mongo.ErrNoDocuments
+	}
+	var latest FormataBuilderStream
+	first := true
+	for _, stream := range s.formataStreams {
+		if first ||
+			stream.UpdatedAt.After(latest.UpdatedAt) ||
+			(stream.UpdatedAt.Equal(latest.UpdatedAt) && stream.ID.Timestamp().After(latest.ID.Timestamp())) {
+			latest = stream
+			first = false
+		}
+	}
+	copied := latest
+	return &copied, nil
+}
+
+func (s *MemoryStore) LoadFormataBuilderStreamByID(_ context.Context, id primitive.ObjectID) (*FormataBuilderStream, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	stream, ok := s.formataStreams[id]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	copied := stream
+	return &copied, nil
+}
+
+func (s *MemoryStore) ListFormataBuilderStreams(_ context.Context) ([]FormataBuilderStream, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.formataStreams) == 0 {
+		return nil, nil
+	}
+	items := make([]FormataBuilderStream, 0, len(s.formataStreams))
+	for _, stream := range s.formataStreams {
+		items = append(items, stream)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].UpdatedAt.Equal(items[j].UpdatedAt) {
+			return items[i].ID.Hex() < items[j].ID.Hex()
+		}
+		return items[i].UpdatedAt.After(items[j].UpdatedAt)
+	})
+	return items, nil
+}
+
+func (s *MemoryStore) DeleteFormataBuilderStream(_ context.Context, id primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.formataStreams[id]; !ok {
+		return mongo.ErrNoDocuments
+	}
+	delete(s.formataStreams, id)
+	return nil
+}
+
+func (s *MemoryStore) DeleteWorkflowData(_ context.Context, workflowKey string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	trimmedKey := strings.TrimSpace(workflowKey)
+	processIDs := make(map[primitive.ObjectID]struct{})
+	for id, process := range s.processes {
+		if strings.TrimSpace(process.WorkflowKey) != trimmedKey {
+			continue
+		}
+		processIDs[id] = struct{}{}
+		delete(s.processes, id)
+	}
+
+	if len(processIDs) == 0 {
+		return nil
+	}
+
+	notarizations := s.notarizations[:0]
+	for _, notarization := range s.notarizations {
+		if _, ok := processIDs[notarization.ProcessID]; ok {
+			continue
+		}
+		notarizations = append(notarizations, notarization)
+	}
+	s.notarizations = notarizations
+	s.processEvents = dropProcessEvents(s.processEvents, processIDs)
+	s.dropSubstepDrafts(processIDs)
+
+	for id, attachment := range s.attachments {
+		if _, ok := processIDs[attachment.meta.ProcessID]; ok {
+			delete(s.attachments, id)
+		}
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) DeleteProcessAttachments(_ context.Context, processID primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, attachment := range s.attachments {
+		if attachment.meta.ProcessID == processID {
+			delete(s.attachments, id)
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) PurgeTerminatedProcesses(_ context.Context, workflowKey string, endedBefore time.Time, hardDelete bool, now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	trimmedKey := strings.TrimSpace(workflowKey)
+	purged := make(map[primitive.ObjectID]struct{})
+	for id, process := range s.processes {
+		key := strings.TrimSpace(process.WorkflowKey)
+		if key != trimmedKey && !(trimmedKey == "workflow" && key == "") {
+			continue
+		}
+		if process.Status != processStatusTerminated {
+			continue
+		}
+		if process.Termination == nil || !process.Termination.EndedAt.Before(endedBefore) {
+			continue
+		}
+		if hardDelete {
+			purged[id] = struct{}{}
+			delete(s.processes, id)
+			continue
+		}
+		if process.DeletedAt != nil {
+			continue
+		}
+		deletedAt := now
+		process.DeletedAt = &deletedAt
+		s.processes[id] = process
+		purged[id] = struct{}{}
+	}
+	if !hardDelete || len(purged) == 0 {
+		return len(purged), nil
+	}
+
+	notarizations := s.notarizations[:0]
+	for _, notarization := range s.notarizations {
+		if _, ok := purged[notarization.ProcessID]; ok {
+			continue
+		}
+		notarizations = append(notarizations, notarization)
+	}
+	s.notarizations = notarizations
+	s.processEvents = dropProcessEvents(s.processEvents, purged)
+	s.dropSubstepDrafts(purged)
+	for id, attachment := range s.attachments {
+		if _, ok := purged[attachment.meta.ProcessID]; ok {
+			delete(s.attachments, id)
+		}
+	}
+	return len(purged), nil
+}
+
+func (s *MemoryStore) AcquireLock(_ context.Context, name, owner string, now time.Time, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.locks == nil {
+		s.locks = map[string]memoryLock{}
+	}
+	if current, ok := s.locks[name]; ok && current.owner != owner && current.expiresAt.After(now) {
+		return false, nil
+	}
+	s.locks[name] = memoryLock{owner: owner, expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+func (s *MemoryStore) LoadReadOnly(_ context.Context) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.readOnly, nil
+}
+
+func (s *MemoryStore) SetReadOnly(_ context.Context, enabled bool) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if stream.ID.IsZero() {
-		stream.ID = primitive.NewObjectID()
-	}
-	if stream.UpdatedAt.IsZero() {
-		stream.UpdatedAt = time.Now().UTC()
-	}
-	if strings.TrimSpace(stream.CreatedByUserID) == "" {
-		stream.CreatedByUserID = strings.TrimSpace(stream.UpdatedByUserID)
-	}
-	if _, exists := s.formataStreams[stream.ID]; exists {
-		return FormataBuilderStream{}, errors.New("formata builder stream id already exists")
-	}
-	s.formataStreams[stream.ID] = stream
-	return stream, nil
+	s.readOnly = enabled
+	return nil
 }
 
-func (s *MemoryStore) UpdateFormataBuilderStream(_ context.Context, stream FormataBuilderStream) (FormataBuilderStream, error) {
+func (s *MemoryStore) CreateShareLink(_ context.Context, link ShareLink) (ShareLink, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if stream.ID.IsZero() {
-		return FormataBuilderStream{}, mongo.ErrNoDocuments
+	if s.shareLinks == nil {
+		s.shareLinks = map[string]ShareLink{}
 	}
-	if _, exists := s.formataStreams[stream.ID]; !exists {
-		return FormataBuilderStream{}, mongo.ErrNoDocuments
-	}
-	if stream.UpdatedAt.IsZero() {
-		stream.UpdatedAt = time.Now().UTC()
+	if _, exists := s.shareLinks[link.TokenHash]; exists {
+		return ShareLink{}, errors.New("share link token already exists")
 	}
-	if strings.TrimSpace(stream.CreatedByUserID) == "" {
-		stream.CreatedByUserID = strings.TrimSpace(stream.UpdatedByUserID)
+	if link.ID.IsZero() {
+		link.ID = primitive.NewObjectID()
 	}
-	s.formataStreams[stream.ID] = stream
-	return stream, nil
+	s.shareLinks[link.TokenHash] = link
+	return link, nil
 }
 
-func (s *MemoryStore) LoadFormataBuilderStream(_ context.Context) (*FormataBuilderStream, error) {
+func (s *MemoryStore) LoadShareLinkByTokenHash(_ context.Context, tokenHash string) (*ShareLink, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	if len(s.formataStreams) == 0 {
+	link, ok := s.shareLinks[strings.TrimSpace(tokenHash)]
+	if !ok {
 		return nil, mongo.ErrNoDocuments
 	}
-	var latest FormataBuilderStream
-	first := true
-	for _, stream := range s.formataStreams {
-		if first ||
-			stream.UpdatedAt.After(latest.UpdatedAt) ||
-			(stream.UpdatedAt.Equal(latest.UpdatedAt) && stream.ID.Timestamp().After(latest.ID.Timestamp())) {
-			latest = stream
-			first = false
-		}
-	}
-	copied := latest
-	return &copied, nil
+	return &link, nil
 }
 
-func (s *MemoryStore) LoadFormataBuilderStreamByID(_ context.Context, id primitive.ObjectID) (*FormataBuilderStream, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	stream, ok := s.formataStreams[id]
-	if !ok {
-		return nil, mongo.ErrNoDocuments
+func (s *MemoryStore) AppendProcessEvent(_ context.Context, event ProcessEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if event.ID.IsZero() {
+		event.ID = primitive.NewObjectID()
 	}
-	copied := stream
-	return &copied, nil
+	s.processEvents = append(s.processEvents, event)
+	return nil
 }
 
-func (s *MemoryStore) ListFormataBuilderStreams(_ context.Context) ([]FormataBuilderStream, error) {
+func (s *MemoryStore) ListProcessEvents(_ context.Context, processID primitive.ObjectID) ([]ProcessEvent, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	if len(s.formataStreams) == 0 {
-		return nil, nil
-	}
-	items := make([]FormataBuilderStream, 0, len(s.formataStreams))
-	for _, stream := range s.formataStreams {
-		items = append(items, stream)
-	}
-	sort.Slice(items, func(i, j int) bool {
-		if items[i].UpdatedAt.Equal(items[j].UpdatedAt) {
-			return items[i].ID.Hex() < items[j].ID.Hex()
+	var events []ProcessEvent
+	for _, event := range s.processEvents {
+		if event.ProcessID == processID {
+			events = append(events, event)
 		}
-		return items[i].UpdatedAt.After(items[j].UpdatedAt)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].At.Before(events[j].At)
 	})
-	return items, nil
+	return events, nil
 }
 
-func (s *MemoryStore) DeleteFormataBuilderStream(_ context.Context, id primitive.ObjectID) error {
+func memorySubstepDraftKey(processID primitive.ObjectID, substepID, userID string) string {
+	return processID.Hex() + "|" + strings.TrimSpace(substepID) + "|" + strings.TrimSpace(userID)
+}
+
+func (s *MemoryStore) SaveSubstepDraft(_ context.Context, draft SubstepDraft) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if _, ok := s.formataStreams[id]; !ok {
-		return mongo.ErrNoDocuments
+	if s.drafts == nil {
+		s.drafts = map[string]SubstepDraft{}
 	}
-	delete(s.formataStreams, id)
+	key := memorySubstepDraftKey(draft.ProcessID, draft.SubstepID, draft.UserID)
+	if existing, ok := s.drafts[key]; ok {
+		draft.ID = existing.ID
+	}
+	if draft.ID.IsZero() {
+		draft.ID = primitive.NewObjectID()
+	}
+	draft.Data = cloneInterfaceMap(draft.Data)
+	s.drafts[key] = draft
 	return nil
 }
 
-func (s *MemoryStore) DeleteWorkflowData(_ context.Context, workflowKey string) error {
+func (s *MemoryStore) LoadSubstepDraft(_ context.Context, processID primitive.ObjectID, substepID, userID string) (*SubstepDraft, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	draft, ok := s.drafts[memorySubstepDraftKey(processID, substepID, userID)]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	draft.Data = cloneInterfaceMap(draft.Data)
+	return &draft, nil
+}
+
+func (s *MemoryStore) DeleteSubstepDrafts(_ context.Context, processID primitive.ObjectID, substepID string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-
-	trimmedKey := strings.TrimSpace(workflowKey)
-	processIDs := make(map[primitive.ObjectID]struct{})
-	for id, process := range s.processes {
-		if strings.TrimSpace(process.WorkflowKey) != trimmedKey {
-			continue
+	for key, draft := range s.drafts {
+		if draft.ProcessID == processID && draft.SubstepID == strings.TrimSpace(substepID) {
+			delete(s.drafts, key)
 		}
-		processIDs[id] = struct{}{}
-		delete(s.processes, id)
-	}
-
-	if len(processIDs) == 0 {
-		return nil
 	}
+	return nil
+}
 
-	notarizations := s.notarizations[:0]
-	for _, notarization := range s.notarizations {
-		if _, ok := processIDs[notarization.ProcessID]; ok {
-			continue
+// dropSubstepDrafts removes the drafts of the given processes. Callers hold
+// s.mu.
+func (s *MemoryStore) dropSubstepDrafts(processIDs map[primitive.ObjectID]struct{}) {
+	for key, draft := range s.drafts {
+		if _, ok := processIDs[draft.ProcessID]; ok {
+			delete(s.drafts, key)
 		}
-		notarizations = append(notarizations, notarization)
 	}
-	s.notarizations = notarizations
+}
 
-	for id, attachment := range s.attachments {
-		if _, ok := processIDs[attachment.meta.ProcessID]; ok {
-			delete(s.attachments, id)
+func dropProcessEvents(events []ProcessEvent, processIDs map[primitive.ObjectID]struct{}) []ProcessEvent {
+	kept := events[:0]
+	for _, event := range events {
+		if _, ok := processIDs[event.ProcessID]; ok {
+			continue
 		}
+		kept = append(kept, event)
 	}
-
-	return nil
+	return kept
 }
 
 func cloneProcess(process Process) Process {
@@ -1051,6 +2459,18 @@ func cloneProcess(process Process) Process {
 		cloned.DPP = &dpp
 	}
 	cloned.Termination = cloneProcessTermination(process.Termination)
+	if process.Participants != nil {
+		cloned.Participants = append([]string{}, process.Participants...)
+	}
+	if process.SearchText != nil {
+		cloned.SearchText = append([]string{}, process.SearchText...)
+	}
+	if process.Metadata != nil {
+		cloned.Metadata = make(map[string]string, len(process.Metadata))
+		for key, value := range process.Metadata {
+			cloned.Metadata[key] = value
+		}
+	}
 	cloned.Progress = make(map[string]ProcessStep, len(process.Progress))
 	for key, value := range process.Progress {
 		cloned.Progress[key] = cloneProcessStep(value)
@@ -1064,6 +2484,33 @@ func cloneProcess(process Process) Process {
 	return cloned
 }
 
+func progressParticipant(progress ProcessStep) string {
+	if progress.DoneBy == nil {
+		return ""
+	}
+	return strings.TrimSpace(progress.DoneBy.ID)
+}
+
+// processParticipants lists the distinct actor ids that completed a substep,
+// sorted so backfilled documents are stable.
+func processParticipants(progress map[string]ProcessStep) []string {
+	participants := []string{}
+	seen := map[string]struct{}{}
+	for _, step := range progress {
+		participant := progressParticipant(step)
+		if participant == "" {
+			continue
+		}
+		if _, ok := seen[participant]; ok {
+			continue
+		}
+		seen[participant] = struct{}{}
+		participants = append(participants, participant)
+	}
+	sort.Strings(participants)
+	return participants
+}
+
 func cloneProcessTermination(termination *ProcessTermination) *ProcessTermination {
 	if termination == nil {
 		return nil
@@ -1086,12 +2533,32 @@ func cloneProcessStep(step ProcessStep) ProcessStep {
 		actor := *step.DoneBy
 		cloned.DoneBy = &actor
 	}
+	if step.DurationSeconds != nil {
+		duration := *step.DurationSeconds
+		cloned.DurationSeconds = &duration
+	}
 	if step.Data != nil {
 		cloned.Data = make(map[string]interface{}, len(step.Data))
 		for key, value := range step.Data {
 			cloned.Data[key] = value
 		}
 	}
+	if step.Amendments != nil {
+		cloned.Amendments = make([]ProcessAmendment, 0, len(step.Amendments))
+		for _, amendment := range step.Amendments {
+			cloned.Amendments = append(cloned.Amendments, cloneProcessAmendment(amendment))
+		}
+	}
+	return cloned
+}
+
+func cloneProcessAmendment(amendment ProcessAmendment) ProcessAmendment {
+	cloned := amendment
+	cloned.Data = cloneInterfaceMap(amendment.Data)
+	if amendment.AmendedBy != nil {
+		actor := *amendment.AmendedBy
+		cloned.AmendedBy = &actor
+	}
 	return cloned
 }
 
@@ -1364,6 +2831,36 @@ func (s *MongoStore) DeleteWorkflowData(ctx context.Context, workflowKey string)
 		return nil
 	}
 
+	return s.deleteProcessRecords(ctx, processIDs)
+}
+
+// deleteProcessRecords removes the processes with the given IDs together with
+// their notarizations, events and attachment blobs.
+func (s *MongoStore) deleteProcessRecords(ctx context.Context, processIDs []primitive.ObjectID) error {
+	if err := s.deleteAttachmentsByProcessIDs(ctx, processIDs); err != nil {
+		return err
+	}
+	if _, err := s.database().Collection("notarizations").DeleteMany(ctx, bson.M{"processId": bson.M{"$in": processIDs}}); err != nil {
+		return err
+	}
+	if _, err := s.database().Collection("process_events").DeleteMany(ctx, bson.M{"processId": bson.M{"$in": processIDs}}); err != nil {
+		return err
+	}
+	if _, err := s.database().Collection("substep_drafts").DeleteMany(ctx, bson.M{"processId": bson.M{"$in": processIDs}}); err != nil {
+		return err
+	}
+	if _, err := s.database().Collection("processes").DeleteMany(ctx, bson.M{"_id": bson.M{"$in": processIDs}}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteProcessAttachments removes every GridFS blob uploaded for processID.
+func (s *MongoStore) DeleteProcessAttachments(ctx context.Context, processID primitive.ObjectID) error {
+	return s.deleteAttachmentsByProcessIDs(ctx, []primitive.ObjectID{processID})
+}
+
+func (s *MongoStore) deleteAttachmentsByProcessIDs(ctx context.Context, processIDs []primitive.ObjectID) error {
 	attachmentCursor, err := s.database().Collection("attachments.files").Find(
 		ctx,
 		bson.M{"metadata.processId": bson.M{"$in": processIDs}},
@@ -1386,21 +2883,218 @@ func (s *MongoStore) DeleteWorkflowData(ctx context.Context, workflowKey string)
 		}
 		attachmentIDs = append(attachmentIDs, id)
 	}
+	if len(attachmentIDs) == 0 {
+		return nil
+	}
+	if _, err := s.database().Collection("attachments.chunks").DeleteMany(ctx, bson.M{"files_id": bson.M{"$in": attachmentIDs}}); err != nil {
+		return err
+	}
+	if _, err := s.database().Collection("attachments.files").DeleteMany(ctx, bson.M{"_id": bson.M{"$in": attachmentIDs}}); err != nil {
+		return err
+	}
+	return nil
+}
 
-	if len(attachmentIDs) > 0 {
-		if _, err := s.database().Collection("attachments.chunks").DeleteMany(ctx, bson.M{"files_id": bson.M{"$in": attachmentIDs}}); err != nil {
-			return err
+// PurgeTerminatedProcesses applies a workflow retention policy to terminated
+// processes that ended before endedBefore. Soft deletion stamps deletedAt so
+// listings skip the process; hard deletion also drops its notarizations and
+// attachment blobs. It returns the number of processes affected.
+func (s *MongoStore) PurgeTerminatedProcesses(ctx context.Context, workflowKey string, endedBefore time.Time, hardDelete bool, now time.Time) (int, error) {
+	workflowKey = strings.TrimSpace(workflowKey)
+	filter := bson.M{
+		"workflowKey":         workflowKey,
+		"status":              processStatusTerminated,
+		"termination.endedAt": bson.M{"$lt": endedBefore},
+	}
+	if workflowKey == "workflow" {
+		delete(filter, "workflowKey")
+		filter["$or"] = []bson.M{{"workflowKey": workflowKey}, {"workflowKey": bson.M{"$exists": false}}}
+	}
+	if !hardDelete {
+		filter = withoutDeletedProcesses(filter)
+	}
+	cursor, err := s.database().Collection("processes").Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	processIDs := make([]primitive.ObjectID, 0)
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			continue
 		}
-		if _, err := s.database().Collection("attachments.files").DeleteMany(ctx, bson.M{"_id": bson.M{"$in": attachmentIDs}}); err != nil {
-			return err
+		id, ok := doc["_id"].(primitive.ObjectID)
+		if !ok || id.IsZero() {
+			continue
+		}
+		processIDs = append(processIDs, id)
+	}
+	if len(processIDs) == 0 {
+		return 0, nil
+	}
+	if hardDelete {
+		if err := s.deleteProcessRecords(ctx, processIDs); err != nil {
+			return 0, err
+		}
+		return len(processIDs), nil
+	}
+	for _, id := range processIDs {
+		if _, err := s.database().Collection("processes").UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"deletedAt": now}}); err != nil {
+			return 0, err
 		}
 	}
+	return len(processIDs), nil
+}
 
-	if _, err := s.database().Collection("notarizations").DeleteMany(ctx, bson.M{"processId": bson.M{"$in": processIDs}}); err != nil {
-		return err
+// AcquireLock claims the named lock document for owner until now+ttl. It
+// succeeds when the lock is free, expired, or already held by owner, so
+// background jobs can coordinate across instances.
+func (s *MongoStore) AcquireLock(ctx context.Context, name, owner string, now time.Time, ttl time.Duration) (bool, error) {
+	filter := bson.M{
+		"_id": name,
+		"$or": []bson.M{{"owner": owner}, {"expiresAt": bson.M{"$lte": now}}},
 	}
-	if _, err := s.database().Collection("processes").DeleteMany(ctx, bson.M{"_id": bson.M{"$in": processIDs}}); err != nil {
-		return err
+	update := bson.M{"$set": bson.M{"owner": owner, "expiresAt": now.Add(ttl)}}
+	err := s.database().Collection("locks").FindOneAndUpdate(ctx, filter, update, options.FindOneAndUpdate().SetUpsert(true)).Err()
+	switch {
+	case err == nil, errors.Is(err, mongo.ErrNoDocuments):
+		return true, nil
+	case mongo.IsDuplicateKeyError(err):
+		return false, nil
+	default:
+		return false, err
 	}
-	return nil
+}
+
+// readOnlySettingID is the settings document holding the read-only
+// maintenance flag shared by every instance.
+const readOnlySettingID = "read_only"
+
+// LoadReadOnly reports whether read-only mode is on; a missing settings
+// document means it is off.
+func (s *MongoStore) LoadReadOnly(ctx context.Context) (bool, error) {
+	var setting struct {
+		Enabled bool `bson:"enabled"`
+	}
+	err := s.database().Collection("settings").FindOne(ctx, bson.M{"_id": readOnlySettingID}).Decode(&setting)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return setting.Enabled, nil
+}
+
+func (s *MongoStore) SetReadOnly(ctx context.Context, enabled bool) error {
+	_, err := s.database().Collection("settings").UpdateOne(
+		ctx,
+		bson.M{"_id": readOnlySettingID},
+		bson.M{"$set": bson.M{"enabled": enabled}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// EnsureShareLinkIndex makes tokenHash unique, so a token resolves to one
+// link, and backs LoadShareLinkByTokenHash.
+func (s *MongoStore) EnsureShareLinkIndex(ctx context.Context) error {
+	return s.database().Collection("share_links").CreateIndexes(ctx, []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "tokenHash", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}})
+}
+
+func (s *MongoStore) CreateShareLink(ctx context.Context, link ShareLink) (ShareLink, error) {
+	if link.ID.IsZero() {
+		link.ID = primitive.NewObjectID()
+	}
+	if _, err := s.database().Collection("share_links").InsertOne(ctx, link); err != nil {
+		return ShareLink{}, err
+	}
+	return link, nil
+}
+
+func (s *MongoStore) LoadShareLinkByTokenHash(ctx context.Context, tokenHash string) (*ShareLink, error) {
+	var link ShareLink
+	if err := s.database().Collection("share_links").FindOne(ctx, bson.M{"tokenHash": strings.TrimSpace(tokenHash)}).Decode(&link); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// EnsureSubstepDraftIndex makes (processId, substepId, userId) unique, so
+// concurrent autosaves of one user upsert a single draft.
+func (s *MongoStore) EnsureSubstepDraftIndex(ctx context.Context) error {
+	return s.database().Collection("substep_drafts").CreateIndexes(ctx, []mongo.IndexModel{{
+		Keys:    bson.D{{Key: "processId", Value: 1}, {Key: "substepId", Value: 1}, {Key: "userId", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}})
+}
+
+// SaveSubstepDraft upserts the draft of one user for one substep.
+func (s *MongoStore) SaveSubstepDraft(ctx context.Context, draft SubstepDraft) error {
+	_, err := s.database().Collection("substep_drafts").UpdateOne(
+		ctx,
+		bson.M{"processId": draft.ProcessID, "substepId": strings.TrimSpace(draft.SubstepID), "userId": strings.TrimSpace(draft.UserID)},
+		bson.M{"$set": bson.M{"data": draft.Data, "updatedAt": draft.UpdatedAt}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+func (s *MongoStore) LoadSubstepDraft(ctx context.Context, processID primitive.ObjectID, substepID, userID string) (*SubstepDraft, error) {
+	var draft SubstepDraft
+	if err := s.database().Collection("substep_drafts").FindOne(ctx, bson.M{"processId": processID, "substepId": strings.TrimSpace(substepID), "userId": strings.TrimSpace(userID)}).Decode(&draft); err != nil {
+		return nil, err
+	}
+	return &draft, nil
+}
+
+// DeleteSubstepDrafts drops the drafts of every user for one substep.
+func (s *MongoStore) DeleteSubstepDrafts(ctx context.Context, processID primitive.ObjectID, substepID string) error {
+	_, err := s.database().Collection("substep_drafts").DeleteMany(ctx, bson.M{"processId": processID, "substepId": strings.TrimSpace(substepID)})
+	return err
+}
+
+// AppendProcessEvent inserts one history entry. Events are never updated.
+func (s *MongoStore) AppendProcessEvent(ctx context.Context, event ProcessEvent) error {
+	if event.ID.IsZero() {
+		event.ID = primitive.NewObjectID()
+	}
+	_, err := s.database().Collection("process_events").InsertOne(ctx, event)
+	return err
+}
+
+// EnsureProcessEventsIndex backs ListProcessEvents, which reads one process's
+// history in time order, and the purge's processId $in delete.
+func (s *MongoStore) EnsureProcessEventsIndex(ctx context.Context) error {
+	return s.database().Collection("process_events").CreateIndexes(ctx, []mongo.IndexModel{{
+		Keys: bson.D{{Key: "processId", Value: 1}, {Key: "at", Value: 1}, {Key: "_id", Value: 1}},
+	}})
+}
+
+// ListProcessEvents returns the history of one process, oldest first.
+func (s *MongoStore) ListProcessEvents(ctx context.Context, processID primitive.ObjectID) ([]ProcessEvent, error) {
+	cursor, err := s.database().Collection("process_events").Find(
+		ctx,
+		bson.M{"processId": processID},
+		options.Find().SetSort(bson.D{{Key: "at", Value: 1}, {Key: "_id", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var events []ProcessEvent
+	for cursor.Next(ctx) {
+		var event ProcessEvent
+		if err := cursor.Decode(&event); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
 }
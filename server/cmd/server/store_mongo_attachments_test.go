@@ -14,6 +14,7 @@ import (
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
@@ -178,3 +179,26 @@ func TestMongoStoreOpenAttachmentDownloadAndBucketName(t *testing.T) {
 		t.Fatalf("bucket name calls = %#v, want [attachments]", db.bucketNames)
 	}
 }
+
+func TestMongoStoreDeleteAttachmentIgnoresMissingBlob(t *testing.T) {
+	attachmentID := primitive.NewObjectID()
+	deleteErr := gridfs.ErrFileNotFound
+	bucket := &fakeGridFSBucket{
+		deleteFn: func(fileID interface{}) error {
+			return deleteErr
+		},
+	}
+	store := &MongoStore{dbPort: &fakeMongoDatabase{bucket: bucket}}
+
+	if err := store.DeleteAttachment(t.Context(), attachmentID); err != nil {
+		t.Fatalf("DeleteAttachment missing blob error = %v, want nil", err)
+	}
+	if len(bucket.deletedIDs) != 1 || bucket.deletedIDs[0] != attachmentID {
+		t.Fatalf("deleted ids = %#v, want [%v]", bucket.deletedIDs, attachmentID)
+	}
+
+	deleteErr = errors.New("delete failed")
+	if err := store.DeleteAttachment(t.Context(), attachmentID); !errors.Is(err, deleteErr) {
+		t.Fatalf("DeleteAttachment error = %v, want %v", err, deleteErr)
+	}
+}
@@ -112,6 +112,7 @@ func TestMongoStoreLoadProcessByDigitalLinkUsesDPPFields(t *testing.T) {
 		"dpp.gtin":   "09506000134352",
 		"dpp.lot":    "LOT-001",
 		"dpp.serial": "SERIAL-001",
+		"deletedAt":  bson.M{"$exists": false},
 	}
 	if !reflect.DeepEqual(collection.findOneFilters[0], expected) {
 		t.Fatalf("filter = %#v, want %#v", collection.findOneFilters[0], expected)
@@ -141,7 +142,7 @@ func TestMongoStoreLoadLatestProcessByWorkflow(t *testing.T) {
 	if len(collection.findOneFilters) != 1 {
 		t.Fatalf("expected one findOne filter, got %d", len(collection.findOneFilters))
 	}
-	if !reflect.DeepEqual(collection.findOneFilters[0], bson.M{"workflowKey": "wf-a"}) {
+	if !reflect.DeepEqual(collection.findOneFilters[0], bson.M{"workflowKey": "wf-a", "deletedAt": bson.M{"$exists": false}}) {
 		t.Fatalf("findOne filter = %#v, want workflow filter", collection.findOneFilters[0])
 	}
 	if len(collection.findOneOptionsCalls) != 1 || len(collection.findOneOptionsCalls[0]) != 1 {
@@ -195,7 +196,7 @@ func TestMongoStoreListRecentProcessesByWorkflow(t *testing.T) {
 	if len(collection.findFilters) != 1 {
 		t.Fatalf("expected one find filter, got %d", len(collection.findFilters))
 	}
-	if !reflect.DeepEqual(collection.findFilters[0], bson.M{"workflowKey": "wf-a"}) {
+	if !reflect.DeepEqual(collection.findFilters[0], bson.M{"workflowKey": "wf-a", "deletedAt": bson.M{"$exists": false}}) {
 		t.Fatalf("find filter = %#v, want workflow filter", collection.findFilters[0])
 	}
 
@@ -220,7 +221,10 @@ func TestMongoStoreDefaultWorkflowFallbackFilter(t *testing.T) {
 	if _, err := store.ListRecentProcessesByWorkflow(t.Context(), "workflow", 10); err != nil {
 		t.Fatalf("ListRecentProcessesByWorkflow returned error: %v", err)
 	}
-	want := bson.M{"$or": []bson.M{{"workflowKey": "workflow"}, {"workflowKey": bson.M{"$exists": false}}}}
+	want := bson.M{
+		"$or":       []bson.M{{"workflowKey": "workflow"}, {"workflowKey": bson.M{"$exists": false}}},
+		"deletedAt": bson.M{"$exists": false},
+	}
 	if len(collection.findFilters) != 1 || !reflect.DeepEqual(collection.findFilters[0], want) {
 		t.Fatalf("find filter = %#v, want %#v", collection.findFilters, want)
 	}
@@ -248,6 +252,7 @@ func TestMongoStoreUpdateProcessProgress(t *testing.T) {
 			"workflowKey":  "wf-a",
 			"progress.1_1": progress,
 		},
+		"$currentDate": bson.M{"updatedAt": true},
 	}
 	if !reflect.DeepEqual(collection.findOneAndUpdUpdate[0], expectedUpdate) {
 		t.Fatalf("update doc = %#v, want %#v", collection.findOneAndUpdUpdate[0], expectedUpdate)
@@ -262,6 +267,149 @@ func TestMongoStoreUpdateProcessProgress(t *testing.T) {
 	}
 }
 
+func TestMongoStoreUpdateProcessProgressAddsParticipant(t *testing.T) {
+	collection := &fakeMongoCollection{}
+	db := &fakeMongoDatabase{collections: map[string]*fakeMongoCollection{"processes": collection}}
+	store := &MongoStore{dbPort: db}
+	progress := ProcessStep{State: "done", DoneBy: &Actor{ID: " appwrite:user-1 "}}
+
+	if err := store.UpdateProcessProgress(t.Context(), primitive.NewObjectID(), "wf-a", "1.1", progress); err != nil {
+		t.Fatalf("UpdateProcessProgress returned error: %v", err)
+	}
+	update, _ := collection.findOneAndUpdUpdate[0].(bson.M)
+	if !reflect.DeepEqual(update["$addToSet"], bson.M{"participants": "appwrite:user-1"}) {
+		t.Fatalf("update doc = %#v, want participants $addToSet", update)
+	}
+}
+
+func TestMongoStoreListProcessesByParticipant(t *testing.T) {
+	cursor := &fakeCursor{docs: []Process{{ID: primitive.NewObjectID()}}}
+	collection := &fakeMongoCollection{
+		findFn: func(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (mongoCursorPort, error) {
+			return cursor, nil
+		},
+	}
+	db := &fakeMongoDatabase{collections: map[string]*fakeMongoCollection{"processes": collection}}
+	store := &MongoStore{dbPort: db}
+
+	processes, err := store.ListProcessesByParticipant(t.Context(), "wf-a", "appwrite:user-1")
+	if err != nil || len(processes) != 1 {
+		t.Fatalf("ListProcessesByParticipant = %#v, %v", processes, err)
+	}
+	if want := (bson.M{"workflowKey": "wf-a", "participants": "appwrite:user-1", "deletedAt": bson.M{"$exists": false}}); !reflect.DeepEqual(collection.findFilters[0], want) {
+		t.Fatalf("find filter = %#v, want %#v", collection.findFilters[0], want)
+	}
+
+	if _, err := store.ListProcessesByParticipant(t.Context(), "workflow", "appwrite:user-1"); err != nil {
+		t.Fatalf("ListProcessesByParticipant(default) returned error: %v", err)
+	}
+	want := bson.M{
+		"participants": "appwrite:user-1",
+		"$or":          []bson.M{{"workflowKey": "workflow"}, {"workflowKey": bson.M{"$exists": false}}},
+		"deletedAt":    bson.M{"$exists": false},
+	}
+	if !reflect.DeepEqual(collection.findFilters[1], want) {
+		t.Fatalf("find filter = %#v, want %#v", collection.findFilters[1], want)
+	}
+
+	findErr := errors.New("find failed")
+	collection.findFn = func(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (mongoCursorPort, error) {
+		return nil, findErr
+	}
+	if _, err := store.ListProcessesByParticipant(t.Context(), "wf-a", "appwrite:user-1"); !errors.Is(err, findErr) {
+		t.Fatalf("ListProcessesByParticipant error = %v, want %v", err, findErr)
+	}
+}
+
+func TestMongoStoreBackfillProcessParticipants(t *testing.T) {
+	id := primitive.NewObjectID()
+	cursor := &fakeCursor{docs: []Process{{
+		ID: id,
+		Progress: map[string]ProcessStep{
+			"1_1": {State: "done", DoneBy: &Actor{ID: "appwrite:b"}},
+			"1_2": {State: "done", DoneBy: &Actor{ID: "appwrite:a"}},
+			"1_3": {State: "done", DoneBy: &Actor{ID: "appwrite:b"}},
+		},
+	}}}
+	collection := &fakeMongoCollection{
+		findFn: func(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (mongoCursorPort, error) {
+			return cursor, nil
+		},
+	}
+	db := &fakeMongoDatabase{collections: map[string]*fakeMongoCollection{"processes": collection}}
+	store := &MongoStore{dbPort: db}
+
+	if err := store.BackfillProcessParticipants(t.Context()); err != nil {
+		t.Fatalf("BackfillProcessParticipants returned error: %v", err)
+	}
+	if len(collection.createIndexesModels) != 1 {
+		t.Fatalf("expected participants index, got %#v", collection.createIndexesModels)
+	}
+	if !reflect.DeepEqual(collection.findFilters[0], bson.M{"participants": bson.M{"$exists": false}}) {
+		t.Fatalf("find filter = %#v", collection.findFilters[0])
+	}
+	wantUpdate := bson.M{"$set": bson.M{"participants": []string{"appwrite:a", "appwrite:b"}}}
+	if len(collection.updateOneUpdates) != 1 || !reflect.DeepEqual(collection.updateOneUpdates[0], wantUpdate) {
+		t.Fatalf("updates = %#v, want %#v", collection.updateOneUpdates, wantUpdate)
+	}
+
+	indexErr := errors.New("index failed")
+	collection.createIndexesFn = func(ctx context.Context, models []mongo.IndexModel) error { return indexErr }
+	if err := store.BackfillProcessParticipants(t.Context()); !errors.Is(err, indexErr) {
+		t.Fatalf("BackfillProcessParticipants error = %v, want %v", err, indexErr)
+	}
+}
+
+func TestMongoStoreBackfillsRunOnce(t *testing.T) {
+	processes := &fakeMongoCollection{
+		findFn: func(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (mongoCursorPort, error) {
+			return &fakeCursor{}, nil
+		},
+	}
+	migrations := &fakeMongoCollection{}
+	db := &fakeMongoDatabase{collections: map[string]*fakeMongoCollection{"processes": processes, "migrations": migrations}}
+	store := &MongoStore{dbPort: db}
+
+	if err := store.BackfillProcessParticipants(t.Context()); err != nil {
+		t.Fatalf("BackfillProcessParticipants: %v", err)
+	}
+	if err := store.BackfillProcessSearchText(t.Context()); err != nil {
+		t.Fatalf("BackfillProcessSearchText: %v", err)
+	}
+	if len(processes.findFilters) != 2 {
+		t.Fatalf("first boot scans = %d, want 2", len(processes.findFilters))
+	}
+	wantMarkers := []interface{}{bson.M{"_id": "process_participants"}, bson.M{"_id": "process_search_text"}}
+	if !reflect.DeepEqual(migrations.updateOneFilters, wantMarkers) {
+		t.Fatalf("migration markers = %#v, want %#v", migrations.updateOneFilters, wantMarkers)
+	}
+
+	migrations.findOneFn = func(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) mongoSingleResultPort {
+		return fakeSingleResult{}
+	}
+	if err := store.BackfillProcessParticipants(t.Context()); err != nil {
+		t.Fatalf("BackfillProcessParticipants again: %v", err)
+	}
+	if err := store.BackfillProcessSearchText(t.Context()); err != nil {
+		t.Fatalf("BackfillProcessSearchText again: %v", err)
+	}
+	if len(processes.findFilters) != 2 || len(processes.createIndexesModels) != 2 {
+		t.Fatalf("later boot scans = %d, index calls = %d; want no scan and the index kept", len(processes.findFilters), len(processes.createIndexesModels))
+	}
+
+	cursorErr := errors.New("cursor interrupted")
+	migrations.findOneFn = nil
+	processes.findFn = func(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (mongoCursorPort, error) {
+		return &fakeCursor{err: cursorErr}, nil
+	}
+	if err := store.BackfillProcessSearchText(t.Context()); !errors.Is(err, cursorErr) {
+		t.Fatalf("BackfillProcessSearchText error = %v, want %v", err, cursorErr)
+	}
+	if len(migrations.updateOneFilters) != 2 {
+		t.Fatal("a failed backfill must not be marked done")
+	}
+}
+
 func TestMongoStoreUpdateProcessStatusAndInsertNotarization(t *testing.T) {
 	processes := &fakeMongoCollection{}
 	notarizations := &fakeMongoCollection{}
@@ -280,7 +428,10 @@ func TestMongoStoreUpdateProcessStatusAndInsertNotarization(t *testing.T) {
 	if len(processes.updateOneFilters) != 1 || len(processes.updateOneUpdates) != 1 {
 		t.Fatalf("expected one UpdateOne call, got filters=%d updates=%d", len(processes.updateOneFilters), len(processes.updateOneUpdates))
 	}
-	expectedStatusUpdate := bson.M{"$set": bson.M{"status": "done", "workflowKey": "wf-a"}}
+	expectedStatusUpdate := bson.M{
+		"$set":         bson.M{"status": "done", "workflowKey": "wf-a"},
+		"$currentDate": bson.M{"updatedAt": true},
+	}
 	if !reflect.DeepEqual(processes.updateOneUpdates[0], expectedStatusUpdate) {
 		t.Fatalf("status update = %#v, want %#v", processes.updateOneUpdates[0], expectedStatusUpdate)
 	}
@@ -336,6 +487,7 @@ func TestMongoStoreUpdateProcessTermination(t *testing.T) {
 			"workflowKey": "wf-a",
 			"termination": termination,
 		},
+		"$currentDate": bson.M{"updatedAt": true},
 	}
 	if !reflect.DeepEqual(processes.updateOneUpdates[0], expected) {
 		t.Fatalf("termination update = %#v, want %#v", processes.updateOneUpdates[0], expected)
@@ -377,8 +529,99 @@ func TestMongoStoreUpdateProcessDPP(t *testing.T) {
 			"workflowKey": "wf-a",
 			"dpp":         dpp,
 		},
+		"$currentDate": bson.M{"updatedAt": true},
 	}
 	if !reflect.DeepEqual(processes.updateOneUpdates[0], expectedUpdate) {
 		t.Fatalf("update = %#v, want %#v", processes.updateOneUpdates[0], expectedUpdate)
 	}
 }
+
+func TestMongoStoreUpdateProcessMetadataRewritesSearchText(t *testing.T) {
+	processes := &fakeMongoCollection{}
+	processes.findOneAndUpdateFn = func(ctx context.Context, filter interface{}, update interface{}, opts ...*options.FindOneAndUpdateOptions) mongoSingleResultPort {
+		return fakeSingleResult{decodeFn: func(v interface{}) error {
+			*v.(*Process) = Process{
+				Progress: map[string]ProcessStep{"1_1": {State: "done", Data: map[string]interface{}{"value": "lot-42"}}},
+				Metadata: map[string]string{"ticket": "T-2"},
+			}
+			return nil
+		}}
+	}
+	store := &MongoStore{dbPort: &fakeMongoDatabase{collections: map[string]*fakeMongoCollection{"processes": processes}}}
+	id := primitive.NewObjectID()
+
+	if err := store.UpdateProcessMetadata(t.Context(), id, map[string]string{"ticket": "T-2"}, []string{"customer"}); err != nil {
+		t.Fatalf("UpdateProcessMetadata returned error: %v", err)
+	}
+	expectedUpdate := bson.M{
+		"$set":   bson.M{"metadata.ticket": "T-2"},
+		"$unset": bson.M{"metadata.customer": ""},
+	}
+	if len(processes.findOneAndUpdUpdate) != 1 || !reflect.DeepEqual(processes.findOneAndUpdUpdate[0], expectedUpdate) {
+		t.Fatalf("metadata update = %#v, want %#v", processes.findOneAndUpdUpdate, expectedUpdate)
+	}
+	expectedSearch := bson.M{"$set": bson.M{"searchText": []string{"lot-42", "T-2"}}}
+	if len(processes.updateOneUpdates) != 1 || !reflect.DeepEqual(processes.updateOneUpdates[0], expectedSearch) {
+		t.Fatalf("searchText update = %#v, want %#v", processes.updateOneUpdates, expectedSearch)
+	}
+}
+
+func TestMongoStoreAcquireLock(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	collection := &fakeMongoCollection{}
+	db := &fakeMongoDatabase{collections: map[string]*fakeMongoCollection{"locks": collection}}
+	store := &MongoStore{dbPort: db}
+
+	collection.findOneAndUpdateFn = func(ctx context.Context, filter interface{}, update interface{}, opts ...*options.FindOneAndUpdateOptions) mongoSingleResultPort {
+		return fakeSingleResult{err: mongo.ErrNoDocuments}
+	}
+	if ok, err := store.AcquireLock(t.Context(), "sweeper", "a", now, time.Hour); err != nil || !ok {
+		t.Fatalf("AcquireLock(new) = %v, %v; want true", ok, err)
+	}
+	wantFilter := bson.M{"_id": "sweeper", "$or": []bson.M{{"owner": "a"}, {"expiresAt": bson.M{"$lte": now}}}}
+	if !reflect.DeepEqual(collection.findOneAndUpdFilter[0], wantFilter) {
+		t.Fatalf("filter = %#v, want %#v", collection.findOneAndUpdFilter[0], wantFilter)
+	}
+	wantUpdate := bson.M{"$set": bson.M{"owner": "a", "expiresAt": now.Add(time.Hour)}}
+	if !reflect.DeepEqual(collection.findOneAndUpdUpdate[0], wantUpdate) {
+		t.Fatalf("update = %#v, want %#v", collection.findOneAndUpdUpdate[0], wantUpdate)
+	}
+
+	collection.findOneAndUpdateFn = func(ctx context.Context, filter interface{}, update interface{}, opts ...*options.FindOneAndUpdateOptions) mongoSingleResultPort {
+		return fakeSingleResult{err: mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000}}}}
+	}
+	if ok, err := store.AcquireLock(t.Context(), "sweeper", "b", now, time.Hour); err != nil || ok {
+		t.Fatalf("AcquireLock(held) = %v, %v; want false", ok, err)
+	}
+
+	lockErr := errors.New("lock failed")
+	collection.findOneAndUpdateFn = func(ctx context.Context, filter interface{}, update interface{}, opts ...*options.FindOneAndUpdateOptions) mongoSingleResultPort {
+		return fakeSingleResult{err: lockErr}
+	}
+	if _, err := store.AcquireLock(t.Context(), "sweeper", "b", now, time.Hour); !errors.Is(err, lockErr) {
+		t.Fatalf("AcquireLock error = %v, want %v", err, lockErr)
+	}
+}
+
+func TestMongoStoreClaimUserRolesVersion(t *testing.T) {
+	collection := &fakeMongoCollection{}
+	db := &fakeMongoDatabase{collections: map[string]*fakeMongoCollection{"counters": collection}}
+	store := &MongoStore{dbPort: db}
+
+	if err := store.ClaimUserRolesVersion(t.Context(), "user-2", 4); err != nil {
+		t.Fatalf("ClaimUserRolesVersion: %v", err)
+	}
+	if want := (bson.M{"_id": "roles:user-2", "seq": int64(4)}); !reflect.DeepEqual(collection.updateOneFilters[0], want) {
+		t.Fatalf("filter = %#v, want %#v", collection.updateOneFilters[0], want)
+	}
+	if want := (bson.M{"$inc": bson.M{"seq": int64(1)}}); !reflect.DeepEqual(collection.updateOneUpdates[0], want) {
+		t.Fatalf("update = %#v, want %#v", collection.updateOneUpdates[0], want)
+	}
+
+	collection.updateOneFn = func(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+		return nil, mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000}}}
+	}
+	if err := store.ClaimUserRolesVersion(t.Context(), "user-2", 4); !errors.Is(err, ErrRolesVersionConflict) {
+		t.Fatalf("ClaimUserRolesVersion(taken) = %v, want %v", err, ErrRolesVersionConflict)
+	}
+}
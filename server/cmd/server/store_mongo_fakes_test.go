@@ -166,6 +166,7 @@ func (r fakeSingleResult) Err() error {
 type fakeCursor struct {
 	docs        []Process
 	decodeErrAt map[int]error
+	err         error
 	index       int
 	closed      bool
 	closeErr    error
@@ -189,6 +190,10 @@ func (c *fakeCursor) Decode(val interface{}) error {
 	return nil
 }
 
+func (c *fakeCursor) Err() error {
+	return c.err
+}
+
 func (c *fakeCursor) Close(ctx context.Context) error {
 	c.closed = true
 	return c.closeErr
@@ -197,6 +202,7 @@ func (c *fakeCursor) Close(ctx context.Context) error {
 type fakeAnyCursor struct {
 	items       []interface{}
 	decodeErrAt map[int]error
+	err         error
 	index       int
 	closed      bool
 	closeErr    error
@@ -248,6 +254,10 @@ func (c *fakeAnyCursor) Decode(val interface{}) error {
 	return errors.New("unsupported decode target")
 }
 
+func (c *fakeAnyCursor) Err() error {
+	return c.err
+}
+
 func (c *fakeAnyCursor) Close(ctx context.Context) error {
 	c.closed = true
 	return c.closeErr
@@ -297,7 +297,7 @@ func TestMongoStoreHasProcessesByWorkflow(t *testing.T) {
 		if !hasProcesses {
 			t.Fatal("expected workflow to have processes")
 		}
-		if len(collection.findOneFilters) != 1 || !reflect.DeepEqual(collection.findOneFilters[0], bson.M{"workflowKey": "workflow-1"}) {
+		if len(collection.findOneFilters) != 1 || !reflect.DeepEqual(collection.findOneFilters[0], bson.M{"workflowKey": "workflow-1", "deletedAt": bson.M{"$exists": false}}) {
 			t.Fatalf("findOne filter = %#v, want workflow filter", collection.findOneFilters)
 		}
 	})
@@ -93,6 +93,9 @@ func TestMongoDriverAdaptersExecuteWrapperMethods(t *testing.T) {
 	runAndRecover(func() {
 		_ = (mongoDriverCursor{}).Decode(&Process{})
 	})
+	runAndRecover(func() {
+		_ = (mongoDriverCursor{}).Err()
+	})
 	runAndRecover(func() {
 		_ = (mongoDriverCursor{}).Close(context.Background())
 	})
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MongoRetryConfig bounds how MongoStore retries transient errors. A zero
+// MaxRetries disables retrying.
+type MongoRetryConfig struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+func mongoRetryConfigFromEnv() MongoRetryConfig {
+	return MongoRetryConfig{
+		MaxRetries: max(intEnvOr("MONGO_RETRY_MAX", 3), 0),
+		BaseDelay:  time.Duration(intEnvOr("MONGO_RETRY_BASE_MS", 100)) * time.Millisecond,
+		MaxDelay:   time.Duration(intEnvOr("MONGO_RETRY_MAX_DELAY_MS", 2000)) * time.Millisecond,
+	}
+}
+
+// Server error codes of failovers and dropped connections, as listed by the
+// MongoDB retryable writes specification.
+var retryableMongoErrorCodes = []int{
+	6,     // HostUnreachable
+	7,     // HostNotFound
+	89,    // NetworkTimeout
+	91,    // ShutdownInProgress
+	189,   // PrimarySteppedDown
+	262,   // ExceededTimeLimit
+	9001,  // SocketException
+	10107, // NotWritablePrimary
+	11600, // InterruptedAtShutdown
+	11602, // InterruptedDueToReplStateChange
+	13435, // NotPrimaryNoSecondaryOk
+	13436, // NotPrimaryOrSecondary
+}
+
+// isRetryableMongoError reports whether err is a transient failure worth
+// another attempt. Missing documents, duplicate keys, validation failures and
+// cancelled contexts never are.
+func isRetryableMongoError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, mongo.ErrNoDocuments) || mongo.IsDuplicateKeyError(err) {
+		return false
+	}
+	if mongo.IsNetworkError(err) {
+		return true
+	}
+	var serverErr mongo.ServerError
+	if !errors.As(err, &serverErr) {
+		return false
+	}
+	if serverErr.HasErrorLabel("RetryableWriteError") || serverErr.HasErrorLabel("TransientTransactionError") {
+		return true
+	}
+	for _, code := range retryableMongoErrorCodes {
+		if serverErr.HasErrorCode(code) {
+			return true
+		}
+	}
+	return false
+}
+
+// mongoRetryDelay is a full-jitter exponential backoff: a random wait up to
+// BaseDelay*2^attempt, capped at MaxDelay.
+func mongoRetryDelay(cfg MongoRetryConfig, attempt int) time.Duration {
+	ceiling := cfg.BaseDelay << min(attempt, 30)
+	if ceiling <= 0 || (cfg.MaxDelay > 0 && ceiling > cfg.MaxDelay) {
+		ceiling = cfg.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling + 1)
+}
+
+// withRetry runs op, repeating it on transient errors as configured. Only
+// idempotent operations may be wrapped. Inside a transaction op runs once:
+// the driver's session.WithTransaction retries the whole transaction.
+func (s *MongoStore) withRetry(ctx context.Context, op func() error) error {
+	if mongo.SessionFromContext(ctx) != nil {
+		return op()
+	}
+	for attempt := 0; ; attempt++ {
+		err := op()
+		if err == nil || attempt >= s.retry.MaxRetries || !isRetryableMongoError(err) {
+			return err
+		}
+		timer := time.NewTimer(mongoRetryDelay(s.retry, attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	errMongoStepDown = mongo.CommandError{Code: 189, Name: "PrimarySteppedDown"}
+	errMongoNetwork  = mongo.CommandError{Message: "connection reset", Labels: []string{"NetworkError"}}
+	errMongoDupKey   = mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 11000, Message: "duplicate key"}}}
+)
+
+func TestIsRetryableMongoError(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"step down", errMongoStepDown, true},
+		{"network", errMongoNetwork, true},
+		{"retryable write label", mongo.CommandError{Code: 1, Labels: []string{"RetryableWriteError"}}, true},
+		{"duplicate key", errMongoDupKey, false},
+		{"validation", mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 121, Message: "Document failed validation"}}}, false},
+		{"no documents", mongo.ErrNoDocuments, false},
+		{"context", context.DeadlineExceeded, false},
+		{"plain", errors.New("boom"), false},
+	}
+	for _, tc := range cases {
+		if got := isRetryableMongoError(tc.err); got != tc.want {
+			t.Errorf("%s: isRetryableMongoError = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestMongoRetryDelayIsCapped(t *testing.T) {
+	cfg := MongoRetryConfig{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	for attempt := 0; attempt < 40; attempt++ {
+		if delay := mongoRetryDelay(cfg, attempt); delay < 0 || delay > cfg.MaxDelay {
+			t.Fatalf("attempt %d delay = %s, want within [0, %s]", attempt, delay, cfg.MaxDelay)
+		}
+	}
+	if delay := mongoRetryDelay(MongoRetryConfig{}, 3); delay != 0 {
+		t.Fatalf("zero config delay = %s, want 0", delay)
+	}
+}
+
+func TestMongoRetryConfigFromEnv(t *testing.T) {
+	t.Setenv("MONGO_RETRY_MAX", "5")
+	t.Setenv("MONGO_RETRY_BASE_MS", "20")
+	t.Setenv("MONGO_RETRY_MAX_DELAY_MS", "500")
+	want := MongoRetryConfig{MaxRetries: 5, BaseDelay: 20 * time.Millisecond, MaxDelay: 500 * time.Millisecond}
+	if got := mongoRetryConfigFromEnv(); got != want {
+		t.Fatalf("config = %#v, want %#v", got, want)
+	}
+	t.Setenv("MONGO_RETRY_MAX", "-1")
+	if got := mongoRetryConfigFromEnv(); got.MaxRetries != 0 {
+		t.Fatalf("negative MONGO_RETRY_MAX = %d, want 0", got.MaxRetries)
+	}
+}
+
+func TestMongoStoreRetriesTransientWrites(t *testing.T) {
+	calls := 0
+	processes := &fakeMongoCollection{findOneAndUpdateFn: func(context.Context, interface{}, interface{}, ...*options.FindOneAndUpdateOptions) mongoSingleResultPort {
+		calls++
+		if calls < 3 {
+			return fakeSingleResult{err: errMongoStepDown}
+		}
+		return fakeSingleResult{}
+	}}
+	store := &MongoStore{
+		dbPort: &fakeMongoDatabase{collections: map[string]*fakeMongoCollection{"processes": processes}},
+		retry:  MongoRetryConfig{MaxRetries: 3},
+	}
+	if err := store.UpdateProcessProgress(t.Context(), primitive.NewObjectID(), "workflow", "1.1", ProcessStep{State: "done"}); err != nil {
+		t.Fatalf("UpdateProcessProgress: %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("attempts = %d, want 3", calls)
+	}
+
+	calls = 0
+	store.retry.MaxRetries = 1
+	if err := store.UpdateProcessProgress(t.Context(), primitive.NewObjectID(), "workflow", "1.1", ProcessStep{State: "done"}); !isRetryableMongoError(err) {
+		t.Fatalf("expected step-down error once retries run out, got %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("attempts = %d, want 2", calls)
+	}
+}
+
+func TestMongoStoreDoesNotRetryPermanentErrors(t *testing.T) {
+	calls := 0
+	processes := &fakeMongoCollection{findOneFn: func(context.Context, interface{}, ...*options.FindOneOptions) mongoSingleResultPort {
+		calls++
+		return fakeSingleResult{err: mongo.ErrNoDocuments}
+	}}
+	store := &MongoStore{
+		dbPort: &fakeMongoDatabase{collections: map[string]*fakeMongoCollection{"processes": processes}},
+		retry:  MongoRetryConfig{MaxRetries: 3},
+	}
+	if _, err := store.LoadProcessByID(t.Context(), primitive.NewObjectID()); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Fatalf("expected ErrNoDocuments, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("attempts = %d, want 1", calls)
+	}
+
+	calls = 0
+	notarizations := &fakeMongoCollection{insertOneFn: func(context.Context, interface{}, ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
+		calls++
+		return nil, errMongoDupKey
+	}}
+	store.dbPort = &fakeMongoDatabase{collections: map[string]*fakeMongoCollection{"notarizations": notarizations}}
+	if err := store.InsertNotarization(t.Context(), Notarization{ProcessID: primitive.NewObjectID()}); !mongo.IsDuplicateKeyError(err) {
+		t.Fatalf("expected duplicate key error, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("attempts = %d, want 1", calls)
+	}
+}
+
+func TestMongoStoreInsertNotarizationRetryIsIdempotent(t *testing.T) {
+	var ids []primitive.ObjectID
+	notarizations := &fakeMongoCollection{insertOneFn: func(_ context.Context, document interface{}, _ ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
+		ids = append(ids, document.(Notarization).ID)
+		if len(ids) == 1 {
+			// The first write landed but its reply was lost.
+			return nil, errMongoNetwork
+		}
+		return nil, errMongoDupKey
+	}}
+	store := &MongoStore{
+		dbPort: &fakeMongoDatabase{collections: map[string]*fakeMongoCollection{"notarizations": notarizations}},
+		retry:  MongoRetryConfig{MaxRetries: 2},
+	}
+	if err := store.InsertNotarization(t.Context(), Notarization{ProcessID: primitive.NewObjectID()}); err != nil {
+		t.Fatalf("InsertNotarization: %v", err)
+	}
+	if len(ids) != 2 || ids[0].IsZero() || ids[0] != ids[1] {
+		t.Fatalf("inserted ids = %v, want the same fixed id twice", ids)
+	}
+}
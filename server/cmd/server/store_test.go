@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"io"
 	"net/http"
@@ -66,6 +67,9 @@ func TestHandleStartProcessWithMemoryStore(t *testing.T) {
 	if len(processes[0].Progress) != 7 {
 		t.Fatalf("expected 7 configured substeps in progress map, got %d", len(processes[0].Progress))
 	}
+	if processes[0].CreatedBy != legacyProcessCreator {
+		t.Fatalf("expected legacy creator %q without enforced auth, got %q", legacyProcessCreator, processes[0].CreatedBy)
+	}
 	if !processes[0].CreatedAt.Equal(fixedNow) {
 		t.Fatalf("expected deterministic createdAt %s, got %s", fixedNow, processes[0].CreatedAt)
 	}
@@ -260,11 +264,17 @@ func TestMemoryStoreWorkflowQueriesAndMissingProcessErrors(t *testing.T) {
 		t.Fatalf("UpdateProcessStatus missing err = %v, want %v", err, mongo.ErrNoDocuments)
 	}
 
+	if err := store.UpdateProcessProgress(t.Context(), id, "workflow", "1.1", ProcessStep{State: "done"}); err != nil {
+		t.Fatalf("UpdateProcessProgress existing err: %v", err)
+	}
 	if err := store.UpdateProcessStatus(t.Context(), id, "workflow", "done"); err != nil {
 		t.Fatalf("UpdateProcessStatus existing err: %v", err)
 	}
-	if err := store.UpdateProcessProgress(t.Context(), id, "workflow", "1.1", ProcessStep{State: "done"}); err != nil {
-		t.Fatalf("UpdateProcessProgress existing err: %v", err)
+	if snapshot, ok := store.SnapshotProcess(id); !ok || snapshot.UpdatedAt.IsZero() {
+		t.Fatalf("expected UpdateProcessStatus to set UpdatedAt, got %#v", snapshot)
+	}
+	if err := store.UpdateProcessProgress(t.Context(), id, "workflow", "1.2", ProcessStep{State: "done"}); !errors.Is(err, ErrIllegalStatusTransition) {
+		t.Fatalf("UpdateProcessProgress on done process err = %v, want %v", err, ErrIllegalStatusTransition)
 	}
 	if _, err := store.LoadProcessByDigitalLink(t.Context(), "09506000134352", "lot-a", "serial-a"); !errors.Is(err, mongo.ErrNoDocuments) {
 		t.Fatalf("LoadProcessByDigitalLink missing err = %v, want %v", err, mongo.ErrNoDocuments)
@@ -354,3 +364,73 @@ func TestMemoryStoreDigitalLinkRoundTrip(t *testing.T) {
 		t.Fatalf("unexpected dpp data: %#v", process.DPP)
 	}
 }
+
+func TestMemoryStoreBackfillProcessParticipants(t *testing.T) {
+	store := NewMemoryStore()
+	id := store.SeedProcess(Process{
+		WorkflowKey: "workflow",
+		Progress: map[string]ProcessStep{
+			"1_1": {State: "done", DoneBy: &Actor{ID: "appwrite:b"}},
+			"1_2": {State: "done", DoneBy: &Actor{ID: "appwrite:a"}},
+			"1_3": {State: "pending"},
+		},
+	})
+	if err := store.BackfillProcessParticipants(t.Context()); err != nil {
+		t.Fatalf("BackfillProcessParticipants: %v", err)
+	}
+	processes, err := store.ListProcessesByParticipant(t.Context(), "workflow", "appwrite:a")
+	if err != nil || len(processes) != 1 || processes[0].ID != id {
+		t.Fatalf("ListProcessesByParticipant = %#v, %v", processes, err)
+	}
+	if got := processes[0].Participants; len(got) != 2 || got[0] != "appwrite:a" || got[1] != "appwrite:b" {
+		t.Fatalf("participants = %#v", got)
+	}
+}
+
+func TestMemoryStoreWithTransactionRollsBackOnError(t *testing.T) {
+	store := NewMemoryStore()
+	id := store.SeedProcess(Process{Status: "active", Progress: map[string]ProcessStep{"1_1": {State: "pending"}}})
+	write := func(ctx context.Context) error {
+		if err := store.UpdateProcessProgress(ctx, id, "workflow", "1.1", ProcessStep{State: "done"}); err != nil {
+			return err
+		}
+		return store.InsertNotarization(ctx, Notarization{ProcessID: id, SubstepID: "1.1"})
+	}
+
+	failed := errors.New("boom")
+	err := store.WithTransaction(t.Context(), func(ctx context.Context) error {
+		if err := write(ctx); err != nil {
+			return err
+		}
+		return failed
+	})
+	if !errors.Is(err, failed) || !errors.Is(err, ErrTransactionRolledBack) {
+		t.Fatalf("WithTransaction error = %v", err)
+	}
+	if snapshot, _ := store.SnapshotProcess(id); snapshot.Progress["1_1"].State != "pending" {
+		t.Fatalf("progress after rollback = %#v", snapshot.Progress)
+	}
+	if notarizations, _ := store.ListNotarizations(t.Context(), id); len(notarizations) != 0 {
+		t.Fatalf("notarizations after rollback = %d", len(notarizations))
+	}
+
+	if err := store.WithTransaction(t.Context(), write); err != nil {
+		t.Fatalf("WithTransaction: %v", err)
+	}
+	if snapshot, _ := store.SnapshotProcess(id); snapshot.Progress["1_1"].State != "done" {
+		t.Fatalf("progress after commit = %#v", snapshot.Progress)
+	}
+}
+
+func TestMongoStoreWithTransactionWithoutClientRunsDirectly(t *testing.T) {
+	store := &MongoStore{dbPort: &fakeMongoDatabase{}}
+	calls := 0
+	failed := errors.New("boom")
+	err := store.WithTransaction(t.Context(), func(ctx context.Context) error {
+		calls++
+		return failed
+	})
+	if calls != 1 || !errors.Is(err, failed) || errors.Is(err, ErrTransactionRolledBack) {
+		t.Fatalf("calls = %d err = %v", calls, err)
+	}
+}
@@ -11,9 +11,10 @@ func TestStreamCardTemplateRendersCoreFields(t *testing.T) {
 
 	var out bytes.Buffer
 	card := StreamCardView{
-		Key:         "demo",
-		Name:        "Demo Stream",
-		Description: "Track a pilot batch end to end",
+		Key:             "demo",
+		Name:            "Demo Stream",
+		Description:     "Track a pilot batch end to end",
+		DescriptionHTML: renderMarkdown("Track a pilot batch **end to end**"),
 		Counts: WorkflowProcessCounts{
 			NotStarted: 2,
 			Started:    1,
@@ -41,7 +42,7 @@ func TestStreamCardTemplateRendersCoreFields(t *testing.T) {
 		`class="btn btn-ghost btn-icon stream-card-menu-trigger"`,
 		`href="/my/streams/demo/"`,
 		"Demo Stream",
-		"Track a pilot batch end to end",
+		"<p>Track a pilot batch <strong>end to end</strong></p>",
 		"<td>2</td>",
 		"<td>1</td>",
 		"<td>3</td>",
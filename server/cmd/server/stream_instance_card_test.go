@@ -85,3 +85,23 @@ func TestStreamInstanceCardTemplateRendersStatusIcon(t *testing.T) {
 		})
 	}
 }
+
+func TestStreamInstanceCardTemplateRendersCreatedBy(t *testing.T) {
+	tmpl := parseTestTemplates(t)
+
+	render := func(card StreamInstanceCard) string {
+		var out bytes.Buffer
+		if err := tmpl.ExecuteTemplate(&out, "stream_instance_card", card); err != nil {
+			t.Fatalf("render stream_instance_card template: %v", err)
+		}
+		return out.String()
+	}
+	card := StreamInstanceCard{ID: "process-1", Status: "active", CreatedAt: "1 Mar 2026 at 10:00 UTC", CreatedAtISO: "2026-03-01T10:00:00Z"}
+	if body := render(card); strings.Contains(body, "Started by") {
+		t.Fatalf("expected no creator without CreatedBy, got:\n%s", body)
+	}
+	card.CreatedBy = "ana@example.com"
+	if body := render(card); !strings.Contains(body, "Started by: ana@example.com") {
+		t.Fatalf("expected creator in card meta, got:\n%s", body)
+	}
+}
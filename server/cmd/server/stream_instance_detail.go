@@ -13,7 +13,11 @@ func (s *Server) buildStreamInstanceDetailView(ctx context.Context, cfg RuntimeC
 	timeline := decorateTimelineSelection(buildTimeline(cfg.Workflow, process, workflowKey, roleMeta, cfg.Roles, organizationNameMap(cfg)), selected)
 	timeline = decorateTimelineOrganizationLogos(timeline, organizationLogoURLMap(ctx, s.identity))
 	actions = s.applyDoneByEmailToSubstepViews(ctx, cfg.Workflow, actor, actions)
-	timeline = decorateTimelineSubstepBodies(timeline, actions)
+	actions = s.applyAssigneeNamesToSubstepViews(ctx, actions)
+	actions = s.applySubstepDrafts(ctx, process, actor, actions)
+	titles := substepTitlesForLocale(cfg.Labels, labelLocaleFromContext(ctx))
+	actions = localizeSubstepBodies(actions, titles)
+	timeline = decorateTimelineSubstepBodies(localizeTimeline(timeline, titles), actions)
 
 	view := StreamInstanceDetailView{
 		WorkflowKey:       workflowKey,
@@ -41,7 +45,7 @@ func (s *Server) buildStreamInstanceDetailView(ctx context.Context, cfg RuntimeC
 	}
 
 	if processDone {
-		view.Attachments = buildProcessDownloadAttachments(workflowKey, process, collectProcessAttachments(cfg.Workflow, process))
+		view.Attachments = buildProcessDownloadAttachments(workflowKey, process, s.withAttachmentSizes(ctx, collectProcessAttachments(cfg.Workflow, process)))
 		if process != nil && process.DPP != nil {
 			view.DPPURL = digitalLinkURL(process.DPP.GTIN, process.DPP.Lot, process.DPP.Serial)
 			view.DPPGS1 = gs1ElementString(process.DPP.GTIN, process.DPP.Lot, process.DPP.Serial)
@@ -80,6 +84,32 @@ func (s *Server) applyDoneByEmailToSubstepViews(ctx context.Context, def Workflo
 	return actions
 }
 
+// applyAssigneeNamesToSubstepViews rewrites the "Assigned to" reason with the
+// assignees' emails. An assignee the identity lookup cannot resolve keeps the
+// configured ID.
+func (s *Server) applyAssigneeNamesToSubstepViews(ctx context.Context, actions []SubstepBodyView) []SubstepBodyView {
+	cache := map[string]userIdentityView{}
+	for idx := range actions {
+		if len(actions[idx].Assignees) == 0 {
+			continue
+		}
+		names := make([]string, 0, len(actions[idx].Assignees))
+		for _, id := range actions[idx].Assignees {
+			actorID := id
+			if _, ok := parseAppwriteActorID(id); !ok {
+				actorID = appwriteActorID(id)
+			}
+			if identity, ok := s.lookupUserIdentityByActorID(ctx, actorID, cache); ok && strings.TrimSpace(identity.email) != "" {
+				names = append(names, identity.email)
+				continue
+			}
+			names = append(names, id)
+		}
+		actions[idx].Reason = assignedToReason(names)
+	}
+	return actions
+}
+
 func (s *Server) applyDoneByEmailToTermination(ctx context.Context, def WorkflowDef, viewer Actor, termination *ProcessTerminationView) *ProcessTerminationView {
 	if termination == nil {
 		return nil
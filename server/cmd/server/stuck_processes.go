@@ -0,0 +1,134 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const stuckProcessesDefaultDays = 7
+
+type StuckProcessesResponse struct {
+	WorkflowKey string             `json:"workflow_key"`
+	Days        int                `json:"days"`
+	Processes   []StuckProcessItem `json:"processes"`
+}
+
+// StuckProcessItem is an active process whose oldest available substep has
+// waited longer than the threshold. AgeDays is rounded down.
+type StuckProcessItem struct {
+	ProcessID   string   `json:"process_id"`
+	Code        string   `json:"code,omitempty"`
+	SubstepID   string   `json:"substep_id"`
+	Title       string   `json:"title"`
+	Role        string   `json:"role"`
+	Roles       []string `json:"roles"`
+	AvailableAt string   `json:"available_at"`
+	AgeSeconds  int64    `json:"age_seconds"`
+	AgeDays     int      `json:"age_days"`
+}
+
+// oldestAvailableSubstep returns the available substep of process that has
+// been waiting longest, with the time it became available.
+func oldestAvailableSubstep(def WorkflowDef, process *Process) (WorkflowSub, time.Time, bool) {
+	available := computeAvailability(def, process)
+	var oldest WorkflowSub
+	var oldestAt time.Time
+	found := false
+	for _, sub := range orderedSubsteps(def) {
+		if !available[sub.SubstepID] {
+			continue
+		}
+		availableAt := substepAvailableAt(def, process, sub.SubstepID)
+		if !found || availableAt.Before(oldestAt) {
+			oldest, oldestAt, found = sub, availableAt, true
+		}
+	}
+	return oldest, oldestAt, found
+}
+
+// buildStuckProcesses lists the active processes blocked for more than days,
+// longest waiting first.
+func buildStuckProcesses(workflowKey string, def WorkflowDef, processes []Process, days int, now time.Time) StuckProcessesResponse {
+	response := StuckProcessesResponse{WorkflowKey: workflowKey, Days: days, Processes: []StuckProcessItem{}}
+	threshold := time.Duration(days) * 24 * time.Hour
+	for idx := range processes {
+		process := &processes[idx]
+		if deriveProcessStatus(def, process) != processStatusActive {
+			continue
+		}
+		sub, availableAt, ok := oldestAvailableSubstep(def, process)
+		if !ok || availableAt.IsZero() {
+			continue
+		}
+		age := now.Sub(availableAt)
+		if age <= threshold {
+			continue
+		}
+		roles := substepRoles(sub)
+		role := strings.TrimSpace(sub.Role)
+		if role == "" && len(roles) > 0 {
+			role = roles[0]
+		}
+		response.Processes = append(response.Processes, StuckProcessItem{
+			ProcessID:   process.ID.Hex(),
+			Code:        strings.TrimSpace(process.Code),
+			SubstepID:   sub.SubstepID,
+			Title:       sub.Title,
+			Role:        role,
+			Roles:       roles,
+			AvailableAt: availableAt.UTC().Format(time.RFC3339),
+			AgeSeconds:  int64(age / time.Second),
+			AgeDays:     int(age / (24 * time.Hour)),
+		})
+	}
+	sort.SliceStable(response.Processes, func(i, j int) bool {
+		return response.Processes[i].AgeSeconds > response.Processes[j].AgeSeconds
+	})
+	return response
+}
+
+// handleStuckProcesses serves GET /my/streams/:key/stuck?days=N: active
+// processes whose next substep has been available for more than N days
+// (default 7).
+func (s *Server) handleStuckProcesses(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, _, ok := s.requireAuthenticatedPost(w, r)
+	if !ok {
+		return
+	}
+	workflowKey, cfg, err := s.selectedWorkflowUnvalidated(r)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if s.store == nil {
+		http.Error(w, "store not configured", http.StatusInternalServerError)
+		return
+	}
+	days := stuckProcessesDefaultDays
+	if raw := strings.TrimSpace(r.URL.Query().Get("days")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			http.Error(w, "days must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		days = parsed
+	}
+
+	processes, err := s.store.ListRecentProcessesByWorkflow(r.Context(), workflowKey, 0)
+	if err != nil {
+		logAndHTTPError(w, r, http.StatusInternalServerError, "failed to load processes", err, "failed to list processes for workflow %s stuck report", workflowKey)
+		return
+	}
+	processes = s.visibleProcesses(user, cfg, processes)
+	for idx := range processes {
+		processes[idx].Progress = normalizeProgressKeys(processes[idx].Progress)
+	}
+	writeJSON(w, buildStuckProcesses(workflowKey, cfg.Workflow, processes, days, s.nowUTC()))
+}
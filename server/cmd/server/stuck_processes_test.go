@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestHandleStuckProcesses(t *testing.T) {
+	now := time.Date(2026, 4, 20, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	oldDone := now.Add(-10 * 24 * time.Hour)
+	blocked := store.SeedProcess(Process{
+		ID:          primitive.NewObjectID(),
+		WorkflowKey: "workflow",
+		Code:        "PRC-2026-000001",
+		CreatedAt:   now.Add(-30 * 24 * time.Hour),
+		Status:      "active",
+		Progress:    map[string]ProcessStep{"1_1": {State: "done", DoneAt: &oldDone}},
+	})
+	older := store.SeedProcess(Process{ID: primitive.NewObjectID(), WorkflowKey: "workflow", CreatedAt: now.Add(-20 * 24 * time.Hour), Status: "active"})
+	store.SeedProcess(Process{ID: primitive.NewObjectID(), WorkflowKey: "workflow", CreatedAt: now.Add(-2 * 24 * time.Hour), Status: "active"})
+	store.SeedProcess(Process{
+		ID:          primitive.NewObjectID(),
+		WorkflowKey: "workflow",
+		CreatedAt:   now.Add(-40 * 24 * time.Hour),
+		Status:      "terminated",
+		Termination: &ProcessTermination{Reason: "cancelled", EndedAt: now.Add(-39 * 24 * time.Hour)},
+	})
+	server := &Server{store: store, now: func() time.Time { return now }}
+	stuck := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/stuck"+query, nil)
+		req = req.WithContext(context.WithValue(req.Context(), workflowContextKey{}, workflowContextValue{
+			Key: "workflow",
+			Cfg: testRuntimeConfig(),
+		}))
+		rec := httptest.NewRecorder()
+		server.handleStuckProcesses(rec, req)
+		return rec
+	}
+
+	rec := stuck("")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	var response StuckProcessesResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode stuck processes: %v", err)
+	}
+	if response.WorkflowKey != "workflow" || response.Days != stuckProcessesDefaultDays || len(response.Processes) != 2 {
+		t.Fatalf("unexpected response %#v", response)
+	}
+	first, second := response.Processes[0], response.Processes[1]
+	if first.ProcessID != older.Hex() || first.SubstepID != "1.1" || first.Role != "dep1" || first.AgeDays != 20 {
+		t.Fatalf("first stuck process = %#v", first)
+	}
+	if second.ProcessID != blocked.Hex() || second.Code != "PRC-2026-000001" || second.SubstepID != "1.2" ||
+		second.AvailableAt != oldDone.Format(time.RFC3339) || second.AgeDays != 10 {
+		t.Fatalf("second stuck process = %#v", second)
+	}
+
+	rec = stuck("?days=15")
+	response = StuckProcessesResponse{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode stuck processes: %v", err)
+	}
+	if len(response.Processes) != 1 || response.Processes[0].ProcessID != older.Hex() {
+		t.Fatalf("days=15 processes = %#v", response.Processes)
+	}
+
+	if rec := stuck("?days=abc"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid days status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
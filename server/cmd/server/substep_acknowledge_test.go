@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNormalizeInputTypesAcknowledge(t *testing.T) {
+	workflow := WorkflowDef{Steps: []WorkflowStep{{StepID: "1", Substep: []WorkflowSub{
+		{SubstepID: "1.1", InputKey: "agreed", InputType: " Acknowledge "},
+	}}}}
+	if err := normalizeInputTypes(&workflow); err != nil {
+		t.Fatalf("normalizeInputTypes: %v", err)
+	}
+	if got := workflow.Steps[0].Substep[0].InputType; got != "acknowledge" {
+		t.Fatalf("inputType = %q, want acknowledge", got)
+	}
+
+	workflow.Steps[0].Substep[0].Schema = map[string]interface{}{"type": "object"}
+	if err := normalizeInputTypes(&workflow); err == nil || !strings.Contains(err.Error(), "schema is not allowed") {
+		t.Fatalf("expected schema rejection, got %v", err)
+	}
+}
+
+func TestAcknowledgePayloadIsStable(t *testing.T) {
+	if got := acknowledgePayload(WorkflowSub{InputKey: " agreed "}); !reflect.DeepEqual(got, map[string]interface{}{"agreed": true}) {
+		t.Fatalf("payload = %#v", got)
+	}
+	if got := acknowledgePayload(WorkflowSub{}); !reflect.DeepEqual(got, map[string]interface{}{"acknowledged": true}) {
+		t.Fatalf("payload without input key = %#v", got)
+	}
+}
+
+func TestHandleCompleteSubstepAcknowledge(t *testing.T) {
+	store := NewMemoryStore()
+	server, processID, _ := newServerForCompleteTests(t, store, fakeAuthorizer{})
+	cfg := testFormataRuntimeConfig()
+	sub := &cfg.Workflow.Steps[0].Substep[0]
+	sub.InputType = "acknowledge"
+	sub.InputKey = "agreed"
+	sub.Schema = nil
+	server.configProvider = func() (RuntimeConfig, error) { return cfg, nil }
+	post := func(action string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/process/"+processID+"/substep/1.1/"+action, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		if action == "amend" {
+			server.handleAmendSubstep(rec, req, processID, "1.1")
+		} else {
+			server.handleCompleteSubstep(rec, req, processID, "1.1")
+		}
+		return rec
+	}
+
+	if rec := post("complete", url.Values{"value": {`{"ignored":"yes"}`}}); rec.Code != http.StatusOK {
+		t.Fatalf("complete status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	id, _ := primitive.ObjectIDFromHex(processID)
+	process, _ := store.SnapshotProcess(id)
+	progress := normalizeProgressKeys(process.Progress)["1.1"]
+	want := map[string]interface{}{"agreed": true}
+	if progress.State != "done" || progress.DoneBy == nil || !reflect.DeepEqual(progress.Data, want) {
+		t.Fatalf("unexpected progress %#v", progress)
+	}
+	notarizations := store.Notarizations()
+	if len(notarizations) != 1 || notarizations[0].FakeNotary.Digest != digestPayload(want) {
+		t.Fatalf("unexpected notarizations %#v", notarizations)
+	}
+
+	if rec := post("amend", url.Values{"reason": {"typo"}}); rec.Code != http.StatusConflict {
+		t.Fatalf("amend status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+}
+
+func TestSubstepBodyTemplateAcknowledgeRendersConfirmButton(t *testing.T) {
+	tmpl := parseTestTemplates(t)
+	action := withSubstepBodyMode(SubstepBodyView{
+		WorkflowKey:   "workflow",
+		ProcessID:     "process-1",
+		SubstepID:     "1.1",
+		InputKey:      "agreed",
+		InputType:     "acknowledge",
+		Status:        "available",
+		MatchingRoles: []SubstepRoleOption{{Slug: "dep1", Label: "Dep 1"}},
+	})
+
+	var out bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&out, "substep_body", action); err != nil {
+		t.Fatalf("render substep_body template: %v", err)
+	}
+	body := out.String()
+	for _, marker := range []string{`substep-body-acknowledge`, `action="/my/streams/workflow/instance/process-1/substep/1.1/complete?substep=1.1"`, `name="activeRole"`, `Confirm`} {
+		if !strings.Contains(body, marker) {
+			t.Fatalf("expected %q in body: %s", marker, body)
+		}
+	}
+	if strings.Contains(body, "js-formata-host") {
+		t.Fatalf("acknowledge substep must not render a formata form: %s", body)
+	}
+}
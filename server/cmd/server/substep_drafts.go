@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// substepDraftMaxBytes caps a draft save. Drafts carry form answers only;
+// files are uploaded on completion.
+const substepDraftMaxBytes = 256 << 10
+
+// draftValue drops what a draft cannot restore: file fields serialized as
+// data URLs and the empty objects browsers give for unset files. ok is false
+// when nothing is left.
+func draftValue(value interface{}) (interface{}, bool) {
+	switch typed := value.(type) {
+	case string:
+		if strings.HasPrefix(strings.TrimSpace(typed), "data:") {
+			return nil, false
+		}
+		return typed, true
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(typed))
+		for key, item := range typed {
+			if kept, ok := draftValue(item); ok {
+				out[key] = kept
+			}
+		}
+		return out, len(out) > 0
+	case []interface{}:
+		out := make([]interface{}, 0, len(typed))
+		for _, item := range typed {
+			if kept, ok := draftValue(item); ok {
+				out = append(out, kept)
+			}
+		}
+		return out, len(out) > 0
+	case nil:
+		return nil, false
+	default:
+		return typed, true
+	}
+}
+
+// parseSubstepDraft decodes the posted `value` JSON object. Unlike a
+// completion it is not validated against the schema: a draft is expected
+// to be incomplete.
+func parseSubstepDraft(raw string) (map[string]interface{}, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return map[string]interface{}{}, nil
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &decoded); err != nil {
+		return nil, errInvalidForm
+	}
+	data, ok := draftValue(decoded)
+	if !ok {
+		return map[string]interface{}{}, nil
+	}
+	return data.(map[string]interface{}), nil
+}
+
+// schemaWithDraftDefaults returns a copy of schema where each property
+// answered in draft carries it as its `default`, which is how the formata
+// form is pre-filled. Nested objects are followed; other values are used
+// as they are.
+func schemaWithDraftDefaults(schema, draft map[string]interface{}) map[string]interface{} {
+	if schema == nil || len(draft) == 0 {
+		return schema
+	}
+	out := cloneInterfaceMap(schema)
+	properties, ok := out["properties"].(map[string]interface{})
+	if !ok {
+		return out
+	}
+	for key, value := range draft {
+		property, ok := properties[key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			if _, hasProperties := property["properties"].(map[string]interface{}); hasProperties {
+				properties[key] = schemaWithDraftDefaults(property, nested)
+				continue
+			}
+		}
+		property["default"] = value
+	}
+	return out
+}
+
+// applySubstepDrafts pre-fills the actionable formata forms of actions with
+// the viewer's drafts. Missing drafts and lookup failures leave the form
+// as it is.
+func (s *Server) applySubstepDrafts(ctx context.Context, process *Process, actor Actor, actions []SubstepBodyView) []SubstepBodyView {
+	if s.store == nil || process == nil || process.ID.IsZero() || strings.TrimSpace(actor.ID) == "" {
+		return actions
+	}
+	for idx := range actions {
+		if actions[idx].Mode != SubstepBodyModeActionable || actions[idx].FormSchema == "" {
+			continue
+		}
+		draft, err := s.store.LoadSubstepDraft(ctx, process.ID, actions[idx].SubstepID, actor.ID)
+		if err != nil {
+			if !errors.Is(err, mongo.ErrNoDocuments) {
+				log.Printf("failed to load draft of process %s substep %s: %v", process.ID.Hex(), actions[idx].SubstepID, err)
+			}
+			continue
+		}
+		var schema map[string]interface{}
+		if err := json.Unmarshal([]byte(actions[idx].FormSchema), &schema); err != nil {
+			continue
+		}
+		actions[idx].FormSchema = marshalJSONCompact(schemaWithDraftDefaults(schema, canonicalPayload(draft.Data)))
+		actions[idx].HasDraft = true
+	}
+	return actions
+}
+
+// handleSaveSubstepDraft serves POST .../instance/:id/substep/:sub/draft. It
+// stores the viewer's partial answer for a formata substep they could
+// complete and answers 204; nothing about the process changes.
+func (s *Server) handleSaveSubstepDraft(w http.ResponseWriter, r *http.Request, processID, substepID string) {
+	user, _, ok := s.requireAuthenticatedPost(w, r)
+	if !ok {
+		return
+	}
+	workflowKey, cfg, selected := s.selectedWorkflowOrRedirectHome(w, r)
+	if !selected {
+		return
+	}
+	actor := actorFromAccountUser(user, workflowKey)
+	actor.ID = accountActorID(user)
+	ctx := r.Context()
+	process, err := s.loadProcess(ctx, processID)
+	if err != nil || !s.processBelongsToWorkflow(process, workflowKey) {
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			logRequestError(r, err, "failed to load process %s for substep %s draft", processID, substepID)
+		}
+		http.Error(w, "process not found", http.StatusNotFound)
+		return
+	}
+	substep, _, err := findSubstep(cfg.Workflow, substepID)
+	if err != nil || isAcknowledgeSubstep(substep) || isSignatureSubstep(substep) || isMultiselectSubstep(substep) || isURLSubstep(substep) {
+		http.Error(w, "substep not found", http.StatusNotFound)
+		return
+	}
+	if !processAcceptsCompletions(process) {
+		http.Error(w, "stream is already ended", http.StatusConflict)
+		return
+	}
+	if progress, ok := process.Progress[substep.SubstepID]; ok && progress.State == "done" {
+		http.Error(w, "substep is already completed", http.StatusConflict)
+		return
+	}
+	allowedRoles := substepRoles(substep)
+	if (s.enforceAuth && len(intersectRoles(allowedRoles, actor.RoleSlugs)) == 0) || !substepAssignedTo(substep, actor.ID) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, substepDraftMaxBytes)
+	if err := r.ParseForm(); err != nil {
+		if isRequestTooLarge(err) {
+			http.Error(w, "draft too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+	data, err := parseSubstepDraft(r.FormValue("value"))
+	if err != nil {
+		http.Error(w, "invalid draft", http.StatusBadRequest)
+		return
+	}
+	if err := s.store.SaveSubstepDraft(ctx, SubstepDraft{
+		ProcessID: process.ID,
+		SubstepID: substep.SubstepID,
+		UserID:    actor.ID,
+		Data:      data,
+		UpdatedAt: s.nowUTC(),
+	}); err != nil {
+		logAndHTTPError(w, r, http.StatusInternalServerError, "failed to save draft", err, "failed to save draft of process %s substep %s", process.ID.Hex(), substep.SubstepID)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestParseSubstepDraftDropsFiles(t *testing.T) {
+	data, err := parseSubstepDraft(`{"name":"Lot 7","photo":"data:image/png;base64,AAAA","scan":{},"tags":["a",null]}`)
+	if err != nil {
+		t.Fatalf("parseSubstepDraft: %v", err)
+	}
+	if len(data) != 2 || data["name"] != "Lot 7" || len(data["tags"].([]interface{})) != 1 {
+		t.Fatalf("unexpected draft %#v", data)
+	}
+	if _, err := parseSubstepDraft(`[1]`); err == nil {
+		t.Fatal("expected a non-object draft to be rejected")
+	}
+}
+
+func TestSchemaWithDraftDefaults(t *testing.T) {
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"name": map[string]interface{}{"type": "string"},
+			"address": map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{"city": map[string]interface{}{"type": "string"}},
+			},
+		},
+	}
+	got := schemaWithDraftDefaults(schema, map[string]interface{}{
+		"name":    "Lot 7",
+		"address": map[string]interface{}{"city": "Turin"},
+		"unknown": true,
+	})
+	properties := got["properties"].(map[string]interface{})
+	if properties["name"].(map[string]interface{})["default"] != "Lot 7" {
+		t.Fatalf("expected name default, got %#v", properties["name"])
+	}
+	city := properties["address"].(map[string]interface{})["properties"].(map[string]interface{})["city"].(map[string]interface{})
+	if city["default"] != "Turin" {
+		t.Fatalf("expected nested default, got %#v", city)
+	}
+	if _, ok := properties["unknown"]; ok {
+		t.Fatal("draft keys outside the schema must not be added")
+	}
+	if _, ok := schema["properties"].(map[string]interface{})["name"].(map[string]interface{})["default"]; ok {
+		t.Fatal("input schema must not be modified")
+	}
+}
+
+func TestSubstepDraftSavePrefillAndClear(t *testing.T) {
+	store := NewMemoryStore()
+	server, processID, _ := newServerForCompleteTests(t, store, fakeAuthorizer{})
+	cfg := testFormataRuntimeConfig()
+	cfg.Workflow.Steps[0].Substep[0].Schema = map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"value": map[string]interface{}{"type": "string"}},
+	}
+	server.configProvider = func() (RuntimeConfig, error) { return cfg, nil }
+	id, _ := primitive.ObjectIDFromHex(processID)
+	post := func(action string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/instance/"+processID+"/substep/1.1/"+action, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		server.handleProcessRoutes(rec, req)
+		return rec
+	}
+
+	if rec := post("draft", url.Values{"value": {`{"value":"half"}`}}); rec.Code != http.StatusNoContent {
+		t.Fatalf("draft status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	draft, err := store.LoadSubstepDraft(context.Background(), id, "1.1", "legacy-user")
+	if err != nil || draft.Data["value"] != "half" {
+		t.Fatalf("LoadSubstepDraft = %#v, %v", draft, err)
+	}
+	process, _ := store.SnapshotProcess(id)
+	if progress := normalizeProgressKeys(process.Progress)["1.1"]; progress.State != "pending" || progress.Data != nil {
+		t.Fatalf("draft must not touch progress, got %#v", progress)
+	}
+	if _, err := store.LoadSubstepDraft(context.Background(), id, "1.1", "someone-else"); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Fatalf("drafts must be per user, got %v", err)
+	}
+
+	loaded, _ := server.loadProcess(context.Background(), processID)
+	detail := server.buildStreamInstanceDetailView(context.Background(), cfg, "workflow", loaded, Actor{ID: "legacy-user", RoleSlugs: []string{"dep1"}, WorkflowKey: "workflow"}, "1.1", "", false)
+	if detail.SelectedBody == nil || !detail.SelectedBody.HasDraft {
+		t.Fatalf("expected selected body to be pre-filled, got %#v", detail.SelectedBody)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal([]byte(detail.SelectedBody.FormSchema), &schema); err != nil {
+		t.Fatalf("decode form schema: %v", err)
+	}
+	if got := schema["properties"].(map[string]interface{})["value"].(map[string]interface{})["default"]; got != "half" {
+		t.Fatalf("form default = %v, want half", got)
+	}
+
+	if rec := post("complete", url.Values{"value": {`{"value":"done"}`}}); rec.Code != http.StatusOK {
+		t.Fatalf("complete status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	if _, err := store.LoadSubstepDraft(context.Background(), id, "1.1", "legacy-user"); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Fatalf("expected draft to be cleared on completion, got %v", err)
+	}
+	if rec := post("draft", url.Values{"value": {`{"value":"late"}`}}); rec.Code != http.StatusConflict {
+		t.Fatalf("draft after completion status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+}
+
+func TestSubstepDraftIndexIsUnique(t *testing.T) {
+	db := &fakeMongoDatabase{}
+	store := &MongoStore{dbPort: db}
+	if err := store.EnsureSubstepDraftIndex(context.Background()); err != nil {
+		t.Fatalf("EnsureSubstepDraftIndex: %v", err)
+	}
+	models := db.Collection("substep_drafts").(*fakeMongoCollection).createIndexesModels
+	if len(models) != 1 || len(models[0]) != 1 || models[0][0].Options == nil || models[0][0].Options.Unique == nil || !*models[0][0].Options.Unique {
+		t.Fatalf("index models = %#v, want one unique index", models)
+	}
+	keys, ok := models[0][0].Keys.(bson.D)
+	if !ok || len(keys) != 3 || keys[0].Key != "processId" || keys[1].Key != "substepId" || keys[2].Key != "userId" {
+		t.Fatalf("index keys = %#v, want processId, substepId, userId", models[0][0].Keys)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SubstepFieldHelp is one helpBySchema note, labelled with the schema
+// property title when it has one.
+type SubstepFieldHelp struct {
+	Field string
+	Label string
+	Note  string
+}
+
+// normalizeSubstepHelpConfig trims the help texts of a substep. helpBySchema
+// is only allowed on formata substeps and must name schema properties. The
+// texts are display-only: they never reach validation or digests.
+func normalizeSubstepHelpConfig(substep *WorkflowSub) error {
+	substep.Help = strings.TrimSpace(substep.Help)
+	substep.Placeholder = strings.TrimSpace(substep.Placeholder)
+	if len(substep.HelpBySchema) == 0 {
+		return nil
+	}
+	if isAcknowledgeSubstep(*substep) || isSignatureSubstep(*substep) || isMultiselectSubstep(*substep) || isURLSubstep(*substep) {
+		return errors.New("helpBySchema is only allowed when inputType=formata")
+	}
+	properties, _ := substep.Schema["properties"].(map[string]interface{})
+	help := make(map[string]string, len(substep.HelpBySchema))
+	for field, note := range substep.HelpBySchema {
+		field = strings.TrimSpace(field)
+		if _, ok := properties[field]; !ok {
+			return fmt.Errorf("helpBySchema field %q is not a schema property", field)
+		}
+		if note = strings.TrimSpace(note); note != "" {
+			help[field] = note
+		}
+	}
+	substep.HelpBySchema = help
+	return nil
+}
+
+// substepFieldHelp lists the helpBySchema notes of sub ordered by field.
+func substepFieldHelp(sub WorkflowSub) []SubstepFieldHelp {
+	if len(sub.HelpBySchema) == 0 {
+		return nil
+	}
+	properties, _ := sub.Schema["properties"].(map[string]interface{})
+	notes := make([]SubstepFieldHelp, 0, len(sub.HelpBySchema))
+	for field, note := range sub.HelpBySchema {
+		label := field
+		if property, ok := properties[field].(map[string]interface{}); ok {
+			if title, ok := property["title"].(string); ok && strings.TrimSpace(title) != "" {
+				label = strings.TrimSpace(title)
+			}
+		}
+		notes = append(notes, SubstepFieldHelp{Field: field, Label: label, Note: note})
+	}
+	sort.Slice(notes, func(i, j int) bool { return notes[i].Field < notes[j].Field })
+	return notes
+}
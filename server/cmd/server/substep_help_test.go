@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeSubstepHelpConfig(t *testing.T) {
+	sub := WorkflowSub{
+		InputType:    "formata",
+		Schema:       map[string]interface{}{"type": "object", "properties": map[string]interface{}{"batch": map[string]interface{}{"type": "string", "title": "Batch ID"}}},
+		Help:         "  Copy it from the label.  ",
+		Placeholder:  " B-2026-001 ",
+		HelpBySchema: map[string]string{" batch ": " Printed under the barcode. "},
+	}
+	if err := normalizeSubstepHelpConfig(&sub); err != nil {
+		t.Fatalf("normalizeSubstepHelpConfig: %v", err)
+	}
+	if sub.Help != "Copy it from the label." || sub.Placeholder != "B-2026-001" || sub.HelpBySchema["batch"] != "Printed under the barcode." {
+		t.Fatalf("unexpected normalized help %#v", sub)
+	}
+	if got := substepFieldHelp(sub); len(got) != 1 || got[0].Label != "Batch ID" || got[0].Field != "batch" {
+		t.Fatalf("substepFieldHelp = %#v", got)
+	}
+
+	unknown := WorkflowSub{InputType: "formata", Schema: sub.Schema, HelpBySchema: map[string]string{"lot": "x"}}
+	if err := normalizeSubstepHelpConfig(&unknown); err == nil || !strings.Contains(err.Error(), `"lot" is not a schema property`) {
+		t.Fatalf("expected unknown field error, got %v", err)
+	}
+	ack := WorkflowSub{InputType: "acknowledge", HelpBySchema: map[string]string{"batch": "x"}}
+	if err := normalizeSubstepHelpConfig(&ack); err == nil {
+		t.Fatal("expected helpBySchema to be refused on acknowledge substeps")
+	}
+}
+
+func TestSubstepHelpIsDisplayOnly(t *testing.T) {
+	cfg := testFormataRuntimeConfig()
+	sub := &cfg.Workflow.Steps[0].Substep[0]
+	sub.Help = "Weigh the pallet."
+	sub.Placeholder = "120"
+	views := buildSubstepViews(cfg.Workflow, nil, "workflow", Actor{ID: "appwrite:u-1", RoleSlugs: []string{"dep1"}}, false, nil, nil)
+	view := findSubstepView(t, views, "1.1")
+	if view.Help != "Weigh the pallet." || view.Placeholder != "120" || strings.Contains(view.FormSchema, "Weigh") {
+		t.Fatalf("unexpected help on view %#v", view)
+	}
+
+	var out bytes.Buffer
+	if err := parseTestTemplates(t).ExecuteTemplate(&out, "substep_body", view); err != nil {
+		t.Fatalf("render substep_body template: %v", err)
+	}
+	if body := out.String(); !strings.Contains(body, "Weigh the pallet.") || !strings.Contains(body, "Example: 120") {
+		t.Fatalf("expected help in body: %s", body)
+	}
+
+	view.Status = "done"
+	view = withSubstepBodyMode(view)
+	out.Reset()
+	if err := parseTestTemplates(t).ExecuteTemplate(&out, "substep_body", view); err != nil {
+		t.Fatalf("render done substep_body template: %v", err)
+	}
+	if strings.Contains(out.String(), "Weigh the pallet.") {
+		t.Fatalf("help must not be shown on completed substeps: %s", out.String())
+	}
+}
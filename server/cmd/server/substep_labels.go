@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+type labelLocaleContextKey struct{}
+
+func withLabelLocale(ctx context.Context, locale string) context.Context {
+	locale = normalizeLabelLocale(locale)
+	if locale == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, labelLocaleContextKey{}, locale)
+}
+
+func labelLocaleFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	locale, _ := ctx.Value(labelLocaleContextKey{}).(string)
+	return locale
+}
+
+func normalizeLabelLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// normalizeSubstepLabels lowercases locale keys, trims titles and rejects
+// translations for substeps the workflow does not define.
+func normalizeSubstepLabels(cfg *RuntimeConfig) error {
+	if cfg == nil || len(cfg.Labels) == 0 {
+		return nil
+	}
+	known := map[string]bool{}
+	for _, sub := range orderedSubsteps(cfg.Workflow) {
+		known[sub.SubstepID] = true
+	}
+	normalized := make(map[string]map[string]string, len(cfg.Labels))
+	for locale, titles := range cfg.Labels {
+		key := normalizeLabelLocale(locale)
+		if key == "" {
+			return fmt.Errorf("labels: locale is required")
+		}
+		if normalized[key] == nil {
+			normalized[key] = map[string]string{}
+		}
+		for substepID, title := range titles {
+			substepID = strings.TrimSpace(substepID)
+			if !known[substepID] {
+				return fmt.Errorf("labels.%s: unknown substep %q", key, substepID)
+			}
+			if title = strings.TrimSpace(title); title != "" {
+				normalized[key][substepID] = title
+			}
+		}
+	}
+	cfg.Labels = normalized
+	return nil
+}
+
+// substepTitlesForLocale returns the title overrides for locale, falling back
+// from a regional tag ("de-ch") to its base language ("de").
+func substepTitlesForLocale(labels map[string]map[string]string, locale string) map[string]string {
+	locale = normalizeLabelLocale(locale)
+	if locale == "" || len(labels) == 0 {
+		return nil
+	}
+	if titles, ok := labels[locale]; ok {
+		return titles
+	}
+	if base, _, found := strings.Cut(locale, "-"); found {
+		return labels[base]
+	}
+	return nil
+}
+
+func localizeSubstepBodies(actions []SubstepBodyView, titles map[string]string) []SubstepBodyView {
+	if len(titles) == 0 {
+		return actions
+	}
+	for idx := range actions {
+		if title, ok := titles[actions[idx].SubstepID]; ok {
+			actions[idx].Title = title
+		}
+	}
+	return actions
+}
+
+func localizeTimeline(timeline []TimelineStep, titles map[string]string) []TimelineStep {
+	if len(titles) == 0 {
+		return timeline
+	}
+	for stepIndex := range timeline {
+		for substepIndex := range timeline[stepIndex].Substeps {
+			entry := &timeline[stepIndex].Substeps[substepIndex]
+			title, ok := titles[entry.SubstepID]
+			if !ok {
+				continue
+			}
+			entry.Title = title
+			if entry.Body != nil {
+				body := *entry.Body
+				body.Title = title
+				entry.Body = &body
+			}
+		}
+	}
+	return timeline
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseRuntimeConfigDataSubstepLabels(t *testing.T) {
+	config := func(labels string) []byte {
+		return []byte(`
+workflow:
+  name: "Workflow"
+  steps:
+    - id: "1"
+      title: "Step 1"
+      order: 1
+      substeps:
+        - id: "1.1"
+          title: "First"
+          order: 1
+          roles: ["dep1"]
+          inputKey: "value"
+          inputType: "formata"
+          schema: {type: object}
+labels:
+` + labels)
+	}
+
+	cfg, err := parseRuntimeConfigData("labels.yaml", config("  DE_ch:\n    \" 1.1 \": \" Erste \"\n"))
+	if err != nil {
+		t.Fatalf("parseRuntimeConfigData(valid): %v", err)
+	}
+	if got := cfg.Labels["de-ch"]["1.1"]; got != "Erste" {
+		t.Fatalf("labels[de-ch][1.1] = %q, want Erste", got)
+	}
+
+	_, err = parseRuntimeConfigData("labels.yaml", config("  de:\n    \"9.9\": \"Nope\"\n"))
+	if err == nil || !strings.Contains(err.Error(), `unknown substep "9.9"`) {
+		t.Fatalf("expected unknown substep error, got %v", err)
+	}
+}
+
+func TestSubstepTitlesForLocale(t *testing.T) {
+	labels := map[string]map[string]string{
+		"de":    {"1.1": "Erste"},
+		"fr-ca": {"1.1": "Premier"},
+	}
+	for locale, want := range map[string]string{
+		"de":    "Erste",
+		"DE-at": "Erste",
+		"fr_CA": "Premier",
+		"fr":    "",
+		"":      "",
+	} {
+		if got := substepTitlesForLocale(labels, locale)["1.1"]; got != want {
+			t.Fatalf("substepTitlesForLocale(%q)[1.1] = %q, want %q", locale, got, want)
+		}
+	}
+}
+
+func TestBuildStreamInstanceDetailViewLocalizesTitles(t *testing.T) {
+	now := time.Date(2026, 2, 3, 9, 0, 0, 0, time.UTC)
+	process := &Process{
+		ID: primitive.NewObjectID(),
+		Progress: map[string]ProcessStep{
+			"1.1": {State: "done", DoneAt: ptrTime(now), DoneBy: &Actor{ID: "u1", Role: "dep1"}, Data: map[string]interface{}{"value": 1}},
+		},
+	}
+	cfg := testRuntimeConfig()
+	cfg.Labels = map[string]map[string]string{"de": {"1.1": "Erste", "1.2": "Zweite"}}
+	server := &Server{}
+
+	view := server.buildStreamInstanceDetailView(withLabelLocale(t.Context(), "de-DE"), cfg, "workflow", process, Actor{Role: "dep1", RoleSlugs: []string{"dep1"}}, "1.2", "", false)
+	substeps := view.Timeline[0].Substeps
+	if substeps[0].Title != "Erste" || substeps[0].Body == nil || substeps[0].Body.Title != "Erste" {
+		t.Fatalf("expected localized 1.1 title, got %#v", substeps[0])
+	}
+	if substeps[2].Title != "C" {
+		t.Fatalf("expected fallback title for 1.3, got %q", substeps[2].Title)
+	}
+	if view.SelectedBody == nil || view.SelectedBody.Title != "Zweite" {
+		t.Fatalf("expected localized selected body, got %#v", view.SelectedBody)
+	}
+
+	defaultView := server.buildStreamInstanceDetailView(t.Context(), cfg, "workflow", process, Actor{Role: "dep1", RoleSlugs: []string{"dep1"}}, "", "", false)
+	if got := defaultView.Timeline[0].Substeps[0].Title; got != "A" {
+		t.Fatalf("expected canonical title without locale, got %q", got)
+	}
+
+	export := buildNotarizedExport(cfg.Workflow, process)
+	if got := export.Steps[0].Substeps[0].Title; got != "A" {
+		t.Fatalf("notarized export title = %q, want canonical A", got)
+	}
+}
@@ -17,6 +17,7 @@ func buildSubstepViews(def WorkflowDef, process *Process, workflowKey string, ac
 	var actions []SubstepBodyView
 	ordered := orderedSubsteps(def)
 	availMap := computeAvailability(def, process)
+	hidden := hiddenSubsteps(def, process)
 	substepOrgs := substepOrganizationMap(def)
 	terminated := process != nil && process.Termination != nil
 	terminationSubstepID := ""
@@ -81,15 +82,19 @@ func buildSubstepViews(def WorkflowDef, process *Process, workflowKey string, ac
 				status = processStatusTerminated
 			} else if terminated && (pastTermination || terminationSubstepID == "") {
 				status = "skipped"
+			} else if hidden[sub.SubstepID] {
+				status = "skipped"
 			} else if availMap[sub.SubstepID] {
 				status = "available"
 			}
 		}
 		stepOrgSlug := substepOrgs[sub.SubstepID]
 		orgAuthorized := stepOrgSlug == "" || strings.TrimSpace(actor.OrgSlug) == stepOrgSlug
-		disabled := status != "available" || len(matchingRoles) == 0 || !orgAuthorized
+		assigned := substepAssignedTo(sub, actor.ID)
+		disabled := status != "available" || len(matchingRoles) == 0 || !orgAuthorized || !assigned
 		reason := ""
 		detailMessage := ""
+		var assignees []string
 		if status == "locked" {
 			reason = "Locked by sequence"
 		} else if status == "done" {
@@ -103,10 +108,17 @@ func buildSubstepViews(def WorkflowDef, process *Process, workflowKey string, ac
 		} else if status == "skipped" {
 			reason = "Stream ended early"
 			detailMessage = "Step not completed because the stream was ended before this."
+			if hidden[sub.SubstepID] {
+				reason = substepHiddenReason
+				detailMessage = substepHiddenDetail
+			}
 		} else if !orgAuthorized {
 			reason = "Not authorized for organization"
 		} else if len(matchingRoles) == 0 {
 			reason = "Not authorized"
+		} else if !assigned {
+			assignees = substepAssignees(sub)
+			reason = assignedToReason(assignees)
 		}
 		formSchema := ""
 		formUISchema := ""
@@ -115,6 +127,8 @@ func buildSubstepViews(def WorkflowDef, process *Process, workflowKey string, ac
 		doneBy := ""
 		doneRole := ""
 		description := strings.TrimSpace(sub.InputKey)
+		amendedAt := ""
+		amendedAtISO := ""
 		var values []SubstepKV
 		var attachments []SubstepAttachmentView
 		if status == "done" && process != nil {
@@ -142,13 +156,16 @@ func buildSubstepViews(def WorkflowDef, process *Process, workflowKey string, ac
 					}
 				}
 				if value, ok := processStepDataValue(progress, sub); ok {
-					values = flattenDisplayValues("", value)
+					values = substepDisplayValues(sub, value)
 				}
-				attachments = buildSubstepAttachments(workflowKey, process, progress.Data)
+				attachments = buildSubstepAttachments(workflowKey, process, currentStepData(progress))
+				amendedAt, amendedAtISO = amendedAtDisplay(progress)
 			}
 		}
-		formSchema = marshalJSONCompact(effective.Schema)
-		formUISchema = marshalJSONCompact(effective.UISchema)
+		if !isAcknowledgeSubstep(effective) && !isSignatureSubstep(effective) && !isMultiselectSubstep(effective) && !isURLSubstep(effective) {
+			formSchema = marshalJSONCompact(schemaWithNumberRange(effective))
+			formUISchema = marshalJSONCompact(effective.UISchema)
+		}
 		hasOverride := override != nil && strings.TrimSpace(override.SubstepID) != ""
 		overrideReason := ""
 		if hasOverride {
@@ -166,41 +183,53 @@ func buildSubstepViews(def WorkflowDef, process *Process, workflowKey string, ac
 		if canAdaptForm {
 			adaptURL = streamInstancePath(workflowKey, processIDString(process)) + "/substep/" + sub.SubstepID + "/override"
 		}
-		actions = append(actions, withSubstepBodyMode(SubstepBodyView{
-			WorkflowKey:    workflowKey,
-			ProcessID:      processIDString(process),
-			SubstepID:      sub.SubstepID,
-			Title:          sub.Title,
-			Role:           role,
-			RoleBadges:     roleBadges,
-			MatchingRoles:  matchingRoles,
-			RoleLabel:      roleLabel,
-			Palette:        palette,
-			InputKey:       sub.InputKey,
-			Description:    description,
-			InputType:      sub.InputType,
-			FormSchema:     formSchema,
-			FormUISchema:   formUISchema,
-			Status:         status,
-			DoneAt:         doneAt,
-			DoneAtISO:      doneAtISO,
-			DoneBy:         doneBy,
-			DoneRole:       doneRole,
-			Values:         values,
-			Attachments:    attachments,
-			Disabled:       disabled,
-			Reason:         reason,
-			DetailMessage:  detailMessage,
-			CanAdaptForm:   canAdaptForm,
-			AdaptURL:       adaptURL,
-			FormataArchURL: "",
-			OverrideReason: overrideReason,
-			HasOverride:    hasOverride,
-		}))
+		view := SubstepBodyView{
+			WorkflowKey:         workflowKey,
+			ProcessID:           processIDString(process),
+			SubstepID:           sub.SubstepID,
+			Title:               sub.Title,
+			Role:                role,
+			RoleBadges:          roleBadges,
+			MatchingRoles:       matchingRoles,
+			RoleLabel:           roleLabel,
+			Palette:             palette,
+			InputKey:            sub.InputKey,
+			Description:         description,
+			InputType:           sub.InputType,
+			FormSchema:          formSchema,
+			FormUISchema:        formUISchema,
+			Status:              status,
+			DoneAt:              doneAt,
+			DoneAtISO:           doneAtISO,
+			DoneBy:              doneBy,
+			DoneRole:            doneRole,
+			AmendedAt:           amendedAt,
+			AmendedAtISO:        amendedAtISO,
+			Values:              values,
+			Attachments:         attachments,
+			Disabled:            disabled,
+			Reason:              reason,
+			DetailMessage:       detailMessage,
+			CanAdaptForm:        canAdaptForm,
+			AdaptURL:            adaptURL,
+			FormataArchURL:      "",
+			OverrideReason:      overrideReason,
+			HasOverride:         hasOverride,
+			Help:                sub.Help,
+			Placeholder:         sub.Placeholder,
+			FieldHelp:           substepFieldHelp(sub),
+			RequireConfirmation: sub.RequireConfirmation,
+			Options:             sub.Options,
+			AllowedHosts:        sub.AllowedHosts,
+			Assignees:           assignees,
+		}
+		applyReworkNotice(&view, process)
+		actions = append(actions, withSubstepBodyMode(view))
 		if terminated && strings.TrimSpace(sub.SubstepID) == terminationSubstepID {
 			pastTermination = true
 		}
 	}
+	markRejectableSubsteps(def, workflowKey, process, actions)
 	return actions
 }
 
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	substepHiddenReason = "Not applicable"
+	substepHiddenDetail = "Step skipped because of an earlier answer."
+)
+
+// substepCondition is a parsed visibleWhen expression:
+// `<substepId>.<field path> == "value"` or `!=`. Field paths walk nested
+// objects of the referenced substep's current data with dots.
+type substepCondition struct {
+	SubstepID string
+	Field     string
+	Value     string
+	Negate    bool
+}
+
+// parseVisibleWhen splits expr against the known substep IDs. Substep IDs
+// contain dots themselves, so the longest matching ID prefix wins.
+func parseVisibleWhen(expr string, substepIDs []string) (substepCondition, error) {
+	expr = strings.TrimSpace(expr)
+	operator := "=="
+	left, right, found := strings.Cut(expr, "!=")
+	if found {
+		operator = "!="
+	} else if left, right, found = strings.Cut(expr, "=="); !found {
+		return substepCondition{}, fmt.Errorf("visibleWhen %q must use == or !=", expr)
+	}
+	left = strings.TrimSpace(left)
+	right = strings.TrimSpace(right)
+	if right == "" {
+		return substepCondition{}, fmt.Errorf("visibleWhen %q is missing a value", expr)
+	}
+	value := right
+	if strings.HasPrefix(right, `"`) || strings.HasPrefix(right, "'") {
+		if strings.HasPrefix(right, "'") && strings.HasSuffix(right, "'") && len(right) >= 2 {
+			value = right[1 : len(right)-1]
+		} else {
+			unquoted, err := strconv.Unquote(right)
+			if err != nil {
+				return substepCondition{}, fmt.Errorf("visibleWhen %q has an invalid quoted value", expr)
+			}
+			value = unquoted
+		}
+	}
+
+	ids := append([]string(nil), substepIDs...)
+	sort.Slice(ids, func(i, j int) bool { return len(ids[i]) > len(ids[j]) })
+	for _, id := range ids {
+		field, ok := strings.CutPrefix(left, id+".")
+		if !ok || strings.TrimSpace(field) == "" {
+			continue
+		}
+		return substepCondition{
+			SubstepID: id,
+			Field:     strings.TrimSpace(field),
+			Value:     value,
+			Negate:    operator == "!=",
+		}, nil
+	}
+	return substepCondition{}, fmt.Errorf("visibleWhen %q must reference <substepId>.<field> of a known substep", expr)
+}
+
+// validateSubstepVisibility checks every visibleWhen at load time. The
+// referenced substep must be a prerequisite, so the answer is known before
+// the conditional substep can become available.
+func validateSubstepVisibility(workflow *WorkflowDef) error {
+	ordered := orderedSubsteps(*workflow)
+	ids := make([]string, 0, len(ordered))
+	for _, sub := range ordered {
+		ids = append(ids, sub.SubstepID)
+	}
+	for stepIndex := range workflow.Steps {
+		for substepIndex := range workflow.Steps[stepIndex].Substep {
+			substep := &workflow.Steps[stepIndex].Substep[substepIndex]
+			substep.VisibleWhen = strings.TrimSpace(substep.VisibleWhen)
+			if substep.VisibleWhen == "" {
+				continue
+			}
+			condition, err := parseVisibleWhen(substep.VisibleWhen, ids)
+			if err != nil {
+				return fmt.Errorf("invalid visibleWhen for substep %s: %w", substep.SubstepID, err)
+			}
+			if _, ok := substepPrerequisites(*workflow, substep.SubstepID)[condition.SubstepID]; !ok {
+				return fmt.Errorf("invalid visibleWhen for substep %s: substep %s must come before it", substep.SubstepID, condition.SubstepID)
+			}
+		}
+	}
+	return nil
+}
+
+// substepPrerequisites returns every substep that must be settled before
+// substepID becomes available, following dependsOn transitively.
+func substepPrerequisites(def WorkflowDef, substepID string) map[string]struct{} {
+	ordered := orderedSubsteps(def)
+	direct := func(id string) []string {
+		for idx, sub := range ordered {
+			if sub.SubstepID != id {
+				continue
+			}
+			if len(sub.DependsOn) > 0 {
+				return sub.DependsOn
+			}
+			prev := make([]string, 0, idx)
+			for _, earlier := range ordered[:idx] {
+				prev = append(prev, earlier.SubstepID)
+			}
+			return prev
+		}
+		return nil
+	}
+	seen := map[string]struct{}{}
+	queue := direct(substepID)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		queue = append(queue, direct(id)...)
+	}
+	return seen
+}
+
+// hiddenSubsteps evaluates visibleWhen against the process, in workflow
+// order. A condition stays undecided (visible) until the referenced substep is
+// done; a hidden reference counts as having no value.
+func hiddenSubsteps(def WorkflowDef, process *Process) map[string]bool {
+	hidden := map[string]bool{}
+	if process == nil {
+		return hidden
+	}
+	ordered := orderedSubsteps(def)
+	ids := make([]string, 0, len(ordered))
+	for _, sub := range ordered {
+		ids = append(ids, sub.SubstepID)
+	}
+	for _, sub := range ordered {
+		if strings.TrimSpace(sub.VisibleWhen) == "" || isSubstepDone(process, sub.SubstepID) {
+			continue
+		}
+		condition, err := parseVisibleWhen(sub.VisibleWhen, ids)
+		if err != nil {
+			continue
+		}
+		var data map[string]interface{}
+		if !hidden[condition.SubstepID] {
+			progress, ok := process.Progress[condition.SubstepID]
+			if !ok || progress.State != "done" {
+				continue
+			}
+			data = currentStepData(progress)
+		}
+		actual, found := substepPayloadValue(data, condition.Field)
+		matches := found && fmt.Sprint(actual) == condition.Value
+		hidden[sub.SubstepID] = matches == condition.Negate
+	}
+	return hidden
+}
+
+func substepPayloadValue(data map[string]interface{}, field string) (interface{}, bool) {
+	var current interface{} = data
+	for _, segment := range strings.Split(field, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = object[segment]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, current != nil
+}
+
+// isSubstepSettled reports whether substepID no longer blocks anything:
+// completed, hidden by its visibleWhen, or optional under doneWhen: required.
+func isSubstepSettled(def WorkflowDef, process *Process, hidden map[string]bool, substepID string) bool {
+	if hidden[substepID] || isSubstepDone(process, substepID) {
+		return true
+	}
+	sub, _, err := findSubstep(def, substepID)
+	return err == nil && !substepRequiredForCompletion(def, sub)
+}
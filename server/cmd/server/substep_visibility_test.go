@@ -0,0 +1,107 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func workflowWithVisibleWhen(substepID, expr string) WorkflowDef {
+	def := testRuntimeConfig().Workflow
+	for stepIdx := range def.Steps {
+		for subIdx := range def.Steps[stepIdx].Substep {
+			if def.Steps[stepIdx].Substep[subIdx].SubstepID == substepID {
+				def.Steps[stepIdx].Substep[subIdx].VisibleWhen = expr
+			}
+		}
+	}
+	return def
+}
+
+func TestParseVisibleWhen(t *testing.T) {
+	ids := []string{"1.1", "1.10", "1.2"}
+	condition, err := parseVisibleWhen(`1.1.answer.value != "yes"`, ids)
+	if err != nil {
+		t.Fatalf("parseVisibleWhen: %v", err)
+	}
+	want := substepCondition{SubstepID: "1.1", Field: "answer.value", Value: "yes", Negate: true}
+	if condition != want {
+		t.Fatalf("condition = %#v, want %#v", condition, want)
+	}
+	if condition, err := parseVisibleWhen(`1.10.answer == 'no'`, ids); err != nil || condition.SubstepID != "1.10" || condition.Value != "no" {
+		t.Fatalf("longest prefix condition = %#v, err = %v", condition, err)
+	}
+	if condition, err := parseVisibleWhen("1.2.count == 3", ids); err != nil || condition.Value != "3" || condition.Negate {
+		t.Fatalf("bare literal condition = %#v, err = %v", condition, err)
+	}
+	for _, expr := range []string{`1.1.answer > "yes"`, `9.9.answer == "yes"`, `1.1 == "yes"`, `1.1.answer == `} {
+		if _, err := parseVisibleWhen(expr, ids); err == nil {
+			t.Fatalf("expected %q to be rejected", expr)
+		}
+	}
+}
+
+func TestValidateSubstepVisibilityRequiresPrerequisite(t *testing.T) {
+	def := workflowWithVisibleWhen("2.1", `1.2.inspected == "yes"`)
+	if err := validateSubstepVisibility(&def); err != nil {
+		t.Fatalf("validateSubstepVisibility: %v", err)
+	}
+	def = workflowWithVisibleWhen("1.2", `2.1.inspected == "yes"`)
+	if err := validateSubstepVisibility(&def); err == nil || !strings.Contains(err.Error(), "must come before it") {
+		t.Fatalf("expected later reference to be rejected, got %v", err)
+	}
+	def = workflowWithVisibleWhen("2.1", `1.2.inspected == "yes"`)
+	def.Steps[1].Substep[0].DependsOn = []string{"1.1"}
+	if err := validateSubstepVisibility(&def); err == nil {
+		t.Fatal("expected reference outside dependsOn to be rejected")
+	}
+}
+
+func TestHiddenSubstepIsSkippedForSequencingAndCompletion(t *testing.T) {
+	def := workflowWithVisibleWhen("2.1", `1.2.inspected == "yes"`)
+	process := processWithDone("1.1", "1.2", "1.3")
+	process.Progress["1.2"] = ProcessStep{State: "done", Data: map[string]interface{}{"inspected": "no"}}
+
+	if !hiddenSubsteps(def, process)["2.1"] {
+		t.Fatal("expected 2.1 to be hidden")
+	}
+	availability := computeAvailability(def, process)
+	if availability["2.1"] || !availability["2.2"] {
+		t.Fatalf("availability = %#v, want 2.2 available and 2.1 hidden", availability)
+	}
+	if isSequenceOK(def, process, "2.1") {
+		t.Fatal("expected hidden substep to be out of sequence")
+	}
+	for _, id := range []string{"2.2", "3.1", "3.2"} {
+		process.Progress[id] = ProcessStep{State: "done"}
+	}
+	if !isProcessComplete(def, process) {
+		t.Fatal("expected process to be done with 2.1 hidden")
+	}
+	export := buildNotarizedExport(def, process)
+	if got := export.Steps[1].Substeps[0].Status; got != "skipped" {
+		t.Fatalf("notarized status = %q, want skipped", got)
+	}
+
+	process.Progress["1.2"] = ProcessStep{State: "done", Data: map[string]interface{}{"inspected": "yes"}}
+	if hiddenSubsteps(def, process)["2.1"] || isProcessComplete(def, process) {
+		t.Fatal("expected 2.1 to be required when the answer matches")
+	}
+}
+
+func TestBuildSubstepViewsMarksHiddenSubstepNotApplicable(t *testing.T) {
+	def := workflowWithVisibleWhen("1.3", `1.2.inspected == "yes"`)
+	process := processWithDone("1.1")
+	process.Progress["1.2"] = ProcessStep{State: "done", Data: map[string]interface{}{"inspected": "no"}}
+
+	views := buildSubstepViews(def, process, "workflow", Actor{Role: "dep1", RoleSlugs: []string{"dep1"}}, false, nil, nil)
+	for _, view := range views {
+		if view.SubstepID != "1.3" {
+			continue
+		}
+		if view.Status != "skipped" || view.Reason != substepHiddenReason || !view.Disabled {
+			t.Fatalf("unexpected hidden view %#v", view)
+		}
+		return
+	}
+	t.Fatal("substep 1.3 missing from views")
+}
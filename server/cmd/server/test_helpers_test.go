@@ -82,11 +82,7 @@ func testTemplates() *template.Template {
 	  {{else if eq .Body "home_body"}}{{template "home_body" .}}
 	  {{else if eq .Body "process_body"}}{{template "process_body" .}}
   {{else if eq .Body "dpp_body"}}{{template "dpp_body" .}}
-  {{else if eq .Body "about_body"}}{{template "about_body" .}}
-  {{else if eq .Body "backoffice_picker_body"}}{{template "backoffice_picker_body" .}}
-  {{else if eq .Body "backoffice_landing_body"}}{{template "backoffice_landing_body" .}}
-  {{else if eq .Body "dept_dashboard_body"}}{{template "dept_dashboard_body" .}}
-  {{else if eq .Body "dept_process_body"}}{{template "dept_process_body" .}}{{end}}
+  {{else if eq .Body "about_body"}}{{template "about_body" .}}{{end}}
 {{end}}
 	{{define "home_picker_body"}}HOME_PICKER {{range .Workflows}}{{.Key}}:{{.Name}}{{if .Description}}:{{.Description}}{{end}}:{{.Counts.NotStarted}}/{{.Counts.Started}}/{{.Counts.Terminated}}|{{end}}{{end}}
 	{{define "public_home_body"}}PUBLIC_HOME{{end}}
@@ -113,16 +109,6 @@ func testTemplates() *template.Template {
 {{define "dpp.html"}}{{template "layout.html" .}}{{end}}
 {{define "about_body"}}ABOUT{{end}}
 {{define "about.html"}}{{template "layout.html" .}}{{end}}
-{{define "backoffice_picker_body"}}BACKOFFICE_PICKER {{range .Workflows}}{{.Key}}:{{.Name}}{{if .Description}}:{{.Description}}{{end}}:{{.Counts.NotStarted}}/{{.Counts.Started}}/{{.Counts.Terminated}}|{{end}}{{end}}
-{{define "backoffice_landing_body"}}BACKOFFICE{{end}}
-{{define "backoffice.html"}}{{template "layout.html" .}}{{end}}
-{{define "backoffice_landing.html"}}{{template "layout.html" .}}{{end}}
-{{define "dept_dashboard_content"}}DASHBOARD {{.CurrentUser.Role}} TODO {{len .TodoActions}} ACTIVE {{len .ActiveProcesses}} DONE {{len .DoneProcesses}}{{end}}
-{{define "dept_dashboard_body"}}{{template "dept_dashboard_content" .}}{{end}}
-{{define "backoffice_department.html"}}{{template "layout.html" .}}{{end}}
-{{define "backoffice_department_partial.html"}}{{template "dept_dashboard_content" .}}{{end}}
-{{define "dept_process_body"}}PROCESS_PAGE {{.Error}}{{end}}
-{{define "backoffice_process.html"}}{{template "layout.html" .}}{{end}}
 {{define "error_banner.html"}}{{if .Error}}ERROR {{.Error}}{{end}}{{end}}
 `))
 }
@@ -28,6 +28,7 @@ func advanceTimelinePastTermination(substepID string, terminated bool, terminati
 type timelineWalkState struct {
 	substepOrgs          map[string]string
 	availableMap         map[string]bool
+	hidden               map[string]bool
 	terminated           bool
 	terminationSubstepID string
 	terminationReason    string
@@ -47,6 +48,7 @@ func newTimelineWalkState(def WorkflowDef, process *Process) timelineWalkState {
 	return timelineWalkState{
 		substepOrgs:          substepOrgs,
 		availableMap:         availableMap,
+		hidden:               hiddenSubsteps(def, process),
 		terminated:           terminated,
 		terminationSubstepID: terminationSubstepID,
 		terminationReason:    terminationReason,
@@ -87,6 +89,9 @@ func buildTimelineSteps(def WorkflowDef, process *Process, orgNames map[string]s
 		}
 		for _, sub := range workflowSubsteps {
 			status := resolveTimelineSubstepStatus(sub.SubstepID, process, state.availableMap, state.terminated, state.terminationSubstepID, state.pastTermination)
+			if status == "locked" && state.hidden[sub.SubstepID] {
+				status = "skipped"
+			}
 			entry := opts.buildSubstep(timelineSubstepBuildContext{
 				state:       &state,
 				step:        step,
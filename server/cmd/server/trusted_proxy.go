@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// trustedProxiesFromEnv parses TRUSTED_PROXIES: comma-separated CIDRs or
+// bare IPs of the reverse proxies in front of the server. Forwarded headers
+// are only believed when the immediate peer is one of them; with the list
+// empty they are ignored and r.RemoteAddr / r.TLS describe the client. main()
+// parses it once into Server.trustedProxies and fails startup on a malformed
+// entry, so a typo does not silently stop honoring the proxy's headers.
+func trustedProxiesFromEnv() ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, item := range splitCSVEnv("TRUSTED_PROXIES") {
+		if !strings.Contains(item, "/") {
+			addr, err := netip.ParseAddr(item)
+			if err != nil {
+				return nil, fmt.Errorf("invalid TRUSTED_PROXIES entry %q: %w", item, err)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TRUSTED_PROXIES entry %q: %w", item, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+func isTrustedProxyAddr(addr netip.Addr, prefixes []netip.Prefix) bool {
+	addr = addr.Unmap()
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteAddrIP is the immediate peer of r, without its port.
+func remoteAddrIP(r *http.Request) (netip.Addr, bool) {
+	host := strings.TrimSpace(r.RemoteAddr)
+	if splitHost, _, err := net.SplitHostPort(host); err == nil {
+		host = splitHost
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+// fromTrustedProxy reports whether r arrived through a TRUSTED_PROXIES peer.
+func (s *Server) fromTrustedProxy(r *http.Request) bool {
+	if r == nil || len(s.trustedProxies) == 0 {
+		return false
+	}
+	peer, ok := remoteAddrIP(r)
+	return ok && isTrustedProxyAddr(peer, s.trustedProxies)
+}
+
+// clientIP returns the address of the caller. Behind a trusted proxy it is
+// the right-most X-Forwarded-For entry that is not itself a trusted proxy, so
+// a client cannot spoof it by sending its own header; otherwise it is the
+// peer address.
+func (s *Server) clientIP(r *http.Request) string {
+	if r == nil {
+		return ""
+	}
+	peer, ok := remoteAddrIP(r)
+	if !ok {
+		return strings.TrimSpace(r.RemoteAddr)
+	}
+	if !s.fromTrustedProxy(r) {
+		return peer.String()
+	}
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for idx := len(hops) - 1; idx >= 0; idx-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[idx]))
+		if err != nil {
+			break
+		}
+		if !isTrustedProxyAddr(addr, s.trustedProxies) {
+			return addr.Unmap().String()
+		}
+		peer = addr.Unmap()
+	}
+	return peer.String()
+}
+
+// forwardedProto and forwardedHost are the scheme and host a trusted proxy
+// reports for the client's request: X-Forwarded-Proto / X-Forwarded-Host, or
+// the proto / host parameters of Forwarded. They are empty when r did not
+// come through a trusted proxy.
+func (s *Server) forwardedProto(r *http.Request) string {
+	if !s.fromTrustedProxy(r) {
+		return ""
+	}
+	if proto := firstForwardedHeaderValue(r.Header.Get("X-Forwarded-Proto")); proto != "" {
+		return proto
+	}
+	return forwardedHeaderParam(r.Header.Get("Forwarded"), "proto")
+}
+
+func (s *Server) forwardedHost(r *http.Request) string {
+	if !s.fromTrustedProxy(r) {
+		return ""
+	}
+	if host := firstForwardedHeaderValue(r.Header.Get("X-Forwarded-Host")); host != "" {
+		return host
+	}
+	return forwardedHeaderParam(r.Header.Get("Forwarded"), "host")
+}
+
+// requestIsHTTPS reports whether the client reached the server over TLS:
+// directly, or as told by a trusted proxy.
+func (s *Server) requestIsHTTPS(r *http.Request) bool {
+	if r == nil {
+		return false
+	}
+	if r.TLS != nil {
+		return true
+	}
+	return strings.EqualFold(s.forwardedProto(r), "https")
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+// testTrustedProxies parses raw the way main() parses TRUSTED_PROXIES.
+func testTrustedProxies(t *testing.T, raw string) []netip.Prefix {
+	t.Helper()
+	t.Setenv("TRUSTED_PROXIES", raw)
+	prefixes, err := trustedProxiesFromEnv()
+	if err != nil {
+		t.Fatalf("trustedProxiesFromEnv(%q): %v", raw, err)
+	}
+	return prefixes
+}
+
+func TestClientIPHonorsForwardedForOnlyFromTrustedProxies(t *testing.T) {
+	newRequest := func(remoteAddr string, forwardedFor ...string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		for _, value := range forwardedFor {
+			req.Header.Add("X-Forwarded-For", value)
+		}
+		return req
+	}
+
+	server := &Server{}
+	if got := server.clientIP(newRequest("203.0.113.9:5000", "198.51.100.7")); got != "203.0.113.9" {
+		t.Fatalf("without trusted proxies clientIP = %q", got)
+	}
+
+	server.trustedProxies = testTrustedProxies(t, "10.0.0.0/8, 192.168.1.5")
+	tests := []struct {
+		name string
+		req  *http.Request
+		want string
+	}{
+		{"untrusted peer", newRequest("203.0.113.9:5000", "198.51.100.7"), "203.0.113.9"},
+		{"trusted peer", newRequest("10.1.2.3:5000", "198.51.100.7"), "198.51.100.7"},
+		{"spoofed left-most entry", newRequest("10.1.2.3:5000", "1.2.3.4, 198.51.100.7"), "198.51.100.7"},
+		{"proxy chain", newRequest("10.1.2.3:5000", "198.51.100.7", "192.168.1.5"), "198.51.100.7"},
+		{"no header", newRequest("[::ffff:10.1.2.3]:5000"), "10.1.2.3"},
+		{"garbage header", newRequest("10.1.2.3:5000", "unknown"), "10.1.2.3"},
+	}
+	for _, tt := range tests {
+		if got := server.clientIP(tt.req); got != tt.want {
+			t.Fatalf("%s: clientIP = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRequestIsHTTPSAndTrustedProxiesValidation(t *testing.T) {
+	server := &Server{trustedProxies: testTrustedProxies(t, "10.0.0.0/8")}
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "203.0.113.9:5000"
+	req.Header.Set("X-Forwarded-Proto", "https")
+	if server.requestIsHTTPS(req) {
+		t.Fatal("expected forwarded proto from an untrusted peer to be ignored")
+	}
+	req.RemoteAddr = "10.0.0.2:5000"
+	if !server.requestIsHTTPS(req) {
+		t.Fatal("expected forwarded proto from a trusted peer")
+	}
+	forwarded := httptest.NewRequest(http.MethodGet, "/", nil)
+	forwarded.RemoteAddr = "10.0.0.2:5000"
+	forwarded.Header.Set("Forwarded", `for=198.51.100.7;proto=https;host="attesta.example.com"`)
+	if !server.requestIsHTTPS(forwarded) {
+		t.Fatal("expected Forwarded proto from a trusted peer")
+	}
+	direct := httptest.NewRequest(http.MethodGet, "/", nil)
+	direct.TLS = &tls.ConnectionState{}
+	if !server.requestIsHTTPS(direct) {
+		t.Fatal("expected direct TLS to count")
+	}
+
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/33")
+	if _, err := trustedProxiesFromEnv(); err == nil {
+		t.Fatal("expected invalid CIDR to be rejected")
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// isURLSubstep reports whether sub takes a single external http(s) link as
+// evidence.
+func isURLSubstep(sub WorkflowSub) bool {
+	return normalizeInputTypeForCheck(sub.InputType) == "url"
+}
+
+func urlInputKey(sub WorkflowSub) string {
+	if key := strings.TrimSpace(sub.InputKey); key != "" {
+		return key
+	}
+	return "url"
+}
+
+// normalizeURLConfig lowercases the allowedHosts of a url substep and
+// rejects empty or duplicate entries.
+func normalizeURLConfig(substep *WorkflowSub) error {
+	if len(substep.Schema) > 0 || len(substep.UISchema) > 0 {
+		return errors.New("schema is not allowed when inputType=url")
+	}
+	if len(substep.Options) > 0 {
+		return errors.New("options are only allowed when inputType=multiselect")
+	}
+	seen := make(map[string]bool, len(substep.AllowedHosts))
+	for idx, host := range substep.AllowedHosts {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host == "" {
+			return fmt.Errorf("allowedHosts[%d] is required", idx)
+		}
+		if strings.ContainsAny(host, "/:@ ") {
+			return fmt.Errorf("allowedHosts[%d] %q must be a bare hostname", idx, host)
+		}
+		if seen[host] {
+			return fmt.Errorf("allowedHosts value %q is duplicated", host)
+		}
+		seen[host] = true
+		substep.AllowedHosts[idx] = host
+	}
+	return nil
+}
+
+// urlHostAllowed matches host against allowed exactly or as a subdomain, so
+// "example.com" also admits "docs.example.com". An empty list allows any host.
+func urlHostAllowed(allowed []string, host string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	host = strings.ToLower(host)
+	for _, candidate := range allowed {
+		if host == candidate || strings.HasSuffix(host, "."+candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeURLValue checks that raw is an absolute http(s) URL on an allowed
+// host and returns it trimmed. The string is stored as submitted otherwise,
+// so the digest covers exactly the link the user gave.
+func normalizeURLValue(sub WorkflowSub, raw interface{}) (string, error) {
+	key := urlInputKey(sub)
+	value, ok := raw.(string)
+	if !ok && raw != nil {
+		return "", fmt.Errorf("%s must be a link.", key)
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", fmt.Errorf("%s is required.", key)
+	}
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Hostname() == "" || parsed.Opaque != "" {
+		return "", fmt.Errorf("%s must be a valid http or https link.", key)
+	}
+	if scheme := strings.ToLower(parsed.Scheme); scheme != "http" && scheme != "https" {
+		return "", fmt.Errorf("%s must be a valid http or https link.", key)
+	}
+	if !urlHostAllowed(sub.AllowedHosts, parsed.Hostname()) {
+		return "", fmt.Errorf("Links to %s are not allowed for this step.", parsed.Hostname())
+	}
+	return value, nil
+}
+
+// parseURLPayload reads the link posted as the inputKey field, or a JSON
+// object in "value" for API clients, and returns {<inputKey>: "<url>"}
+// through normalizePayload.
+func parseURLPayload(r *http.Request, substep WorkflowSub) (map[string]interface{}, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, errInvalidForm
+	}
+	rawValue := strings.TrimSpace(r.PostForm.Get("value"))
+	if rawValue == "" {
+		data, err := json.Marshal(map[string]interface{}{urlInputKey(substep): r.PostForm.Get(urlInputKey(substep))})
+		if err != nil {
+			return nil, errInvalidForm
+		}
+		rawValue = string(data)
+	}
+	return normalizePayload(substep, rawValue)
+}
+
+// urlDisplayValues shows a stored link as a clickable row. Values that no
+// longer pass normalizeURLValue are shown as plain text.
+func urlDisplayValues(sub WorkflowSub, data map[string]interface{}) []SubstepKV {
+	key := urlInputKey(sub)
+	link, err := normalizeURLValue(WorkflowSub{InputKey: key}, data[key])
+	if err != nil {
+		return flattenDisplayValues("", data)
+	}
+	return []SubstepKV{{Key: key, Value: link, URL: link}}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func testURLSubstep() WorkflowSub {
+	return WorkflowSub{SubstepID: "1.1", InputType: "url", InputKey: "evidence", AllowedHosts: []string{"example.com"}}
+}
+
+func TestNormalizeInputTypesURL(t *testing.T) {
+	workflow := WorkflowDef{Steps: []WorkflowStep{{StepID: "1", Substep: []WorkflowSub{
+		{SubstepID: "1.1", InputType: " URL ", AllowedHosts: []string{" Example.COM ", "docs.test"}},
+	}}}}
+	if err := normalizeInputTypes(&workflow); err != nil {
+		t.Fatalf("normalizeInputTypes: %v", err)
+	}
+	sub := workflow.Steps[0].Substep[0]
+	if sub.InputType != "url" || !reflect.DeepEqual(sub.AllowedHosts, []string{"example.com", "docs.test"}) {
+		t.Fatalf("substep = %#v", sub)
+	}
+
+	for name, hosts := range map[string][]string{
+		"blank":     {" "},
+		"duplicate": {"a.test", "A.test"},
+		"with path": {"a.test/x"},
+	} {
+		workflow.Steps[0].Substep[0].AllowedHosts = hosts
+		if err := normalizeInputTypes(&workflow); err == nil {
+			t.Fatalf("%s allowedHosts: expected error", name)
+		}
+	}
+	workflow.Steps[0].Substep[0] = WorkflowSub{SubstepID: "1.1", InputType: "formata", Schema: map[string]interface{}{"type": "object"}, AllowedHosts: []string{"a.test"}}
+	if err := normalizeInputTypes(&workflow); err == nil || !strings.Contains(err.Error(), "only allowed when inputType=url") {
+		t.Fatalf("expected allowedHosts rejection on formata, got %v", err)
+	}
+}
+
+func TestNormalizePayloadURL(t *testing.T) {
+	sub := testURLSubstep()
+	payload, err := normalizePayload(sub, `{"evidence":" https://docs.example.com/report.pdf ","extra":1}`)
+	if err != nil {
+		t.Fatalf("normalizePayload: %v", err)
+	}
+	if !reflect.DeepEqual(payload, map[string]interface{}{"evidence": "https://docs.example.com/report.pdf"}) {
+		t.Fatalf("payload = %#v", payload)
+	}
+	for _, raw := range []string{
+		`{"evidence":"ftp://example.com/file"}`,
+		`{"evidence":"javascript:alert(1)"}`,
+		`{"evidence":"example.com/report"}`,
+		`{"evidence":"https://evil.test/report"}`,
+		`{"evidence":"https://notexample.com/report"}`,
+		`{"evidence":""}`,
+		`{"evidence":42}`,
+	} {
+		if _, err := normalizePayload(sub, raw); err == nil {
+			t.Fatalf("expected %s to be rejected", raw)
+		}
+	}
+	sub.AllowedHosts = nil
+	if _, err := normalizePayload(sub, `{"evidence":"http://evil.test/report"}`); err != nil {
+		t.Fatalf("any host should pass without allowedHosts: %v", err)
+	}
+	want := []SubstepKV{{Key: "evidence", Value: "https://docs.example.com/report.pdf", URL: "https://docs.example.com/report.pdf"}}
+	if got := substepDisplayValues(sub, payload); !reflect.DeepEqual(got, want) {
+		t.Fatalf("display values = %#v", got)
+	}
+	if got := substepDisplayValues(sub, map[string]interface{}{"evidence": "javascript:alert(1)"}); len(got) != 1 || got[0].URL != "" {
+		t.Fatalf("unsafe stored value must not become a link: %#v", got)
+	}
+}
+
+func TestHandleCompleteSubstepURL(t *testing.T) {
+	store := NewMemoryStore()
+	server, processID, _ := newServerForCompleteTests(t, store, fakeAuthorizer{})
+	cfg := testFormataRuntimeConfig()
+	sub := &cfg.Workflow.Steps[0].Substep[0]
+	link := testURLSubstep()
+	sub.InputType, sub.InputKey, sub.AllowedHosts, sub.Schema = link.InputType, link.InputKey, link.AllowedHosts, nil
+	server.configProvider = func() (RuntimeConfig, error) { return cfg, nil }
+	post := func(form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/process/"+processID+"/substep/1.1/complete", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		server.handleCompleteSubstep(rec, req, processID, "1.1")
+		return rec
+	}
+
+	if rec := post(url.Values{"evidence": {"file:///etc/passwd"}}); rec.Code != http.StatusBadRequest {
+		t.Fatalf("file scheme status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if rec := post(url.Values{"evidence": {"https://example.com/lab/42"}}); rec.Code != http.StatusOK {
+		t.Fatalf("complete status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	id, _ := primitive.ObjectIDFromHex(processID)
+	process, _ := store.SnapshotProcess(id)
+	progress := normalizeProgressKeys(process.Progress)["1.1"]
+	if progress.State != "done" || !reflect.DeepEqual(progress.Data, map[string]interface{}{"evidence": "https://example.com/lab/42"}) {
+		t.Fatalf("progress = %#v", progress)
+	}
+}
+
+func TestSubstepBodyTemplateURL(t *testing.T) {
+	tmpl := parseTestTemplates(t)
+	sub := testURLSubstep()
+	render := func(view SubstepBodyView) string {
+		t.Helper()
+		var out bytes.Buffer
+		if err := tmpl.ExecuteTemplate(&out, "substep_body", withSubstepBodyMode(view)); err != nil {
+			t.Fatalf("render substep_body template: %v", err)
+		}
+		return out.String()
+	}
+
+	body := render(SubstepBodyView{
+		WorkflowKey:   "workflow",
+		ProcessID:     "process-1",
+		SubstepID:     "1.1",
+		InputKey:      sub.InputKey,
+		InputType:     "url",
+		AllowedHosts:  sub.AllowedHosts,
+		Status:        "available",
+		MatchingRoles: []SubstepRoleOption{{Slug: "dep1", Label: "Dep 1"}},
+	})
+	for _, marker := range []string{`type="url"`, `name="evidence"`, "example.com"} {
+		if !strings.Contains(body, marker) {
+			t.Fatalf("expected %q in body: %s", marker, body)
+		}
+	}
+	if strings.Contains(body, "js-formata-host") {
+		t.Fatalf("url substep must not render a formata form: %s", body)
+	}
+
+	body = render(SubstepBodyView{
+		ProcessID: "process-1",
+		SubstepID: "1.1",
+		InputType: "url",
+		Status:    "done",
+		Values:    substepDisplayValues(sub, map[string]interface{}{"evidence": "https://example.com/lab/42"}),
+	})
+	if !strings.Contains(body, `<a href="https://example.com/lab/42" target="_blank" rel="noopener noreferrer">`) {
+		t.Fatalf("expected evidence link in result: %s", body)
+	}
+}
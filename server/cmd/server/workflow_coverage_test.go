@@ -4,7 +4,9 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -49,6 +51,62 @@ func TestDefaultWorkflowKeyBranches(t *testing.T) {
 	}
 }
 
+func TestDefaultWorkflowKeyHonorsConfiguredKey(t *testing.T) {
+	tempDir := t.TempDir()
+	writeWorkflowConfig(t, filepath.Join(tempDir, "workflow.yaml"), "Main workflow", "string")
+	writeWorkflowConfig(t, filepath.Join(tempDir, "zeta.yaml"), "Zeta workflow", "string")
+
+	server := &Server{configDir: tempDir, defaultWorkflow: "zeta"}
+	if err := server.validateDefaultWorkflowKey(); err != nil {
+		t.Fatalf("validateDefaultWorkflowKey: %v", err)
+	}
+	if got := server.defaultWorkflowKey(); got != "zeta" {
+		t.Fatalf("defaultWorkflowKey = %q, want zeta", got)
+	}
+	if !server.processBelongsToWorkflow(&Process{}, "zeta") {
+		t.Fatal("expected legacy processes without a workflow key to resolve to the configured default")
+	}
+
+	server = &Server{configDir: tempDir, defaultWorkflow: "missing"}
+	if err := server.validateDefaultWorkflowKey(); err == nil || !strings.Contains(err.Error(), "DEFAULT_WORKFLOW_KEY") {
+		t.Fatalf("expected unknown key error, got %v", err)
+	}
+}
+
+func TestDisabledWorkflowsSkippedForDefaultAndHomeOptions(t *testing.T) {
+	tempDir := t.TempDir()
+	writeWorkflowConfig(t, filepath.Join(tempDir, "workflow.yaml"), "Main workflow", "string")
+	writeWorkflowConfig(t, filepath.Join(tempDir, "zeta.yaml"), "Zeta workflow", "string")
+	mainPath := filepath.Join(tempDir, "workflow.yaml")
+	data, err := os.ReadFile(mainPath)
+	if err != nil {
+		t.Fatalf("read workflow: %v", err)
+	}
+	disabled := strings.Replace(string(data), "workflow:\n", "workflow:\n  enabled: false\n", 1)
+	if err := os.WriteFile(mainPath, []byte(disabled), 0o644); err != nil {
+		t.Fatalf("write workflow: %v", err)
+	}
+
+	server := &Server{configDir: tempDir, defaultWorkflow: "workflow"}
+	if got := server.defaultWorkflowKey(); got != "zeta" {
+		t.Fatalf("defaultWorkflowKey = %q, want zeta", got)
+	}
+	options, err := server.workflowOptions(t.Context(), nil)
+	if err != nil {
+		t.Fatalf("workflowOptions: %v", err)
+	}
+	if len(options) != 1 || options[0].Key != "zeta" {
+		t.Fatalf("expected only enabled workflow in options, got %#v", options)
+	}
+	catalog, err := server.workflowCatalog()
+	if err != nil {
+		t.Fatalf("workflowCatalog: %v", err)
+	}
+	if workflowEnabled(catalog["workflow"].Workflow) || !workflowEnabled(catalog["zeta"].Workflow) {
+		t.Fatalf("unexpected enabled flags: workflow=%v zeta=%v", catalog["workflow"].Workflow.Enabled, catalog["zeta"].Workflow.Enabled)
+	}
+}
+
 func TestHandleWorkflowRoutesDispatchFallbacks(t *testing.T) {
 	tempDir := t.TempDir()
 	writeWorkflowConfig(t, filepath.Join(tempDir, "workflow.yaml"), "Main workflow", "string")
@@ -100,6 +158,9 @@ func TestLegacyRoutesGone(t *testing.T) {
 		"/org-admin/profile",
 		"/dashboard",
 		"/dashboard/streams/workflow",
+		"/backoffice",
+		"/backoffice/dep1",
+		"/my/streams/workflow/backoffice/dep1/partial",
 	}
 	for _, path := range cases {
 		t.Run(path, func(t *testing.T) {
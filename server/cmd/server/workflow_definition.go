@@ -0,0 +1,193 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+const workflowDefinitionAPIPath = "/api/v1/workflows"
+
+// WorkflowDefinition is the public shape of a workflow config. It is built
+// field by field so storage details such as the Mongo _id and the server's
+// workflowDefID never reach integrators.
+type WorkflowDefinition struct {
+	Key           string                           `json:"key"`
+	Name          string                           `json:"name"`
+	Description   string                           `json:"description,omitempty"`
+	StartRoles    []string                         `json:"start_roles,omitempty"`
+	RetentionDays int                              `json:"retention_days,omitempty"`
+	Organizations []WorkflowDefinitionOrganization `json:"organizations"`
+	Roles         []WorkflowDefinitionRole         `json:"roles"`
+	Steps         []WorkflowDefinitionStep         `json:"steps"`
+}
+
+type WorkflowDefinitionOrganization struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+type WorkflowDefinitionRole struct {
+	OrgSlug string `json:"org_slug"`
+	Slug    string `json:"slug"`
+	Name    string `json:"name"`
+}
+
+type WorkflowDefinitionStep struct {
+	StepID       string                      `json:"step_id"`
+	Title        string                      `json:"title"`
+	Order        int                         `json:"order"`
+	Organization string                      `json:"organization,omitempty"`
+	Substeps     []WorkflowDefinitionSubstep `json:"substeps"`
+}
+
+type WorkflowDefinitionSubstep struct {
+	SubstepID           string                 `json:"substep_id"`
+	Title               string                 `json:"title"`
+	Order               int                    `json:"order"`
+	Roles               []string               `json:"roles"`
+	InputKey            string                 `json:"input_key"`
+	InputType           string                 `json:"input_type"`
+	Schema              map[string]interface{} `json:"schema,omitempty"`
+	UISchema            map[string]interface{} `json:"ui_schema,omitempty"`
+	DependsOn           []string               `json:"depends_on,omitempty"`
+	VisibleWhen         string                 `json:"visible_when,omitempty"`
+	Min                 *float64               `json:"min,omitempty"`
+	Max                 *float64               `json:"max,omitempty"`
+	Step                *float64               `json:"step,omitempty"`
+	Help                string                 `json:"help,omitempty"`
+	Placeholder         string                 `json:"placeholder,omitempty"`
+	HelpBySchema        map[string]string      `json:"help_by_schema,omitempty"`
+	RequireConfirmation bool                   `json:"require_confirmation,omitempty"`
+}
+
+type WorkflowDefinitionListResponse struct {
+	Workflows []WorkflowDefinition `json:"workflows"`
+}
+
+func buildWorkflowDefinition(key string, cfg RuntimeConfig) WorkflowDefinition {
+	definition := WorkflowDefinition{
+		Key:           key,
+		Name:          cfg.Workflow.Name,
+		Description:   strings.TrimSpace(cfg.Workflow.Description),
+		StartRoles:    append([]string(nil), cfg.Workflow.StartRoles...),
+		RetentionDays: cfg.Workflow.RetentionDays,
+		Organizations: make([]WorkflowDefinitionOrganization, 0, len(cfg.Organizations)),
+		Roles:         make([]WorkflowDefinitionRole, 0, len(cfg.Roles)),
+		Steps:         []WorkflowDefinitionStep{},
+	}
+	for _, org := range cfg.Organizations {
+		definition.Organizations = append(definition.Organizations, WorkflowDefinitionOrganization{Slug: org.Slug, Name: org.Name})
+	}
+	for _, role := range cfg.Roles {
+		definition.Roles = append(definition.Roles, WorkflowDefinitionRole{OrgSlug: role.OrgSlug, Slug: role.Slug, Name: role.Name})
+	}
+	for _, step := range sortedSteps(cfg.Workflow) {
+		stepView := WorkflowDefinitionStep{
+			StepID:       step.StepID,
+			Title:        step.Title,
+			Order:        step.Order,
+			Organization: step.OrganizationSlug,
+			Substeps:     []WorkflowDefinitionSubstep{},
+		}
+		for _, sub := range sortedSubsteps(step) {
+			stepView.Substeps = append(stepView.Substeps, WorkflowDefinitionSubstep{
+				SubstepID:           sub.SubstepID,
+				Title:               sub.Title,
+				Order:               sub.Order,
+				Roles:               substepRoles(sub),
+				InputKey:            sub.InputKey,
+				InputType:           sub.InputType,
+				Schema:              sub.Schema,
+				UISchema:            sub.UISchema,
+				DependsOn:           append([]string(nil), sub.DependsOn...),
+				VisibleWhen:         sub.VisibleWhen,
+				Min:                 sub.Min,
+				Max:                 sub.Max,
+				Step:                sub.Step,
+				Help:                sub.Help,
+				Placeholder:         sub.Placeholder,
+				HelpBySchema:        sub.HelpBySchema,
+				RequireConfirmation: sub.RequireConfirmation,
+			})
+		}
+		definition.Steps = append(definition.Steps, stepView)
+	}
+	return definition
+}
+
+// handleWorkflowDefinitionAPI serves GET /api/v1/workflows (every workflow)
+// and GET /api/v1/workflows/:key/{definition,overview}. Workflows whose
+// organization or role references do not resolve are refused rather than
+// described.
+func (s *Server) handleWorkflowDefinitionAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, _, ok := s.requireAuthenticatedPost(w, r)
+	if !ok {
+		return
+	}
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, workflowDefinitionAPIPath), "/")
+	catalog, err := s.workflowCatalog()
+	if err != nil {
+		logAndHTTPError(w, r, http.StatusInternalServerError, "failed to load workflows", err, "failed to load workflow catalog for definition api")
+		return
+	}
+
+	if rest == "" {
+		response := WorkflowDefinitionListResponse{Workflows: []WorkflowDefinition{}}
+		for _, key := range sortedWorkflowKeys(catalog) {
+			cfg := catalog[key]
+			if err := s.validateWorkflowRefs(r.Context(), cfg); err != nil {
+				if !s.writeWorkflowRefsError(w, r, key, err) {
+					return
+				}
+				continue
+			}
+			response.Workflows = append(response.Workflows, buildWorkflowDefinition(key, cfg))
+		}
+		writeJSON(w, response)
+		return
+	}
+
+	key, suffix, _ := strings.Cut(rest, "/")
+	if suffix != "definition" && suffix != "overview" {
+		http.NotFound(w, r)
+		return
+	}
+	cfg, ok := catalog[key]
+	if !ok {
+		http.Error(w, "workflow not found", http.StatusNotFound)
+		return
+	}
+	if err := s.validateWorkflowRefs(r.Context(), cfg); err != nil {
+		if s.writeWorkflowRefsError(w, r, key, err) {
+			http.Error(w, err.Error(), http.StatusConflict)
+		}
+		return
+	}
+	if suffix == "overview" {
+		overview, err := s.buildWorkflowOverview(r.Context(), user, key, cfg)
+		if err != nil {
+			logAndHTTPError(w, r, http.StatusInternalServerError, "failed to load processes", err, "failed to count processes of workflow %s for overview api", key)
+			return
+		}
+		writeJSON(w, overview)
+		return
+	}
+	writeJSON(w, buildWorkflowDefinition(key, cfg))
+}
+
+// writeWorkflowRefsError answers unexpected validation failures with 500 and
+// reports whether err was an ordinary reference error the caller should
+// handle itself.
+func (s *Server) writeWorkflowRefsError(w http.ResponseWriter, r *http.Request, key string, err error) bool {
+	var refErr *WorkflowRefValidationError
+	if errors.As(err, &refErr) {
+		return true
+	}
+	logAndHTTPError(w, r, http.StatusInternalServerError, "failed to validate workflow", err, "failed to validate workflow %s references", key)
+	return false
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHandleWorkflowDefinitionAPI(t *testing.T) {
+	tempDir := t.TempDir()
+	writeWorkflowConfig(t, filepath.Join(tempDir, "workflow.yaml"), "Main workflow", "string", "Main description")
+	writeWorkflowConfig(t, filepath.Join(tempDir, "second.yaml"), "Second workflow", "string")
+	server := &Server{
+		authorizer: fakeAuthorizer{},
+		configDir:  tempDir,
+	}
+	mux := server.newMux()
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := get("/api/v1/workflows/workflow/definition")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("definition status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	if body := rec.Body.String(); strings.Contains(body, `"_id"`) || strings.Contains(strings.ToLower(body), "workflowdefid") {
+		t.Fatalf("expected no storage ids in definition, got %s", body)
+	}
+	var definition WorkflowDefinition
+	if err := json.Unmarshal(rec.Body.Bytes(), &definition); err != nil {
+		t.Fatalf("decode definition: %v", err)
+	}
+	if definition.Key != "workflow" || definition.Name != "Main workflow" || definition.Description != "Main description" {
+		t.Fatalf("unexpected definition header %#v", definition)
+	}
+	if len(definition.Steps) != 1 || len(definition.Steps[0].Substeps) != 1 {
+		t.Fatalf("unexpected steps %#v", definition.Steps)
+	}
+	substep := definition.Steps[0].Substeps[0]
+	if substep.SubstepID != "1.1" || substep.InputType != "formata" || !reflect.DeepEqual(substep.Roles, []string{"dep1"}) || substep.Schema["type"] != "object" {
+		t.Fatalf("unexpected substep %#v", substep)
+	}
+	if !reflect.DeepEqual(definition.Roles, []WorkflowDefinitionRole{{OrgSlug: "org1", Slug: "dep1", Name: "Department 1"}}) {
+		t.Fatalf("unexpected roles %#v", definition.Roles)
+	}
+
+	list := get("/api/v1/workflows")
+	var response WorkflowDefinitionListResponse
+	if err := json.Unmarshal(list.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode list: %v (%s)", err, list.Body.String())
+	}
+	if len(response.Workflows) != 2 || response.Workflows[0].Key != "second" || response.Workflows[1].Key != "workflow" {
+		t.Fatalf("unexpected workflow list %#v", response.Workflows)
+	}
+
+	if rec := get("/api/v1/workflows/missing/definition"); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown workflow status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if rec := get("/api/v1/workflows/workflow"); rec.Code != http.StatusNotFound {
+		t.Fatalf("bare workflow path status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"strings"
+)
+
+// WorkflowOverview bundles what the stream picker and admin views otherwise
+// fetch separately: the workflow header, a summary of its structure without
+// schemas, role labels and palettes, and process counts.
+type WorkflowOverview struct {
+	Key          string                 `json:"key"`
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description,omitempty"`
+	Enabled      bool                   `json:"enabled"`
+	StepCount    int                    `json:"step_count"`
+	SubstepCount int                    `json:"substep_count"`
+	Steps        []WorkflowOverviewStep `json:"steps"`
+	Roles        []WorkflowOverviewRole `json:"roles"`
+	Counts       WorkflowOverviewCounts `json:"counts"`
+}
+
+type WorkflowOverviewStep struct {
+	StepID       string                    `json:"step_id"`
+	Title        string                    `json:"title"`
+	Order        int                       `json:"order"`
+	Organization string                    `json:"organization,omitempty"`
+	Substeps     []WorkflowOverviewSubstep `json:"substeps"`
+}
+
+type WorkflowOverviewSubstep struct {
+	SubstepID string   `json:"substep_id"`
+	Title     string   `json:"title"`
+	InputType string   `json:"input_type"`
+	Roles     []string `json:"roles"`
+}
+
+type WorkflowOverviewRole struct {
+	OrgSlug string `json:"org_slug"`
+	Slug    string `json:"slug"`
+	Name    string `json:"name"`
+	Label   string `json:"label"`
+	Palette string `json:"palette"`
+}
+
+type WorkflowOverviewCounts struct {
+	Total      int `json:"total"`
+	NotStarted int `json:"not_started"`
+	Started    int `json:"started"`
+	Terminated int `json:"terminated"`
+}
+
+// buildWorkflowOverview assembles the overview of key. Counts stay zero when
+// the server has no store.
+func (s *Server) buildWorkflowOverview(ctx context.Context, user *AccountUser, key string, cfg RuntimeConfig) (WorkflowOverview, error) {
+	overview := WorkflowOverview{
+		Key:         key,
+		Name:        cfg.Workflow.Name,
+		Description: strings.TrimSpace(cfg.Workflow.Description),
+		Enabled:     workflowEnabled(cfg.Workflow),
+		Steps:       []WorkflowOverviewStep{},
+		Roles:       make([]WorkflowOverviewRole, 0, len(cfg.Roles)),
+	}
+	for _, step := range sortedSteps(cfg.Workflow) {
+		stepView := WorkflowOverviewStep{
+			StepID:       step.StepID,
+			Title:        step.Title,
+			Order:        step.Order,
+			Organization: step.OrganizationSlug,
+			Substeps:     []WorkflowOverviewSubstep{},
+		}
+		for _, sub := range sortedSubsteps(step) {
+			stepView.Substeps = append(stepView.Substeps, WorkflowOverviewSubstep{
+				SubstepID: sub.SubstepID,
+				Title:     sub.Title,
+				InputType: sub.InputType,
+				Roles:     substepRoles(sub),
+			})
+		}
+		overview.SubstepCount += len(stepView.Substeps)
+		overview.Steps = append(overview.Steps, stepView)
+	}
+	overview.StepCount = len(overview.Steps)
+
+	roleMeta := s.roleMetaIndex(ctx)
+	for _, role := range cfg.Roles {
+		meta := roleMetaForOrg(role.OrgSlug, role.Slug, roleMeta, cfg.Roles)
+		label := meta.Label
+		if meta.Label == meta.ID && strings.TrimSpace(role.Name) != "" {
+			label = role.Name
+		}
+		overview.Roles = append(overview.Roles, WorkflowOverviewRole{
+			OrgSlug: role.OrgSlug,
+			Slug:    role.Slug,
+			Name:    role.Name,
+			Label:   label,
+			Palette: meta.Palette,
+		})
+	}
+
+	if s.store == nil {
+		return overview, nil
+	}
+	processes, err := s.store.ListRecentProcessesByWorkflow(ctx, key, 0)
+	if err != nil {
+		return WorkflowOverview{}, err
+	}
+	processes = s.visibleProcesses(user, cfg, processes)
+	counts := workflowProcessCounts(cfg.Workflow, processes)
+	overview.Counts = WorkflowOverviewCounts{
+		Total:      len(processes),
+		NotStarted: counts.NotStarted,
+		Started:    counts.Started,
+		Terminated: counts.Terminated,
+	}
+	return overview, nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestHandleWorkflowOverviewAPI(t *testing.T) {
+	tempDir := t.TempDir()
+	writeWorkflowConfig(t, filepath.Join(tempDir, "workflow.yaml"), "Main workflow", "string", "Main description")
+	store := NewMemoryStore()
+	store.SeedProcess(Process{WorkflowKey: "workflow", Progress: map[string]ProcessStep{"1_1": {State: "pending"}}})
+	store.SeedProcess(Process{WorkflowKey: "workflow", Progress: map[string]ProcessStep{"1_1": {State: "done"}}})
+	store.SeedProcess(Process{WorkflowKey: "other"})
+	server := &Server{
+		authorizer: fakeAuthorizer{},
+		configDir:  tempDir,
+		store:      store,
+	}
+	mux := server.newMux()
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/workflows/workflow/overview", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("overview status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	var overview WorkflowOverview
+	if err := json.Unmarshal(rec.Body.Bytes(), &overview); err != nil {
+		t.Fatalf("decode overview: %v", err)
+	}
+	if overview.Key != "workflow" || overview.Name != "Main workflow" || overview.Description != "Main description" || !overview.Enabled {
+		t.Fatalf("unexpected overview header %#v", overview)
+	}
+	if overview.StepCount != 1 || overview.SubstepCount != 1 || !reflect.DeepEqual(overview.Steps[0].Substeps, []WorkflowOverviewSubstep{{SubstepID: "1.1", Title: "Input", InputType: "formata", Roles: []string{"dep1"}}}) {
+		t.Fatalf("unexpected structure %#v", overview.Steps)
+	}
+	if !reflect.DeepEqual(overview.Roles, []WorkflowOverviewRole{{OrgSlug: "org1", Slug: "dep1", Name: "Department 1", Label: "Department 1", Palette: autoRolePalette("dep1")}}) {
+		t.Fatalf("unexpected roles %#v", overview.Roles)
+	}
+	if overview.Counts != (WorkflowOverviewCounts{Total: 2, NotStarted: 1, Terminated: 1}) {
+		t.Fatalf("unexpected counts %#v", overview.Counts)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/workflows/missing/overview", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown workflow status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandleWorkflowOverviewAPIRefusesMisconfiguredWorkflow(t *testing.T) {
+	t.Setenv("ADMIN_EMAIL", "admin@example.com")
+	t.Setenv("ADMIN_PASSWORD", "change-me")
+	tempDir := t.TempDir()
+	writeWorkflowConfig(t, filepath.Join(tempDir, "workflow.yaml"), "Main workflow", "string")
+	server := &Server{
+		authorizer:  fakeAuthorizer{},
+		configDir:   tempDir,
+		enforceAuth: true,
+		identity: &fakeIdentityStore{
+			listOrganizationsFunc: func(ctx context.Context) ([]IdentityOrg, error) { return nil, nil },
+		},
+	}
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/workflows/workflow/overview", nil)
+	req.AddCookie(&http.Cookie{Name: "attesta_session", Value: platformAdminSessionValue()})
+	rec := httptest.NewRecorder()
+	server.handleWorkflowDefinitionAPI(rec, req)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("misconfigured workflow status = %d, want %d body = %s", rec.Code, http.StatusConflict, rec.Body.String())
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+)
+
+// workflowUnavailableMessage is what users who cannot fix a broken workflow
+// see instead of the reference problems.
+const workflowUnavailableMessage = "This workflow is temporarily unavailable."
+
+const (
+	workflowRefMissingOrg  = "missing_organization"
+	workflowRefMissingRole = "missing_role"
+	workflowRefInvalid     = "invalid_reference"
+)
+
+// WorkflowRefProblem is one unresolved reference found by
+// validateWorkflowRefs. OrgSlug and RoleSlug are set for missing
+// organizations and roles so admins can be sent to the page that fixes them.
+type WorkflowRefProblem struct {
+	Kind     string
+	OrgSlug  string
+	RoleSlug string
+	Message  string
+}
+
+// WorkflowRefProblemView is a problem as listed on the stream home, with an
+// optional link to the admin page that resolves it.
+type WorkflowRefProblemView struct {
+	Message  string
+	FixURL   string
+	FixLabel string
+}
+
+func newWorkflowRefValidationError(problems []WorkflowRefProblem) *WorkflowRefValidationError {
+	seen := map[string]struct{}{}
+	deduped := make([]WorkflowRefProblem, 0, len(problems))
+	messages := make([]string, 0, len(problems))
+	for _, problem := range problems {
+		if _, ok := seen[problem.Message]; ok {
+			continue
+		}
+		seen[problem.Message] = struct{}{}
+		deduped = append(deduped, problem)
+		messages = append(messages, problem.Message)
+	}
+	return &WorkflowRefValidationError{Messages: messages, Problems: deduped}
+}
+
+// canSeeWorkflowRefProblems reports whether user may see the individual
+// reference problems of a workflow; everyone else gets
+// workflowUnavailableMessage.
+func canSeeWorkflowRefProblems(user *AccountUser) bool {
+	return user != nil && (user.IsPlatformAdmin || userIsOrgAdmin(user))
+}
+
+// workflowRefProblemViews attaches fix links to problems: platform admins
+// are sent to the organization console, org admins to their own roles page
+// for roles missing from their organization.
+func workflowRefProblemViews(user *AccountUser, problems []WorkflowRefProblem) []WorkflowRefProblemView {
+	views := make([]WorkflowRefProblemView, 0, len(problems))
+	for _, problem := range problems {
+		view := WorkflowRefProblemView{Message: problem.Message}
+		switch problem.Kind {
+		case workflowRefMissingOrg:
+			if user.IsPlatformAdmin {
+				view.FixURL, view.FixLabel = "/admin/orgs", "Create organization"
+			}
+		case workflowRefMissingRole:
+			if userIsOrgAdmin(user) && user.OrgSlug == problem.OrgSlug {
+				view.FixURL, view.FixLabel = organizationPath("roles"), "Add role"
+			} else if user.IsPlatformAdmin {
+				view.FixURL, view.FixLabel = "/admin/orgs", "Manage organizations"
+			}
+		}
+		views = append(views, view)
+	}
+	return views
+}
+
+// writeWorkflowSelectionError answers a failed selectedWorkflow call: broken
+// references become 503 with workflowUnavailableMessage, anything else 500.
+func writeWorkflowSelectionError(w http.ResponseWriter, err error) {
+	var refErr *WorkflowRefValidationError
+	if errors.As(err, &refErr) {
+		http.Error(w, workflowUnavailableMessage, http.StatusServiceUnavailable)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
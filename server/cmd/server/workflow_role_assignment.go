@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// roleHolderCountTTL is how long per-organization role counts are reused
+// before CountUsersByRole is asked again. The home page shows admins these
+// warnings on every view, so a freshly assigned role may take this long to
+// clear its warning.
+const roleHolderCountTTL = time.Minute
+
+// roleHolderCountCache keeps CountUsersByRole results per organization.
+type roleHolderCountCache struct {
+	mu   sync.Mutex
+	orgs map[string]roleHolderCountEntry
+}
+
+type roleHolderCountEntry struct {
+	counts    map[string]int
+	expiresAt time.Time
+}
+
+func (c *roleHolderCountCache) load(orgSlug string, now time.Time) (map[string]int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.orgs[orgSlug]
+	if !ok || now.After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.counts, true
+}
+
+func (c *roleHolderCountCache) save(orgSlug string, counts map[string]int, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.orgs == nil {
+		c.orgs = map[string]roleHolderCountEntry{}
+	}
+	c.orgs[orgSlug] = roleHolderCountEntry{counts: counts, expiresAt: now.Add(roleHolderCountTTL)}
+}
+
+// unassignedWorkflowRoleWarnings reports substep roles that no active user
+// holds in the organization that may act on the substep: the step's
+// organizationSlug when set, since Cerbos enforces it, or else any
+// organization declaring the role. Such substeps can never be completed, so
+// processes reaching them stall. The check needs identity and returns nothing
+// when auth is not enforced. Counts come from s.roleHolderCounts for up to
+// roleHolderCountTTL.
+func (s *Server) unassignedWorkflowRoleWarnings(ctx context.Context, cfg RuntimeConfig) ([]string, error) {
+	if s == nil || s.identity == nil || !s.enforceAuth {
+		return nil, nil
+	}
+	roleOrgs := map[string][]string{}
+	for _, role := range cfg.Roles {
+		orgSlug := strings.TrimSpace(role.OrgSlug)
+		roleSlug := strings.TrimSpace(role.Slug)
+		if orgSlug == "" || roleSlug == "" || containsRole(roleOrgs[roleSlug], orgSlug) {
+			continue
+		}
+		roleOrgs[roleSlug] = append(roleOrgs[roleSlug], orgSlug)
+	}
+	// Substeps are grouped by role and the organizations that may hold it, so
+	// each warning names the organizations that were actually checked.
+	type roleScope struct {
+		key  string
+		role string
+		orgs []string
+	}
+	var scopes []roleScope
+	substepsByScope := map[string][]string{}
+	substepOrgs := substepOrganizationMap(cfg.Workflow)
+	for _, sub := range orderedSubsteps(cfg.Workflow) {
+		for _, role := range substepRoles(sub) {
+			if len(roleOrgs[role]) == 0 {
+				continue
+			}
+			orgs := []string{substepOrgs[sub.SubstepID]}
+			if orgs[0] == "" {
+				orgs = append([]string(nil), roleOrgs[role]...)
+				sort.Strings(orgs)
+			}
+			key := role + "\x00" + strings.Join(orgs, ",")
+			if _, ok := substepsByScope[key]; !ok {
+				scopes = append(scopes, roleScope{key: key, role: role, orgs: orgs})
+			}
+			substepsByScope[key] = append(substepsByScope[key], sub.SubstepID)
+		}
+	}
+	if len(scopes) == 0 {
+		return nil, nil
+	}
+
+	now := s.nowUTC()
+	countsByOrg := map[string]map[string]int{}
+	var warnings []string
+	for _, scope := range scopes {
+		holders := 0
+		for _, orgSlug := range scope.orgs {
+			counts, ok := countsByOrg[orgSlug]
+			if !ok {
+				counts, ok = s.roleHolderCounts.load(orgSlug, now)
+			}
+			if !ok {
+				var err error
+				counts, err = s.identity.CountUsersByRole(ctx, orgSlug)
+				if err != nil && !errors.Is(err, ErrIdentityNotFound) {
+					return nil, err
+				}
+				s.roleHolderCounts.save(orgSlug, counts, now)
+			}
+			countsByOrg[orgSlug] = counts
+			holders += counts[scope.role]
+		}
+		if holders == 0 {
+			warnings = append(warnings, "role "+scope.role+" has no active users in "+strings.Join(scope.orgs, ", ")+" (needed by substeps "+strings.Join(dedupeStrings(substepsByScope[scope.key]), ", ")+")")
+		}
+	}
+	return warnings, nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCountIdentityUsersByRoleSkipsInactiveUsers(t *testing.T) {
+	counts := countIdentityUsersByRole([]IdentityUser{
+		{ID: "u1", Status: "active", Labels: []string{encodeIdentityRoleLabel("dep1"), encodeIdentityRoleLabel("dep2")}},
+		{ID: "u2", Labels: []string{encodeIdentityRoleLabel("dep1")}},
+		{ID: "u3", Status: "pending", Labels: []string{encodeIdentityRoleLabel("dep3")}},
+	})
+	if want := map[string]int{"dep1": 2, "dep2": 1}; !reflect.DeepEqual(counts, want) {
+		t.Fatalf("counts = %#v, want %#v", counts, want)
+	}
+}
+
+func TestUnassignedWorkflowRoleWarnings(t *testing.T) {
+	cfg := testRuntimeConfig()
+	cfg.Roles = []WorkflowRole{
+		{OrgSlug: "org1", Slug: "dep1"},
+		{OrgSlug: "org1", Slug: "dep2"},
+		{OrgSlug: "org2", Slug: "dep2"},
+		{OrgSlug: "org2", Slug: "dep3"},
+	}
+	counts := map[string]map[string]int{
+		"org1": {"dep1": 1},
+		"org2": {"dep2": 3},
+	}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	server := &Server{
+		enforceAuth: true,
+		identity: &fakeIdentityStore{countUsersByRoleFunc: func(ctx context.Context, orgSlug string) (map[string]int, error) {
+			return counts[orgSlug], nil
+		}},
+		now: func() time.Time { return now },
+	}
+
+	warnings, err := server.unassignedWorkflowRoleWarnings(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("unassignedWorkflowRoleWarnings: %v", err)
+	}
+	if want := []string{"role dep3 has no active users in org2 (needed by substeps 3.1, 3.2)"}; !reflect.DeepEqual(warnings, want) {
+		t.Fatalf("warnings = %#v, want %#v", warnings, want)
+	}
+
+	// Step 2 belongs to org1, so dep2 holders in org2 cannot act on it.
+	scoped := testRuntimeConfig()
+	scoped.Roles = cfg.Roles
+	scoped.Workflow.Steps[1].OrganizationSlug = "org1"
+	scopedWarnings, err := server.unassignedWorkflowRoleWarnings(context.Background(), scoped)
+	if err != nil {
+		t.Fatalf("unassignedWorkflowRoleWarnings(scoped): %v", err)
+	}
+	if want := []string{
+		"role dep2 has no active users in org1 (needed by substeps 2.1, 2.2)",
+		"role dep3 has no active users in org2 (needed by substeps 3.1, 3.2)",
+	}; !reflect.DeepEqual(scopedWarnings, want) {
+		t.Fatalf("scoped warnings = %#v, want %#v", scopedWarnings, want)
+	}
+
+	server.identity = &fakeIdentityStore{countUsersByRoleFunc: func(ctx context.Context, orgSlug string) (map[string]int, error) {
+		return nil, errors.New("appwrite down")
+	}}
+	if cached, err := server.unassignedWorkflowRoleWarnings(context.Background(), cfg); err != nil || !reflect.DeepEqual(cached, warnings) {
+		t.Fatalf("expected cached counts within the TTL, got %#v %v", cached, err)
+	}
+	now = now.Add(roleHolderCountTTL + time.Second)
+	if _, err := server.unassignedWorkflowRoleWarnings(context.Background(), cfg); err == nil {
+		t.Fatal("expected identity error once the cache expired")
+	}
+
+	server.enforceAuth = false
+	if warnings, err := server.unassignedWorkflowRoleWarnings(context.Background(), cfg); err != nil || warnings != nil {
+		t.Fatalf("expected no check without auth, got %#v %v", warnings, err)
+	}
+}
+
+func TestWorkflowHomeShowsRoleWarningsToAdmins(t *testing.T) {
+	cfg := testRuntimeConfig()
+	cfg.Roles = []WorkflowRole{{OrgSlug: "org1", Slug: "dep1"}}
+	server := &Server{
+		store:       NewMemoryStore(),
+		enforceAuth: true,
+		authorizer:  fakeAuthorizer{},
+		identity:    &fakeIdentityStore{},
+	}
+	request := httptest.NewRequest(http.MethodGet, "/my/streams/workflow", nil)
+
+	view := server.buildWorkflowHomeView(request.Context(), request, &AccountUser{Email: "admin@example.com", IsPlatformAdmin: true}, "workflow", cfg, "")
+	if len(view.RoleWarnings) != 1 {
+		t.Fatalf("expected one role warning for admins, got %#v", view.RoleWarnings)
+	}
+	view = server.buildWorkflowHomeView(request.Context(), request, &AccountUser{Email: "u1@example.com", RoleSlugs: []string{"dep1"}}, "workflow", cfg, "")
+	if len(view.RoleWarnings) != 0 {
+		t.Fatalf("expected no role warnings for non-admins, got %#v", view.RoleWarnings)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+)
+
+// WorkflowRolesResponse maps every role of a workflow to the badge metadata
+// server templates use, so client code renders the same label and colors.
+type WorkflowRolesResponse struct {
+	WorkflowKey string                      `json:"workflow_key"`
+	Roles       map[string]WorkflowRoleMeta `json:"roles"`
+}
+
+// WorkflowRoleMeta is RoleMeta for JSON. Palette is the role-palette.css key
+// and Color its CSS background variable (empty for "fallback").
+type WorkflowRoleMeta struct {
+	ID      string `json:"id"`
+	Label   string `json:"label"`
+	Palette string `json:"palette"`
+	Color   string `json:"color,omitempty"`
+}
+
+// workflowRoleMetas resolves the configured roles and every substep role
+// through roleMetaForOrg, the lookup the timeline badges use.
+func workflowRoleMetas(cfg RuntimeConfig, index map[roleMetaKey]RoleMeta) map[string]WorkflowRoleMeta {
+	roles := map[string]WorkflowRoleMeta{}
+	add := func(orgSlug, role string) {
+		role = strings.TrimSpace(role)
+		if role == "" {
+			return
+		}
+		if _, ok := roles[role]; ok {
+			return
+		}
+		meta := roleMetaForOrg(orgSlug, role, index, cfg.Roles)
+		roles[role] = WorkflowRoleMeta{
+			ID:      role,
+			Label:   meta.Label,
+			Palette: meta.Palette,
+			Color:   rolePaletteStyles[meta.Palette].Color,
+		}
+	}
+	for _, role := range cfg.Roles {
+		add(role.OrgSlug, role.Slug)
+	}
+	for _, step := range cfg.Workflow.Steps {
+		for _, sub := range step.Substep {
+			for _, role := range substepRoles(sub) {
+				add(step.OrganizationSlug, role)
+			}
+		}
+	}
+	return roles
+}
+
+// handleWorkflowRoles serves GET /my/streams/:key/roles.json.
+func (s *Server) handleWorkflowRoles(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if _, _, ok := s.requireAuthenticatedPost(w, r); !ok {
+		return
+	}
+	workflowKey, cfg, err := s.selectedWorkflowUnvalidated(r)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, WorkflowRolesResponse{
+		WorkflowKey: workflowKey,
+		Roles:       workflowRoleMetas(cfg, s.roleMetaIndex(r.Context())),
+	})
+}
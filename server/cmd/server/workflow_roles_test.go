@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandleWorkflowRoles(t *testing.T) {
+	server := &Server{
+		identity: &fakeIdentityStore{
+			listOrganizationsFunc: func(ctx context.Context) ([]IdentityOrg, error) {
+				return []IdentityOrg{{
+					Slug:  "org1",
+					Roles: []IdentityRole{{Slug: "dep1", Name: "Department 1", Palette: "blue"}},
+				}}, nil
+			},
+		},
+	}
+	roles := func(method string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/roles.json", nil)
+		req = req.WithContext(context.WithValue(req.Context(), workflowContextKey{}, workflowContextValue{
+			Key: "workflow",
+			Cfg: testRuntimeConfig(),
+		}))
+		rec := httptest.NewRecorder()
+		server.handleWorkflowRoles(rec, req)
+		return rec
+	}
+
+	rec := roles(http.MethodGet)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d body = %s", rec.Code, rec.Body.String())
+	}
+	var response WorkflowRolesResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode roles: %v", err)
+	}
+	if response.WorkflowKey != "workflow" || len(response.Roles) != 3 {
+		t.Fatalf("unexpected response %#v", response)
+	}
+	want := WorkflowRoleMeta{ID: "dep1", Label: "Department 1", Palette: "blue", Color: "var(--role-blue-bg)"}
+	if got := response.Roles["dep1"]; got != want {
+		t.Fatalf("dep1 = %#v, want %#v", got, want)
+	}
+	if got := response.Roles["dep2"]; got != (WorkflowRoleMeta{ID: "dep2", Label: "dep2", Palette: autoRolePalette("dep2"), Color: rolePaletteStyles[autoRolePalette("dep2")].Color}) {
+		t.Fatalf("dep2 fallback = %#v", got)
+	}
+
+	if rec := roles(http.MethodPost); rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("POST status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	workflowValidatePath     = "/admin/workflows/validate"
+	workflowValidateMaxBytes = 2 << 20
+)
+
+type WorkflowValidationReport struct {
+	Valid    bool     `json:"valid"`
+	Errors   []string `json:"errors"`
+	Warnings []string `json:"warnings"`
+	Name     string   `json:"name,omitempty"`
+	Steps    int      `json:"steps"`
+	Substeps int      `json:"substeps"`
+}
+
+// validateWorkflowYAML runs the catalog-load checks plus organization and role
+// reference resolution against data without storing anything. Roles no active
+// user holds are reported as warnings.
+func (s *Server) validateWorkflowYAML(r *http.Request, data []byte) (WorkflowValidationReport, error) {
+	report := WorkflowValidationReport{Errors: []string{}, Warnings: []string{}}
+	var cfg RuntimeConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		report.Errors = append(report.Errors, "parse yaml: "+err.Error())
+		return report, nil
+	}
+	legacyDepartments := len(cfg.Departments) > 0 && len(cfg.Roles) == 0
+	if err := validateWorkflowConfig(&cfg); err != nil {
+		report.Errors = append(report.Errors, err.Error())
+		return report, nil
+	}
+	report.Name = cfg.Workflow.Name
+	report.Steps = len(cfg.Workflow.Steps)
+	report.Substeps = countWorkflowSubsteps(cfg.Workflow)
+
+	if err := s.validateWorkflowRefs(r.Context(), cfg); err != nil {
+		var refErr *WorkflowRefValidationError
+		if !errors.As(err, &refErr) {
+			return WorkflowValidationReport{}, err
+		}
+		report.Errors = append(report.Errors, refErr.Messages...)
+	} else if s.identity == nil || !s.enforceAuth {
+		report.Warnings = append(report.Warnings, "organization and role references were not checked: identity is not configured")
+	}
+	if legacyDepartments {
+		report.Warnings = append(report.Warnings, "departments are deprecated; declare organizations and roles instead")
+	}
+	report.Warnings = append(report.Warnings, unusedWorkflowRoleWarnings(cfg)...)
+	if len(report.Errors) == 0 {
+		unassigned, err := s.unassignedWorkflowRoleWarnings(r.Context(), cfg)
+		if err != nil {
+			return WorkflowValidationReport{}, err
+		}
+		report.Warnings = append(report.Warnings, unassigned...)
+	}
+	report.Valid = len(report.Errors) == 0
+	return report, nil
+}
+
+func unusedWorkflowRoleWarnings(cfg RuntimeConfig) []string {
+	used := map[string]struct{}{}
+	for _, sub := range orderedSubsteps(cfg.Workflow) {
+		for _, role := range substepRoles(sub) {
+			used[strings.TrimSpace(role)] = struct{}{}
+		}
+	}
+	var warnings []string
+	for _, role := range cfg.Roles {
+		slug := strings.TrimSpace(role.Slug)
+		if _, ok := used[slug]; slug != "" && !ok {
+			warnings = append(warnings, "role "+strings.TrimSpace(role.OrgSlug)+"/"+slug+" is not used by any substep")
+		}
+	}
+	return dedupeStrings(warnings)
+}
+
+// handleWorkflowValidate serves POST /admin/workflows/validate: the request
+// body is workflow YAML and the response a WorkflowValidationReport. Invalid
+// workflows still answer 200; only unreadable requests fail.
+func (s *Server) handleWorkflowValidate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, _, ok := s.requireAuthenticatedPost(w, r)
+	if !ok {
+		return
+	}
+	if s.enforceAuth {
+		allowed, err := s.canAccessPlatformAdminConsole(r.Context(), user)
+		if err != nil {
+			status, message := authorizerErrorResponse(err)
+			logAndHTTPError(w, r, status, message, err, "cerbos check failed for workflow validation")
+			return
+		}
+		if !allowed {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, workflowValidateMaxBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, "workflow yaml is too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "failed to read workflow yaml", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		http.Error(w, "workflow yaml is required", http.StatusBadRequest)
+		return
+	}
+	report, err := s.validateWorkflowYAML(r, data)
+	if err != nil {
+		logAndHTTPError(w, r, http.StatusBadGateway, "failed to validate workflow references", err, "failed to resolve workflow references during dry-run validation")
+		return
+	}
+	writeJSON(w, report)
+}
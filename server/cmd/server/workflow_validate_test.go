@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHandleWorkflowValidateReportsWithoutPersisting(t *testing.T) {
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, "candidate.yaml")
+	writeWorkflowConfig(t, path, "Candidate", "string")
+	valid, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	server := &Server{authorizer: fakeAuthorizer{}, configDir: t.TempDir()}
+	mux := server.newMux()
+	validate := func(body string) (*httptest.ResponseRecorder, WorkflowValidationReport) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, workflowValidatePath, strings.NewReader(body)))
+		var report WorkflowValidationReport
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+				t.Fatalf("decode report: %v (%s)", err, rec.Body.String())
+			}
+		}
+		return rec, report
+	}
+
+	rec, report := validate(string(valid))
+	if rec.Code != http.StatusOK || !report.Valid || len(report.Errors) != 0 {
+		t.Fatalf("valid workflow status = %d report = %#v", rec.Code, report)
+	}
+	if report.Name != "Candidate" || report.Steps != 1 || report.Substeps != 1 {
+		t.Fatalf("unexpected summary %#v", report)
+	}
+	if len(report.Warnings) == 0 || !strings.Contains(report.Warnings[0], "not checked") {
+		t.Fatalf("expected unchecked references warning, got %#v", report.Warnings)
+	}
+	if catalog, err := server.workflowCatalog(); err == nil && len(catalog) != 0 {
+		t.Fatalf("dry run must not add workflows, got %#v", sortedWorkflowKeys(catalog))
+	}
+
+	_, report = validate(strings.Replace(string(valid), "inputType: \"formata\"", "inputType: \"bogus\"", 1))
+	if report.Valid || len(report.Errors) != 1 || !strings.Contains(report.Errors[0], "invalid inputType for substep 1.1") {
+		t.Fatalf("expected inputType error, got %#v", report)
+	}
+	_, report = validate("workflow: [")
+	if report.Valid || len(report.Errors) != 1 || !strings.HasPrefix(report.Errors[0], "parse yaml:") {
+		t.Fatalf("expected parse error, got %#v", report)
+	}
+
+	if rec, _ := validate("  "); rec.Code != http.StatusBadRequest {
+		t.Fatalf("empty body status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, workflowValidatePath, nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("GET status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestValidateWorkflowYAMLReportsUnresolvedReferences(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "candidate.yaml")
+	writeWorkflowConfig(t, path, "Candidate", "string")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	server := &Server{
+		identity:    &fakeIdentityStore{listOrganizationsFunc: func(ctx context.Context) ([]IdentityOrg, error) { return nil, nil }},
+		enforceAuth: true,
+	}
+
+	report, err := server.validateWorkflowYAML(httptest.NewRequest(http.MethodPost, workflowValidatePath, nil), data)
+	if err != nil {
+		t.Fatalf("validateWorkflowYAML: %v", err)
+	}
+	if report.Valid || !containsRole(report.Errors, "missing organization slug org1") {
+		t.Fatalf("expected unresolved organization error, got %#v", report)
+	}
+}
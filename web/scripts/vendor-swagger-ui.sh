@@ -0,0 +1,22 @@
+#!/bin/sh
+# Copy the pinned swagger-ui-dist assets into public/swagger-ui, where /docs/
+# loads them from instead of a CDN. With --if-missing it does nothing when
+# they are already vendored, so image builds fetch them only for a checkout
+# without them.
+set -eu
+
+version="${SWAGGER_UI_VERSION:-5.17.14}"
+web="$(cd "$(dirname "$0")/.." && pwd)"
+dest="${web}/public/swagger-ui"
+
+if [ "${1:-}" = "--if-missing" ] \
+  && [ -f "${dest}/swagger-ui.css" ] && [ -f "${dest}/swagger-ui-bundle.js" ]; then
+  exit 0
+fi
+
+tmp="$(mktemp -d)"
+trap 'rm -rf "${tmp}"' EXIT
+(cd "${tmp}" && npm pack "swagger-ui-dist@${version}" >/dev/null && tar -xzf swagger-ui-dist-*.tgz)
+mkdir -p "${dest}"
+cp "${tmp}/package/swagger-ui.css" "${tmp}/package/swagger-ui-bundle.js" "${tmp}/package/LICENSE" "${dest}/"
+echo "vendored swagger-ui-dist@${version} into ${dest}"
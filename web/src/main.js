@@ -927,6 +927,29 @@ const syncFormataValue = (component, hiddenInput) => {
   hiddenInput.value = JSON.stringify(readFormataComponentValue(component));
 };
 
+const formataDraftDelayMs = 1500;
+
+// Saves the current answers of a formata form as the viewer's draft, a
+// moment after they stop typing. Failures are ignored: the draft is only a
+// convenience and the real submit does not depend on it.
+const scheduleFormataDraftSave = (form, component) => {
+  const url = form.dataset.formataDraftPost;
+  if (!url) {
+    return;
+  }
+  window.clearTimeout(Number(form.dataset.formataDraftTimer || 0));
+  const timer = window.setTimeout(() => {
+    if ((form.dataset.formataSubmitState || "idle") !== "idle") {
+      return;
+    }
+    const body = new URLSearchParams({
+      value: JSON.stringify(readFormataComponentValue(component)),
+    });
+    void fetch(url, { method: "POST", body }).catch(() => {});
+  }, formataDraftDelayMs);
+  form.dataset.formataDraftTimer = String(timer);
+};
+
 const pendingActiveRoleSubmits = new WeakMap();
 
 const activeRoleInputForForm = (form) => {
@@ -1021,6 +1044,10 @@ const submitFormataPayload = (form, hiddenInput, payload) => {
     if (roleInput && roleInput.value.trim()) {
       values.activeRole = roleInput.value.trim();
     }
+    const confirmInput = form.querySelector('input[name="confirm"]');
+    if (confirmInput && confirmInput.checked) {
+      values.confirm = confirmInput.value;
+    }
     htmxApi.ajax("POST", url, {
       source: form,
       target,
@@ -1092,6 +1119,11 @@ const initializeFormataForms = async (container = document) => {
     const sync = () => syncFormataValue(component, hiddenInput);
     sync();
     component.addEventListener("formata:change", sync);
+    if (host.dataset.formataDisabled !== "true") {
+      component.addEventListener("formata:change", () =>
+        scheduleFormataDraftSave(form, component),
+      );
+    }
     component.addEventListener("submit", async (componentEvent) => {
       componentEvent.preventDefault?.();
       componentEvent.stopPropagation?.();
@@ -1156,9 +1188,70 @@ if (processId && workflowKey && processPageContent) {
   });
 }
 
+const initializeSignaturePads = (container = document) => {
+  for (const form of container.querySelectorAll(".js-signature-form")) {
+    const canvas = form.querySelector(".js-signature-canvas");
+    const input = form.querySelector(".js-signature-value");
+    if (!canvas || !input || form.dataset.signatureReady === "true") {
+      continue;
+    }
+    form.dataset.signatureReady = "true";
+    if (canvas.dataset.disabled === "true") {
+      continue;
+    }
+    const context = canvas.getContext("2d");
+    context.lineWidth = 2.5;
+    context.lineCap = "round";
+    context.strokeStyle = "#111";
+    let drawing = false;
+    let drawn = false;
+    const point = (event) => {
+      const rect = canvas.getBoundingClientRect();
+      return {
+        x: ((event.clientX - rect.left) * canvas.width) / rect.width,
+        y: ((event.clientY - rect.top) * canvas.height) / rect.height,
+      };
+    };
+    canvas.addEventListener("pointerdown", (event) => {
+      drawing = true;
+      canvas.setPointerCapture(event.pointerId);
+      const { x, y } = point(event);
+      context.beginPath();
+      context.moveTo(x, y);
+    });
+    canvas.addEventListener("pointermove", (event) => {
+      if (!drawing) {
+        return;
+      }
+      const { x, y } = point(event);
+      context.lineTo(x, y);
+      context.stroke();
+      drawn = true;
+    });
+    const stop = () => {
+      drawing = false;
+    };
+    canvas.addEventListener("pointerup", stop);
+    canvas.addEventListener("pointercancel", stop);
+    form.querySelector(".js-signature-clear")?.addEventListener("click", () => {
+      context.clearRect(0, 0, canvas.width, canvas.height);
+      drawn = false;
+      input.value = "";
+    });
+    form.addEventListener("submit", (event) => {
+      if (!drawn) {
+        event.preventDefault();
+        return;
+      }
+      input.value = canvas.toDataURL("image/png");
+    });
+  }
+};
+
 document.addEventListener("DOMContentLoaded", () => {
   formatLocalDateTimes(document);
   void initializeFormataForms(document);
+  initializeSignaturePads(document);
   markSelectedSubstep(currentSelectedSubstep());
   focusNextActionInput();
 });
@@ -1184,6 +1277,7 @@ document.body.addEventListener("htmx:afterSwap", (event) => {
   }
   if (event.target && event.target.id === "process-page-content") {
     void initializeFormataForms(event.target);
+    initializeSignaturePads(event.target);
     markSelectedSubstep(currentSelectedSubstep());
     focusNextActionInput();
   }
@@ -1422,29 +1516,3 @@ document.body.addEventListener(
   { passive: true },
 );
 
-const deptRoot = document.querySelector("[data-dept-role]");
-if (deptRoot) {
-  const role = deptRoot.dataset.deptRole;
-  const deptWorkflowKey = deptRoot.dataset.workflowKey;
-  const dashboard = document.getElementById("dept-dashboard");
-  if (role && deptWorkflowKey && dashboard) {
-    const source = new EventSource(
-      `/my/streams/${deptWorkflowKey}/events?workflow=${deptWorkflowKey}&role=${role}`,
-    );
-    source.addEventListener("role-updated", async () => {
-      try {
-        const response = await fetch(
-          `/my/streams/${deptWorkflowKey}/backoffice/${role}/partial`,
-        );
-        if (!response.ok) {
-          return;
-        }
-        const html = await response.text();
-        dashboard.innerHTML = html;
-        formatLocalDateTimes(dashboard);
-      } catch (err) {
-        // keep UI responsive even if SSE fails
-      }
-    });
-  }
-}